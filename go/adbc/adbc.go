@@ -48,6 +48,11 @@ import (
 //go:generate go run golang.org/x/tools/cmd/stringer -type InfoCode -linecomment
 
 // Error is the detailed error for an operation
+//
+// Error stays comparable, but two errors with an underlying error or
+// details are only equal if they were made by the same call of WithErr
+// or WithDetails, so use errors.Is to check for an Error with a given
+// status and message, and errors.As to get at the underlying error.
 type Error struct {
 	// Msg is a string representing a human readable error message
 	Msg string
@@ -58,12 +63,80 @@ type Error struct {
 	// SqlState is a SQLSTATE error code, if provided, as defined
 	// by the SQL:2003 standard. If not set, it will be "\0\0\0\0\0"
 	SqlState [5]byte
+	// extra holds what is set with WithErr and WithDetails, behind a
+	// pointer so that Error stays comparable
+	extra *errorExtra
+}
+
+type errorExtra struct {
+	err     error
+	details []ErrorDetail
 }
 
 func (e Error) Error() string {
 	return fmt.Sprintf("%s: SqlState: %s, msg: %s", e.Code, string(e.SqlState[:]), e.Msg)
 }
 
+// Unwrap returns the underlying error set with WithErr, so that
+// errors.Is and errors.As can reach the error of the client library
+// the driver uses.
+func (e Error) Unwrap() error {
+	if e.extra == nil {
+		return nil
+	}
+	return e.extra.err
+}
+
+// Is reports whether the target is an Error with the same message,
+// status, vendor code and SQLSTATE, ignoring the details and the
+// underlying error, which keeps errors.Is working with Error values
+// as sentinels.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && e.Msg == t.Msg && e.Code == t.Code &&
+		e.VendorCode == t.VendorCode && e.SqlState == t.SqlState
+}
+
+// WithErr returns a copy of the error wrapping the given underlying
+// error, such as the error of the client library the driver uses.
+func (e Error) WithErr(err error) Error {
+	e.extra = newErrorExtra(err, e.Details())
+	return e
+}
+
+// Details returns the additional driver-specific payloads describing
+// the error, such as the details of a gRPC status or the ID of the
+// query which failed. The same key may appear more than once.
+func (e Error) Details() []ErrorDetail {
+	if e.extra == nil {
+		return nil
+	}
+	return e.extra.details
+}
+
+// WithDetails returns a copy of the error with the given details.
+func (e Error) WithDetails(details ...ErrorDetail) Error {
+	if len(details) == 0 {
+		details = nil
+	}
+	e.extra = newErrorExtra(e.Unwrap(), details)
+	return e
+}
+
+func newErrorExtra(err error, details []ErrorDetail) *errorExtra {
+	if err == nil && details == nil {
+		return nil
+	}
+	return &errorExtra{err: err, details: details}
+}
+
+// ErrorDetail is a driver-specific key/value payload attached to an
+// Error. The value is arbitrary bytes, whose format depends on the key.
+type ErrorDetail struct {
+	Key   string
+	Value []byte
+}
+
 // Status represents an error code for operations that may fail
 type Status uint8
 
@@ -143,19 +216,31 @@ const (
 )
 
 // Canonical option values
+//
+// Ingesting with OptionValueIngestModeMerge updates the rows of the target
+// table which match the bound data on the columns listed (comma separated)
+// in OptionKeyIngestKeyColumns and inserts the others, creating the table
+// if it does not exist.
 const (
-	OptionValueEnabled          = "true"
-	OptionValueDisabled         = "false"
-	OptionKeyAutoCommit         = "adbc.connection.autocommit"
-	OptionKeyIngestTargetTable  = "adbc.ingest.target_table"
-	OptionKeyIngestMode         = "adbc.ingest.mode"
-	OptionKeyIsolationLevel     = "adbc.connection.transaction.isolation_level"
-	OptionKeyReadOnly           = "adbc.connection.readonly"
-	OptionValueIngestModeCreate = "adbc.ingest.mode.create"
-	OptionValueIngestModeAppend = "adbc.ingest.mode.append"
-	OptionKeyURI                = "uri"
-	OptionKeyUsername           = "username"
-	OptionKeyPassword           = "password"
+	OptionValueEnabled                = "true"
+	OptionValueDisabled               = "false"
+	OptionKeyAutoCommit               = "adbc.connection.autocommit"
+	OptionKeyIngestTargetTable        = "adbc.ingest.target_table"
+	OptionKeyIngestTargetCatalog      = "adbc.ingest.target_catalog"
+	OptionKeyIngestTargetDBSchema     = "adbc.ingest.target_db_schema"
+	OptionKeyIngestTemporary          = "adbc.ingest.temporary"
+	OptionKeyIngestMode               = "adbc.ingest.mode"
+	OptionKeyIngestKeyColumns         = "adbc.ingest.key_columns"
+	OptionKeyIsolationLevel           = "adbc.connection.transaction.isolation_level"
+	OptionKeyReadOnly                 = "adbc.connection.readonly"
+	OptionValueIngestModeCreate       = "adbc.ingest.mode.create"
+	OptionValueIngestModeAppend       = "adbc.ingest.mode.append"
+	OptionValueIngestModeReplace      = "adbc.ingest.mode.replace"
+	OptionValueIngestModeCreateAppend = "adbc.ingest.mode.create_append"
+	OptionValueIngestModeMerge        = "adbc.ingest.mode.merge"
+	OptionKeyURI                      = "uri"
+	OptionKeyUsername                 = "username"
+	OptionKeyPassword                 = "password"
 )
 
 type OptionIsolationLevel string
@@ -205,6 +290,16 @@ const (
 	InfoVendorVersion InfoCode = 1 // VendorVersion
 	// The database vendor/product Arrow library version (type: utf8)
 	InfoVendorArrowVersion InfoCode = 2 // VendorArrowVersion
+	// Whether the database supports SQL queries (type: bool)
+	InfoVendorSql InfoCode = 3 // VendorSql
+	// Whether the database supports Substrait plans (type: bool)
+	InfoVendorSubstrait InfoCode = 4 // VendorSubstrait
+	// The minimum Substrait version supported by the database
+	// (type: utf8)
+	InfoVendorSubstraitMinVersion InfoCode = 5 // VendorSubstraitMinVersion
+	// The maximum Substrait version supported by the database
+	// (type: utf8)
+	InfoVendorSubstraitMaxVersion InfoCode = 6 // VendorSubstraitMaxVersion
 
 	// The driver name (type: utf8)
 	InfoDriverName InfoCode = 100 // DriverName
@@ -212,6 +307,37 @@ const (
 	InfoDriverVersion InfoCode = 101 // DriverVersion
 	// The driver Arrow library version (type: utf8)
 	InfoDriverArrowVersion InfoCode = 102 // DriverArrowVersion
+
+	// The feature flags below tell which optional features of ADBC
+	// the driver supports with the database it is connected to. A
+	// driver which does not report a flag makes no claim either way.
+	// They are not part of the ADBC specification, so they take codes
+	// outside of the range [0, 10_000) it reserves, after the range
+	// [10_000, 20_000) of the Flight SQL driver.
+
+	// The ingest modes supported by bulk ingestion, as values of
+	// OptionKeyIngestMode, empty if bulk ingestion is not supported
+	// (type: list<utf8>)
+	InfoFeatureBulkIngestModes InfoCode = 20_000 // FeatureBulkIngestModes
+	// Whether ExecutePartitions and ReadPartition are supported
+	// (type: bool)
+	InfoFeaturePartitions InfoCode = 20_001 // FeaturePartitions
+	// Whether transactions are supported, by disabling
+	// OptionKeyAutoCommit and using Commit and Rollback (type: bool)
+	InfoFeatureTransactions InfoCode = 20_002 // FeatureTransactions
+	// Whether savepoints within transactions are supported (type: bool)
+	InfoFeatureSavepoints InfoCode = 20_003 // FeatureSavepoints
+	// Whether operations in progress are cancelled along with their
+	// context, or by AdbcConnectionCancel and AdbcStatementCancel
+	// through the C API (type: bool)
+	InfoFeatureCancel InfoCode = 20_004 // FeatureCancel
+	// Whether GetParameterSchema is supported for prepared statements
+	// (type: bool)
+	InfoFeatureParameterSchema InfoCode = 20_005 // FeatureParameterSchema
+	// The isolation levels which may be set with
+	// OptionKeyIsolationLevel, empty if it cannot be set
+	// (type: list<utf8>)
+	InfoFeatureIsolationLevels InfoCode = 20_006 // FeatureIsolationLevels
 )
 
 type ObjectDepth int
@@ -410,6 +536,137 @@ type PostInitOptions interface {
 	SetOption(key, value string) error
 }
 
+// GetSetOptions is an optional interface which can be implemented by
+// a Database, Connection or Statement to get options and set options
+// of types other than string.
+//
+// Getting an option which is not recognized returns an error with
+// StatusNotFound. Options should at least be retrievable with the
+// getter matching the type they were set with, and may also be
+// retrievable converted to another type.
+type GetSetOptions interface {
+	PostInitOptions
+	SetOptionBytes(key string, value []byte) error
+	SetOptionInt(key string, value int64) error
+	SetOptionDouble(key string, value float64) error
+	GetOption(key string) (string, error)
+	GetOptionBytes(key string) ([]byte, error)
+	GetOptionInt(key string) (int64, error)
+	GetOptionDouble(key string) (float64, error)
+}
+
+// Standard statistic keys and names, as returned in the statistic_key
+// column of ConnectionGetStatistics.GetStatistics. Keys in [0, 1024) are
+// reserved for ADBC, drivers may define others and list them with
+// ConnectionGetStatistics.GetStatisticNames.
+const (
+	// The average byte width statistic. The average size in bytes of a
+	// row in the column. Value type is float64.
+	//
+	// For example, this is roughly the average length of a string for a
+	// string column.
+	StatisticAverageByteWidthKey  = 0
+	StatisticAverageByteWidthName = "adbc.statistic.byte_width"
+	// The distinct value count (NDV) statistic. The number of distinct
+	// values in the column. Value type is int64 (when not approximate)
+	// or float64 (when approximate).
+	StatisticDistinctCountKey  = 1
+	StatisticDistinctCountName = "adbc.statistic.distinct_count"
+	// The max byte width statistic. The maximum size in bytes of a row
+	// in the column. Value type is int64 (when not approximate) or
+	// float64 (when approximate).
+	//
+	// For example, this is the maximum length of a string for a string
+	// column.
+	StatisticMaxByteWidthKey  = 2
+	StatisticMaxByteWidthName = "adbc.statistic.max_byte_width"
+	// The max value statistic. Value type is column-dependent.
+	StatisticMaxValueKey  = 3
+	StatisticMaxValueName = "adbc.statistic.max_value"
+	// The min value statistic. Value type is column-dependent.
+	StatisticMinValueKey  = 4
+	StatisticMinValueName = "adbc.statistic.min_value"
+	// The null count statistic. The number of values that are null in
+	// the column. Value type is int64 (when not approximate) or float64
+	// (when approximate).
+	StatisticNullCountKey  = 5
+	StatisticNullCountName = "adbc.statistic.null_count"
+	// The row count statistic. The number of rows in the column or
+	// table. Value type is int64 (when not approximate) or float64 (when
+	// approximate).
+	StatisticRowCountKey  = 6
+	StatisticRowCountName = "adbc.statistic.row_count"
+)
+
+// ConnectionGetStatistics is an optional interface which can be
+// implemented by drivers able to report statistics about the data
+// distribution of tables, such as row counts and the distinct values
+// of columns.
+type ConnectionGetStatistics interface {
+	// GetStatistics gets statistics about the data distribution of
+	// tables.
+	//
+	// The result is an Arrow dataset with the following schema:
+	//
+	//		Field Name               | Field Type
+	//		-------------------------|----------------------------------
+	//		catalog_name             | utf8
+	//		catalog_db_schemas       | list<DB_SCHEMA_SCHEMA> not null
+	//
+	// DB_SCHEMA_SCHEMA is a Struct with fields:
+	//
+	//		Field Name               | Field Type
+	//		-------------------------|----------------------------------
+	//		db_schema_name           | utf8
+	//		db_schema_statistics     | list<STATISTICS_SCHEMA> not null
+	//
+	// STATISTICS_SCHEMA is a Struct with fields:
+	//
+	//		Field Name               | Field Type                       | Comments
+	//		-------------------------|----------------------------------| --------
+	//		table_name               | utf8 not null                    |
+	//		column_name              | utf8                             | (1)
+	//		statistic_key            | int16 not null                   | (2)
+	//		statistic_value          | VALUE_SCHEMA not null            |
+	//		statistic_is_approximate | bool not null                    | (3)
+	//
+	// 1. If null, then the statistic applies to the entire table.
+	// 2. A dictionary-encoded statistic name (although we do not use the
+	//    Arrow dictionary type). Values in [0, 1024) are reserved for
+	//    ADBC, see the Statistic constants. Other values are for
+	//    driver-specific statistics, whose names are given by
+	//    GetStatisticNames.
+	// 3. If true, then the value is approximate or best-effort.
+	//
+	// VALUE_SCHEMA is a dense union with members:
+	//
+	//		Field Name               | Field Type
+	//		-------------------------|----------------------------------
+	//		int64                    | int64
+	//		uint64                   | uint64
+	//		float64                  | float64
+	//		binary                   | binary
+	//
+	// The catalog, dbSchema and tableName arguments are search patterns,
+	// as for Connection.GetObjects, nil meaning no filtering.
+	//
+	// approximate requests best-effort or cached values of the
+	// statistics rather than exact ones, which may be expensive to
+	// compute or unsupported.
+	GetStatistics(ctx context.Context, catalog, dbSchema, tableName *string, approximate bool) (array.RecordReader, error)
+
+	// GetStatisticNames gets the names of the driver-specific
+	// statistics.
+	//
+	// The result is an Arrow dataset with the following schema:
+	//
+	//		Field Name     | Field Type
+	//		---------------|----------------
+	//		statistic_name | utf8 not null
+	//		statistic_key  | int16 not null
+	GetStatisticNames(ctx context.Context) (array.RecordReader, error)
+}
+
 // Partitions represent a partitioned result set.
 //
 // Some backends may internally partition the results. These partitions
@@ -536,3 +793,45 @@ type Statement interface {
 	// an error with a StatusNotImplemented code.
 	ExecutePartitions(context.Context) (*arrow.Schema, Partitions, int64, error)
 }
+
+// StatementExecuteSchema is an optional interface which can be
+// implemented by drivers able to get the schema of the result set of
+// a query without executing it.
+type StatementExecuteSchema interface {
+	// ExecuteSchema gets the schema of the result set of the current
+	// query or prepared statement without executing it.
+	//
+	// The schema is the one ExecuteQuery would return the results
+	// with. If the driver cannot determine it without running the
+	// query, this should return an error with StatusNotImplemented.
+	ExecuteSchema(context.Context) (*arrow.Schema, error)
+}
+
+// StatementExecuteMulti is an optional interface which can be
+// implemented by drivers able to execute a query made of several
+// statements, such as a script, and return the result of each.
+type StatementExecuteMulti interface {
+	// ExecuteMulti executes the current query and returns its results,
+	// one per statement and in order.
+	//
+	// Only SQL queries can be executed this way. Drivers which cannot
+	// run bound parameters or bulk ingestion this way should return
+	// an error with StatusNotImplemented.
+	ExecuteMulti(context.Context) (MultiResult, error)
+}
+
+// MultiResult iterates over the results of StatementExecuteMulti.
+// It must be closed once done with.
+type MultiResult interface {
+	// NextResult returns the result of the next statement and the
+	// number of rows affected by it, if known. If unknown, the number
+	// of rows affected will be -1. The reader is nil for statements
+	// which have no result set.
+	//
+	// The caller must release the reader, which is only guaranteed to
+	// be valid until the next call. After the last result it returns
+	// io.EOF.
+	NextResult(context.Context) (array.RecordReader, int64, error)
+	// Close releases the results not yet read.
+	Close() error
+}
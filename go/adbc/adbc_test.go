@@ -0,0 +1,52 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package adbc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorDetails(t *testing.T) {
+	sentinel := adbc.Error{Msg: "failed", Code: adbc.StatusIO}
+	details := []adbc.ErrorDetail{{Key: "a", Value: []byte("1")}, {Key: "a", Value: []byte("2")}}
+	err := sentinel.WithDetails(details...)
+
+	assert.Nil(t, sentinel.Details())
+	assert.Equal(t, details, err.Details())
+	assert.Nil(t, err.WithDetails().Details())
+
+	// errors stay comparable, with or without details
+	assert.True(t, sentinel == sentinel)
+	assert.True(t, err == err)
+	assert.False(t, err == sentinel)
+	assert.True(t, errors.Is(err, sentinel))
+
+	// the underlying error is kept along with the details
+	cause := errors.New("connection reset")
+	wrapped := err.WithErr(cause)
+	assert.Equal(t, details, wrapped.Details())
+	assert.Nil(t, err.Unwrap())
+	assert.True(t, errors.Is(wrapped, cause))
+	assert.True(t, errors.Is(wrapped, sentinel))
+	assert.Same(t, cause, errors.Unwrap(wrapped.WithDetails()))
+	assert.False(t, wrapped == err.WithErr(cause))
+}
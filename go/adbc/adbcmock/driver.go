@@ -0,0 +1,702 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package adbcmock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// NewDatabase returns a mock database, failing only if an
+// ExpectNewDatabase expectation says so.
+func (m *Mock) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	e, err := m.match("NewDatabase", true, func(e expectation) error {
+		ex, ok := e.(*ExpectedNewDatabase)
+		if !ok {
+			return errMismatch
+		}
+		if ex.options != nil && !optionsEqual(ex.options, opts) {
+			return fmt.Errorf("options %v differ from the expected %v", opts, ex.options)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e != nil && e.returnErr() != nil {
+		return nil, e.returnErr()
+	}
+	return &database{mock: m}, nil
+}
+
+func optionsEqual(expected, actual map[string]string) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for k, v := range expected {
+		if av, ok := actual[k]; !ok || av != v {
+			return false
+		}
+	}
+	return true
+}
+
+// setOption matches setting an option on a database, connection or
+// statement. Disabling autocommit also matches ExpectBegin.
+func (m *Mock) setOption(call, key, value string) error {
+	e, err := m.match(fmt.Sprintf("%s %s=%s", call, key, value), false, func(e expectation) error {
+		switch ex := e.(type) {
+		case *ExpectedSetOption:
+			if ex.key != key || ex.value != value {
+				return fmt.Errorf("expected option %s=%s", ex.key, ex.value)
+			}
+			return nil
+		case *ExpectedBegin:
+			if key != adbc.OptionKeyAutoCommit || value != adbc.OptionValueDisabled {
+				return errMismatch
+			}
+			return nil
+		}
+		return errMismatch
+	})
+	if err != nil {
+		return err
+	}
+	return e.returnErr()
+}
+
+func (m *Mock) matchQuery(expected, actual string) error {
+	if m.QueryMatcher == nil {
+		return QueryMatcherRegexp(expected, actual)
+	}
+	return m.QueryMatcher(expected, actual)
+}
+
+func emptyReader(sc *arrow.Schema) array.RecordReader {
+	rdr, _ := array.NewRecordReader(sc, nil)
+	return rdr
+}
+
+type database struct {
+	mock *Mock
+}
+
+// SetOptions matches each option, in order of their keys, against
+// ExpectSetOption expectations.
+func (d *database) SetOptions(opts map[string]string) error {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := d.mock.setOption("Database.SetOptions", k, opts[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open returns a mock connection, failing only if an ExpectOpen
+// expectation says so.
+func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
+	e, err := d.mock.match("Open", true, func(e expectation) error {
+		if _, ok := e.(*ExpectedOpen); !ok {
+			return errMismatch
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e != nil && e.returnErr() != nil {
+		return nil, e.returnErr()
+	}
+	return &cnxn{mock: d.mock}, nil
+}
+
+func (d *database) Close() error { return nil }
+
+type cnxn struct {
+	mock   *Mock
+	closed bool
+}
+
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	e, err := c.mock.match(fmt.Sprintf("GetInfo %v", infoCodes), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedGetInfo)
+		if !ok {
+			return errMismatch
+		}
+		if len(ex.codes) == 0 {
+			return nil
+		}
+		if len(ex.codes) != len(infoCodes) {
+			return fmt.Errorf("expected info codes %v", ex.codes)
+		}
+		for i, code := range ex.codes {
+			if infoCodes[i] != code {
+				return fmt.Errorf("expected info codes %v", ex.codes)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ex := e.(*ExpectedGetInfo)
+	if ex.err != nil {
+		return nil, ex.err
+	}
+	if ex.rows == nil {
+		return emptyReader(adbc.GetInfoSchema), nil
+	}
+	return ex.rows, nil
+}
+
+func matchFilter(name string, expected, actual *string) error {
+	switch {
+	case expected == nil:
+		return nil
+	case actual == nil:
+		return fmt.Errorf("expected %s filter '%s', got none", name, *expected)
+	case *expected != *actual:
+		return fmt.Errorf("expected %s filter '%s', got '%s'", name, *expected, *actual)
+	}
+	return nil
+}
+
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (array.RecordReader, error) {
+	e, err := c.mock.match("GetObjects", false, func(e expectation) error {
+		ex, ok := e.(*ExpectedGetObjects)
+		if !ok {
+			return errMismatch
+		}
+		if ex.depth != nil && *ex.depth != depth {
+			return fmt.Errorf("expected depth %s, got %s", depthName(*ex.depth), depthName(depth))
+		}
+		for _, f := range []struct {
+			name             string
+			expected, actual *string
+		}{
+			{"catalog", ex.catalog, catalog},
+			{"db schema", ex.dbSchema, dbSchema},
+			{"table name", ex.tableName, tableName},
+			{"column name", ex.colName, columnName},
+		} {
+			if err := matchFilter(f.name, f.expected, f.actual); err != nil {
+				return err
+			}
+		}
+		if ex.tableTypes != nil && !sameStrings(ex.tableTypes, tableType) {
+			return fmt.Errorf("expected table types %v, got %v", ex.tableTypes, tableType)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ex := e.(*ExpectedGetObjects)
+	if ex.err != nil {
+		return nil, ex.err
+	}
+	if ex.rows == nil {
+		return emptyReader(adbc.GetObjectsSchema), nil
+	}
+	return ex.rows, nil
+}
+
+// sameStrings reports whether two slices hold the same strings in any
+// order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	e, err := c.mock.match("GetTableSchema "+tableName, false, func(e expectation) error {
+		ex, ok := e.(*ExpectedGetTableSchema)
+		if !ok {
+			return errMismatch
+		}
+		if ex.table != tableName {
+			return fmt.Errorf("expected table %s", ex.table)
+		}
+		if err := matchFilter("catalog", ex.catalog, catalog); err != nil {
+			return err
+		}
+		return matchFilter("db schema", ex.dbSchema, dbSchema)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ex := e.(*ExpectedGetTableSchema)
+	return ex.schema, ex.err
+}
+
+func (c *cnxn) GetTableTypes(context.Context) (array.RecordReader, error) {
+	e, err := c.mock.match("GetTableTypes", false, func(e expectation) error {
+		if _, ok := e.(*ExpectedGetTableTypes); !ok {
+			return errMismatch
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ex := e.(*ExpectedGetTableTypes)
+	if ex.err != nil {
+		return nil, ex.err
+	}
+	if ex.rows == nil {
+		return emptyReader(adbc.TableTypesSchema), nil
+	}
+	return ex.rows, nil
+}
+
+func (c *cnxn) Commit(context.Context) error {
+	e, err := c.mock.match("Commit", false, func(e expectation) error {
+		if _, ok := e.(*ExpectedCommit); !ok {
+			return errMismatch
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.returnErr()
+}
+
+func (c *cnxn) Rollback(context.Context) error {
+	e, err := c.mock.match("Rollback", false, func(e expectation) error {
+		if _, ok := e.(*ExpectedRollback); !ok {
+			return errMismatch
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.returnErr()
+}
+
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	return &statement{mock: c.mock}, nil
+}
+
+func (c *cnxn) Close() error {
+	if c.closed {
+		return adbc.Error{Code: adbc.StatusInvalidState}
+	}
+	c.closed = true
+	return nil
+}
+
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	e, err := c.mock.match(fmt.Sprintf("ReadPartition %x", serializedPartition), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedReadPartition)
+		if !ok {
+			return errMismatch
+		}
+		if ex.partition != nil && !bytes.Equal(ex.partition, serializedPartition) {
+			return fmt.Errorf("expected partition %x", ex.partition)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ex := e.(*ExpectedReadPartition)
+	if ex.err != nil {
+		return nil, ex.err
+	}
+	if ex.rows == nil {
+		return emptyReader(arrow.NewSchema(nil, nil)), nil
+	}
+	return ex.rows, nil
+}
+
+func (c *cnxn) SetOption(key, value string) error {
+	return c.mock.setOption("Connection.SetOption", key, value)
+}
+
+type statement struct {
+	mock   *Mock
+	closed bool
+
+	query    string
+	prepared *ExpectedPrepare
+
+	targetTable   string
+	targetCatalog string
+	targetSchema  string
+	ingestMode    string
+
+	bound      arrow.Record
+	streamBind array.RecordReader
+}
+
+func (st *statement) clearBinds() {
+	if st.bound != nil {
+		st.bound.Release()
+		st.bound = nil
+	} else if st.streamBind != nil {
+		st.streamBind.Release()
+		st.streamBind = nil
+	}
+}
+
+func (st *statement) Close() error {
+	if st.closed {
+		return adbc.Error{
+			Msg:  "statement already closed",
+			Code: adbc.StatusInvalidState}
+	}
+	st.clearBinds()
+	st.closed = true
+	return nil
+}
+
+// SetOption records the bulk ingestion options, which ExpectIngest
+// checks, and matches any other option against ExpectSetOption.
+func (st *statement) SetOption(key, val string) error {
+	switch key {
+	case adbc.OptionKeyIngestTargetTable:
+		st.query, st.prepared = "", nil
+		st.targetTable = val
+	case adbc.OptionKeyIngestTargetCatalog:
+		st.targetCatalog = val
+	case adbc.OptionKeyIngestTargetDBSchema:
+		st.targetSchema = val
+	case adbc.OptionKeyIngestMode:
+		st.ingestMode = val
+	default:
+		return st.mock.setOption("Statement.SetOption", key, val)
+	}
+	return nil
+}
+
+func (st *statement) SetSqlQuery(query string) error {
+	st.query, st.prepared = query, nil
+	st.targetTable = ""
+	return nil
+}
+
+func (st *statement) Prepare(context.Context) error {
+	if err := st.checkQuery(); err != nil {
+		return err
+	}
+
+	e, err := st.mock.match(fmt.Sprintf("Prepare '%s'", st.query), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedPrepare)
+		if !ok {
+			return errMismatch
+		}
+		return st.mock.matchQuery(ex.query, st.query)
+	})
+	if err != nil {
+		return err
+	}
+	if e.returnErr() != nil {
+		return e.returnErr()
+	}
+	st.prepared = e.(*ExpectedPrepare)
+	return nil
+}
+
+// SetSubstraitPlan is not supported, as expectations are given as SQL.
+func (st *statement) SetSubstraitPlan([]byte) error {
+	return adbc.Error{
+		Msg:  "[adbcmock] Substrait plans are not supported",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+func (st *statement) Bind(_ context.Context, values arrow.Record) error {
+	st.clearBinds()
+	st.bound = values
+	if st.bound != nil {
+		st.bound.Retain()
+	}
+	return nil
+}
+
+func (st *statement) BindStream(_ context.Context, stream array.RecordReader) error {
+	st.clearBinds()
+	st.streamBind = stream
+	if st.streamBind != nil {
+		st.streamBind.Retain()
+	}
+	return nil
+}
+
+// GetParameterSchema returns the schema set with
+// WillReturnParameterSchema when the statement was prepared.
+func (st *statement) GetParameterSchema() (*arrow.Schema, error) {
+	if st.prepared == nil {
+		return nil, adbc.Error{
+			Msg:  "[adbcmock] statement is not prepared",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if st.prepared.paramSchema == nil {
+		return nil, adbc.Error{
+			Msg:  "[adbcmock] no parameter schema was given for " + st.prepared.String(),
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+	return st.prepared.paramSchema, nil
+}
+
+func (st *statement) checkQuery() error {
+	if st.query == "" {
+		return adbc.Error{
+			Msg:  "cannot execute without a query",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	return nil
+}
+
+// args returns the bound parameters, consuming a bound stream. The
+// returned function releases them.
+func (st *statement) args() ([]arrow.Record, func(), error) {
+	if st.bound != nil {
+		return []arrow.Record{st.bound}, func() {}, nil
+	}
+	if st.streamBind == nil {
+		return nil, func() {}, nil
+	}
+
+	stream := st.streamBind
+	st.streamBind = nil
+	defer stream.Release()
+
+	var recs []arrow.Record
+	release := func() {
+		for _, r := range recs {
+			r.Release()
+		}
+	}
+	for stream.Next() {
+		stream.Record().Retain()
+		recs = append(recs, stream.Record())
+	}
+	if err := stream.Err(); err != nil {
+		release()
+		return nil, nil, err
+	}
+	if recs == nil {
+		recs = []arrow.Record{}
+	}
+	return recs, release, nil
+}
+
+// matchArgs compares the bound parameters with the expected ones,
+// unless no parameters were expected.
+func (st *statement) matchArgs(expected, actual []arrow.Record) error {
+	if expected == nil {
+		return nil
+	}
+	return st.mock.matchArgs(expected, actual)
+}
+
+func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	if st.targetTable != "" {
+		n, err := st.ingest()
+		return nil, n, err
+	}
+	if err := st.checkQuery(); err != nil {
+		return nil, -1, err
+	}
+
+	args, release, err := st.args()
+	if err != nil {
+		return nil, -1, err
+	}
+	defer release()
+
+	e, err := st.mock.match(fmt.Sprintf("ExecuteQuery '%s'%s", st.query, describeArgs(args)), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedQuery)
+		if !ok {
+			return errMismatch
+		}
+		if err := st.mock.matchQuery(ex.query, st.query); err != nil {
+			return err
+		}
+		return st.matchArgs(ex.args, args)
+	})
+	if err != nil {
+		return nil, -1, err
+	}
+
+	ex := e.(*ExpectedQuery)
+	if ex.err != nil {
+		return nil, -1, ex.err
+	}
+	if ex.rows == nil {
+		return emptyReader(arrow.NewSchema(nil, nil)), ex.n, nil
+	}
+	return ex.rows, ex.n, nil
+}
+
+func (st *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	if st.targetTable != "" {
+		return st.ingest()
+	}
+	if err := st.checkQuery(); err != nil {
+		return -1, err
+	}
+
+	args, release, err := st.args()
+	if err != nil {
+		return -1, err
+	}
+	defer release()
+
+	e, err := st.mock.match(fmt.Sprintf("ExecuteUpdate '%s'%s", st.query, describeArgs(args)), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedExec)
+		if !ok {
+			return errMismatch
+		}
+		if err := st.mock.matchQuery(ex.query, st.query); err != nil {
+			return err
+		}
+		return st.matchArgs(ex.args, args)
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	ex := e.(*ExpectedExec)
+	if ex.err != nil {
+		return -1, ex.err
+	}
+	return ex.n, nil
+}
+
+func (st *statement) ingest() (int64, error) {
+	if st.bound == nil && st.streamBind == nil {
+		return -1, adbc.Error{
+			Msg:  "must call Bind before bulk ingestion",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	data, release, err := st.args()
+	if err != nil {
+		return -1, err
+	}
+	defer release()
+
+	var rows int64
+	for _, r := range data {
+		rows += r.NumRows()
+	}
+
+	call := fmt.Sprintf("ingest into %s%s", st.targetTable, describeArgs(data))
+	e, err := st.mock.match(call, false, func(e expectation) error {
+		ex, ok := e.(*ExpectedIngest)
+		if !ok {
+			return errMismatch
+		}
+		switch {
+		case ex.table != st.targetTable:
+			return fmt.Errorf("expected target table %s", ex.table)
+		case ex.catalog != nil && *ex.catalog != st.targetCatalog:
+			return fmt.Errorf("expected target catalog '%s', got '%s'", *ex.catalog, st.targetCatalog)
+		case ex.dbSchema != nil && *ex.dbSchema != st.targetSchema:
+			return fmt.Errorf("expected target db schema '%s', got '%s'", *ex.dbSchema, st.targetSchema)
+		case ex.mode != "" && ex.mode != st.ingestMode:
+			return fmt.Errorf("expected ingest mode '%s', got '%s'", ex.mode, st.ingestMode)
+		}
+		return st.matchArgs(ex.data, data)
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	ex := e.(*ExpectedIngest)
+	switch {
+	case ex.err != nil:
+		return -1, ex.err
+	case ex.n >= 0:
+		return ex.n, nil
+	}
+	return rows, nil
+}
+
+func (st *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	if err := st.checkQuery(); err != nil {
+		return nil, adbc.Partitions{}, -1, err
+	}
+
+	args, release, err := st.args()
+	if err != nil {
+		return nil, adbc.Partitions{}, -1, err
+	}
+	defer release()
+
+	e, err := st.mock.match(fmt.Sprintf("ExecutePartitions '%s'%s", st.query, describeArgs(args)), false, func(e expectation) error {
+		ex, ok := e.(*ExpectedExecutePartitions)
+		if !ok {
+			return errMismatch
+		}
+		if err := st.mock.matchQuery(ex.query, st.query); err != nil {
+			return err
+		}
+		return st.matchArgs(ex.args, args)
+	})
+	if err != nil {
+		return nil, adbc.Partitions{}, -1, err
+	}
+
+	ex := e.(*ExpectedExecutePartitions)
+	if ex.err != nil {
+		return nil, adbc.Partitions{}, -1, ex.err
+	}
+	return ex.schema, ex.partitions, ex.n, nil
+}
+
+var (
+	_ adbc.Driver          = (*Mock)(nil)
+	_ adbc.PostInitOptions = (*cnxn)(nil)
+	_ adbc.PostInitOptions = (*statement)(nil)
+)
@@ -0,0 +1,685 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package adbcmock
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type expectation interface {
+	fulfilled() bool
+	trigger()
+	returnErr() error
+	String() string
+}
+
+// commonExpectation holds the state shared by every expectation
+type commonExpectation struct {
+	triggered bool
+	err       error
+}
+
+func (e *commonExpectation) fulfilled() bool  { return e.triggered }
+func (e *commonExpectation) trigger()         { e.triggered = true }
+func (e *commonExpectation) returnErr() error { return e.err }
+
+func describeArgs(recs []arrow.Record) string {
+	if recs == nil {
+		return ""
+	}
+	var rows int64
+	for _, r := range recs {
+		rows += r.NumRows()
+	}
+	return fmt.Sprintf(" with %d rows of arguments", rows)
+}
+
+// ExpectedNewDatabase is the expectation of a call to NewDatabase
+type ExpectedNewDatabase struct {
+	commonExpectation
+	options map[string]string
+}
+
+// ExpectNewDatabase expects a database to be created. Databases can be
+// created without expectations, so this is only needed to check their
+// options or make their creation fail.
+func (m *Mock) ExpectNewDatabase() *ExpectedNewDatabase {
+	e := &ExpectedNewDatabase{}
+	m.expect(e)
+	return e
+}
+
+// WithOptions expects the database to be created with exactly these
+// options.
+func (e *ExpectedNewDatabase) WithOptions(opts map[string]string) *ExpectedNewDatabase {
+	e.options = opts
+	return e
+}
+
+// WillReturnError makes creating the database fail.
+func (e *ExpectedNewDatabase) WillReturnError(err error) *ExpectedNewDatabase {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedNewDatabase) String() string {
+	if e.options == nil {
+		return "ExpectedNewDatabase"
+	}
+	return fmt.Sprintf("ExpectedNewDatabase with options %v", e.options)
+}
+
+// ExpectedOpen is the expectation of opening a connection
+type ExpectedOpen struct {
+	commonExpectation
+}
+
+// ExpectOpen expects a connection to be opened. Connections can be
+// opened without expectations, so this is only needed to make opening
+// one fail.
+func (m *Mock) ExpectOpen() *ExpectedOpen {
+	e := &ExpectedOpen{}
+	m.expect(e)
+	return e
+}
+
+// WillReturnError makes opening the connection fail.
+func (e *ExpectedOpen) WillReturnError(err error) *ExpectedOpen {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedOpen) String() string { return "ExpectedOpen" }
+
+// ExpectedSetOption is the expectation of setting an option on a
+// database, connection or statement after it was created.
+type ExpectedSetOption struct {
+	commonExpectation
+	key, value string
+}
+
+// ExpectSetOption expects an option to be set to the given value.
+func (m *Mock) ExpectSetOption(key, value string) *ExpectedSetOption {
+	e := &ExpectedSetOption{key: key, value: value}
+	m.expect(e)
+	return e
+}
+
+// WillReturnError makes setting the option fail.
+func (e *ExpectedSetOption) WillReturnError(err error) *ExpectedSetOption {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedSetOption) String() string {
+	return fmt.Sprintf("ExpectedSetOption %s=%s", e.key, e.value)
+}
+
+// ExpectedBegin is the expectation of a transaction being started by
+// disabling autocommit.
+type ExpectedBegin struct {
+	commonExpectation
+}
+
+// ExpectBegin expects autocommit to be disabled on a connection.
+func (m *Mock) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	m.expect(e)
+	return e
+}
+
+// WillReturnError makes disabling autocommit fail.
+func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedBegin) String() string { return "ExpectedBegin" }
+
+// ExpectedCommit is the expectation of a call to Commit
+type ExpectedCommit struct {
+	commonExpectation
+}
+
+// ExpectCommit expects the current transaction to be committed.
+func (m *Mock) ExpectCommit() *ExpectedCommit {
+	e := &ExpectedCommit{}
+	m.expect(e)
+	return e
+}
+
+// WillReturnError makes the commit fail.
+func (e *ExpectedCommit) WillReturnError(err error) *ExpectedCommit {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedCommit) String() string { return "ExpectedCommit" }
+
+// ExpectedRollback is the expectation of a call to Rollback
+type ExpectedRollback struct {
+	commonExpectation
+}
+
+// ExpectRollback expects the current transaction to be rolled back.
+func (m *Mock) ExpectRollback() *ExpectedRollback {
+	e := &ExpectedRollback{}
+	m.expect(e)
+	return e
+}
+
+// WillReturnError makes the rollback fail.
+func (e *ExpectedRollback) WillReturnError(err error) *ExpectedRollback {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedRollback) String() string { return "ExpectedRollback" }
+
+// ExpectedPrepare is the expectation of a statement being prepared
+type ExpectedPrepare struct {
+	commonExpectation
+	query       string
+	paramSchema *arrow.Schema
+}
+
+// ExpectPrepare expects a statement with a matching query to be
+// prepared.
+func (m *Mock) ExpectPrepare(query string) *ExpectedPrepare {
+	e := &ExpectedPrepare{query: query}
+	m.expect(e)
+	return e
+}
+
+// WillReturnParameterSchema sets the schema returned by
+// GetParameterSchema once the statement is prepared.
+func (e *ExpectedPrepare) WillReturnParameterSchema(sc *arrow.Schema) *ExpectedPrepare {
+	e.paramSchema = sc
+	return e
+}
+
+// WillReturnError makes preparing the statement fail.
+func (e *ExpectedPrepare) WillReturnError(err error) *ExpectedPrepare {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedPrepare) String() string {
+	return fmt.Sprintf("ExpectedPrepare '%s'", e.query)
+}
+
+// ExpectedQuery is the expectation of a query being executed with
+// ExecuteQuery.
+type ExpectedQuery struct {
+	commonExpectation
+	query string
+	args  []arrow.Record
+	rows  array.RecordReader
+	n     int64
+}
+
+// ExpectQuery expects a matching query to be executed with
+// ExecuteQuery. Unless WillReturnRows is used, the result is empty.
+func (m *Mock) ExpectQuery(query string) *ExpectedQuery {
+	e := &ExpectedQuery{query: query, n: -1}
+	m.expect(e)
+	return e
+}
+
+// WithArgs expects the query to be executed with parameters equal to
+// the rows of the records. The records must stay valid until the query
+// is executed.
+func (e *ExpectedQuery) WithArgs(args ...arrow.Record) *ExpectedQuery {
+	e.args = append([]arrow.Record{}, args...)
+	return e
+}
+
+// WillReturnRows sets the result of the query. Ownership of the reader
+// is passed to the caller of ExecuteQuery.
+func (e *ExpectedQuery) WillReturnRows(rdr array.RecordReader) *ExpectedQuery {
+	e.rows = rdr
+	return e
+}
+
+// WillReturnRowsAffected sets the number of rows reported along with
+// the result, which is -1 by default.
+func (e *ExpectedQuery) WillReturnRowsAffected(n int64) *ExpectedQuery {
+	e.n = n
+	return e
+}
+
+// WillReturnError makes the query fail.
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedQuery) String() string {
+	return fmt.Sprintf("ExpectedQuery '%s'%s", e.query, describeArgs(e.args))
+}
+
+// ExpectedExec is the expectation of a query being executed with
+// ExecuteUpdate.
+type ExpectedExec struct {
+	commonExpectation
+	query string
+	args  []arrow.Record
+	n     int64
+}
+
+// ExpectExec expects a matching query to be executed with
+// ExecuteUpdate.
+func (m *Mock) ExpectExec(query string) *ExpectedExec {
+	e := &ExpectedExec{query: query, n: -1}
+	m.expect(e)
+	return e
+}
+
+// WithArgs expects the query to be executed with parameters equal to
+// the rows of the records. The records must stay valid until the query
+// is executed.
+func (e *ExpectedExec) WithArgs(args ...arrow.Record) *ExpectedExec {
+	e.args = append([]arrow.Record{}, args...)
+	return e
+}
+
+// WillReturnResult sets the number of affected rows, which is -1 by
+// default.
+func (e *ExpectedExec) WillReturnResult(n int64) *ExpectedExec {
+	e.n = n
+	return e
+}
+
+// WillReturnError makes the execution fail.
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedExec) String() string {
+	return fmt.Sprintf("ExpectedExec '%s'%s", e.query, describeArgs(e.args))
+}
+
+// ExpectedIngest is the expectation of data being ingested into a table
+type ExpectedIngest struct {
+	commonExpectation
+	table             string
+	catalog, dbSchema *string
+	mode              string
+	data              []arrow.Record
+	n                 int64
+}
+
+// ExpectIngest expects bound data to be ingested into the table by
+// executing a statement with adbc.OptionKeyIngestTargetTable set.
+func (m *Mock) ExpectIngest(table string) *ExpectedIngest {
+	e := &ExpectedIngest{table: table, n: -1}
+	m.expect(e)
+	return e
+}
+
+// WithCatalog expects adbc.OptionKeyIngestTargetCatalog to be set to
+// the catalog. Without it any catalog matches.
+func (e *ExpectedIngest) WithCatalog(catalog string) *ExpectedIngest {
+	e.catalog = &catalog
+	return e
+}
+
+// WithDBSchema expects adbc.OptionKeyIngestTargetDBSchema to be set to
+// the db schema. Without it any db schema matches.
+func (e *ExpectedIngest) WithDBSchema(dbSchema string) *ExpectedIngest {
+	e.dbSchema = &dbSchema
+	return e
+}
+
+// WithMode expects the ingestion to use the given mode, one of the
+// adbc.OptionValueIngestMode* values. Without it any mode matches.
+func (e *ExpectedIngest) WithMode(mode string) *ExpectedIngest {
+	e.mode = mode
+	return e
+}
+
+// WithData expects the ingested rows to equal the rows of the records.
+// The records must stay valid until the data is ingested.
+func (e *ExpectedIngest) WithData(data ...arrow.Record) *ExpectedIngest {
+	e.data = append([]arrow.Record{}, data...)
+	return e
+}
+
+// WillReturnResult sets the number of ingested rows reported, which is
+// the number of bound rows by default.
+func (e *ExpectedIngest) WillReturnResult(n int64) *ExpectedIngest {
+	e.n = n
+	return e
+}
+
+// WillReturnError makes the ingestion fail.
+func (e *ExpectedIngest) WillReturnError(err error) *ExpectedIngest {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedIngest) String() string {
+	name := e.table
+	if e.dbSchema != nil {
+		name = *e.dbSchema + "." + name
+	}
+	if e.catalog != nil {
+		name = *e.catalog + "." + name
+	}
+	var mode string
+	if e.mode != "" {
+		mode = " in mode " + e.mode
+	}
+	return fmt.Sprintf("ExpectedIngest into %s%s%s", name, mode, describeArgs(e.data))
+}
+
+// ExpectedExecutePartitions is the expectation of a query being
+// executed with ExecutePartitions.
+type ExpectedExecutePartitions struct {
+	commonExpectation
+	query      string
+	args       []arrow.Record
+	schema     *arrow.Schema
+	partitions adbc.Partitions
+	n          int64
+}
+
+// ExpectExecutePartitions expects a matching query to be executed with
+// ExecutePartitions.
+func (m *Mock) ExpectExecutePartitions(query string) *ExpectedExecutePartitions {
+	e := &ExpectedExecutePartitions{query: query, n: -1}
+	m.expect(e)
+	return e
+}
+
+// WithArgs expects the query to be executed with parameters equal to
+// the rows of the records. The records must stay valid until the query
+// is executed.
+func (e *ExpectedExecutePartitions) WithArgs(args ...arrow.Record) *ExpectedExecutePartitions {
+	e.args = append([]arrow.Record{}, args...)
+	return e
+}
+
+// WillReturnPartitions sets the schema, partitions and number of rows
+// the execution returns. Each partition can be read back by expecting
+// it with ExpectReadPartition.
+func (e *ExpectedExecutePartitions) WillReturnPartitions(sc *arrow.Schema, partitions adbc.Partitions, n int64) *ExpectedExecutePartitions {
+	e.schema, e.partitions, e.n = sc, partitions, n
+	return e
+}
+
+// WillReturnError makes the execution fail.
+func (e *ExpectedExecutePartitions) WillReturnError(err error) *ExpectedExecutePartitions {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedExecutePartitions) String() string {
+	return fmt.Sprintf("ExpectedExecutePartitions '%s'%s", e.query, describeArgs(e.args))
+}
+
+// ExpectedReadPartition is the expectation of a call to ReadPartition
+type ExpectedReadPartition struct {
+	commonExpectation
+	partition []byte
+	rows      array.RecordReader
+}
+
+// ExpectReadPartition expects the serialized partition to be read. A
+// nil partition matches any.
+func (m *Mock) ExpectReadPartition(partition []byte) *ExpectedReadPartition {
+	e := &ExpectedReadPartition{partition: partition}
+	m.expect(e)
+	return e
+}
+
+// WillReturnRows sets the contents of the partition. Ownership of the
+// reader is passed to the caller of ReadPartition.
+func (e *ExpectedReadPartition) WillReturnRows(rdr array.RecordReader) *ExpectedReadPartition {
+	e.rows = rdr
+	return e
+}
+
+// WillReturnError makes reading the partition fail.
+func (e *ExpectedReadPartition) WillReturnError(err error) *ExpectedReadPartition {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedReadPartition) String() string {
+	if e.partition == nil {
+		return "ExpectedReadPartition"
+	}
+	return fmt.Sprintf("ExpectedReadPartition %x", e.partition)
+}
+
+// ExpectedGetInfo is the expectation of a call to GetInfo
+type ExpectedGetInfo struct {
+	commonExpectation
+	codes []adbc.InfoCode
+	rows  array.RecordReader
+}
+
+// ExpectGetInfo expects GetInfo to be called for the given codes, or
+// for any codes if none are given.
+func (m *Mock) ExpectGetInfo(codes ...adbc.InfoCode) *ExpectedGetInfo {
+	e := &ExpectedGetInfo{codes: codes}
+	m.expect(e)
+	return e
+}
+
+// WillReturnRows sets the result, which must follow
+// adbc.GetInfoSchema. Ownership of the reader is passed to the caller.
+func (e *ExpectedGetInfo) WillReturnRows(rdr array.RecordReader) *ExpectedGetInfo {
+	e.rows = rdr
+	return e
+}
+
+// WillReturnError makes the call fail.
+func (e *ExpectedGetInfo) WillReturnError(err error) *ExpectedGetInfo {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedGetInfo) String() string {
+	if len(e.codes) == 0 {
+		return "ExpectedGetInfo"
+	}
+	return fmt.Sprintf("ExpectedGetInfo %v", e.codes)
+}
+
+// ExpectedGetObjects is the expectation of a call to GetObjects
+type ExpectedGetObjects struct {
+	commonExpectation
+	depth                                 *adbc.ObjectDepth
+	catalog, dbSchema, tableName, colName *string
+	tableTypes                            []string
+	rows                                  array.RecordReader
+}
+
+// ExpectGetObjects expects GetObjects to be called. Unless narrowed down
+// with the With methods, any depth and filters match.
+func (m *Mock) ExpectGetObjects() *ExpectedGetObjects {
+	e := &ExpectedGetObjects{}
+	m.expect(e)
+	return e
+}
+
+// WithDepth expects the given depth.
+func (e *ExpectedGetObjects) WithDepth(depth adbc.ObjectDepth) *ExpectedGetObjects {
+	e.depth = &depth
+	return e
+}
+
+// WithCatalog expects the given catalog filter pattern.
+func (e *ExpectedGetObjects) WithCatalog(pattern string) *ExpectedGetObjects {
+	e.catalog = &pattern
+	return e
+}
+
+// WithDBSchema expects the given db schema filter pattern.
+func (e *ExpectedGetObjects) WithDBSchema(pattern string) *ExpectedGetObjects {
+	e.dbSchema = &pattern
+	return e
+}
+
+// WithTableName expects the given table name filter pattern.
+func (e *ExpectedGetObjects) WithTableName(pattern string) *ExpectedGetObjects {
+	e.tableName = &pattern
+	return e
+}
+
+// WithColumnName expects the given column name filter pattern.
+func (e *ExpectedGetObjects) WithColumnName(pattern string) *ExpectedGetObjects {
+	e.colName = &pattern
+	return e
+}
+
+// WithTableTypes expects the given table types, in any order.
+func (e *ExpectedGetObjects) WithTableTypes(types ...string) *ExpectedGetObjects {
+	e.tableTypes = types
+	return e
+}
+
+// WillReturnRows sets the result, which must follow
+// adbc.GetObjectsSchema. Ownership of the reader is passed to the
+// caller.
+func (e *ExpectedGetObjects) WillReturnRows(rdr array.RecordReader) *ExpectedGetObjects {
+	e.rows = rdr
+	return e
+}
+
+// WillReturnError makes the call fail.
+func (e *ExpectedGetObjects) WillReturnError(err error) *ExpectedGetObjects {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedGetObjects) String() string {
+	var filters []string
+	if e.depth != nil {
+		filters = append(filters, "depth "+depthName(*e.depth))
+	}
+	for _, f := range []struct {
+		name string
+		val  *string
+	}{{"catalog", e.catalog}, {"db schema", e.dbSchema}, {"table", e.tableName}, {"column", e.colName}} {
+		if f.val != nil {
+			filters = append(filters, fmt.Sprintf("%s '%s'", f.name, *f.val))
+		}
+	}
+	if e.tableTypes != nil {
+		filters = append(filters, fmt.Sprintf("table types %v", e.tableTypes))
+	}
+	if len(filters) == 0 {
+		return "ExpectedGetObjects"
+	}
+	return "ExpectedGetObjects with " + strings.Join(filters, ", ")
+}
+
+func depthName(d adbc.ObjectDepth) string {
+	switch d {
+	case adbc.ObjectDepthAll:
+		return "all"
+	case adbc.ObjectDepthCatalogs:
+		return "catalogs"
+	case adbc.ObjectDepthDBSchemas:
+		return "db schemas"
+	case adbc.ObjectDepthTables:
+		return "tables"
+	}
+	return fmt.Sprintf("ObjectDepth(%d)", int(d))
+}
+
+// ExpectedGetTableSchema is the expectation of a call to GetTableSchema
+type ExpectedGetTableSchema struct {
+	commonExpectation
+	catalog, dbSchema *string
+	table             string
+	schema            *arrow.Schema
+}
+
+// ExpectGetTableSchema expects the schema of the table to be requested.
+func (m *Mock) ExpectGetTableSchema(table string) *ExpectedGetTableSchema {
+	e := &ExpectedGetTableSchema{table: table}
+	m.expect(e)
+	return e
+}
+
+// WithCatalog expects the table to be qualified by the catalog.
+func (e *ExpectedGetTableSchema) WithCatalog(catalog string) *ExpectedGetTableSchema {
+	e.catalog = &catalog
+	return e
+}
+
+// WithDBSchema expects the table to be qualified by the db schema.
+func (e *ExpectedGetTableSchema) WithDBSchema(dbSchema string) *ExpectedGetTableSchema {
+	e.dbSchema = &dbSchema
+	return e
+}
+
+// WillReturnSchema sets the schema returned.
+func (e *ExpectedGetTableSchema) WillReturnSchema(sc *arrow.Schema) *ExpectedGetTableSchema {
+	e.schema = sc
+	return e
+}
+
+// WillReturnError makes the call fail.
+func (e *ExpectedGetTableSchema) WillReturnError(err error) *ExpectedGetTableSchema {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedGetTableSchema) String() string {
+	return "ExpectedGetTableSchema " + e.table
+}
+
+// ExpectedGetTableTypes is the expectation of a call to GetTableTypes
+type ExpectedGetTableTypes struct {
+	commonExpectation
+	rows array.RecordReader
+}
+
+// ExpectGetTableTypes expects the table types to be requested.
+func (m *Mock) ExpectGetTableTypes() *ExpectedGetTableTypes {
+	e := &ExpectedGetTableTypes{}
+	m.expect(e)
+	return e
+}
+
+// WillReturnRows sets the result, which must follow
+// adbc.TableTypesSchema. Ownership of the reader is passed to the
+// caller.
+func (e *ExpectedGetTableTypes) WillReturnRows(rdr array.RecordReader) *ExpectedGetTableTypes {
+	e.rows = rdr
+	return e
+}
+
+// WillReturnError makes the call fail.
+func (e *ExpectedGetTableTypes) WillReturnError(err error) *ExpectedGetTableTypes {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedGetTableTypes) String() string { return "ExpectedGetTableTypes" }
@@ -0,0 +1,213 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package adbcmock provides a mock implementation of the adbc.Driver,
+// adbc.Database, adbc.Connection and adbc.Statement interfaces, to unit
+// test code using ADBC without a real database.
+//
+// In the style of sqlmock, a test registers the calls it expects along
+// with canned results, runs the code under test against the mock and
+// then checks that every expectation was met:
+//
+//	mock := adbcmock.New()
+//	mock.ExpectQuery(`SELECT \* FROM users WHERE id = \?`).
+//		WithArgs(params).
+//		WillReturnRows(rdr)
+//
+//	db, _ := mock.NewDatabase(nil)
+//	// ... run the code under test with db ...
+//
+//	if err := mock.ExpectationsWereMet(); err != nil {
+//		t.Error(err)
+//	}
+//
+// Calls which create or close databases, connections and statements,
+// as well as setting the query, bulk ingestion options or parameters of
+// a statement, need no expectation. Every other call must match the
+// next expectation, or any unmet expectation if the order is relaxed
+// with MatchExpectationsInOrder, and fails otherwise.
+package adbcmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// QueryMatcher reports whether an executed query matches the expected
+// one, returning an error describing the mismatch if not.
+type QueryMatcher func(expected, actual string) error
+
+// QueryMatcherRegexp matches queries against the expected query as a
+// regular expression. It is the default matcher.
+func QueryMatcherRegexp(expected, actual string) error {
+	re, err := regexp.Compile(expected)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(actual) {
+		return fmt.Errorf("query '%s' does not match regex '%s'", actual, expected)
+	}
+	return nil
+}
+
+// QueryMatcherEqual matches queries which are equal to the expected
+// query, ignoring leading and trailing whitespace.
+func QueryMatcherEqual(expected, actual string) error {
+	if strings.TrimSpace(expected) != strings.TrimSpace(actual) {
+		return fmt.Errorf("query '%s' is not equal to '%s'", actual, expected)
+	}
+	return nil
+}
+
+// Mock is an adbc.Driver whose databases, connections and statements
+// check the calls made to them against the registered expectations.
+//
+// It is safe for concurrent use.
+type Mock struct {
+	// QueryMatcher compares executed queries with expected ones
+	QueryMatcher QueryMatcher
+
+	mu       sync.Mutex
+	ordered  bool
+	expected []expectation
+}
+
+// New returns a mock expecting calls in order and matching queries as
+// regular expressions.
+func New() *Mock {
+	return &Mock{
+		QueryMatcher: QueryMatcherRegexp,
+		ordered:      true,
+	}
+}
+
+// MatchExpectationsInOrder sets whether calls must happen in the order
+// they were expected, which is the default, or may match any expectation
+// not met yet.
+func (m *Mock) MatchExpectationsInOrder(ordered bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = ordered
+}
+
+// ExpectationsWereMet returns an error describing the first expectation
+// that no call matched.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expected {
+		if !e.fulfilled() {
+			return fmt.Errorf("there is a remaining expectation which was not matched: %s", e)
+		}
+	}
+	return nil
+}
+
+func (m *Mock) expect(e expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = append(m.expected, e)
+}
+
+// errMismatch is returned by a matching function when an expectation is
+// for a different kind of call
+var errMismatch = fmt.Errorf("not the expected kind of call")
+
+// match finds the expectation for a call and marks it as met. The
+// matches function checks a candidate expectation, returning
+// errMismatch if it is for another kind of call, or an error describing
+// how the call differs from it.
+//
+// Calls which are optional do not fail if they match no expectation,
+// in which case match returns nil.
+func (m *Mock) match(call string, optional bool, matches func(expectation) error) (expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.expected {
+		if e.fulfilled() {
+			continue
+		}
+
+		err := matches(e)
+		if err == nil {
+			e.trigger()
+			return e, nil
+		}
+		if !m.ordered {
+			continue
+		}
+
+		if optional && err == errMismatch {
+			return nil, nil
+		}
+		if err == errMismatch {
+			return nil, mockError("call to %s was not expected, next expectation is: %s", call, e)
+		}
+		return nil, mockError("call to %s does not match the next expectation %s: %s", call, e, err)
+	}
+
+	if optional {
+		return nil, nil
+	}
+	return nil, mockError("call to %s was not expected", call)
+}
+
+func mockError(format string, args ...interface{}) error {
+	return adbc.Error{
+		Msg:  "[adbcmock] " + fmt.Sprintf(format, args...),
+		Code: adbc.StatusInternal,
+	}
+}
+
+// matchArgs compares bound parameters or ingested data with the
+// expected records, regardless of how the rows are split into batches.
+func (m *Mock) matchArgs(expected, actual []arrow.Record) error {
+	var expectedRows, actualRows int64
+	for _, r := range expected {
+		expectedRows += r.NumRows()
+	}
+	for _, r := range actual {
+		actualRows += r.NumRows()
+	}
+	if expectedRows != actualRows {
+		return fmt.Errorf("expected %d rows of arguments, got %d", expectedRows, actualRows)
+	}
+	if len(expected) == 0 || len(actual) == 0 {
+		return nil
+	}
+
+	if !expected[0].Schema().Equal(actual[0].Schema()) {
+		return fmt.Errorf("expected arguments with schema %s, got %s", expected[0].Schema(), actual[0].Schema())
+	}
+
+	left := array.NewTableFromRecords(expected[0].Schema(), expected)
+	defer left.Release()
+	right := array.NewTableFromRecords(actual[0].Schema(), actual)
+	defer right.Release()
+	if !array.TableEqual(left, right) {
+		return fmt.Errorf("arguments do not match the expected records")
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package adbcmock_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/adbcmock"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTests struct {
+	suite.Suite
+
+	mem  *memory.CheckedAllocator
+	ctx  context.Context
+	mock *adbcmock.Mock
+	cnxn adbc.Connection
+	stmt adbc.Statement
+}
+
+func (s *MockTests) SetupTest() {
+	s.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	s.ctx = context.Background()
+	s.mock = adbcmock.New()
+
+	db, err := s.mock.NewDatabase(nil)
+	s.Require().NoError(err)
+	s.cnxn, err = db.Open(s.ctx)
+	s.Require().NoError(err)
+	s.stmt, err = s.cnxn.NewStatement()
+	s.Require().NoError(err)
+}
+
+func (s *MockTests) TearDownTest() {
+	s.NoError(s.stmt.Close())
+	s.NoError(s.cnxn.Close())
+	s.mem.AssertSize(s.T(), 0)
+}
+
+var intsSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "ints", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+}, nil)
+
+func (s *MockTests) ints(data string) arrow.Record {
+	rec, _, err := array.RecordFromJSON(s.mem, intsSchema, strings.NewReader(data))
+	s.Require().NoError(err)
+	return rec
+}
+
+func (s *MockTests) reader(recs ...arrow.Record) array.RecordReader {
+	rdr, err := array.NewRecordReader(intsSchema, recs)
+	s.Require().NoError(err)
+	return rdr
+}
+
+func (s *MockTests) requireStatus(err error, code adbc.Status) {
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(code, adbcErr.Code, adbcErr.Msg)
+}
+
+func (s *MockTests) TestQuery() {
+	params := s.ints(`[{"ints": 42}]`)
+	defer params.Release()
+	result := s.ints(`[{"ints": 1}, {"ints": 2}]`)
+	defer result.Release()
+
+	s.mock.ExpectQuery(`SELECT \* FROM t WHERE id = \?`).
+		WithArgs(params).
+		WillReturnRows(s.reader(result))
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT * FROM t WHERE id = ?"))
+	s.NoError(s.stmt.Bind(s.ctx, params))
+	rdr, n, err := s.stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	s.EqualValues(-1, n)
+	s.True(rdr.Next())
+	s.True(array.RecordEqual(result, rdr.Record()))
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MockTests) TestQueryMismatch() {
+	params := s.ints(`[{"ints": 42}]`)
+	defer params.Release()
+	other := s.ints(`[{"ints": 43}]`)
+	defer other.Release()
+
+	s.mock.ExpectQuery("SELECT 1")
+	s.mock.ExpectQuery("SELECT ?").WithArgs(params)
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT 2"))
+	_, _, err := s.stmt.ExecuteQuery(s.ctx)
+	s.requireStatus(err, adbc.StatusInternal)
+	s.Contains(err.Error(), "ExpectedQuery 'SELECT 1'")
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT 1"))
+	rdr, _, err := s.stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	rdr.Release()
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT ?"))
+	s.NoError(s.stmt.Bind(s.ctx, other))
+	_, _, err = s.stmt.ExecuteQuery(s.ctx)
+	s.requireStatus(err, adbc.StatusInternal)
+	s.Contains(err.Error(), "arguments do not match")
+
+	err = s.mock.ExpectationsWereMet()
+	s.Error(err)
+	s.Contains(err.Error(), "ExpectedQuery 'SELECT ?' with 1 rows of arguments")
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT 3"))
+	_, _, err = s.stmt.ExecuteQuery(s.ctx)
+	s.requireStatus(err, adbc.StatusInternal)
+}
+
+func (s *MockTests) TestQueryMatcherEqual() {
+	s.mock.QueryMatcher = adbcmock.QueryMatcherEqual
+	s.mock.ExpectExec("UPDATE t SET x = x + 1").WillReturnResult(3)
+
+	s.NoError(s.stmt.SetSqlQuery("UPDATE t SET x = x + 1\n"))
+	n, err := s.stmt.ExecuteUpdate(s.ctx)
+	s.NoError(err)
+	s.EqualValues(3, n)
+
+	s.mock.ExpectExec("SELECT .*")
+	s.NoError(s.stmt.SetSqlQuery("SELECT 1"))
+	_, err = s.stmt.ExecuteUpdate(s.ctx)
+	s.requireStatus(err, adbc.StatusInternal)
+}
+
+func (s *MockTests) TestUnordered() {
+	s.mock.MatchExpectationsInOrder(false)
+	s.mock.ExpectExec("INSERT").WillReturnResult(1)
+	s.mock.ExpectExec("DELETE").WillReturnResult(2)
+
+	s.NoError(s.stmt.SetSqlQuery("DELETE FROM t"))
+	n, err := s.stmt.ExecuteUpdate(s.ctx)
+	s.NoError(err)
+	s.EqualValues(2, n)
+
+	s.Error(s.mock.ExpectationsWereMet())
+
+	s.NoError(s.stmt.SetSqlQuery("INSERT INTO t VALUES (1)"))
+	n, err = s.stmt.ExecuteUpdate(s.ctx)
+	s.NoError(err)
+	s.EqualValues(1, n)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MockTests) TestTransactions() {
+	failed := errors.New("commit failed")
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec("INSERT")
+	s.mock.ExpectCommit().WillReturnError(failed)
+	s.mock.ExpectRollback()
+
+	opts := s.cnxn.(adbc.PostInitOptions)
+	s.NoError(opts.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+	s.NoError(s.stmt.SetSqlQuery("INSERT INTO t VALUES (1)"))
+	_, err := s.stmt.ExecuteUpdate(s.ctx)
+	s.NoError(err)
+	s.ErrorIs(s.cnxn.Commit(s.ctx), failed)
+	s.NoError(s.cnxn.Rollback(s.ctx))
+	s.NoError(s.mock.ExpectationsWereMet())
+
+	s.requireStatus(s.cnxn.Commit(s.ctx), adbc.StatusInternal)
+}
+
+func (s *MockTests) TestOptions() {
+	s.mock.ExpectNewDatabase().WithOptions(map[string]string{adbc.OptionKeyURI: "mock://"})
+	s.mock.ExpectOpen().WillReturnError(adbc.Error{Code: adbc.StatusUnauthenticated})
+	s.mock.ExpectSetOption("batch_size", "10")
+	s.mock.ExpectSetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueEnabled)
+
+	_, err := s.mock.NewDatabase(map[string]string{adbc.OptionKeyURI: "other://"})
+	s.requireStatus(err, adbc.StatusInternal)
+	db, err := s.mock.NewDatabase(map[string]string{adbc.OptionKeyURI: "mock://"})
+	s.Require().NoError(err)
+
+	_, err = db.Open(s.ctx)
+	s.requireStatus(err, adbc.StatusUnauthenticated)
+
+	s.NoError(s.stmt.SetOption("batch_size", "10"))
+	s.requireStatus(s.stmt.SetOption("batch_size", "20"), adbc.StatusInternal)
+	s.NoError(s.cnxn.(adbc.PostInitOptions).SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueEnabled))
+	s.NoError(s.mock.ExpectationsWereMet())
+
+	// without expectations, databases and connections are created freely
+	db, err = s.mock.NewDatabase(nil)
+	s.Require().NoError(err)
+	cnxn, err := db.Open(s.ctx)
+	s.Require().NoError(err)
+	s.NoError(cnxn.Close())
+	s.requireStatus(cnxn.Close(), adbc.StatusInvalidState)
+}
+
+func (s *MockTests) TestIngest() {
+	first := s.ints(`[{"ints": 1}, {"ints": 2}]`)
+	defer first.Release()
+	second := s.ints(`[{"ints": 3}]`)
+	defer second.Release()
+	all := s.ints(`[{"ints": 1}, {"ints": 2}, {"ints": 3}]`)
+	defer all.Release()
+
+	s.mock.ExpectIngest("target").
+		WithDBSchema("public").
+		WithMode(adbc.OptionValueIngestModeAppend).
+		WithData(all)
+	s.mock.ExpectIngest("target").WillReturnError(adbc.Error{Code: adbc.StatusAlreadyExists})
+
+	s.NoError(s.stmt.SetOption(adbc.OptionKeyIngestTargetTable, "target"))
+	s.NoError(s.stmt.SetOption(adbc.OptionKeyIngestTargetDBSchema, "public"))
+	s.NoError(s.stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeAppend))
+
+	rdr := s.reader(first, second)
+	s.NoError(s.stmt.BindStream(s.ctx, rdr))
+	rdr.Release()
+	n, err := s.stmt.ExecuteUpdate(s.ctx)
+	s.NoError(err)
+	s.EqualValues(3, n)
+
+	s.NoError(s.stmt.Bind(s.ctx, first))
+	_, _, err = s.stmt.ExecuteQuery(s.ctx)
+	s.requireStatus(err, adbc.StatusAlreadyExists)
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MockTests) TestPrepare() {
+	paramSchema := arrow.NewSchema([]arrow.Field{{Name: "0", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+	s.mock.ExpectPrepare("SELECT").WillReturnParameterSchema(paramSchema)
+	s.mock.ExpectQuery("SELECT")
+
+	_, err := s.stmt.GetParameterSchema()
+	s.requireStatus(err, adbc.StatusInvalidState)
+	s.requireStatus(s.stmt.Prepare(s.ctx), adbc.StatusInvalidState)
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT ?"))
+	s.NoError(s.stmt.Prepare(s.ctx))
+	sc, err := s.stmt.GetParameterSchema()
+	s.NoError(err)
+	s.True(paramSchema.Equal(sc))
+
+	rdr, _, err := s.stmt.ExecuteQuery(s.ctx)
+	s.NoError(err)
+	s.False(rdr.Next())
+	rdr.Release()
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MockTests) TestMetadata() {
+	tables := "tab%"
+	s.mock.ExpectGetObjects().WithDepth(adbc.ObjectDepthTables).WithTableName("tab%").WithTableTypes("view", "table")
+	s.mock.ExpectGetTableSchema("t").WithDBSchema("s").WillReturnSchema(intsSchema)
+	s.mock.ExpectGetInfo(adbc.InfoDriverName)
+	s.mock.ExpectGetTableTypes()
+
+	_, err := s.cnxn.GetObjects(s.ctx, adbc.ObjectDepthAll, nil, nil, &tables, nil, nil)
+	s.requireStatus(err, adbc.StatusInternal)
+	rdr, err := s.cnxn.GetObjects(s.ctx, adbc.ObjectDepthTables, nil, nil, &tables, nil, []string{"table", "view"})
+	s.Require().NoError(err)
+	s.True(adbc.GetObjectsSchema.Equal(rdr.Schema()))
+	rdr.Release()
+
+	dbSchema := "s"
+	sc, err := s.cnxn.GetTableSchema(s.ctx, nil, &dbSchema, "t")
+	s.NoError(err)
+	s.True(intsSchema.Equal(sc))
+
+	rdr, err = s.cnxn.GetInfo(s.ctx, []adbc.InfoCode{adbc.InfoDriverName})
+	s.Require().NoError(err)
+	s.True(adbc.GetInfoSchema.Equal(rdr.Schema()))
+	rdr.Release()
+
+	rdr, err = s.cnxn.GetTableTypes(s.ctx)
+	s.Require().NoError(err)
+	s.True(adbc.TableTypesSchema.Equal(rdr.Schema()))
+	rdr.Release()
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func (s *MockTests) TestPartitions() {
+	result := s.ints(`[{"ints": 7}]`)
+	defer result.Release()
+
+	partitions := adbc.Partitions{NumPartitions: 2, PartitionIDs: [][]byte{{1}, {2}}}
+	s.mock.ExpectExecutePartitions("SELECT").WillReturnPartitions(intsSchema, partitions, 1)
+	s.mock.ExpectReadPartition([]byte{1}).WillReturnRows(s.reader(result))
+	s.mock.ExpectReadPartition(nil)
+
+	s.NoError(s.stmt.SetSqlQuery("SELECT ints FROM t"))
+	sc, parts, n, err := s.stmt.ExecutePartitions(s.ctx)
+	s.Require().NoError(err)
+	s.True(intsSchema.Equal(sc))
+	s.Equal(partitions, parts)
+	s.EqualValues(1, n)
+
+	rdr, err := s.cnxn.ReadPartition(s.ctx, parts.PartitionIDs[0])
+	s.Require().NoError(err)
+	s.True(rdr.Next())
+	s.True(array.RecordEqual(result, rdr.Record()))
+	rdr.Release()
+
+	rdr, err = s.cnxn.ReadPartition(s.ctx, parts.PartitionIDs[1])
+	s.Require().NoError(err)
+	rdr.Release()
+	s.NoError(s.mock.ExpectationsWereMet())
+}
+
+func TestMock(t *testing.T) {
+	suite.Run(t, &MockTests{})
+}
+
+// countUsers is an example of code under test
+func countUsers(ctx context.Context, cnxn adbc.Connection) (int64, error) {
+	stmt, err := cnxn.NewStatement()
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SetSqlQuery("SELECT count(*) FROM users"); err != nil {
+		return 0, err
+	}
+	rdr, _, err := stmt.ExecuteQuery(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer rdr.Release()
+
+	if !rdr.Next() {
+		return 0, rdr.Err()
+	}
+	return rdr.Record().Column(0).(*array.Int64).Value(0), nil
+}
+
+func Example() {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "count", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	rec, _, _ := array.RecordFromJSON(memory.DefaultAllocator, sc, strings.NewReader(`[{"count": 3}]`))
+	defer rec.Release()
+	rdr, _ := array.NewRecordReader(sc, []arrow.Record{rec})
+
+	mock := adbcmock.New()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM users`).WillReturnRows(rdr)
+
+	db, _ := mock.NewDatabase(nil)
+	cnxn, _ := db.Open(context.Background())
+	defer cnxn.Close()
+
+	n, err := countUsers(context.Background(), cnxn)
+	fmt.Println(n, err)
+	fmt.Println(mock.ExpectationsWereMet())
+
+	// Output:
+	// 3 <nil>
+	// <nil>
+}
@@ -0,0 +1,320 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cache wraps the connections of any ADBC driver to cache the
+// results of the metadata calls GetObjects, GetTableSchema and
+// GetTableTypes, which can run several queries each, for example in
+// the snowflake driver.
+//
+//	c := cache.New(mem, cache.Options{
+//		ObjectsTTL:      5 * time.Minute,
+//		TableSchemaTTL:  time.Minute,
+//		InvalidateOnDDL: true,
+//	})
+//	drv := cache.WrapDriver(snowflake.Driver{Alloc: mem}, c)
+//
+// GetObjects results are cached by their filters, and a call is served
+// from the result of a call with the same filters down to the same or
+// a greater depth, cut off at the requested depth. A Cache may be
+// shared by connections
+// to the same database with the same defaults: GetTableSchema results
+// for a table outside of the current catalog or schema are cached by
+// the given names.
+//
+// The cached results are dropped when their TTL runs out, on a call to
+// Invalidate, and with InvalidateOnDDL, when a connection runs DDL or
+// bulk ingestion.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// Options configures what a Cache caches and for how long.
+type Options struct {
+	// ObjectsTTL, TableSchemaTTL and TableTypesTTL are how long the
+	// results of GetObjects, GetTableSchema and GetTableTypes are
+	// cached for. The results of a call with a zero TTL are not cached.
+	ObjectsTTL, TableSchemaTTL, TableTypesTTL time.Duration
+	// MaxTableSchemas is the maximum number of table schemas cached,
+	// the least recently used are dropped first. Zero means no limit.
+	MaxTableSchemas int
+	// MaxObjectsBytes is the maximum size of the GetObjects results
+	// cached, in bytes of their JSON encoding. Larger results are not
+	// cached. Zero means no limit.
+	MaxObjectsBytes int
+	// InvalidateOnDDL drops all cached results when a statement runs
+	// DDL (CREATE, ALTER, DROP, RENAME, COMMENT or USE) or bulk
+	// ingestion, and when a transaction which did is rolled back.
+	InvalidateOnDDL bool
+}
+
+// ddlPattern matches the queries which may change the metadata, with
+// any of their statements doing so if there are several, after any
+// comments starting them
+var ddlPattern = regexp.MustCompile(`(?i)(^|;)(\s|--[^\n]*(\n|$)|/\*(?s:.*?)\*/)*(CREATE|ALTER|DROP|RENAME|COMMENT|USE)\b`)
+
+type schemaKey struct {
+	catalog, dbSchema string
+	// hasCatalog and hasDBSchema tell a nil name from an empty one
+	hasCatalog, hasDBSchema bool
+	table                   string
+}
+
+type schemaEntry struct {
+	key     schemaKey
+	schema  *arrow.Schema
+	expires time.Time
+}
+
+// objectsKey identifies the cached GetObjects results
+type objectsKey struct {
+	depth adbc.ObjectDepth
+	// filters is the encoding of the filters of the call
+	filters string
+}
+
+type objectsEntry struct {
+	catalogs []catalogInfo
+	expires  time.Time
+}
+
+// Cache holds the metadata cached for the connections wrapping it. It
+// is safe for concurrent use.
+type Cache struct {
+	alloc memory.Allocator
+	opts  Options
+
+	mu sync.Mutex
+	// gen is incremented on invalidation, so that results fetched
+	// before are not stored
+	gen uint64
+	// objects are the GetObjects results by depth and filters
+	objects map[objectsKey]objectsEntry
+	// schemas is a list of *schemaEntry, most recently used first
+	schemas      *list.List
+	schemaLookup map[schemaKey]*list.Element
+	tableTypes   []arrow.Record
+	typesSchema  *arrow.Schema
+	typesExpires time.Time
+}
+
+// New returns an empty cache allocating the results it serves with
+// alloc, or the default allocator if nil.
+func New(alloc memory.Allocator, opts Options) *Cache {
+	if alloc == nil {
+		alloc = memory.DefaultAllocator
+	}
+	return &Cache{
+		alloc:        alloc,
+		opts:         opts,
+		objects:      make(map[objectsKey]objectsEntry),
+		schemas:      list.New(),
+		schemaLookup: make(map[schemaKey]*list.Element),
+	}
+}
+
+// Invalidate drops all cached results.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.objects = make(map[objectsKey]objectsEntry)
+	c.schemas.Init()
+	c.schemaLookup = make(map[schemaKey]*list.Element)
+	c.releaseTableTypes()
+}
+
+func (c *Cache) releaseTableTypes() {
+	for _, rec := range c.tableTypes {
+		rec.Release()
+	}
+	c.tableTypes, c.typesSchema = nil, nil
+}
+
+func (c *Cache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// depthLevel orders the depths from the shallowest
+func depthLevel(depth adbc.ObjectDepth) int {
+	if depth == adbc.ObjectDepthAll {
+		return int(adbc.ObjectDepthTables) + 1
+	}
+	return int(depth)
+}
+
+// getObjects returns the objects matching the filters down to at least
+// depth, fetching them with fetch if they are not cached
+func (c *Cache) getObjects(depth adbc.ObjectDepth, filters string, fetch func() (array.RecordReader, error)) ([]catalogInfo, error) {
+	if c.opts.ObjectsTTL > 0 {
+		c.mu.Lock()
+		now := time.Now()
+		for k, e := range c.objects {
+			if now.After(e.expires) {
+				delete(c.objects, k)
+			} else if k.filters == filters && depthLevel(k.depth) >= depthLevel(depth) {
+				c.mu.Unlock()
+				return e.catalogs, nil
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	gen := c.generation()
+	rdr, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+
+	var buf bytes.Buffer
+	for rdr.Next() {
+		if err := array.RecordToJSON(rdr.Record(), &buf); err != nil {
+			return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+		}
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, err
+	}
+
+	size := buf.Len()
+	catalogs, err := decodeObjects(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.opts.ObjectsTTL > 0 && (c.opts.MaxObjectsBytes == 0 || size <= c.opts.MaxObjectsBytes) {
+		c.mu.Lock()
+		if c.gen == gen {
+			c.objects[objectsKey{depth: depth, filters: filters}] = objectsEntry{catalogs: catalogs, expires: time.Now().Add(c.opts.ObjectsTTL)}
+		}
+		c.mu.Unlock()
+	}
+	return catalogs, nil
+}
+
+func (c *Cache) getTableSchema(key schemaKey, fetch func() (*arrow.Schema, error)) (*arrow.Schema, error) {
+	if c.opts.TableSchemaTTL <= 0 {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.schemaLookup[key]; ok {
+		e := elem.Value.(*schemaEntry)
+		if time.Now().Before(e.expires) {
+			c.schemas.MoveToFront(elem)
+			c.mu.Unlock()
+			return e.schema, nil
+		}
+		c.schemas.Remove(elem)
+		delete(c.schemaLookup, key)
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	sc, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return sc, nil
+	}
+	if elem, ok := c.schemaLookup[key]; ok {
+		c.schemas.Remove(elem)
+	}
+	c.schemaLookup[key] = c.schemas.PushFront(&schemaEntry{
+		key: key, schema: sc, expires: time.Now().Add(c.opts.TableSchemaTTL)})
+	for c.opts.MaxTableSchemas > 0 && c.schemas.Len() > c.opts.MaxTableSchemas {
+		oldest := c.schemas.Back()
+		delete(c.schemaLookup, oldest.Value.(*schemaEntry).key)
+		c.schemas.Remove(oldest)
+	}
+	return sc, nil
+}
+
+func (c *Cache) getTableTypes(fetch func() (array.RecordReader, error)) (array.RecordReader, error) {
+	if c.opts.TableTypesTTL <= 0 {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	if c.typesSchema != nil {
+		if time.Now().Before(c.typesExpires) {
+			defer c.mu.Unlock()
+			return newReader(c.typesSchema, c.tableTypes)
+		}
+		c.releaseTableTypes()
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	rdr, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+
+	var recs []arrow.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen == gen {
+		c.releaseTableTypes()
+		for _, rec := range recs {
+			rec.Retain()
+		}
+		c.tableTypes, c.typesSchema = append([]arrow.Record(nil), recs...), rdr.Schema()
+		c.typesExpires = time.Now().Add(c.opts.TableTypesTTL)
+	}
+	return newReader(rdr.Schema(), recs)
+}
+
+func newReader(sc *arrow.Schema, recs []arrow.Record) (array.RecordReader, error) {
+	rdr, err := array.NewRecordReader(sc, recs)
+	if err != nil {
+		return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+	}
+	return rdr, nil
+}
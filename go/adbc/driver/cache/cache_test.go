@@ -0,0 +1,384 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/adbcmock"
+	"github.com/apache/arrow-adbc/go/adbc/driver/cache"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const objectsJSON = `[
+	{"catalog_name": "db", "catalog_db_schemas": [
+		{"db_schema_name": "public", "db_schema_tables": [
+			{"table_name": "users", "table_type": "TABLE", "table_constraints": [], "table_columns": [
+				{"column_name": "id", "ordinal_position": 1},
+				{"column_name": "name", "ordinal_position": 2, "remarks": "full name"}]},
+			{"table_name": "user_view", "table_type": "VIEW", "table_constraints": [], "table_columns": [
+				{"column_name": "name", "ordinal_position": 1}]}]},
+		{"db_schema_name": "audit", "db_schema_tables": []}]},
+	{"catalog_name": "other", "catalog_db_schemas": []}
+]`
+
+func open(t *testing.T, mock *adbcmock.Mock, c *cache.Cache) adbc.Connection {
+	db, err := cache.WrapDriver(mock, c).NewDatabase(nil)
+	require.NoError(t, err)
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, cnxn.Close()) })
+	return cnxn
+}
+
+// flatten lists the paths of the objects in a GetObjects result, a
+// list cut off by the depth shows as "-"
+func flatten(t *testing.T, rdr array.RecordReader) []string {
+	defer rdr.Release()
+	var buf bytes.Buffer
+	for rdr.Next() {
+		require.NoError(t, array.RecordToJSON(rdr.Record(), &buf))
+	}
+	require.NoError(t, rdr.Err())
+
+	var out []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var cat struct {
+			Name    string `json:"catalog_name"`
+			Schemas []struct {
+				Name   string `json:"db_schema_name"`
+				Tables []struct {
+					Name    string `json:"table_name"`
+					Columns []struct {
+						Name    string  `json:"column_name"`
+						Remarks *string `json:"remarks"`
+					} `json:"table_columns"`
+				} `json:"db_schema_tables"`
+			} `json:"catalog_db_schemas"`
+		}
+		require.NoError(t, dec.Decode(&cat))
+		out = append(out, cat.Name)
+		if cat.Schemas == nil {
+			out = append(out, cat.Name+".-")
+		}
+		for _, sc := range cat.Schemas {
+			path := cat.Name + "." + sc.Name
+			out = append(out, path)
+			if sc.Tables == nil {
+				out = append(out, path+".-")
+			}
+			for _, tbl := range sc.Tables {
+				path := path + "." + tbl.Name
+				out = append(out, path)
+				if tbl.Columns == nil {
+					out = append(out, path+".-")
+				}
+				for _, col := range tbl.Columns {
+					if col.Remarks != nil {
+						out = append(out, path+"."+col.Name+" "+*col.Remarks)
+					} else {
+						out = append(out, path+"."+col.Name)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestGetObjectsFiltered(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	superset, _, err := array.RecordFromJSON(mem, adbc.GetObjectsSchema, strings.NewReader(objectsJSON))
+	require.NoError(t, err)
+	defer superset.Release()
+	// the mock doesn't filter, the results are filtered again in the
+	// cache as the drivers would
+	rows := func() array.RecordReader {
+		rdr, err := array.NewRecordReader(adbc.GetObjectsSchema, []arrow.Record{superset})
+		require.NoError(t, err)
+		return rdr
+	}
+
+	mock := adbcmock.New()
+	mock.ExpectGetObjects().WithDepth(adbc.ObjectDepthAll).WillReturnRows(rows())
+	mock.ExpectGetObjects().WithDepth(adbc.ObjectDepthDBSchemas).WithCatalog("D%").WillReturnRows(rows())
+	mock.ExpectGetObjects().WithDepth(adbc.ObjectDepthTables).WithDBSchema("public").WithTableName("user%").
+		WithTableTypes("VIEW").WillReturnRows(rows())
+	mock.ExpectGetObjects().WithDepth(adbc.ObjectDepthColumns).WithTableName("users").WithColumnName("i_").
+		WillReturnRows(rows())
+	cnxn := open(t, mock, cache.New(mem, cache.Options{ObjectsTTL: time.Hour}))
+	ctx := context.Background()
+
+	all, err := cnxn.GetObjects(ctx, adbc.ObjectDepthAll, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"db", "db.public", "db.public.users", "db.public.users.id", "db.public.users.name full name",
+		"db.public.user_view", "db.public.user_view.name", "db.audit", "other",
+	}, flatten(t, all))
+
+	schemas, err := cnxn.GetObjects(ctx, adbc.ObjectDepthDBSchemas, strPtr("D%"), nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "db.public", "db.public.-", "db.audit", "db.audit.-"}, flatten(t, schemas))
+
+	tables, err := cnxn.GetObjects(ctx, adbc.ObjectDepthTables, nil, strPtr("public"), strPtr("user%"), nil, []string{"VIEW"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "db.public", "db.public.user_view", "db.public.user_view.-", "other"},
+		flatten(t, tables))
+
+	columns, err := cnxn.GetObjects(ctx, adbc.ObjectDepthColumns, nil, nil, strPtr("users"), strPtr("i_"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "db.public", "db.public.users", "db.public.users.id", "db.audit", "other"},
+		flatten(t, columns))
+
+	// served from the cache, the same filters down to a greater depth,
+	// and no table types being no filter
+	tables, err = cnxn.GetObjects(ctx, adbc.ObjectDepthTables, nil, strPtr("public"), strPtr("user%"), nil, []string{"VIEW"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "db.public", "db.public.user_view", "db.public.user_view.-", "other"},
+		flatten(t, tables))
+	catalogs, err := cnxn.GetObjects(ctx, adbc.ObjectDepthCatalogs, nil, nil, nil, nil, []string{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "db.-", "other", "other.-"}, flatten(t, catalogs))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTableSchemaTTLAndLimit(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	mock := adbcmock.New()
+	for _, table := range []string{"a", "a", "b", "a"} {
+		mock.ExpectGetTableSchema(table).WillReturnSchema(sc)
+	}
+	cnxn := open(t, mock, cache.New(nil, cache.Options{TableSchemaTTL: 50 * time.Millisecond, MaxTableSchemas: 1}))
+	ctx := context.Background()
+
+	get := func(table string) {
+		out, err := cnxn.GetTableSchema(ctx, nil, nil, table)
+		require.NoError(t, err)
+		assert.True(t, sc.Equal(out))
+	}
+
+	get("a")
+	get("a")
+	time.Sleep(100 * time.Millisecond)
+	get("a")
+	get("a")
+	// b evicts a
+	get("b")
+	get("a")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidation(t *testing.T) {
+	mock := adbcmock.New()
+	mock.ExpectGetTableTypes()
+	mock.ExpectExec("CREATE TABLE t").WillReturnResult(0)
+	mock.ExpectGetTableTypes()
+	mock.ExpectExec("INSERT INTO t").WillReturnResult(1)
+	mock.ExpectExec("DROP TABLE t").WillReturnResult(0)
+	mock.ExpectGetTableTypes()
+	mock.ExpectExec("DROP TABLE t").WillReturnResult(0)
+	mock.ExpectGetTableTypes()
+	mock.ExpectGetTableTypes()
+	c := cache.New(nil, cache.Options{TableTypesTTL: time.Hour, InvalidateOnDDL: true})
+	cnxn := open(t, mock, c)
+	ctx := context.Background()
+
+	tableTypes := func() {
+		rdr, err := cnxn.GetTableTypes(ctx)
+		require.NoError(t, err)
+		rdr.Release()
+	}
+	exec := func(query string) {
+		stmt, err := cnxn.NewStatement()
+		require.NoError(t, err)
+		defer stmt.Close()
+		require.NoError(t, stmt.SetSqlQuery(query))
+		_, err = stmt.ExecuteUpdate(ctx)
+		require.NoError(t, err)
+	}
+
+	tableTypes()
+	tableTypes()
+	exec("CREATE TABLE t (a INT)")
+	tableTypes()
+	// not DDL
+	exec("INSERT INTO t VALUES (1)")
+	tableTypes()
+	// DDL after comments
+	exec("-- drop it\n  DROP TABLE t")
+	tableTypes()
+	exec("/* drop it\n */ /**/ DROP TABLE t")
+	tableTypes()
+	c.Invalidate()
+	tableTypes()
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostInitOptions(t *testing.T) {
+	cnxn := open(t, adbcmock.New(), cache.New(nil, cache.Options{}))
+	_, ok := cnxn.(adbc.PostInitOptions)
+	assert.True(t, ok)
+
+	wrapped := cache.WrapConnection(struct{ adbc.Connection }{cnxn}, cache.New(nil, cache.Options{}))
+	assertNotImplemented(t, wrapped.(adbc.PostInitOptions).SetOption("key", "value"))
+}
+
+func assertNotImplemented(t *testing.T, err error) {
+	t.Helper()
+	var adbcErr adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+type withStatistics struct {
+	adbc.Connection
+}
+
+func (withStatistics) GetStatistics(context.Context, *string, *string, *string, bool) (array.RecordReader, error) {
+	return nil, adbc.Error{Code: adbc.StatusNotFound}
+}
+
+func (withStatistics) GetStatisticNames(context.Context) (array.RecordReader, error) {
+	return nil, adbc.Error{Code: adbc.StatusNotFound}
+}
+
+func TestGetStatistics(t *testing.T) {
+	cnxn := open(t, adbcmock.New(), cache.New(nil, cache.Options{}))
+	_, err := cnxn.(adbc.ConnectionGetStatistics).GetStatisticNames(context.Background())
+	assertNotImplemented(t, err)
+
+	wrapped := cache.WrapConnection(withStatistics{struct{ adbc.Connection }{cnxn}}, cache.New(nil, cache.Options{}))
+	assertNotImplemented(t, wrapped.(adbc.PostInitOptions).SetOption("key", "value"))
+	stats, ok := wrapped.(adbc.ConnectionGetStatistics)
+	require.True(t, ok)
+
+	// statistics are passed through, not cached
+	var adbcErr adbc.Error
+	_, err = stats.GetStatistics(context.Background(), nil, nil, nil, false)
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+	_, err = stats.GetStatisticNames(context.Background())
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+}
+
+type withExecuteSchema struct {
+	adbc.Connection
+}
+
+func (c withExecuteSchema) NewStatement() (adbc.Statement, error) {
+	stmt, err := c.Connection.NewStatement()
+	return schemaStatement{stmt}, err
+}
+
+type schemaStatement struct {
+	adbc.Statement
+}
+
+func (schemaStatement) ExecuteSchema(context.Context) (*arrow.Schema, error) {
+	return arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int64}}, nil), nil
+}
+
+func TestExecuteSchema(t *testing.T) {
+	cnxn := cache.WrapConnection(withExecuteSchema{open(t, adbcmock.New(), cache.New(nil, cache.Options{}))},
+		cache.New(nil, cache.Options{}))
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	es, ok := stmt.(adbc.StatementExecuteSchema)
+	require.True(t, ok)
+	sc, err := es.ExecuteSchema(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", sc.Field(0).Name)
+}
+
+type withExecuteMulti struct {
+	adbc.Connection
+}
+
+func (c withExecuteMulti) NewStatement() (adbc.Statement, error) {
+	stmt, err := c.Connection.NewStatement()
+	return multiStatement{stmt}, err
+}
+
+type multiStatement struct {
+	adbc.Statement
+}
+
+func (multiStatement) ExecuteMulti(context.Context) (adbc.MultiResult, error) {
+	return noResults{}, nil
+}
+
+type noResults struct{}
+
+func (noResults) NextResult(context.Context) (array.RecordReader, int64, error) {
+	return nil, -1, io.EOF
+}
+
+func (noResults) Close() error { return nil }
+
+func TestExecuteMulti(t *testing.T) {
+	mock := adbcmock.New()
+	mock.ExpectGetTableTypes()
+	mock.ExpectGetTableTypes()
+	c := cache.New(nil, cache.Options{TableTypesTTL: time.Hour, InvalidateOnDDL: true})
+	cnxn := cache.WrapConnection(withExecuteMulti{open(t, mock, cache.New(nil, cache.Options{}))}, c)
+	ctx := context.Background()
+
+	tableTypes := func() {
+		rdr, err := cnxn.GetTableTypes(ctx)
+		require.NoError(t, err)
+		rdr.Release()
+	}
+
+	tableTypes()
+	tableTypes()
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.(adbc.StatementExecuteSchema).ExecuteSchema(ctx)
+	assertNotImplemented(t, err)
+
+	// a statement after the first one changes the metadata
+	require.NoError(t, stmt.SetSqlQuery("INSERT INTO t VALUES (1); CREATE TABLE u (a INT)"))
+	res, err := stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	require.NoError(t, err)
+	require.NoError(t, res.Close())
+
+	tableTypes()
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
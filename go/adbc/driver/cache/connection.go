@@ -0,0 +1,290 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"context"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type driver struct {
+	wrapped adbc.Driver
+	cache   *Cache
+}
+
+// WrapDriver returns a driver whose connections cache their metadata
+// in c. All the databases of the driver share the cache, so it should
+// only be used to connect to a single database.
+func WrapDriver(drv adbc.Driver, c *Cache) adbc.Driver {
+	return &driver{wrapped: drv, cache: c}
+}
+
+func (d *driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	db, err := d.wrapped.NewDatabase(opts)
+	if err != nil {
+		return nil, err
+	}
+	return WrapDatabase(db, d.cache), nil
+}
+
+type database struct {
+	wrapped adbc.Database
+	cache   *Cache
+}
+
+// WrapDatabase returns a database whose connections cache their
+// metadata in c.
+func WrapDatabase(db adbc.Database, c *Cache) adbc.Database {
+	return &database{wrapped: db, cache: c}
+}
+
+func (d *database) SetOptions(opts map[string]string) error {
+	return d.wrapped.SetOptions(opts)
+}
+
+func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
+	cnxn, err := d.wrapped.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return WrapConnection(cnxn, d.cache), nil
+}
+
+type cnxn struct {
+	wrapped adbc.Connection
+	cache   *Cache
+	// ddl is whether the current transaction ran DDL
+	ddl bool
+}
+
+// WrapConnection returns a connection caching its metadata in c. It
+// always satisfies adbc.PostInitOptions and adbc.ConnectionGetStatistics;
+// the calls fail with adbc.StatusNotImplemented if the wrapped
+// connection doesn't support them.
+func WrapConnection(cn adbc.Connection, c *Cache) adbc.Connection {
+	return &cnxn{wrapped: cn, cache: c}
+}
+
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	return c.wrapped.GetInfo(ctx, infoCodes)
+}
+
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (array.RecordReader, error) {
+	filter, err := newObjectsFilter(depth, catalog, dbSchema, tableName, columnName, tableType)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogs, err := c.cache.getObjects(depth, filter.key, func() (array.RecordReader, error) {
+		return c.wrapped.GetObjects(ctx, depth, catalog, dbSchema, tableName, columnName, tableType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objectsReader(c.cache.alloc, filter.apply(catalogs))
+}
+
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	key := schemaKey{table: tableName}
+	if catalog != nil {
+		key.catalog, key.hasCatalog = *catalog, true
+	}
+	if dbSchema != nil {
+		key.dbSchema, key.hasDBSchema = *dbSchema, true
+	}
+	return c.cache.getTableSchema(key, func() (*arrow.Schema, error) {
+		return c.wrapped.GetTableSchema(ctx, catalog, dbSchema, tableName)
+	})
+}
+
+func (c *cnxn) GetTableTypes(ctx context.Context) (array.RecordReader, error) {
+	return c.cache.getTableTypes(func() (array.RecordReader, error) {
+		return c.wrapped.GetTableTypes(ctx)
+	})
+}
+
+func (c *cnxn) Commit(ctx context.Context) error {
+	if err := c.wrapped.Commit(ctx); err != nil {
+		return err
+	}
+	c.ddl = false
+	return nil
+}
+
+func (c *cnxn) Rollback(ctx context.Context) error {
+	if err := c.wrapped.Rollback(ctx); err != nil {
+		return err
+	}
+	// the cache may hold the metadata of the rolled back DDL
+	if c.ddl {
+		c.cache.Invalidate()
+		c.ddl = false
+	}
+	return nil
+}
+
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	stmt, err := c.wrapped.NewStatement()
+	if err != nil {
+		return nil, err
+	}
+	return &statement{Statement: stmt, cnxn: c}, nil
+}
+
+func (c *cnxn) Close() error {
+	return c.wrapped.Close()
+}
+
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	return c.wrapped.ReadPartition(ctx, serializedPartition)
+}
+
+// ranDDL invalidates the cache after a statement ran DDL
+func (c *cnxn) ranDDL() {
+	if c.cache.opts.InvalidateOnDDL {
+		c.cache.Invalidate()
+		c.ddl = true
+	}
+}
+
+func (c *cnxn) SetOption(key, value string) error {
+	opts, ok := c.wrapped.(adbc.PostInitOptions)
+	if !ok {
+		return adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	return opts.SetOption(key, value)
+}
+
+// GetStatistics is passed through, statistics are not cached
+func (c *cnxn) GetStatistics(ctx context.Context, catalog, dbSchema, tableName *string, approximate bool) (array.RecordReader, error) {
+	stats, ok := c.wrapped.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return nil, adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	return stats.GetStatistics(ctx, catalog, dbSchema, tableName, approximate)
+}
+
+func (c *cnxn) GetStatisticNames(ctx context.Context) (array.RecordReader, error) {
+	stats, ok := c.wrapped.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return nil, adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	return stats.GetStatisticNames(ctx)
+}
+
+// statement watches for the DDL and bulk ingestion run through it
+type statement struct {
+	adbc.Statement
+	cnxn *cnxn
+
+	ddl    bool
+	ingest bool
+}
+
+func (s *statement) ExecuteSchema(ctx context.Context) (*arrow.Schema, error) {
+	es, ok := s.Statement.(adbc.StatementExecuteSchema)
+	if !ok {
+		return nil, adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	return es.ExecuteSchema(ctx)
+}
+
+// ExecuteMulti invalidates the cache once the statements are executed,
+// and again once their results are closed in case the driver runs
+// them as the results are read.
+func (s *statement) ExecuteMulti(ctx context.Context) (adbc.MultiResult, error) {
+	em, ok := s.Statement.(adbc.StatementExecuteMulti)
+	if !ok {
+		return nil, adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	res, err := em.ExecuteMulti(ctx)
+	if err != nil || !s.ddl {
+		return res, err
+	}
+	s.cnxn.ranDDL()
+	return &ddlResult{MultiResult: res, cnxn: s.cnxn}, nil
+}
+
+// ddlResult invalidates the cache when the results of a query running
+// DDL are closed
+type ddlResult struct {
+	adbc.MultiResult
+	cnxn *cnxn
+}
+
+func (r *ddlResult) Close() error {
+	err := r.MultiResult.Close()
+	r.cnxn.ranDDL()
+	return err
+}
+
+func (s *statement) SetOption(key, val string) error {
+	if err := s.Statement.SetOption(key, val); err != nil {
+		return err
+	}
+	if key == adbc.OptionKeyIngestTargetTable {
+		s.ingest = true
+	}
+	return nil
+}
+
+func (s *statement) SetSqlQuery(query string) error {
+	if err := s.Statement.SetSqlQuery(query); err != nil {
+		return err
+	}
+	s.ddl, s.ingest = ddlPattern.MatchString(query), false
+	return nil
+}
+
+func (s *statement) SetSubstraitPlan(plan []byte) error {
+	if err := s.Statement.SetSubstraitPlan(plan); err != nil {
+		return err
+	}
+	s.ddl, s.ingest = false, false
+	return nil
+}
+
+func (s *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	rdr, n, err := s.Statement.ExecuteQuery(ctx)
+	if err == nil && (s.ddl || s.ingest) {
+		s.cnxn.ranDDL()
+	}
+	return rdr, n, err
+}
+
+func (s *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	n, err := s.Statement.ExecuteUpdate(ctx)
+	if err == nil && (s.ddl || s.ingest) {
+		s.cnxn.ranDDL()
+	}
+	return n, err
+}
+
+var (
+	_ adbc.Driver                  = (*driver)(nil)
+	_ adbc.Database                = (*database)(nil)
+	_ adbc.Connection              = (*cnxn)(nil)
+	_ adbc.PostInitOptions         = (*cnxn)(nil)
+	_ adbc.ConnectionGetStatistics = (*cnxn)(nil)
+	_ adbc.Statement               = (*statement)(nil)
+	_ adbc.StatementExecuteSchema  = (*statement)(nil)
+	_ adbc.StatementExecuteMulti   = (*statement)(nil)
+)
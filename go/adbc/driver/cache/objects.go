@@ -0,0 +1,191 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/driver/internal"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// The GetObjects results are kept in their JSON form, decoded only as
+// far as needed to filter them, so that they are served back unchanged.
+// A nil slice is a null list, cut off by the depth.
+
+type catalogInfo struct {
+	Name      *string        `json:"catalog_name"`
+	DBSchemas []dbSchemaInfo `json:"catalog_db_schemas"`
+}
+
+type dbSchemaInfo struct {
+	Name   *string     `json:"db_schema_name"`
+	Tables []tableInfo `json:"db_schema_tables"`
+}
+
+type tableInfo struct {
+	Name        string            `json:"table_name"`
+	Type        string            `json:"table_type"`
+	Columns     []columnInfo      `json:"table_columns"`
+	Constraints []json.RawMessage `json:"table_constraints"`
+}
+
+type columnInfo map[string]json.RawMessage
+
+func (c columnInfo) name() string {
+	var name string
+	_ = json.Unmarshal(c["column_name"], &name)
+	return name
+}
+
+func decodeObjects(r io.Reader) ([]catalogInfo, error) {
+	var out []catalogInfo
+	dec := json.NewDecoder(r)
+	for {
+		var cat catalogInfo
+		if err := dec.Decode(&cat); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+		}
+		out = append(out, cat)
+	}
+}
+
+// objectsFilter selects the objects of a GetObjects call
+type objectsFilter struct {
+	depth                                 adbc.ObjectDepth
+	catalog, dbSchema, tableName, columns *regexp.Regexp
+	tableTypes                            map[string]bool
+	// key is the encoding of the filters, the results of calls with
+	// the same key only differing by their depth
+	key string
+}
+
+func newObjectsFilter(depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (f objectsFilter, err error) {
+	f.depth = depth
+	for _, p := range []struct {
+		pattern *string
+		re      **regexp.Regexp
+	}{{catalog, &f.catalog}, {dbSchema, &f.dbSchema}, {tableName, &f.tableName}, {columnName, &f.columns}} {
+		if *p.re, err = internal.PatternToRegexp(p.pattern); err != nil {
+			return f, adbc.Error{Msg: err.Error(), Code: adbc.StatusInvalidArgument}
+		}
+	}
+	var types []string
+	if len(tableType) > 0 {
+		f.tableTypes = make(map[string]bool)
+		for _, t := range tableType {
+			f.tableTypes[t] = true
+		}
+		types = append(types, tableType...)
+		sort.Strings(types)
+	}
+	key, err := json.Marshal([]interface{}{catalog, dbSchema, tableName, columnName, types})
+	if err != nil {
+		return f, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+	}
+	f.key = string(key)
+	return f, nil
+}
+
+func matches(re *regexp.Regexp, name *string) bool {
+	if re == nil {
+		return true
+	}
+	if name == nil {
+		return re.MatchString("")
+	}
+	return re.MatchString(*name)
+}
+
+func (f *objectsFilter) apply(catalogs []catalogInfo) []catalogInfo {
+	out := make([]catalogInfo, 0, len(catalogs))
+	for _, cat := range catalogs {
+		if !matches(f.catalog, cat.Name) {
+			continue
+		}
+		if f.depth == adbc.ObjectDepthCatalogs {
+			cat.DBSchemas = nil
+		} else {
+			cat.DBSchemas = f.dbSchemas(cat.DBSchemas)
+		}
+		out = append(out, cat)
+	}
+	return out
+}
+
+func (f *objectsFilter) dbSchemas(schemas []dbSchemaInfo) []dbSchemaInfo {
+	out := make([]dbSchemaInfo, 0, len(schemas))
+	for _, sc := range schemas {
+		if !matches(f.dbSchema, sc.Name) {
+			continue
+		}
+		if f.depth == adbc.ObjectDepthDBSchemas {
+			sc.Tables = nil
+		} else {
+			sc.Tables = f.tables(sc.Tables)
+		}
+		out = append(out, sc)
+	}
+	return out
+}
+
+func (f *objectsFilter) tables(tables []tableInfo) []tableInfo {
+	out := make([]tableInfo, 0, len(tables))
+	for _, tbl := range tables {
+		if !matches(f.tableName, &tbl.Name) ||
+			(f.tableTypes != nil && !f.tableTypes[tbl.Type]) {
+			continue
+		}
+		if f.depth == adbc.ObjectDepthTables {
+			tbl.Columns, tbl.Constraints = nil, nil
+		} else if f.columns != nil && tbl.Columns != nil {
+			cols := make([]columnInfo, 0, len(tbl.Columns))
+			for _, col := range tbl.Columns {
+				if f.columns.MatchString(col.name()) {
+					cols = append(cols, col)
+				}
+			}
+			tbl.Columns = cols
+		}
+		out = append(out, tbl)
+	}
+	return out
+}
+
+// objectsReader encodes the catalogs as a GetObjects result
+func objectsReader(mem memory.Allocator, catalogs []catalogInfo) (array.RecordReader, error) {
+	data, err := json.Marshal(catalogs)
+	if err != nil {
+		return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+	}
+	rec, _, err := array.RecordFromJSON(mem, adbc.GetObjectsSchema, bytes.NewReader(data))
+	if err != nil {
+		return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+	}
+	defer rec.Release()
+	return newReader(adbc.GetObjectsSchema, []arrow.Record{rec})
+}
@@ -0,0 +1,528 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package databasesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/driver/internal"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"golang.org/x/exp/slices"
+)
+
+var isolationLevels = map[adbc.OptionIsolationLevel]sql.IsolationLevel{
+	adbc.LevelDefault:         sql.LevelDefault,
+	adbc.LevelReadUncommitted: sql.LevelReadUncommitted,
+	adbc.LevelReadCommitted:   sql.LevelReadCommitted,
+	adbc.LevelRepeatableRead:  sql.LevelRepeatableRead,
+	adbc.LevelSnapshot:        sql.LevelSnapshot,
+	adbc.LevelSerializable:    sql.LevelSerializable,
+	adbc.LevelLinearizable:    sql.LevelLinearizable,
+}
+
+// queryer is implemented by both *sql.Conn and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type cnxn struct {
+	conn *sql.Conn
+	db   *database
+
+	// tx is the active transaction while autocommit is disabled
+	tx     *sql.Tx
+	txOpts sql.TxOptions
+}
+
+// queryer returns what queries should be run on: the active transaction
+// if there is one, otherwise the connection itself.
+func (c *cnxn) queryer() queryer {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.conn
+}
+
+// stmt returns the prepared statement to execute, bound to the active
+// transaction if there is one. Statements are always prepared on the
+// connection so that they outlive transactions.
+func (c *cnxn) stmt(ctx context.Context, s *sql.Stmt) *sql.Stmt {
+	if c.tx != nil {
+		return c.tx.StmtContext(ctx, s)
+	}
+	return s
+}
+
+func (c *cnxn) begin(ctx context.Context) error {
+	tx, err := c.conn.BeginTx(ctx, &c.txOpts)
+	if err != nil {
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+	c.tx = tx
+	return nil
+}
+
+// GetInfo returns metadata about the database/driver. The vendor name is
+// the name the database/sql driver was registered with, or its Go type
+// if the driver wraps an existing *sql.DB.
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	const strValTypeID arrow.UnionTypeCode = 0
+
+	if len(infoCodes) == 0 {
+		infoCodes = infoSupportedCodes
+	}
+
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.GetInfoSchema)
+	defer bldr.Release()
+	bldr.Reserve(len(infoCodes))
+
+	infoNameBldr := bldr.Field(0).(*array.Uint32Builder)
+	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
+	strInfoBldr := infoValueBldr.Child(0).(*array.StringBuilder)
+
+	for _, code := range infoCodes {
+		switch code {
+		case adbc.InfoDriverName:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverName)
+		case adbc.InfoDriverVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverVersion)
+		case adbc.InfoDriverArrowVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverArrowVersion)
+		case adbc.InfoVendorName:
+			vendor := c.db.driverName
+			if vendor == "" {
+				vendor = fmt.Sprintf("%T", c.db.db.Driver())
+			}
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(vendor)
+		default:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.AppendNull()
+		}
+	}
+
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{final})
+}
+
+// metadataQuery runs a query against information_schema. As not every
+// database provides it, a failure is reported as not implemented.
+func (c *cnxn) metadataQuery(ctx context.Context, query string) (*sql.Rows, error) {
+	rows, err := c.queryer().QueryContext(ctx, query)
+	if err != nil {
+		return nil, adbc.Error{
+			Msg:  "[database/sql] could not query information_schema: " + err.Error(),
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+	return rows, nil
+}
+
+func compilePatterns(patterns ...*string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := internal.PatternToRegexp(p)
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  err.Error(),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		out[i] = re
+	}
+	return out, nil
+}
+
+func matches(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
+
+// GetObjects gets a hierarchical view of all catalogs, database schemas,
+// tables, and columns from the information_schema views. Patterns are
+// matched in the driver as the placeholder syntax differs between
+// databases.
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (array.RecordReader, error) {
+	g := internal.GetObjects{Ctx: ctx, Depth: depth, Catalog: catalog, DbSchema: dbSchema, TableName: tableName, ColumnName: columnName, TableType: tableType}
+	if err := g.Init(c.db.alloc, c.getObjectsDbSchemas, c.getObjectsTables); err != nil {
+		return nil, err
+	}
+	defer g.Release()
+
+	rows, err := c.metadataQuery(ctx, "SELECT DISTINCT catalog_name FROM information_schema.schemata ORDER BY catalog_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var name sql.NullString
+	for rows.Next() {
+		if err := rows.Scan(&name); err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		g.AppendCatalog(name.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	return g.Finish()
+}
+
+func (c *cnxn) getObjectsDbSchemas(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string) (result map[string][]string, err error) {
+	if depth == adbc.ObjectDepthCatalogs {
+		return
+	}
+
+	patterns, err := compilePatterns(catalog, dbSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.metadataQuery(ctx, "SELECT catalog_name, schema_name FROM information_schema.schemata ORDER BY catalog_name, schema_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result = make(map[string][]string)
+	var catalogName, schemaName sql.NullString
+	for rows.Next() {
+		if err = rows.Scan(&catalogName, &schemaName); err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		if !matches(patterns[0], catalogName.String) || !matches(patterns[1], schemaName.String) {
+			continue
+		}
+		result[catalogName.String] = append(result[catalogName.String], schemaName.String)
+	}
+	return result, errToAdbcErr(adbc.StatusIO, rows.Err())
+}
+
+func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (result internal.SchemaToTableInfo, err error) {
+	if depth == adbc.ObjectDepthCatalogs || depth == adbc.ObjectDepthDBSchemas {
+		return
+	}
+
+	patterns, err := compilePatterns(catalog, dbSchema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	include := func(key internal.CatalogAndSchema, tbl string) bool {
+		return matches(patterns[0], key.Catalog) && matches(patterns[1], key.Schema) && matches(patterns[2], tbl)
+	}
+
+	rows, err := c.metadataQuery(ctx, "SELECT table_catalog, table_schema, table_name, table_type FROM information_schema.tables ORDER BY table_catalog, table_schema, table_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result = make(internal.SchemaToTableInfo)
+	var tblCat, tblSchema, tblName, tblType sql.NullString
+	for rows.Next() {
+		if err = rows.Scan(&tblCat, &tblSchema, &tblName, &tblType); err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+
+		key := internal.CatalogAndSchema{Catalog: tblCat.String, Schema: tblSchema.String}
+		if !include(key, tblName.String) {
+			continue
+		}
+		if len(tableType) > 0 && !slices.Contains(tableType, tblType.String) {
+			continue
+		}
+		result[key] = append(result[key], internal.TableInfo{
+			Name: tblName.String, TableType: tblType.String})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	if depth != adbc.ObjectDepthColumns {
+		return
+	}
+
+	columns, err := c.getColumns(ctx, include)
+	if err != nil {
+		return nil, err
+	}
+	for key, tables := range result {
+		for i := range tables {
+			if fields, ok := columns[tableKey{key, tables[i].Name}]; ok {
+				tables[i].Schema = arrow.NewSchema(fields, nil)
+			}
+		}
+	}
+	return
+}
+
+type tableKey struct {
+	internal.CatalogAndSchema
+	table string
+}
+
+// getColumns reads information_schema.columns for every table accepted
+// by include, converting each column to an arrow field.
+func (c *cnxn) getColumns(ctx context.Context, include func(internal.CatalogAndSchema, string) bool) (map[tableKey][]arrow.Field, error) {
+	rows, err := c.metadataQuery(ctx, `SELECT table_catalog, table_schema, table_name, column_name,
+		ordinal_position, is_nullable, data_type, numeric_precision, numeric_scale
+		FROM information_schema.columns
+		ORDER BY table_catalog, table_schema, table_name, ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		result                                     = make(map[tableKey][]arrow.Field)
+		tblCat, tblSchema, tblName, colName        sql.NullString
+		isNullable, dataType                       sql.NullString
+		ordinalPos, numericPrecision, numericScale sql.NullInt64
+	)
+	for rows.Next() {
+		err = rows.Scan(&tblCat, &tblSchema, &tblName, &colName, &ordinalPos,
+			&isNullable, &dataType, &numericPrecision, &numericScale)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+
+		key := tableKey{internal.CatalogAndSchema{Catalog: tblCat.String, Schema: tblSchema.String}, tblName.String}
+		if !include(key.CatalogAndSchema, key.table) {
+			continue
+		}
+
+		dt := typeFromName(dataType.String, numericPrecision.Int64, numericScale.Int64,
+			numericPrecision.Valid && numericScale.Valid)
+		if dt == nil {
+			dt = arrow.BinaryTypes.String
+		}
+		result[key] = append(result[key], arrow.Field{
+			Name:     colName.String,
+			Type:     dt,
+			Nullable: isNullable.String != "NO",
+			Metadata: fieldMetadata(dataType.String, int(ordinalPos.Int64)),
+		})
+	}
+	return result, errToAdbcErr(adbc.StatusIO, rows.Err())
+}
+
+// GetTableSchema returns the schema of a table from
+// information_schema.columns. If the table name is found in more than
+// one catalog or schema, those must be given to disambiguate it.
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog *string, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	equal := func(want *string, got string) bool {
+		return want == nil || *want == got
+	}
+
+	columns, err := c.getColumns(ctx, func(key internal.CatalogAndSchema, tbl string) bool {
+		return tbl == tableName && equal(catalog, key.Catalog) && equal(dbSchema, key.Schema)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(columns) {
+	case 0:
+		return nil, adbc.Error{
+			Msg:  "[database/sql] table not found: " + tableName,
+			Code: adbc.StatusNotFound,
+		}
+	case 1:
+		for _, fields := range columns {
+			return arrow.NewSchema(fields, nil), nil
+		}
+	}
+
+	return nil, adbc.Error{
+		Msg:  fmt.Sprintf("[database/sql] table name %s is ambiguous, found in %d schemas", tableName, len(columns)),
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+// GetTableTypes returns the distinct table types listed in
+// information_schema.tables.
+func (c *cnxn) GetTableTypes(ctx context.Context) (array.RecordReader, error) {
+	rows, err := c.metadataQuery(ctx, "SELECT DISTINCT table_type FROM information_schema.tables ORDER BY table_type")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.TableTypesSchema)
+	defer bldr.Release()
+
+	typeBldr := bldr.Field(0).(*array.StringBuilder)
+	var tblType sql.NullString
+	for rows.Next() {
+		if err := rows.Scan(&tblType); err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		typeBldr.Append(tblType.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.TableTypesSchema, []arrow.Record{final})
+}
+
+// Commit commits any pending transactions on this connection, it should
+// only be used if autocommit is disabled.
+//
+// Behavior is undefined if this is mixed with SQL transaction statements.
+func (c *cnxn) Commit(ctx context.Context) error {
+	if c.tx == nil {
+		return adbc.Error{
+			Msg:  "no active transaction, cannot commit",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	err := c.tx.Commit()
+	c.tx = nil
+	if err != nil {
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+	return c.begin(ctx)
+}
+
+// Rollback rolls back any pending transactions. Only used if autocommit
+// is disabled.
+//
+// Behavior is undefined if this is mixed with SQL transaction statements.
+func (c *cnxn) Rollback(ctx context.Context) error {
+	if c.tx == nil {
+		return adbc.Error{
+			Msg:  "no active transaction, cannot rollback",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	err := c.tx.Rollback()
+	c.tx = nil
+	if err != nil {
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+	return c.begin(ctx)
+}
+
+// NewStatement initializes a new statement object tied to this connection
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	return &statement{
+		alloc:     c.db.alloc,
+		cnxn:      c,
+		batchSize: c.db.batchSize,
+	}, nil
+}
+
+// Close closes this connection and releases any associated resources,
+// rolling back any active transaction.
+func (c *cnxn) Close() error {
+	if c.conn == nil {
+		return adbc.Error{Code: adbc.StatusInvalidState}
+	}
+
+	if c.tx != nil {
+		c.tx.Rollback()
+		c.tx = nil
+	}
+
+	defer func() {
+		c.conn = nil
+	}()
+	return errToAdbcErr(adbc.StatusIO, c.conn.Close())
+}
+
+// ReadPartition is not supported as database/sql has no notion of
+// partitioned results.
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	return nil, adbc.Error{
+		Code: adbc.StatusNotImplemented,
+		Msg:  "ReadPartition not supported by the database/sql adapter",
+	}
+}
+
+// SetOption sets a connection option. Changes to the isolation level or
+// read-only mode take effect from the next transaction started, which
+// happens when autocommit is disabled or after a commit or rollback.
+func (c *cnxn) SetOption(key, value string) error {
+	switch key {
+	case adbc.OptionKeyAutoCommit:
+		switch value {
+		case adbc.OptionValueEnabled:
+			if c.tx != nil {
+				err := c.tx.Commit()
+				c.tx = nil
+				if err != nil {
+					return errToAdbcErr(adbc.StatusIO, err)
+				}
+			}
+			return nil
+		case adbc.OptionValueDisabled:
+			if c.tx == nil {
+				return c.begin(context.Background())
+			}
+			return nil
+		}
+	case adbc.OptionKeyIsolationLevel:
+		level, ok := isolationLevels[adbc.OptionIsolationLevel(value)]
+		if ok {
+			c.txOpts.Isolation = level
+			return nil
+		}
+	case adbc.OptionKeyReadOnly:
+		switch value {
+		case adbc.OptionValueEnabled:
+			c.txOpts.ReadOnly = true
+			return nil
+		case adbc.OptionValueDisabled:
+			c.txOpts.ReadOnly = false
+			return nil
+		}
+	default:
+		return adbc.Error{
+			Msg:  "[database/sql] unknown connection option " + key + ": " + value,
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	return adbc.Error{
+		Msg:  "[database/sql] invalid value for option " + key + ": " + value,
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*cnxn)(nil)
+)
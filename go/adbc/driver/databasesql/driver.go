@@ -0,0 +1,250 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package databasesql is an ADBC Driver Implementation which wraps any
+// driver registered with the standard database/sql package, so that
+// databases without a native ADBC driver can be used from Arrow-native
+// code.
+//
+// The database/sql driver is chosen by its registered name and opened
+// with the given URI as its data source name:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	db, err := databasesql.Driver{}.NewDatabase(map[string]string{
+//		databasesql.OptionDriverName: "pgx",
+//		adbc.OptionKeyURI:            "postgres://localhost:5432/db",
+//	})
+//
+// Alternatively an already opened *sql.DB can be wrapped by setting the
+// DB field of the Driver.
+//
+// Result sets are converted to Arrow record batches of at most
+// OptionBatchSize rows, using the column types reported by the
+// database/sql driver. Bound parameters are passed to a prepared
+// statement which is executed once per bound row, so the placeholder
+// syntax of queries is whatever the wrapped driver expects. Metadata is
+// read from the standard information_schema views where the database
+// provides them.
+package databasesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	infoDriverName = "ADBC database/sql Adapter - Go"
+
+	// The name the database/sql driver was registered with, as would
+	// be passed to sql.Open. Required unless the Driver wraps an
+	// existing *sql.DB.
+	OptionDriverName = "adbc.databasesql.driver_name"
+	// The maximum number of rows in each record batch of a result set.
+	// It can be set on the database, or on a statement to override the
+	// database's value. Defaults to 1024.
+	OptionBatchSize = "adbc.databasesql.batch_size"
+
+	defaultBatchSize = 1024
+)
+
+var (
+	infoDriverVersion      string
+	infoDriverArrowVersion string
+	infoSupportedCodes     []adbc.InfoCode
+)
+
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			switch {
+			case dep.Path == "github.com/apache/arrow-adbc/go/adbc/driver/databasesql":
+				infoDriverVersion = dep.Version
+			case strings.HasPrefix(dep.Path, "github.com/apache/arrow/go/"):
+				infoDriverArrowVersion = dep.Version
+			}
+		}
+	}
+	// XXX: Deps not populated in tests
+	// https://github.com/golang/go/issues/33976
+	if infoDriverVersion == "" {
+		infoDriverVersion = "(unknown or development build)"
+	}
+	if infoDriverArrowVersion == "" {
+		infoDriverArrowVersion = "(unknown or development build)"
+	}
+
+	infoSupportedCodes = []adbc.InfoCode{
+		adbc.InfoDriverName,
+		adbc.InfoDriverVersion,
+		adbc.InfoDriverArrowVersion,
+		adbc.InfoVendorName,
+	}
+}
+
+func errToAdbcErr(code adbc.Status, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e adbc.Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = adbc.StatusCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = adbc.StatusTimeout
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, sql.ErrTxDone):
+		code = adbc.StatusInvalidState
+	}
+
+	return adbc.Error{
+		Msg:  err.Error(),
+		Code: code,
+	}
+}
+
+func parseBatchSize(v string) (int, error) {
+	size, err := strconv.Atoi(v)
+	if err != nil || size <= 0 {
+		return 0, adbc.Error{
+			Msg:  fmt.Sprintf("invalid value for option '%s': '%s', must be a positive integer", OptionBatchSize, v),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return size, nil
+}
+
+type Driver struct {
+	Alloc memory.Allocator
+	// DB, if set, is used by every database created from this driver
+	// instead of opening a new *sql.DB from the OptionDriverName and
+	// adbc.OptionKeyURI options. It is not closed by the driver.
+	DB *sql.DB
+}
+
+func (d Driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	db := &database{alloc: d.Alloc, db: d.DB, batchSize: defaultBatchSize}
+
+	opts = maps.Clone(opts)
+	if db.alloc == nil {
+		db.alloc = memory.DefaultAllocator
+	}
+
+	return db, db.SetOptions(opts)
+}
+
+type database struct {
+	alloc     memory.Allocator
+	batchSize int
+
+	driverName string
+	dsn        string
+	// db is opened on first use unless provided by the Driver, in
+	// which case ownsDB is false and it is left open by Close. mu
+	// guards both, as connections may be opened concurrently.
+	mu     sync.Mutex
+	db     *sql.DB
+	ownsDB bool
+}
+
+func (d *database) SetOptions(cnOptions map[string]string) error {
+	for k, v := range cnOptions {
+		switch k {
+		case OptionDriverName:
+			d.driverName = v
+		case adbc.OptionKeyURI:
+			d.dsn = v
+		case OptionBatchSize:
+			size, err := parseBatchSize(v)
+			if err != nil {
+				return err
+			}
+			d.batchSize = size
+		default:
+			return adbc.Error{
+				Msg:  "[database/sql] unknown database option " + k + ": " + v,
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	}
+	return nil
+}
+
+// sqlDB returns the *sql.DB of the database, opening it if needed.
+func (d *database) sqlDB() (*sql.DB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.db == nil {
+		if d.driverName == "" {
+			return nil, adbc.Error{
+				Msg:  "[database/sql] option " + OptionDriverName + " is required",
+				Code: adbc.StatusInvalidState,
+			}
+		}
+
+		db, err := sql.Open(d.driverName, d.dsn)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidArgument, err)
+		}
+		d.db, d.ownsDB = db, true
+	}
+	return d.db, nil
+}
+
+func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
+	db, err := d.sqlDB()
+	if err != nil {
+		return nil, err
+	}
+
+	// pin a single session so that transactions, temporary objects and
+	// session settings behave as they would on a native connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	return &cnxn{conn: conn, db: d}, nil
+}
+
+// Close closes the underlying *sql.DB if it was opened by this database.
+func (d *database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.db == nil || !d.ownsDB {
+		return nil
+	}
+
+	defer func() { d.db = nil }()
+	return errToAdbcErr(adbc.StatusIO, d.db.Close())
+}
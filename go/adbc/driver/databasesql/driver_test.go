@@ -0,0 +1,523 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package databasesql_test
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	driver "github.com/apache/arrow-adbc/go/adbc/driver/databasesql"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	_ "modernc.org/sqlite"
+)
+
+type SQLiteTests struct {
+	suite.Suite
+
+	mem  *memory.CheckedAllocator
+	ctx  context.Context
+	db   adbc.Database
+	cnxn adbc.Connection
+}
+
+func (s *SQLiteTests) SetupTest() {
+	s.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	s.ctx = context.Background()
+
+	var err error
+	s.db, err = driver.Driver{Alloc: s.mem}.NewDatabase(map[string]string{
+		driver.OptionDriverName: "sqlite",
+		adbc.OptionKeyURI:       "file:" + filepath.Join(s.T().TempDir(), "test.db"),
+		driver.OptionBatchSize:  "2",
+	})
+	s.Require().NoError(err)
+
+	s.cnxn, err = s.db.Open(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *SQLiteTests) TearDownTest() {
+	s.NoError(s.cnxn.Close())
+	s.NoError(s.db.(interface{ Close() error }).Close())
+	s.mem.AssertSize(s.T(), 0)
+}
+
+func (s *SQLiteTests) exec(query string) {
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetSqlQuery(query))
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+}
+
+func (s *SQLiteTests) query(stmt adbc.Statement, query string) array.RecordReader {
+	s.Require().NoError(stmt.SetSqlQuery(query))
+	rdr, n, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(-1, n)
+	return rdr
+}
+
+func (s *SQLiteTests) recordFromJSON(sc *arrow.Schema, data string) arrow.Record {
+	rec, _, err := array.RecordFromJSON(s.mem, sc, strings.NewReader(data))
+	s.Require().NoError(err)
+	return rec
+}
+
+// checkResult reads all batches from rdr, checking their sizes and that
+// they match the expected record when concatenated.
+func (s *SQLiteTests) checkResult(rdr array.RecordReader, batchSizes []int64, expected arrow.Record) {
+	defer rdr.Release()
+	s.Truef(expected.Schema().Equal(rdr.Schema()), "expected: %s\ngot: %s", expected.Schema(), rdr.Schema())
+
+	var (
+		sizes  []int64
+		offset int64
+	)
+	for rdr.Next() {
+		rec := rdr.Record()
+		sizes = append(sizes, rec.NumRows())
+
+		slice := expected.NewSlice(offset, offset+rec.NumRows())
+		s.Truef(array.RecordEqual(slice, rec), "expected: %s\ngot: %s", slice, rec)
+		slice.Release()
+		offset += rec.NumRows()
+	}
+	s.NoError(rdr.Err())
+	s.Equal(batchSizes, sizes)
+}
+
+func (s *SQLiteTests) TestQueryTypes() {
+	s.exec(`CREATE TABLE types (i INTEGER NOT NULL, s TEXT, r REAL, d DATE,
+		ts DATETIME, b BLOB, n NUMERIC(10,2), bo BOOLEAN)`)
+	s.exec(`INSERT INTO types VALUES
+		(1, 'foo', 1.5, '2023-01-02', '2023-01-02 03:04:05', x'0102', 12.25, true),
+		(2, NULL, NULL, NULL, NULL, NULL, NULL, NULL),
+		(3, '', -2.5, '1969-12-31', '1969-12-31 23:59:59.5', x'', -0.5, false)`)
+
+	md := func(typ string) arrow.Metadata {
+		return arrow.NewMetadata([]string{"TYPE_NAME"}, []string{typ})
+	}
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true, Metadata: md("INTEGER")},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true, Metadata: md("TEXT")},
+		{Name: "r", Type: arrow.PrimitiveTypes.Float64, Nullable: true, Metadata: md("REAL")},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true, Metadata: md("DATE")},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true, Metadata: md("DATETIME")},
+		{Name: "b", Type: arrow.BinaryTypes.Binary, Nullable: true, Metadata: md("BLOB")},
+		{Name: "n", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true, Metadata: md("NUMERIC(10,2)")},
+		{Name: "bo", Type: arrow.FixedWidthTypes.Boolean, Nullable: true, Metadata: md("BOOLEAN")},
+	}, nil), `[
+		{"i": 1, "s": "foo", "r": 1.5, "d": 19359, "ts": "2023-01-02T03:04:05", "b": "AQI=", "n": "12.25", "bo": true},
+		{"i": 2, "s": null, "r": null, "d": null, "ts": null, "b": null, "n": null, "bo": null},
+		{"i": 3, "s": "", "r": -2.5, "d": -1, "ts": "1969-12-31T23:59:59.5", "b": "", "n": "-0.50", "bo": false}
+	]`)
+	defer expected.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.checkResult(s.query(stmt, "SELECT * FROM types ORDER BY i"), []int64{2, 1}, expected)
+}
+
+func (s *SQLiteTests) TestBatchSize() {
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	query := `WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM seq WHERE x < 10)
+		SELECT x FROM seq`
+	tests := []struct {
+		batchSize string
+		sizes     []int64
+	}{
+		{"3", []int64{3, 3, 3, 1}},
+		{"10", []int64{10}},
+		{"1024", []int64{10}},
+	}
+	for _, tt := range tests {
+		s.Run(tt.batchSize, func() {
+			s.Require().NoError(stmt.SetOption(driver.OptionBatchSize, tt.batchSize))
+			rdr := s.query(stmt, query)
+			defer rdr.Release()
+
+			var sizes []int64
+			for rdr.Next() {
+				sizes = append(sizes, rdr.Record().NumRows())
+			}
+			s.NoError(rdr.Err())
+			s.Equal(tt.sizes, sizes)
+		})
+	}
+
+	var adbcErr adbc.Error
+	s.ErrorAs(stmt.SetOption(driver.OptionBatchSize, "0"), &adbcErr)
+	s.Equal(adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func (s *SQLiteTests) TestEmptyResult() {
+	s.exec("CREATE TABLE empty (i INTEGER, s TEXT)")
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	rdr := s.query(stmt, "SELECT * FROM empty")
+	defer rdr.Release()
+
+	s.Equal([]string{"i", "s"}, []string{rdr.Schema().Field(0).Name, rdr.Schema().Field(1).Name})
+	s.Equal(arrow.PrimitiveTypes.Int64, rdr.Schema().Field(0).Type)
+	s.Equal(arrow.BinaryTypes.String, rdr.Schema().Field(1).Type)
+	s.False(rdr.Next())
+	s.NoError(rdr.Err())
+}
+
+func (s *SQLiteTests) TestBindInsert() {
+	s.exec("CREATE TABLE bound (i INTEGER, s TEXT, d DATE)")
+
+	params := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+	}, nil), `[
+		{"i": 1, "s": "foo", "d": 19359},
+		{"i": null, "s": "bar", "d": null},
+		{"i": 3, "s": null, "d": 0}
+	]`)
+	defer params.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetSqlQuery("INSERT INTO bound VALUES (?, ?, ?)"))
+	s.Require().NoError(stmt.Prepare(s.ctx))
+	s.Require().NoError(stmt.Bind(s.ctx, params))
+	n, err := stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(3, n)
+
+	// the bound record is kept, so it can be executed again
+	n, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(3, n)
+
+	md := func(typ string) arrow.Metadata {
+		return arrow.NewMetadata([]string{"TYPE_NAME"}, []string{typ})
+	}
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true, Metadata: md("INTEGER")},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true, Metadata: md("TEXT")},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true, Metadata: md("DATE")},
+	}, nil), `[
+		{"i": 1, "s": "foo", "d": 19359},
+		{"i": null, "s": "bar", "d": null},
+		{"i": 3, "s": null, "d": 0},
+		{"i": 1, "s": "foo", "d": 19359},
+		{"i": null, "s": "bar", "d": null},
+		{"i": 3, "s": null, "d": 0}
+	]`)
+	defer expected.Release()
+
+	s.Require().NoError(stmt.Bind(s.ctx, nil))
+	s.checkResult(s.query(stmt, "SELECT * FROM bound ORDER BY rowid"), []int64{2, 2, 2}, expected)
+}
+
+func (s *SQLiteTests) TestBindStreamQuery() {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "x", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+	batches := []arrow.Record{
+		s.recordFromJSON(sc, `[{"x": 1}, {"x": 2}, {"x": 3}]`),
+		s.recordFromJSON(sc, `[]`),
+		s.recordFromJSON(sc, `[{"x": 4}]`),
+	}
+	rdr, err := array.NewRecordReader(sc, batches)
+	s.Require().NoError(err)
+	for _, b := range batches {
+		b.Release()
+	}
+	defer rdr.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.BindStream(s.ctx, rdr))
+	s.Require().NoError(stmt.SetSqlQuery("SELECT CAST(? AS INTEGER) * 10 AS y"))
+
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "y", Type: arrow.PrimitiveTypes.Int64, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{}, []string{})},
+	}, nil), `[{"y": 10}, {"y": 20}, {"y": 30}, {"y": 40}]`)
+	defer expected.Release()
+
+	result, _, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	s.checkResult(result, []int64{2, 2}, expected)
+
+	// the stream was consumed by the execution
+	_, _, err = stmt.ExecuteQuery(s.ctx)
+	s.Error(err)
+}
+
+func (s *SQLiteTests) TestBindNested() {
+	s.exec("CREATE TABLE nested (v TEXT)")
+
+	params := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "v", Type: arrow.ListOf(arrow.PrimitiveTypes.Int32), Nullable: true},
+	}, nil), `[{"v": [1, 2]}, {"v": null}]`)
+	defer params.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetSqlQuery("INSERT INTO nested VALUES (?)"))
+	s.Require().NoError(stmt.Bind(s.ctx, params))
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+
+	s.Require().NoError(stmt.Bind(s.ctx, nil))
+	rdr := s.query(stmt, "SELECT v FROM nested ORDER BY rowid")
+	defer rdr.Release()
+	s.Require().True(rdr.Next())
+	col := rdr.Record().Column(0).(*array.String)
+	s.Equal("[1,2]", col.Value(0))
+	s.True(col.IsNull(1))
+}
+
+func (s *SQLiteTests) TestTransactions() {
+	s.exec("CREATE TABLE tx (i INTEGER)")
+	cnxnopts := s.cnxn.(adbc.PostInitOptions)
+
+	count := func() int64 {
+		stmt, err := s.cnxn.NewStatement()
+		s.Require().NoError(err)
+		defer stmt.Close()
+
+		rdr := s.query(stmt, "SELECT count(*) FROM tx")
+		defer rdr.Release()
+		s.Require().True(rdr.Next())
+		return rdr.Record().Column(0).(*array.Int64).Value(0)
+	}
+
+	var adbcErr adbc.Error
+	s.ErrorAs(s.cnxn.Commit(s.ctx), &adbcErr)
+	s.Equal(adbc.StatusInvalidState, adbcErr.Code)
+
+	s.Require().NoError(cnxnopts.SetOption(adbc.OptionKeyIsolationLevel, string(adbc.LevelSerializable)))
+	s.Require().NoError(cnxnopts.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+	s.Require().NoError(stmt.SetSqlQuery("INSERT INTO tx VALUES (1)"))
+	s.Require().NoError(stmt.Prepare(s.ctx))
+
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(1, count())
+	s.Require().NoError(s.cnxn.Rollback(s.ctx))
+	s.EqualValues(0, count())
+
+	// the prepared statement outlives the transaction
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	s.Require().NoError(s.cnxn.Commit(s.ctx))
+	s.Require().NoError(cnxnopts.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueEnabled))
+	s.EqualValues(1, count())
+
+	s.ErrorAs(cnxnopts.SetOption(adbc.OptionKeyIsolationLevel, "invalid"), &adbcErr)
+	s.Equal(adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func (s *SQLiteTests) TestGetInfo() {
+	rdr, err := s.cnxn.GetInfo(s.ctx, []adbc.InfoCode{adbc.InfoDriverName, adbc.InfoVendorName})
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	s.Require().True(rdr.Next())
+	values := rdr.Record().Column(1).(*array.DenseUnion).Field(0).(*array.String)
+	s.Equal("ADBC database/sql Adapter - Go", values.Value(0))
+	s.Equal("sqlite", values.Value(1))
+}
+
+// createInformationSchema emulates the information_schema views, which
+// SQLite doesn't have, on the test connection.
+func (s *SQLiteTests) createInformationSchema() {
+	s.exec("ATTACH ':memory:' AS information_schema")
+	s.exec("CREATE TABLE information_schema.schemata (catalog_name TEXT, schema_name TEXT)")
+	s.exec("CREATE TABLE information_schema.tables (table_catalog TEXT, table_schema TEXT, table_name TEXT, table_type TEXT)")
+	s.exec(`CREATE TABLE information_schema.columns (table_catalog TEXT, table_schema TEXT,
+		table_name TEXT, column_name TEXT, ordinal_position INTEGER, is_nullable TEXT,
+		data_type TEXT, numeric_precision INTEGER, numeric_scale INTEGER)`)
+
+	s.exec(`INSERT INTO information_schema.schemata VALUES ('db', 'main'), ('db', 'other')`)
+	s.exec(`INSERT INTO information_schema.tables VALUES
+		('db', 'main', 'ints', 'BASE TABLE'), ('db', 'main', 'view', 'VIEW'),
+		('db', 'other', 'ints', 'BASE TABLE')`)
+	s.exec(`INSERT INTO information_schema.columns VALUES
+		('db', 'main', 'ints', 'id', 1, 'NO', 'bigint', 64, 0),
+		('db', 'main', 'ints', 'amount', 2, 'YES', 'numeric', 10, 2),
+		('db', 'main', 'view', 'name', 1, 'YES', 'character varying', NULL, NULL),
+		('db', 'other', 'ints', 'id', 1, 'YES', 'integer', 32, 0)`)
+}
+
+func (s *SQLiteTests) TestGetObjects() {
+	// without information_schema metadata is not available
+	_, err := s.cnxn.GetObjects(s.ctx, adbc.ObjectDepthAll, nil, nil, nil, nil, nil)
+	var adbcErr adbc.Error
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+
+	s.createInformationSchema()
+
+	schemaFilter, tableFilter, columnFilter := "ma%", "in_s", "am%"
+	rdr, err := s.cnxn.GetObjects(s.ctx, adbc.ObjectDepthColumns, nil, &schemaFilter, &tableFilter, &columnFilter, []string{"BASE TABLE"})
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	s.Truef(adbc.GetObjectsSchema.Equal(rdr.Schema()), "expected: %s\ngot: %s", adbc.GetObjectsSchema, rdr.Schema())
+	s.Require().True(rdr.Next())
+	rec := rdr.Record()
+	s.Require().EqualValues(1, rec.NumRows())
+	s.Equal("db", rec.Column(0).(*array.String).Value(0))
+
+	var (
+		dbSchemas    = rec.Column(1).(*array.List).ListValues().(*array.Struct)
+		tables       = dbSchemas.Field(1).(*array.List).ListValues().(*array.Struct)
+		columns      = tables.Field(2).(*array.List).ListValues().(*array.Struct)
+		schemaNames  = dbSchemas.Field(0).(*array.String)
+		tableNames   = tables.Field(0).(*array.String)
+		columnNames  = columns.Field(0).(*array.String)
+		columnOrdPos = columns.Field(1).(*array.Int32)
+	)
+	s.Equal(1, schemaNames.Len())
+	s.Equal("main", schemaNames.Value(0))
+	s.Equal(1, tableNames.Len())
+	s.Equal("ints", tableNames.Value(0))
+	s.Equal(1, columnNames.Len())
+	s.Equal("amount", columnNames.Value(0))
+	s.EqualValues(2, columnOrdPos.Value(0))
+	s.False(rdr.Next())
+}
+
+func (s *SQLiteTests) TestGetTableSchema() {
+	s.createInformationSchema()
+
+	var adbcErr adbc.Error
+	_, err := s.cnxn.GetTableSchema(s.ctx, nil, nil, "ints")
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInvalidArgument, adbcErr.Code)
+
+	_, err = s.cnxn.GetTableSchema(s.ctx, nil, nil, "missing")
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotFound, adbcErr.Code)
+
+	dbSchema := "main"
+	sc, err := s.cnxn.GetTableSchema(s.ctx, nil, &dbSchema, "ints")
+	s.Require().NoError(err)
+
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64,
+			Metadata: arrow.NewMetadata([]string{"TYPE_NAME", "ORDINAL_POSITION"}, []string{"bigint", "1"})},
+		{Name: "amount", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"TYPE_NAME", "ORDINAL_POSITION"}, []string{"numeric", "2"})},
+	}, nil)
+	s.Truef(expected.Equal(sc), "expected: %s\ngot: %s", expected, sc)
+
+	rdr, err := s.cnxn.GetTableTypes(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+	s.Require().True(rdr.Next())
+	types := rdr.Record().Column(0).(*array.String)
+	s.Equal([]string{"BASE TABLE", "VIEW"}, []string{types.Value(0), types.Value(1)})
+}
+
+func (s *SQLiteTests) TestBindUint64() {
+	s.exec("CREATE TABLE unsigned (v INTEGER)")
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+	s.Require().NoError(stmt.SetSqlQuery("INSERT INTO unsigned VALUES (?)"))
+
+	sc := arrow.NewSchema([]arrow.Field{{Name: "v", Type: arrow.PrimitiveTypes.Uint64, Nullable: true}}, nil)
+	bind := func(v uint64) error {
+		bldr := array.NewRecordBuilder(s.mem, sc)
+		defer bldr.Release()
+		bldr.Field(0).(*array.Uint64Builder).Append(v)
+		params := bldr.NewRecord()
+		defer params.Release()
+
+		s.Require().NoError(stmt.Bind(s.ctx, params))
+		_, err := stmt.ExecuteUpdate(s.ctx)
+		return err
+	}
+
+	s.Require().NoError(bind(math.MaxInt64))
+	err = bind(math.MaxInt64 + 1)
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInvalidArgument, adbcErr.Code)
+	s.Contains(adbcErr.Msg, "9223372036854775808 overflows int64")
+}
+
+func TestConcurrentOpen(t *testing.T) {
+	db, err := driver.Driver{}.NewDatabase(map[string]string{
+		driver.OptionDriverName: "sqlite",
+		adbc.OptionKeyURI:       "file:" + filepath.Join(t.TempDir(), "test.db"),
+	})
+	require.NoError(t, err)
+	defer db.(interface{ Close() error }).Close()
+
+	var (
+		wg    sync.WaitGroup
+		cnxns = make([]adbc.Connection, 8)
+		errs  = make([]error, len(cnxns))
+	)
+	for i := range cnxns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cnxns[i], errs[i] = db.Open(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i, cnxn := range cnxns {
+		require.NoError(t, errs[i])
+		assert.NoError(t, cnxn.Close())
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	suite.Run(t, new(SQLiteTests))
+}
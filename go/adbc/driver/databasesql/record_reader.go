@@ -0,0 +1,755 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package databasesql
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/decimal256"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+	rawBytes    = reflect.TypeOf(sql.RawBytes(nil))
+	nullTypeMap = map[reflect.Type]reflect.Type{
+		reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+		reflect.TypeOf(sql.NullByte{}):    reflect.TypeOf(uint8(0)),
+		reflect.TypeOf(sql.NullInt16{}):   reflect.TypeOf(int16(0)),
+		reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+		reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+		reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+		reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+		reflect.TypeOf(sql.NullTime{}):    timeType,
+	}
+)
+
+// baseTypeName normalizes a database type name such as "VARCHAR(255)"
+// or "int unsigned" to its upper-cased name without parameters, also
+// reporting whether it was unsigned.
+func baseTypeName(name string) (base string, unsigned bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if paren := strings.Index(name, "("); paren != -1 {
+		rest := ""
+		if end := strings.Index(name[paren:], ")"); end != -1 {
+			rest = name[paren+end+1:]
+		}
+		name = strings.TrimSpace(name[:paren] + rest)
+	}
+	if strings.HasSuffix(name, " UNSIGNED") {
+		return strings.TrimSuffix(name, " UNSIGNED"), true
+	}
+	return name, false
+}
+
+func decimalType(prec, scale int64) arrow.DataType {
+	switch {
+	case prec <= 0 || scale < 0 || scale > prec:
+		return nil
+	case prec <= 38:
+		return &arrow.Decimal128Type{Precision: int32(prec), Scale: int32(scale)}
+	case prec <= 76:
+		return &arrow.Decimal256Type{Precision: int32(prec), Scale: int32(scale)}
+	}
+	return nil
+}
+
+// typeParams parses the precision and scale from a type name such as
+// "NUMERIC(10,2)", for drivers which don't report them separately.
+func typeParams(name string) (prec, scale int64, ok bool) {
+	open, end := strings.Index(name, "("), strings.Index(name, ")")
+	if open == -1 || end < open {
+		return 0, 0, false
+	}
+
+	params := strings.Split(name[open+1:end], ",")
+	prec, err := strconv.ParseInt(strings.TrimSpace(params[0]), 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if len(params) > 1 {
+		if scale, err = strconv.ParseInt(strings.TrimSpace(params[1]), 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return prec, scale, true
+}
+
+// typeFromName maps the name of a database type, as reported by
+// ColumnType.DatabaseTypeName or information_schema.columns, to an arrow
+// type. Names differ between databases so this only covers the common
+// spellings, returning nil for anything it doesn't recognize. Where
+// databases disagree on the width of a type, such as INTEGER being 64
+// bits in SQLite but 32 bits elsewhere, the wider type is used.
+func typeFromName(name string, prec, scale int64, hasDecimal bool) arrow.DataType {
+	base, unsigned := baseTypeName(name)
+	switch base {
+	case "BOOL", "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean
+	case "TINYINT", "INT1":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint8
+		}
+		return arrow.PrimitiveTypes.Int8
+	case "SMALLINT", "INT2":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint16
+		}
+		return arrow.PrimitiveTypes.Int16
+	case "MEDIUMINT", "INT4":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint32
+		}
+		return arrow.PrimitiveTypes.Int32
+	case "INT", "INTEGER", "BIGINT", "INT8":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint64
+		}
+		return arrow.PrimitiveTypes.Int64
+	case "FLOAT4":
+		return arrow.PrimitiveTypes.Float32
+	case "REAL", "FLOAT", "FLOAT8", "DOUBLE", "DOUBLE PRECISION":
+		return arrow.PrimitiveTypes.Float64
+	case "DECIMAL", "NUMERIC", "NUMBER":
+		if hasDecimal {
+			if dt := decimalType(prec, scale); dt != nil {
+				return dt
+			}
+		}
+		return arrow.BinaryTypes.String
+	case "CHAR", "CHARACTER", "VARCHAR", "CHARACTER VARYING", "NCHAR",
+		"NVARCHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "STRING",
+		"CLOB", "JSON", "JSONB", "UUID", "ENUM", "SET", "NAME":
+		return arrow.BinaryTypes.String
+	case "BINARY", "VARBINARY", "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB",
+		"LONGBLOB":
+		return arrow.BinaryTypes.Binary
+	case "DATE":
+		return arrow.FixedWidthTypes.Date32
+	case "TIME", "TIME WITHOUT TIME ZONE":
+		return arrow.FixedWidthTypes.Time64us
+	case "DATETIME", "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE":
+		return arrow.FixedWidthTypes.Timestamp_us
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	}
+	return nil
+}
+
+// typeFromScanType maps the Go type a database/sql driver scans a
+// column into to an arrow type, returning nil if it isn't specific
+// enough to decide.
+func typeFromScanType(typ reflect.Type) arrow.DataType {
+	if typ == nil {
+		return nil
+	}
+	if under, ok := nullTypeMap[typ]; ok {
+		typ = under
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return arrow.FixedWidthTypes.Timestamp_us
+	case typ == bytesType || typ == rawBytes:
+		return arrow.BinaryTypes.Binary
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean
+	case reflect.Int8:
+		return arrow.PrimitiveTypes.Int8
+	case reflect.Int16:
+		return arrow.PrimitiveTypes.Int16
+	case reflect.Int32:
+		return arrow.PrimitiveTypes.Int32
+	case reflect.Int, reflect.Int64:
+		return arrow.PrimitiveTypes.Int64
+	case reflect.Uint8:
+		return arrow.PrimitiveTypes.Uint8
+	case reflect.Uint16:
+		return arrow.PrimitiveTypes.Uint16
+	case reflect.Uint32:
+		return arrow.PrimitiveTypes.Uint32
+	case reflect.Uint, reflect.Uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case reflect.Float32:
+		return arrow.PrimitiveTypes.Float32
+	case reflect.Float64:
+		return arrow.PrimitiveTypes.Float64
+	case reflect.String:
+		return arrow.BinaryTypes.String
+	}
+	return nil
+}
+
+// columnType picks the arrow type for a result column. The scan type is
+// preferred as it reflects what the driver will actually return, but
+// the database type name is needed to tell dates, times and decimals
+// apart from timestamps, strings and floats.
+func columnType(ct *sql.ColumnType) arrow.DataType {
+	prec, scale, hasDecimal := ct.DecimalSize()
+	if !hasDecimal {
+		prec, scale, hasDecimal = typeParams(ct.DatabaseTypeName())
+	}
+	byName := typeFromName(ct.DatabaseTypeName(), prec, scale, hasDecimal)
+	byScan := typeFromScanType(ct.ScanType())
+
+	switch {
+	case byScan == nil && byName == nil:
+		return arrow.BinaryTypes.String
+	case byScan == nil:
+		return byName
+	case byName == nil:
+		return byScan
+	}
+
+	switch byName.ID() {
+	case arrow.DATE32, arrow.TIME64, arrow.TIMESTAMP, arrow.DECIMAL128, arrow.DECIMAL256:
+		return byName
+	}
+	return byScan
+}
+
+func fieldMetadata(typeName string, ordinal int) arrow.Metadata {
+	keys, values := []string{}, []string{}
+	if typeName != "" {
+		keys, values = append(keys, "TYPE_NAME"), append(values, typeName)
+	}
+	if ordinal > 0 {
+		keys, values = append(keys, "ORDINAL_POSITION"), append(values, strconv.Itoa(ordinal))
+	}
+	return arrow.NewMetadata(keys, values)
+}
+
+func schemaFromColumnTypes(cols []*sql.ColumnType) *arrow.Schema {
+	fields := make([]arrow.Field, len(cols))
+	for i, ct := range cols {
+		nullable, ok := ct.Nullable()
+		fields[i] = arrow.Field{
+			Name:     ct.Name(),
+			Type:     columnType(ct),
+			Nullable: nullable || !ok,
+			Metadata: fieldMetadata(ct.DatabaseTypeName(), 0),
+		}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+func invalidValue(v interface{}, dt arrow.DataType) error {
+	return adbc.Error{
+		Msg:  fmt.Sprintf("[database/sql] cannot convert value %v of type %T to %s", v, v, dt),
+		Code: adbc.StatusInvalidData,
+	}
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case uint:
+		return int64(v), uint64(v) <= math.MaxInt64
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	case float32:
+		return int64(v), float32(int64(v)) == v
+	case float64:
+		return int64(v), float64(int64(v)) == v
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case []byte:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch v := v.(type) {
+	case uint64:
+		return v, true
+	case uint:
+		return uint64(v), true
+	case []byte:
+		n, err := strconv.ParseUint(string(v), 10, 64)
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return n, err == nil
+	}
+	n, ok := toInt64(v)
+	return uint64(n), ok && n >= 0
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case []byte:
+		f, err := strconv.ParseFloat(string(v), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	case uint64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	}
+	n, ok := toInt64(v)
+	return float64(n), ok
+}
+
+func toBool(v interface{}) (bool, bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case []byte:
+		b, err := strconv.ParseBool(string(v))
+		return b, err == nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	n, ok := toInt64(v)
+	return n != 0, ok
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// the layouts tried, in order, for dates and times returned as text
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	var s string
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return time.Time{}, false
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// wallClock reinterprets the wall clock reading of t as UTC, which is
+// how arrow represents timestamps without a time zone.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(),
+		t.Second(), t.Nanosecond(), time.UTC)
+}
+
+type intType interface {
+	int8 | int16 | int32 | int64
+}
+
+type uintType interface {
+	uint8 | uint16 | uint32 | uint64
+}
+
+func appendInt[T intType](b interface{ Append(T) }, dt arrow.DataType) func(interface{}) error {
+	return func(v interface{}) error {
+		n, ok := toInt64(v)
+		if !ok || int64(T(n)) != n {
+			return invalidValue(v, dt)
+		}
+		b.Append(T(n))
+		return nil
+	}
+}
+
+func appendUint[T uintType](b interface{ Append(T) }, dt arrow.DataType) func(interface{}) error {
+	return func(v interface{}) error {
+		n, ok := toUint64(v)
+		if !ok || uint64(T(n)) != n {
+			return invalidValue(v, dt)
+		}
+		b.Append(T(n))
+		return nil
+	}
+}
+
+// newAppender returns a function appending a value scanned from a row to
+// the builder. Values are converted as leniently as possible since
+// drivers are free to return, for instance, numbers as text.
+func newAppender(b array.Builder) func(interface{}) error {
+	dt := b.Type()
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		return func(v interface{}) error {
+			val, ok := toBool(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			b.Append(val)
+			return nil
+		}
+	case *array.Int8Builder:
+		return appendInt[int8](b, dt)
+	case *array.Int16Builder:
+		return appendInt[int16](b, dt)
+	case *array.Int32Builder:
+		return appendInt[int32](b, dt)
+	case *array.Int64Builder:
+		return appendInt[int64](b, dt)
+	case *array.Uint8Builder:
+		return appendUint[uint8](b, dt)
+	case *array.Uint16Builder:
+		return appendUint[uint16](b, dt)
+	case *array.Uint32Builder:
+		return appendUint[uint32](b, dt)
+	case *array.Uint64Builder:
+		return appendUint[uint64](b, dt)
+	case *array.Float32Builder:
+		return func(v interface{}) error {
+			f, ok := toFloat64(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			b.Append(float32(f))
+			return nil
+		}
+	case *array.Float64Builder:
+		return func(v interface{}) error {
+			f, ok := toFloat64(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			b.Append(f)
+			return nil
+		}
+	case *array.StringBuilder:
+		return func(v interface{}) error {
+			b.Append(toString(v))
+			return nil
+		}
+	case *array.BinaryBuilder:
+		return func(v interface{}) error {
+			switch v := v.(type) {
+			case []byte:
+				b.Append(v)
+			case string:
+				b.AppendString(v)
+			default:
+				return invalidValue(v, dt)
+			}
+			return nil
+		}
+	case *array.Date32Builder:
+		return func(v interface{}) error {
+			t, ok := toTime(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			b.Append(arrow.Date32FromTime(wallClock(t)))
+			return nil
+		}
+	case *array.Time64Builder:
+		return func(v interface{}) error {
+			t, ok := toTime(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			sinceMidnight := time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second +
+				time.Duration(t.Nanosecond())
+			b.Append(arrow.Time64(sinceMidnight.Microseconds()))
+			return nil
+		}
+	case *array.TimestampBuilder:
+		hasZone := dt.(*arrow.TimestampType).TimeZone != ""
+		return func(v interface{}) error {
+			t, ok := toTime(v)
+			if !ok {
+				return invalidValue(v, dt)
+			}
+			if !hasZone {
+				t = wallClock(t)
+			}
+			b.Append(arrow.Timestamp(t.UnixMicro()))
+			return nil
+		}
+	case *array.Decimal128Builder:
+		decType := dt.(*arrow.Decimal128Type)
+		return func(v interface{}) (err error) {
+			var n decimal128.Num
+			switch v := v.(type) {
+			case float32, float64:
+				f, _ := toFloat64(v)
+				n, err = decimal128.FromFloat64(f, decType.Precision, decType.Scale)
+			default:
+				n, err = decimal128.FromString(toString(v), decType.Precision, decType.Scale)
+			}
+			if err != nil {
+				return invalidValue(v, dt)
+			}
+			b.Append(n)
+			return nil
+		}
+	case *array.Decimal256Builder:
+		decType := dt.(*arrow.Decimal256Type)
+		return func(v interface{}) (err error) {
+			var n decimal256.Num
+			switch v := v.(type) {
+			case float32, float64:
+				f, _ := toFloat64(v)
+				n, err = decimal256.FromFloat64(f, decType.Precision, decType.Scale)
+			default:
+				n, err = decimal256.FromString(toString(v), decType.Precision, decType.Scale)
+			}
+			if err != nil {
+				return invalidValue(v, dt)
+			}
+			b.Append(n)
+			return nil
+		}
+	}
+
+	return func(v interface{}) error {
+		return adbc.Error{
+			Msg:  fmt.Sprintf("[database/sql] unsupported result type %s", dt),
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+}
+
+// reader converts one or more *sql.Rows into record batches. Further
+// result sets are obtained from next, if set, once the current one is
+// exhausted; they must all have the same columns as the first.
+type reader struct {
+	refCount  int64
+	schema    *arrow.Schema
+	bldr      *array.RecordBuilder
+	appenders []func(interface{}) error
+	batchSize int
+
+	rows *sql.Rows
+	next func() (*sql.Rows, error)
+	done func()
+	vals []interface{}
+	dest []interface{}
+
+	rec arrow.Record
+	err error
+}
+
+// newRecordReader takes ownership of rows and calls done, if set, once
+// the reader has been released.
+func newRecordReader(alloc memory.Allocator, rows *sql.Rows, next func() (*sql.Rows, error), done func(), batchSize int) (array.RecordReader, error) {
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		if done != nil {
+			done()
+		}
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+
+	schema := schemaFromColumnTypes(cols)
+	bldr := array.NewRecordBuilder(alloc, schema)
+	rdr := &reader{
+		refCount:  1,
+		schema:    schema,
+		bldr:      bldr,
+		appenders: make([]func(interface{}) error, len(cols)),
+		batchSize: batchSize,
+		rows:      rows,
+		next:      next,
+		done:      done,
+		vals:      make([]interface{}, len(cols)),
+		dest:      make([]interface{}, len(cols)),
+	}
+	for i := range cols {
+		rdr.appenders[i] = newAppender(bldr.Field(i))
+		rdr.dest[i] = &rdr.vals[i]
+	}
+	return rdr, nil
+}
+
+func (r *reader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *reader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *reader) Err() error {
+	return r.err
+}
+
+// nextRows closes the exhausted result set and moves on to the next
+// one, returning false once there are none left.
+func (r *reader) nextRows() bool {
+	if err := r.rows.Err(); err != nil {
+		r.err = errToAdbcErr(adbc.StatusIO, err)
+	}
+	r.rows.Close()
+	r.rows = nil
+	if r.err != nil || r.next == nil {
+		return false
+	}
+
+	rows, err := r.next()
+	if err != nil {
+		r.err = errToAdbcErr(adbc.StatusIO, err)
+		return false
+	}
+	if rows == nil {
+		return false
+	}
+
+	r.rows = rows
+	cols, err := rows.Columns()
+	if err != nil {
+		r.err = errToAdbcErr(adbc.StatusIO, err)
+		return false
+	}
+	if len(cols) != len(r.appenders) {
+		r.err = adbc.Error{
+			Msg:  fmt.Sprintf("[database/sql] result sets have different numbers of columns: %d and %d", len(r.appenders), len(cols)),
+			Code: adbc.StatusInvalidData,
+		}
+		return false
+	}
+	return true
+}
+
+func (r *reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+
+	nrows := 0
+	for r.err == nil && r.rows != nil && nrows < r.batchSize {
+		if !r.rows.Next() {
+			r.nextRows()
+			continue
+		}
+
+		if err := r.rows.Scan(r.dest...); err != nil {
+			r.err = errToAdbcErr(adbc.StatusInvalidData, err)
+			break
+		}
+		for i, v := range r.vals {
+			if v == nil {
+				r.bldr.Field(i).AppendNull()
+				continue
+			}
+			if err := r.appenders[i](v); err != nil {
+				r.err = err
+				break
+			}
+		}
+		nrows++
+	}
+
+	if r.err != nil || nrows == 0 {
+		return false
+	}
+
+	r.rec = r.bldr.NewRecord()
+	return true
+}
+
+func (r *reader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *reader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		if r.rows != nil {
+			r.rows.Close()
+			r.rows = nil
+		}
+		r.bldr.Release()
+		if r.done != nil {
+			r.done()
+		}
+	}
+}
@@ -0,0 +1,494 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package databasesql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+type statement struct {
+	alloc     memory.Allocator
+	cnxn      *cnxn
+	batchSize int
+
+	query    string
+	prepared *sql.Stmt
+
+	bound      arrow.Record
+	streamBind array.RecordReader
+}
+
+func (st *statement) clearBinds() {
+	if st.bound != nil {
+		st.bound.Release()
+		st.bound = nil
+	} else if st.streamBind != nil {
+		st.streamBind.Release()
+		st.streamBind = nil
+	}
+}
+
+func (st *statement) closePrepared() error {
+	if st.prepared == nil {
+		return nil
+	}
+	defer func() { st.prepared = nil }()
+	return errToAdbcErr(adbc.StatusIO, st.prepared.Close())
+}
+
+// Close releases any relevant resources associated with this statement
+// and closes it (particularly if it is a prepared statement).
+//
+// A statement instance should not be used after Close is called.
+func (st *statement) Close() error {
+	if st.cnxn == nil {
+		return adbc.Error{
+			Msg:  "statement already closed",
+			Code: adbc.StatusInvalidState}
+	}
+
+	st.clearBinds()
+	st.cnxn = nil
+	return st.closePrepared()
+}
+
+// SetOption sets a string option on this statement
+func (st *statement) SetOption(key string, val string) error {
+	switch key {
+	case OptionBatchSize:
+		size, err := parseBatchSize(val)
+		if err != nil {
+			return err
+		}
+		st.batchSize = size
+	case adbc.OptionKeyIngestTargetTable, adbc.OptionKeyIngestMode:
+		return adbc.Error{
+			Msg:  "bulk ingestion is not supported by the database/sql adapter",
+			Code: adbc.StatusNotImplemented,
+		}
+	default:
+		return adbc.Error{
+			Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return nil
+}
+
+// SetSqlQuery sets the query string to be executed, discarding any
+// previously prepared statement.
+func (st *statement) SetSqlQuery(query string) error {
+	st.query = query
+	return st.closePrepared()
+}
+
+// Prepare prepares the query on the connection, so that it can be
+// executed repeatedly, including within later transactions.
+func (st *statement) Prepare(ctx context.Context) error {
+	if st.query == "" {
+		return adbc.Error{
+			Code: adbc.StatusInvalidState,
+			Msg:  "cannot prepare statement with no query",
+		}
+	}
+
+	if err := st.closePrepared(); err != nil {
+		return err
+	}
+
+	stmt, err := st.cnxn.conn.PrepareContext(ctx, st.query)
+	if err != nil {
+		return errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+	st.prepared = stmt
+	return nil
+}
+
+// preparedStmt returns the statement to execute bound parameters with,
+// preparing the query for just this execution if Prepare wasn't called.
+// The returned function must be called once the statement is done with.
+func (st *statement) preparedStmt(ctx context.Context) (*sql.Stmt, func(), error) {
+	if st.prepared != nil {
+		return st.cnxn.stmt(ctx, st.prepared), func() {}, nil
+	}
+
+	stmt, err := st.cnxn.conn.PrepareContext(ctx, st.query)
+	if err != nil {
+		return nil, nil, errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+	return st.cnxn.stmt(ctx, stmt), func() { stmt.Close() }, nil
+}
+
+// params returns an iterator over the bound parameters. A bound stream
+// can only be consumed once, so the iterator takes it over from the
+// statement and releases it once it is exhausted.
+func (st *statement) params() *paramRows {
+	if st.bound != nil {
+		return &paramRows{rec: st.bound}
+	}
+
+	p := &paramRows{stream: st.streamBind}
+	st.streamBind = nil
+	return p
+}
+
+// paramRows iterates over the rows of the bound parameters, converting
+// each to the arguments for one execution of the statement.
+type paramRows struct {
+	rec    arrow.Record
+	stream array.RecordReader
+	row    int
+
+	args []interface{}
+	err  error
+}
+
+func (p *paramRows) next() bool {
+	for p.rec == nil || p.row >= int(p.rec.NumRows()) {
+		if p.stream == nil {
+			return false
+		}
+		if !p.stream.Next() {
+			p.err = p.stream.Err()
+			p.release()
+			return false
+		}
+		p.rec, p.row = p.stream.Record(), 0
+	}
+
+	p.args = make([]interface{}, p.rec.NumCols())
+	for i, col := range p.rec.Columns() {
+		v, err := argValue(col, p.row)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.args[i] = v
+	}
+	p.row++
+	return true
+}
+
+func (p *paramRows) release() {
+	if p.stream != nil {
+		p.stream.Release()
+		p.stream = nil
+	}
+}
+
+// argValue converts a single arrow value to an argument which any
+// database/sql driver accepts through the default parameter converter.
+// Values without an equivalent, such as nested types, are passed as
+// JSON text.
+func argValue(arr arrow.Array, i int) (interface{}, error) {
+	if arr.IsNull(i) {
+		return nil, nil
+	}
+
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		return arr.Value(i), nil
+	case *array.Int8:
+		return int64(arr.Value(i)), nil
+	case *array.Int16:
+		return int64(arr.Value(i)), nil
+	case *array.Int32:
+		return int64(arr.Value(i)), nil
+	case *array.Int64:
+		return arr.Value(i), nil
+	case *array.Uint8:
+		return int64(arr.Value(i)), nil
+	case *array.Uint16:
+		return int64(arr.Value(i)), nil
+	case *array.Uint32:
+		return int64(arr.Value(i)), nil
+	case *array.Uint64:
+		// database/sql only converts uint64 values up to the largest
+		// int64, report larger ones rather than its generic error
+		v := arr.Value(i)
+		if v > math.MaxInt64 {
+			return nil, adbc.Error{
+				Msg:  fmt.Sprintf("[database/sql] uint64 parameter value %d overflows int64", v),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		return int64(v), nil
+	case *array.Float16:
+		return float64(arr.Value(i).Float32()), nil
+	case *array.Float32:
+		return float64(arr.Value(i)), nil
+	case *array.Float64:
+		return arr.Value(i), nil
+	case *array.String:
+		return arr.Value(i), nil
+	case *array.LargeString:
+		return arr.Value(i), nil
+	case *array.Binary:
+		return append([]byte(nil), arr.Value(i)...), nil
+	case *array.LargeBinary:
+		return append([]byte(nil), arr.Value(i)...), nil
+	case *array.FixedSizeBinary:
+		return append([]byte(nil), arr.Value(i)...), nil
+	case *array.Date32:
+		return arr.Value(i).ToTime(), nil
+	case *array.Date64:
+		return arr.Value(i).ToTime(), nil
+	case *array.Time32:
+		unit := arr.DataType().(*arrow.Time32Type).Unit
+		return arr.Value(i).ToTime(unit).Format("15:04:05.999999999"), nil
+	case *array.Time64:
+		unit := arr.DataType().(*arrow.Time64Type).Unit
+		return arr.Value(i).ToTime(unit).Format("15:04:05.999999999"), nil
+	case *array.Timestamp:
+		unit := arr.DataType().(*arrow.TimestampType).Unit
+		return arr.Value(i).ToTime(unit), nil
+	case *array.Duration:
+		return int64(arr.Value(i)), nil
+	case *array.Decimal128:
+		return arr.Value(i).ToString(arr.DataType().(*arrow.Decimal128Type).Scale), nil
+	case *array.Decimal256:
+		return arr.Value(i).ToString(arr.DataType().(*arrow.Decimal256Type).Scale), nil
+	case *array.Dictionary:
+		return argValue(arr.Dictionary(), arr.GetValueIndex(i))
+	case array.ExtensionArray:
+		return argValue(arr.Storage(), i)
+	}
+
+	switch arr.DataType().ID() {
+	case arrow.LIST, arrow.LARGE_LIST, arrow.FIXED_SIZE_LIST, arrow.STRUCT,
+		arrow.MAP, arrow.SPARSE_UNION, arrow.DENSE_UNION:
+		b, err := json.Marshal(arr.GetOneForMarshal(i))
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  err.Error(),
+				Code: adbc.StatusInvalidData,
+			}
+		}
+		return string(b), nil
+	}
+
+	return nil, adbc.Error{
+		Msg:  fmt.Sprintf("[database/sql] unsupported parameter type %s", arr.DataType()),
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+func (st *statement) hasBinds() bool {
+	return st.bound != nil || st.streamBind != nil
+}
+
+func (st *statement) checkQuery() error {
+	if st.query == "" {
+		return adbc.Error{
+			Msg:  "cannot execute without a query",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	return nil
+}
+
+// ExecuteQuery executes the current query or prepared statement
+// and returnes a RecordReader for the results. The number of rows
+// affected is not known and is always -1.
+//
+// With bound parameters the statement is executed once per bound row
+// and the result sets are read one after another, as they would be
+// for a single query.
+func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	if err := st.checkQuery(); err != nil {
+		return nil, -1, err
+	}
+
+	if !st.hasBinds() {
+		var (
+			rows *sql.Rows
+			err  error
+		)
+		if st.prepared != nil {
+			rows, err = st.cnxn.stmt(ctx, st.prepared).QueryContext(ctx)
+		} else {
+			rows, err = st.cnxn.queryer().QueryContext(ctx, st.query)
+		}
+		if err != nil {
+			return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		rdr, err := newRecordReader(st.alloc, rows, nil, nil, st.batchSize)
+		return rdr, -1, err
+	}
+
+	stmt, closeStmt, err := st.preparedStmt(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	params := st.params()
+	done := func() {
+		params.release()
+		closeStmt()
+	}
+	next := func() (*sql.Rows, error) {
+		if !params.next() {
+			return nil, params.err
+		}
+		return stmt.QueryContext(ctx, params.args...)
+	}
+
+	rows, err := next()
+	if err == nil && rows == nil {
+		err = adbc.Error{
+			Msg:  "cannot execute query without any rows of bound parameters",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if err != nil {
+		done()
+		return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	rdr, err := newRecordReader(st.alloc, rows, next, done, st.batchSize)
+	return rdr, -1, err
+}
+
+// ExecuteUpdate executes a statement that does not generate a result
+// set, once per bound row if parameters are bound. It returns the total
+// number of rows affected if the driver reports it, otherwise -1.
+func (st *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	if err := st.checkQuery(); err != nil {
+		return -1, err
+	}
+
+	if !st.hasBinds() {
+		var (
+			res sql.Result
+			err error
+		)
+		if st.prepared != nil {
+			res, err = st.cnxn.stmt(ctx, st.prepared).ExecContext(ctx)
+		} else {
+			res, err = st.cnxn.queryer().ExecContext(ctx, st.query)
+		}
+		if err != nil {
+			return -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			n = -1
+		}
+		return n, nil
+	}
+
+	stmt, closeStmt, err := st.preparedStmt(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer closeStmt()
+
+	params := st.params()
+	defer params.release()
+
+	var total int64
+	for params.next() {
+		res, err := stmt.ExecContext(ctx, params.args...)
+		if err != nil {
+			return -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		if total >= 0 {
+			n, err := res.RowsAffected()
+			if err != nil {
+				total = -1
+			} else {
+				total += n
+			}
+		}
+	}
+	if params.err != nil {
+		return -1, errToAdbcErr(adbc.StatusInvalidData, params.err)
+	}
+	return total, nil
+}
+
+// SetSubstraitPlan is not supported as database/sql only accepts SQL.
+func (st *statement) SetSubstraitPlan(plan []byte) error {
+	return adbc.Error{
+		Msg:  "the database/sql adapter does not support Substrait plans",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// Bind uses an arrow record batch to bind parameters to the query.
+//
+// The statement is executed once for each row, with the columns of
+// the record as its positional parameters. The record is kept so the
+// statement can be executed again with the same parameters.
+func (st *statement) Bind(_ context.Context, values arrow.Record) error {
+	st.clearBinds()
+
+	st.bound = values
+	if st.bound != nil {
+		st.bound.Retain()
+	}
+	return nil
+}
+
+// BindStream uses a record batch stream to bind parameters for this
+// query, executing the statement once for each row of the stream.
+//
+// The stream is consumed by the next execution and released once it
+// is exhausted.
+func (st *statement) BindStream(_ context.Context, stream array.RecordReader) error {
+	st.clearBinds()
+
+	st.streamBind = stream
+	if st.streamBind != nil {
+		st.streamBind.Retain()
+	}
+	return nil
+}
+
+// GetParameterSchema is not supported as database/sql does not expose
+// the types of a prepared statement's parameters.
+func (st *statement) GetParameterSchema() (*arrow.Schema, error) {
+	return nil, adbc.Error{
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// ExecutePartitions is not supported as database/sql has no notion of
+// partitioned results.
+func (st *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	return nil, adbc.Partitions{}, -1, adbc.Error{
+		Msg:  "ExecutePartitions not supported by the database/sql adapter",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*statement)(nil)
+)
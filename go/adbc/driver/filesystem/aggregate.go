@@ -0,0 +1,476 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/scalar"
+	"golang.org/x/exp/constraints"
+)
+
+// aggregate evaluates an aggregate relation. Its whole input is read
+// into memory first, and the result is a single batch with a row per
+// group, or a single row if there are no groupings.
+func (e *executor) aggregate(r *aggregateRel) (array.RecordReader, error) {
+	input, err := e.rel(r.input)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := e.materialize(input)
+	input.Release()
+	if err != nil {
+		return nil, err
+	}
+	defer rec.Release()
+
+	var (
+		fields = make([]arrow.Field, 0, len(r.groupings)+len(r.measures))
+		cols   = make([]arrow.Array, 0, cap(fields))
+	)
+	defer func() {
+		for _, c := range cols {
+			c.Release()
+		}
+	}()
+
+	keys := make([]arrow.Array, 0, len(r.groupings))
+	defer func() {
+		for _, k := range keys {
+			k.Release()
+		}
+	}()
+	for _, g := range r.groupings {
+		k, err := e.evalArray(g, rec)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+		fields = append(fields, arrow.Field{Name: g.name(rec.Schema()), Type: k.DataType(), Nullable: true})
+	}
+
+	groupOf, firstRows := groupRows(keys, int(rec.NumRows()))
+	if len(keys) == 0 {
+		// an aggregation without groupings always has one row, even
+		// over empty input
+		firstRows = []int{0}
+	} else {
+		first := makeIndices(e, firstRows)
+		for _, k := range keys {
+			col, err := compute.TakeArray(e.ctx, k, first)
+			if err != nil {
+				first.Release()
+				return nil, errToAdbcErr(adbc.StatusInternal, err)
+			}
+			cols = append(cols, col)
+		}
+		first.Release()
+	}
+
+	for _, m := range r.measures {
+		col, err := e.measure(m, rec, groupOf, len(firstRows))
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+		fields = append(fields, arrow.Field{Name: m.fn.name(rec.Schema()), Type: col.DataType(), Nullable: true})
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	out := array.NewRecord(schema, cols, int64(len(firstRows)))
+	defer out.Release()
+	return array.NewRecordReader(schema, []arrow.Record{out})
+}
+
+// materialize reads every batch of a reader into a single record.
+func (e *executor) materialize(rdr array.RecordReader) (arrow.Record, error) {
+	var recs []arrow.Record
+	defer func() {
+		for _, r := range recs {
+			r.Release()
+		}
+	}()
+	for rdr.Next() {
+		rdr.Record().Retain()
+		recs = append(recs, rdr.Record())
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, err
+	}
+
+	switch len(recs) {
+	case 0:
+		return emptyRecord(e.alloc, rdr.Schema()), nil
+	case 1:
+		recs[0].Retain()
+		return recs[0], nil
+	}
+
+	var rows int64
+	cols := make([]arrow.Array, len(rdr.Schema().Fields()))
+	defer func() {
+		for _, c := range cols {
+			if c != nil {
+				c.Release()
+			}
+		}
+	}()
+	for i := range cols {
+		chunks := make([]arrow.Array, len(recs))
+		for j, r := range recs {
+			chunks[j] = r.Column(i)
+		}
+		col, err := array.Concatenate(chunks, e.alloc)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusInternal, err)
+		}
+		cols[i] = col
+	}
+	for _, r := range recs {
+		rows += r.NumRows()
+	}
+	return array.NewRecord(rdr.Schema(), cols, rows), nil
+}
+
+// groupRows assigns each row to a group by the values of the keys,
+// returning the group of each row and the first row of each group.
+func groupRows(keys []arrow.Array, rows int) (groupOf []int, firstRows []int) {
+	groupOf = make([]int, rows)
+	if len(keys) == 0 {
+		return groupOf, nil
+	}
+
+	groups := make(map[string]int)
+	var buf bytes.Buffer
+	for i := 0; i < rows; i++ {
+		buf.Reset()
+		for _, k := range keys {
+			encodeKey(&buf, k, i)
+		}
+
+		g, ok := groups[buf.String()]
+		if !ok {
+			g = len(firstRows)
+			groups[buf.String()] = g
+			firstRows = append(firstRows, i)
+		}
+		groupOf[i] = g
+	}
+	return groupOf, firstRows
+}
+
+// encodeKey appends an unambiguous encoding of a value to buf, so that
+// the concatenated encodings of several keys identify a group.
+func encodeKey(buf *bytes.Buffer, arr arrow.Array, i int) {
+	if arr.IsNull(i) {
+		buf.WriteByte(0)
+		return
+	}
+	buf.WriteByte(1)
+
+	writeBytes := func(b []byte) {
+		var n [binary.MaxVarintLen64]byte
+		buf.Write(n[:binary.PutUvarint(n[:], uint64(len(b)))])
+		buf.Write(b)
+	}
+
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		if arr.Value(i) {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		return
+	case *array.String:
+		writeBytes([]byte(arr.Value(i)))
+		return
+	case *array.LargeString:
+		writeBytes([]byte(arr.Value(i)))
+		return
+	case *array.Binary:
+		writeBytes(arr.Value(i))
+		return
+	case *array.LargeBinary:
+		writeBytes(arr.Value(i))
+		return
+	}
+
+	if fw, ok := arr.DataType().(arrow.FixedWidthDataType); ok && fw.BitWidth()%8 == 0 && arr.DataType().ID() != arrow.DICTIONARY {
+		width := fw.BitWidth() / 8
+		start := (arr.Data().Offset() + i) * width
+		buf.Write(arr.Data().Buffers()[1].Bytes()[start : start+width])
+		return
+	}
+
+	sc, err := scalar.GetScalar(arr, i)
+	if err != nil {
+		writeBytes([]byte(err.Error()))
+		return
+	}
+	if r, ok := sc.(scalar.Releasable); ok {
+		defer r.Release()
+	}
+	writeBytes([]byte(sc.String()))
+}
+
+func makeIndices(e *executor, rows []int) arrow.Array {
+	bldr := array.NewInt64Builder(e.alloc)
+	defer bldr.Release()
+	bldr.Reserve(len(rows))
+	for _, r := range rows {
+		if r < 0 {
+			bldr.AppendNull()
+		} else {
+			bldr.UnsafeAppend(int64(r))
+		}
+	}
+	return bldr.NewArray()
+}
+
+// measure computes an aggregate function for each group.
+func (e *executor) measure(m measure, rec arrow.Record, groupOf []int, groups int) (arrow.Array, error) {
+	fn := m.fn.fn
+	switch fn {
+	case "count", "sum", "avg", "min", "max":
+	default:
+		return nil, errUnsupportedPlan("aggregate function %s is not supported", fn)
+	}
+
+	// include reports whether a row passes the measure's filter
+	include := func(int) bool { return true }
+	if m.filter != nil {
+		mask, err := e.evalArray(m.filter, rec)
+		if err != nil {
+			return nil, err
+		}
+		defer mask.Release()
+		bools, ok := mask.(*array.Boolean)
+		if !ok {
+			return nil, errInvalidPlan("measure filter must be boolean, got %s", mask.DataType())
+		}
+		include = func(i int) bool { return bools.IsValid(i) && bools.Value(i) }
+	}
+
+	if fn == "count" && len(m.fn.args) == 0 {
+		counts := make([]int64, groups)
+		for i, g := range groupOf {
+			if include(i) {
+				counts[g]++
+			}
+		}
+		return int64Array(e, counts, nil), nil
+	}
+	if len(m.fn.args) != 1 {
+		return nil, errInvalidPlan("aggregate function %s takes 1 argument, got %d", fn, len(m.fn.args))
+	}
+
+	arg, err := e.evalArray(m.fn.args[0], rec)
+	if err != nil {
+		return nil, err
+	}
+	defer arg.Release()
+
+	valid := func(i int) bool { return arg.IsValid(i) && include(i) }
+
+	switch fn {
+	case "count":
+		counts := make([]int64, groups)
+		for i, g := range groupOf {
+			if valid(i) {
+				counts[g]++
+			}
+		}
+		return int64Array(e, counts, nil), nil
+	case "min", "max":
+		less, err := lessFunc(arg)
+		if err != nil {
+			return nil, err
+		}
+		best := make([]int, groups)
+		for g := range best {
+			best[g] = -1
+		}
+		for i, g := range groupOf {
+			if !valid(i) {
+				continue
+			}
+			if b := best[g]; b < 0 || (fn == "min" && less(i, b)) || (fn == "max" && less(b, i)) {
+				best[g] = i
+			}
+		}
+		indices := makeIndices(e, best)
+		defer indices.Release()
+		out, err := compute.TakeArray(e.ctx, arg, indices)
+		return out, errToAdbcErr(adbc.StatusInternal, err)
+	}
+
+	// sum and avg
+	id := arg.DataType().ID()
+	if !arrow.IsInteger(id) && !arrow.IsFloating(id) {
+		return nil, errUnsupportedPlan("aggregate function %s is not supported for %s", fn, arg.DataType())
+	}
+	seen := make([]bool, groups)
+	counts := make([]int64, groups)
+	for i, g := range groupOf {
+		if valid(i) {
+			seen[g] = true
+			counts[g]++
+		}
+	}
+
+	if fn == "sum" && arrow.IsSignedInteger(id) {
+		vals, err := castValues[int64](e, arg, arrow.PrimitiveTypes.Int64)
+		if err != nil {
+			return nil, err
+		}
+		sums := make([]int64, groups)
+		for i, g := range groupOf {
+			if valid(i) {
+				sums[g] += vals[i]
+			}
+		}
+		return int64Array(e, sums, seen), nil
+	}
+	if fn == "sum" && arrow.IsUnsignedInteger(id) {
+		vals, err := castValues[uint64](e, arg, arrow.PrimitiveTypes.Uint64)
+		if err != nil {
+			return nil, err
+		}
+		sums := make([]uint64, groups)
+		for i, g := range groupOf {
+			if valid(i) {
+				sums[g] += vals[i]
+			}
+		}
+		bldr := array.NewUint64Builder(e.alloc)
+		defer bldr.Release()
+		bldr.AppendValues(sums, seen)
+		return bldr.NewArray(), nil
+	}
+
+	vals, err := castValues[float64](e, arg, arrow.PrimitiveTypes.Float64)
+	if err != nil {
+		return nil, err
+	}
+	sums := make([]float64, groups)
+	for i, g := range groupOf {
+		if valid(i) {
+			sums[g] += vals[i]
+		}
+	}
+	if fn == "avg" {
+		for g := range sums {
+			if counts[g] > 0 {
+				sums[g] /= float64(counts[g])
+			}
+		}
+	}
+	bldr := array.NewFloat64Builder(e.alloc)
+	defer bldr.Release()
+	bldr.AppendValues(sums, seen)
+	return bldr.NewArray(), nil
+}
+
+func int64Array(e *executor, vals []int64, valid []bool) arrow.Array {
+	bldr := array.NewInt64Builder(e.alloc)
+	defer bldr.Release()
+	bldr.AppendValues(vals, valid)
+	return bldr.NewArray()
+}
+
+// castValues casts a numeric array and returns a copy of its values.
+// Null slots have undefined values.
+func castValues[T int64 | uint64 | float64](e *executor, arr arrow.Array, dt arrow.DataType) ([]T, error) {
+	casted, err := compute.CastToType(e.ctx, arr, dt)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+	defer casted.Release()
+
+	var vals interface{}
+	switch c := casted.(type) {
+	case *array.Int64:
+		vals = c.Int64Values()
+	case *array.Uint64:
+		vals = c.Uint64Values()
+	case *array.Float64:
+		vals = c.Float64Values()
+	}
+	return append([]T{}, vals.([]T)...), nil
+}
+
+func orderedLess[T constraints.Ordered](vals []T) func(i, j int) bool {
+	return func(i, j int) bool { return vals[i] < vals[j] }
+}
+
+// lessFunc returns a comparison of the values of an array by index, for
+// the types min and max are supported for.
+func lessFunc(arr arrow.Array) (func(i, j int) bool, error) {
+	switch arr := arr.(type) {
+	case *array.Int8:
+		return orderedLess(arr.Int8Values()), nil
+	case *array.Int16:
+		return orderedLess(arr.Int16Values()), nil
+	case *array.Int32:
+		return orderedLess(arr.Int32Values()), nil
+	case *array.Int64:
+		return orderedLess(arr.Int64Values()), nil
+	case *array.Uint8:
+		return orderedLess(arr.Uint8Values()), nil
+	case *array.Uint16:
+		return orderedLess(arr.Uint16Values()), nil
+	case *array.Uint32:
+		return orderedLess(arr.Uint32Values()), nil
+	case *array.Uint64:
+		return orderedLess(arr.Uint64Values()), nil
+	case *array.Float32:
+		return orderedLess(arr.Float32Values()), nil
+	case *array.Float64:
+		return orderedLess(arr.Float64Values()), nil
+	case *array.Date32:
+		return orderedLess(arr.Date32Values()), nil
+	case *array.Date64:
+		return orderedLess(arr.Date64Values()), nil
+	case *array.Time32:
+		return orderedLess(arr.Time32Values()), nil
+	case *array.Time64:
+		return orderedLess(arr.Time64Values()), nil
+	case *array.Timestamp:
+		return orderedLess(arr.TimestampValues()), nil
+	case *array.Duration:
+		return orderedLess(arr.DurationValues()), nil
+	case *array.Boolean:
+		return func(i, j int) bool { return !arr.Value(i) && arr.Value(j) }, nil
+	case *array.String:
+		return func(i, j int) bool { return strings.Compare(arr.Value(i), arr.Value(j)) < 0 }, nil
+	case *array.LargeString:
+		return func(i, j int) bool { return strings.Compare(arr.Value(i), arr.Value(j)) < 0 }, nil
+	case *array.Binary:
+		return func(i, j int) bool { return bytes.Compare(arr.Value(i), arr.Value(j)) < 0 }, nil
+	case *array.LargeBinary:
+		return func(i, j int) bool { return bytes.Compare(arr.Value(i), arr.Value(j)) < 0 }, nil
+	}
+	return nil, errUnsupportedPlan("min and max are not supported for %s", arr.DataType())
+}
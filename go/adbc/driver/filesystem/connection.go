@@ -0,0 +1,297 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/driver/internal"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"golang.org/x/exp/slices"
+)
+
+type cnxn struct {
+	db *database
+	// catalog is the name of the root directory
+	catalog string
+	closed  bool
+}
+
+// GetInfo returns metadata about the database/driver. As the data is
+// read and written by this driver, the vendor's Arrow version is the
+// driver's own.
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	const strValTypeID arrow.UnionTypeCode = 0
+
+	if len(infoCodes) == 0 {
+		infoCodes = infoSupportedCodes
+	}
+
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.GetInfoSchema)
+	defer bldr.Release()
+	bldr.Reserve(len(infoCodes))
+
+	infoNameBldr := bldr.Field(0).(*array.Uint32Builder)
+	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
+	strInfoBldr := infoValueBldr.Child(0).(*array.StringBuilder)
+
+	for _, code := range infoCodes {
+		switch code {
+		case adbc.InfoDriverName:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverName)
+		case adbc.InfoDriverVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverVersion)
+		case adbc.InfoDriverArrowVersion, adbc.InfoVendorArrowVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverArrowVersion)
+		case adbc.InfoVendorName:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoVendorName)
+		default:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.AppendNull()
+		}
+	}
+
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{final})
+}
+
+func compilePatterns(patterns ...*string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := internal.PatternToRegexp(p)
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  err.Error(),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		out[i] = re
+	}
+	return out, nil
+}
+
+func matches(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
+
+// GetObjects gets a hierarchical view of the root directory, its
+// subdirectories and data files. Column information is read from the
+// footer of each file, or inferred for CSV files.
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (array.RecordReader, error) {
+	g := internal.GetObjects{Ctx: ctx, Depth: depth, Catalog: catalog, DbSchema: dbSchema, TableName: tableName, ColumnName: columnName, TableType: tableType}
+	if err := g.Init(c.db.alloc, c.getObjectsDbSchemas, c.getObjectsTables); err != nil {
+		return nil, err
+	}
+	defer g.Release()
+
+	g.AppendCatalog(c.catalog)
+	return g.Finish()
+}
+
+func (c *cnxn) getObjectsDbSchemas(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string) (result map[string][]string, err error) {
+	if depth == adbc.ObjectDepthCatalogs {
+		return
+	}
+
+	patterns, err := compilePatterns(catalog, dbSchema)
+	if err != nil {
+		return nil, err
+	}
+	if !matches(patterns[0], c.catalog) {
+		return
+	}
+
+	schemas, err := c.db.dbSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	result = make(map[string][]string)
+	for _, s := range schemas {
+		if matches(patterns[1], s) {
+			result[c.catalog] = append(result[c.catalog], s)
+		}
+	}
+	return
+}
+
+func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (result internal.SchemaToTableInfo, err error) {
+	if depth == adbc.ObjectDepthCatalogs || depth == adbc.ObjectDepthDBSchemas {
+		return
+	}
+	if len(tableType) > 0 && !slices.Contains(tableType, tableTypeTable) {
+		return
+	}
+
+	patterns, err := compilePatterns(catalog, dbSchema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !matches(patterns[0], c.catalog) {
+		return
+	}
+
+	schemas, err := c.db.dbSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	result = make(internal.SchemaToTableInfo)
+	for _, s := range schemas {
+		if !matches(patterns[1], s) {
+			continue
+		}
+
+		tables, err := c.db.tables(s)
+		if err != nil {
+			return nil, err
+		}
+
+		key := internal.CatalogAndSchema{Catalog: c.catalog, Schema: s}
+		for _, t := range tables {
+			if !matches(patterns[2], t.name) {
+				continue
+			}
+
+			info := internal.TableInfo{Name: t.name, TableType: tableTypeTable}
+			if depth == adbc.ObjectDepthColumns {
+				if info.Schema, err = c.db.tableSchema(t); err != nil {
+					return nil, err
+				}
+			}
+			result[key] = append(result[key], info)
+		}
+	}
+	return
+}
+
+// GetTableSchema returns the schema of a table as stored in its file,
+// or as inferred for CSV files. If dbSchema is nil, the table name must
+// be unique across all db schemas.
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog *string, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	if catalog != nil && *catalog != c.catalog {
+		return nil, adbc.Error{
+			Msg:  "[filesystem] catalog not found: " + *catalog,
+			Code: adbc.StatusNotFound,
+		}
+	}
+
+	t, err := c.db.findTable(dbSchema, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return c.db.tableSchema(t)
+}
+
+// GetTableTypes returns the only table type, "table".
+func (c *cnxn) GetTableTypes(ctx context.Context) (array.RecordReader, error) {
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.TableTypesSchema)
+	defer bldr.Release()
+
+	bldr.Field(0).(*array.StringBuilder).Append(tableTypeTable)
+
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.TableTypesSchema, []arrow.Record{final})
+}
+
+// Commit is not supported as every change is written immediately.
+func (c *cnxn) Commit(ctx context.Context) error {
+	return adbc.Error{
+		Msg:  "no active transaction, cannot commit",
+		Code: adbc.StatusInvalidState,
+	}
+}
+
+// Rollback is not supported as every change is written immediately.
+func (c *cnxn) Rollback(ctx context.Context) error {
+	return adbc.Error{
+		Msg:  "no active transaction, cannot rollback",
+		Code: adbc.StatusInvalidState,
+	}
+}
+
+// NewStatement initializes a new statement object tied to this connection
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	return &statement{
+		alloc:     c.db.alloc,
+		cnxn:      c,
+		batchSize: c.db.batchSize,
+	}, nil
+}
+
+// Close closes this connection. There are no resources held between
+// calls, so it only marks the connection as closed.
+func (c *cnxn) Close() error {
+	if c.closed {
+		return adbc.Error{Code: adbc.StatusInvalidState}
+	}
+	c.closed = true
+	return nil
+}
+
+// ReadPartition is not supported as results are never partitioned.
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	return nil, adbc.Error{
+		Code: adbc.StatusNotImplemented,
+		Msg:  "ReadPartition not supported by the filesystem driver",
+	}
+}
+
+// SetOption sets a connection option. Only autocommit mode is
+// supported, as there are no transactions.
+func (c *cnxn) SetOption(key, value string) error {
+	switch key {
+	case adbc.OptionKeyAutoCommit:
+		switch value {
+		case adbc.OptionValueEnabled:
+			return nil
+		case adbc.OptionValueDisabled:
+			return adbc.Error{
+				Msg:  "[filesystem] transactions are not supported",
+				Code: adbc.StatusNotImplemented,
+			}
+		}
+	default:
+		return adbc.Error{
+			Msg:  "[filesystem] unknown connection option " + key + ": " + value,
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	return adbc.Error{
+		Msg:  "[filesystem] invalid value for option " + key + ": " + value,
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*cnxn)(nil)
+)
@@ -0,0 +1,251 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package filesystem is an ADBC Driver Implementation which exposes a
+// directory of data files as a read/write database, giving an offline,
+// dependency-free data source for development and tests.
+//
+// The root directory, given by adbc.OptionKeyURI as either a path or a
+// file:// URI, is the only catalog and is named after the directory.
+// The driver registers itself with adbc.RegisterDriver as "filesystem"
+// for the file scheme, so adbc.Open can open file:// URIs.
+// Each subdirectory of the root is a database schema, while files
+// directly in the root belong to the schema with the empty name. Every
+// Parquet (.parquet), Arrow IPC file (.arrow, .feather) or CSV (.csv)
+// file is a table named after the file without its extension:
+//
+//	data/
+//	  sales/
+//	    orders.parquet     -> table "orders" in schema "sales"
+//	    regions.csv        -> table "regions" in schema "sales"
+//	  lookup.arrow         -> table "lookup" in schema ""
+//
+// CSV files must have a header row; the type of each column is
+// inferred from the first OptionCSVInferRows rows as int64, float64,
+// boolean, date32 or utf8, and empty values are read as null.
+//
+// There is no SQL engine: queries are given as Substrait plans with
+// SetSubstraitPlan, and may read named tables then filter, project,
+// aggregate (count, sum, min, max and avg with optional grouping) and
+// fetch. Expressions are evaluated with the Arrow compute package.
+//
+// Bulk ingestion writes Parquet files. As Parquet files cannot be
+// extended in place, appending to a table rewrites its file.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	infoDriverName = "ADBC Filesystem Driver - Go"
+	infoVendorName = "Filesystem"
+
+	// The maximum number of rows in each record batch read from CSV
+	// and Parquet files. It can be set on the database, or on a
+	// statement to override the database's value. Defaults to 1024.
+	OptionBatchSize = "adbc.filesystem.batch_size"
+	// The number of rows of a CSV file used to infer the types of its
+	// columns. Defaults to 1000.
+	OptionCSVInferRows = "adbc.filesystem.csv.infer_rows"
+
+	defaultBatchSize    = 1024
+	defaultCSVInferRows = 1000
+	tableTypeTable      = "table"
+)
+
+var (
+	infoDriverVersion      string
+	infoDriverArrowVersion string
+	infoSupportedCodes     []adbc.InfoCode
+)
+
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			switch {
+			case dep.Path == "github.com/apache/arrow-adbc/go/adbc/driver/filesystem":
+				infoDriverVersion = dep.Version
+			case strings.HasPrefix(dep.Path, "github.com/apache/arrow/go/"):
+				infoDriverArrowVersion = dep.Version
+			}
+		}
+	}
+	// XXX: Deps not populated in tests
+	// https://github.com/golang/go/issues/33976
+	if infoDriverVersion == "" {
+		infoDriverVersion = "(unknown or development build)"
+	}
+	if infoDriverArrowVersion == "" {
+		infoDriverArrowVersion = "(unknown or development build)"
+	}
+
+	infoSupportedCodes = []adbc.InfoCode{
+		adbc.InfoDriverName,
+		adbc.InfoDriverVersion,
+		adbc.InfoDriverArrowVersion,
+		adbc.InfoVendorName,
+		adbc.InfoVendorArrowVersion,
+	}
+
+	adbc.RegisterDriver("filesystem", Driver{}, "file")
+}
+
+func errToAdbcErr(code adbc.Status, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e adbc.Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = adbc.StatusCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = adbc.StatusTimeout
+	case errors.Is(err, fs.ErrNotExist):
+		code = adbc.StatusNotFound
+	case errors.Is(err, fs.ErrExist):
+		code = adbc.StatusAlreadyExists
+	case errors.Is(err, fs.ErrPermission):
+		code = adbc.StatusUnauthorized
+	case errors.Is(err, arrow.ErrNotImplemented):
+		code = adbc.StatusNotImplemented
+	case errors.Is(err, arrow.ErrInvalid), errors.Is(err, arrow.ErrType):
+		code = adbc.StatusInvalidArgument
+	}
+
+	return adbc.Error{
+		Msg:  "[filesystem] " + err.Error(),
+		Code: code,
+	}
+}
+
+func parsePositiveInt(key, v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, adbc.Error{
+			Msg:  fmt.Sprintf("invalid value for option '%s': '%s', must be a positive integer", key, v),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return n, nil
+}
+
+type Driver struct {
+	Alloc memory.Allocator
+}
+
+func (d Driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	db := &database{alloc: d.Alloc, batchSize: defaultBatchSize, csvInferRows: defaultCSVInferRows}
+
+	opts = maps.Clone(opts)
+	if db.alloc == nil {
+		db.alloc = memory.DefaultAllocator
+	}
+
+	return db, db.SetOptions(opts)
+}
+
+type database struct {
+	alloc        memory.Allocator
+	root         string
+	batchSize    int
+	csvInferRows int
+}
+
+// rootFromURI accepts either a plain path or a file:// URI.
+func rootFromURI(uri string) (string, error) {
+	if !strings.HasPrefix(uri, "file:") {
+		return filepath.Clean(uri), nil
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil || (u.Host != "" && u.Host != "localhost") {
+		return "", adbc.Error{
+			Msg:  "[filesystem] invalid URI, expected a path or file:///path: " + uri,
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	if u.Opaque != "" {
+		// file:relative/path
+		return filepath.Clean(filepath.FromSlash(u.Opaque)), nil
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), nil
+}
+
+func (d *database) SetOptions(cnOptions map[string]string) error {
+	for k, v := range cnOptions {
+		var err error
+		switch k {
+		case adbc.OptionKeyURI:
+			d.root, err = rootFromURI(v)
+		case OptionBatchSize:
+			d.batchSize, err = parsePositiveInt(k, v)
+		case OptionCSVInferRows:
+			d.csvInferRows, err = parsePositiveInt(k, v)
+		default:
+			err = adbc.Error{
+				Msg:  "[filesystem] unknown database option " + k + ": " + v,
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
+	if d.root == "" {
+		return nil, adbc.Error{
+			Msg:  "[filesystem] option " + adbc.OptionKeyURI + " is required",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	info, err := os.Stat(d.root)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	if !info.IsDir() {
+		return nil, adbc.Error{
+			Msg:  "[filesystem] not a directory: " + d.root,
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	return &cnxn{db: d, catalog: filepath.Base(d.root)}, nil
+}
@@ -0,0 +1,709 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	driver "github.com/apache/arrow-adbc/go/adbc/driver/filesystem"
+	"github.com/apache/arrow-adbc/go/adbc/validation"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type FilesystemQuirks struct {
+	root string
+	mem  *memory.CheckedAllocator
+}
+
+func (q *FilesystemQuirks) SetupDriver(t *testing.T) adbc.Driver {
+	q.root = t.TempDir()
+	q.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	return driver.Driver{Alloc: q.mem}
+}
+
+func (q *FilesystemQuirks) TearDownDriver(t *testing.T, _ adbc.Driver) {
+	q.mem.AssertSize(t, 0)
+}
+
+func (q *FilesystemQuirks) DatabaseOptions() map[string]string {
+	return map[string]string{adbc.OptionKeyURI: q.root}
+}
+
+func (q *FilesystemQuirks) CreateSampleTable(tableName string, r arrow.Record) error {
+	db, err := driver.Driver{Alloc: q.mem}.NewDatabase(q.DatabaseOptions())
+	if err != nil {
+		return err
+	}
+	cnxn, err := db.Open(context.Background())
+	if err != nil {
+		return err
+	}
+	defer cnxn.Close()
+
+	stmt, err := cnxn.NewStatement()
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if err := stmt.SetOption(adbc.OptionKeyIngestTargetTable, tableName); err != nil {
+		return err
+	}
+	if err := stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeReplace); err != nil {
+		return err
+	}
+	if err := stmt.Bind(context.Background(), r); err != nil {
+		return err
+	}
+	_, err = stmt.ExecuteUpdate(context.Background())
+	return err
+}
+
+func (q *FilesystemQuirks) DropTable(_ adbc.Connection, tblname string) error {
+	for _, ext := range []string{".parquet", ".arrow", ".feather", ".csv"} {
+		if err := os.Remove(filepath.Join(q.root, tblname+ext)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *FilesystemQuirks) Alloc() memory.Allocator               { return q.mem }
+func (q *FilesystemQuirks) BindParameter(_ int) string            { return "" }
+func (q *FilesystemQuirks) SupportsConcurrentStatements() bool    { return true }
+func (q *FilesystemQuirks) SupportsPartitionedData() bool         { return false }
+func (q *FilesystemQuirks) SupportsTransactions() bool            { return false }
+func (q *FilesystemQuirks) SupportsGetParameterSchema() bool      { return false }
+func (q *FilesystemQuirks) SupportsDynamicParameterBinding() bool { return false }
+func (q *FilesystemQuirks) SupportsBulkIngest() bool              { return true }
+func (q *FilesystemQuirks) SupportsBulkIngestMode(mode string) bool {
+	return mode != adbc.OptionValueIngestModeMerge
+}
+func (q *FilesystemQuirks) DBSchema() string { return "" }
+func (q *FilesystemQuirks) GetMetadata(code adbc.InfoCode) interface{} {
+	switch code {
+	case adbc.InfoDriverName:
+		return "ADBC Filesystem Driver - Go"
+	// runtime/debug.ReadBuildInfo doesn't currently work for tests
+	// github.com/golang/go/issues/33976
+	case adbc.InfoDriverVersion:
+		return "(unknown or development build)"
+	case adbc.InfoDriverArrowVersion, adbc.InfoVendorArrowVersion:
+		return arrowVersion()
+	case adbc.InfoVendorName:
+		return "Filesystem"
+	}
+
+	return nil
+}
+
+// arrowVersion is the Arrow version the driver reports, which newer
+// toolchains do record in the build info of tests.
+func arrowVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if strings.HasPrefix(dep.Path, "github.com/apache/arrow/go/") {
+				return dep.Version
+			}
+		}
+	}
+	return "(unknown or development build)"
+}
+
+func (q *FilesystemQuirks) SampleTableSchemaMetadata(tblName string, dt arrow.DataType) arrow.Metadata {
+	return arrow.Metadata{}
+}
+
+func TestValidation(t *testing.T) {
+	q := &FilesystemQuirks{}
+	suite.Run(t, &validation.DatabaseTests{Quirks: q})
+	suite.Run(t, &validation.ConnectionTests{Quirks: q})
+}
+
+// planBuilder serializes Substrait plans field by field, so that the
+// tests don't need the generated Substrait bindings.
+type planBuilder struct {
+	functions []string
+}
+
+func msg(num protowire.Number, fields ...[]byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, bytes.Join(fields, nil))
+}
+
+func varint(num protowire.Number, v int64) []byte {
+	b := protowire.AppendTag(nil, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func str(num protowire.Number, vals ...string) []byte {
+	var b []byte
+	for _, v := range vals {
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+	return b
+}
+
+func (p *planBuilder) plan(root []byte, names ...string) []byte {
+	var out []byte
+	for i, fn := range p.functions {
+		out = append(out, msg(2, msg(3, varint(2, int64(i+1)), str(3, fn)))...)
+	}
+	return append(out, msg(3, msg(2, msg(1, root), str(2, names...)))...)
+}
+
+func (p *planBuilder) fnRef(name string) int64 {
+	for i, fn := range p.functions {
+		if fn == name {
+			return int64(i + 1)
+		}
+	}
+	p.functions = append(p.functions, name)
+	return int64(len(p.functions))
+}
+
+func (p *planBuilder) call(name string, args ...[]byte) []byte {
+	fields := [][]byte{varint(1, p.fnRef(name))}
+	for _, a := range args {
+		fields = append(fields, msg(4, msg(3, a)))
+	}
+	return msg(3, fields...)
+}
+
+func (p *planBuilder) measure(name string, filter []byte, args ...[]byte) []byte {
+	fields := [][]byte{varint(1, p.fnRef(name))}
+	for _, a := range args {
+		fields = append(fields, msg(7, msg(3, a)))
+	}
+	if filter != nil {
+		return msg(4, msg(1, fields...), msg(2, filter))
+	}
+	return msg(4, msg(1, fields...))
+}
+
+func emit(cols ...int64) []byte {
+	var fields [][]byte
+	for _, c := range cols {
+		fields = append(fields, varint(1, c))
+	}
+	return msg(1, msg(2, fields...))
+}
+
+func read(table ...string) []byte { return msg(1, msg(7, str(1, table...))) }
+
+func readColumns(table string, cols []string, extra ...[]byte) []byte {
+	fields := append([][]byte{msg(2, str(1, cols...)), msg(7, str(1, table))}, extra...)
+	return msg(1, fields...)
+}
+
+func filter(input, cond []byte) []byte { return msg(2, msg(2, input), msg(3, cond)) }
+
+func fetch(input []byte, offset, count int64) []byte {
+	return msg(3, msg(2, input), varint(3, offset), varint(4, count))
+}
+
+func project(input []byte, common []byte, exprs ...[]byte) []byte {
+	fields := [][]byte{common, msg(2, input)}
+	for _, e := range exprs {
+		fields = append(fields, msg(3, e))
+	}
+	return msg(7, fields...)
+}
+
+func aggregate(input []byte, groupings [][]byte, measures ...[]byte) []byte {
+	var grouping [][]byte
+	for _, g := range groupings {
+		grouping = append(grouping, msg(1, g))
+	}
+	fields := [][]byte{msg(2, input)}
+	if groupings != nil {
+		fields = append(fields, msg(3, grouping...))
+	}
+	return msg(4, append(fields, measures...)...)
+}
+
+func field(i int64) []byte { return msg(2, msg(1, msg(2, varint(1, i)))) }
+
+func i64(v int64) []byte   { return msg(1, varint(7, v)) }
+func text(v string) []byte { return msg(1, str(12, v)) }
+
+type FilesystemTests struct {
+	suite.Suite
+
+	mem  *memory.CheckedAllocator
+	ctx  context.Context
+	root string
+	db   adbc.Database
+	cnxn adbc.Connection
+	stmt adbc.Statement
+}
+
+func (s *FilesystemTests) SetupTest() {
+	s.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	s.ctx = context.Background()
+	s.root = filepath.Join(s.T().TempDir(), "data")
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.root, "sales"), 0o755))
+
+	var err error
+	s.db, err = driver.Driver{Alloc: s.mem}.NewDatabase(map[string]string{
+		adbc.OptionKeyURI:      "file://" + filepath.ToSlash(s.root),
+		driver.OptionBatchSize: "2",
+	})
+	s.Require().NoError(err)
+
+	s.cnxn, err = s.db.Open(s.ctx)
+	s.Require().NoError(err)
+	s.stmt, err = s.cnxn.NewStatement()
+	s.Require().NoError(err)
+
+	people := s.recordFromJSON(peopleSchema, `[
+		{"id": 1, "name": "alice", "age": 34, "city": "paris"},
+		{"id": 2, "name": "bob", "age": 27, "city": "berlin"},
+		{"id": 3, "name": "carol", "age": null, "city": "paris"},
+		{"id": 4, "name": "dave", "age": 45, "city": "rome"},
+		{"id": 5, "name": "erin", "age": 31, "city": "berlin"}
+	]`)
+	defer people.Release()
+	s.ingest("", "people", adbc.OptionValueIngestModeCreate, people)
+
+	items := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "item", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil), `[{"item": "apple", "price": 0.5}, {"item": "pear", "price": 0.75}]`)
+	defer items.Release()
+
+	f, err := os.Create(filepath.Join(s.root, "sales", "items.arrow"))
+	s.Require().NoError(err)
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(items.Schema()), ipc.WithAllocator(s.mem))
+	s.Require().NoError(err)
+	s.Require().NoError(w.Write(items))
+	s.Require().NoError(w.Close())
+	s.Require().NoError(f.Close())
+
+	s.Require().NoError(os.WriteFile(filepath.Join(s.root, "sales", "orders.csv"),
+		[]byte("id,item,qty,day,paid\n1,apple,3,2023-01-02,true\n2,pear,,2023-01-03,false\n3,apple,1,,\n"), 0o644))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.root, "notes.txt"), []byte("not a table"), 0o644))
+}
+
+func (s *FilesystemTests) TearDownTest() {
+	s.NoError(s.stmt.Close())
+	s.NoError(s.cnxn.Close())
+	s.mem.AssertSize(s.T(), 0)
+}
+
+var peopleSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "age", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	{Name: "city", Type: arrow.BinaryTypes.String, Nullable: true},
+}, nil)
+
+func (s *FilesystemTests) recordFromJSON(sc *arrow.Schema, data string) arrow.Record {
+	rec, _, err := array.RecordFromJSON(s.mem, sc, strings.NewReader(data))
+	s.Require().NoError(err)
+	return rec
+}
+
+func (s *FilesystemTests) ingest(dbSchema, table, mode string, rec arrow.Record) (int64, error) {
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, table))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetDBSchema, dbSchema))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestMode, mode))
+	s.Require().NoError(stmt.Bind(s.ctx, rec))
+	return stmt.ExecuteUpdate(s.ctx)
+}
+
+func (s *FilesystemTests) query(plan []byte) array.RecordReader {
+	s.Require().NoError(s.stmt.SetSubstraitPlan(plan))
+	rdr, n, err := s.stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(-1, n)
+	return rdr
+}
+
+func (s *FilesystemTests) queryErr(plan []byte, code adbc.Status) {
+	s.Require().NoError(s.stmt.SetSubstraitPlan(plan))
+	rdr, _, err := s.stmt.ExecuteQuery(s.ctx)
+	if err == nil {
+		// some errors only surface while reading
+		for rdr.Next() {
+		}
+		err = rdr.Err()
+		rdr.Release()
+	}
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(code, adbcErr.Code, adbcErr.Msg)
+}
+
+// checkResult reads all batches from rdr, checking their sizes and that
+// they match the expected record when concatenated.
+func (s *FilesystemTests) checkResult(rdr array.RecordReader, batchSizes []int64, expected arrow.Record) {
+	defer rdr.Release()
+	s.Truef(expected.Schema().Equal(rdr.Schema()), "expected: %s\ngot: %s", expected.Schema(), rdr.Schema())
+
+	var (
+		sizes  []int64
+		offset int64
+	)
+	for rdr.Next() {
+		rec := rdr.Record()
+		sizes = append(sizes, rec.NumRows())
+
+		slice := expected.NewSlice(offset, offset+rec.NumRows())
+		s.Truef(array.RecordEqual(slice, rec), "expected: %s\ngot: %s", slice, rec)
+		slice.Release()
+		offset += rec.NumRows()
+	}
+	s.NoError(rdr.Err())
+	s.Equal(batchSizes, sizes)
+}
+
+func (s *FilesystemTests) TestGetObjects() {
+	rdr, err := s.cnxn.GetObjects(s.ctx, adbc.ObjectDepthTables, nil, nil, nil, nil, nil)
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	var tables []string
+	for rdr.Next() {
+		rec := rdr.Record()
+		catalogs := rec.Column(0).(*array.String)
+		schemasList := rec.Column(1).(*array.List)
+		schemas := schemasList.ListValues().(*array.Struct)
+		tablesList := schemas.Field(1).(*array.List)
+		tableNames := tablesList.ListValues().(*array.Struct).Field(0).(*array.String)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			s.Equal("data", catalogs.Value(i))
+			start, end := schemasList.ValueOffsets(i)
+			for j := start; j < end; j++ {
+				schema := schemas.Field(0).(*array.String).Value(int(j))
+				tstart, tend := tablesList.ValueOffsets(int(j))
+				for k := tstart; k < tend; k++ {
+					tables = append(tables, schema+"."+tableNames.Value(int(k)))
+				}
+			}
+		}
+	}
+	s.NoError(rdr.Err())
+	s.Equal([]string{".people", "sales.items", "sales.orders"}, tables)
+}
+
+func (s *FilesystemTests) TestGetTableSchema() {
+	sc, err := s.cnxn.GetTableSchema(s.ctx, nil, nil, "people")
+	s.Require().NoError(err)
+	s.Truef(peopleSchema.Equal(sc), "expected: %s\ngot: %s", peopleSchema, sc)
+
+	sales := "sales"
+	sc, err = s.cnxn.GetTableSchema(s.ctx, nil, &sales, "orders")
+	s.Require().NoError(err)
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "item", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "qty", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "paid", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	}, nil)
+	s.Truef(expected.Equal(sc), "expected: %s\ngot: %s", expected, sc)
+
+	catalog := "data"
+	sc, err = s.cnxn.GetTableSchema(s.ctx, &catalog, nil, "items")
+	s.Require().NoError(err)
+	s.Equal([]string{"item", "price"}, []string{sc.Field(0).Name, sc.Field(1).Name})
+
+	var adbcErr adbc.Error
+	_, err = s.cnxn.GetTableSchema(s.ctx, nil, nil, "notes")
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotFound, adbcErr.Code)
+
+	other := "other"
+	_, err = s.cnxn.GetTableSchema(s.ctx, &other, nil, "people")
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotFound, adbcErr.Code)
+
+	escape := ".."
+	_, err = s.cnxn.GetTableSchema(s.ctx, nil, &escape, "people")
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func (s *FilesystemTests) TestReadTable() {
+	var p planBuilder
+	expected := s.recordFromJSON(peopleSchema, `[
+		{"id": 1, "name": "alice", "age": 34, "city": "paris"},
+		{"id": 2, "name": "bob", "age": 27, "city": "berlin"},
+		{"id": 3, "name": "carol", "age": null, "city": "paris"},
+		{"id": 4, "name": "dave", "age": 45, "city": "rome"},
+		{"id": 5, "name": "erin", "age": 31, "city": "berlin"}
+	]`)
+	defer expected.Release()
+
+	s.checkResult(s.query(p.plan(read("people"))), []int64{2, 2, 1}, expected)
+	s.checkResult(s.query(p.plan(read("data", "", "people"))), []int64{2, 2, 1}, expected)
+
+	orders := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "item", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "qty", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "paid", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	}, nil), `[
+		{"id": 1, "item": "apple", "qty": 3, "day": 19359, "paid": true},
+		{"id": 2, "item": "pear", "qty": null, "day": 19360, "paid": false},
+		{"id": 3, "item": "apple", "qty": 1, "day": null, "paid": null}
+	]`)
+	defer orders.Release()
+	s.checkResult(s.query(p.plan(read("sales", "orders"))), []int64{2, 1}, orders)
+
+	s.queryErr(p.plan(read("other", "sales", "orders")), adbc.StatusNotFound)
+	s.queryErr(p.plan(read("missing")), adbc.StatusNotFound)
+	s.queryErr(p.plan(readColumns("people", []string{"missing"})), adbc.StatusNotFound)
+}
+
+func (s *FilesystemTests) TestFilterProject() {
+	var p planBuilder
+	// SELECT name AS who, age + 1 AS next FROM people WHERE age > 30 AND city <> 'rome'
+	scan := readColumns("people", []string{"name", "age", "city"})
+	cond := p.call("and",
+		p.call("gt:i64_i64", field(1), i64(30)),
+		p.call("not_equal:str_str", field(2), text("rome")))
+	plan := p.plan(project(filter(scan, cond), emit(0, 3), p.call("add:i64_i64", field(1), i64(1))), "who", "next")
+
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "who", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "next", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil), `[{"who": "alice", "next": 35}, {"who": "erin", "next": 32}]`)
+	defer expected.Release()
+	s.checkResult(s.query(plan), []int64{1, 1}, expected)
+
+	// the filter and projection of a read relation itself
+	plan = p.plan(readColumns("people", []string{"id", "age"},
+		msg(3, p.call("is_null", field(1))),
+		msg(4, msg(1, msg(1, varint(1, 0))))))
+	ids := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil), `[{"id": 3}]`)
+	defer ids.Release()
+	s.checkResult(s.query(plan), []int64{1}, ids)
+
+	// a filter which isn't boolean
+	s.queryErr(p.plan(filter(read("people"), field(0))), adbc.StatusInvalidArgument)
+	s.queryErr(p.plan(filter(read("people"), p.call("soundex", field(1)))), adbc.StatusNotImplemented)
+	s.queryErr(p.plan(read("people"), "too", "few"), adbc.StatusInvalidArgument)
+}
+
+func (s *FilesystemTests) TestFetch() {
+	var p planBuilder
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil), `[{"id": 2}, {"id": 3}, {"id": 4}]`)
+	defer expected.Release()
+
+	scan := readColumns("people", []string{"id"})
+	s.checkResult(s.query(p.plan(fetch(scan, 1, 3))), []int64{1, 2}, expected)
+}
+
+func (s *FilesystemTests) TestAggregate() {
+	var p planBuilder
+	// SELECT city, count(*), sum(age), max(name), avg(age) FILTER (WHERE age > 30)
+	// FROM people GROUP BY city
+	plan := p.plan(aggregate(read("people"), [][]byte{field(3)},
+		p.measure("count", nil),
+		p.measure("sum", nil, field(2)),
+		p.measure("max", nil, field(1)),
+		p.measure("avg", p.call("gt", field(2), i64(30)), field(2))),
+		"city", "n", "total", "last", "older")
+
+	expected := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "city", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "n", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "total", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "last", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "older", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil), `[
+		{"city": "paris", "n": 2, "total": 34, "last": "carol", "older": 34},
+		{"city": "berlin", "n": 2, "total": 58, "last": "erin", "older": 31},
+		{"city": "rome", "n": 1, "total": 45, "last": "dave", "older": 45}
+	]`)
+	defer expected.Release()
+	s.checkResult(s.query(plan), []int64{3}, expected)
+
+	// without groupings, even over no rows
+	plan = p.plan(aggregate(filter(read("people"), p.call("lt", field(2), i64(0))), nil,
+		p.measure("count", nil, field(0)),
+		p.measure("min", nil, field(2))),
+		"n", "youngest")
+	empty := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "n", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "youngest", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil), `[{"n": 0, "youngest": null}]`)
+	defer empty.Release()
+	s.checkResult(s.query(plan), []int64{1}, empty)
+
+	s.queryErr(p.plan(aggregate(read("people"), nil, p.measure("median", nil, field(2)))), adbc.StatusNotImplemented)
+}
+
+// substraitPlan encodes the Substrait plan of testdata/substrait, given
+// in the JSON form of the Substrait protobuf messages, with the
+// protobuf runtime rather than planBuilder. The message definitions
+// come from the subset of the Substrait schema in descriptor.pbtxt.
+func (s *FilesystemTests) substraitPlan(name string) []byte {
+	b, err := os.ReadFile(filepath.Join("testdata", "substrait", "descriptor.pbtxt"))
+	s.Require().NoError(err)
+	var set descriptorpb.FileDescriptorSet
+	s.Require().NoError(prototext.Unmarshal(b, &set))
+	files, err := protodesc.NewFiles(&set)
+	s.Require().NoError(err)
+	desc, err := files.FindDescriptorByName("substrait.Plan")
+	s.Require().NoError(err)
+
+	b, err = os.ReadFile(filepath.Join("testdata", "substrait", name+".json"))
+	s.Require().NoError(err)
+	plan := dynamicpb.NewMessage(desc.(protoreflect.MessageDescriptor))
+	s.Require().NoError(protojson.Unmarshal(b, plan))
+	b, err = proto.Marshal(plan)
+	s.Require().NoError(err)
+	return b
+}
+
+func (s *FilesystemTests) TestSubstraitFixtures() {
+	tests := []struct {
+		name       string
+		schema     *arrow.Schema
+		batchSizes []int64
+		expected   string
+	}{
+		{"filter_project", arrow.NewSchema([]arrow.Field{
+			{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "next", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		}, nil), []int64{1, 1}, `[{"name": "alice", "next": 35}, {"name": "erin", "next": 32}]`},
+		{"aggregate", arrow.NewSchema([]arrow.Field{
+			{Name: "city", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "n", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+			{Name: "total", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		}, nil), []int64{2}, `[{"city": "paris", "n": 2, "total": 34}, {"city": "rome", "n": 1, "total": 45}]`},
+		{"fetch_cast", arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		}, nil), []int64{1, 1}, `[{"id": 2}, {"id": 3}]`},
+	}
+	for _, tc := range tests {
+		s.Run(tc.name, func() {
+			expected := s.recordFromJSON(tc.schema, tc.expected)
+			defer expected.Release()
+			s.checkResult(s.query(s.substraitPlan(tc.name)), tc.batchSizes, expected)
+		})
+	}
+}
+
+func (s *FilesystemTests) TestUnsupported() {
+	var adbcErr adbc.Error
+	s.ErrorAs(s.stmt.SetSqlQuery("SELECT 1"), &adbcErr)
+	s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+
+	_, _, err := s.stmt.ExecuteQuery(s.ctx)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInvalidState, adbcErr.Code)
+
+	var p planBuilder
+	// a sort relation
+	s.queryErr(p.plan(msg(5, msg(2, read("people")))), adbc.StatusNotImplemented)
+	s.queryErr([]byte{0xff}, adbc.StatusInvalidArgument)
+}
+
+func (s *FilesystemTests) TestIngest() {
+	rec := s.recordFromJSON(arrow.NewSchema([]arrow.Field{
+		{Name: "n", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil), `[{"n": 1}, {"n": 2}]`)
+	defer rec.Release()
+
+	n, err := s.ingest("sales", "numbers", adbc.OptionValueIngestModeCreate, rec)
+	s.Require().NoError(err)
+	s.EqualValues(2, n)
+	_, err = os.Stat(filepath.Join(s.root, "sales", "numbers.parquet"))
+	s.NoError(err)
+
+	var adbcErr adbc.Error
+	_, err = s.ingest("sales", "numbers", adbc.OptionValueIngestModeCreate, rec)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusAlreadyExists, adbcErr.Code)
+
+	_, err = s.ingest("sales", "numbers", adbc.OptionValueIngestModeAppend, rec)
+	s.NoError(err)
+	_, err = s.ingest("sales", "numbers", adbc.OptionValueIngestModeCreateAppend, rec)
+	s.NoError(err)
+
+	var p planBuilder
+	all := s.recordFromJSON(rec.Schema(), `[{"n": 1}, {"n": 2}, {"n": 1}, {"n": 2}, {"n": 1}, {"n": 2}]`)
+	defer all.Release()
+	s.checkResult(s.query(p.plan(read("sales", "numbers"))), []int64{2, 2, 2}, all)
+
+	_, err = s.ingest("sales", "missing", adbc.OptionValueIngestModeAppend, rec)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotFound, adbcErr.Code)
+
+	_, err = s.ingest("sales", "orders", adbc.OptionValueIngestModeAppend, rec)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+
+	_, err = s.ingest("", "people", adbc.OptionValueIngestModeAppend, rec)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusAlreadyExists, adbcErr.Code)
+
+	_, err = s.ingest("nowhere", "numbers", adbc.OptionValueIngestModeCreate, rec)
+	s.ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusNotFound, adbcErr.Code)
+
+	// replacing a CSV table stores it as Parquet instead
+	n, err = s.ingest("sales", "orders", adbc.OptionValueIngestModeReplace, rec)
+	s.Require().NoError(err)
+	s.EqualValues(2, n)
+	_, err = os.Stat(filepath.Join(s.root, "sales", "orders.csv"))
+	s.True(os.IsNotExist(err))
+	s.checkResult(s.query(p.plan(read("orders"))), []int64{2}, rec)
+
+	s.ErrorAs(s.stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeMerge), &adbcErr)
+	s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+	s.ErrorAs(s.stmt.SetOption(adbc.OptionKeyIngestTemporary, adbc.OptionValueEnabled), &adbcErr)
+	s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+func TestFilesystem(t *testing.T) {
+	suite.Run(t, &FilesystemTests{})
+}
@@ -0,0 +1,521 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/arrow/scalar"
+)
+
+// scalarFunctions maps the names of Substrait scalar functions to the
+// Arrow compute functions implementing them. The boolean functions not,
+// is_null and is_not_null are evaluated separately.
+var scalarFunctions = map[string]string{
+	"add":       "add",
+	"subtract":  "sub",
+	"multiply":  "multiply",
+	"divide":    "divide",
+	"negate":    "negate",
+	"abs":       "abs",
+	"power":     "power",
+	"sign":      "sign",
+	"equal":     "equal",
+	"not_equal": "not_equal",
+	"lt":        "less",
+	"lte":       "less_equal",
+	"gt":        "greater",
+	"gte":       "greater_equal",
+	"and":       "and_kleene",
+	"or":        "or_kleene",
+	"xor":       "xor",
+}
+
+// executor runs a decoded plan. Relations are evaluated lazily, batch by
+// batch, as the returned reader is consumed, apart from aggregations
+// which need all of their input.
+type executor struct {
+	ctx       context.Context
+	db        *database
+	catalog   string
+	alloc     memory.Allocator
+	batchSize int
+}
+
+func (e *executor) execute(p *plan) (array.RecordReader, error) {
+	rdr, err := e.rel(p.root)
+	if err != nil || len(p.names) == 0 {
+		return rdr, err
+	}
+
+	schema, err := renameFields(rdr.Schema(), p.names)
+	if err != nil {
+		rdr.Release()
+		return nil, err
+	}
+	return newMapReader(rdr, schema, func(rec arrow.Record) (arrow.Record, bool, error) {
+		return array.NewRecord(schema, rec.Columns(), rec.NumRows()), false, nil
+	}), nil
+}
+
+// renameFields gives the top-level fields of a schema the names of a
+// plan's root, which also lists the names of nested fields.
+func renameFields(sc *arrow.Schema, names []string) (*arrow.Schema, error) {
+	fields := append([]arrow.Field{}, sc.Fields()...)
+	idx := 0
+	for i := range fields {
+		if idx < len(names) {
+			fields[i].Name = names[idx]
+		}
+		idx += 1 + countNestedNames(fields[i].Type)
+	}
+	if idx != len(names) {
+		return nil, errInvalidPlan("root has %d names for an output of %d columns", len(names), len(fields))
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func countNestedNames(dt arrow.DataType) int {
+	switch dt := dt.(type) {
+	case *arrow.StructType:
+		n := 0
+		for _, f := range dt.Fields() {
+			n += 1 + countNestedNames(f.Type)
+		}
+		return n
+	case *arrow.MapType:
+		return countNestedNames(dt.KeyType()) + countNestedNames(dt.ItemType())
+	case *arrow.ListType:
+		return countNestedNames(dt.Elem())
+	case *arrow.LargeListType:
+		return countNestedNames(dt.Elem())
+	case *arrow.FixedSizeListType:
+		return countNestedNames(dt.Elem())
+	}
+	return 0
+}
+
+func (e *executor) rel(r rel) (rdr array.RecordReader, err error) {
+	switch r := r.(type) {
+	case *readRel:
+		rdr, err = e.read(r)
+	case *filterRel:
+		rdr, err = e.filter(r)
+	case *projectRel:
+		rdr, err = e.project(r)
+	case *fetchRel:
+		rdr, err = e.fetch(r)
+	case *aggregateRel:
+		rdr, err = e.aggregate(r)
+	}
+	if err != nil || r.emit() == nil {
+		return rdr, err
+	}
+
+	out, err := selectColumns(rdr, r.emit())
+	if err != nil {
+		rdr.Release()
+	}
+	return out, err
+}
+
+// selectColumns returns a reader of the given columns of rdr, which it
+// takes ownership of.
+func selectColumns(rdr array.RecordReader, indices []int) (array.RecordReader, error) {
+	src := rdr.Schema()
+	fields := make([]arrow.Field, len(indices))
+	for i, idx := range indices {
+		if idx < 0 || idx >= len(src.Fields()) {
+			return nil, errInvalidPlan("column %d out of range for %d columns", idx, len(src.Fields()))
+		}
+		fields[i] = src.Field(idx)
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	return newMapReader(rdr, schema, func(rec arrow.Record) (arrow.Record, bool, error) {
+		cols := make([]arrow.Array, len(indices))
+		for i, idx := range indices {
+			cols[i] = rec.Column(idx)
+		}
+		return array.NewRecord(schema, cols, rec.NumRows()), false, nil
+	}), nil
+}
+
+func (e *executor) read(r *readRel) (array.RecordReader, error) {
+	var dbSchema *string
+	switch len(r.table) {
+	case 3:
+		if r.table[0] != e.catalog {
+			return nil, adbc.Error{
+				Msg:  "[filesystem] catalog not found: " + r.table[0],
+				Code: adbc.StatusNotFound,
+			}
+		}
+		dbSchema = &r.table[1]
+	case 2:
+		dbSchema = &r.table[0]
+	}
+
+	t, err := e.db.findTable(dbSchema, r.table[len(r.table)-1])
+	if err != nil {
+		return nil, err
+	}
+	rdr, err := e.db.openTable(e.ctx, t, e.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// field references are relative to the base schema, which may list
+	// the columns in a different order than the file
+	if r.baseNames != nil {
+		indices := make([]int, len(r.baseNames))
+		for i, name := range r.baseNames {
+			found := rdr.Schema().FieldIndices(name)
+			if len(found) == 0 {
+				rdr.Release()
+				return nil, adbc.Error{
+					Msg:  fmt.Sprintf("[filesystem] column %s not found in table %s", name, t.name),
+					Code: adbc.StatusNotFound,
+				}
+			}
+			indices[i] = found[0]
+		}
+		if rdr, err = selectColumns(rdr, indices); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.filter != nil {
+		rdr = e.filterReader(rdr, r.filter)
+	}
+
+	if r.projection != nil {
+		out, err := selectColumns(rdr, r.projection)
+		if err != nil {
+			rdr.Release()
+		}
+		return out, err
+	}
+	return rdr, nil
+}
+
+func (e *executor) filter(r *filterRel) (array.RecordReader, error) {
+	rdr, err := e.rel(r.input)
+	if err != nil {
+		return nil, err
+	}
+	return e.filterReader(rdr, r.cond), nil
+}
+
+func (e *executor) filterReader(rdr array.RecordReader, cond expr) array.RecordReader {
+	return newMapReader(rdr, rdr.Schema(), func(rec arrow.Record) (arrow.Record, bool, error) {
+		mask, err := e.evalArray(cond, rec)
+		if err != nil {
+			return nil, false, err
+		}
+		defer mask.Release()
+
+		if mask.DataType().ID() != arrow.BOOL {
+			return nil, false, errInvalidPlan("filter condition must be boolean, got %s", mask.DataType())
+		}
+		out, err := compute.FilterRecordBatch(e.ctx, rec, mask, compute.DefaultFilterOptions())
+		return out, false, err
+	})
+}
+
+func (e *executor) project(r *projectRel) (array.RecordReader, error) {
+	rdr, err := e.rel(r.input)
+	if err != nil {
+		return nil, err
+	}
+
+	// the types of the computed columns are found by evaluating the
+	// expressions over an empty batch
+	empty := emptyRecord(e.alloc, rdr.Schema())
+	defer empty.Release()
+
+	fields := append([]arrow.Field{}, rdr.Schema().Fields()...)
+	for _, ex := range r.exprs {
+		arr, err := e.evalArray(ex, empty)
+		if err != nil {
+			rdr.Release()
+			return nil, err
+		}
+		fields = append(fields, arrow.Field{Name: ex.name(rdr.Schema()), Type: arr.DataType(), Nullable: true})
+		arr.Release()
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	return newMapReader(rdr, schema, func(rec arrow.Record) (arrow.Record, bool, error) {
+		cols := append([]arrow.Array{}, rec.Columns()...)
+		computed := make([]arrow.Array, 0, len(r.exprs))
+		defer func() {
+			for _, c := range computed {
+				c.Release()
+			}
+		}()
+
+		for _, ex := range r.exprs {
+			arr, err := e.evalArray(ex, rec)
+			if err != nil {
+				return nil, false, err
+			}
+			computed = append(computed, arr)
+		}
+		return array.NewRecord(schema, append(cols, computed...), rec.NumRows()), false, nil
+	}), nil
+}
+
+func (e *executor) fetch(r *fetchRel) (array.RecordReader, error) {
+	rdr, err := e.rel(r.input)
+	if err != nil {
+		return nil, err
+	}
+
+	var seen int64
+	return newMapReader(rdr, rdr.Schema(), func(rec arrow.Record) (arrow.Record, bool, error) {
+		start, end := r.offset-seen, rec.NumRows()
+		if r.count >= 0 && r.offset+r.count-seen < end {
+			end = r.offset + r.count - seen
+		}
+		seen += rec.NumRows()
+
+		done := r.count >= 0 && seen >= r.offset+r.count
+		if start < 0 {
+			start = 0
+		}
+		if start >= end {
+			return nil, done, nil
+		}
+		return rec.NewSlice(start, end), done, nil
+	}), nil
+}
+
+func emptyRecord(mem memory.Allocator, sc *arrow.Schema) arrow.Record {
+	bldr := array.NewRecordBuilder(mem, sc)
+	defer bldr.Release()
+	return bldr.NewRecord()
+}
+
+// evalArray evaluates an expression over a batch, broadcasting scalar
+// results to the length of the batch.
+func (e *executor) evalArray(ex expr, rec arrow.Record) (arrow.Array, error) {
+	d, err := e.eval(ex, rec)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Release()
+
+	switch d := d.(type) {
+	case *compute.ArrayDatum:
+		return d.MakeArray(), nil
+	case *compute.ScalarDatum:
+		return scalar.MakeArrayFromScalar(d.Value, int(rec.NumRows()), e.alloc)
+	}
+	return nil, errInvalidPlan("expression %s did not evaluate to a value", ex.name(rec.Schema()))
+}
+
+func (e *executor) eval(ex expr, rec arrow.Record) (compute.Datum, error) {
+	switch ex := ex.(type) {
+	case *literalExpr:
+		return compute.NewDatum(ex.val), nil
+	case *fieldRefExpr:
+		return e.evalFieldRef(ex, rec)
+	case *castExpr:
+		in, err := e.eval(ex.input, rec)
+		if err != nil {
+			return nil, err
+		}
+		defer in.Release()
+		out, err := compute.CastDatum(e.ctx, in, compute.SafeCastOptions(ex.to))
+		return out, errToAdbcErr(adbc.StatusInvalidArgument, err)
+	case *callExpr:
+		return e.evalCall(ex, rec)
+	}
+	return nil, errUnsupportedPlan("unsupported expression %T", ex)
+}
+
+func (e *executor) evalFieldRef(ex *fieldRefExpr, rec arrow.Record) (compute.Datum, error) {
+	if idx := ex.path[0]; idx < 0 || idx >= int(rec.NumCols()) {
+		return nil, errInvalidPlan("field reference %d out of range for %d columns", idx, rec.NumCols())
+	}
+
+	arr := rec.Column(ex.path[0])
+	for _, idx := range ex.path[1:] {
+		st, ok := arr.(*array.Struct)
+		if !ok || idx < 0 || idx >= st.NumField() {
+			return nil, errInvalidPlan("invalid nested field reference %v", ex.path)
+		}
+		arr = st.Field(idx)
+	}
+	return compute.NewDatum(arr), nil
+}
+
+func (e *executor) evalCall(ex *callExpr, rec arrow.Record) (compute.Datum, error) {
+	args := make([]compute.Datum, 0, len(ex.args))
+	defer func() {
+		for _, a := range args {
+			a.Release()
+		}
+	}()
+	for _, a := range ex.args {
+		d, err := e.eval(a, rec)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, d)
+	}
+
+	checkArgs := func(n int) error {
+		if len(args) != n {
+			return errInvalidPlan("function %s takes %d arguments, got %d", ex.fn, n, len(args))
+		}
+		return nil
+	}
+
+	switch ex.fn {
+	case "not":
+		if err := checkArgs(1); err != nil {
+			return nil, err
+		}
+		out, err := compute.CallFunction(e.ctx, "xor", nil, args[0], compute.NewDatum(true))
+		return out, errToAdbcErr(adbc.StatusInvalidArgument, err)
+	case "is_null", "is_not_null":
+		if err := checkArgs(1); err != nil {
+			return nil, err
+		}
+		return e.isNull(args[0], ex.fn == "is_null"), nil
+	case "and", "or":
+		// these are variadic in Substrait, but binary in Arrow
+		if len(args) == 0 {
+			return nil, errInvalidPlan("function %s takes at least one argument", ex.fn)
+		}
+		acc := compute.NewDatum(args[0])
+		for _, a := range args[1:] {
+			next, err := compute.CallFunction(e.ctx, scalarFunctions[ex.fn], nil, acc, a)
+			acc.Release()
+			if err != nil {
+				return nil, errToAdbcErr(adbc.StatusInvalidArgument, err)
+			}
+			acc = next
+		}
+		return acc, nil
+	}
+
+	name, ok := scalarFunctions[ex.fn]
+	if !ok {
+		return nil, errUnsupportedPlan("function %s is not supported", ex.fn)
+	}
+	out, err := compute.CallFunction(e.ctx, name, nil, args...)
+	return out, errToAdbcErr(adbc.StatusInvalidArgument, err)
+}
+
+// isNull computes is_null, or is_not_null if wantNull is false, from the
+// validity of a value.
+func (e *executor) isNull(d compute.Datum, wantNull bool) compute.Datum {
+	if sc, ok := d.(*compute.ScalarDatum); ok {
+		return compute.NewDatum(sc.Value.IsValid() != wantNull)
+	}
+
+	arr := d.(*compute.ArrayDatum).MakeArray()
+	defer arr.Release()
+
+	bldr := array.NewBooleanBuilder(e.alloc)
+	defer bldr.Release()
+	bldr.Resize(arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		bldr.UnsafeAppend(arr.IsNull(i) == wantNull)
+	}
+	out := bldr.NewArray()
+	defer out.Release()
+	return compute.NewDatum(out)
+}
+
+// mapReader transforms each record of a source reader, which it takes
+// ownership of. The transformation returns nil to skip a record, and
+// done once no more records are needed.
+type mapReader struct {
+	refCount int64
+	src      array.RecordReader
+	schema   *arrow.Schema
+	fn       func(arrow.Record) (arrow.Record, bool, error)
+
+	done bool
+	rec  arrow.Record
+	err  error
+}
+
+func newMapReader(src array.RecordReader, schema *arrow.Schema, fn func(arrow.Record) (arrow.Record, bool, error)) *mapReader {
+	return &mapReader{refCount: 1, src: src, schema: schema, fn: fn}
+}
+
+func (r *mapReader) Schema() *arrow.Schema { return r.schema }
+func (r *mapReader) Record() arrow.Record  { return r.rec }
+func (r *mapReader) Err() error            { return r.err }
+
+func (r *mapReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+
+	for !r.done && r.err == nil {
+		if !r.src.Next() {
+			r.err = r.src.Err()
+			return false
+		}
+
+		out, done, err := r.fn(r.src.Record())
+		r.done = done
+		if err != nil {
+			r.err = errToAdbcErr(adbc.StatusInternal, err)
+			return false
+		}
+		if out == nil {
+			continue
+		}
+		if out.NumRows() == 0 {
+			out.Release()
+			continue
+		}
+		r.rec = out
+		return true
+	}
+	return false
+}
+
+func (r *mapReader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *mapReader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.src.Release()
+	}
+}
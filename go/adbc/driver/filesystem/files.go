@@ -0,0 +1,492 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	arrowcsv "github.com/apache/arrow/go/v12/arrow/csv"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/file"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+// fieldIDKey is the field metadata key pqarrow stores field ids under
+const fieldIDKey = "PARQUET:field_id"
+
+type fileFormat int
+
+const (
+	formatParquet fileFormat = iota
+	formatIPC
+	formatCSV
+)
+
+// formatExtensions lists the recognized file extensions. When several
+// files in a directory have the same name apart from the extension, the
+// one listed first is the table.
+var formatExtensions = []struct {
+	ext    string
+	format fileFormat
+}{
+	{".parquet", formatParquet},
+	{".arrow", formatIPC},
+	{".feather", formatIPC},
+	{".csv", formatCSV},
+}
+
+// tableFile is a data file exposed as a table
+type tableFile struct {
+	dbSchema string
+	name     string
+	path     string
+	format   fileFormat
+}
+
+// checkName rejects db schema and table names which are not a single
+// visible path element, so that no name refers outside the root.
+func checkName(kind, name string) error {
+	if name == "" || name == "." || name == ".." || strings.HasPrefix(name, ".") ||
+		strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, os.PathSeparator) {
+		return adbc.Error{
+			Msg:  "[filesystem] invalid " + kind + " name: '" + name + "'",
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return nil
+}
+
+// dbSchemaDir returns the directory of a db schema, which must exist.
+func (d *database) dbSchemaDir(dbSchema string) (string, error) {
+	if dbSchema == "" {
+		return d.root, nil
+	}
+	if err := checkName("db schema", dbSchema); err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(d.root, dbSchema)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", adbc.Error{
+			Msg:  "[filesystem] db schema not found: " + dbSchema,
+			Code: adbc.StatusNotFound,
+		}
+	}
+	return dir, nil
+}
+
+// dbSchemas lists the db schemas: the root itself and its visible
+// subdirectories.
+func (d *database) dbSchemas() ([]string, error) {
+	entries, err := os.ReadDir(d.root)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	result := []string{""}
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			result = append(result, e.Name())
+		}
+	}
+	return result, nil
+}
+
+// tables lists the tables of a db schema, sorted by name.
+func (d *database) tables(dbSchema string) ([]tableFile, error) {
+	dir, err := d.dbSchemaDir(dbSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	found := make(map[string]tableFile)
+	for _, fe := range formatExtensions {
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), fe.ext) {
+				continue
+			}
+
+			tbl := name[:len(name)-len(fe.ext)]
+			if _, ok := found[tbl]; !ok {
+				found[tbl] = tableFile{dbSchema: dbSchema, name: tbl, path: filepath.Join(dir, name), format: fe.format}
+			}
+		}
+	}
+
+	result := make([]tableFile, 0, len(found))
+	for _, t := range found {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result, nil
+}
+
+// findTable looks a table up by name, in every db schema if dbSchema is
+// nil, in which case the name must be unambiguous.
+func (d *database) findTable(dbSchema *string, name string) (tableFile, error) {
+	var schemas []string
+	if dbSchema != nil {
+		schemas = []string{*dbSchema}
+	} else {
+		var err error
+		if schemas, err = d.dbSchemas(); err != nil {
+			return tableFile{}, err
+		}
+	}
+
+	var matches []tableFile
+	for _, s := range schemas {
+		tables, err := d.tables(s)
+		if err != nil {
+			return tableFile{}, err
+		}
+		for _, t := range tables {
+			if t.name == name {
+				matches = append(matches, t)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return tableFile{}, adbc.Error{
+			Msg:  "[filesystem] table not found: " + name,
+			Code: adbc.StatusNotFound,
+		}
+	case 1:
+		return matches[0], nil
+	}
+	return tableFile{}, adbc.Error{
+		Msg:  "[filesystem] table name " + name + " is ambiguous, found in " + strconv.Itoa(len(matches)) + " db schemas",
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+// tableSchema reads the schema of a table from the file's footer, or
+// infers it for CSV files.
+func (d *database) tableSchema(t tableFile) (*arrow.Schema, error) {
+	switch t.format {
+	case formatParquet:
+		rdr, err := file.OpenParquetFile(t.path, false)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+		defer rdr.Close()
+
+		md := rdr.MetaData()
+		sc, err := pqarrow.FromParquet(md.Schema, &pqarrow.ArrowReadProperties{}, md.KeyValueMetadata())
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		return stripFieldIDs(sc), nil
+	case formatIPC:
+		f, err := os.Open(t.path)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+		defer f.Close()
+
+		rdr, err := ipc.NewFileReader(f, ipc.WithAllocator(d.alloc))
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		defer rdr.Close()
+		return rdr.Schema(), nil
+	default:
+		return d.inferCSVSchema(t.path)
+	}
+}
+
+// inferCSVSchema picks, for each column of a CSV file, the narrowest
+// type that every non-empty value in the first rows can be parsed as.
+func (d *database) inferCSVSchema(path string) (*arrow.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, adbc.Error{
+			Msg:  "[filesystem] could not read CSV header of " + path + ": " + err.Error(),
+			Code: adbc.StatusInvalidData,
+		}
+	}
+	names := append([]string{}, header...)
+
+	const (
+		canInt = 1 << iota
+		canFloat
+		canBool
+		canDate
+		canAll = canInt | canFloat | canBool | canDate
+	)
+	candidates := make([]int, len(names))
+	seen := make([]bool, len(names))
+	for i := range candidates {
+		candidates[i] = canAll
+	}
+
+	for n := 0; n < d.csvInferRows; n++ {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  "[filesystem] could not read CSV file " + path + ": " + err.Error(),
+				Code: adbc.StatusInvalidData,
+			}
+		}
+
+		for i, v := range row {
+			if v == "" {
+				continue
+			}
+			seen[i] = true
+			if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+				candidates[i] &^= canInt
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				candidates[i] &^= canFloat
+			}
+			if _, err := strconv.ParseBool(v); err != nil {
+				candidates[i] &^= canBool
+			}
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				candidates[i] &^= canDate
+			}
+		}
+	}
+
+	fields := make([]arrow.Field, len(names))
+	for i, name := range names {
+		var dt arrow.DataType = arrow.BinaryTypes.String
+		switch c := candidates[i]; {
+		case !seen[i]:
+		case c&canInt != 0:
+			dt = arrow.PrimitiveTypes.Int64
+		case c&canFloat != 0:
+			dt = arrow.PrimitiveTypes.Float64
+		case c&canBool != 0:
+			dt = arrow.FixedWidthTypes.Boolean
+		case c&canDate != 0:
+			dt = arrow.FixedWidthTypes.Date32
+		}
+		fields[i] = arrow.Field{Name: name, Type: dt, Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// openTable returns a reader over the contents of a table.
+func (d *database) openTable(ctx context.Context, t tableFile, batchSize int) (array.RecordReader, error) {
+	switch t.format {
+	case formatParquet:
+		pf, err := file.OpenParquetFile(t.path, false, file.WithReadProps(parquet.NewReaderProperties(d.alloc)))
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: int64(batchSize)}, d.alloc)
+		if err != nil {
+			pf.Close()
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		rdr, err := fr.GetRecordReader(ctx, nil, nil)
+		if err != nil {
+			pf.Close()
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		sc := stripFieldIDs(rdr.Schema())
+		return newMapReader(&closingReader{RecordReader: rdr, refCount: 1, closer: pf}, sc,
+			func(rec arrow.Record) (arrow.Record, bool, error) {
+				return array.NewRecord(sc, rec.Columns(), rec.NumRows()), false, nil
+			}), nil
+	case formatIPC:
+		f, err := os.Open(t.path)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		fr, err := ipc.NewFileReader(f, ipc.WithAllocator(d.alloc))
+		if err != nil {
+			f.Close()
+			return nil, errToAdbcErr(adbc.StatusInvalidData, err)
+		}
+		return &ipcFileReader{refCount: 1, rdr: fr, f: f}, nil
+	default:
+		sc, err := d.inferCSVSchema(t.path)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := os.Open(t.path)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+		rdr := arrowcsv.NewReader(f, sc, arrowcsv.WithHeader(true), arrowcsv.WithChunk(batchSize),
+			arrowcsv.WithAllocator(d.alloc), arrowcsv.WithNullReader(true, ""))
+		return &closingReader{RecordReader: rdr, refCount: 1, closer: f}, nil
+	}
+}
+
+// stripFieldIDs removes the Parquet field ids of unnumbered columns,
+// which pqarrow reports as -1 in the metadata of every field.
+func stripFieldIDs(sc *arrow.Schema) *arrow.Schema {
+	fields := append([]arrow.Field{}, sc.Fields()...)
+	for i, f := range fields {
+		if idx := f.Metadata.FindKey(fieldIDKey); idx >= 0 && f.Metadata.Values()[idx] == "-1" {
+			keys, vals := []string{}, []string{}
+			for j, k := range f.Metadata.Keys() {
+				if j != idx {
+					keys, vals = append(keys, k), append(vals, f.Metadata.Values()[j])
+				}
+			}
+			fields[i].Metadata = arrow.NewMetadata(keys, vals)
+		}
+	}
+	md := sc.Metadata()
+	return arrow.NewSchema(fields, &md)
+}
+
+// closingReader closes the file being read once the reader is released
+type closingReader struct {
+	array.RecordReader
+	refCount int64
+	closer   io.Closer
+}
+
+func (r *closingReader) Err() error {
+	// the Parquet reader reports the end of the file as an error
+	if err := r.RecordReader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return errToAdbcErr(adbc.StatusInvalidData, err)
+	}
+	return nil
+}
+
+func (r *closingReader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *closingReader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		r.RecordReader.Release()
+		r.closer.Close()
+	}
+}
+
+// ipcFileReader reads the record batches of an Arrow IPC file in order
+type ipcFileReader struct {
+	refCount int64
+	rdr      *ipc.FileReader
+	f        *os.File
+	next     int
+	rec      arrow.Record
+	err      error
+}
+
+func (r *ipcFileReader) Schema() *arrow.Schema { return r.rdr.Schema() }
+func (r *ipcFileReader) Record() arrow.Record  { return r.rec }
+func (r *ipcFileReader) Err() error            { return r.err }
+
+func (r *ipcFileReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil || r.next >= r.rdr.NumRecords() {
+		return false
+	}
+
+	r.rec, r.err = r.rdr.RecordAt(r.next)
+	if r.err != nil {
+		r.err = errToAdbcErr(adbc.StatusInvalidData, r.err)
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *ipcFileReader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *ipcFileReader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		r.rdr.Close()
+		r.f.Close()
+	}
+}
+
+// writeParquet writes the records produced by write to a Parquet file
+// at path. The file is written under a temporary name and then renamed,
+// so readers never see a partially written table and a failure leaves
+// any existing file untouched.
+func (d *database) writeParquet(path string, sc *arrow.Schema, write func(*pqarrow.FileWriter) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".adbc-ingest-*")
+	if err != nil {
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	props := parquet.NewWriterProperties(parquet.WithAllocator(d.alloc))
+	arrProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema(), pqarrow.WithAllocator(d.alloc))
+	w, err := pqarrow.NewFileWriter(sc, tmp, props, arrProps)
+	if err != nil {
+		tmp.Close()
+		return errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+
+	if err := write(w); err != nil {
+		w.Close()
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+	// closing the writer also closes the file
+	if err := w.Close(); err != nil {
+		return errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	return errToAdbcErr(adbc.StatusIO, os.Rename(tmp.Name(), path))
+}
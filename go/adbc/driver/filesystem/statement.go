@@ -0,0 +1,421 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+type statement struct {
+	alloc     memory.Allocator
+	cnxn      *cnxn
+	batchSize int
+
+	plan []byte
+
+	targetTable   string
+	targetCatalog string
+	targetSchema  string
+	ingestMode    string
+
+	bound      arrow.Record
+	streamBind array.RecordReader
+}
+
+func (st *statement) clearBinds() {
+	if st.bound != nil {
+		st.bound.Release()
+		st.bound = nil
+	} else if st.streamBind != nil {
+		st.streamBind.Release()
+		st.streamBind = nil
+	}
+}
+
+// Close releases any relevant resources associated with this statement
+// and closes it.
+//
+// A statement instance should not be used after Close is called.
+func (st *statement) Close() error {
+	if st.cnxn == nil {
+		return adbc.Error{
+			Msg:  "statement already closed",
+			Code: adbc.StatusInvalidState}
+	}
+
+	st.clearBinds()
+	st.cnxn = nil
+	return nil
+}
+
+// SetOption sets a string option on this statement
+func (st *statement) SetOption(key string, val string) error {
+	switch key {
+	case adbc.OptionKeyIngestTargetTable:
+		st.plan = nil
+		st.targetTable = val
+	case adbc.OptionKeyIngestTargetCatalog:
+		st.targetCatalog = val
+	case adbc.OptionKeyIngestTargetDBSchema:
+		st.targetSchema = val
+	case adbc.OptionKeyIngestTemporary:
+		switch val {
+		case adbc.OptionValueEnabled:
+			return adbc.Error{
+				Msg:  "[filesystem] temporary tables are not supported",
+				Code: adbc.StatusNotImplemented,
+			}
+		case adbc.OptionValueDisabled:
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case adbc.OptionKeyIngestMode:
+		switch val {
+		case adbc.OptionValueIngestModeAppend,
+			adbc.OptionValueIngestModeCreate,
+			adbc.OptionValueIngestModeReplace,
+			adbc.OptionValueIngestModeCreateAppend:
+			st.ingestMode = val
+		case adbc.OptionValueIngestModeMerge:
+			return adbc.Error{
+				Msg:  "[filesystem] merge ingestion is not supported",
+				Code: adbc.StatusNotImplemented,
+			}
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case adbc.OptionKeyIngestKeyColumns:
+		return adbc.Error{
+			Msg:  "[filesystem] merge ingestion is not supported",
+			Code: adbc.StatusNotImplemented,
+		}
+	case OptionBatchSize:
+		size, err := parsePositiveInt(key, val)
+		if err != nil {
+			return err
+		}
+		st.batchSize = size
+	default:
+		return adbc.Error{
+			Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return nil
+}
+
+// SetSqlQuery is not supported, as there is no SQL engine to run
+// queries. Queries are given as Substrait plans instead.
+func (st *statement) SetSqlQuery(query string) error {
+	return adbc.Error{
+		Msg:  "[filesystem] SQL queries are not supported, use SetSubstraitPlan",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// Prepare is not supported, as there is no SQL engine to run queries.
+func (st *statement) Prepare(ctx context.Context) error {
+	return adbc.Error{
+		Msg:  "[filesystem] prepared statements are not supported, use SetSubstraitPlan",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// SetSubstraitPlan sets a serialized Substrait plan to execute,
+// discarding any ingestion target. The plan is only decoded once it is
+// executed.
+func (st *statement) SetSubstraitPlan(plan []byte) error {
+	st.plan = plan
+	st.targetTable = ""
+	return nil
+}
+
+// ExecuteQuery executes the Substrait plan and returns a reader of its
+// results, which are read from the files as the reader is consumed.
+// The number of rows is not known up front and is reported as -1.
+//
+// If an ingestion target is set, the bound data is ingested instead
+// and no reader is returned.
+func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	if st.targetTable != "" {
+		n, err := st.executeIngest(ctx)
+		return nil, n, err
+	}
+
+	if st.plan == nil {
+		return nil, -1, adbc.Error{
+			Msg:  "cannot execute without a Substrait plan",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if st.bound != nil || st.streamBind != nil {
+		return nil, -1, adbc.Error{
+			Msg:  "[filesystem] Substrait plans with parameters are not supported",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	p, err := decodePlan(st.plan)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	exec := &executor{
+		ctx:       compute.WithAllocator(ctx, st.alloc),
+		db:        st.cnxn.db,
+		catalog:   st.cnxn.catalog,
+		alloc:     st.alloc,
+		batchSize: st.batchSize,
+	}
+	rdr, err := exec.execute(p)
+	if err != nil {
+		return nil, -1, err
+	}
+	return rdr, -1, nil
+}
+
+// ExecuteUpdate ingests the bound data into the target table and
+// returns the number of rows written. Executing a Substrait plan this
+// way is not supported, as plans cannot modify tables.
+func (st *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	if st.targetTable != "" {
+		return st.executeIngest(ctx)
+	}
+
+	if st.plan == nil {
+		return -1, adbc.Error{
+			Msg:  "cannot execute without a Substrait plan or ingestion target",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	return -1, adbc.Error{
+		Msg:  "[filesystem] ExecuteUpdate is only supported for bulk ingestion",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// executeIngest writes the bound data to the target table as a Parquet
+// file. Appending rewrites the existing file with the new rows added,
+// which is only possible for tables stored as Parquet.
+func (st *statement) executeIngest(ctx context.Context) (int64, error) {
+	if st.bound == nil && st.streamBind == nil {
+		return -1, adbc.Error{
+			Msg:  "must call Bind before bulk ingestion",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	defer st.clearBinds()
+
+	if st.targetCatalog != "" && st.targetCatalog != st.cnxn.catalog {
+		return -1, adbc.Error{
+			Msg:  "[filesystem] catalog not found: " + st.targetCatalog,
+			Code: adbc.StatusNotFound,
+		}
+	}
+	if err := checkName("table", st.targetTable); err != nil {
+		return -1, err
+	}
+
+	db := st.cnxn.db
+	dir, err := db.dbSchemaDir(st.targetSchema)
+	if err != nil {
+		return -1, err
+	}
+	existing, err := db.findTable(&st.targetSchema, st.targetTable)
+	exists := err == nil
+	if err != nil && !isNotFound(err) {
+		return -1, err
+	}
+
+	var rdr array.RecordReader
+	if st.bound != nil {
+		rdr, err = array.NewRecordReader(st.bound.Schema(), []arrow.Record{st.bound})
+		if err != nil {
+			return -1, errToAdbcErr(adbc.StatusInternal, err)
+		}
+	} else {
+		rdr = st.streamBind
+		rdr.Retain()
+	}
+	defer rdr.Release()
+
+	var (
+		schema = rdr.Schema()
+		prior  array.RecordReader
+	)
+	switch st.ingestMode {
+	case adbc.OptionValueIngestModeCreate, "":
+		if exists {
+			return -1, adbc.Error{
+				Msg:  "[filesystem] table already exists: " + st.targetTable,
+				Code: adbc.StatusAlreadyExists,
+			}
+		}
+	case adbc.OptionValueIngestModeAppend, adbc.OptionValueIngestModeCreateAppend:
+		if !exists {
+			if st.ingestMode == adbc.OptionValueIngestModeCreateAppend {
+				break
+			}
+			return -1, adbc.Error{
+				Msg:  "[filesystem] table not found: " + st.targetTable,
+				Code: adbc.StatusNotFound,
+			}
+		}
+		if existing.format != formatParquet {
+			return -1, adbc.Error{
+				Msg:  "[filesystem] can only append to tables stored as Parquet: " + existing.path,
+				Code: adbc.StatusNotImplemented,
+			}
+		}
+
+		if schema, err = db.tableSchema(existing); err != nil {
+			return -1, err
+		}
+		if !compatibleSchemas(schema, rdr.Schema()) {
+			return -1, adbc.Error{
+				Msg:  fmt.Sprintf("[filesystem] cannot append data with schema %s to table %s with schema %s", rdr.Schema(), st.targetTable, schema),
+				Code: adbc.StatusAlreadyExists,
+			}
+		}
+		if prior, err = db.openTable(ctx, existing, st.batchSize); err != nil {
+			return -1, err
+		}
+		defer prior.Release()
+	}
+
+	var rows int64
+	path := filepath.Join(dir, st.targetTable+".parquet")
+	err = db.writeParquet(path, schema, func(w *pqarrow.FileWriter) error {
+		if prior != nil {
+			for prior.Next() {
+				if err := w.Write(prior.Record()); err != nil {
+					return err
+				}
+			}
+			if err := prior.Err(); err != nil {
+				return err
+			}
+		}
+
+		for rdr.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// the names and nullability of the table's columns are kept
+			// when appending
+			rec := array.NewRecord(schema, rdr.Record().Columns(), rdr.Record().NumRows())
+			err := w.Write(rec)
+			rec.Release()
+			if err != nil {
+				return err
+			}
+			rows += rec.NumRows()
+		}
+		return rdr.Err()
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	// a replaced table may have been stored in another format, which
+	// would otherwise shadow the new file
+	if exists && existing.path != path {
+		if err := os.Remove(existing.path); err != nil {
+			return -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+	}
+	return rows, nil
+}
+
+// compatibleSchemas reports whether data with the schema of src can be
+// appended to a table with the schema of dst.
+func compatibleSchemas(dst, src *arrow.Schema) bool {
+	if len(dst.Fields()) != len(src.Fields()) {
+		return false
+	}
+	for i, f := range dst.Fields() {
+		if f.Name != src.Field(i).Name || !arrow.TypeEqual(f.Type, src.Field(i).Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNotFound(err error) bool {
+	e, ok := err.(adbc.Error)
+	return ok && e.Code == adbc.StatusNotFound
+}
+
+// Bind uses an arrow record batch as the data to ingest into the
+// target table.
+func (st *statement) Bind(_ context.Context, values arrow.Record) error {
+	st.clearBinds()
+
+	st.bound = values
+	if st.bound != nil {
+		st.bound.Retain()
+	}
+	return nil
+}
+
+// BindStream uses a record batch stream as the data to ingest into the
+// target table. The stream is consumed by the next execution.
+func (st *statement) BindStream(_ context.Context, stream array.RecordReader) error {
+	st.clearBinds()
+
+	st.streamBind = stream
+	if st.streamBind != nil {
+		st.streamBind.Retain()
+	}
+	return nil
+}
+
+// GetParameterSchema is not supported as plans cannot have parameters.
+func (st *statement) GetParameterSchema() (*arrow.Schema, error) {
+	return nil, adbc.Error{
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// ExecutePartitions is not supported as results are never partitioned.
+func (st *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	return nil, adbc.Partitions{}, -1, adbc.Error{
+		Msg:  "ExecutePartitions not supported by the filesystem driver",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*statement)(nil)
+)
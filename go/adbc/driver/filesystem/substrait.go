@@ -0,0 +1,928 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filesystem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/arrow/scalar"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Substrait plans are protobuf messages. Only the subset of the
+// specification executed by this driver is decoded, directly from the
+// wire format using the field numbers of the Substrait protos.
+
+// message holds the fields of a decoded protobuf message by number,
+// with repeated fields in the order they were encoded.
+type message map[protowire.Number][]protoValue
+
+type protoValue struct {
+	typ protowire.Type
+	num uint64
+	buf []byte
+}
+
+func errInvalidPlan(format string, args ...interface{}) error {
+	return adbc.Error{
+		Msg:  "[filesystem] invalid Substrait plan: " + fmt.Sprintf(format, args...),
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+func errUnsupportedPlan(format string, args ...interface{}) error {
+	return adbc.Error{
+		Msg:  "[filesystem] unsupported Substrait plan: " + fmt.Sprintf(format, args...),
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+func parseMessage(b []byte) (message, error) {
+	m := make(message)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, errInvalidPlan("%s", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		v := protoValue{typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			v.num, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var x uint32
+			x, n = protowire.ConsumeFixed32(b)
+			v.num = uint64(x)
+		case protowire.Fixed64Type:
+			v.num, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v.buf, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, errInvalidPlan("%s", protowire.ParseError(n))
+		}
+		b = b[n:]
+		m[num] = append(m[num], v)
+	}
+	return m, nil
+}
+
+func (m message) has(n protowire.Number) bool { return len(m[n]) > 0 }
+
+// last returns the last value of a field, which is the one that counts
+// for non-repeated fields.
+func (m message) last(n protowire.Number) protoValue {
+	if vals := m[n]; len(vals) > 0 {
+		return vals[len(vals)-1]
+	}
+	return protoValue{}
+}
+
+func (m message) uint(n protowire.Number) uint64   { return m.last(n).num }
+func (m message) int32(n protowire.Number) int32   { return int32(m.last(n).num) }
+func (m message) int64(n protowire.Number) int64   { return int64(m.last(n).num) }
+func (m message) bytes(n protowire.Number) []byte  { return m.last(n).buf }
+func (m message) string(n protowire.Number) string { return string(m.last(n).buf) }
+
+func (m message) strings(n protowire.Number) []string {
+	out := make([]string, len(m[n]))
+	for i, v := range m[n] {
+		out[i] = string(v.buf)
+	}
+	return out
+}
+
+// varints returns a repeated integer field, which may be packed.
+func (m message) varints(n protowire.Number) ([]uint64, error) {
+	var out []uint64
+	for _, v := range m[n] {
+		if v.typ != protowire.BytesType {
+			out = append(out, v.num)
+			continue
+		}
+		for b := v.buf; len(b) > 0; {
+			x, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, errInvalidPlan("%s", protowire.ParseError(n))
+			}
+			out, b = append(out, x), b[n:]
+		}
+	}
+	return out, nil
+}
+
+func (m message) message(n protowire.Number) (message, error) {
+	return parseMessage(m.bytes(n))
+}
+
+func (m message) messages(n protowire.Number) ([]message, error) {
+	out := make([]message, len(m[n]))
+	for i, v := range m[n] {
+		var err error
+		if out[i], err = parseMessage(v.buf); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// plan is a decoded Substrait plan with a single root relation
+type plan struct {
+	root rel
+	// names are the output names given by the plan's root, depth first
+	// for nested types
+	names []string
+}
+
+type rel interface {
+	// emit is the output mapping of the relation, nil to output all
+	// of its columns
+	emit() []int
+}
+
+type relCommon struct{ outputMapping []int }
+
+func (r relCommon) emit() []int { return r.outputMapping }
+
+type readRel struct {
+	relCommon
+	// table is the name of the table, optionally qualified by db
+	// schema and catalog
+	table []string
+	// baseNames are the names of the columns read, which field
+	// references refer to by position
+	baseNames []string
+	filter    expr
+	// projection, if not nil, selects columns of the base schema
+	projection []int
+}
+
+type filterRel struct {
+	relCommon
+	input rel
+	cond  expr
+}
+
+type projectRel struct {
+	relCommon
+	input rel
+	exprs []expr
+}
+
+type fetchRel struct {
+	relCommon
+	input  rel
+	offset int64
+	// count is the maximum number of rows, or -1 for no limit
+	count int64
+}
+
+type aggregateRel struct {
+	relCommon
+	input     rel
+	groupings []expr
+	measures  []measure
+}
+
+type measure struct {
+	fn     *callExpr
+	filter expr
+}
+
+type expr interface {
+	// name returns a name for the expression over an input with the
+	// given schema, used as the name of a column computed from it
+	name(input *arrow.Schema) string
+}
+
+type literalExpr struct{ val scalar.Scalar }
+
+func (e *literalExpr) name(*arrow.Schema) string { return e.val.String() }
+
+// fieldRefExpr references a column of the input, and for struct columns
+// possibly a nested field of it
+type fieldRefExpr struct{ path []int }
+
+func (e *fieldRefExpr) name(input *arrow.Schema) string {
+	dt := arrow.DataType(arrow.StructOf(input.Fields()...))
+	names := make([]string, 0, len(e.path))
+	for _, idx := range e.path {
+		st, ok := dt.(*arrow.StructType)
+		if !ok || idx < 0 || idx >= len(st.Fields()) {
+			return fmt.Sprintf("$%d", e.path[0])
+		}
+		names = append(names, st.Field(idx).Name)
+		dt = st.Field(idx).Type
+	}
+	return strings.Join(names, ".")
+}
+
+type callExpr struct {
+	fn   string
+	args []expr
+}
+
+func (e *callExpr) name(input *arrow.Schema) string {
+	args := make([]string, len(e.args))
+	for i, a := range e.args {
+		args[i] = a.name(input)
+	}
+	return e.fn + "(" + strings.Join(args, ", ") + ")"
+}
+
+type castExpr struct {
+	to    arrow.DataType
+	input expr
+}
+
+func (e *castExpr) name(input *arrow.Schema) string {
+	return "cast(" + e.input.name(input) + " AS " + e.to.String() + ")"
+}
+
+// planDecoder decodes the relations of a plan, resolving the function
+// references declared by its extensions.
+type planDecoder struct {
+	functions map[uint32]string
+}
+
+func decodePlan(b []byte) (*plan, error) {
+	m, err := parseMessage(b)
+	if err != nil {
+		return nil, err
+	}
+
+	d := planDecoder{functions: make(map[uint32]string)}
+	decls, err := m.messages(2)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range decls {
+		if !decl.has(3) {
+			continue
+		}
+		fn, err := decl.message(3)
+		if err != nil {
+			return nil, err
+		}
+		d.functions[uint32(fn.uint(2))] = fn.string(3)
+	}
+
+	relations, err := m.messages(3)
+	if err != nil {
+		return nil, err
+	}
+	if len(relations) != 1 {
+		return nil, errUnsupportedPlan("expected exactly one relation, got %d", len(relations))
+	}
+
+	p := &plan{}
+	planRel := relations[0]
+	if planRel.has(2) {
+		root, err := planRel.message(2)
+		if err != nil {
+			return nil, err
+		}
+		p.names = root.strings(2)
+		input, err := root.message(1)
+		if err != nil {
+			return nil, err
+		}
+		p.root, err = d.rel(input)
+		return p, err
+	}
+
+	input, err := planRel.message(1)
+	if err != nil {
+		return nil, err
+	}
+	p.root, err = d.rel(input)
+	return p, err
+}
+
+var relKinds = map[protowire.Number]string{
+	5: "sort", 6: "join", 8: "set", 9: "extension_single", 10: "extension_multi",
+	11: "extension_leaf", 12: "cross", 13: "hash_join", 14: "merge_join",
+}
+
+func (d *planDecoder) rel(m message) (rel, error) {
+	kinds := []struct {
+		num    protowire.Number
+		decode func(message) (rel, error)
+	}{
+		{1, d.readRel}, {2, d.filterRel}, {3, d.fetchRel}, {4, d.aggregateRel}, {7, d.projectRel},
+	}
+	for _, k := range kinds {
+		if m.has(k.num) {
+			r, err := m.message(k.num)
+			if err != nil {
+				return nil, err
+			}
+			return k.decode(r)
+		}
+	}
+
+	for num, name := range relKinds {
+		if m.has(num) {
+			return nil, errUnsupportedPlan("%s relations are not supported", name)
+		}
+	}
+	return nil, errInvalidPlan("empty relation")
+}
+
+func (d *planDecoder) common(m message) (relCommon, error) {
+	common, err := m.message(1)
+	if err != nil || !common.has(2) {
+		return relCommon{}, err
+	}
+
+	emit, err := common.message(2)
+	if err != nil {
+		return relCommon{}, err
+	}
+	mapping, err := emit.varints(1)
+	if err != nil {
+		return relCommon{}, err
+	}
+
+	out := relCommon{outputMapping: make([]int, len(mapping))}
+	for i, v := range mapping {
+		out.outputMapping[i] = int(int32(v))
+	}
+	return out, nil
+}
+
+func (d *planDecoder) input(m message) (rel, error) {
+	if !m.has(2) {
+		return nil, errInvalidPlan("relation has no input")
+	}
+	input, err := m.message(2)
+	if err != nil {
+		return nil, err
+	}
+	return d.rel(input)
+}
+
+func (d *planDecoder) readRel(m message) (rel, error) {
+	common, err := d.common(m)
+	if err != nil {
+		return nil, err
+	}
+	if !m.has(7) {
+		return nil, errUnsupportedPlan("only named tables can be read")
+	}
+
+	r := &readRel{relCommon: common}
+	named, err := m.message(7)
+	if err != nil {
+		return nil, err
+	}
+	if r.table = named.strings(1); len(r.table) == 0 || len(r.table) > 3 {
+		return nil, errInvalidPlan("invalid table name %q", r.table)
+	}
+
+	if m.has(2) {
+		if r.baseNames, err = d.topLevelNames(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.has(3) {
+		if r.filter, err = d.exprField(m, 3); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.has(4) {
+		mask, err := m.message(4)
+		if err != nil {
+			return nil, err
+		}
+		sel, err := mask.message(1)
+		if err != nil {
+			return nil, err
+		}
+		items, err := sel.messages(1)
+		if err != nil {
+			return nil, err
+		}
+		r.projection = make([]int, len(items))
+		for i, item := range items {
+			r.projection[i] = int(item.int32(1))
+		}
+	}
+	return r, nil
+}
+
+// topLevelNames returns the names of the top-level columns of a read
+// relation's base schema, skipping the names of nested fields.
+func (d *planDecoder) topLevelNames(m message) ([]string, error) {
+	base, err := m.message(2)
+	if err != nil {
+		return nil, err
+	}
+	names := base.strings(1)
+	if !base.has(2) {
+		return names, nil
+	}
+
+	st, err := base.message(2)
+	if err != nil {
+		return nil, err
+	}
+	types, err := st.messages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for i, typ := range types {
+		n, err := nestedNames(typ)
+		if err != nil {
+			return nil, err
+		}
+		if i >= len(names) {
+			return nil, errInvalidPlan("base schema has fewer names than types")
+		}
+		out = append(out, names[i])
+		names = names[n:]
+	}
+	return out, nil
+}
+
+// nestedNames counts the names used by the nested fields of a type
+func nestedNames(typ message) (int, error) {
+	switch {
+	case typ.has(25):
+		st, err := typ.message(25)
+		if err != nil {
+			return 0, err
+		}
+		children, err := st.messages(1)
+		if err != nil {
+			return 0, err
+		}
+		total := 0
+		for _, c := range children {
+			n, err := nestedNames(c)
+			if err != nil {
+				return 0, err
+			}
+			total += 1 + n
+		}
+		return total, nil
+	case typ.has(27):
+		list, err := typ.message(27)
+		if err != nil {
+			return 0, err
+		}
+		elem, err := list.message(1)
+		if err != nil {
+			return 0, err
+		}
+		return nestedNames(elem)
+	case typ.has(28):
+		mp, err := typ.message(28)
+		if err != nil {
+			return 0, err
+		}
+		key, err := mp.message(1)
+		if err != nil {
+			return 0, err
+		}
+		value, err := mp.message(2)
+		if err != nil {
+			return 0, err
+		}
+		nk, err := nestedNames(key)
+		if err != nil {
+			return 0, err
+		}
+		nv, err := nestedNames(value)
+		return nk + nv, err
+	}
+	return 0, nil
+}
+
+func (d *planDecoder) filterRel(m message) (rel, error) {
+	common, err := d.common(m)
+	if err != nil {
+		return nil, err
+	}
+	input, err := d.input(m)
+	if err != nil {
+		return nil, err
+	}
+	if !m.has(3) {
+		return nil, errInvalidPlan("filter has no condition")
+	}
+	cond, err := d.exprField(m, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &filterRel{relCommon: common, input: input, cond: cond}, nil
+}
+
+func (d *planDecoder) fetchRel(m message) (rel, error) {
+	common, err := d.common(m)
+	if err != nil {
+		return nil, err
+	}
+	input, err := d.input(m)
+	if err != nil {
+		return nil, err
+	}
+	if m.has(5) || m.has(6) {
+		return nil, errUnsupportedPlan("fetch offset and count must be constants")
+	}
+
+	r := &fetchRel{relCommon: common, input: input, offset: m.int64(3), count: -1}
+	if m.has(4) {
+		r.count = m.int64(4)
+	}
+	if r.offset < 0 {
+		return nil, errInvalidPlan("negative fetch offset %d", r.offset)
+	}
+	return r, nil
+}
+
+func (d *planDecoder) projectRel(m message) (rel, error) {
+	common, err := d.common(m)
+	if err != nil {
+		return nil, err
+	}
+	input, err := d.input(m)
+	if err != nil {
+		return nil, err
+	}
+	exprs, err := d.exprs(m, 3)
+	if err != nil {
+		return nil, err
+	}
+	return &projectRel{relCommon: common, input: input, exprs: exprs}, nil
+}
+
+func (d *planDecoder) aggregateRel(m message) (rel, error) {
+	common, err := d.common(m)
+	if err != nil {
+		return nil, err
+	}
+	input, err := d.input(m)
+	if err != nil {
+		return nil, err
+	}
+	r := &aggregateRel{relCommon: common, input: input}
+
+	groupings, err := m.messages(3)
+	if err != nil {
+		return nil, err
+	}
+	switch len(groupings) {
+	case 0:
+	case 1:
+		if r.groupings, err = d.exprs(groupings[0], 1); err != nil {
+			return nil, err
+		}
+		// newer plans refer to the grouping expressions of the relation
+		refs, err := groupings[0].varints(2)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			shared, err := d.exprs(m, 5)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range refs {
+				if ref >= uint64(len(shared)) {
+					return nil, errInvalidPlan("grouping expression reference %d out of range", ref)
+				}
+				r.groupings = append(r.groupings, shared[ref])
+			}
+		}
+	default:
+		return nil, errUnsupportedPlan("grouping sets are not supported")
+	}
+
+	measures, err := m.messages(4)
+	if err != nil {
+		return nil, err
+	}
+	for _, ms := range measures {
+		fn, err := ms.message(1)
+		if err != nil {
+			return nil, err
+		}
+		// AGGREGATION_INVOCATION_DISTINCT
+		if fn.uint(6) == 2 {
+			return nil, errUnsupportedPlan("distinct aggregates are not supported")
+		}
+
+		call, err := d.call(fn, 7, 2)
+		if err != nil {
+			return nil, err
+		}
+		out := measure{fn: call}
+		if ms.has(2) {
+			if out.filter, err = d.exprField(ms, 2); err != nil {
+				return nil, err
+			}
+		}
+		r.measures = append(r.measures, out)
+	}
+	return r, nil
+}
+
+func (d *planDecoder) exprs(m message, n protowire.Number) ([]expr, error) {
+	msgs, err := m.messages(n)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]expr, len(msgs))
+	for i, e := range msgs {
+		if out[i], err = d.expr(e); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (d *planDecoder) exprField(m message, n protowire.Number) (expr, error) {
+	e, err := m.message(n)
+	if err != nil {
+		return nil, err
+	}
+	return d.expr(e)
+}
+
+func (d *planDecoder) expr(m message) (expr, error) {
+	switch {
+	case m.has(1):
+		lit, err := m.message(1)
+		if err != nil {
+			return nil, err
+		}
+		val, err := decodeLiteral(lit)
+		if err != nil {
+			return nil, err
+		}
+		return &literalExpr{val: val}, nil
+	case m.has(2):
+		ref, err := m.message(2)
+		if err != nil {
+			return nil, err
+		}
+		return d.fieldRef(ref)
+	case m.has(3):
+		fn, err := m.message(3)
+		if err != nil {
+			return nil, err
+		}
+		return d.call(fn, 4, 2)
+	case m.has(8):
+		// value IN (options...) is evaluated as a disjunction
+		in, err := m.message(8)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.exprField(in, 1)
+		if err != nil {
+			return nil, err
+		}
+		options, err := d.exprs(in, 2)
+		if err != nil {
+			return nil, err
+		}
+		or := &callExpr{fn: "or"}
+		for _, opt := range options {
+			or.args = append(or.args, &callExpr{fn: "equal", args: []expr{value, opt}})
+		}
+		if len(or.args) == 0 {
+			return &literalExpr{val: scalar.NewBooleanScalar(false)}, nil
+		}
+		return or, nil
+	case m.has(11):
+		cast, err := m.message(11)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := cast.message(1)
+		if err != nil {
+			return nil, err
+		}
+		to, err := decodeType(typ)
+		if err != nil {
+			return nil, err
+		}
+		input, err := d.exprField(cast, 2)
+		if err != nil {
+			return nil, err
+		}
+		return &castExpr{to: to, input: input}, nil
+	}
+	return nil, errUnsupportedPlan("only literals, field references, scalar functions, IN lists and casts are supported in expressions")
+}
+
+func (d *planDecoder) fieldRef(m message) (expr, error) {
+	if !m.has(1) {
+		return nil, errUnsupportedPlan("only direct field references are supported")
+	}
+	if m.has(4) || m.has(5) {
+		return nil, errUnsupportedPlan("only field references to the input are supported")
+	}
+
+	seg, err := m.message(1)
+	if err != nil {
+		return nil, err
+	}
+	ref := &fieldRefExpr{}
+	for {
+		if !seg.has(2) {
+			return nil, errUnsupportedPlan("only struct field references are supported")
+		}
+		field, err := seg.message(2)
+		if err != nil {
+			return nil, err
+		}
+		ref.path = append(ref.path, int(field.int32(1)))
+		if !field.has(2) {
+			break
+		}
+		if seg, err = field.message(2); err != nil {
+			return nil, err
+		}
+	}
+	return ref, nil
+}
+
+// call decodes a scalar or aggregate function invocation, whose
+// arguments are in field argsField, or deprecatedArgsField in older
+// plans.
+func (d *planDecoder) call(m message, argsField, deprecatedArgsField protowire.Number) (*callExpr, error) {
+	ref := uint32(m.uint(1))
+	name, ok := d.functions[ref]
+	if !ok {
+		return nil, errInvalidPlan("undeclared function reference %d", ref)
+	}
+	// compound names carry the argument types, as in add:i64_i64
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+
+	call := &callExpr{fn: name}
+	args, err := m.messages(argsField)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		// enum and type arguments only select a variant of the
+		// function, so only value arguments are kept
+		if !arg.has(3) {
+			continue
+		}
+		e, err := d.exprField(arg, 3)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, e)
+	}
+
+	if len(args) == 0 {
+		if call.args, err = d.exprs(m, deprecatedArgsField); err != nil {
+			return nil, err
+		}
+	}
+	return call, nil
+}
+
+func decodeLiteral(m message) (scalar.Scalar, error) {
+	switch {
+	case m.has(1):
+		return scalar.NewBooleanScalar(m.uint(1) != 0), nil
+	case m.has(2):
+		return scalar.NewInt8Scalar(int8(m.int32(2))), nil
+	case m.has(3):
+		return scalar.NewInt16Scalar(int16(m.int32(3))), nil
+	case m.has(5):
+		return scalar.NewInt32Scalar(m.int32(5)), nil
+	case m.has(7):
+		return scalar.NewInt64Scalar(m.int64(7)), nil
+	case m.has(10):
+		return scalar.NewFloat32Scalar(math.Float32frombits(uint32(m.uint(10)))), nil
+	case m.has(11):
+		return scalar.NewFloat64Scalar(math.Float64frombits(m.uint(11))), nil
+	case m.has(12):
+		return scalar.NewStringScalar(m.string(12)), nil
+	case m.has(13):
+		return scalar.NewBinaryScalar(memory.NewBufferBytes(m.bytes(13)), arrow.BinaryTypes.Binary), nil
+	case m.has(14):
+		return scalar.NewTimestampScalar(arrow.Timestamp(m.int64(14)), arrow.FixedWidthTypes.Timestamp_us), nil
+	case m.has(16):
+		return scalar.NewDate32Scalar(arrow.Date32(m.int32(16))), nil
+	case m.has(17):
+		return scalar.NewTime64Scalar(arrow.Time64(m.int64(17)), arrow.FixedWidthTypes.Time64us), nil
+	case m.has(21):
+		return scalar.NewStringScalar(m.string(21)), nil
+	case m.has(22):
+		vc, err := m.message(22)
+		if err != nil {
+			return nil, err
+		}
+		return scalar.NewStringScalar(vc.string(1)), nil
+	case m.has(23):
+		v := m.bytes(23)
+		return scalar.NewFixedSizeBinaryScalar(memory.NewBufferBytes(v), &arrow.FixedSizeBinaryType{ByteWidth: len(v)}), nil
+	case m.has(24):
+		dec, err := m.message(24)
+		if err != nil {
+			return nil, err
+		}
+		v := dec.bytes(1)
+		if len(v) != 16 {
+			return nil, errInvalidPlan("decimal literal must have 16 bytes, got %d", len(v))
+		}
+		num := decimal128.New(int64(binary.LittleEndian.Uint64(v[8:])), binary.LittleEndian.Uint64(v[:8]))
+		return scalar.NewDecimal128Scalar(num, &arrow.Decimal128Type{Precision: dec.int32(2), Scale: dec.int32(3)}), nil
+	case m.has(27):
+		return scalar.NewTimestampScalar(arrow.Timestamp(m.int64(27)), &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}), nil
+	case m.has(29):
+		typ, err := m.message(29)
+		if err != nil {
+			return nil, err
+		}
+		dt, err := decodeType(typ)
+		if err != nil {
+			return nil, err
+		}
+		return scalar.MakeNullScalar(dt), nil
+	}
+	return nil, errUnsupportedPlan("unsupported literal type")
+}
+
+func decodeType(m message) (arrow.DataType, error) {
+	simple := []struct {
+		num protowire.Number
+		dt  arrow.DataType
+	}{
+		{1, arrow.FixedWidthTypes.Boolean},
+		{2, arrow.PrimitiveTypes.Int8},
+		{3, arrow.PrimitiveTypes.Int16},
+		{5, arrow.PrimitiveTypes.Int32},
+		{7, arrow.PrimitiveTypes.Int64},
+		{10, arrow.PrimitiveTypes.Float32},
+		{11, arrow.PrimitiveTypes.Float64},
+		{12, arrow.BinaryTypes.String},
+		{13, arrow.BinaryTypes.Binary},
+		{14, arrow.FixedWidthTypes.Timestamp_us},
+		{16, arrow.FixedWidthTypes.Date32},
+		{17, arrow.FixedWidthTypes.Time64us},
+		{21, arrow.BinaryTypes.String},
+		{22, arrow.BinaryTypes.String},
+		{29, &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}},
+	}
+	for _, s := range simple {
+		if m.has(s.num) {
+			return s.dt, nil
+		}
+	}
+
+	switch {
+	case m.has(23):
+		fb, err := m.message(23)
+		if err != nil {
+			return nil, err
+		}
+		return &arrow.FixedSizeBinaryType{ByteWidth: int(fb.int32(1))}, nil
+	case m.has(24):
+		dec, err := m.message(24)
+		if err != nil {
+			return nil, err
+		}
+		return &arrow.Decimal128Type{Precision: dec.int32(2), Scale: dec.int32(1)}, nil
+	}
+	return nil, errUnsupportedPlan("unsupported type")
+}
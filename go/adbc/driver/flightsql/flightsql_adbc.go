@@ -29,6 +29,10 @@
 //	db, err := sql.Open("flightsql", "uri=<flight sql db url>")
 //
 // The URI passed *must* contain a scheme, most likely "grpc+tcp://"
+//
+// The driver registers itself with adbc.RegisterDriver as "flightsql"
+// for the grpc, grpc+tcp, grpc+tls and grpc+unix schemes, so that
+// adbc.Open can open such URIs once this package is imported.
 package flightsql
 
 import (
@@ -83,6 +87,16 @@ const (
 	infoDriverName            = "ADBC Flight SQL Driver - Go"
 )
 
+// The SqlInfo values of the server, such as its supported grammar,
+// keywords or Substrait versions, are reported by GetInfo under the
+// vendor-specific info codes [InfoFlightSqlBase, InfoFlightSqlEnd), as
+// InfoFlightSqlBase plus the SqlInfo, for example
+// InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoKeywords).
+const (
+	InfoFlightSqlBase adbc.InfoCode = 10_000
+	InfoFlightSqlEnd  adbc.InfoCode = 20_000
+)
+
 var (
 	infoDriverVersion      string
 	infoDriverArrowVersion string
@@ -121,7 +135,19 @@ func init() {
 		adbc.InfoVendorName,
 		adbc.InfoVendorVersion,
 		adbc.InfoVendorArrowVersion,
-	}
+		adbc.InfoVendorSql,
+		adbc.InfoVendorSubstrait,
+		adbc.InfoVendorSubstraitMinVersion,
+		adbc.InfoVendorSubstraitMaxVersion,
+		adbc.InfoFeatureBulkIngestModes,
+		adbc.InfoFeaturePartitions,
+		adbc.InfoFeatureTransactions,
+		adbc.InfoFeatureSavepoints,
+		adbc.InfoFeatureCancel,
+		adbc.InfoFeatureIsolationLevels,
+	}
+
+	adbc.RegisterDriver("flightsql", Driver{}, "grpc", "grpc+tcp", "grpc+tls", "grpc+unix")
 }
 
 func getTimeoutOptionValue(v string) (time.Duration, error) {
@@ -192,6 +218,11 @@ type database struct {
 	dialOpts   dbDialOpts
 
 	alloc memory.Allocator
+
+	actionsMu sync.Mutex
+	// action types listed by the server, fetched on first use and
+	// shared by the connections
+	actions map[string]struct{}
 }
 
 func (d *database) SetOptions(cnOptions map[string]string) error {
@@ -542,6 +573,10 @@ func getFlightClient(ctx context.Context, loc string, d *database) (*flightsql.C
 			Unary:  unaryTimeoutInterceptor,
 			Stream: streamTimeoutInterceptor,
 		},
+		{
+			Unary:  unaryTrailerInterceptor,
+			Stream: streamTrailerInterceptor,
+		},
 	}
 
 	uri, err := url.Parse(loc)
@@ -588,6 +623,7 @@ func getFlightClient(ctx context.Context, loc string, d *database) (*flightsql.C
 
 type support struct {
 	transactions bool
+	savepoints   bool
 }
 
 func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
@@ -653,6 +689,7 @@ func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
 						cnxnSupport.transactions =
 							value == int32(flightsql.SqlTransactionTransaction) ||
 								value == int32(flightsql.SqlTransactionSavepoint)
+						cnxnSupport.savepoints = value == int32(flightsql.SqlTransactionSavepoint)
 					}
 				}
 			}
@@ -676,9 +713,109 @@ type cnxn struct {
 }
 
 var adbcToFlightSQLInfo = map[adbc.InfoCode]flightsql.SqlInfo{
-	adbc.InfoVendorName:         flightsql.SqlInfoFlightSqlServerName,
-	adbc.InfoVendorVersion:      flightsql.SqlInfoFlightSqlServerVersion,
-	adbc.InfoVendorArrowVersion: flightsql.SqlInfoFlightSqlServerArrowVersion,
+	adbc.InfoVendorName:                flightsql.SqlInfoFlightSqlServerName,
+	adbc.InfoVendorVersion:             flightsql.SqlInfoFlightSqlServerVersion,
+	adbc.InfoVendorArrowVersion:        flightsql.SqlInfoFlightSqlServerArrowVersion,
+	adbc.InfoVendorSql:                 flightsql.SqlInfoFlightSqlServerSql,
+	adbc.InfoVendorSubstrait:           flightsql.SqlInfoFlightSqlServerSubstrait,
+	adbc.InfoVendorSubstraitMinVersion: flightsql.SqlInfoFlightSqlServerSubstraitMinVersion,
+	adbc.InfoVendorSubstraitMaxVersion: flightsql.SqlInfoFlightSqlServerSubstraitMaxVersion,
+}
+
+// appendInfoValue appends the i-th value of the union of SqlInfo
+// values, whose members are the same as those of adbc.GetInfoSchema.
+// A value of an unexpected type is appended as null.
+func appendInfoValue(bldr *array.DenseUnionBuilder, values *array.DenseUnion, i int) {
+	typeCode := values.TypeCode(i)
+	if typeCode < 0 || int(typeCode) >= bldr.NumChildren() {
+		bldr.AppendNull()
+		return
+	}
+
+	src := values.Field(values.ChildID(i))
+	idx := int(values.ValueOffset(i))
+	dst := bldr.Child(int(typeCode))
+	if src.IsNull(idx) {
+		bldr.Append(typeCode)
+		dst.AppendNull()
+		return
+	}
+
+	switch dst := dst.(type) {
+	case *array.StringBuilder:
+		if src, ok := src.(*array.String); ok {
+			bldr.Append(typeCode)
+			dst.Append(src.Value(idx))
+			return
+		}
+	case *array.BooleanBuilder:
+		if src, ok := src.(*array.Boolean); ok {
+			bldr.Append(typeCode)
+			dst.Append(src.Value(idx))
+			return
+		}
+	case *array.Int64Builder:
+		if src, ok := src.(*array.Int64); ok {
+			bldr.Append(typeCode)
+			dst.Append(src.Value(idx))
+			return
+		}
+	case *array.Int32Builder:
+		if src, ok := src.(*array.Int32); ok {
+			bldr.Append(typeCode)
+			dst.Append(src.Value(idx))
+			return
+		}
+	case *array.ListBuilder:
+		// string_list
+		if src, ok := src.(*array.List); ok {
+			if elems, ok := src.ListValues().(*array.String); ok {
+				bldr.Append(typeCode)
+				dst.Append(true)
+				elemBldr := dst.ValueBuilder().(*array.StringBuilder)
+				start, end := src.ValueOffsets(idx)
+				for j := int(start); j < int(end); j++ {
+					if elems.IsNull(j) {
+						elemBldr.AppendNull()
+					} else {
+						elemBldr.Append(elems.Value(j))
+					}
+				}
+				return
+			}
+		}
+	case *array.MapBuilder:
+		// int32_to_int32_list_map
+		if src, ok := src.(*array.Map); ok {
+			keys, keysOk := src.Keys().(*array.Int32)
+			items, itemsOk := src.Items().(*array.List)
+			if keysOk && itemsOk {
+				if elems, ok := items.ListValues().(*array.Int32); ok {
+					bldr.Append(typeCode)
+					dst.Append(true)
+					keyBldr := dst.KeyBuilder().(*array.Int32Builder)
+					itemBldr := dst.ItemBuilder().(*array.ListBuilder)
+					elemBldr := itemBldr.ValueBuilder().(*array.Int32Builder)
+					start, end := src.ValueOffsets(idx)
+					for j := int(start); j < int(end); j++ {
+						keyBldr.Append(keys.Value(j))
+						if items.IsNull(j) {
+							itemBldr.AppendNull()
+							continue
+						}
+						itemBldr.Append(true)
+						itemStart, itemEnd := items.ValueOffsets(j)
+						for k := int(itemStart); k < int(itemEnd); k++ {
+							elemBldr.Append(elems.Value(k))
+						}
+					}
+					return
+				}
+			}
+		}
+	}
+
+	bldr.AppendNull()
 }
 
 func doGet(ctx context.Context, cl *flightsql.Client, endpoint *flight.FlightEndpoint, clientCache gcache.Cache, opts ...grpc.CallOption) (rdr *flight.Reader, err error) {
@@ -830,10 +967,17 @@ func (c *cnxn) SetOption(key, value string) error {
 // codes are defined as constants. Codes [0, 10_000) are reserved
 // for ADBC usage. Drivers/vendors will ignore requests for unrecognized
 // codes (the row will be omitted from the result).
+//
+// The SqlInfo values of the server are reported under the codes
+// [InfoFlightSqlBase, InfoFlightSqlEnd), all of them being reported
+// when no codes are requested, including those which are also reported
+// under a standard code such as InfoVendorName.
 func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
 	const strValTypeID arrow.UnionTypeCode = 0
 
-	if len(infoCodes) == 0 {
+	// with no codes, every SqlInfo of the server is reported
+	all := len(infoCodes) == 0
+	if all {
 		infoCodes = infoSupportedCodes
 	}
 
@@ -845,10 +989,19 @@ func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.Re
 	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
 	strInfoBldr := infoValueBldr.Child(0).(*array.StringBuilder)
 
+	// the info codes requested for each SqlInfo
+	requested := make(map[flightsql.SqlInfo][]adbc.InfoCode)
 	translated := make([]flightsql.SqlInfo, 0, len(infoCodes))
 	for _, code := range infoCodes {
-		if t, ok := adbcToFlightSQLInfo[code]; ok {
-			translated = append(translated, t)
+		t, ok := adbcToFlightSQLInfo[code]
+		if !ok && code >= InfoFlightSqlBase && code < InfoFlightSqlEnd {
+			t, ok = flightsql.SqlInfo(code-InfoFlightSqlBase), true
+		}
+		if ok {
+			if _, dup := requested[t]; !dup {
+				translated = append(translated, t)
+			}
+			requested[t] = append(requested[t], code)
 			continue
 		}
 
@@ -865,52 +1018,92 @@ func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.Re
 			infoNameBldr.Append(uint32(code))
 			infoValueBldr.Append(strValTypeID)
 			strInfoBldr.Append(infoDriverArrowVersion)
+		case adbc.InfoFeatureBulkIngestModes, adbc.InfoFeatureIsolationLevels:
+			// Flight SQL has no bulk ingestion, nor isolation levels
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoStringList(infoValueBldr, nil)
+		case adbc.InfoFeaturePartitions, adbc.InfoFeatureCancel:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoBool(infoValueBldr, true)
+		case adbc.InfoFeatureTransactions:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoBool(infoValueBldr, c.supportInfo.transactions)
+		case adbc.InfoFeatureSavepoints:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoBool(infoValueBldr, c.supportInfo.savepoints)
+		}
+	}
+
+	if all || len(translated) > 0 {
+		if all {
+			// an empty list asks the server for all of its SqlInfo
+			translated = nil
+		}
+		if err := c.appendSqlInfo(ctx, translated, requested, infoNameBldr, infoValueBldr); err != nil {
+			return nil, err
 		}
 	}
 
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{final})
+}
+
+// appendSqlInfo appends the SqlInfo values of the server under the
+// info codes requested for them. With no SqlInfo, all of them are
+// appended, each one under its code offset by InfoFlightSqlBase as
+// well as under the standard info code it matches, if any.
+func (c *cnxn) appendSqlInfo(ctx context.Context, translated []flightsql.SqlInfo, requested map[flightsql.SqlInfo][]adbc.InfoCode, infoNameBldr *array.Uint32Builder, infoValueBldr *array.DenseUnionBuilder) error {
 	ctx = metadata.NewOutgoingContext(ctx, c.hdrs)
 	info, err := c.cl.GetSqlInfo(ctx, translated, c.timeouts)
-	if err == nil {
-		for _, endpoint := range info.Endpoint {
-			rdr, err := doGet(ctx, c.cl, endpoint, c.clientCache, c.timeouts)
-			if err != nil {
-				return nil, adbcFromFlightStatus(err)
-			}
+	if err != nil {
+		if grpcstatus.Code(err) == grpccodes.Unimplemented {
+			return nil
+		}
+		return adbcFromFlightStatus(err)
+	}
 
-			for rdr.Next() {
-				rec := rdr.Record()
-				field := rec.Column(0).(*array.Uint32)
-				info := rec.Column(1).(*array.DenseUnion)
+	for _, endpoint := range info.Endpoint {
+		rdr, err := doGet(ctx, c.cl, endpoint, c.clientCache, c.timeouts)
+		if err != nil {
+			return adbcFromFlightStatus(err)
+		}
 
-				for i := 0; i < int(rec.NumRows()); i++ {
-					switch flightsql.SqlInfo(field.Value(i)) {
-					case flightsql.SqlInfoFlightSqlServerName:
-						infoNameBldr.Append(uint32(adbc.InfoVendorName))
-					case flightsql.SqlInfoFlightSqlServerVersion:
-						infoNameBldr.Append(uint32(adbc.InfoVendorVersion))
-					case flightsql.SqlInfoFlightSqlServerArrowVersion:
-						infoNameBldr.Append(uint32(adbc.InfoVendorArrowVersion))
-					}
+		for rdr.Next() {
+			rec := rdr.Record()
+			field := rec.Column(0).(*array.Uint32)
+			values := rec.Column(1).(*array.DenseUnion)
 
-					infoValueBldr.Append(info.TypeCode(i))
-					// we know we're only doing string fields here right now
-					v := info.Field(info.ChildID(i)).(*array.String).
-						Value(int(info.ValueOffset(i)))
-					strInfoBldr.Append(v)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				sqlInfo := flightsql.SqlInfo(field.Value(i))
+				codes := requested[sqlInfo]
+				if code := InfoFlightSqlBase + adbc.InfoCode(sqlInfo); len(translated) == 0 && code < InfoFlightSqlEnd && !containsInfoCode(codes, code) {
+					codes = append(codes[:len(codes):len(codes)], code)
 				}
-			}
 
-			if rdr.Err() != nil {
-				return nil, adbcFromFlightStatus(rdr.Err())
+				for _, code := range codes {
+					infoNameBldr.Append(uint32(code))
+					appendInfoValue(infoValueBldr, values, i)
+				}
 			}
 		}
-	} else if grpcstatus.Code(err) != grpccodes.Unimplemented {
-		return nil, adbcFromFlightStatus(err)
+
+		err = rdr.Err()
+		rdr.Release()
+		if err != nil {
+			return adbcFromFlightStatus(err)
+		}
 	}
+	return nil
+}
 
-	final := bldr.NewRecord()
-	defer final.Release()
-	return array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{final})
+func containsInfoCode(codes []adbc.InfoCode, code adbc.InfoCode) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
 }
 
 // GetObjects gets a hierarchical view of all catalogs, database schemas,
@@ -1336,6 +1529,22 @@ func (c *cnxn) executeSubstrait(ctx context.Context, plan flightsql.SubstraitPla
 	return c.cl.ExecuteSubstrait(ctx, plan, opts...)
 }
 
+func (c *cnxn) executeSchema(ctx context.Context, query string, opts ...grpc.CallOption) (*flight.SchemaResult, error) {
+	if c.txn != nil {
+		return c.txn.GetExecuteSchema(ctx, query, opts...)
+	}
+
+	return c.cl.GetExecuteSchema(ctx, query, opts...)
+}
+
+func (c *cnxn) executeSubstraitSchema(ctx context.Context, plan flightsql.SubstraitPlan, opts ...grpc.CallOption) (*flight.SchemaResult, error) {
+	if c.txn != nil {
+		return c.txn.GetExecuteSubstraitSchema(ctx, plan, opts...)
+	}
+
+	return c.cl.GetExecuteSubstraitSchema(ctx, plan, opts...)
+}
+
 func (c *cnxn) executeUpdate(ctx context.Context, query string, opts ...grpc.CallOption) (n int64, err error) {
 	if c.txn != nil {
 		return c.txn.ExecuteUpdate(ctx, query, opts...)
@@ -1412,5 +1621,6 @@ func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (r
 }
 
 var (
-	_ adbc.PostInitOptions = (*cnxn)(nil)
+	_ adbc.PostInitOptions        = (*cnxn)(nil)
+	_ adbc.StatementExecuteSchema = (*statement)(nil)
 )
@@ -26,6 +26,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"database/sql"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io"
@@ -34,6 +35,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -45,6 +47,7 @@ import (
 	"github.com/apache/arrow/go/v12/arrow/flight"
 	"github.com/apache/arrow/go/v12/arrow/flight/flightsql"
 	"github.com/apache/arrow/go/v12/arrow/flight/flightsql/example"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
 	"github.com/apache/arrow/go/v12/arrow/memory"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -54,6 +57,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type HeaderServerMiddleware struct {
@@ -294,6 +298,10 @@ func TestADBCFlightSQL(t *testing.T) {
 	suite.Run(t, &TimeoutTestSuite{})
 	suite.Run(t, &TLSTests{Quirks: &FlightSQLQuirks{db: db}})
 	suite.Run(t, &ConnectionTests{})
+	suite.Run(t, &SqlInfoTests{})
+	suite.Run(t, &StatisticsTests{})
+	suite.Run(t, &ExecuteSchemaTests{})
+	suite.Run(t, &ErrorDetailsTests{})
 	suite.Run(t, &DomainSocketTests{db: db})
 }
 
@@ -1290,6 +1298,570 @@ func (suite *ConnectionTests) TestGetInfo() {
 	suite.Require().True(driverArrowVersion)
 }
 
+func (suite *ConnectionTests) TestGetStatisticsNotSupported() {
+	var adbcErr adbc.Error
+
+	_, err := suite.Cnxn.(adbc.ConnectionGetStatistics).GetStatistics(suite.ctx, nil, nil, nil, true)
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+
+	_, err = suite.Cnxn.(adbc.ConnectionGetStatistics).GetStatisticNames(suite.ctx)
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+// SqlInfoTests checks that SqlInfo values of every type are reported
+// by GetInfo.
+type SqlInfoTests struct {
+	suite.Suite
+
+	alloc  *memory.CheckedAllocator
+	server flight.Server
+	DB     adbc.Database
+	Cnxn   adbc.Connection
+	ctx    context.Context
+}
+
+func (suite *SqlInfoTests) SetupSuite() {
+	suite.alloc = memory.NewCheckedAllocator(memory.DefaultAllocator)
+
+	srv := &flightsql.BaseServer{}
+	for id, value := range map[flightsql.SqlInfo]interface{}{
+		flightsql.SqlInfoFlightSqlServerName:                "test server",
+		flightsql.SqlInfoFlightSqlServerCancel:              true,
+		flightsql.SqlInfoMaxBinaryLiteralLen:                int64(1024),
+		flightsql.SqlInfoSupportedGrammar:                   int32(3),
+		flightsql.SqlInfoKeywords:                           []string{"ASOF", "QUALIFY"},
+		flightsql.SqlInfoSupportsConvert:                    map[int32][]int32{1: {2, 3}},
+		flightsql.SqlInfoFlightSqlServerSubstraitMinVersion: "0.1.0",
+	} {
+		suite.Require().NoError(srv.RegisterSqlInfo(id, value))
+	}
+
+	suite.server = flight.NewServerWithMiddleware(nil)
+	suite.server.RegisterFlightService(flightsql.NewFlightServer(srv))
+	suite.Require().NoError(suite.server.Init("localhost:0"))
+
+	go func() {
+		// Explicitly ignore error
+		_ = suite.server.Serve()
+	}()
+
+	var err error
+	suite.ctx = context.Background()
+	suite.DB, err = (driver.Driver{Alloc: suite.alloc}).NewDatabase(map[string]string{
+		adbc.OptionKeyURI: "grpc+tcp://" + suite.server.Addr().String(),
+	})
+	suite.Require().NoError(err)
+	suite.Cnxn, err = suite.DB.Open(suite.ctx)
+	suite.Require().NoError(err)
+}
+
+func (suite *SqlInfoTests) TearDownSuite() {
+	suite.Require().NoError(suite.Cnxn.Close())
+	suite.server.Shutdown()
+	suite.alloc.AssertSize(suite.T(), 0)
+}
+
+// getInfo returns the values reported by GetInfo as JSON.
+func (suite *SqlInfoTests) getInfo(codes []adbc.InfoCode) map[adbc.InfoCode]string {
+	rdr, err := suite.Cnxn.GetInfo(suite.ctx, codes)
+	suite.Require().NoError(err)
+	defer rdr.Release()
+
+	values := make(map[adbc.InfoCode]string)
+	for rdr.Next() {
+		rec := rdr.Record()
+		names := rec.Column(0).(*array.Uint32)
+		union := rec.Column(1).(*array.DenseUnion)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			v, err := json.Marshal(union.Field(union.ChildID(i)).GetOneForMarshal(int(union.ValueOffset(i))))
+			suite.Require().NoError(err)
+			values[adbc.InfoCode(names.Value(i))] = string(v)
+		}
+	}
+	suite.Require().NoError(rdr.Err())
+	return values
+}
+
+func (suite *SqlInfoTests) TestAllTypes() {
+	values := suite.getInfo([]adbc.InfoCode{
+		adbc.InfoVendorName,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoFlightSqlServerName),
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoFlightSqlServerCancel),
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoMaxBinaryLiteralLen),
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoSupportedGrammar),
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoKeywords),
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoSupportsConvert),
+	})
+
+	suite.Equal(map[adbc.InfoCode]string{
+		adbc.InfoVendorName: `"test server"`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoFlightSqlServerName):   `"test server"`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoFlightSqlServerCancel): `true`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoMaxBinaryLiteralLen):   `1024`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoSupportedGrammar):      `3`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoKeywords):              `["ASOF","QUALIFY"]`,
+		driver.InfoFlightSqlBase + adbc.InfoCode(flightsql.SqlInfoSupportsConvert):       `[{"key":1,"value":[2,3]}]`,
+	}, values)
+}
+
+func (suite *SqlInfoTests) TestAll() {
+	values := suite.getInfo(nil)
+
+	suite.Equal(`"ADBC Flight SQL Driver - Go"`, values[adbc.InfoDriverName])
+	suite.Equal(`"test server"`, values[adbc.InfoVendorName])
+	suite.Equal(`"0.1.0"`, values[adbc.InfoVendorSubstraitMinVersion])
+	suite.Equal(`true`, values[driver.InfoFlightSqlBase+adbc.InfoCode(flightsql.SqlInfoFlightSqlServerCancel)])
+	suite.Equal(`["ASOF","QUALIFY"]`, values[driver.InfoFlightSqlBase+adbc.InfoCode(flightsql.SqlInfoKeywords)])
+	// the SqlInfo matching standard info codes are reported under both
+	suite.Equal(`"test server"`, values[driver.InfoFlightSqlBase+adbc.InfoCode(flightsql.SqlInfoFlightSqlServerName)])
+	suite.Equal(`"0.1.0"`, values[driver.InfoFlightSqlBase+adbc.InfoCode(flightsql.SqlInfoFlightSqlServerSubstraitMinVersion)])
+
+	suite.Equal(`true`, values[adbc.InfoFeaturePartitions])
+	suite.Equal(`false`, values[adbc.InfoFeatureTransactions])
+	suite.Equal(`[]`, values[adbc.InfoFeatureBulkIngestModes])
+}
+
+func (suite *SqlInfoTests) TestAllMatchesSpecific() {
+	all := suite.getInfo(nil)
+	suite.Require().NotEmpty(all)
+
+	codes := make([]adbc.InfoCode, 0, len(all))
+	for code, value := range all {
+		codes = append(codes, code)
+		suite.Equal(map[adbc.InfoCode]string{code: value}, suite.getInfo([]adbc.InfoCode{code}), "info code %d", code)
+	}
+	suite.Equal(all, suite.getInfo(codes))
+}
+
+func (suite *SqlInfoTests) TestDriverOnly() {
+	values := suite.getInfo([]adbc.InfoCode{adbc.InfoDriverName})
+	suite.Equal(map[adbc.InfoCode]string{adbc.InfoDriverName: `"ADBC Flight SQL Driver - Go"`}, values)
+}
+
+// StatisticsTestServer adds the statistics actions to a Flight SQL
+// server, it reports one row count for whatever table was requested.
+type StatisticsTestServer struct {
+	flight.FlightServer
+
+	alloc     memory.Allocator
+	request   driver.GetStatisticsRequest
+	listCalls int32
+}
+
+func (ss *StatisticsTestServer) ListActions(_ *flight.Empty, stream flight.FlightService_ListActionsServer) error {
+	atomic.AddInt32(&ss.listCalls, 1)
+	for _, action := range []string{driver.GetStatisticsActionType, driver.GetStatisticNamesActionType} {
+		if err := stream.Send(&flight.ActionType{Type: action}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *StatisticsTestServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	var (
+		sc   *arrow.Schema
+		data string
+	)
+	switch action.Type {
+	case driver.GetStatisticsActionType:
+		ss.request = driver.GetStatisticsRequest{}
+		if err := json.Unmarshal(action.Body, &ss.request); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		sc = adbc.GetStatisticsSchema
+		data = fmt.Sprintf(`[{"catalog_name": "main", "catalog_db_schemas": [{
+			"db_schema_name": "public",
+			"db_schema_statistics": [{
+				"table_name": %q, "column_name": null,
+				"statistic_key": %d, "statistic_value": [0, 42],
+				"statistic_is_approximate": %t}]}]}]`,
+			*ss.request.TableName, adbc.StatisticRowCountKey, ss.request.Approximate)
+	case driver.GetStatisticNamesActionType:
+		sc = adbc.GetStatisticNamesSchema
+		data = `[{"statistic_name": "test.statistic", "statistic_key": 1024}]`
+	default:
+		return ss.FlightServer.DoAction(action, stream)
+	}
+
+	rec, _, err := array.RecordFromJSON(ss.alloc, sc, strings.NewReader(data))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(sc), ipc.WithAllocator(ss.alloc))
+	if err := w.Write(rec); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.Send(&flight.Result{Body: buf.Bytes()})
+}
+
+type StatisticsTests struct {
+	suite.Suite
+
+	alloc   *memory.CheckedAllocator
+	server  flight.Server
+	service *StatisticsTestServer
+
+	DB   adbc.Database
+	Cnxn adbc.Connection
+	ctx  context.Context
+}
+
+func (suite *StatisticsTests) SetupSuite() {
+	suite.alloc = memory.NewCheckedAllocator(memory.DefaultAllocator)
+
+	suite.server = flight.NewServerWithMiddleware(nil)
+	suite.Require().NoError(suite.server.Init("localhost:0"))
+	suite.service = &StatisticsTestServer{
+		FlightServer: flightsql.NewFlightServer(&flightsql.BaseServer{}),
+		alloc:        suite.alloc,
+	}
+	suite.server.RegisterFlightService(suite.service)
+
+	go func() {
+		// Explicitly ignore error
+		_ = suite.server.Serve()
+	}()
+
+	var err error
+	suite.ctx = context.Background()
+	suite.DB, err = (driver.Driver{Alloc: suite.alloc}).NewDatabase(map[string]string{
+		adbc.OptionKeyURI: "grpc+tcp://" + suite.server.Addr().String(),
+	})
+	suite.Require().NoError(err)
+	suite.Cnxn, err = suite.DB.Open(suite.ctx)
+	suite.Require().NoError(err)
+}
+
+func (suite *StatisticsTests) TearDownSuite() {
+	suite.Require().NoError(suite.Cnxn.Close())
+	suite.server.Shutdown()
+	suite.alloc.AssertSize(suite.T(), 0)
+}
+
+func (suite *StatisticsTests) TestGetStatistics() {
+	table := "foo"
+	rdr, err := suite.Cnxn.(adbc.ConnectionGetStatistics).GetStatistics(suite.ctx, nil, nil, &table, true)
+	suite.Require().NoError(err)
+	defer rdr.Release()
+
+	suite.Nil(suite.service.request.Catalog)
+	suite.Nil(suite.service.request.DBSchema)
+	suite.Equal(&table, suite.service.request.TableName)
+	suite.True(suite.service.request.Approximate)
+
+	suite.True(rdr.Schema().Equal(adbc.GetStatisticsSchema))
+	suite.Require().True(rdr.Next())
+	rec := rdr.Record()
+	suite.EqualValues(1, rec.NumRows())
+	suite.Equal("main", rec.Column(0).(*array.String).Value(0))
+
+	schemas := rec.Column(1).(*array.List).ListValues().(*array.Struct)
+	suite.Equal("public", schemas.Field(0).(*array.String).Value(0))
+	stats := schemas.Field(1).(*array.List).ListValues().(*array.Struct)
+	suite.Equal(1, stats.Len())
+	suite.Equal("foo", stats.Field(0).(*array.String).Value(0))
+	suite.True(stats.Field(1).IsNull(0))
+	suite.EqualValues(adbc.StatisticRowCountKey, stats.Field(2).(*array.Int16).Value(0))
+	values := stats.Field(3).(*array.DenseUnion)
+	suite.EqualValues(42, values.Field(0).(*array.Int64).Value(int(values.ValueOffset(0))))
+	suite.True(stats.Field(4).(*array.Boolean).Value(0))
+
+	suite.False(rdr.Next())
+	suite.NoError(rdr.Err())
+}
+
+func (suite *StatisticsTests) TestListActionsOnce() {
+	cnxn, err := suite.DB.Open(suite.ctx)
+	suite.Require().NoError(err)
+	defer cnxn.Close()
+
+	for _, cn := range []adbc.Connection{suite.Cnxn, cnxn, cnxn} {
+		rdr, err := cn.(adbc.ConnectionGetStatistics).GetStatisticNames(suite.ctx)
+		suite.Require().NoError(err)
+		rdr.Release()
+	}
+	suite.EqualValues(1, atomic.LoadInt32(&suite.service.listCalls))
+}
+
+func (suite *StatisticsTests) TestGetStatisticNames() {
+	rdr, err := suite.Cnxn.(adbc.ConnectionGetStatistics).GetStatisticNames(suite.ctx)
+	suite.Require().NoError(err)
+	defer rdr.Release()
+
+	suite.Require().True(rdr.Next())
+	rec := rdr.Record()
+	suite.EqualValues(1, rec.NumRows())
+	suite.Equal("test.statistic", rec.Column(0).(*array.String).Value(0))
+	suite.EqualValues(1024, rec.Column(1).(*array.Int16).Value(0))
+	suite.False(rdr.Next())
+	suite.NoError(rdr.Err())
+}
+
+// ExecuteSchemaTestServer reports the result schema of the query
+// "schema" with GetSchema, of "info" with GetFlightInfo only, and of
+// "prepared" when it is prepared. It counts the GetFlightInfo calls.
+type ExecuteSchemaTestServer struct {
+	flightsql.BaseServer
+
+	infoCalls int32
+}
+
+var executeSchemaTestSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	{Name: "b", Type: arrow.BinaryTypes.String},
+}, nil)
+
+func (ts *ExecuteSchemaTestServer) GetSchemaStatement(ctx context.Context, cmd flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.SchemaResult, error) {
+	if cmd.GetQuery() == "schema" {
+		return &flight.SchemaResult{Schema: flight.SerializeSchema(executeSchemaTestSchema, memory.DefaultAllocator)}, nil
+	}
+	return ts.BaseServer.GetSchemaStatement(ctx, cmd, desc)
+}
+
+func (ts *ExecuteSchemaTestServer) GetFlightInfoStatement(ctx context.Context, cmd flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	atomic.AddInt32(&ts.infoCalls, 1)
+	info := &flight.FlightInfo{
+		FlightDescriptor: desc,
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}
+	if cmd.GetQuery() == "info" {
+		info.Schema = flight.SerializeSchema(executeSchemaTestSchema, memory.DefaultAllocator)
+	}
+	return info, nil
+}
+
+func (ts *ExecuteSchemaTestServer) CreatePreparedStatement(ctx context.Context, req flightsql.ActionCreatePreparedStatementRequest) (result flightsql.ActionCreatePreparedStatementResult, err error) {
+	result.Handle = []byte(req.GetQuery())
+	if req.GetQuery() == "prepared" {
+		result.DatasetSchema = executeSchemaTestSchema
+	}
+	return
+}
+
+func (ts *ExecuteSchemaTestServer) ClosePreparedStatement(context.Context, flightsql.ActionClosePreparedStatementRequest) error {
+	return nil
+}
+
+type ExecuteSchemaTests struct {
+	suite.Suite
+
+	server  flight.Server
+	service *ExecuteSchemaTestServer
+	DB      adbc.Database
+	Cnxn    adbc.Connection
+	Stmt    adbc.Statement
+	ctx     context.Context
+}
+
+func (suite *ExecuteSchemaTests) SetupSuite() {
+	suite.server = flight.NewServerWithMiddleware(nil)
+	suite.service = &ExecuteSchemaTestServer{}
+	suite.server.RegisterFlightService(flightsql.NewFlightServer(suite.service))
+	suite.Require().NoError(suite.server.Init("localhost:0"))
+
+	go func() {
+		// Explicitly ignore error
+		_ = suite.server.Serve()
+	}()
+
+	var err error
+	suite.ctx = context.Background()
+	suite.DB, err = (driver.Driver{}).NewDatabase(map[string]string{
+		adbc.OptionKeyURI: "grpc+tcp://" + suite.server.Addr().String(),
+	})
+	suite.Require().NoError(err)
+	suite.Cnxn, err = suite.DB.Open(suite.ctx)
+	suite.Require().NoError(err)
+}
+
+func (suite *ExecuteSchemaTests) SetupTest() {
+	var err error
+	suite.Stmt, err = suite.Cnxn.NewStatement()
+	suite.Require().NoError(err)
+}
+
+func (suite *ExecuteSchemaTests) TearDownTest() {
+	suite.Require().NoError(suite.Stmt.Close())
+}
+
+func (suite *ExecuteSchemaTests) TearDownSuite() {
+	suite.Require().NoError(suite.Cnxn.Close())
+	suite.server.Shutdown()
+}
+
+func (suite *ExecuteSchemaTests) executeSchema(query string, prepare bool) (*arrow.Schema, error) {
+	suite.Require().NoError(suite.Stmt.SetSqlQuery(query))
+	if prepare {
+		suite.Require().NoError(suite.Stmt.Prepare(suite.ctx))
+	}
+	return suite.Stmt.(adbc.StatementExecuteSchema).ExecuteSchema(suite.ctx)
+}
+
+func (suite *ExecuteSchemaTests) TestGetSchema() {
+	sc, err := suite.executeSchema("schema", false)
+	suite.Require().NoError(err)
+	suite.True(executeSchemaTestSchema.Equal(sc), sc.String())
+}
+
+func (suite *ExecuteSchemaTests) TestNoFlightInfoFallback() {
+	// GetFlightInfo may run the query, so it is not asked instead
+	var adbcErr adbc.Error
+	_, err := suite.executeSchema("info", false)
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+	suite.Zero(atomic.LoadInt32(&suite.service.infoCalls))
+}
+
+func (suite *ExecuteSchemaTests) TestPreparedDatasetSchema() {
+	sc, err := suite.executeSchema("prepared", true)
+	suite.Require().NoError(err)
+	suite.True(executeSchemaTestSchema.Equal(sc), sc.String())
+}
+
+func (suite *ExecuteSchemaTests) TestNoSchema() {
+	var adbcErr adbc.Error
+	_, err := suite.executeSchema("other", false)
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+
+	_, err = suite.executeSchema("other", true)
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+// ErrorDetailsTests checks that the details and trailers of the gRPC
+// errors of the server are kept in the adbc.Error.
+type ErrorDetailsTests struct {
+	suite.Suite
+
+	server flight.Server
+	DB     adbc.Database
+	Cnxn   adbc.Connection
+	Stmt   adbc.Statement
+	ctx    context.Context
+}
+
+// ErrorDetailsTestServer fails GetFlightInfo for the query "info" and
+// DoGet for any other, with a detail and a trailer.
+type ErrorDetailsTestServer struct {
+	flightsql.BaseServer
+}
+
+func errorDetailsTestErr(ctx context.Context) error {
+	if err := grpc.SetTrailer(ctx, metadata.Pairs("x-detail", "trailer", "x-detail-bin", "\x00\x01")); err != nil {
+		return err
+	}
+	st, err := status.New(codes.FailedPrecondition, "no such query").
+		WithDetails(wrapperspb.String("detail"))
+	if err != nil {
+		return err
+	}
+	return st.Err()
+}
+
+func (ts *ErrorDetailsTestServer) GetFlightInfoStatement(ctx context.Context, cmd flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if cmd.GetQuery() == "info" {
+		return nil, errorDetailsTestErr(ctx)
+	}
+	tkt, err := flightsql.CreateStatementQueryTicket([]byte(cmd.GetQuery()))
+	if err != nil {
+		return nil, err
+	}
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: tkt}}},
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+func (ts *ErrorDetailsTestServer) DoGetStatement(ctx context.Context, tkt flightsql.StatementQueryTicket) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	return nil, nil, errorDetailsTestErr(ctx)
+}
+
+func (suite *ErrorDetailsTests) SetupSuite() {
+	suite.server = flight.NewServerWithMiddleware(nil)
+	suite.server.RegisterFlightService(flightsql.NewFlightServer(&ErrorDetailsTestServer{}))
+	suite.Require().NoError(suite.server.Init("localhost:0"))
+
+	go func() {
+		// Explicitly ignore error
+		_ = suite.server.Serve()
+	}()
+
+	var err error
+	suite.ctx = context.Background()
+	suite.DB, err = (driver.Driver{}).NewDatabase(map[string]string{
+		adbc.OptionKeyURI: "grpc+tcp://" + suite.server.Addr().String(),
+	})
+	suite.Require().NoError(err)
+	suite.Cnxn, err = suite.DB.Open(suite.ctx)
+	suite.Require().NoError(err)
+}
+
+func (suite *ErrorDetailsTests) SetupTest() {
+	var err error
+	suite.Stmt, err = suite.Cnxn.NewStatement()
+	suite.Require().NoError(err)
+}
+
+func (suite *ErrorDetailsTests) TearDownTest() {
+	suite.Require().NoError(suite.Stmt.Close())
+}
+
+func (suite *ErrorDetailsTests) TearDownSuite() {
+	suite.Require().NoError(suite.Cnxn.Close())
+	suite.server.Shutdown()
+}
+
+func (suite *ErrorDetailsTests) checkError(err error) {
+	var adbcErr adbc.Error
+	suite.Require().ErrorAs(err, &adbcErr)
+	suite.Equal(adbc.StatusUnknown, adbcErr.Code)
+
+	detail, err := proto.Marshal(wrapperspb.String("detail"))
+	suite.Require().NoError(err)
+	suite.Equal([]adbc.ErrorDetail{
+		{Key: "type.googleapis.com/google.protobuf.StringValue", Value: detail},
+		{Key: "x-detail", Value: []byte("trailer")},
+		{Key: "x-detail-bin", Value: []byte{0, 1}},
+	}, adbcErr.Details())
+
+	// the gRPC status is reachable through the chain of errors
+	var withStatus interface{ GRPCStatus() *status.Status }
+	suite.Require().ErrorAs(adbcErr, &withStatus)
+	suite.Equal(codes.FailedPrecondition, withStatus.GRPCStatus().Code())
+	suite.Equal("no such query", withStatus.GRPCStatus().Message())
+}
+
+func (suite *ErrorDetailsTests) TestUnary() {
+	suite.Require().NoError(suite.Stmt.SetSqlQuery("info"))
+	_, _, err := suite.Stmt.ExecuteQuery(suite.ctx)
+	suite.checkError(err)
+}
+
+func (suite *ErrorDetailsTests) TestStream() {
+	suite.Require().NoError(suite.Stmt.SetSqlQuery("stream"))
+	rdr, _, err := suite.Stmt.ExecuteQuery(suite.ctx)
+	if err == nil {
+		defer rdr.Release()
+		for rdr.Next() {
+		}
+		err = rdr.Err()
+	}
+	suite.checkError(err)
+}
+
 type DomainSocketTests struct {
 	suite.Suite
 
@@ -86,6 +86,19 @@ func (s *sqlOrSubstrait) executeUpdate(ctx context.Context, cnxn *cnxn, opts ...
 	}
 }
 
+func (s *sqlOrSubstrait) executeSchema(ctx context.Context, cnxn *cnxn, opts ...grpc.CallOption) (*flight.SchemaResult, error) {
+	if s.sqlQuery != "" {
+		return cnxn.executeSchema(ctx, s.sqlQuery, opts...)
+	} else if s.substraitPlan != nil {
+		return cnxn.executeSubstraitSchema(ctx, flightsql.SubstraitPlan{Plan: s.substraitPlan, Version: s.substraitVersion}, opts...)
+	}
+
+	return nil, adbc.Error{
+		Code: adbc.StatusInvalidState,
+		Msg:  "[Flight SQL Statement] cannot call ExecuteSchema without a query or prepared statement",
+	}
+}
+
 func (s *sqlOrSubstrait) prepare(ctx context.Context, cnxn *cnxn, opts ...grpc.CallOption) (*flightsql.PreparedStatement, error) {
 	if s.sqlQuery != "" {
 		return cnxn.prepare(ctx, s.sqlQuery, opts...)
@@ -247,6 +260,48 @@ func (s *statement) ExecuteQuery(ctx context.Context) (rdr array.RecordReader, n
 	return
 }
 
+// ExecuteSchema gets the schema of the result set of the current query
+// or prepared statement without executing it.
+//
+// A prepared statement reports its dataset schema when it is created,
+// otherwise the schema is requested with GetSchema. StatusNotImplemented
+// is returned if the server doesn't implement GetSchema or reports no
+// schema, GetFlightInfo is not used instead as it may run the query.
+func (s *statement) ExecuteSchema(ctx context.Context) (*arrow.Schema, error) {
+	ctx = metadata.NewOutgoingContext(ctx, s.hdrs)
+
+	var (
+		res *flight.SchemaResult
+		err error
+	)
+	if s.prepared != nil {
+		if sc := s.prepared.DatasetSchema(); sc != nil {
+			return sc, nil
+		}
+		res, err = s.prepared.GetSchema(ctx, s.timeouts)
+	} else {
+		res, err = s.query.executeSchema(ctx, s.cnxn, s.timeouts)
+	}
+
+	if err != nil {
+		return nil, adbcFromFlightStatus(err)
+	}
+
+	serialized := res.Schema
+	if len(serialized) == 0 {
+		return nil, adbc.Error{
+			Msg:  "[Flight SQL Statement] server did not report the result schema",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	sc, err := flight.DeserializeSchema(serialized, s.alloc)
+	if err != nil {
+		return nil, adbcFromFlightStatus(err)
+	}
+	return sc, nil
+}
+
 // ExecuteUpdate executes a statement that does not generate a result
 // set. It returns the number of rows affected if known, otherwise -1.
 func (s *statement) ExecuteUpdate(ctx context.Context) (n int64, err error) {
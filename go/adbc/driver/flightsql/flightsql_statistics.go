@@ -0,0 +1,179 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package flightsql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/flight"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Flight SQL has no commands for table statistics, so GetStatistics and
+// GetStatisticNames are implemented with custom Flight actions instead.
+// A server that supports them must list them in ListActions.
+//
+// The body of a GetStatisticsActionType action is a JSON encoded
+// GetStatisticsRequest and the body of a GetStatisticNamesActionType
+// action is empty. The server replies with one or more results whose
+// bodies are Arrow IPC streams of adbc.GetStatisticsSchema and
+// adbc.GetStatisticNamesSchema respectively.
+const (
+	GetStatisticsActionType     = "adbc.GetStatistics"
+	GetStatisticNamesActionType = "adbc.GetStatisticNames"
+)
+
+// GetStatisticsRequest is the body of a GetStatisticsActionType action.
+// Nil patterns are omitted and mean "do not filter".
+type GetStatisticsRequest struct {
+	Catalog     *string `json:"catalog,omitempty"`
+	DBSchema    *string `json:"db_schema,omitempty"`
+	TableName   *string `json:"table_name,omitempty"`
+	Approximate bool    `json:"approximate"`
+}
+
+// GetStatistics gets statistics about the data distribution of tables
+// by calling the GetStatisticsActionType action. Servers which don't
+// list the action get a NotImplemented error.
+func (c *cnxn) GetStatistics(ctx context.Context, catalog *string, dbSchema *string, tableName *string, approximate bool) (array.RecordReader, error) {
+	body, err := json.Marshal(GetStatisticsRequest{
+		Catalog:     catalog,
+		DBSchema:    dbSchema,
+		TableName:   tableName,
+		Approximate: approximate,
+	})
+	if err != nil {
+		return nil, adbc.Error{
+			Msg:  "[Flight SQL] GetStatistics: " + err.Error(),
+			Code: adbc.StatusInternal,
+		}
+	}
+
+	return c.doStatisticsAction(ctx, GetStatisticsActionType, body, adbc.GetStatisticsSchema)
+}
+
+// GetStatisticNames gets the names of the server specific statistics
+// by calling the GetStatisticNamesActionType action.
+func (c *cnxn) GetStatisticNames(ctx context.Context) (array.RecordReader, error) {
+	return c.doStatisticsAction(ctx, GetStatisticNamesActionType, nil, adbc.GetStatisticNamesSchema)
+}
+
+// supportsAction reports whether the server lists the action type,
+// the list is fetched once per database. A server not implementing
+// ListActions supports none.
+func (c *cnxn) supportsAction(ctx context.Context, actionType string) (bool, error) {
+	c.db.actionsMu.Lock()
+	defer c.db.actionsMu.Unlock()
+	if c.db.actions == nil {
+		actions, err := c.listActions(ctx)
+		if err != nil {
+			return false, err
+		}
+		c.db.actions = actions
+	}
+
+	_, ok := c.db.actions[actionType]
+	return ok, nil
+}
+
+func (c *cnxn) listActions(ctx context.Context) (map[string]struct{}, error) {
+	actions := make(map[string]struct{})
+	stream, err := c.cl.Client.ListActions(ctx, &flight.Empty{}, c.timeouts)
+	for err == nil {
+		var action *flight.ActionType
+		if action, err = stream.Recv(); err == nil {
+			actions[action.Type] = struct{}{}
+		}
+	}
+	if errors.Is(err, io.EOF) || status.Code(err) == codes.Unimplemented {
+		return actions, nil
+	}
+	return nil, adbcFromFlightStatus(err)
+}
+
+func (c *cnxn) doStatisticsAction(ctx context.Context, actionType string, body []byte, expectedSchema *arrow.Schema) (array.RecordReader, error) {
+	ctx = metadata.NewOutgoingContext(ctx, c.hdrs)
+	ok, err := c.supportsAction(ctx, actionType)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, adbc.Error{
+			Msg:  "[Flight SQL] server does not support the " + actionType + " action",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	stream, err := c.cl.Client.DoAction(ctx, &flight.Action{Type: actionType, Body: body}, c.timeouts)
+	if err != nil {
+		return nil, adbcFromFlightStatus(err)
+	}
+
+	recs := make([]arrow.Record, 0)
+	defer func() {
+		for _, r := range recs {
+			r.Release()
+		}
+	}()
+
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, adbcFromFlightStatus(err)
+		}
+
+		rdr, err := ipc.NewReader(bytes.NewReader(result.Body),
+			ipc.WithAllocator(c.db.alloc), ipc.WithSchema(expectedSchema))
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  "[Flight SQL] invalid " + actionType + " result: " + err.Error(),
+				Code: adbc.StatusInvalidData,
+			}
+		}
+
+		for rdr.Next() {
+			rec := rdr.Record()
+			rec.Retain()
+			recs = append(recs, rec)
+		}
+		err = rdr.Err()
+		rdr.Release()
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  "[Flight SQL] invalid " + actionType + " result: " + err.Error(),
+				Code: adbc.StatusInvalidData,
+			}
+		}
+	}
+
+	return array.NewRecordReader(expectedSchema, recs)
+}
+
+var _ adbc.ConnectionGetStatistics = (*cnxn)(nil)
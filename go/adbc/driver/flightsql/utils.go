@@ -18,8 +18,15 @@
 package flightsql
 
 import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
 	"github.com/apache/arrow-adbc/go/adbc"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -28,8 +35,22 @@ func adbcFromFlightStatus(err error) error {
 		return err
 	}
 
+	// the status may be wrapped, such as by trailerError or by the
+	// readers of arrow, which status.Code does not look through
+	var (
+		withStatus interface{ GRPCStatus() *status.Status }
+		grpcStatus *status.Status
+	)
+	if err == nil {
+		return nil
+	} else if errors.As(err, &withStatus) {
+		grpcStatus = withStatus.GRPCStatus()
+	} else {
+		grpcStatus = status.New(codes.Unknown, err.Error())
+	}
+
 	var adbcCode adbc.Status
-	switch status.Code(err) {
+	switch grpcStatus.Code() {
 	case codes.OK:
 		return nil
 	case codes.Canceled:
@@ -71,5 +92,78 @@ func adbcFromFlightStatus(err error) error {
 	return adbc.Error{
 		Msg:  err.Error(),
 		Code: adbcCode,
+	}.WithErr(err).WithDetails(flightErrorDetails(err, grpcStatus)...)
+}
+
+// flightErrorDetails returns the details of a gRPC status, keyed by
+// their type URL with the serialized message as the value, followed by
+// the trailers sent along with the error, if any, one per value.
+func flightErrorDetails(err error, grpcStatus *status.Status) []adbc.ErrorDetail {
+	var details []adbc.ErrorDetail
+	for _, d := range grpcStatus.Proto().GetDetails() {
+		details = append(details, adbc.ErrorDetail{Key: d.GetTypeUrl(), Value: d.GetValue()})
+	}
+
+	var te trailerError
+	if errors.As(err, &te) {
+		keys := make([]string, 0, len(te.trailers))
+		for k := range te.trailers {
+			// a response without data has its headers in the trailers
+			if k != "content-type" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			for _, v := range te.trailers[k] {
+				details = append(details, adbc.ErrorDetail{Key: k, Value: []byte(v)})
+			}
+		}
+	}
+	return details
+}
+
+// trailerError is an error of a gRPC call along with the trailers the
+// server sent with it, which become details of the adbc.Error.
+type trailerError struct {
+	error
+	trailers metadata.MD
+}
+
+func (e trailerError) GRPCStatus() *status.Status { return status.Convert(e.error) }
+
+func (e trailerError) Unwrap() error { return e.error }
+
+func withTrailers(err error, trailers metadata.MD) error {
+	if err == nil || err == io.EOF || trailers.Len() == 0 {
+		return err
+	}
+	return trailerError{error: err, trailers: trailers}
+}
+
+func unaryTrailerInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var trailers metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailers))...)
+	return withTrailers(err, trailers)
+}
+
+type trailerClientStream struct {
+	grpc.ClientStream
+}
+
+func (s trailerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && err != io.EOF {
+		// the trailers are available once RecvMsg fails
+		return withTrailers(err, s.Trailer())
+	}
+	return err
+}
+
+func streamTrailerInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return s, err
 	}
+	return trailerClientStream{s}, nil
 }
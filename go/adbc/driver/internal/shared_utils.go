@@ -304,3 +304,27 @@ func (g *GetObjects) appendTableInfo(tableInfo TableInfo) {
 		g.tableColumnsItems.Append(true)
 	}
 }
+
+const (
+	infoBoolTypeID       arrow.UnionTypeCode = 1
+	infoStringListTypeID arrow.UnionTypeCode = 4
+)
+
+// AppendInfoBool appends a bool_value to the info_value union of
+// adbc.GetInfoSchema.
+func AppendInfoBool(bldr *array.DenseUnionBuilder, v bool) {
+	bldr.Append(infoBoolTypeID)
+	bldr.Child(int(infoBoolTypeID)).(*array.BooleanBuilder).Append(v)
+}
+
+// AppendInfoStringList appends a string_list to the info_value union
+// of adbc.GetInfoSchema.
+func AppendInfoStringList(bldr *array.DenseUnionBuilder, v []string) {
+	bldr.Append(infoStringListTypeID)
+	listBldr := bldr.Child(int(infoStringListTypeID)).(*array.ListBuilder)
+	listBldr.Append(true)
+	valueBldr := listBldr.ValueBuilder().(*array.StringBuilder)
+	for _, s := range v {
+		valueBldr.Append(s)
+	}
+}
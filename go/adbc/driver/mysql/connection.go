@@ -0,0 +1,773 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/driver/internal"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// MySQL has a single catalog
+const catalogName = "def"
+
+type cnxn struct {
+	db *database
+	pc *packetConn
+
+	caps          uint32
+	serverVersion string
+	autocommit    bool
+
+	// active is the result set currently being streamed from the
+	// server, which has to be read to the end before the next command
+	active *resultSet
+}
+
+// serverStmt is a statement prepared on the server
+type serverStmt struct {
+	id     uint32
+	params []column
+	cols   []column
+}
+
+// paramFunc appends the value of parameter i to the parameters of a
+// COM_STMT_EXECUTE packet, as appendParam does.
+type paramFunc func(b []byte, i int) (typ byte, flags byte, out []byte, err error)
+
+func stringParams(args ...interface{}) paramFunc {
+	return func(b []byte, i int) (byte, byte, []byte, error) {
+		s, ok := args[i].(string)
+		if !ok {
+			return typeNull, 0, b, nil
+		}
+		return typeVarString, 0, appendLenEncString(b, s), nil
+	}
+}
+
+// command sends a command, first discarding the rest of the active
+// result set.
+func (c *cnxn) command(cmd byte, arg []byte) error {
+	if c.pc == nil {
+		return adbc.Error{
+			Msg:  "[MySQL] connection is closed",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if rs := c.active; rs != nil {
+		if err := rs.discard(); err != nil {
+			return err
+		}
+		rs.discarded = true
+	}
+	return c.pc.writeCommand(cmd, arg)
+}
+
+// readResponse reads the response to a query, which is either an OK
+// packet or the start of a result set.
+func (c *cnxn) readResponse(binary bool) (okPacket, *resultSet, error) {
+	data, err := c.pc.readPacket()
+	if err != nil {
+		return okPacket{}, nil, err
+	}
+	if len(data) == 0 {
+		return okPacket{}, nil, c.pc.ioError(errMalformedPacket)
+	}
+
+	switch data[0] {
+	case iOK:
+		ok, err := parseOK(data)
+		if err != nil {
+			return ok, nil, c.pc.ioError(err)
+		}
+		return ok, nil, nil
+	case iERR:
+		return okPacket{}, nil, parseError(data)
+	case iLocalInFile:
+		return okPacket{}, nil, c.refuseLocalInfile()
+	}
+
+	p := payload{buf: data}
+	ncols, _ := p.lenEncInt()
+	if p.err != nil {
+		return okPacket{}, nil, c.pc.ioError(p.err)
+	}
+	cols, err := c.readColumns(int(ncols))
+	if err != nil {
+		return okPacket{}, nil, err
+	}
+
+	rs := &resultSet{c: c, cols: cols, binary: binary}
+	c.active = rs
+	return okPacket{}, rs, nil
+}
+
+// readColumns reads n column definitions and, unless deprecated, the EOF
+// packet following them.
+func (c *cnxn) readColumns(n int) ([]column, error) {
+	if n == 0 {
+		return nil, nil
+	}
+
+	cols := make([]column, n)
+	for i := range cols {
+		data, err := c.pc.readPacket()
+		if err != nil {
+			return nil, err
+		}
+		if cols[i], err = parseColumn(data); err != nil {
+			return nil, c.pc.ioError(err)
+		}
+	}
+	if c.caps&clientDeprecateEOF == 0 {
+		if _, err := c.pc.readPacket(); err != nil {
+			return nil, err
+		}
+	}
+	return cols, nil
+}
+
+// discardResults reads the remaining results of a command, dropping any
+// rows and summing the affected rows.
+func (c *cnxn) discardResults(binary bool) (okPacket, error) {
+	var total okPacket
+	for {
+		ok, rs, err := c.readResponse(binary)
+		if err != nil {
+			return total, err
+		}
+		if rs != nil {
+			if err := rs.discard(); err != nil {
+				return total, err
+			}
+			// the status of a result set is only known once it has been
+			// read, and further results have been discarded with it
+			return total, nil
+		}
+
+		total.affectedRows += ok.affectedRows
+		total.lastInsertID = ok.lastInsertID
+		total.status = ok.status
+		total.warnings += ok.warnings
+		if ok.status&statusMoreResultsExists == 0 {
+			return total, nil
+		}
+	}
+}
+
+// refuseLocalInfile answers a request for a local file the driver didn't
+// ask for with an empty file, so that a server cannot read arbitrary
+// data from the client.
+func (c *cnxn) refuseLocalInfile() error {
+	if err := c.pc.writePacket(nil); err != nil {
+		return err
+	}
+	if _, err := c.pc.readResult(); err != nil {
+		return err
+	}
+	return adbc.Error{
+		Msg:  "[MySQL] server requested a local file outside of bulk ingestion",
+		Code: adbc.StatusInvalidState,
+	}
+}
+
+// exec runs a query with the text protocol, discarding any rows.
+func (c *cnxn) exec(ctx context.Context, query string) (okPacket, error) {
+	defer c.pc.watch(ctx)()
+
+	if err := c.command(comQuery, []byte(query)); err != nil {
+		return okPacket{}, err
+	}
+	return c.discardResults(false)
+}
+
+// query runs a query with the text protocol, returning its result set,
+// or nil if the query didn't return rows.
+func (c *cnxn) query(ctx context.Context, query string) (okPacket, *resultSet, error) {
+	defer c.pc.watch(ctx)()
+
+	if err := c.command(comQuery, []byte(query)); err != nil {
+		return okPacket{}, nil, err
+	}
+	ok, rs, err := c.readResponse(false)
+	if err == nil && rs == nil && ok.status&statusMoreResultsExists != 0 {
+		var more okPacket
+		more, err = c.discardResults(false)
+		ok.affectedRows += more.affectedRows
+	}
+	return ok, rs, err
+}
+
+func (c *cnxn) prepare(ctx context.Context, query string) (*serverStmt, error) {
+	defer c.pc.watch(ctx)()
+
+	if err := c.command(comStmtPrepare, []byte(query)); err != nil {
+		return nil, err
+	}
+	data, err := c.pc.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[0] == iERR {
+		return nil, parseError(data)
+	}
+
+	p := payload{buf: data}
+	p.uint8() // OK
+	stmt := &serverStmt{id: p.uint32()}
+	ncols, nparams := int(p.uint16()), int(p.uint16())
+	if p.err != nil {
+		return nil, c.pc.ioError(p.err)
+	}
+
+	if stmt.params, err = c.readColumns(nparams); err != nil {
+		return nil, err
+	}
+	if stmt.cols, err = c.readColumns(ncols); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (c *cnxn) closeStmt(stmt *serverStmt) error {
+	if c.pc == nil || c.pc.broken {
+		return nil
+	}
+	// there is no response to COM_STMT_CLOSE
+	return c.command(comStmtClose, appendUint32(nil, stmt.id))
+}
+
+// execute runs a prepared statement with the binary protocol, returning
+// its result set, or nil if it didn't return rows.
+func (c *cnxn) execute(ctx context.Context, stmt *serverStmt, param paramFunc) (okPacket, *resultSet, error) {
+	pkt := appendUint32(nil, stmt.id)
+	pkt = append(pkt, 0)       // no cursor
+	pkt = appendUint32(pkt, 1) // iteration count
+
+	if n := len(stmt.params); n > 0 {
+		nulls := make([]byte, (n+7)/8)
+		types := make([]byte, 0, 2*n)
+		var values []byte
+		for i := 0; i < n; i++ {
+			typ, flags, out, err := param(values, i)
+			if err != nil {
+				return okPacket{}, nil, err
+			}
+			if typ == typeNull {
+				nulls[i/8] |= 1 << (i % 8)
+			}
+			types, values = append(types, typ, flags), out
+		}
+		pkt = append(pkt, nulls...)
+		pkt = append(pkt, 1) // new parameters bound
+		pkt = append(pkt, types...)
+		pkt = append(pkt, values...)
+	}
+
+	defer c.pc.watch(ctx)()
+	if err := c.command(comStmtExecute, pkt); err != nil {
+		return okPacket{}, nil, err
+	}
+	ok, rs, err := c.readResponse(true)
+	if err == nil && rs == nil && ok.status&statusMoreResultsExists != 0 {
+		var more okPacket
+		more, err = c.discardResults(true)
+		ok.affectedRows += more.affectedRows
+	}
+	return ok, rs, err
+}
+
+// metadataQuery runs a query with string parameters (nil for NULL),
+// returning the values of its rows as strings, with nil for NULL.
+func (c *cnxn) metadataQuery(ctx context.Context, query string, args ...interface{}) ([][]*string, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	defer c.closeStmt(stmt)
+
+	_, rs, err := c.execute(ctx, stmt, stringParams(args...))
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	return readStrings(newRecordReader(c.db.alloc, rs, nil, nil, defaultBatchSize))
+}
+
+// textQuery runs a query with the text protocol, returning the values of
+// its rows as metadataQuery does.
+func (c *cnxn) textQuery(ctx context.Context, query string) ([][]*string, error) {
+	_, rs, err := c.query(ctx, query)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	return readStrings(newRecordReader(c.db.alloc, rs, nil, nil, defaultBatchSize))
+}
+
+func readStrings(rdr array.RecordReader) ([][]*string, error) {
+	defer rdr.Release()
+
+	var rows [][]*string
+	for rdr.Next() {
+		rec := rdr.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			row := make([]*string, rec.NumCols())
+			for j, col := range rec.Columns() {
+				if col.IsNull(i) {
+					continue
+				}
+				var v string
+				switch col := col.(type) {
+				case *array.String:
+					v = col.Value(i)
+				case *array.Binary:
+					v = string(col.Value(i))
+				default:
+					v = col.ValueStr(i)
+				}
+				row[j] = &v
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, rdr.Err()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func atoi(s *string) int64 {
+	n, _ := strconv.ParseInt(deref(s), 10, 64)
+	return n
+}
+
+func (c *cnxn) vendorName() string {
+	if strings.Contains(c.serverVersion, "MariaDB") {
+		return "MariaDB"
+	}
+	return "MySQL"
+}
+
+func (c *cnxn) vendorVersion() string {
+	// MariaDB prefixes its version for the sake of old clients
+	return strings.TrimPrefix(c.serverVersion, "5.5.5-")
+}
+
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	const strValTypeID arrow.UnionTypeCode = 0
+
+	if len(infoCodes) == 0 {
+		infoCodes = infoSupportedCodes
+	}
+
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.GetInfoSchema)
+	defer bldr.Release()
+	bldr.Reserve(len(infoCodes))
+
+	infoNameBldr := bldr.Field(0).(*array.Uint32Builder)
+	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
+	strInfoBldr := infoValueBldr.Child(0).(*array.StringBuilder)
+
+	for _, code := range infoCodes {
+		switch code {
+		case adbc.InfoDriverName:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverName)
+		case adbc.InfoDriverVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverVersion)
+		case adbc.InfoDriverArrowVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(infoDriverArrowVersion)
+		case adbc.InfoVendorName:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(c.vendorName())
+		case adbc.InfoVendorVersion:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.Append(strValTypeID)
+			strInfoBldr.Append(c.vendorVersion())
+		default:
+			infoNameBldr.Append(uint32(code))
+			infoValueBldr.AppendNull()
+		}
+	}
+
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{final})
+}
+
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (array.RecordReader, error) {
+	g := internal.GetObjects{Ctx: ctx, Depth: depth, Catalog: catalog, DbSchema: dbSchema, TableName: tableName, ColumnName: columnName, TableType: tableType}
+	if err := g.Init(c.db.alloc, c.getObjectsDbSchemas, c.getObjectsTables); err != nil {
+		return nil, err
+	}
+	defer g.Release()
+
+	g.AppendCatalog(catalogName)
+	return g.Finish()
+}
+
+func likePattern(pattern *string) string {
+	if pattern == nil {
+		return "%"
+	}
+	return *pattern
+}
+
+func (c *cnxn) matchesCatalog(catalog *string) (bool, error) {
+	re, err := internal.PatternToRegexp(catalog)
+	if err != nil {
+		return false, adbc.Error{
+			Msg:  err.Error(),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return re == nil || re.MatchString(catalogName), nil
+}
+
+func (c *cnxn) getObjectsDbSchemas(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string) (result map[string][]string, err error) {
+	if depth == adbc.ObjectDepthCatalogs {
+		return
+	}
+	if ok, err := c.matchesCatalog(catalog); !ok || err != nil {
+		return nil, err
+	}
+
+	rows, err := c.metadataQuery(ctx,
+		"SELECT SCHEMA_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME LIKE ? ORDER BY SCHEMA_NAME",
+		likePattern(dbSchema))
+	if err != nil {
+		return nil, err
+	}
+
+	result = map[string][]string{catalogName: {}}
+	for _, row := range rows {
+		result[catalogName] = append(result[catalogName], deref(row[0]))
+	}
+	return
+}
+
+func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (result internal.SchemaToTableInfo, err error) {
+	if depth == adbc.ObjectDepthCatalogs || depth == adbc.ObjectDepthDBSchemas {
+		return
+	}
+	if ok, err := c.matchesCatalog(catalog); !ok || err != nil {
+		return nil, err
+	}
+
+	query := "SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM information_schema.TABLES WHERE TABLE_SCHEMA LIKE ? AND TABLE_NAME LIKE ?"
+	args := []interface{}{likePattern(dbSchema), likePattern(tableName)}
+	if len(tableType) > 0 {
+		query += " AND TABLE_TYPE IN (?" + strings.Repeat(", ?", len(tableType)-1) + ")"
+		for _, t := range tableType {
+			args = append(args, t)
+		}
+	}
+	rows, err := c.metadataQuery(ctx, query+" ORDER BY TABLE_SCHEMA, TABLE_NAME", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result = make(internal.SchemaToTableInfo)
+	tables := make(map[[2]string]*internal.TableInfo)
+	for _, row := range rows {
+		key := internal.CatalogAndSchema{Catalog: catalogName, Schema: deref(row[0])}
+		result[key] = append(result[key], internal.TableInfo{Name: deref(row[1]), TableType: deref(row[2])})
+	}
+	for key, infos := range result {
+		for i := range infos {
+			tables[[2]string{key.Schema, infos[i].Name}] = &infos[i]
+		}
+	}
+
+	if depth != adbc.ObjectDepthAll && depth != adbc.ObjectDepthColumns {
+		return
+	}
+
+	rows, err = c.metadataQuery(ctx,
+		"SELECT TABLE_SCHEMA, TABLE_NAME, "+columnsQueryFields+" FROM information_schema.COLUMNS "+
+			"WHERE TABLE_SCHEMA LIKE ? AND TABLE_NAME LIKE ? AND COLUMN_NAME LIKE ? "+
+			"ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION",
+		likePattern(dbSchema), likePattern(tableName), likePattern(columnName))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[[2]string][]arrow.Field)
+	for _, row := range rows {
+		key := [2]string{deref(row[0]), deref(row[1])}
+		fields[key] = append(fields[key], columnsRowToField(row[2:], true))
+	}
+	for key, info := range tables {
+		info.Schema = arrow.NewSchema(fields[key], nil)
+	}
+	return
+}
+
+// the fields of information_schema.COLUMNS read by columnsRowToField
+const columnsQueryFields = "COLUMN_NAME, ORDINAL_POSITION, IS_NULLABLE, DATA_TYPE, COLUMN_TYPE, NUMERIC_PRECISION, NUMERIC_SCALE, COLUMN_COMMENT"
+
+// columnsRowToField converts a row of columnsQueryFields to a field, with
+// the ordinal position in its metadata if withOrdinal is set.
+func columnsRowToField(row []*string, withOrdinal bool) arrow.Field {
+	name, ordinal, nullable, dataType, columnType := deref(row[0]), deref(row[1]), deref(row[2]), deref(row[3]), deref(row[4])
+
+	typeName := strings.ToUpper(dataType)
+	if strings.Contains(strings.ToLower(columnType), "unsigned") {
+		typeName += " UNSIGNED"
+	}
+	md := map[string]string{"TYPE_NAME": typeName}
+	if withOrdinal {
+		md["ORDINAL_POSITION"] = ordinal
+	}
+	if comment := deref(row[7]); comment != "" {
+		md["COMMENT"] = comment
+	}
+
+	return arrow.Field{
+		Name:     name,
+		Type:     typeFromColumnType(dataType, columnType, atoi(row[5]), atoi(row[6])),
+		Nullable: nullable == "YES",
+		Metadata: arrow.MetadataFrom(md),
+	}
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func qualifiedName(dbSchema *string, tableName string) string {
+	if dbSchema == nil || *dbSchema == "" {
+		return quoteIdentifier(tableName)
+	}
+	return quoteIdentifier(*dbSchema) + "." + quoteIdentifier(tableName)
+}
+
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog *string, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	if catalog != nil && *catalog != "" && *catalog != catalogName {
+		return nil, adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] catalog '%s' not found, the only catalog is '%s'", *catalog, catalogName),
+			Code: adbc.StatusNotFound,
+		}
+	}
+
+	var schemaArg interface{}
+	if dbSchema != nil && *dbSchema != "" {
+		schemaArg = *dbSchema
+	}
+	rows, err := c.metadataQuery(ctx,
+		"SELECT "+columnsQueryFields+" FROM information_schema.COLUMNS "+
+			"WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION",
+		schemaArg, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > 0 {
+		fields := make([]arrow.Field, len(rows))
+		for i, row := range rows {
+			fields[i] = columnsRowToField(row, false)
+		}
+		return arrow.NewSchema(fields, nil), nil
+	}
+
+	// temporary tables are not listed in information_schema, but
+	// preparing a query describes their columns all the same
+	stmt, err := c.prepare(ctx, "SELECT * FROM "+qualifiedName(dbSchema, tableName))
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusNotFound, err)
+	}
+	defer c.closeStmt(stmt)
+
+	fields := make([]arrow.Field, len(stmt.cols))
+	for i := range stmt.cols {
+		fields[i] = stmt.cols[i].field()
+		fields[i].Metadata = arrow.MetadataFrom(map[string]string{"TYPE_NAME": stmt.cols[i].typeName()})
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func (c *cnxn) GetTableTypes(_ context.Context) (array.RecordReader, error) {
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.TableTypesSchema)
+	defer bldr.Release()
+
+	bldr.Field(0).(*array.StringBuilder).AppendValues([]string{"BASE TABLE", "SYSTEM VIEW", "VIEW"}, nil)
+	final := bldr.NewRecord()
+	defer final.Release()
+	return array.NewRecordReader(adbc.TableTypesSchema, []arrow.Record{final})
+}
+
+func (c *cnxn) Commit(ctx context.Context) error {
+	if c.autocommit {
+		return adbc.Error{
+			Msg:  "[MySQL] cannot commit when autocommit is enabled",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	_, err := c.exec(ctx, "COMMIT")
+	return errToAdbcErr(adbc.StatusInternal, err)
+}
+
+func (c *cnxn) Rollback(ctx context.Context) error {
+	if c.autocommit {
+		return adbc.Error{
+			Msg:  "[MySQL] cannot rollback when autocommit is enabled",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	_, err := c.exec(ctx, "ROLLBACK")
+	return errToAdbcErr(adbc.StatusInternal, err)
+}
+
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	return &statement{
+		cnxn:      c,
+		alloc:     c.db.alloc,
+		batchSize: c.db.batchSize,
+	}, nil
+}
+
+func (c *cnxn) Close() error {
+	if c.pc == nil {
+		return adbc.Error{Code: adbc.StatusInvalidState}
+	}
+
+	defer func() {
+		c.pc, c.active = nil, nil
+	}()
+	if !c.pc.broken {
+		// best effort, the server closes the connection either way
+		c.command(comQuit, nil)
+	}
+	return errToAdbcErr(adbc.StatusIO, c.pc.conn.Close())
+}
+
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	return nil, adbc.Error{
+		Code: adbc.StatusNotImplemented,
+		Msg:  "[MySQL] ReadPartition not supported",
+	}
+}
+
+// isolationLevels maps the ADBC isolation levels to their SQL names. The
+// others are not supported by MySQL.
+var isolationLevels = map[string]string{
+	string(adbc.LevelReadUncommitted): "READ UNCOMMITTED",
+	string(adbc.LevelReadCommitted):   "READ COMMITTED",
+	string(adbc.LevelRepeatableRead):  "REPEATABLE READ",
+	string(adbc.LevelSerializable):    "SERIALIZABLE",
+}
+
+// SetOption sets the session characteristics of the connection. As in
+// SQL, the isolation level and read only mode apply from the next
+// transaction on.
+func (c *cnxn) SetOption(key, value string) error {
+	ctx := context.Background()
+	switch key {
+	case adbc.OptionKeyAutoCommit:
+		var enable bool
+		switch value {
+		case adbc.OptionValueEnabled:
+			enable = true
+		case adbc.OptionValueDisabled:
+		default:
+			return adbc.Error{
+				Msg:  "[MySQL] invalid value for option " + key + ": " + value,
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+
+		// enabling autocommit commits the current transaction
+		query := "SET autocommit = 0"
+		if enable {
+			query = "SET autocommit = 1"
+		}
+		if _, err := c.exec(ctx, query); err != nil {
+			return errToAdbcErr(adbc.StatusInternal, err)
+		}
+		c.autocommit = enable
+		return nil
+	case adbc.OptionKeyIsolationLevel:
+		var query string
+		switch value {
+		case string(adbc.LevelDefault):
+			// back to the server's default
+			variable := "transaction_isolation"
+			if c.vendorName() == "MariaDB" {
+				variable = "tx_isolation"
+			}
+			query = fmt.Sprintf("SET SESSION %s = @@GLOBAL.%s", variable, variable)
+		case string(adbc.LevelSnapshot), string(adbc.LevelLinearizable):
+			return adbc.Error{
+				Msg:  "[MySQL] isolation level " + value + " is not supported",
+				Code: adbc.StatusNotImplemented,
+			}
+		default:
+			level, ok := isolationLevels[value]
+			if !ok {
+				return adbc.Error{
+					Msg:  "[MySQL] invalid value for option " + key + ": " + value,
+					Code: adbc.StatusInvalidArgument,
+				}
+			}
+			query = "SET SESSION TRANSACTION ISOLATION LEVEL " + level
+		}
+		_, err := c.exec(ctx, query)
+		return errToAdbcErr(adbc.StatusInternal, err)
+	case adbc.OptionKeyReadOnly:
+		var query string
+		switch value {
+		case adbc.OptionValueEnabled:
+			query = "SET SESSION TRANSACTION READ ONLY"
+		case adbc.OptionValueDisabled:
+			query = "SET SESSION TRANSACTION READ WRITE"
+		default:
+			return adbc.Error{
+				Msg:  "[MySQL] invalid value for option " + key + ": " + value,
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		_, err := c.exec(ctx, query)
+		return errToAdbcErr(adbc.StatusInternal, err)
+	default:
+		return adbc.Error{
+			Msg:  "[MySQL] unknown connection option " + key + ": " + value,
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*cnxn)(nil)
+)
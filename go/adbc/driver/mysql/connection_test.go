@@ -0,0 +1,390 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openFake(t *testing.T, srv *fakeServer, mem memory.Allocator, opts map[string]string) adbc.Connection {
+	dbOpts := map[string]string{adbc.OptionKeyURI: srv.uri()}
+	for k, v := range opts {
+		dbOpts[k] = v
+	}
+	db, err := Driver{Alloc: mem}.NewDatabase(dbOpts)
+	require.NoError(t, err)
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+	return cnxn
+}
+
+func requireStatus(t *testing.T, err error, code adbc.Status) adbc.Error {
+	var adbcErr adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	require.Equal(t, code, adbcErr.Code, adbcErr.Msg)
+	return adbcErr
+}
+
+// typesResult has a column of each kind of type with two rows of values
+// and a row of NULLs
+func typesResult() fakeResult {
+	utf8 := uint16(collationUTF8MB4)
+	return fakeResult{
+		cols: []column{
+			intCol("i", typeLongLong, false),
+			intCol("u", typeLongLong, true),
+			{name: "b", typ: typeTiny, length: 1, charset: charsetBinary},
+			{name: "f", typ: typeDouble, length: 22, charset: charsetBinary},
+			{name: "d", typ: typeNewDecimal, length: 12, decimals: 2, charset: charsetBinary},
+			{name: "s", typ: typeVarString, length: 1020, charset: utf8},
+			{name: "bin", typ: typeBlob, charset: charsetBinary},
+			{name: "dt", typ: typeDate, charset: charsetBinary},
+			{name: "ts", typ: typeDatetime, decimals: 6, charset: charsetBinary},
+			{name: "tm", typ: typeTime, charset: charsetBinary},
+		},
+		rows: [][]interface{}{
+			{int64(-1), uint64(1 << 63), true, 1.5, "12.34", "héllo", []byte{0, 1}, time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 5, 17, 10, 20, 30, 123456000, time.UTC), -90 * time.Minute},
+			{int64(2), uint64(3), false, -2.25, "-0.50", "", []byte{}, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC), 30 * time.Hour},
+			{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		},
+	}
+}
+
+const typesJSON = `[
+	{"i": -1, "u": 9223372036854775808, "b": true, "f": 1.5, "d": "12.34", "s": "héllo", "bin": "AAE=", "dt": "2023-05-17", "ts": "2023-05-17T10:20:30.123456", "tm": -5400000000},
+	{"i": 2, "u": 3, "b": false, "f": -2.25, "d": "-0.50", "s": "", "bin": "", "dt": "1969-12-31", "ts": "1999-12-31T23:59:59", "tm": 108000000000},
+	{"i": null, "u": null, "b": null, "f": null, "d": null, "s": null, "bin": null, "dt": null, "ts": null, "tm": null}
+]`
+
+func readAll(t *testing.T, rdr array.RecordReader) (recs []arrow.Record) {
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	require.NoError(t, rdr.Err())
+	return
+}
+
+func TestFakeQueryTypes(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	srv := newFakeServer(t, func(query string, params []interface{}) fakeResult { return typesResult() })
+	cnxn := openFake(t, srv, mem, map[string]string{OptionBatchSize: "2"})
+	defer cnxn.Close()
+
+	for _, prepared := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prepared=%v", prepared), func(t *testing.T) {
+			stmt, err := cnxn.NewStatement()
+			require.NoError(t, err)
+			defer stmt.Close()
+
+			require.NoError(t, stmt.SetSqlQuery("SELECT * FROM types"))
+			if prepared {
+				require.NoError(t, stmt.Prepare(context.Background()))
+			}
+			rdr, n, err := stmt.ExecuteQuery(context.Background())
+			require.NoError(t, err)
+			defer rdr.Release()
+			assert.EqualValues(t, -1, n)
+
+			recs := readAll(t, rdr)
+			defer func() {
+				for _, r := range recs {
+					r.Release()
+				}
+			}()
+			require.Len(t, recs, 2)
+			assert.EqualValues(t, 2, recs[0].NumRows())
+
+			expected, _, err := array.RecordFromJSON(mem, rdr.Schema(), strings.NewReader(typesJSON))
+			require.NoError(t, err)
+			defer expected.Release()
+
+			for i, rec := range recs {
+				slice := expected.NewSlice(int64(2*i), int64(2*i)+rec.NumRows())
+				assert.Truef(t, array.RecordEqual(slice, rec), "expected: %s\ngot: %s", slice, rec)
+				slice.Release()
+			}
+		})
+	}
+
+	queries := srv.loggedQueries()
+	assert.Equal(t, "SELECT * FROM types", queries[0])
+	assert.Equal(t, "PREPARE SELECT * FROM types", queries[1])
+}
+
+func TestFakeAuthentication(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct{ plugin, switchTo string }{
+		{pluginNativePassword, ""},
+		{pluginCachingSHA2, ""},
+		{pluginCachingSHA2, pluginNativePassword},
+	} {
+		srv := newFakeServer(t, func(string, []interface{}) fakeResult { return fakeResult{} })
+		srv.plugin, srv.switchTo = tt.plugin, tt.switchTo
+
+		db, err := Driver{}.NewDatabase(map[string]string{adbc.OptionKeyURI: srv.uri()})
+		require.NoError(t, err)
+		cnxn, err := db.Open(ctx)
+		require.NoError(t, err, tt.plugin)
+		require.NoError(t, cnxn.Close())
+
+		require.NoError(t, db.SetOptions(map[string]string{adbc.OptionKeyPassword: "wrong"}))
+		_, err = db.Open(ctx)
+		adbcErr := requireStatus(t, err, adbc.StatusUnauthenticated)
+		assert.EqualValues(t, 1045, adbcErr.VendorCode)
+	}
+}
+
+func TestFakeBindAndParameterSchema(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	srv := newFakeServer(t, func(query string, params []interface{}) fakeResult {
+		if strings.HasPrefix(query, "INSERT") {
+			return fakeResult{affected: 1}
+		}
+		res := fakeResult{cols: []column{intCol("v", typeLongLong, false), {name: "s", typ: typeVarString, charset: collationUTF8MB4}}}
+		if params != nil {
+			res.rows = [][]interface{}{{params[0], params[1]}}
+		}
+		return res
+	})
+	cnxn := openFake(t, srv, mem, nil)
+	defer cnxn.Close()
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery("SELECT ?, ?"))
+	_, err = stmt.GetParameterSchema()
+	requireStatus(t, err, adbc.StatusInvalidState)
+	require.NoError(t, stmt.Prepare(ctx))
+	paramSchema, err := stmt.GetParameterSchema()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(paramSchema.Fields()))
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "a", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "b", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	params, _, err := array.RecordFromJSON(mem, schema, strings.NewReader(`[{"a": 1, "b": "x"}, {"a": null, "b": "y"}, {"a": 3, "b": null}]`))
+	require.NoError(t, err)
+	defer params.Release()
+
+	require.NoError(t, stmt.Bind(ctx, params))
+	rdr, _, err := stmt.ExecuteQuery(ctx)
+	require.NoError(t, err)
+	recs := readAll(t, rdr)
+	rdr.Release()
+	require.Len(t, recs, 1)
+	assert.EqualValues(t, 3, recs[0].NumRows())
+	assert.Equal(t, `[1 (null) 3]`, recs[0].Column(0).String())
+	assert.Equal(t, `["x" "y" (null)]`, recs[0].Column(1).String())
+	recs[0].Release()
+
+	// a bound stream is executed once per row across its batches
+	require.NoError(t, stmt.SetSqlQuery("INSERT INTO t VALUES (?, ?)"))
+	stream, err := array.NewRecordReader(schema, []arrow.Record{params, params})
+	require.NoError(t, err)
+	require.NoError(t, stmt.BindStream(ctx, stream))
+	stream.Release()
+	n, err := stmt.ExecuteUpdate(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, n)
+
+	executes := 0
+	for _, q := range srv.loggedQueries() {
+		if strings.HasPrefix(q, "EXECUTE INSERT") {
+			executes++
+		}
+	}
+	assert.Equal(t, 6, executes)
+
+	// the number of bound columns must match the parameters
+	require.NoError(t, stmt.SetSqlQuery("SELECT ?"))
+	require.NoError(t, stmt.Bind(ctx, params))
+	_, err = stmt.ExecuteUpdate(ctx)
+	requireStatus(t, err, adbc.StatusInvalidArgument)
+}
+
+func TestFakeIngest(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	srv := newFakeServer(t, func(string, []interface{}) fakeResult { return fakeResult{} })
+	cnxn := openFake(t, srv, mem, nil)
+	defer cnxn.Close()
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "ints", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "strs", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "dec", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+	}, nil)
+	rec, _, err := array.RecordFromJSON(mem, schema, strings.NewReader(`[
+		{"ints": 1, "strs": "a\tb", "ts": "2023-05-17T10:20:30.5Z", "dec": "1.25"},
+		{"ints": 2, "strs": null, "ts": null, "dec": null},
+		{"ints": 3, "strs": "back\\slash\n", "ts": "1970-01-01T00:00:00Z", "dec": "-3.00"}
+	]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestTargetTable, "my`table"))
+	require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestTargetDBSchema, "other"))
+	require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeReplace))
+	requireStatus(t, stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeMerge), adbc.StatusNotImplemented)
+	require.NoError(t, stmt.Bind(ctx, rec))
+	n, err := stmt.ExecuteUpdate(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+
+	queries := srv.loggedQueries()
+	require.Len(t, queries, 3)
+	assert.Equal(t, "DROP TABLE IF EXISTS `other`.`my``table`", queries[0])
+	assert.Equal(t, "CREATE TABLE `other`.`my``table` (`ints` BIGINT NOT NULL, `strs` LONGTEXT, `ts` DATETIME(3), `dec` DECIMAL(10,2))", queries[1])
+	assert.True(t, strings.HasPrefix(queries[2], "LOAD DATA LOCAL INFILE 'adbc_ingest' INTO TABLE `other`.`my``table`"), queries[2])
+	assert.True(t, strings.HasSuffix(queries[2], "(`ints`, `strs`, `ts`, `dec`)"), queries[2])
+
+	assert.Equal(t, "1\ta\\tb\t2023-05-17 10:20:30.5\t1.25\n"+
+		"2\t\\N\t\\N\t\\N\n"+
+		"3\tback\\\\slash\\n\t1970-01-01 00:00:00\t-3.00\n", string(srv.infile))
+}
+
+func TestFakeConnectionOptions(t *testing.T) {
+	srv := newFakeServer(t, func(string, []interface{}) fakeResult { return fakeResult{} })
+	cnxn := openFake(t, srv, memory.DefaultAllocator, nil)
+	defer cnxn.Close()
+	ctx := context.Background()
+
+	requireStatus(t, cnxn.Commit(ctx), adbc.StatusInvalidState)
+
+	opts := cnxn.(adbc.PostInitOptions)
+	require.NoError(t, opts.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+	require.NoError(t, opts.SetOption(adbc.OptionKeyIsolationLevel, string(adbc.LevelSerializable)))
+	require.NoError(t, opts.SetOption(adbc.OptionKeyIsolationLevel, string(adbc.LevelDefault)))
+	require.NoError(t, opts.SetOption(adbc.OptionKeyReadOnly, adbc.OptionValueEnabled))
+	require.NoError(t, cnxn.Commit(ctx))
+	require.NoError(t, cnxn.Rollback(ctx))
+	requireStatus(t, opts.SetOption(adbc.OptionKeyIsolationLevel, string(adbc.LevelSnapshot)), adbc.StatusNotImplemented)
+	requireStatus(t, opts.SetOption(adbc.OptionKeyReadOnly, "maybe"), adbc.StatusInvalidArgument)
+
+	assert.Equal(t, []string{
+		"SET autocommit = 0",
+		"SET SESSION TRANSACTION ISOLATION LEVEL SERIALIZABLE",
+		"SET SESSION transaction_isolation = @@GLOBAL.transaction_isolation",
+		"SET SESSION TRANSACTION READ ONLY",
+		"COMMIT",
+		"ROLLBACK",
+	}, srv.loggedQueries())
+}
+
+func TestFakeErrors(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	rows := make([][]interface{}, 10)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i)}
+	}
+	srv := newFakeServer(t, func(query string, _ []interface{}) fakeResult {
+		switch query {
+		case "SELECT * FROM missing":
+			return fakeResult{err: &serverError{code: 1146, sqlState: "42S02", msg: "Table 'testdb.missing' doesn't exist"}}
+		case "SELECT * FROM many":
+			return fakeResult{cols: []column{intCol("i", typeLongLong, false)}, rows: rows}
+		}
+		return fakeResult{cols: []column{intCol("one", typeLongLong, false)}, rows: [][]interface{}{{int64(1)}}}
+	})
+	cnxn := openFake(t, srv, mem, map[string]string{OptionBatchSize: "1"})
+	defer cnxn.Close()
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery("SELECT * FROM missing"))
+	_, _, err = stmt.ExecuteQuery(ctx)
+	adbcErr := requireStatus(t, err, adbc.StatusNotFound)
+	assert.EqualValues(t, 1146, adbcErr.VendorCode)
+	assert.Equal(t, "42S02", string(adbcErr.SqlState[:]))
+
+	// a query on the connection discards the rest of an unfinished result set
+	require.NoError(t, stmt.SetSqlQuery("SELECT * FROM many"))
+	rdr, _, err := stmt.ExecuteQuery(ctx)
+	require.NoError(t, err)
+	defer rdr.Release()
+	require.True(t, rdr.Next())
+
+	other, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer other.Close()
+	require.NoError(t, other.SetSqlQuery("SELECT 1"))
+	rdr2, _, err := other.ExecuteQuery(ctx)
+	require.NoError(t, err)
+	recs := readAll(t, rdr2)
+	rdr2.Release()
+	require.Len(t, recs, 1)
+	recs[0].Release()
+
+	assert.False(t, rdr.Next())
+	requireStatus(t, rdr.Err(), adbc.StatusInvalidState)
+
+	// the server may only ask for the file being ingested
+	require.NoError(t, stmt.SetSqlQuery("SELECT evil"))
+	_, err = stmt.ExecuteUpdate(ctx)
+	requireStatus(t, err, adbc.StatusInvalidState)
+	assert.Empty(t, srv.infile)
+}
+
+func TestFakeGetInfo(t *testing.T) {
+	srv := newFakeServer(t, func(string, []interface{}) fakeResult { return fakeResult{} })
+	cnxn := openFake(t, srv, memory.DefaultAllocator, nil)
+	defer cnxn.Close()
+
+	rdr, err := cnxn.GetInfo(context.Background(), []adbc.InfoCode{adbc.InfoVendorName, adbc.InfoVendorVersion})
+	require.NoError(t, err)
+	defer rdr.Release()
+	require.True(t, rdr.Next())
+	values := rdr.Record().Column(1).(*array.DenseUnion).Field(0).(*array.String)
+	assert.Equal(t, "MySQL", values.Value(0))
+	assert.Equal(t, "8.0.33-fake", values.Value(1))
+}
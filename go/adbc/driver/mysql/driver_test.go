@@ -0,0 +1,351 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql_test
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	driver "github.com/apache/arrow-adbc/go/adbc/driver/mysql"
+	"github.com/apache/arrow-adbc/go/adbc/validation"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type MySQLQuirks struct {
+	uri    string
+	dbName string
+	mem    *memory.CheckedAllocator
+}
+
+func (s *MySQLQuirks) SetupDriver(t *testing.T) adbc.Driver {
+	s.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	return driver.Driver{Alloc: s.mem}
+}
+
+func (s *MySQLQuirks) TearDownDriver(t *testing.T, _ adbc.Driver) {
+	s.mem.AssertSize(t, 0)
+}
+
+func (s *MySQLQuirks) DatabaseOptions() map[string]string {
+	return map[string]string{adbc.OptionKeyURI: s.uri}
+}
+
+func (s *MySQLQuirks) getSqlTypeFromArrowType(dt arrow.DataType) string {
+	switch dt.ID() {
+	case arrow.INT8:
+		return "TINYINT"
+	case arrow.INT16:
+		return "SMALLINT"
+	case arrow.INT32:
+		return "INT"
+	case arrow.INT64:
+		return "BIGINT"
+	case arrow.FLOAT32:
+		return "FLOAT"
+	case arrow.FLOAT64:
+		return "DOUBLE"
+	case arrow.STRING:
+		return "TEXT"
+	default:
+		return ""
+	}
+}
+
+// exec runs a query with the driver itself, binding r if it isn't nil
+func (s *MySQLQuirks) exec(query string, r arrow.Record) error {
+	db, err := driver.Driver{Alloc: s.mem}.NewDatabase(s.DatabaseOptions())
+	if err != nil {
+		return err
+	}
+	cnxn, err := db.Open(context.Background())
+	if err != nil {
+		return err
+	}
+	defer cnxn.Close()
+
+	stmt, err := cnxn.NewStatement()
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if err = stmt.SetSqlQuery(query); err != nil {
+		return err
+	}
+	if r != nil {
+		if err = stmt.Bind(context.Background(), r); err != nil {
+			return err
+		}
+	}
+	_, err = stmt.ExecuteUpdate(context.Background())
+	return err
+}
+
+func (s *MySQLQuirks) CreateSampleTable(tableName string, r arrow.Record) error {
+	var b strings.Builder
+	b.WriteString("CREATE TABLE `")
+	b.WriteString(tableName)
+	b.WriteString("` (")
+
+	for i := 0; i < int(r.NumCols()); i++ {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		f := r.Schema().Field(i)
+		b.WriteString(f.Name)
+		b.WriteByte(' ')
+		b.WriteString(s.getSqlTypeFromArrowType(f.Type))
+	}
+	b.WriteString(")")
+
+	if err := s.exec(b.String(), nil); err != nil {
+		return err
+	}
+
+	bindings := strings.Repeat("?,", int(r.NumCols()))
+	return s.exec("INSERT INTO `"+tableName+"` VALUES ("+bindings[:len(bindings)-1]+")", r)
+}
+
+func (s *MySQLQuirks) DropTable(cnxn adbc.Connection, tblname string) error {
+	stmt, err := cnxn.NewStatement()
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if err = stmt.SetSqlQuery("DROP TABLE IF EXISTS `" + tblname + "`"); err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecuteUpdate(context.Background())
+	return err
+}
+
+func (s *MySQLQuirks) Alloc() memory.Allocator               { return s.mem }
+func (s *MySQLQuirks) BindParameter(_ int) string            { return "?" }
+func (s *MySQLQuirks) SupportsConcurrentStatements() bool    { return false }
+func (s *MySQLQuirks) SupportsPartitionedData() bool         { return false }
+func (s *MySQLQuirks) SupportsTransactions() bool            { return true }
+func (s *MySQLQuirks) SupportsGetParameterSchema() bool      { return true }
+func (s *MySQLQuirks) SupportsDynamicParameterBinding() bool { return false }
+
+// SupportsBulkIngest is false since the validation ingest tests quote
+// identifiers with double quotes and sort with NULLS LAST, neither of
+// which MySQL accepts. Ingestion is covered by MySQLIngestTests.
+func (s *MySQLQuirks) SupportsBulkIngest() bool { return false }
+func (s *MySQLQuirks) DBSchema() string         { return s.dbName }
+func (s *MySQLQuirks) GetMetadata(code adbc.InfoCode) interface{} {
+	switch code {
+	case adbc.InfoDriverName:
+		return "ADBC MySQL Driver - Go"
+	// runtime/debug.ReadBuildInfo doesn't currently work for tests
+	// github.com/golang/go/issues/33976
+	case adbc.InfoDriverVersion:
+		return "(unknown or development build)"
+	case adbc.InfoDriverArrowVersion:
+		return "(unknown or development build)"
+	case adbc.InfoVendorName:
+		return "MySQL"
+	}
+
+	return nil
+}
+
+func (s *MySQLQuirks) SampleTableSchemaMetadata(tblName string, dt arrow.DataType) arrow.Metadata {
+	switch dt.ID() {
+	case arrow.STRING:
+		return arrow.MetadataFrom(map[string]string{"TYPE_NAME": "TEXT"})
+	case arrow.INT64:
+		return arrow.MetadataFrom(map[string]string{"TYPE_NAME": "BIGINT"})
+	}
+	return arrow.Metadata{}
+}
+
+func TestADBCMySQL(t *testing.T) {
+	uri := os.Getenv("MYSQL_URI")
+
+	if uri == "" {
+		t.Skip("no MYSQL_URI defined, skip mysql driver tests")
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// avoid multiple runs clashing by operating in a fresh database and
+	// then dropping that database when we're done.
+	q := &MySQLQuirks{uri: uri, mem: memory.NewCheckedAllocator(memory.DefaultAllocator)}
+	q.dbName = "adbc_testing_" + strings.ReplaceAll(uuid.New().String(), "-", "_")
+	if err := q.exec("CREATE DATABASE `"+q.dbName+"`", nil); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := q.exec("DROP DATABASE `"+q.dbName+"`", nil); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	u.Path = "/" + q.dbName
+	q.uri = u.String()
+
+	suite.Run(t, &validation.DatabaseTests{Quirks: q})
+	suite.Run(t, &validation.ConnectionTests{Quirks: q})
+	suite.Run(t, &validation.StatementTests{Quirks: q})
+	suite.Run(t, &MySQLIngestTests{Quirks: q})
+}
+
+// MySQLIngestTests checks bulk ingestion with queries in MySQL's own
+// dialect, in place of the validation ingest tests.
+type MySQLIngestTests struct {
+	suite.Suite
+
+	Quirks *MySQLQuirks
+
+	ctx  context.Context
+	db   adbc.Database
+	cnxn adbc.Connection
+}
+
+func (s *MySQLIngestTests) SetupTest() {
+	s.ctx = context.Background()
+
+	var err error
+	s.db, err = driver.Driver{Alloc: s.Quirks.mem}.NewDatabase(s.Quirks.DatabaseOptions())
+	s.Require().NoError(err)
+	s.cnxn, err = s.db.Open(s.ctx)
+	s.Require().NoError(err)
+	s.Require().NoError(s.Quirks.DropTable(s.cnxn, "bulk_ingest"))
+}
+
+func (s *MySQLIngestTests) TearDownTest() {
+	s.NoError(s.Quirks.DropTable(s.cnxn, "bulk_ingest"))
+	s.NoError(s.cnxn.Close())
+	s.Quirks.mem.AssertSize(s.T(), 0)
+}
+
+func (s *MySQLIngestTests) ints(vals ...interface{}) arrow.Record {
+	bldr := array.NewRecordBuilder(s.Quirks.mem, arrow.NewSchema([]arrow.Field{
+		{Name: "int64s", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil))
+	defer bldr.Release()
+	for _, v := range vals {
+		if v == nil {
+			bldr.Field(0).AppendNull()
+		} else {
+			bldr.Field(0).(*array.Int64Builder).Append(int64(v.(int)))
+		}
+	}
+	return bldr.NewRecord()
+}
+
+// ingest binds the values to a new statement ingesting into target
+func (s *MySQLIngestTests) ingest(mode, target string, vals ...interface{}) error {
+	rec := s.ints(vals...)
+	defer rec.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, target))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestMode, mode))
+	s.Require().NoError(stmt.Bind(s.ctx, rec))
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	return err
+}
+
+// check reads back the table, nulls sorting last as with NULLS LAST
+func (s *MySQLIngestTests) check(table string, vals ...interface{}) {
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetSqlQuery("SELECT `int64s` FROM " + table +
+		" ORDER BY `int64s` IS NULL, `int64s` DESC"))
+	rdr, _, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	expected := s.ints(vals...)
+	defer expected.Release()
+
+	var got []arrow.Array
+	for rdr.Next() {
+		col := rdr.Record().Column(0)
+		col.Retain()
+		defer col.Release()
+		got = append(got, col)
+	}
+	s.Require().NoError(rdr.Err())
+	actual, err := array.Concatenate(got, s.Quirks.mem)
+	s.Require().NoError(err)
+	defer actual.Release()
+	s.Truef(array.Equal(expected.Column(0), actual), "expected: %s\ngot: %s", expected.Column(0), actual)
+}
+
+func (s *MySQLIngestTests) TestCreate() {
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeCreate, "bulk_ingest", 42, -42, nil))
+	s.check("`bulk_ingest`", 42, -42, nil)
+
+	// the table already exists
+	s.Error(s.ingest(adbc.OptionValueIngestModeCreate, "bulk_ingest", 1))
+}
+
+func (s *MySQLIngestTests) TestAppend() {
+	s.Error(s.ingest(adbc.OptionValueIngestModeAppend, "bulk_ingest", 1), "the table doesn't exist")
+
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeCreate, "bulk_ingest", 42))
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeAppend, "bulk_ingest", -42, nil))
+	s.check("`bulk_ingest`", 42, -42, nil)
+}
+
+func (s *MySQLIngestTests) TestReplace() {
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeReplace, "bulk_ingest", 42))
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeReplace, "bulk_ingest", -42))
+	s.check("`bulk_ingest`", -42)
+}
+
+func (s *MySQLIngestTests) TestCreateAppend() {
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeCreateAppend, "bulk_ingest", 42))
+	s.Require().NoError(s.ingest(adbc.OptionValueIngestModeCreateAppend, "bulk_ingest", nil, -42))
+	s.check("`bulk_ingest`", 42, -42, nil)
+}
+
+func (s *MySQLIngestTests) TestTargetSchema() {
+	rec := s.ints(1, nil)
+	defer rec.Release()
+
+	stmt, err := s.cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, "bulk_ingest"))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetDBSchema, s.Quirks.DBSchema()))
+	s.Require().NoError(stmt.Bind(s.ctx, rec))
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	s.check("`"+s.Quirks.DBSchema()+"`.`bulk_ingest`", 1, nil)
+}
@@ -0,0 +1,420 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+)
+
+const (
+	// utf8mb4_general_ci, understood by both MySQL and MariaDB
+	collationUTF8MB4 = 45
+
+	pluginNativePassword  = "mysql_native_password"
+	pluginCachingSHA2     = "caching_sha2_password"
+	pluginSHA256Password  = "sha256_password"
+	pluginClearPassword   = "mysql_clear_password"
+	cachingSHA2FastAuthOK = 3
+	cachingSHA2FullAuth   = 4
+	cachingSHA2PublicKey  = 2
+	sha256PublicKey       = 1
+	authMoreData          = 0x01
+
+	clientCaps = clientLongPassword | clientLongFlag | clientProtocol41 |
+		clientTransactions | clientSecureConn | clientMultiResults |
+		clientPSMultiResults | clientPluginAuth |
+		clientPluginAuthLenEncClientData | clientDeprecateEOF |
+		clientLocalFiles
+)
+
+// handshake is the initial packet sent by the server (protocol version 10)
+type handshake struct {
+	serverVersion string
+	connID        uint32
+	caps          uint32
+	status        uint16
+	scramble      []byte
+	plugin        string
+}
+
+func parseHandshake(data []byte) (*handshake, error) {
+	if len(data) > 0 && data[0] == iERR {
+		return nil, parseError(data)
+	}
+
+	p := payload{buf: data}
+	if v := p.uint8(); v != 10 {
+		return nil, adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] unsupported protocol version %d", v),
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	hs := &handshake{serverVersion: p.nulString(), connID: p.uint32()}
+	scramble := append([]byte{}, p.next(8)...)
+	p.next(1) // filler
+	hs.caps = uint32(p.uint16())
+	if len(p.buf) > 0 {
+		p.uint8() // default collation
+		hs.status = p.uint16()
+		hs.caps |= uint32(p.uint16()) << 16
+		authLen := int(p.uint8())
+		p.next(10) // reserved
+		if hs.caps&clientSecureConn != 0 {
+			n := authLen - 8
+			if n < 13 {
+				n = 13
+			}
+			// the second part of the scramble is NUL terminated
+			scramble = append(scramble, bytes.TrimRight(p.next(n), "\x00")...)
+		}
+		if hs.caps&clientPluginAuth != 0 {
+			hs.plugin = p.nulString()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	hs.scramble = scramble
+	if hs.plugin == "" {
+		hs.plugin = pluginNativePassword
+	}
+	return hs, nil
+}
+
+// connect dials the server, negotiates TLS and authenticates.
+func connect(ctx context.Context, cfg *config) (*packetConn, *handshake, error) {
+	dialer := net.Dialer{Timeout: cfg.dialTimeout}
+	netConn, err := dialer.DialContext(ctx, cfg.network, cfg.addr)
+	if err != nil {
+		return nil, nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	pc := newPacketConn(netConn)
+	hs, err := pc.handshake(ctx, cfg)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	return pc, hs, nil
+}
+
+func (p *packetConn) handshake(ctx context.Context, cfg *config) (*handshake, error) {
+	defer p.watch(ctx)()
+
+	data, err := p.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	hs, err := parseHandshake(data)
+	if err != nil {
+		return nil, err
+	}
+	if hs.caps&clientProtocol41 == 0 || hs.caps&clientSecureConn == 0 {
+		return nil, adbc.Error{
+			Msg:  "[MySQL] server " + hs.serverVersion + " is too old, protocol 4.1 authentication is required",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	caps := clientCaps & hs.caps
+	if cfg.dbname != "" {
+		caps |= clientConnectWithDB
+	}
+
+	secure := cfg.network == "unix"
+	if cfg.tlsConfig != nil {
+		switch {
+		case hs.caps&clientSSL != 0:
+			caps |= clientSSL
+			if err := p.startTLS(caps, cfg.tlsConfig); err != nil {
+				return nil, err
+			}
+			secure = true
+		case cfg.tlsMode != OptionValueTLSPreferred:
+			return nil, adbc.Error{
+				Msg:  "[MySQL] TLS was requested but the server does not support it",
+				Code: adbc.StatusIO,
+			}
+		}
+	}
+	hs.caps = caps
+
+	auth := authenticator{cfg: cfg, secure: secure, plugin: hs.plugin, scramble: hs.scramble}
+	resp, err := auth.response()
+	if err != nil {
+		return nil, err
+	}
+
+	pkt := appendUint32(nil, caps)
+	pkt = appendUint32(pkt, maxPacketSize)
+	pkt = append(pkt, collationUTF8MB4)
+	pkt = append(pkt, make([]byte, 23)...)
+	pkt = append(append(pkt, cfg.user...), 0)
+	pkt = appendLenEncString(pkt, string(resp))
+	if caps&clientConnectWithDB != 0 {
+		pkt = append(append(pkt, cfg.dbname...), 0)
+	}
+	pkt = append(append(pkt, auth.plugin...), 0)
+	if err := p.writePacket(pkt); err != nil {
+		return nil, err
+	}
+
+	return hs, auth.finish(p)
+}
+
+func (p *packetConn) startTLS(caps uint32, tlsConfig *tls.Config) error {
+	pkt := appendUint32(nil, caps)
+	pkt = appendUint32(pkt, maxPacketSize)
+	pkt = append(pkt, collationUTF8MB4)
+	pkt = append(pkt, make([]byte, 23)...)
+	if err := p.writePacket(pkt); err != nil {
+		return err
+	}
+
+	conn := tls.Client(p.conn, tlsConfig)
+	if err := conn.Handshake(); err != nil {
+		return adbc.Error{
+			Msg:  "[MySQL] TLS handshake failed: " + err.Error(),
+			Code: adbc.StatusIO,
+		}
+	}
+	p.conn = conn
+	p.rd.Reset(conn)
+	return nil
+}
+
+// authenticator computes the responses of an authentication plugin,
+// following the server when it switches to another plugin.
+type authenticator struct {
+	cfg      *config
+	secure   bool
+	plugin   string
+	scramble []byte
+}
+
+func (a *authenticator) response() ([]byte, error) {
+	password := a.cfg.password
+	switch a.plugin {
+	case pluginNativePassword:
+		if password == "" {
+			return nil, nil
+		}
+		return scrambleNativePassword(a.scramble, password), nil
+	case pluginCachingSHA2:
+		if password == "" {
+			return nil, nil
+		}
+		return scrambleSHA256Password(a.scramble, password), nil
+	case pluginSHA256Password:
+		switch {
+		case password == "":
+			return []byte{0}, nil
+		case a.secure:
+			return append([]byte(password), 0), nil
+		case a.cfg.allowPublicKeyRetrieval:
+			return []byte{sha256PublicKey}, nil
+		}
+		return nil, a.insecure()
+	case pluginClearPassword:
+		if !a.secure {
+			return nil, a.insecure()
+		}
+		return append([]byte(password), 0), nil
+	}
+
+	return nil, adbc.Error{
+		Msg:  "[MySQL] unsupported authentication plugin " + a.plugin,
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+func (a *authenticator) insecure() error {
+	return adbc.Error{
+		Msg: fmt.Sprintf("[MySQL] authentication plugin %s requires a secure connection, use TLS or enable %s",
+			a.plugin, OptionAllowPublicKeyRetrieval),
+		Code: adbc.StatusUnauthenticated,
+	}
+}
+
+func (a *authenticator) finish(p *packetConn) error {
+	for {
+		data, err := p.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return p.ioError(errMalformedPacket)
+		}
+
+		switch data[0] {
+		case iOK:
+			return nil
+		case iERR:
+			return parseError(data)
+		case iEOF:
+			// auth switch request
+			pl := payload{buf: data[1:]}
+			a.plugin = pl.nulString()
+			a.scramble = bytes.TrimRight(pl.rest(), "\x00")
+			resp, err := a.response()
+			if err != nil {
+				return err
+			}
+			if err := p.writePacket(resp); err != nil {
+				return err
+			}
+		case authMoreData:
+			resp, err := a.moreData(p, data[1:])
+			if err != nil {
+				return err
+			}
+			if resp != nil {
+				if err := p.writePacket(resp); err != nil {
+					return err
+				}
+			}
+		default:
+			return p.ioError(fmt.Errorf("unexpected packet 0x%02x during authentication", data[0]))
+		}
+	}
+}
+
+func (a *authenticator) moreData(p *packetConn, data []byte) ([]byte, error) {
+	password := a.cfg.password
+	switch a.plugin {
+	case pluginCachingSHA2:
+		if len(data) != 1 {
+			break
+		}
+		switch data[0] {
+		case cachingSHA2FastAuthOK:
+			// followed by OK
+			return nil, nil
+		case cachingSHA2FullAuth:
+			if a.secure {
+				return append([]byte(password), 0), nil
+			}
+			if !a.cfg.allowPublicKeyRetrieval {
+				return nil, a.insecure()
+			}
+			if err := p.writePacket([]byte{cachingSHA2PublicKey}); err != nil {
+				return nil, err
+			}
+			key, err := p.readPacket()
+			if err != nil {
+				return nil, err
+			}
+			if len(key) == 0 || key[0] != authMoreData {
+				return nil, p.ioError(errMalformedPacket)
+			}
+			return a.encryptPassword(key[1:])
+		}
+	case pluginSHA256Password:
+		return a.encryptPassword(data)
+	}
+
+	return nil, p.ioError(fmt.Errorf("unexpected authentication data for plugin %s", a.plugin))
+}
+
+// encryptPassword encrypts the password with the server's RSA public key
+// so that it can be sent over an unencrypted connection.
+func (a *authenticator) encryptPassword(keyPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, adbc.Error{
+			Msg:  "[MySQL] invalid public key received from server",
+			Code: adbc.StatusUnauthenticated,
+		}
+	}
+
+	var pub *rsa.PublicKey
+	if strings.Contains(block.Type, "RSA") {
+		pub, _ = x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	if pub == nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  "[MySQL] invalid public key received from server: " + err.Error(),
+				Code: adbc.StatusUnauthenticated,
+			}
+		}
+		var ok bool
+		if pub, ok = key.(*rsa.PublicKey); !ok {
+			return nil, adbc.Error{
+				Msg:  fmt.Sprintf("[MySQL] unsupported public key type %T received from server", key),
+				Code: adbc.StatusUnauthenticated,
+			}
+		}
+	}
+
+	plain := append([]byte(a.cfg.password), 0)
+	for i := range plain {
+		plain[i] ^= a.scramble[i%len(a.scramble)]
+	}
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, plain, nil)
+}
+
+// scrambleNativePassword computes
+// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+func scrambleNativePassword(scramble []byte, password string) []byte {
+	if len(scramble) > 20 {
+		scramble = scramble[:20]
+	}
+
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	h := sha1.New()
+	h.Write(scramble)
+	h.Write(stage2[:])
+	out := h.Sum(nil)
+	for i := range out {
+		out[i] ^= stage1[i]
+	}
+	return out
+}
+
+// scrambleSHA256Password computes
+// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+func scrambleSHA256Password(scramble []byte, password string) []byte {
+	stage1 := sha256.Sum256([]byte(password))
+	stage2 := sha256.Sum256(stage1[:])
+	h := sha256.New()
+	h.Write(stage2[:])
+	h.Write(scramble)
+	out := h.Sum(nil)
+	for i := range out {
+		out[i] ^= stage1[i]
+	}
+	return out
+}
@@ -0,0 +1,464 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+const (
+	// the name the server is asked to read in LOAD DATA LOCAL INFILE;
+	// no file by this name is read, the bound data is sent instead
+	ingestInfile = "adbc_ingest"
+	// the amount of encoded rows sent per packet
+	ingestChunkSize = 1 << 20
+)
+
+// toMySQLType returns the column type a table is created with to hold
+// values of an Arrow type. Nested types are stored as JSON.
+func toMySQLType(dt arrow.DataType) (string, error) {
+	switch dt := dt.(type) {
+	case *arrow.BooleanType:
+		return "BOOLEAN", nil
+	case *arrow.Int8Type:
+		return "TINYINT", nil
+	case *arrow.Uint8Type:
+		return "TINYINT UNSIGNED", nil
+	case *arrow.Int16Type:
+		return "SMALLINT", nil
+	case *arrow.Uint16Type:
+		return "SMALLINT UNSIGNED", nil
+	case *arrow.Int32Type:
+		return "INT", nil
+	case *arrow.Uint32Type:
+		return "INT UNSIGNED", nil
+	case *arrow.Int64Type:
+		return "BIGINT", nil
+	case *arrow.Uint64Type:
+		return "BIGINT UNSIGNED", nil
+	case *arrow.Float16Type, *arrow.Float32Type:
+		return "FLOAT", nil
+	case *arrow.Float64Type:
+		return "DOUBLE", nil
+	case *arrow.StringType, *arrow.LargeStringType:
+		return "LONGTEXT", nil
+	case *arrow.BinaryType, *arrow.LargeBinaryType:
+		return "LONGBLOB", nil
+	case *arrow.FixedSizeBinaryType:
+		if dt.ByteWidth <= 255 {
+			return fmt.Sprintf("BINARY(%d)", dt.ByteWidth), nil
+		}
+		return "LONGBLOB", nil
+	case *arrow.Date32Type, *arrow.Date64Type:
+		return "DATE", nil
+	case *arrow.TimestampType:
+		return fmt.Sprintf("DATETIME(%d)", fractionalDigits(dt.Unit)), nil
+	case *arrow.Time32Type:
+		return fmt.Sprintf("TIME(%d)", fractionalDigits(dt.Unit)), nil
+	case *arrow.Time64Type:
+		return fmt.Sprintf("TIME(%d)", fractionalDigits(dt.Unit)), nil
+	case *arrow.DurationType:
+		return fmt.Sprintf("TIME(%d)", fractionalDigits(dt.Unit)), nil
+	case arrow.DecimalType:
+		if dt.GetPrecision() > maxDecimalPrecision || dt.GetScale() < 0 || dt.GetScale() > 30 {
+			break
+		}
+		return fmt.Sprintf("DECIMAL(%d,%d)", dt.GetPrecision(), dt.GetScale()), nil
+	case *arrow.DictionaryType:
+		return toMySQLType(dt.ValueType)
+	case arrow.ExtensionType:
+		return toMySQLType(dt.StorageType())
+	case *arrow.ListType, *arrow.LargeListType, *arrow.FixedSizeListType, *arrow.StructType, *arrow.MapType:
+		return "JSON", nil
+	}
+
+	return "", adbc.Error{
+		Msg:  "[MySQL] cannot ingest values of type " + dt.String(),
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// fractionalDigits returns the fractional seconds precision of a time
+// unit, which MySQL limits to microseconds.
+func fractionalDigits(unit arrow.TimeUnit) int {
+	switch unit {
+	case arrow.Second:
+		return 0
+	case arrow.Millisecond:
+		return 3
+	}
+	return 6
+}
+
+func (st *statement) boundSchema() *arrow.Schema {
+	if st.bound != nil {
+		return st.bound.Schema()
+	}
+	return st.streamBind.Schema()
+}
+
+func (st *statement) qualifiedTargetTable() string {
+	var schema *string
+	if st.targetSchema != "" {
+		schema = &st.targetSchema
+	}
+	return qualifiedName(schema, st.targetTable)
+}
+
+// createTable creates the target table according to the ingest mode.
+func (st *statement) createTable(ctx context.Context, target string, schema *arrow.Schema) error {
+	if st.ingestMode == adbc.OptionValueIngestModeAppend {
+		return nil
+	}
+
+	create := "CREATE TABLE "
+	if st.temporary {
+		create = "CREATE TEMPORARY TABLE "
+	}
+	switch st.ingestMode {
+	case adbc.OptionValueIngestModeReplace:
+		drop := "DROP TABLE IF EXISTS "
+		if st.temporary {
+			drop = "DROP TEMPORARY TABLE IF EXISTS "
+		}
+		if _, err := st.cnxn.exec(ctx, drop+target); err != nil {
+			return errToAdbcErr(adbc.StatusInternal, err)
+		}
+	case adbc.OptionValueIngestModeCreateAppend:
+		create += "IF NOT EXISTS "
+	}
+
+	colDefs := make([]string, len(schema.Fields()))
+	for i, f := range schema.Fields() {
+		typ, err := toMySQLType(f.Type)
+		if err != nil {
+			return err
+		}
+		colDefs[i] = quoteIdentifier(f.Name) + " " + typ
+		if !f.Nullable {
+			colDefs[i] += " NOT NULL"
+		}
+	}
+
+	_, err := st.cnxn.exec(ctx, create+target+" ("+strings.Join(colDefs, ", ")+")")
+	return errToAdbcErr(adbc.StatusInternal, err)
+}
+
+// executeIngest loads the bound data into the target table with
+// LOAD DATA LOCAL INFILE, streaming it to the server as tab separated
+// values. The server treats LOCAL loads as if IGNORE had been given, so
+// rows it could only load with warnings fail the ingestion, although
+// the rows sent until then have been loaded.
+func (st *statement) executeIngest(ctx context.Context) (int64, error) {
+	if !st.hasBinds() {
+		return -1, adbc.Error{
+			Msg:  "must call Bind before bulk ingestion",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if st.targetCatalog != "" && st.targetCatalog != catalogName {
+		return -1, adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] catalog '%s' not found, the only catalog is '%s'", st.targetCatalog, catalogName),
+			Code: adbc.StatusNotFound,
+		}
+	}
+	if st.cnxn.caps&clientLocalFiles == 0 {
+		return -1, adbc.Error{
+			Msg:  "[MySQL] server does not support LOAD DATA LOCAL INFILE",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	schema := st.boundSchema()
+	target := st.qualifiedTargetTable()
+	if err := st.createTable(ctx, target, schema); err != nil {
+		return -1, err
+	}
+
+	cols := make([]string, len(schema.Fields()))
+	for i, f := range schema.Fields() {
+		cols[i] = quoteIdentifier(f.Name)
+	}
+	query := fmt.Sprintf("LOAD DATA LOCAL INFILE '%s' INTO TABLE %s CHARACTER SET binary "+
+		"FIELDS TERMINATED BY X'09' ESCAPED BY X'5C' LINES TERMINATED BY X'0A' (%s)",
+		ingestInfile, target, strings.Join(cols, ", "))
+
+	params := st.params()
+	defer params.release()
+	ok, err := st.cnxn.loadData(ctx, query, params)
+	if err != nil {
+		return -1, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	if ok.warnings > 0 {
+		msg := fmt.Sprintf("[MySQL] ingestion into %s raised %d warnings", target, ok.warnings)
+		if rows, err := st.cnxn.textQuery(ctx, "SHOW WARNINGS LIMIT 1"); err == nil && len(rows) > 0 && len(rows[0]) == 3 {
+			msg += ", the first being: " + deref(rows[0][2])
+		}
+		return int64(ok.affectedRows), adbc.Error{
+			Msg:  msg,
+			Code: adbc.StatusInvalidData,
+		}
+	}
+	return int64(ok.affectedRows), nil
+}
+
+// loadData runs a LOAD DATA LOCAL INFILE query, sending the rows of
+// params as the contents of the file.
+func (c *cnxn) loadData(ctx context.Context, query string, params *paramRows) (okPacket, error) {
+	defer c.pc.watch(ctx)()
+
+	if err := c.command(comQuery, []byte(query)); err != nil {
+		return okPacket{}, err
+	}
+	data, err := c.pc.readPacket()
+	if err != nil {
+		return okPacket{}, err
+	}
+	switch {
+	case len(data) > 0 && data[0] == iERR:
+		return okPacket{}, parseError(data)
+	case len(data) == 0 || data[0] != iLocalInFile:
+		return okPacket{}, c.pc.ioError(fmt.Errorf("unexpected response 0x%x to LOAD DATA", data))
+	case string(data[1:]) != ingestInfile:
+		return okPacket{}, c.refuseLocalInfile()
+	}
+
+	// an error encoding the data still has to end the file, after which
+	// the server reports how much of it was loaded
+	encErr := c.sendInfile(params)
+	if encErr != nil && c.pc.broken {
+		return okPacket{}, encErr
+	}
+	if err := c.pc.writePacket(nil); err != nil {
+		return okPacket{}, err
+	}
+	ok, err := c.pc.readResult()
+	if encErr != nil {
+		return ok, encErr
+	}
+	return ok, err
+}
+
+func (c *cnxn) sendInfile(params *paramRows) error {
+	buf := make([]byte, 0, ingestChunkSize+4096)
+	var encoders []tsvEncoder
+	var rec arrow.Record
+
+	for params.next() {
+		if params.rec != rec {
+			rec = params.rec
+			encoders = encoders[:0]
+			for _, col := range rec.Columns() {
+				enc, err := newTSVEncoder(col)
+				if err != nil {
+					return err
+				}
+				encoders = append(encoders, enc)
+			}
+		}
+
+		row := params.row - 1
+		for i, enc := range encoders {
+			if i > 0 {
+				buf = append(buf, '\t')
+			}
+			var err error
+			if buf, err = enc(buf, row); err != nil {
+				return err
+			}
+		}
+		buf = append(buf, '\n')
+
+		if len(buf) >= ingestChunkSize {
+			if err := c.pc.writePacket(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	if params.err != nil {
+		return errToAdbcErr(adbc.StatusIO, params.err)
+	}
+
+	if len(buf) > 0 {
+		return c.pc.writePacket(buf)
+	}
+	return nil
+}
+
+// tsvEncoder appends a value to a LOAD DATA file, escaped with
+// backslashes and with \N for NULL
+type tsvEncoder func(buf []byte, i int) ([]byte, error)
+
+func appendEscaped(buf []byte, v []byte) []byte {
+	for _, c := range v {
+		switch c {
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case 0:
+			buf = append(buf, '\\', '0')
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+func appendDuration(buf []byte, d time.Duration) []byte {
+	if d < 0 {
+		buf, d = append(buf, '-'), -d
+	}
+	buf = strconv.AppendInt(buf, int64(d/time.Hour), 10)
+	return append(buf, fmt.Sprintf(":%02d:%02d.%06d", d/time.Minute%60, d/time.Second%60, d%time.Second/time.Microsecond)...)
+}
+
+func newTSVEncoder(arr arrow.Array) (tsvEncoder, error) {
+	var enc tsvEncoder
+	switch a := arr.(type) {
+	case *array.Boolean:
+		enc = func(buf []byte, i int) ([]byte, error) {
+			if a.Value(i) {
+				return append(buf, '1'), nil
+			}
+			return append(buf, '0'), nil
+		}
+	case *array.Int8:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendInt(buf, int64(a.Value(i)), 10), nil }
+	case *array.Int16:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendInt(buf, int64(a.Value(i)), 10), nil }
+	case *array.Int32:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendInt(buf, int64(a.Value(i)), 10), nil }
+	case *array.Int64:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendInt(buf, a.Value(i), 10), nil }
+	case *array.Uint8:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendUint(buf, uint64(a.Value(i)), 10), nil }
+	case *array.Uint16:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendUint(buf, uint64(a.Value(i)), 10), nil }
+	case *array.Uint32:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendUint(buf, uint64(a.Value(i)), 10), nil }
+	case *array.Uint64:
+		enc = func(buf []byte, i int) ([]byte, error) { return strconv.AppendUint(buf, a.Value(i), 10), nil }
+	case *array.Float16:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendFloat(buf, float64(a.Value(i).Float32()), 32) }
+	case *array.Float32:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendFloat(buf, float64(a.Value(i)), 32) }
+	case *array.Float64:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendFloat(buf, a.Value(i), 64) }
+	case *array.String:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendEscaped(buf, []byte(a.Value(i))), nil }
+	case *array.LargeString:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendEscaped(buf, []byte(a.Value(i))), nil }
+	case *array.Binary:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendEscaped(buf, a.Value(i)), nil }
+	case *array.LargeBinary:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendEscaped(buf, a.Value(i)), nil }
+	case *array.FixedSizeBinary:
+		enc = func(buf []byte, i int) ([]byte, error) { return appendEscaped(buf, a.Value(i)), nil }
+	case *array.Date32:
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return a.Value(i).ToTime().AppendFormat(buf, "2006-01-02"), nil
+		}
+	case *array.Date64:
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return a.Value(i).ToTime().AppendFormat(buf, "2006-01-02"), nil
+		}
+	case *array.Timestamp:
+		// timestamps with a time zone are loaded as UTC
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return a.Value(i).ToTime(unit).UTC().AppendFormat(buf, "2006-01-02 15:04:05.999999"), nil
+		}
+	case *array.Time32:
+		unit := a.DataType().(*arrow.Time32Type).Unit
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return appendDuration(buf, time.Duration(a.Value(i))*unit.Multiplier()), nil
+		}
+	case *array.Time64:
+		unit := a.DataType().(*arrow.Time64Type).Unit
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return appendDuration(buf, time.Duration(a.Value(i))*unit.Multiplier()), nil
+		}
+	case *array.Duration:
+		unit := a.DataType().(*arrow.DurationType).Unit
+		enc = func(buf []byte, i int) ([]byte, error) {
+			return appendDuration(buf, time.Duration(a.Value(i))*unit.Multiplier()), nil
+		}
+	case *array.Decimal128:
+		scale := a.DataType().(*arrow.Decimal128Type).Scale
+		enc = func(buf []byte, i int) ([]byte, error) { return append(buf, a.Value(i).ToString(scale)...), nil }
+	case *array.Decimal256:
+		scale := a.DataType().(*arrow.Decimal256Type).Scale
+		enc = func(buf []byte, i int) ([]byte, error) { return append(buf, a.Value(i).ToString(scale)...), nil }
+	case *array.Dictionary:
+		values, err := newTSVEncoder(a.Dictionary())
+		if err != nil {
+			return nil, err
+		}
+		enc = func(buf []byte, i int) ([]byte, error) { return values(buf, a.GetValueIndex(i)) }
+	case array.ExtensionArray:
+		return newTSVEncoder(a.Storage())
+	case array.ListLike, *array.Struct:
+		enc = func(buf []byte, i int) ([]byte, error) {
+			v, err := json.Marshal(arr.GetOneForMarshal(i))
+			if err != nil {
+				return nil, adbc.Error{
+					Msg:  "[MySQL] cannot encode value as JSON: " + err.Error(),
+					Code: adbc.StatusInvalidData,
+				}
+			}
+			return appendEscaped(buf, v), nil
+		}
+	default:
+		return nil, adbc.Error{
+			Msg:  "[MySQL] cannot ingest values of type " + arr.DataType().String(),
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	return func(buf []byte, i int) ([]byte, error) {
+		if arr.IsNull(i) {
+			return append(buf, '\\', 'N'), nil
+		}
+		return enc(buf, i)
+	}, nil
+}
+
+func appendFloat(buf []byte, v float64, bits int) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] cannot ingest %v, MySQL does not support NaN or infinite values", v),
+			Code: adbc.StatusInvalidData,
+		}
+	}
+	return strconv.AppendFloat(buf, v, 'g', -1, bits), nil
+}
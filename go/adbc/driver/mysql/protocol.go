@@ -0,0 +1,441 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+)
+
+const (
+	// packets carrying this many bytes or more are continued in the
+	// next packet
+	maxPacketSize = 1<<24 - 1
+
+	comQuit        byte = 0x01
+	comInitDB      byte = 0x02
+	comQuery       byte = 0x03
+	comPing        byte = 0x0e
+	comStmtPrepare byte = 0x16
+	comStmtExecute byte = 0x17
+	comStmtClose   byte = 0x19
+
+	iOK          byte = 0x00
+	iLocalInFile byte = 0xfb
+	iEOF         byte = 0xfe
+	iERR         byte = 0xff
+)
+
+// capability flags
+const (
+	clientLongPassword uint32 = 1 << iota
+	clientFoundRows
+	clientLongFlag
+	clientConnectWithDB
+	clientNoSchema
+	clientCompress
+	clientODBC
+	clientLocalFiles
+	clientIgnoreSpace
+	clientProtocol41
+	clientInteractive
+	clientSSL
+	clientIgnoreSIGPIPE
+	clientTransactions
+	clientReserved
+	clientSecureConn
+	clientMultiStatements
+	clientMultiResults
+	clientPSMultiResults
+	clientPluginAuth
+	clientConnectAttrs
+	clientPluginAuthLenEncClientData
+	clientCanHandleExpiredPasswords
+	clientSessionTrack
+	clientDeprecateEOF
+)
+
+// server status flags
+const (
+	statusInTrans           uint16 = 0x0001
+	statusAutocommit        uint16 = 0x0002
+	statusMoreResultsExists uint16 = 0x0008
+)
+
+var errMalformedPacket = errors.New("malformed packet")
+
+// payload consumes the fields of a packet from the front. Reading past
+// the end records errMalformedPacket and returns zero values, so a
+// sequence of reads only needs checking once at the end.
+type payload struct {
+	buf []byte
+	err error
+}
+
+func (p *payload) next(n int) []byte {
+	if p.err != nil || n > len(p.buf) {
+		p.err = errMalformedPacket
+		return nil
+	}
+	out := p.buf[:n]
+	p.buf = p.buf[n:]
+	return out
+}
+
+func (p *payload) uint8() uint8 {
+	if b := p.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (p *payload) uint16() uint16 {
+	if b := p.next(2); b != nil {
+		return uint16(b[0]) | uint16(b[1])<<8
+	}
+	return 0
+}
+
+func (p *payload) uint24() uint32 {
+	if b := p.next(3); b != nil {
+		return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+	}
+	return 0
+}
+
+func (p *payload) uint32() uint32 {
+	if b := p.next(4); b != nil {
+		return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+	}
+	return 0
+}
+
+func (p *payload) uint64() uint64 {
+	if b := p.next(8); b != nil {
+		return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+			uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56
+	}
+	return 0
+}
+
+// lenEncInt reads a length-encoded integer, which is also how NULL is
+// represented in text protocol rows.
+func (p *payload) lenEncInt() (v uint64, isNull bool) {
+	switch first := p.uint8(); first {
+	case 0xfb:
+		return 0, true
+	case 0xfc:
+		return uint64(p.uint16()), false
+	case 0xfd:
+		return uint64(p.uint24()), false
+	case 0xfe:
+		return p.uint64(), false
+	default:
+		return uint64(first), false
+	}
+}
+
+func (p *payload) lenEncBytes() (b []byte, isNull bool) {
+	n, isNull := p.lenEncInt()
+	if isNull {
+		return nil, true
+	}
+	if n > uint64(len(p.buf)) {
+		p.err = errMalformedPacket
+		return nil, false
+	}
+	return p.next(int(n)), false
+}
+
+func (p *payload) lenEncString() string {
+	b, _ := p.lenEncBytes()
+	return string(b)
+}
+
+func (p *payload) nulString() string {
+	for i, c := range p.buf {
+		if c == 0 {
+			s := string(p.buf[:i])
+			p.buf = p.buf[i+1:]
+			return s
+		}
+	}
+	// the last string of a packet may not be terminated
+	s := string(p.buf)
+	p.buf = nil
+	return s
+}
+
+func (p *payload) rest() []byte {
+	b := p.buf
+	p.buf = nil
+	return b
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+func appendLenEncInt(b []byte, v uint64) []byte {
+	switch {
+	case v < 0xfb:
+		return append(b, byte(v))
+	case v <= 0xffff:
+		return appendUint16(append(b, 0xfc), uint16(v))
+	case v <= 0xffffff:
+		return append(b, 0xfd, byte(v), byte(v>>8), byte(v>>16))
+	}
+	return appendUint64(append(b, 0xfe), v)
+}
+
+func appendLenEncString(b []byte, s string) []byte {
+	return append(appendLenEncInt(b, uint64(len(s))), s...)
+}
+
+// serverError is an ERR packet sent by the server
+type serverError struct {
+	code     uint16
+	sqlState string
+	msg      string
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("Error %d (%s): %s", e.code, e.sqlState, e.msg)
+}
+
+func parseError(data []byte) error {
+	p := payload{buf: data[1:]}
+	e := &serverError{code: p.uint16()}
+	if len(p.buf) > 0 && p.buf[0] == '#' {
+		p.next(1)
+		e.sqlState = string(p.next(5))
+	}
+	e.msg = string(p.rest())
+	if p.err != nil {
+		return p.err
+	}
+	return e
+}
+
+// okPacket is the result of a command which doesn't return rows. With
+// clientDeprecateEOF it also ends a result set in place of EOF.
+type okPacket struct {
+	affectedRows uint64
+	lastInsertID uint64
+	status       uint16
+	warnings     uint16
+}
+
+func parseOK(data []byte) (okPacket, error) {
+	p := payload{buf: data[1:]}
+	var ok okPacket
+	ok.affectedRows, _ = p.lenEncInt()
+	ok.lastInsertID, _ = p.lenEncInt()
+	ok.status = p.uint16()
+	ok.warnings = p.uint16()
+	return ok, p.err
+}
+
+// isEOF reports whether a packet ends a result set: an EOF packet, or
+// an OK packet with an EOF header when clientDeprecateEOF is in use.
+// Row packets can also start with 0xfe when their first value is long,
+// but they are then always at least 9 bytes long.
+func isEOF(data []byte, deprecateEOF bool) bool {
+	if len(data) == 0 || data[0] != iEOF {
+		return false
+	}
+	if deprecateEOF {
+		return len(data) < maxPacketSize
+	}
+	return len(data) < 9
+}
+
+// eofStatus returns the status flags of a packet ending a result set.
+func eofStatus(data []byte, deprecateEOF bool) (uint16, error) {
+	if deprecateEOF {
+		ok, err := parseOK(data)
+		return ok.status, err
+	}
+	p := payload{buf: data[1:]}
+	p.uint16() // warnings
+	status := p.uint16()
+	return status, p.err
+}
+
+// packetConn reads and writes MySQL protocol packets, tracking the
+// sequence number which restarts with every command.
+type packetConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	seq  uint8
+
+	// broken is set after an I/O error or cancellation, which leaves the
+	// protocol in an unknown state
+	broken bool
+}
+
+func newPacketConn(conn net.Conn) *packetConn {
+	return &packetConn{conn: conn, rd: bufio.NewReaderSize(conn, 16*1024)}
+}
+
+func (p *packetConn) ioError(err error) error {
+	p.broken = true
+	if errors.Is(err, errMalformedPacket) {
+		return adbc.Error{
+			Msg:  "[MySQL] malformed packet received from server",
+			Code: adbc.StatusInternal,
+		}
+	}
+	return adbc.Error{
+		Msg:  "[MySQL] " + err.Error(),
+		Code: adbc.StatusIO,
+	}
+}
+
+func (p *packetConn) readPacket() ([]byte, error) {
+	var data []byte
+	for {
+		var hdr [4]byte
+		if _, err := io.ReadFull(p.rd, hdr[:]); err != nil {
+			return nil, p.ioError(err)
+		}
+		if hdr[3] != p.seq {
+			return nil, p.ioError(fmt.Errorf("packet out of order: expected sequence %d, got %d", p.seq, hdr[3]))
+		}
+		p.seq++
+
+		n := int(uint32(hdr[0]) | uint32(hdr[1])<<8 | uint32(hdr[2])<<16)
+		start := len(data)
+		data = append(data, make([]byte, n)...)
+		if _, err := io.ReadFull(p.rd, data[start:]); err != nil {
+			return nil, p.ioError(err)
+		}
+		if n < maxPacketSize {
+			return data, nil
+		}
+	}
+}
+
+func (p *packetConn) writePacket(data []byte) error {
+	for {
+		n := len(data)
+		if n > maxPacketSize {
+			n = maxPacketSize
+		}
+
+		hdr := []byte{byte(n), byte(n >> 8), byte(n >> 16), p.seq}
+		p.seq++
+		bufs := net.Buffers{hdr}
+		if n > 0 {
+			bufs = append(bufs, data[:n])
+		}
+		if _, err := bufs.WriteTo(p.conn); err != nil {
+			return p.ioError(err)
+		}
+
+		// a packet of exactly the maximum size is followed by another,
+		// possibly empty, one
+		data = data[n:]
+		if n < maxPacketSize {
+			return nil
+		}
+	}
+}
+
+// writeCommand starts a new command, resetting the sequence number.
+func (p *packetConn) writeCommand(cmd byte, arg []byte) error {
+	if p.broken {
+		return adbc.Error{
+			Msg:  "[MySQL] connection is no longer usable after an earlier error",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	p.seq = 0
+	return p.writePacket(append([]byte{cmd}, arg...))
+}
+
+// readResult reads the response to a command which doesn't return rows.
+func (p *packetConn) readResult() (okPacket, error) {
+	data, err := p.readPacket()
+	if err != nil {
+		return okPacket{}, err
+	}
+
+	switch {
+	case len(data) == 0:
+		return okPacket{}, p.ioError(errMalformedPacket)
+	case data[0] == iERR:
+		return okPacket{}, parseError(data)
+	case data[0] == iOK:
+		ok, err := parseOK(data)
+		if err != nil {
+			return ok, p.ioError(err)
+		}
+		return ok, nil
+	}
+	return okPacket{}, p.ioError(fmt.Errorf("unexpected packet 0x%02x in place of OK", data[0]))
+}
+
+// watch applies the deadline and cancellation of ctx to the network
+// connection until the returned function is called. Interrupting I/O
+// leaves the connection unusable, so a cancelled connection is broken.
+func (p *packetConn) watch(ctx context.Context) (stop func()) {
+	if p == nil {
+		return func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		p.conn.SetDeadline(deadline)
+	}
+	if ctx.Done() == nil {
+		return func() { p.conn.SetDeadline(time.Time{}) }
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case <-ctx.Done():
+			p.conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		p.conn.SetDeadline(time.Time{})
+	}
+}
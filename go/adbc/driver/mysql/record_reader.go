@@ -0,0 +1,279 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// resultSet streams the rows of a result set from the connection. Only
+// one result set can be read from a connection at a time, so it stays
+// the connection's active result set until all its rows have been read
+// or discarded.
+type resultSet struct {
+	c      *cnxn
+	cols   []column
+	binary bool
+	done   bool
+	// discarded is set when another command drained the rows before
+	// they were read
+	discarded bool
+}
+
+// nextRow returns the next row packet, or nil once the result set is
+// exhausted, in which case any further results of the command (from a
+// stored procedure for example) are discarded.
+func (rs *resultSet) nextRow() ([]byte, error) {
+	if rs.discarded {
+		return nil, adbc.Error{
+			Msg:  "[MySQL] result set was discarded by another query on the same connection",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	if rs.done {
+		return nil, nil
+	}
+
+	data, err := rs.c.pc.readPacket()
+	if err != nil {
+		rs.done, rs.c.active = true, nil
+		return nil, err
+	}
+
+	deprecateEOF := rs.c.caps&clientDeprecateEOF != 0
+	switch {
+	case isEOF(data, deprecateEOF):
+		rs.done, rs.c.active = true, nil
+		status, err := eofStatus(data, deprecateEOF)
+		if err != nil {
+			return nil, rs.c.pc.ioError(err)
+		}
+		if status&statusMoreResultsExists != 0 {
+			_, err = rs.c.discardResults(rs.binary)
+		}
+		return nil, err
+	case len(data) > 0 && data[0] == iERR:
+		rs.done, rs.c.active = true, nil
+		return nil, parseError(data)
+	}
+	return data, nil
+}
+
+// discard reads and drops the remaining rows.
+func (rs *resultSet) discard() error {
+	for {
+		data, err := rs.nextRow()
+		if err != nil || data == nil {
+			return err
+		}
+	}
+}
+
+type reader struct {
+	refCount  int64
+	schema    *arrow.Schema
+	bldr      *array.RecordBuilder
+	decoders  []valueDecoder
+	batchSize int
+
+	rs   *resultSet
+	next func() (*resultSet, error)
+	done func()
+
+	rec arrow.Record
+	err error
+}
+
+// newRecordReader takes ownership of rs and calls done, if set, once the
+// reader has been released. After rs is exhausted, next is called for
+// the following result set until it returns nil; those result sets must
+// have the same columns as the first one.
+func newRecordReader(alloc memory.Allocator, rs *resultSet, next func() (*resultSet, error), done func(), batchSize int) array.RecordReader {
+	var cols []column
+	if rs != nil {
+		cols = rs.cols
+	}
+
+	schema := schemaFromColumns(cols)
+	bldr := array.NewRecordBuilder(alloc, schema)
+	rdr := &reader{
+		refCount:  1,
+		schema:    schema,
+		bldr:      bldr,
+		decoders:  make([]valueDecoder, len(cols)),
+		batchSize: batchSize,
+		rs:        rs,
+		next:      next,
+		done:      done,
+	}
+	for i := range cols {
+		rdr.decoders[i] = newValueDecoder(&cols[i], bldr.Field(i))
+	}
+	return rdr
+}
+
+func (r *reader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+func (r *reader) Record() arrow.Record {
+	return r.rec
+}
+
+func (r *reader) Err() error {
+	return r.err
+}
+
+// nextResultSet moves on to the next result set, returning false once
+// there are none left.
+func (r *reader) nextResultSet() bool {
+	r.rs = nil
+	if r.next == nil {
+		return false
+	}
+
+	rs, err := r.next()
+	if err != nil {
+		r.err = errToAdbcErr(adbc.StatusIO, err)
+		return false
+	}
+	if rs == nil {
+		return false
+	}
+
+	r.rs = rs
+	if len(rs.cols) != len(r.decoders) {
+		r.err = adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] result sets have different numbers of columns: %d and %d", len(r.decoders), len(rs.cols)),
+			Code: adbc.StatusInvalidData,
+		}
+		return false
+	}
+	for i := range rs.cols {
+		if dt := rs.cols[i].arrowType(); !arrow.TypeEqual(dt, r.schema.Field(i).Type) {
+			r.err = adbc.Error{
+				Msg:  fmt.Sprintf("[MySQL] result sets have different types for column %s: %s and %s", rs.cols[i].name, r.schema.Field(i).Type, dt),
+				Code: adbc.StatusInvalidData,
+			}
+			return false
+		}
+	}
+	return true
+}
+
+func (r *reader) decodeRow(data []byte) error {
+	if r.rs.binary {
+		// the NULL bitmap of binary rows is offset by two bits
+		nullLen := (len(r.decoders) + 7 + 2) / 8
+		if len(data) < 1+nullLen {
+			return r.rs.c.pc.ioError(errMalformedPacket)
+		}
+		nulls := data[1 : 1+nullLen]
+		p := payload{buf: data[1+nullLen:]}
+		for i, dec := range r.decoders {
+			if bit := i + 2; nulls[bit/8]&(1<<(bit%8)) != 0 {
+				r.bldr.Field(i).AppendNull()
+				continue
+			}
+			if err := dec.binary(&p); err != nil {
+				return err
+			}
+		}
+		if p.err != nil {
+			return r.rs.c.pc.ioError(p.err)
+		}
+		return nil
+	}
+
+	p := payload{buf: data}
+	for i, dec := range r.decoders {
+		v, isNull := p.lenEncBytes()
+		if p.err != nil {
+			return r.rs.c.pc.ioError(p.err)
+		}
+		if isNull {
+			r.bldr.Field(i).AppendNull()
+			continue
+		}
+		if err := dec.text(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+
+	nrows := 0
+	for r.err == nil && r.rs != nil && nrows < r.batchSize {
+		data, err := r.rs.nextRow()
+		if err != nil {
+			r.err = errToAdbcErr(adbc.StatusIO, err)
+			break
+		}
+		if data == nil {
+			r.nextResultSet()
+			continue
+		}
+
+		if err := r.decodeRow(data); err != nil {
+			r.err = errToAdbcErr(adbc.StatusInvalidData, err)
+			break
+		}
+		nrows++
+	}
+
+	if r.err != nil || nrows == 0 {
+		return false
+	}
+
+	r.rec = r.bldr.NewRecord()
+	return true
+}
+
+func (r *reader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *reader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		if r.rs != nil {
+			// leave the connection ready for the next command
+			r.rs.discard()
+			r.rs = nil
+		}
+		r.bldr.Release()
+		if r.done != nil {
+			r.done()
+		}
+	}
+}
@@ -0,0 +1,484 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResult is how the fake server answers a query: with an error, a
+// result set if there are columns, or else an OK packet.
+type fakeResult struct {
+	cols     []column
+	rows     [][]interface{}
+	affected uint64
+	err      *serverError
+}
+
+// fakeServer speaks enough of the server side of the MySQL protocol to
+// test the driver without a database: authentication, text queries,
+// prepared statements and LOAD DATA LOCAL INFILE. Queries are answered
+// by handler, which is called with nil params when a query is prepared
+// to get the columns of its result set.
+type fakeServer struct {
+	t        *testing.T
+	ln       net.Listener
+	user     string
+	password string
+	// the plugin advertised in the handshake and, if set, the one the
+	// server switches to during authentication
+	plugin   string
+	switchTo string
+	handler  func(query string, params []interface{}) fakeResult
+
+	mu      sync.Mutex
+	queries []string
+	infile  []byte
+}
+
+var fakeScramble = []byte("0123456789abcdefghij")
+
+func newFakeServer(t *testing.T, handler func(string, []interface{}) fakeResult) *fakeServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeServer{
+		t: t, ln: ln, user: "adbc", password: "secret",
+		plugin: pluginNativePassword, handler: handler,
+	}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeServer) uri() string {
+	return fmt.Sprintf("mysql://%s:%s@%s/testdb?tls=disabled", s.user, s.password, s.ln.Addr())
+}
+
+func (s *fakeServer) log(query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+}
+
+func (s *fakeServer) loggedQueries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.queries...)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *fakeServer) writeError(pc *packetConn, err *serverError) error {
+	pkt := appendUint16([]byte{iERR}, err.code)
+	pkt = append(append(pkt, '#'), err.sqlState...)
+	return pc.writePacket(append(pkt, err.msg...))
+}
+
+func (s *fakeServer) writeOK(pc *packetConn, header byte, affected uint64) error {
+	pkt := appendLenEncInt([]byte{header}, affected)
+	pkt = appendLenEncInt(pkt, 0)
+	pkt = appendUint16(pkt, statusAutocommit)
+	return pc.writePacket(appendUint16(pkt, 0))
+}
+
+func (s *fakeServer) handshake(pc *packetConn) error {
+	const caps = clientLongPassword | clientLongFlag | clientConnectWithDB |
+		clientLocalFiles | clientProtocol41 | clientTransactions | clientSecureConn |
+		clientMultiResults | clientPSMultiResults | clientPluginAuth |
+		clientPluginAuthLenEncClientData | clientDeprecateEOF
+
+	pkt := append([]byte{10}, "8.0.33-fake"...)
+	pkt = appendUint32(append(pkt, 0), 1)
+	pkt = append(append(pkt, fakeScramble[:8]...), 0)
+	pkt = appendUint16(pkt, uint16(caps&0xffff))
+	pkt = appendUint16(append(pkt, collationUTF8MB4), statusAutocommit)
+	pkt = appendUint16(pkt, uint16(caps>>16))
+	pkt = append(append(pkt, 21), make([]byte, 10)...)
+	pkt = append(append(pkt, fakeScramble[8:]...), 0)
+	pkt = append(append(pkt, s.plugin...), 0)
+	if err := pc.writePacket(pkt); err != nil {
+		return err
+	}
+
+	data, err := pc.readPacket()
+	if err != nil {
+		return err
+	}
+	p := payload{buf: data}
+	clientCaps := p.uint32()
+	p.uint32() // max packet size
+	p.uint8()  // collation
+	p.next(23)
+	user := p.nulString()
+	resp, _ := p.lenEncBytes()
+	if clientCaps&clientConnectWithDB != 0 {
+		p.nulString()
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	plugin := s.plugin
+	if s.switchTo != "" {
+		plugin = s.switchTo
+		pkt := append(append([]byte{iEOF}, plugin...), 0)
+		if err := pc.writePacket(append(append(pkt, fakeScramble...), 0)); err != nil {
+			return err
+		}
+		if resp, err = pc.readPacket(); err != nil {
+			return err
+		}
+	}
+
+	var expected []byte
+	if s.password != "" {
+		switch plugin {
+		case pluginNativePassword:
+			expected = scrambleNativePassword(fakeScramble, s.password)
+		case pluginCachingSHA2:
+			expected = scrambleSHA256Password(fakeScramble, s.password)
+		}
+	}
+	if user != s.user || !bytes.Equal(resp, expected) {
+		s.writeError(pc, &serverError{code: 1045, sqlState: "28000", msg: "Access denied for user '" + user + "'"})
+		return fmt.Errorf("access denied")
+	}
+	if plugin == pluginCachingSHA2 {
+		if err := pc.writePacket([]byte{authMoreData, cachingSHA2FastAuthOK}); err != nil {
+			return err
+		}
+	}
+	return s.writeOK(pc, iOK, 0)
+}
+
+func (s *fakeServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	pc := newPacketConn(conn)
+	if err := s.handshake(pc); err != nil {
+		return
+	}
+
+	stmts := map[uint32]string{}
+	var nextID uint32
+	for {
+		pc.seq = 0
+		data, err := pc.readPacket()
+		if err != nil || len(data) == 0 {
+			return
+		}
+
+		switch data[0] {
+		case comQuit:
+			return
+		case comQuery:
+			query := string(data[1:])
+			s.log(query)
+			switch {
+			case strings.HasPrefix(query, "LOAD DATA"):
+				err = s.loadData(pc, ingestInfile)
+			case query == "SELECT evil":
+				err = s.loadData(pc, "/etc/passwd")
+			default:
+				err = s.writeResult(pc, s.handler(query, nil), false)
+			}
+		case comStmtPrepare:
+			query := string(data[1:])
+			s.log("PREPARE " + query)
+			res := s.handler(query, nil)
+			if res.err != nil {
+				err = s.writeError(pc, res.err)
+				break
+			}
+			nextID++
+			stmts[nextID] = query
+			err = s.writePrepareOK(pc, nextID, strings.Count(query, "?"), res.cols)
+		case comStmtExecute:
+			p := payload{buf: data[1:]}
+			id := p.uint32()
+			query := stmts[id]
+			params := readParams(&p, strings.Count(query, "?"))
+			s.log(fmt.Sprintf("EXECUTE %s %v", query, params))
+			err = s.writeResult(pc, s.handler(query, params), true)
+		case comStmtClose:
+			delete(stmts, payloadID(data[1:]))
+		default:
+			err = s.writeError(pc, &serverError{code: 1047, sqlState: "08S01", msg: "Unknown command"})
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func payloadID(data []byte) uint32 {
+	p := payload{buf: data}
+	return p.uint32()
+}
+
+func (s *fakeServer) writePrepareOK(pc *packetConn, id uint32, nparams int, cols []column) error {
+	pkt := appendUint32([]byte{iOK}, id)
+	pkt = appendUint16(pkt, uint16(len(cols)))
+	pkt = appendUint16(pkt, uint16(nparams))
+	pkt = appendUint16(append(pkt, 0), 0)
+	if err := pc.writePacket(pkt); err != nil {
+		return err
+	}
+
+	for i := 0; i < nparams; i++ {
+		if err := pc.writePacket(appendColumnDef(nil, column{name: "?", typ: typeLongLong, charset: charsetBinary})); err != nil {
+			return err
+		}
+	}
+	for _, col := range cols {
+		if err := pc.writePacket(appendColumnDef(nil, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeServer) writeResult(pc *packetConn, res fakeResult, binary bool) error {
+	if res.err != nil {
+		return s.writeError(pc, res.err)
+	}
+	if len(res.cols) == 0 {
+		return s.writeOK(pc, iOK, res.affected)
+	}
+
+	if err := pc.writePacket(appendLenEncInt(nil, uint64(len(res.cols)))); err != nil {
+		return err
+	}
+	for _, col := range res.cols {
+		if err := pc.writePacket(appendColumnDef(nil, col)); err != nil {
+			return err
+		}
+	}
+	for _, row := range res.rows {
+		var pkt []byte
+		if binary {
+			pkt = appendBinaryRow(res.cols, row)
+		} else {
+			pkt = appendTextRow(res.cols, row)
+		}
+		if err := pc.writePacket(pkt); err != nil {
+			return err
+		}
+	}
+	return s.writeOK(pc, iEOF, 0)
+}
+
+func (s *fakeServer) loadData(pc *packetConn, filename string) error {
+	if err := pc.writePacket(append([]byte{iLocalInFile}, filename...)); err != nil {
+		return err
+	}
+
+	var contents []byte
+	for {
+		data, err := pc.readPacket()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			break
+		}
+		contents = append(contents, data...)
+	}
+
+	s.mu.Lock()
+	s.infile = append(s.infile, contents...)
+	s.mu.Unlock()
+	return s.writeOK(pc, iOK, uint64(bytes.Count(contents, []byte("\n"))))
+}
+
+func appendColumnDef(b []byte, col column) []byte {
+	b = appendLenEncString(b, "def")
+	b = appendLenEncString(b, col.schema)
+	b = appendLenEncString(b, col.table)
+	b = appendLenEncString(b, col.table)
+	b = appendLenEncString(b, col.name)
+	b = appendLenEncString(b, col.name)
+	b = append(b, 0x0c)
+	b = appendUint16(b, col.charset)
+	b = appendUint32(b, col.length)
+	b = append(b, col.typ)
+	b = appendUint16(b, col.flags)
+	return append(b, col.decimals, 0, 0)
+}
+
+func textValue(col column, v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		if col.typ == typeDate {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04:05.999999")
+	case time.Duration:
+		return string(appendDuration(nil, v))
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(v)
+}
+
+func appendTextRow(cols []column, row []interface{}) []byte {
+	var pkt []byte
+	for i, v := range row {
+		if v == nil {
+			pkt = append(pkt, 0xfb)
+			continue
+		}
+		pkt = appendLenEncString(pkt, textValue(cols[i], v))
+	}
+	return pkt
+}
+
+func toUint64(v interface{}) uint64 {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case int:
+		return uint64(v)
+	case int64:
+		return uint64(v)
+	case uint64:
+		return v
+	}
+	panic(fmt.Sprintf("not an integer: %T", v))
+}
+
+func appendBinaryRow(cols []column, row []interface{}) []byte {
+	nulls := make([]byte, (len(cols)+9)/8)
+	var values []byte
+	for i, v := range row {
+		if v == nil {
+			bit := i + 2
+			nulls[bit/8] |= 1 << (bit % 8)
+			continue
+		}
+
+		switch cols[i].typ {
+		case typeTiny:
+			values = append(values, byte(toUint64(v)))
+		case typeShort, typeYear:
+			values = appendUint16(values, uint16(toUint64(v)))
+		case typeInt24, typeLong:
+			values = appendUint32(values, uint32(toUint64(v)))
+		case typeLongLong:
+			values = appendUint64(values, toUint64(v))
+		case typeFloat:
+			values = appendUint32(values, math.Float32bits(float32(v.(float64))))
+		case typeDouble:
+			values = appendUint64(values, math.Float64bits(v.(float64)))
+		case typeDate:
+			t := v.(time.Time)
+			values = append(appendUint16(append(values, 4), uint16(t.Year())), byte(t.Month()), byte(t.Day()))
+		case typeDatetime, typeTimestamp:
+			values = appendDatetime(values, v.(time.Time))
+		case typeTime:
+			values = appendTime(values, v.(time.Duration))
+		default:
+			values = appendLenEncString(values, textValue(cols[i], v))
+		}
+	}
+	return append(append([]byte{iOK}, nulls...), values...)
+}
+
+// readParams decodes the parameters of a COM_STMT_EXECUTE packet.
+func readParams(p *payload, n int) []interface{} {
+	p.uint8()  // flags
+	p.uint32() // iteration count
+	if n == 0 {
+		return nil
+	}
+
+	nulls := p.next((n + 7) / 8)
+	p.uint8() // new parameters bound
+	types := p.next(2 * n)
+	params := make([]interface{}, n)
+	for i := range params {
+		if nulls[i/8]&(1<<(i%8)) != 0 {
+			continue
+		}
+
+		unsigned := types[2*i+1]&paramUnsigned != 0
+		switch types[2*i] {
+		case typeTiny:
+			params[i] = int64(int8(p.uint8()))
+			if unsigned {
+				params[i] = uint64(uint8(params[i].(int64)))
+			}
+		case typeShort:
+			params[i] = int64(int16(p.uint16()))
+		case typeLong:
+			params[i] = int64(int32(p.uint32()))
+		case typeLongLong:
+			v := p.uint64()
+			params[i] = int64(v)
+			if unsigned {
+				params[i] = v
+			}
+		case typeFloat:
+			params[i] = float64(math.Float32frombits(p.uint32()))
+		case typeDouble:
+			params[i] = math.Float64frombits(p.uint64())
+		case typeDate, typeDatetime:
+			params[i], _ = readDatetime(p)
+		case typeTime:
+			params[i] = readTime(p)
+		default:
+			v, _ := p.lenEncBytes()
+			params[i] = string(v)
+		}
+	}
+	return params
+}
+
+func intCol(name string, typ byte, unsigned bool) column {
+	col := column{name: name, typ: typ, charset: charsetBinary, length: 20}
+	if unsigned {
+		col.flags |= flagUnsigned
+	}
+	return col
+}
@@ -0,0 +1,485 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+type statement struct {
+	alloc     memory.Allocator
+	cnxn      *cnxn
+	batchSize int
+
+	query    string
+	prepared *serverStmt
+
+	targetTable   string
+	targetCatalog string
+	targetSchema  string
+	ingestMode    string
+	temporary     bool
+
+	bound      arrow.Record
+	streamBind array.RecordReader
+}
+
+func (st *statement) clearBinds() {
+	if st.bound != nil {
+		st.bound.Release()
+		st.bound = nil
+	} else if st.streamBind != nil {
+		st.streamBind.Release()
+		st.streamBind = nil
+	}
+}
+
+func (st *statement) closePrepared() error {
+	if st.prepared == nil {
+		return nil
+	}
+	defer func() { st.prepared = nil }()
+	return errToAdbcErr(adbc.StatusIO, st.cnxn.closeStmt(st.prepared))
+}
+
+// Close releases any relevant resources associated with this statement
+// and closes it (particularly if it is a prepared statement).
+//
+// A statement instance should not be used after Close is called.
+func (st *statement) Close() error {
+	if st.cnxn == nil {
+		return adbc.Error{
+			Msg:  "statement already closed",
+			Code: adbc.StatusInvalidState}
+	}
+
+	st.clearBinds()
+	err := st.closePrepared()
+	st.cnxn = nil
+	return err
+}
+
+// SetOption sets a string option on this statement
+func (st *statement) SetOption(key string, val string) error {
+	switch key {
+	case adbc.OptionKeyIngestTargetTable:
+		st.query = ""
+		st.targetTable = val
+		return st.closePrepared()
+	case adbc.OptionKeyIngestTargetCatalog:
+		st.targetCatalog = val
+	case adbc.OptionKeyIngestTargetDBSchema:
+		st.targetSchema = val
+	case adbc.OptionKeyIngestTemporary:
+		switch val {
+		case adbc.OptionValueEnabled:
+			st.temporary = true
+		case adbc.OptionValueDisabled:
+			st.temporary = false
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case adbc.OptionKeyIngestMode:
+		switch val {
+		case adbc.OptionValueIngestModeAppend,
+			adbc.OptionValueIngestModeCreate,
+			adbc.OptionValueIngestModeReplace,
+			adbc.OptionValueIngestModeCreateAppend:
+			st.ingestMode = val
+		case adbc.OptionValueIngestModeMerge:
+			return adbc.Error{
+				Msg:  "[MySQL] merge ingestion is not supported",
+				Code: adbc.StatusNotImplemented,
+			}
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case adbc.OptionKeyIngestKeyColumns:
+		return adbc.Error{
+			Msg:  "[MySQL] merge ingestion is not supported",
+			Code: adbc.StatusNotImplemented,
+		}
+	case OptionBatchSize:
+		size, err := parseBatchSize(val)
+		if err != nil {
+			return err
+		}
+		st.batchSize = size
+	default:
+		return adbc.Error{
+			Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+	return nil
+}
+
+// SetSqlQuery sets the query string to be executed, discarding any
+// previously prepared statement and ingestion target.
+func (st *statement) SetSqlQuery(query string) error {
+	st.query = query
+	st.targetTable = ""
+	return st.closePrepared()
+}
+
+// Prepare prepares the query on the server, after which it is executed
+// with the binary protocol and its parameter schema is known.
+func (st *statement) Prepare(ctx context.Context) error {
+	if st.query == "" {
+		return adbc.Error{
+			Code: adbc.StatusInvalidState,
+			Msg:  "cannot prepare statement with no query",
+		}
+	}
+
+	if err := st.closePrepared(); err != nil {
+		return err
+	}
+
+	stmt, err := st.cnxn.prepare(ctx, st.query)
+	if err != nil {
+		return errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+	st.prepared = stmt
+	return nil
+}
+
+// preparedStmt returns the statement to execute bound parameters with,
+// preparing the query for just this execution if Prepare wasn't called.
+// The returned function must be called once the statement is done with.
+func (st *statement) preparedStmt(ctx context.Context) (*serverStmt, func(), error) {
+	if st.prepared != nil {
+		return st.prepared, func() {}, nil
+	}
+
+	stmt, err := st.cnxn.prepare(ctx, st.query)
+	if err != nil {
+		return nil, nil, errToAdbcErr(adbc.StatusInvalidArgument, err)
+	}
+	return stmt, func() { st.cnxn.closeStmt(stmt) }, nil
+}
+
+// params returns an iterator over the bound parameters. A bound stream
+// can only be consumed once, so the iterator takes it over from the
+// statement and releases it once it is exhausted.
+func (st *statement) params() *paramRows {
+	if st.bound != nil {
+		return &paramRows{rec: st.bound}
+	}
+
+	p := &paramRows{stream: st.streamBind}
+	st.streamBind = nil
+	return p
+}
+
+// paramRows iterates over the rows of the bound parameters.
+type paramRows struct {
+	rec    arrow.Record
+	stream array.RecordReader
+	row    int
+
+	err error
+}
+
+func (p *paramRows) next() bool {
+	for p.rec == nil || p.row >= int(p.rec.NumRows()) {
+		if p.stream == nil {
+			return false
+		}
+		if !p.stream.Next() {
+			p.err = p.stream.Err()
+			p.release()
+			return false
+		}
+		p.rec, p.row = p.stream.Record(), 0
+	}
+
+	p.row++
+	return true
+}
+
+// values returns the parameters of the current row for stmt.
+func (p *paramRows) values(stmt *serverStmt) (paramFunc, error) {
+	if int(p.rec.NumCols()) != len(stmt.params) {
+		return nil, adbc.Error{
+			Msg:  fmt.Sprintf("[MySQL] statement has %d parameters but %d columns are bound", len(stmt.params), p.rec.NumCols()),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	rec, row := p.rec, p.row-1
+	return func(b []byte, i int) (byte, byte, []byte, error) {
+		return appendParam(b, rec.Column(i), row)
+	}, nil
+}
+
+func (p *paramRows) release() {
+	if p.stream != nil {
+		p.stream.Release()
+		p.stream = nil
+	}
+}
+
+// execute runs the statement with the parameters of the current row,
+// returning its result set or nil if it didn't return rows.
+func (p *paramRows) execute(ctx context.Context, c *cnxn, stmt *serverStmt) (okPacket, *resultSet, error) {
+	params, err := p.values(stmt)
+	if err != nil {
+		return okPacket{}, nil, err
+	}
+	return c.execute(ctx, stmt, params)
+}
+
+func (st *statement) hasBinds() bool {
+	return st.bound != nil || st.streamBind != nil
+}
+
+func (st *statement) checkQuery() error {
+	if st.query == "" {
+		return adbc.Error{
+			Msg:  "cannot execute without a query",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	return nil
+}
+
+// noParams fails the execution of a statement which has parameters
+// without any being bound.
+func noParams(b []byte, i int) (byte, byte, []byte, error) {
+	return 0, 0, nil, adbc.Error{
+		Msg:  "[MySQL] statement has parameters but none are bound",
+		Code: adbc.StatusInvalidState,
+	}
+}
+
+// ExecuteQuery executes the current query or prepared statement
+// and returns a RecordReader for the results. The number of rows is
+// not known in advance and is reported as -1, while for statements
+// which don't return rows it is the number of rows affected.
+//
+// With bound parameters the statement is executed once per bound row
+// and the result sets are read one after another, as they would be
+// for a single query.
+func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	if st.targetTable != "" {
+		n, err := st.executeIngest(ctx)
+		return nil, n, err
+	}
+	if err := st.checkQuery(); err != nil {
+		return nil, -1, err
+	}
+
+	if !st.hasBinds() {
+		var (
+			ok  okPacket
+			rs  *resultSet
+			err error
+		)
+		if st.prepared != nil {
+			ok, rs, err = st.cnxn.execute(ctx, st.prepared, noParams)
+		} else {
+			ok, rs, err = st.cnxn.query(ctx, st.query)
+		}
+		if err != nil {
+			return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+
+		n := int64(-1)
+		if rs == nil {
+			n = int64(ok.affectedRows)
+		}
+		return newRecordReader(st.alloc, rs, nil, nil, st.batchSize), n, nil
+	}
+
+	stmt, closeStmt, err := st.preparedStmt(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	params := st.params()
+	done := func() {
+		params.release()
+		closeStmt()
+	}
+	// executions without a result set are skipped
+	next := func() (*resultSet, error) {
+		for params.next() {
+			_, rs, err := params.execute(ctx, st.cnxn, stmt)
+			if err != nil || rs != nil {
+				return rs, err
+			}
+		}
+		return nil, params.err
+	}
+
+	if params.rec == nil && params.stream == nil {
+		done()
+		return nil, -1, adbc.Error{
+			Msg:  "cannot execute query without any rows of bound parameters",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	rs, err := next()
+	if err != nil {
+		done()
+		return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	return newRecordReader(st.alloc, rs, next, done, st.batchSize), -1, nil
+}
+
+// ExecuteUpdate executes a statement that does not generate a result
+// set, once per bound row if parameters are bound, and returns the
+// total number of rows affected.
+func (st *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	if st.targetTable != "" {
+		return st.executeIngest(ctx)
+	}
+	if err := st.checkQuery(); err != nil {
+		return -1, err
+	}
+
+	if !st.hasBinds() {
+		var (
+			ok  okPacket
+			err error
+		)
+		if st.prepared != nil {
+			var rs *resultSet
+			if ok, rs, err = st.cnxn.execute(ctx, st.prepared, noParams); rs != nil {
+				err = rs.discard()
+			}
+		} else {
+			ok, err = st.cnxn.exec(ctx, st.query)
+		}
+		if err != nil {
+			return -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+		return int64(ok.affectedRows), nil
+	}
+
+	stmt, closeStmt, err := st.preparedStmt(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer closeStmt()
+
+	params := st.params()
+	defer params.release()
+
+	var total int64
+	for params.next() {
+		ok, rs, err := params.execute(ctx, st.cnxn, stmt)
+		if rs != nil {
+			err = rs.discard()
+		}
+		if err != nil {
+			return -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+		total += int64(ok.affectedRows)
+	}
+	if params.err != nil {
+		return -1, errToAdbcErr(adbc.StatusInvalidData, params.err)
+	}
+	return total, nil
+}
+
+// SetSubstraitPlan is not supported as MySQL only accepts SQL.
+func (st *statement) SetSubstraitPlan(plan []byte) error {
+	return adbc.Error{
+		Msg:  "[MySQL] Substrait plans are not supported",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// Bind uses an arrow record batch to bind parameters to the query.
+//
+// The statement is executed once for each row, with the columns of
+// the record as its positional parameters. The record is kept so the
+// statement can be executed again with the same parameters. For bulk
+// ingestion the record is the data to ingest.
+func (st *statement) Bind(_ context.Context, values arrow.Record) error {
+	st.clearBinds()
+
+	st.bound = values
+	if st.bound != nil {
+		st.bound.Retain()
+	}
+	return nil
+}
+
+// BindStream uses a record batch stream to bind parameters for this
+// query, executing the statement once for each row of the stream.
+//
+// The stream is consumed by the next execution and released once it
+// is exhausted.
+func (st *statement) BindStream(_ context.Context, stream array.RecordReader) error {
+	st.clearBinds()
+
+	st.streamBind = stream
+	if st.streamBind != nil {
+		st.streamBind.Retain()
+	}
+	return nil
+}
+
+// GetParameterSchema returns the parameters of a prepared statement,
+// with the types reported by the server. Servers describe parameters
+// whose type isn't implied by the query in different ways, often as
+// strings, and those of unknown type are of the null type.
+func (st *statement) GetParameterSchema() (*arrow.Schema, error) {
+	if st.prepared == nil {
+		return nil, adbc.Error{
+			Msg:  "[MySQL] the statement must be prepared to get its parameter schema",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	fields := make([]arrow.Field, len(st.prepared.params))
+	for i := range st.prepared.params {
+		fields[i] = st.prepared.params[i].field()
+		fields[i].Nullable = true
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// ExecutePartitions is not supported as MySQL has no notion of
+// partitioned results.
+func (st *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	return nil, adbc.Partitions{}, -1, adbc.Error{
+		Msg:  "[MySQL] ExecutePartitions not supported",
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+var (
+	_ adbc.PostInitOptions = (*statement)(nil)
+)
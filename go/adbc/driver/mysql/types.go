@@ -0,0 +1,863 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/decimal256"
+)
+
+// column types of the protocol
+const (
+	typeDecimal    byte = 0x00
+	typeTiny       byte = 0x01
+	typeShort      byte = 0x02
+	typeLong       byte = 0x03
+	typeFloat      byte = 0x04
+	typeDouble     byte = 0x05
+	typeNull       byte = 0x06
+	typeTimestamp  byte = 0x07
+	typeLongLong   byte = 0x08
+	typeInt24      byte = 0x09
+	typeDate       byte = 0x0a
+	typeTime       byte = 0x0b
+	typeDatetime   byte = 0x0c
+	typeYear       byte = 0x0d
+	typeNewDate    byte = 0x0e
+	typeVarchar    byte = 0x0f
+	typeBit        byte = 0x10
+	typeJSON       byte = 0xf5
+	typeNewDecimal byte = 0xf6
+	typeEnum       byte = 0xf7
+	typeSet        byte = 0xf8
+	typeTinyBlob   byte = 0xf9
+	typeMediumBlob byte = 0xfa
+	typeLongBlob   byte = 0xfb
+	typeBlob       byte = 0xfc
+	typeVarString  byte = 0xfd
+	typeString     byte = 0xfe
+	typeGeometry   byte = 0xff
+
+	flagNotNull  uint16 = 0x0001
+	flagUnsigned uint16 = 0x0020
+	flagEnum     uint16 = 0x0100
+	flagSet      uint16 = 0x0800
+
+	// the character set of binary strings
+	charsetBinary = 63
+	// the parameter type flag for unsigned integers
+	paramUnsigned byte = 0x80
+
+	maxDecimalPrecision = 65
+)
+
+// column is a column definition of a result set or a parameter of a
+// prepared statement
+type column struct {
+	schema   string
+	table    string
+	name     string
+	charset  uint16
+	length   uint32
+	typ      byte
+	flags    uint16
+	decimals byte
+}
+
+func parseColumn(data []byte) (column, error) {
+	p := payload{buf: data}
+	p.lenEncString() // catalog, always "def"
+	var col column
+	col.schema = p.lenEncString()
+	col.table = p.lenEncString()
+	p.lenEncString() // original table
+	col.name = p.lenEncString()
+	p.lenEncString() // original name
+	p.lenEncInt()    // length of the fixed length fields
+	col.charset = p.uint16()
+	col.length = p.uint32()
+	col.typ = p.uint8()
+	col.flags = p.uint16()
+	col.decimals = p.uint8()
+	return col, p.err
+}
+
+func (c *column) unsigned() bool { return c.flags&flagUnsigned != 0 }
+
+func (c *column) decimalType() arrow.DataType {
+	// the length counts the sign and the decimal point
+	prec := int32(c.length)
+	if !c.unsigned() {
+		prec--
+	}
+	if c.decimals > 0 {
+		prec--
+	}
+	scale := int32(c.decimals)
+	if prec < scale {
+		prec = scale
+	}
+	if prec < 1 {
+		prec = 1
+	}
+	if prec > maxDecimalPrecision {
+		prec = maxDecimalPrecision
+	}
+
+	if prec <= 38 {
+		return &arrow.Decimal128Type{Precision: prec, Scale: scale}
+	}
+	return &arrow.Decimal256Type{Precision: prec, Scale: scale}
+}
+
+// arrowType returns the Arrow type values of the column are decoded
+// into. As with JDBC, TINYINT(1) is taken to be a boolean. TIME is an
+// elapsed time which may be negative or exceed a day, so it becomes a
+// duration rather than a time of day.
+func (c *column) arrowType() arrow.DataType {
+	unsigned := c.unsigned()
+	switch c.typ {
+	case typeTiny:
+		switch {
+		case c.length == 1 && !unsigned:
+			return arrow.FixedWidthTypes.Boolean
+		case unsigned:
+			return arrow.PrimitiveTypes.Uint8
+		}
+		return arrow.PrimitiveTypes.Int8
+	case typeShort:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint16
+		}
+		return arrow.PrimitiveTypes.Int16
+	case typeYear:
+		return arrow.PrimitiveTypes.Int16
+	case typeInt24, typeLong:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint32
+		}
+		return arrow.PrimitiveTypes.Int32
+	case typeLongLong:
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint64
+		}
+		return arrow.PrimitiveTypes.Int64
+	case typeFloat:
+		return arrow.PrimitiveTypes.Float32
+	case typeDouble:
+		return arrow.PrimitiveTypes.Float64
+	case typeDecimal, typeNewDecimal:
+		return c.decimalType()
+	case typeDate, typeNewDate:
+		return arrow.FixedWidthTypes.Date32
+	case typeTime:
+		return arrow.FixedWidthTypes.Duration_us
+	case typeDatetime, typeTimestamp:
+		return &arrow.TimestampType{Unit: arrow.Microsecond}
+	case typeBit:
+		return arrow.PrimitiveTypes.Uint64
+	case typeNull:
+		return arrow.Null
+	case typeJSON, typeEnum, typeSet:
+		return arrow.BinaryTypes.String
+	case typeGeometry:
+		return arrow.BinaryTypes.Binary
+	}
+
+	if c.charset == charsetBinary {
+		return arrow.BinaryTypes.Binary
+	}
+	return arrow.BinaryTypes.String
+}
+
+// typeName returns the SQL name of the column's type.
+func (c *column) typeName() string {
+	var name string
+	switch c.typ {
+	case typeTiny:
+		name = "TINYINT"
+	case typeShort:
+		name = "SMALLINT"
+	case typeInt24:
+		name = "MEDIUMINT"
+	case typeLong:
+		name = "INT"
+	case typeLongLong:
+		name = "BIGINT"
+	case typeFloat:
+		name = "FLOAT"
+	case typeDouble:
+		name = "DOUBLE"
+	case typeDecimal, typeNewDecimal:
+		name = "DECIMAL"
+	case typeDate, typeNewDate:
+		return "DATE"
+	case typeTime:
+		return "TIME"
+	case typeDatetime:
+		return "DATETIME"
+	case typeTimestamp:
+		return "TIMESTAMP"
+	case typeYear:
+		return "YEAR"
+	case typeBit:
+		return "BIT"
+	case typeNull:
+		return "NULL"
+	case typeJSON:
+		return "JSON"
+	case typeGeometry:
+		return "GEOMETRY"
+	case typeEnum:
+		return "ENUM"
+	case typeSet:
+		return "SET"
+	case typeTinyBlob, typeMediumBlob, typeLongBlob, typeBlob:
+		if c.charset == charsetBinary {
+			return "BLOB"
+		}
+		return "TEXT"
+	case typeVarchar, typeVarString:
+		switch {
+		case c.flags&flagEnum != 0:
+			return "ENUM"
+		case c.flags&flagSet != 0:
+			return "SET"
+		case c.charset == charsetBinary:
+			return "VARBINARY"
+		}
+		return "VARCHAR"
+	case typeString:
+		switch {
+		case c.flags&flagEnum != 0:
+			return "ENUM"
+		case c.flags&flagSet != 0:
+			return "SET"
+		case c.charset == charsetBinary:
+			return "BINARY"
+		}
+		return "CHAR"
+	default:
+		return fmt.Sprintf("UNKNOWN(%d)", c.typ)
+	}
+
+	if c.unsigned() {
+		name += " UNSIGNED"
+	}
+	return name
+}
+
+func (c *column) field() arrow.Field {
+	return arrow.Field{
+		Name:     c.name,
+		Type:     c.arrowType(),
+		Nullable: c.flags&flagNotNull == 0,
+	}
+}
+
+func schemaFromColumns(cols []column) *arrow.Schema {
+	fields := make([]arrow.Field, len(cols))
+	for i := range cols {
+		fields[i] = cols[i].field()
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// valueDecoder appends the value of a column from a row to its builder.
+// Binary protocol rows are read from the packet directly, while the text
+// protocol provides each value as a string.
+type valueDecoder struct {
+	binary func(p *payload) error
+	text   func(v []byte) error
+}
+
+func invalidValue(col *column, v []byte, err error) error {
+	return adbc.Error{
+		Msg:  fmt.Sprintf("[MySQL] invalid value '%s' for column %s of type %s: %s", v, col.name, col.typeName(), err),
+		Code: adbc.StatusInvalidData,
+	}
+}
+
+func newValueDecoder(col *column, bldr array.Builder) valueDecoder {
+	// integers are sent as strings in the text protocol
+	parseInt := func(v []byte, bits int) (int64, error) {
+		n, err := strconv.ParseInt(string(v), 10, bits)
+		if err != nil {
+			return 0, invalidValue(col, v, err)
+		}
+		return n, nil
+	}
+	parseUint := func(v []byte, bits int) (uint64, error) {
+		n, err := strconv.ParseUint(string(v), 10, bits)
+		if err != nil {
+			return 0, invalidValue(col, v, err)
+		}
+		return n, nil
+	}
+
+	switch b := bldr.(type) {
+	case *array.NullBuilder:
+		return valueDecoder{
+			binary: func(*payload) error { b.AppendNull(); return nil },
+			text:   func([]byte) error { b.AppendNull(); return nil },
+		}
+	case *array.BooleanBuilder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(p.uint8() != 0); return nil },
+			text: func(v []byte) error {
+				n, err := parseInt(v, 8)
+				b.Append(n != 0)
+				return err
+			},
+		}
+	case *array.Int8Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(int8(p.uint8())); return nil },
+			text: func(v []byte) error {
+				n, err := parseInt(v, 8)
+				b.Append(int8(n))
+				return err
+			},
+		}
+	case *array.Uint8Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(p.uint8()); return nil },
+			text: func(v []byte) error {
+				n, err := parseUint(v, 8)
+				b.Append(uint8(n))
+				return err
+			},
+		}
+	case *array.Int16Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(int16(p.uint16())); return nil },
+			text: func(v []byte) error {
+				n, err := parseInt(v, 16)
+				b.Append(int16(n))
+				return err
+			},
+		}
+	case *array.Uint16Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(p.uint16()); return nil },
+			text: func(v []byte) error {
+				n, err := parseUint(v, 16)
+				b.Append(uint16(n))
+				return err
+			},
+		}
+	case *array.Int32Builder:
+		return valueDecoder{
+			// MEDIUMINT is sent as four bytes too
+			binary: func(p *payload) error { b.Append(int32(p.uint32())); return nil },
+			text: func(v []byte) error {
+				n, err := parseInt(v, 32)
+				b.Append(int32(n))
+				return err
+			},
+		}
+	case *array.Uint32Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(p.uint32()); return nil },
+			text: func(v []byte) error {
+				n, err := parseUint(v, 32)
+				b.Append(uint32(n))
+				return err
+			},
+		}
+	case *array.Int64Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(int64(p.uint64())); return nil },
+			text: func(v []byte) error {
+				n, err := parseInt(v, 64)
+				b.Append(n)
+				return err
+			},
+		}
+	case *array.Uint64Builder:
+		if col.typ == typeBit {
+			// BIT values are big endian byte strings in both protocols
+			appendBits := func(v []byte) error {
+				if len(v) > 8 {
+					return invalidValue(col, v, fmt.Errorf("more than 64 bits"))
+				}
+				var n uint64
+				for _, c := range v {
+					n = n<<8 | uint64(c)
+				}
+				b.Append(n)
+				return nil
+			}
+			return valueDecoder{
+				binary: func(p *payload) error {
+					v, _ := p.lenEncBytes()
+					return appendBits(v)
+				},
+				text: appendBits,
+			}
+		}
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(p.uint64()); return nil },
+			text: func(v []byte) error {
+				n, err := parseUint(v, 64)
+				b.Append(n)
+				return err
+			},
+		}
+	case *array.Float32Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(math.Float32frombits(p.uint32())); return nil },
+			text: func(v []byte) error {
+				f, err := strconv.ParseFloat(string(v), 32)
+				if err != nil {
+					return invalidValue(col, v, err)
+				}
+				b.Append(float32(f))
+				return nil
+			},
+		}
+	case *array.Float64Builder:
+		return valueDecoder{
+			binary: func(p *payload) error { b.Append(math.Float64frombits(p.uint64())); return nil },
+			text: func(v []byte) error {
+				f, err := strconv.ParseFloat(string(v), 64)
+				if err != nil {
+					return invalidValue(col, v, err)
+				}
+				b.Append(f)
+				return nil
+			},
+		}
+	case *array.Decimal128Builder:
+		scale := b.Type().(*arrow.Decimal128Type).Scale
+		appendDecimal := func(v []byte) error {
+			n, err := parseDecimal(v, scale)
+			if err != nil || n.BitLen() > 127 {
+				return invalidValue(col, v, fmt.Errorf("not a decimal of scale %d", scale))
+			}
+			b.Append(decimal128.FromBigInt(n))
+			return nil
+		}
+		return valueDecoder{
+			binary: func(p *payload) error {
+				v, _ := p.lenEncBytes()
+				return appendDecimal(v)
+			},
+			text: appendDecimal,
+		}
+	case *array.Decimal256Builder:
+		scale := b.Type().(*arrow.Decimal256Type).Scale
+		appendDecimal := func(v []byte) error {
+			n, err := parseDecimal(v, scale)
+			if err != nil || n.BitLen() > 255 {
+				return invalidValue(col, v, fmt.Errorf("not a decimal of scale %d", scale))
+			}
+			b.Append(decimal256.FromBigInt(n))
+			return nil
+		}
+		return valueDecoder{
+			binary: func(p *payload) error {
+				v, _ := p.lenEncBytes()
+				return appendDecimal(v)
+			},
+			text: appendDecimal,
+		}
+	case *array.Date32Builder:
+		return valueDecoder{
+			binary: func(p *payload) error {
+				if t, ok := readDatetime(p); ok {
+					b.Append(arrow.Date32FromTime(t))
+				} else {
+					b.AppendNull()
+				}
+				return nil
+			},
+			text: func(v []byte) error {
+				t, ok, err := parseDatetime(v)
+				switch {
+				case err != nil:
+					return invalidValue(col, v, err)
+				case ok:
+					b.Append(arrow.Date32FromTime(t))
+				default:
+					b.AppendNull()
+				}
+				return nil
+			},
+		}
+	case *array.TimestampBuilder:
+		return valueDecoder{
+			binary: func(p *payload) error {
+				if t, ok := readDatetime(p); ok {
+					b.Append(arrow.Timestamp(t.UnixMicro()))
+				} else {
+					b.AppendNull()
+				}
+				return nil
+			},
+			text: func(v []byte) error {
+				t, ok, err := parseDatetime(v)
+				switch {
+				case err != nil:
+					return invalidValue(col, v, err)
+				case ok:
+					b.Append(arrow.Timestamp(t.UnixMicro()))
+				default:
+					b.AppendNull()
+				}
+				return nil
+			},
+		}
+	case *array.DurationBuilder:
+		return valueDecoder{
+			binary: func(p *payload) error {
+				b.Append(arrow.Duration(readTime(p).Microseconds()))
+				return nil
+			},
+			text: func(v []byte) error {
+				d, err := parseTime(v)
+				if err != nil {
+					return invalidValue(col, v, err)
+				}
+				b.Append(arrow.Duration(d.Microseconds()))
+				return nil
+			},
+		}
+	case *array.StringBuilder:
+		return valueDecoder{
+			binary: func(p *payload) error {
+				v, _ := p.lenEncBytes()
+				b.BinaryBuilder.Append(v)
+				return nil
+			},
+			text: func(v []byte) error { b.BinaryBuilder.Append(v); return nil },
+		}
+	case *array.BinaryBuilder:
+		return valueDecoder{
+			binary: func(p *payload) error {
+				v, _ := p.lenEncBytes()
+				b.Append(v)
+				return nil
+			},
+			text: func(v []byte) error { b.Append(v); return nil },
+		}
+	}
+
+	panic(fmt.Sprintf("mysql: no decoder for %s", bldr.Type()))
+}
+
+// parseDecimal parses a decimal string into an integer scaled by 10^scale
+// without going through floating point.
+func parseDecimal(v []byte, scale int32) (*big.Int, error) {
+	s := string(v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+
+	intPart, fracPart := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		intPart, fracPart = s[:dot], s[dot+1:]
+	}
+	if len(fracPart) > int(scale) {
+		return nil, fmt.Errorf("too many digits")
+	}
+	digits := intPart + fracPart + strings.Repeat("0", int(scale)-len(fracPart))
+
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("not a number")
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// readDatetime reads a binary DATE, DATETIME or TIMESTAMP value, which
+// is not ok for the zero date and dates with a zero month or day.
+func readDatetime(p *payload) (t time.Time, ok bool) {
+	n := p.uint8()
+	if n < 4 {
+		return
+	}
+	year, month, day := int(p.uint16()), int(p.uint8()), int(p.uint8())
+	var hour, min, sec, usec int
+	if n >= 7 {
+		hour, min, sec = int(p.uint8()), int(p.uint8()), int(p.uint8())
+	}
+	if n >= 11 {
+		usec = int(p.uint32())
+	}
+	if month == 0 || day == 0 {
+		return
+	}
+	return time.Date(year, time.Month(month), day, hour, min, sec, usec*1000, time.UTC), true
+}
+
+// parseDatetime parses a text DATE, DATETIME or TIMESTAMP value, which
+// is not ok for the zero date and dates with a zero month or day.
+func parseDatetime(v []byte) (t time.Time, ok bool, err error) {
+	s := string(v)
+	if len(s) >= 10 && (s[5:7] == "00" || s[8:10] == "00") {
+		return t, false, nil
+	}
+
+	layout := "2006-01-02"
+	if len(s) > 10 {
+		layout = "2006-01-02 15:04:05.999999"
+	}
+	t, err = time.Parse(layout, s)
+	return t, err == nil, err
+}
+
+// readTime reads a binary TIME value.
+func readTime(p *payload) time.Duration {
+	n := p.uint8()
+	if n < 8 {
+		return 0
+	}
+	neg := p.uint8() == 1
+	days, hour, min, sec := time.Duration(p.uint32()), time.Duration(p.uint8()), time.Duration(p.uint8()), time.Duration(p.uint8())
+	var usec time.Duration
+	if n >= 12 {
+		usec = time.Duration(p.uint32())
+	}
+
+	d := days*24*time.Hour + hour*time.Hour + min*time.Minute + sec*time.Second + usec*time.Microsecond
+	if neg {
+		return -d
+	}
+	return d
+}
+
+// parseTime parses a text TIME value, [-]HHH:MM:SS[.ffffff].
+func parseTime(v []byte) (time.Duration, error) {
+	s := string(v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var frac string
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		s, frac = s[:dot], s[dot+1:]
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || len(frac) > 6 {
+		return 0, fmt.Errorf("not a time")
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.ParseUint(parts[i], 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	if frac != "" {
+		n, err := strconv.ParseUint(frac+strings.Repeat("0", 6-len(frac)), 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * time.Microsecond
+	}
+
+	if neg {
+		return -d, nil
+	}
+	return d, nil
+}
+
+func appendDatetime(b []byte, t time.Time) []byte {
+	b = append(b, 11)
+	b = appendUint16(b, uint16(t.Year()))
+	b = append(b, byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second()))
+	return appendUint32(b, uint32(t.Nanosecond()/1000))
+}
+
+func appendTime(b []byte, d time.Duration) []byte {
+	neg := byte(0)
+	if d < 0 {
+		neg, d = 1, -d
+	}
+	b = append(b, 12, neg)
+	b = appendUint32(b, uint32(d/(24*time.Hour)))
+	b = append(b, byte(d/time.Hour%24), byte(d/time.Minute%60), byte(d/time.Second%60))
+	return appendUint32(b, uint32(d%time.Second/time.Microsecond))
+}
+
+// appendParam appends the value of row i of arr to the values of a
+// COM_STMT_EXECUTE packet, returning the parameter type to send along
+// with it. Nested types are sent as JSON strings.
+func appendParam(b []byte, arr arrow.Array, i int) (typ byte, flags byte, out []byte, err error) {
+	if arr.IsNull(i) {
+		return typeNull, 0, b, nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Boolean:
+		v := byte(0)
+		if a.Value(i) {
+			v = 1
+		}
+		return typeTiny, 0, append(b, v), nil
+	case *array.Int8:
+		return typeTiny, 0, append(b, byte(a.Value(i))), nil
+	case *array.Uint8:
+		return typeTiny, paramUnsigned, append(b, a.Value(i)), nil
+	case *array.Int16:
+		return typeShort, 0, appendUint16(b, uint16(a.Value(i))), nil
+	case *array.Uint16:
+		return typeShort, paramUnsigned, appendUint16(b, a.Value(i)), nil
+	case *array.Int32:
+		return typeLong, 0, appendUint32(b, uint32(a.Value(i))), nil
+	case *array.Uint32:
+		return typeLong, paramUnsigned, appendUint32(b, a.Value(i)), nil
+	case *array.Int64:
+		return typeLongLong, 0, appendUint64(b, uint64(a.Value(i))), nil
+	case *array.Uint64:
+		return typeLongLong, paramUnsigned, appendUint64(b, a.Value(i)), nil
+	case *array.Float16:
+		return typeFloat, 0, appendUint32(b, math.Float32bits(a.Value(i).Float32())), nil
+	case *array.Float32:
+		return typeFloat, 0, appendUint32(b, math.Float32bits(a.Value(i))), nil
+	case *array.Float64:
+		return typeDouble, 0, appendUint64(b, math.Float64bits(a.Value(i))), nil
+	case *array.String:
+		return typeVarString, 0, appendLenEncString(b, a.Value(i)), nil
+	case *array.LargeString:
+		return typeVarString, 0, appendLenEncString(b, a.Value(i)), nil
+	case *array.Binary:
+		return typeBlob, 0, appendLenEncString(b, string(a.Value(i))), nil
+	case *array.LargeBinary:
+		return typeBlob, 0, appendLenEncString(b, string(a.Value(i))), nil
+	case *array.FixedSizeBinary:
+		return typeBlob, 0, appendLenEncString(b, string(a.Value(i))), nil
+	case *array.Date32:
+		t := a.Value(i).ToTime()
+		return typeDate, 0, append(appendUint16(append(b, 4), uint16(t.Year())), byte(t.Month()), byte(t.Day())), nil
+	case *array.Date64:
+		t := a.Value(i).ToTime()
+		return typeDate, 0, append(appendUint16(append(b, 4), uint16(t.Year())), byte(t.Month()), byte(t.Day())), nil
+	case *array.Timestamp:
+		// timestamps with a time zone are sent as UTC
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return typeDatetime, 0, appendDatetime(b, a.Value(i).ToTime(unit).UTC()), nil
+	case *array.Time32:
+		unit := a.DataType().(*arrow.Time32Type).Unit
+		return typeTime, 0, appendTime(b, time.Duration(a.Value(i))*unit.Multiplier()), nil
+	case *array.Time64:
+		unit := a.DataType().(*arrow.Time64Type).Unit
+		return typeTime, 0, appendTime(b, time.Duration(a.Value(i))*unit.Multiplier()), nil
+	case *array.Duration:
+		unit := a.DataType().(*arrow.DurationType).Unit
+		return typeTime, 0, appendTime(b, time.Duration(a.Value(i))*unit.Multiplier()), nil
+	case *array.Decimal128:
+		scale := a.DataType().(*arrow.Decimal128Type).Scale
+		return typeNewDecimal, 0, appendLenEncString(b, a.Value(i).ToString(scale)), nil
+	case *array.Decimal256:
+		scale := a.DataType().(*arrow.Decimal256Type).Scale
+		return typeNewDecimal, 0, appendLenEncString(b, a.Value(i).ToString(scale)), nil
+	case *array.Dictionary:
+		return appendParam(b, a.Dictionary(), a.GetValueIndex(i))
+	case array.ExtensionArray:
+		return appendParam(b, a.Storage(), i)
+	case array.ListLike, *array.Struct:
+		v, err := json.Marshal(arr.GetOneForMarshal(i))
+		if err != nil {
+			return 0, 0, nil, adbc.Error{
+				Msg:  "[MySQL] cannot encode parameter as JSON: " + err.Error(),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		return typeVarString, 0, appendLenEncString(b, string(v)), nil
+	}
+
+	return 0, 0, nil, adbc.Error{
+		Msg:  "[MySQL] unsupported parameter type " + arr.DataType().String(),
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// typeFromColumnType returns the Arrow type of a table column described
+// by information_schema.COLUMNS, matching what arrowType decodes its
+// values into.
+func typeFromColumnType(dataType, columnType string, prec, scale int64) arrow.DataType {
+	columnType = strings.ToLower(columnType)
+	unsigned := strings.Contains(columnType, "unsigned")
+	switch strings.ToLower(dataType) {
+	case "tinyint":
+		switch {
+		case strings.HasPrefix(columnType, "tinyint(1)") && !unsigned:
+			return arrow.FixedWidthTypes.Boolean
+		case unsigned:
+			return arrow.PrimitiveTypes.Uint8
+		}
+		return arrow.PrimitiveTypes.Int8
+	case "bool", "boolean":
+		return arrow.FixedWidthTypes.Boolean
+	case "smallint":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint16
+		}
+		return arrow.PrimitiveTypes.Int16
+	case "year":
+		return arrow.PrimitiveTypes.Int16
+	case "mediumint", "int", "integer":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint32
+		}
+		return arrow.PrimitiveTypes.Int32
+	case "bigint":
+		if unsigned {
+			return arrow.PrimitiveTypes.Uint64
+		}
+		return arrow.PrimitiveTypes.Int64
+	case "float":
+		return arrow.PrimitiveTypes.Float32
+	case "double", "real":
+		return arrow.PrimitiveTypes.Float64
+	case "decimal", "numeric":
+		if prec > 38 {
+			return &arrow.Decimal256Type{Precision: int32(prec), Scale: int32(scale)}
+		}
+		return &arrow.Decimal128Type{Precision: int32(prec), Scale: int32(scale)}
+	case "date":
+		return arrow.FixedWidthTypes.Date32
+	case "time":
+		return arrow.FixedWidthTypes.Duration_us
+	case "datetime", "timestamp":
+		return &arrow.TimestampType{Unit: arrow.Microsecond}
+	case "bit":
+		return arrow.PrimitiveTypes.Uint64
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob",
+		"geometry", "point", "linestring", "polygon", "multipoint",
+		"multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		return arrow.BinaryTypes.Binary
+	}
+	return arrow.BinaryTypes.String
+}
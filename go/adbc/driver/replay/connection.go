@@ -0,0 +1,224 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type database struct {
+	drv     *Driver
+	id      string
+	wrapped adbc.Database
+
+	connections int
+}
+
+func (db *database) SetOptions(opts map[string]string) error {
+	return db.drv.call(db.id, "SetOptions", args{Options: db.drv.redactOptions(opts)}, nil, func() outcome {
+		return outcome{err: db.wrapped.SetOptions(opts)}
+	}).err
+}
+
+func (db *database) Open(ctx context.Context) (adbc.Connection, error) {
+	db.drv.mu.Lock()
+	id := fmt.Sprintf("%s.c%d", db.id, db.connections)
+	db.connections++
+	db.drv.mu.Unlock()
+
+	cnxn := &cnxn{drv: db.drv, id: id}
+	o := db.drv.call(db.id, "Open", args{}, nil, func() outcome {
+		var err error
+		cnxn.wrapped, err = db.wrapped.Open(ctx)
+		return outcome{err: err}
+	})
+	if o.err != nil {
+		return nil, o.err
+	}
+	return cnxn, nil
+}
+
+// Close is recorded whether or not the wrapped database implements
+// io.Closer, so that recordings don't depend on it.
+func (db *database) Close() error {
+	return db.drv.call(db.id, "Close", args{}, nil, func() outcome {
+		if closer, ok := db.wrapped.(io.Closer); ok {
+			return outcome{err: closer.Close()}
+		}
+		return outcome{}
+	}).err
+}
+
+type cnxn struct {
+	drv     *Driver
+	id      string
+	wrapped adbc.Connection
+
+	statements int
+}
+
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	o := c.drv.call(c.id, "GetInfo", args{Codes: infoCodes}, nil, func() outcome {
+		rdr, err := c.wrapped.GetInfo(ctx, infoCodes)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (array.RecordReader, error) {
+	a := args{
+		Depth:      &depth,
+		Catalog:    catalog,
+		DBSchema:   dbSchema,
+		TableName:  tableName,
+		ColumnName: columnName,
+		TableTypes: tableType,
+	}
+	o := c.drv.call(c.id, "GetObjects", a, nil, func() outcome {
+		rdr, err := c.wrapped.GetObjects(ctx, depth, catalog, dbSchema, tableName, columnName, tableType)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	a := args{Catalog: catalog, DBSchema: dbSchema, TableName: &tableName}
+	o := c.drv.call(c.id, "GetTableSchema", a, nil, func() outcome {
+		sc, err := c.wrapped.GetTableSchema(ctx, catalog, dbSchema, tableName)
+		return outcome{schema: sc, err: err}
+	})
+	return o.schema, o.err
+}
+
+func (c *cnxn) GetTableTypes(ctx context.Context) (array.RecordReader, error) {
+	o := c.drv.call(c.id, "GetTableTypes", args{}, nil, func() outcome {
+		rdr, err := c.wrapped.GetTableTypes(ctx)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+func (c *cnxn) Commit(ctx context.Context) error {
+	return c.drv.call(c.id, "Commit", args{}, nil, func() outcome {
+		return outcome{err: c.wrapped.Commit(ctx)}
+	}).err
+}
+
+func (c *cnxn) Rollback(ctx context.Context) error {
+	return c.drv.call(c.id, "Rollback", args{}, nil, func() outcome {
+		return outcome{err: c.wrapped.Rollback(ctx)}
+	}).err
+}
+
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	c.drv.mu.Lock()
+	id := fmt.Sprintf("%s.s%d", c.id, c.statements)
+	c.statements++
+	c.drv.mu.Unlock()
+
+	stmt := &statement{drv: c.drv, id: id}
+	o := c.drv.call(c.id, "NewStatement", args{}, nil, func() outcome {
+		var err error
+		stmt.wrapped, err = c.wrapped.NewStatement()
+		return outcome{err: err}
+	})
+	if o.err != nil {
+		return nil, o.err
+	}
+	return stmt, nil
+}
+
+func (c *cnxn) Close() error {
+	return c.drv.call(c.id, "Close", args{}, nil, func() outcome {
+		return outcome{err: c.wrapped.Close()}
+	}).err
+}
+
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	o := c.drv.call(c.id, "ReadPartition", args{Partition: serializedPartition}, nil, func() outcome {
+		rdr, err := c.wrapped.ReadPartition(ctx, serializedPartition)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+// SetOption is forwarded to the wrapped connection, which fails with
+// adbc.StatusNotImplemented if it doesn't support setting options after
+// initialization.
+func (c *cnxn) SetOption(key, value string) error {
+	return c.drv.call(c.id, "SetOption", args{Key: key, Value: c.drv.redactValue(key, value)}, nil, func() outcome {
+		opts, ok := c.wrapped.(adbc.PostInitOptions)
+		if !ok {
+			return outcome{err: adbc.Error{
+				Msg:  "[replay] wrapped connection does not support setting options",
+				Code: adbc.StatusNotImplemented,
+			}}
+		}
+		return outcome{err: opts.SetOption(key, value)}
+	}).err
+}
+
+// GetStatistics is forwarded to the wrapped connection, which fails with
+// adbc.StatusNotImplemented if it doesn't support statistics.
+func (c *cnxn) GetStatistics(ctx context.Context, catalog, dbSchema, tableName *string, approximate bool) (array.RecordReader, error) {
+	a := args{Catalog: catalog, DBSchema: dbSchema, TableName: tableName, Approximate: approximate}
+	o := c.drv.call(c.id, "GetStatistics", a, nil, func() outcome {
+		stats, err := c.statistics()
+		if err != nil {
+			return outcome{err: err}
+		}
+		rdr, err := stats.GetStatistics(ctx, catalog, dbSchema, tableName, approximate)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+func (c *cnxn) GetStatisticNames(ctx context.Context) (array.RecordReader, error) {
+	o := c.drv.call(c.id, "GetStatisticNames", args{}, nil, func() outcome {
+		stats, err := c.statistics()
+		if err != nil {
+			return outcome{err: err}
+		}
+		rdr, err := stats.GetStatisticNames(ctx)
+		return outcome{rdr: rdr, err: err}
+	})
+	return o.rdr, o.err
+}
+
+func (c *cnxn) statistics() (adbc.ConnectionGetStatistics, error) {
+	stats, ok := c.wrapped.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return nil, adbc.Error{
+			Msg:  "[replay] wrapped connection does not support statistics",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+	return stats, nil
+}
+
+var (
+	_ adbc.Connection              = (*cnxn)(nil)
+	_ adbc.PostInitOptions         = (*cnxn)(nil)
+	_ adbc.ConnectionGetStatistics = (*cnxn)(nil)
+)
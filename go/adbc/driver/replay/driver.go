@@ -0,0 +1,621 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package replay is an ADBC driver which records the calls made to
+// another driver, along with their results, and replays them later
+// without the other driver, for running integration tests offline.
+//
+// A recorder proxies every call to the wrapped driver and appends it to
+// a trace file, trace.jsonl, in the recording directory: one JSON
+// object per line, holding the options, queries and other arguments of
+// the call and what it returned. Results and bound parameters are
+// stored next to it as Arrow IPC files.
+//
+//	drv, err := replay.NewRecorder(snowflake.Driver{Alloc: mem}, "testdata/trace", mem)
+//	// ... run the tests against drv ...
+//	err = drv.Close()
+//
+// A replayer serves the recorded results back. Each database,
+// connection, statement and result of ExecuteMulti is identified by the
+// order it was created in, and the calls on it must match the recorded
+// ones in order, or they fail with adbc.StatusInternal. Calls on
+// different objects may be interleaved differently than when recording.
+//
+//	drv, err := replay.NewReplayer("testdata/trace", mem)
+//	// ... run the same tests against drv ...
+//	err = drv.Close() // reports any calls which were not replayed
+//
+// Results are read in full when recording, so a query whose results
+// fail to read records the error of ExecuteQuery instead. The values of
+// the uri and username options, of any option whose key contains
+// password, token, secret or private_key, and of any other options
+// given to NewRecorder are not stored, not even in the messages of
+// recorded errors, and any value matches them when replaying.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+const (
+	traceFile = "trace.jsonl"
+	redacted  = "<redacted>"
+
+	driverObject = "driver"
+)
+
+// call is a line of the trace: a method called on a database,
+// connection or statement, and its outcome
+type call struct {
+	Object string `json:"object"`
+	Method string `json:"method"`
+	Args   args   `json:"args"`
+	Result result `json:"result"`
+}
+
+type args struct {
+	Options     map[string]string `json:"options,omitempty"`
+	Key         string            `json:"key,omitempty"`
+	Value       string            `json:"value,omitempty"`
+	Query       string            `json:"query,omitempty"`
+	Plan        []byte            `json:"plan,omitempty"`
+	Codes       []adbc.InfoCode   `json:"codes,omitempty"`
+	Depth       *adbc.ObjectDepth `json:"depth,omitempty"`
+	Catalog     *string           `json:"catalog,omitempty"`
+	DBSchema    *string           `json:"db_schema,omitempty"`
+	TableName   *string           `json:"table_name,omitempty"`
+	ColumnName  *string           `json:"column_name,omitempty"`
+	TableTypes  []string          `json:"table_types,omitempty"`
+	Approximate bool              `json:"approximate,omitempty"`
+	Partition   []byte            `json:"partition,omitempty"`
+	// Data is the IPC file holding the bound parameters
+	Data string `json:"data,omitempty"`
+}
+
+type result struct {
+	// Rows is the IPC file holding the records read from a result
+	Rows string `json:"rows,omitempty"`
+	// Schema is the IPC file holding a schema result, without records
+	Schema     string           `json:"schema,omitempty"`
+	N          int64            `json:"n,omitempty"`
+	Partitions *adbc.Partitions `json:"partitions,omitempty"`
+	Error      *recordedError   `json:"error,omitempty"`
+}
+
+type recordedError struct {
+	Msg string `json:"msg"`
+	// ADBC is false for errors which were not an adbc.Error
+	ADBC       bool        `json:"adbc"`
+	Code       adbc.Status `json:"code,omitempty"`
+	VendorCode int32       `json:"vendor_code,omitempty"`
+	SqlState   string      `json:"sql_state,omitempty"`
+	// EOF is set for io.EOF, which ends the results of ExecuteMulti
+	EOF bool `json:"eof,omitempty"`
+}
+
+func recordError(err error) *recordedError {
+	if err == io.EOF {
+		return &recordedError{Msg: err.Error(), EOF: true}
+	}
+	var adbcErr adbc.Error
+	if !errors.As(err, &adbcErr) {
+		return &recordedError{Msg: err.Error()}
+	}
+	return &recordedError{
+		Msg:        adbcErr.Msg,
+		ADBC:       true,
+		Code:       adbcErr.Code,
+		VendorCode: adbcErr.VendorCode,
+		SqlState:   strings.TrimRight(string(adbcErr.SqlState[:]), "\x00"),
+	}
+}
+
+func (e *recordedError) err() error {
+	if e.EOF {
+		return io.EOF
+	}
+	if !e.ADBC {
+		return errors.New(e.Msg)
+	}
+	out := adbc.Error{Msg: e.Msg, Code: e.Code, VendorCode: e.VendorCode}
+	copy(out.SqlState[:], e.SqlState)
+	return out
+}
+
+// bound is the data bound to a statement
+type bound struct {
+	schema *arrow.Schema
+	recs   []arrow.Record
+}
+
+// outcome is what a call returned
+type outcome struct {
+	rdr        array.RecordReader
+	schema     *arrow.Schema
+	n          int64
+	partitions *adbc.Partitions
+	err        error
+}
+
+func replayError(format string, args ...interface{}) error {
+	return adbc.Error{
+		Msg:  "[replay] " + fmt.Sprintf(format, args...),
+		Code: adbc.StatusInternal,
+	}
+}
+
+// Driver records the calls made to a wrapped driver, or replays
+// recorded calls.
+type Driver struct {
+	alloc   memory.Allocator
+	dir     string
+	wrapped adbc.Driver
+	redact  map[string]bool
+
+	mu  sync.Mutex
+	seq int
+	// secrets are the redacted values seen when recording, which are
+	// also removed from the messages of recorded errors
+	secrets   map[string]bool
+	databases int
+	closed    bool
+	// trace is written to when recording
+	trace *os.File
+	// queues hold the recorded calls of each object when replaying
+	queues map[string][]call
+}
+
+// NewRecorder returns a driver recording the calls made to the wrapped
+// driver into dir, which is created if needed. A previous recording in
+// dir is removed.
+//
+// The values of the uri and username options, of options whose key
+// contains password, token, secret or private_key, and of the given
+// redacted options are left out of the recording.
+func NewRecorder(wrapped adbc.Driver, dir string, alloc memory.Allocator, redact ...string) (*Driver, error) {
+	if alloc == nil {
+		alloc = memory.DefaultAllocator
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, ioError(err)
+	}
+
+	stale, err := filepath.Glob(filepath.Join(dir, "*.arrow"))
+	if err != nil {
+		return nil, ioError(err)
+	}
+	for _, f := range stale {
+		if err := os.Remove(f); err != nil {
+			return nil, ioError(err)
+		}
+	}
+
+	trace, err := os.Create(filepath.Join(dir, traceFile))
+	if err != nil {
+		return nil, ioError(err)
+	}
+
+	d := &Driver{
+		alloc:   alloc,
+		dir:     dir,
+		wrapped: wrapped,
+		trace:   trace,
+		secrets: make(map[string]bool),
+		redact: map[string]bool{
+			adbc.OptionKeyURI:      true,
+			adbc.OptionKeyUsername: true,
+		},
+	}
+	for _, k := range redact {
+		d.redact[k] = true
+	}
+	return d, nil
+}
+
+// NewReplayer returns a driver replaying the calls recorded in dir.
+func NewReplayer(dir string, alloc memory.Allocator) (*Driver, error) {
+	if alloc == nil {
+		alloc = memory.DefaultAllocator
+	}
+
+	f, err := os.Open(filepath.Join(dir, traceFile))
+	if err != nil {
+		return nil, ioError(err)
+	}
+	defer f.Close()
+
+	d := &Driver{alloc: alloc, dir: dir, queues: make(map[string][]call)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var c call
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			return nil, adbc.Error{
+				Msg:  fmt.Sprintf("[replay] invalid trace %s, line %d: %s", f.Name(), line, err),
+				Code: adbc.StatusInvalidData,
+			}
+		}
+		d.queues[c.Object] = append(d.queues[c.Object], c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ioError(err)
+	}
+	return d, nil
+}
+
+func ioError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return adbc.Error{
+		Msg:  "[replay] " + err.Error(),
+		Code: adbc.StatusIO,
+	}
+}
+
+func (d *Driver) recording() bool { return d.wrapped != nil }
+
+// Close finishes a recording, or reports the first object whose
+// recorded calls were not all replayed.
+func (d *Driver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return adbc.Error{Code: adbc.StatusInvalidState}
+	}
+	d.closed = true
+
+	if d.recording() {
+		return ioError(d.trace.Close())
+	}
+
+	for obj, calls := range d.queues {
+		if len(calls) > 0 {
+			return replayError("%d recorded calls on %s were not replayed, starting with %s", len(calls), obj, calls[0].Method)
+		}
+	}
+	return nil
+}
+
+// NewDatabase records or replays the creation of a database.
+func (d *Driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	d.mu.Lock()
+	id := fmt.Sprintf("db%d", d.databases)
+	d.databases++
+	d.mu.Unlock()
+
+	db := &database{drv: d, id: id}
+	o := d.call(driverObject, "NewDatabase", args{Options: d.redactOptions(opts)}, nil, func() outcome {
+		var err error
+		db.wrapped, err = d.wrapped.NewDatabase(opts)
+		return outcome{err: err}
+	})
+	if o.err != nil {
+		return nil, o.err
+	}
+	return db, nil
+}
+
+// sensitiveKeys are the parts of option keys whose values are always
+// redacted, such as adbc.snowflake.sql.client_option.jwt_private_key
+var sensitiveKeys = []string{"password", "token", "secret", "private_key"}
+
+func (d *Driver) isRedacted(key string) bool {
+	if d.redact[key] {
+		return true
+	}
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Driver) redactOptions(opts map[string]string) map[string]string {
+	out := make(map[string]string, len(opts))
+	for k, v := range opts {
+		out[k] = d.redactValue(k, v)
+	}
+	return out
+}
+
+func (d *Driver) redactValue(key, value string) string {
+	if !d.isRedacted(key) {
+		return value
+	}
+	if value != "" {
+		d.mu.Lock()
+		d.secrets[value] = true
+		d.mu.Unlock()
+	}
+	return redacted
+}
+
+// redactMessage removes the redacted values from an error message, as
+// drivers may quote the options they reject.
+func (d *Driver) redactMessage(msg string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for v := range d.secrets {
+		msg = strings.ReplaceAll(msg, v, redacted)
+	}
+	return msg
+}
+
+// call records or replays a call. When recording, fn makes the call on
+// the wrapped object, and the bound data and results are written to
+// IPC files. When replaying, the call must match the next one recorded
+// for the object, and its results are read back.
+func (d *Driver) call(obj, method string, a args, data *bound, fn func() outcome) outcome {
+	if d.recording() {
+		return d.record(obj, method, a, data, fn)
+	}
+	return d.replay(obj, method, a, data)
+}
+
+func (d *Driver) record(obj, method string, a args, data *bound, fn func() outcome) outcome {
+	d.mu.Lock()
+	d.seq++
+	prefix := fmt.Sprintf("%06d-%s", d.seq, method)
+	d.mu.Unlock()
+
+	if data != nil {
+		a.Data = prefix + "-data.arrow"
+		if err := d.writeIPC(a.Data, data.schema, data.recs); err != nil {
+			return outcome{err: err}
+		}
+	}
+
+	o := fn()
+	c := call{Object: obj, Method: method, Args: a, Result: result{N: o.n, Partitions: o.partitions}}
+	if o.err != nil {
+		c.Result = result{Error: recordError(o.err)}
+		c.Result.Error.Msg = d.redactMessage(c.Result.Error.Msg)
+	}
+
+	if o.rdr != nil {
+		sc := o.rdr.Schema()
+		recs, err := drain(o.rdr)
+		o.rdr.Release()
+		o.rdr = nil
+		defer releaseAll(recs)
+
+		if err != nil {
+			o.err = err
+			c.Result = result{Error: recordError(err)}
+			c.Result.Error.Msg = d.redactMessage(c.Result.Error.Msg)
+		} else {
+			c.Result.Rows = prefix + "-rows.arrow"
+			if o.err = d.writeIPC(c.Result.Rows, sc, recs); o.err != nil {
+				return o
+			}
+			o.rdr, o.err = array.NewRecordReader(sc, recs)
+		}
+	}
+
+	if o.schema != nil {
+		c.Result.Schema = prefix + "-schema.arrow"
+		if err := d.writeIPC(c.Result.Schema, o.schema, nil); err != nil {
+			return outcome{err: err}
+		}
+	}
+
+	var line bytes.Buffer
+	enc := json.NewEncoder(&line)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(c); err != nil {
+		return outcome{err: ioError(err)}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, err := d.trace.Write(line.Bytes()); err != nil {
+		return outcome{err: ioError(err)}
+	}
+	return o
+}
+
+func (d *Driver) replay(obj, method string, a args, data *bound) outcome {
+	d.mu.Lock()
+	queue := d.queues[obj]
+	if len(queue) == 0 {
+		d.mu.Unlock()
+		return outcome{err: replayError("call to %s on %s was not recorded", method, obj)}
+	}
+	c := queue[0]
+	if c.Method != method {
+		d.mu.Unlock()
+		return outcome{err: replayError("call to %s on %s does not match the recorded call to %s", method, obj, c.Method)}
+	}
+	if err := matchArgs(c.Args, a); err != nil {
+		d.mu.Unlock()
+		return outcome{err: replayError("call to %s on %s does not match the recording: %s", method, obj, err)}
+	}
+	d.queues[obj] = queue[1:]
+	d.mu.Unlock()
+
+	if err := d.matchData(c.Args.Data, data); err != nil {
+		return outcome{err: replayError("call to %s on %s does not match the recording: %s", method, obj, err)}
+	}
+
+	if c.Result.Error != nil {
+		return outcome{err: c.Result.Error.err()}
+	}
+
+	o := outcome{n: c.Result.N, partitions: c.Result.Partitions}
+	if c.Result.Rows != "" {
+		sc, recs, err := d.readIPC(c.Result.Rows)
+		if err != nil {
+			return outcome{err: err}
+		}
+		defer releaseAll(recs)
+		if o.rdr, err = array.NewRecordReader(sc, recs); err != nil {
+			return outcome{err: err}
+		}
+	}
+	if c.Result.Schema != "" {
+		var err error
+		if o.schema, _, err = d.readIPC(c.Result.Schema); err != nil {
+			return outcome{err: err}
+		}
+	}
+	return o
+}
+
+// matchArgs compares the arguments of a call with the recorded ones,
+// except for bound data. Redacted option values match any value.
+func matchArgs(recorded, actual args) error {
+	if recorded.Value == redacted {
+		recorded.Value = actual.Value
+	}
+	if recorded.Options != nil {
+		opts := make(map[string]string, len(recorded.Options))
+		for k, v := range recorded.Options {
+			if av, ok := actual.Options[k]; ok && v == redacted {
+				v = av
+			}
+			opts[k] = v
+		}
+		recorded.Options = opts
+	}
+	recorded.Data, actual.Data = "", ""
+
+	if reflect.DeepEqual(recorded, actual) {
+		return nil
+	}
+	want, _ := json.Marshal(recorded)
+	got, _ := json.Marshal(actual)
+	return fmt.Errorf("expected arguments %s, got %s", want, got)
+}
+
+// matchData compares bound data with the data recorded in file,
+// regardless of how the rows are split into batches.
+func (d *Driver) matchData(file string, data *bound) error {
+	switch {
+	case file == "" && data == nil:
+		return nil
+	case file == "":
+		return fmt.Errorf("no data was bound when recording")
+	case data == nil:
+		return fmt.Errorf("data was bound when recording")
+	}
+
+	sc, recs, err := d.readIPC(file)
+	if err != nil {
+		return err
+	}
+	defer releaseAll(recs)
+
+	if !sc.Equal(data.schema) {
+		return fmt.Errorf("expected bound data with schema %s, got %s", sc, data.schema)
+	}
+
+	left := array.NewTableFromRecords(sc, recs)
+	defer left.Release()
+	right := array.NewTableFromRecords(sc, data.recs)
+	defer right.Release()
+	if !array.TableEqual(left, right) {
+		return fmt.Errorf("bound data differs from the recorded data")
+	}
+	return nil
+}
+
+func (d *Driver) writeIPC(name string, sc *arrow.Schema, recs []arrow.Record) error {
+	f, err := os.Create(filepath.Join(d.dir, name))
+	if err != nil {
+		return ioError(err)
+	}
+	defer f.Close()
+
+	w, err := ipc.NewFileWriter(f, ipc.WithSchema(sc), ipc.WithAllocator(d.alloc))
+	if err != nil {
+		return ioError(err)
+	}
+	for _, r := range recs {
+		if err := w.Write(r); err != nil {
+			w.Close()
+			return ioError(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return ioError(err)
+	}
+	return ioError(f.Close())
+}
+
+func (d *Driver) readIPC(name string) (*arrow.Schema, []arrow.Record, error) {
+	f, err := os.Open(filepath.Join(d.dir, name))
+	if err != nil {
+		return nil, nil, ioError(err)
+	}
+	defer f.Close()
+
+	rdr, err := ipc.NewFileReader(f, ipc.WithAllocator(d.alloc))
+	if err != nil {
+		return nil, nil, ioError(err)
+	}
+	defer rdr.Close()
+
+	recs := make([]arrow.Record, 0, rdr.NumRecords())
+	for i := 0; i < rdr.NumRecords(); i++ {
+		rec, err := rdr.RecordAt(i)
+		if err != nil {
+			releaseAll(recs)
+			return nil, nil, ioError(err)
+		}
+		recs = append(recs, rec)
+	}
+	return rdr.Schema(), recs, nil
+}
+
+// drain reads every record of a reader, retaining them.
+func drain(rdr array.RecordReader) ([]arrow.Record, error) {
+	recs := []arrow.Record{}
+	for rdr.Next() {
+		rdr.Record().Retain()
+		recs = append(recs, rdr.Record())
+	}
+	if err := rdr.Err(); err != nil {
+		releaseAll(recs)
+		return nil, err
+	}
+	return recs, nil
+}
+
+func releaseAll(recs []arrow.Record) {
+	for _, r := range recs {
+		r.Release()
+	}
+}
@@ -0,0 +1,337 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/driver/databasesql"
+	"github.com/apache/arrow-adbc/go/adbc/driver/replay"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/suite"
+	_ "modernc.org/sqlite"
+)
+
+type ReplayTests struct {
+	suite.Suite
+
+	mem *memory.CheckedAllocator
+	ctx context.Context
+	dir string
+	uri string
+}
+
+func (s *ReplayTests) SetupTest() {
+	s.mem = memory.NewCheckedAllocator(memory.DefaultAllocator)
+	s.ctx = context.Background()
+	s.dir = filepath.Join(s.T().TempDir(), "trace")
+	s.uri = "file:" + filepath.Join(s.T().TempDir(), "test.db")
+}
+
+func (s *ReplayTests) TearDownTest() {
+	s.mem.AssertSize(s.T(), 0)
+}
+
+func (s *ReplayTests) recorder() *replay.Driver {
+	drv, err := replay.NewRecorder(databasesql.Driver{Alloc: s.mem}, s.dir, s.mem)
+	s.Require().NoError(err)
+	return drv
+}
+
+func (s *ReplayTests) replayer() *replay.Driver {
+	drv, err := replay.NewReplayer(s.dir, s.mem)
+	s.Require().NoError(err)
+	return drv
+}
+
+func (s *ReplayTests) open(drv adbc.Driver, uri string) (adbc.Database, adbc.Connection) {
+	db, err := drv.NewDatabase(map[string]string{
+		databasesql.OptionDriverName: "sqlite",
+		adbc.OptionKeyURI:            uri,
+	})
+	s.Require().NoError(err)
+	cnxn, err := db.Open(s.ctx)
+	s.Require().NoError(err)
+	return db, cnxn
+}
+
+func (s *ReplayTests) close(db adbc.Database, cnxn adbc.Connection) {
+	s.NoError(cnxn.Close())
+	s.NoError(db.(interface{ Close() error }).Close())
+}
+
+func (s *ReplayTests) exec(cnxn adbc.Connection, query string, params arrow.Record) int64 {
+	stmt, err := cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer func() { s.NoError(stmt.Close()) }()
+
+	s.Require().NoError(stmt.SetSqlQuery(query))
+	if params != nil {
+		s.Require().NoError(stmt.Bind(s.ctx, params))
+	}
+	n, err := stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	return n
+}
+
+// query runs query and returns its results as a table, or the error of
+// executing it.
+func (s *ReplayTests) query(cnxn adbc.Connection, query string) (arrow.Table, error) {
+	stmt, err := cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer func() { s.NoError(stmt.Close()) }()
+
+	s.Require().NoError(stmt.SetSqlQuery(query))
+	rdr, _, err := stmt.ExecuteQuery(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Release()
+
+	var recs []arrow.Record
+	for rdr.Next() {
+		rdr.Record().Retain()
+		recs = append(recs, rdr.Record())
+	}
+	s.Require().NoError(rdr.Err())
+
+	tbl := array.NewTableFromRecords(rdr.Schema(), recs)
+	for _, r := range recs {
+		r.Release()
+	}
+	return tbl, nil
+}
+
+// workload creates and fills a table and reads it back, returning the
+// results and the error of querying a table which does not exist.
+func (s *ReplayTests) workload(drv adbc.Driver, uri string) (arrow.Table, error) {
+	db, cnxn := s.open(drv, uri)
+	defer s.close(db, cnxn)
+
+	s.exec(cnxn, "CREATE TABLE ints (i INTEGER, s TEXT)", nil)
+
+	params, _, err := array.RecordFromJSON(s.mem, arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil), strings.NewReader(`[{"i": 1, "s": "foo"}, {"i": 2, "s": null}, {"i": null, "s": "bar"}]`))
+	s.Require().NoError(err)
+	defer params.Release()
+	s.EqualValues(3, s.exec(cnxn, "INSERT INTO ints VALUES (?, ?)", params))
+
+	tbl, err := s.query(cnxn, "SELECT * FROM ints ORDER BY i")
+	s.Require().NoError(err)
+
+	_, missingErr := s.query(cnxn, "SELECT * FROM missing")
+	s.Require().Error(missingErr)
+	return tbl, missingErr
+}
+
+func (s *ReplayTests) TestRecordReplay() {
+	rec := s.recorder()
+	expected, expectedErr := s.workload(rec, s.uri)
+	defer expected.Release()
+	s.Require().NoError(rec.Close())
+
+	trace, err := os.ReadFile(filepath.Join(s.dir, "trace.jsonl"))
+	s.Require().NoError(err)
+	s.NotContains(string(trace), s.uri)
+	s.Contains(string(trace), "<redacted>")
+
+	// replay against a database which doesn't exist, with another uri
+	// since it is redacted
+	rep := s.replayer()
+	actual, actualErr := s.workload(rep, "file:"+filepath.Join(s.T().TempDir(), "other.db"))
+	defer actual.Release()
+	s.NoError(rep.Close())
+
+	s.Truef(array.TableEqual(expected, actual), "expected: %s\ngot: %s", expected.Schema(), actual.Schema())
+	s.Equal(expectedErr.Error(), actualErr.Error())
+	var expectedADBC, actualADBC adbc.Error
+	if errors.As(expectedErr, &expectedADBC) {
+		s.Require().ErrorAs(actualErr, &actualADBC)
+		s.Equal(expectedADBC.Code, actualADBC.Code)
+	}
+
+	_, err = os.Stat(filepath.Join(s.T().TempDir(), "other.db"))
+	s.True(os.IsNotExist(err))
+}
+
+func (s *ReplayTests) TestRedactSensitiveOptions() {
+	rec := s.recorder()
+	// the wrapped driver rejects the options, but the call is recorded
+	_, err := rec.NewDatabase(map[string]string{
+		databasesql.OptionDriverName:                       "sqlite",
+		adbc.OptionKeyPassword:                             "hunter2",
+		"adbc.snowflake.sql.client_option.auth_token":      "tok-value",
+		"adbc.snowflake.sql.client_option.jwt_private_key": "pk-value",
+		"adbc.example.Client_Secret":                       "secret-value",
+	})
+	s.Error(err)
+	s.Require().NoError(rec.Close())
+
+	trace, err := os.ReadFile(filepath.Join(s.dir, "trace.jsonl"))
+	s.Require().NoError(err)
+	for _, v := range []string{"hunter2", "tok-value", "pk-value", "secret-value"} {
+		s.NotContains(string(trace), v)
+	}
+	s.Contains(string(trace), "sqlite")
+}
+
+func (s *ReplayTests) TestMismatch() {
+	rec := s.recorder()
+	db, cnxn := s.open(rec, s.uri)
+	s.exec(cnxn, "CREATE TABLE ints (i INTEGER)", nil)
+	s.close(db, cnxn)
+	s.Require().NoError(rec.Close())
+
+	rep := s.replayer()
+	db, cnxn = s.open(rep, s.uri)
+	stmt, err := cnxn.NewStatement()
+	s.Require().NoError(err)
+
+	var adbcErr adbc.Error
+	err = stmt.SetSqlQuery("CREATE TABLE other (i INTEGER)")
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInternal, adbcErr.Code)
+	s.Contains(adbcErr.Msg, "CREATE TABLE ints")
+
+	_, _, err = stmt.ExecuteQuery(s.ctx)
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusInternal, adbcErr.Code)
+	s.Contains(adbcErr.Msg, "does not match the recorded call to SetSqlQuery")
+}
+
+func (s *ReplayTests) TestMismatchedData() {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+	params := func(data string) arrow.Record {
+		rec, _, err := array.RecordFromJSON(s.mem, sc, strings.NewReader(data))
+		s.Require().NoError(err)
+		return rec
+	}
+
+	rec := s.recorder()
+	db, cnxn := s.open(rec, s.uri)
+	s.exec(cnxn, "CREATE TABLE ints (i INTEGER)", nil)
+	p := params(`[{"i": 1}, {"i": 2}]`)
+	s.exec(cnxn, "INSERT INTO ints VALUES (?)", p)
+	p.Release()
+	s.close(db, cnxn)
+	s.Require().NoError(rec.Close())
+
+	rep := s.replayer()
+	db, cnxn = s.open(rep, s.uri)
+	s.exec(cnxn, "CREATE TABLE ints (i INTEGER)", nil)
+
+	stmt, err := cnxn.NewStatement()
+	s.Require().NoError(err)
+	s.Require().NoError(stmt.SetSqlQuery("INSERT INTO ints VALUES (?)"))
+	p = params(`[{"i": 1}, {"i": 3}]`)
+	defer p.Release()
+
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(stmt.Bind(s.ctx, p), &adbcErr)
+	s.Equal(adbc.StatusInternal, adbcErr.Code)
+	s.Contains(adbcErr.Msg, "bound data differs")
+}
+
+func (s *ReplayTests) TestUnreplayedCalls() {
+	rec := s.recorder()
+	db, cnxn := s.open(rec, s.uri)
+	s.close(db, cnxn)
+	s.Require().NoError(rec.Close())
+
+	rep := s.replayer()
+	_, cnxn = s.open(rep, s.uri)
+	s.NoError(cnxn.Close())
+
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(rep.Close(), &adbcErr)
+	s.Equal(adbc.StatusInternal, adbcErr.Code)
+	s.Contains(adbcErr.Msg, "1 recorded calls on db0 were not replayed, starting with Close")
+
+	s.Require().ErrorAs(rep.Close(), &adbcErr)
+	s.Equal(adbc.StatusInvalidState, adbcErr.Code)
+}
+
+func (s *ReplayTests) TestStatisticsNotSupported() {
+	getStatistics := func(cnxn adbc.Connection) {
+		table := "ints"
+		var adbcErr adbc.Error
+		_, err := cnxn.(adbc.ConnectionGetStatistics).GetStatistics(s.ctx, nil, nil, &table, true)
+		s.Require().ErrorAs(err, &adbcErr)
+		s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+	}
+
+	rec := s.recorder()
+	db, cnxn := s.open(rec, s.uri)
+	getStatistics(cnxn)
+	s.close(db, cnxn)
+	s.Require().NoError(rec.Close())
+
+	// the error is replayed as recorded
+	rep := s.replayer()
+	db, cnxn = s.open(rep, s.uri)
+	getStatistics(cnxn)
+	s.close(db, cnxn)
+	s.NoError(rep.Close())
+}
+
+func (s *ReplayTests) TestExecuteMultiNotSupported() {
+	executeMulti := func(cnxn adbc.Connection) {
+		stmt, err := cnxn.NewStatement()
+		s.Require().NoError(err)
+		defer func() { s.NoError(stmt.Close()) }()
+
+		s.Require().NoError(stmt.SetSqlQuery("SELECT 1; SELECT 2"))
+		var adbcErr adbc.Error
+		_, err = stmt.(adbc.StatementExecuteMulti).ExecuteMulti(s.ctx)
+		s.Require().ErrorAs(err, &adbcErr)
+		s.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+	}
+
+	rec := s.recorder()
+	db, cnxn := s.open(rec, s.uri)
+	executeMulti(cnxn)
+	s.close(db, cnxn)
+	s.Require().NoError(rec.Close())
+
+	rep := s.replayer()
+	db, cnxn = s.open(rep, s.uri)
+	executeMulti(cnxn)
+	s.close(db, cnxn)
+	s.NoError(rep.Close())
+}
+
+func (s *ReplayTests) TestMissingRecording() {
+	_, err := replay.NewReplayer(filepath.Join(s.T().TempDir(), "none"), s.mem)
+	var adbcErr adbc.Error
+	s.Require().ErrorAs(err, &adbcErr)
+	s.Equal(adbc.StatusIO, adbcErr.Code)
+}
+
+func TestReplay(t *testing.T) {
+	suite.Run(t, new(ReplayTests))
+}
@@ -0,0 +1,204 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type statement struct {
+	drv     *Driver
+	id      string
+	wrapped adbc.Statement
+	results int
+}
+
+func (s *statement) Close() error {
+	return s.drv.call(s.id, "Close", args{}, nil, func() outcome {
+		return outcome{err: s.wrapped.Close()}
+	}).err
+}
+
+func (s *statement) SetOption(key, val string) error {
+	return s.drv.call(s.id, "SetOption", args{Key: key, Value: s.drv.redactValue(key, val)}, nil, func() outcome {
+		return outcome{err: s.wrapped.SetOption(key, val)}
+	}).err
+}
+
+func (s *statement) SetSqlQuery(query string) error {
+	return s.drv.call(s.id, "SetSqlQuery", args{Query: query}, nil, func() outcome {
+		return outcome{err: s.wrapped.SetSqlQuery(query)}
+	}).err
+}
+
+func (s *statement) SetSubstraitPlan(plan []byte) error {
+	return s.drv.call(s.id, "SetSubstraitPlan", args{Plan: plan}, nil, func() outcome {
+		return outcome{err: s.wrapped.SetSubstraitPlan(plan)}
+	}).err
+}
+
+func (s *statement) Prepare(ctx context.Context) error {
+	return s.drv.call(s.id, "Prepare", args{}, nil, func() outcome {
+		return outcome{err: s.wrapped.Prepare(ctx)}
+	}).err
+}
+
+func (s *statement) Bind(ctx context.Context, values arrow.Record) error {
+	data := &bound{schema: values.Schema(), recs: []arrow.Record{values}}
+	return s.drv.call(s.id, "Bind", args{}, data, func() outcome {
+		return outcome{err: s.wrapped.Bind(ctx, values)}
+	}).err
+}
+
+// BindStream reads the whole stream so that it can be recorded or
+// compared with the recording, and binds a reader over the same records
+// to the wrapped statement.
+func (s *statement) BindStream(ctx context.Context, stream array.RecordReader) error {
+	defer stream.Release()
+
+	recs, err := drain(stream)
+	if err != nil {
+		return err
+	}
+	defer releaseAll(recs)
+
+	data := &bound{schema: stream.Schema(), recs: recs}
+	return s.drv.call(s.id, "BindStream", args{}, data, func() outcome {
+		rdr, err := array.NewRecordReader(data.schema, recs)
+		if err != nil {
+			return outcome{err: err}
+		}
+		return outcome{err: s.wrapped.BindStream(ctx, rdr)}
+	}).err
+}
+
+func (s *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	o := s.drv.call(s.id, "ExecuteQuery", args{}, nil, func() outcome {
+		rdr, n, err := s.wrapped.ExecuteQuery(ctx)
+		return outcome{rdr: rdr, n: n, err: err}
+	})
+	return o.rdr, o.n, o.err
+}
+
+func (s *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	o := s.drv.call(s.id, "ExecuteUpdate", args{}, nil, func() outcome {
+		n, err := s.wrapped.ExecuteUpdate(ctx)
+		return outcome{n: n, err: err}
+	})
+	return o.n, o.err
+}
+
+func (s *statement) GetParameterSchema() (*arrow.Schema, error) {
+	o := s.drv.call(s.id, "GetParameterSchema", args{}, nil, func() outcome {
+		sc, err := s.wrapped.GetParameterSchema()
+		return outcome{schema: sc, err: err}
+	})
+	return o.schema, o.err
+}
+
+func (s *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	o := s.drv.call(s.id, "ExecutePartitions", args{}, nil, func() outcome {
+		sc, parts, n, err := s.wrapped.ExecutePartitions(ctx)
+		return outcome{schema: sc, partitions: &parts, n: n, err: err}
+	})
+	if o.err != nil {
+		return nil, adbc.Partitions{}, -1, o.err
+	}
+
+	var parts adbc.Partitions
+	if o.partitions != nil {
+		parts = *o.partitions
+	}
+	return o.schema, parts, o.n, nil
+}
+
+// ExecuteSchema is forwarded to the wrapped statement, which fails with
+// adbc.StatusNotImplemented if it doesn't support it.
+func (s *statement) ExecuteSchema(ctx context.Context) (*arrow.Schema, error) {
+	o := s.drv.call(s.id, "ExecuteSchema", args{}, nil, func() outcome {
+		es, ok := s.wrapped.(adbc.StatementExecuteSchema)
+		if !ok {
+			return outcome{err: adbc.Error{
+				Msg:  "[replay] wrapped statement does not support ExecuteSchema",
+				Code: adbc.StatusNotImplemented,
+			}}
+		}
+		sc, err := es.ExecuteSchema(ctx)
+		return outcome{schema: sc, err: err}
+	})
+	return o.schema, o.err
+}
+
+// ExecuteMulti is forwarded to the wrapped statement, which fails with
+// adbc.StatusNotImplemented if it doesn't support it. The results are
+// an object of their own in the recording.
+func (s *statement) ExecuteMulti(ctx context.Context) (adbc.MultiResult, error) {
+	s.drv.mu.Lock()
+	id := fmt.Sprintf("%s.r%d", s.id, s.results)
+	s.results++
+	s.drv.mu.Unlock()
+
+	res := &multiResult{drv: s.drv, id: id}
+	o := s.drv.call(s.id, "ExecuteMulti", args{}, nil, func() outcome {
+		em, ok := s.wrapped.(adbc.StatementExecuteMulti)
+		if !ok {
+			return outcome{err: adbc.Error{
+				Msg:  "[replay] wrapped statement does not support ExecuteMulti",
+				Code: adbc.StatusNotImplemented,
+			}}
+		}
+		var err error
+		res.wrapped, err = em.ExecuteMulti(ctx)
+		return outcome{err: err}
+	})
+	if o.err != nil {
+		return nil, o.err
+	}
+	return res, nil
+}
+
+type multiResult struct {
+	drv     *Driver
+	id      string
+	wrapped adbc.MultiResult
+}
+
+func (m *multiResult) NextResult(ctx context.Context) (array.RecordReader, int64, error) {
+	o := m.drv.call(m.id, "NextResult", args{}, nil, func() outcome {
+		rdr, n, err := m.wrapped.NextResult(ctx)
+		return outcome{rdr: rdr, n: n, err: err}
+	})
+	return o.rdr, o.n, o.err
+}
+
+func (m *multiResult) Close() error {
+	return m.drv.call(m.id, "Close", args{}, nil, func() outcome {
+		return outcome{err: m.wrapped.Close()}
+	}).err
+}
+
+var (
+	_ adbc.Statement              = (*statement)(nil)
+	_ adbc.StatementExecuteSchema = (*statement)(nil)
+	_ adbc.StatementExecuteMulti  = (*statement)(nil)
+)
@@ -22,6 +22,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -126,6 +127,20 @@ func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.Re
 			infoNameBldr.Append(uint32(code))
 			infoValueBldr.Append(strValTypeID)
 			strInfoBldr.Append(infoVendorName)
+		case adbc.InfoVendorSql, adbc.InfoFeatureTransactions, adbc.InfoFeatureCancel:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoBool(infoValueBldr, true)
+		case adbc.InfoVendorSubstrait, adbc.InfoFeaturePartitions,
+			adbc.InfoFeatureSavepoints, adbc.InfoFeatureParameterSchema:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoBool(infoValueBldr, false)
+		case adbc.InfoFeatureBulkIngestModes:
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoStringList(infoValueBldr, infoBulkIngestModes)
+		case adbc.InfoFeatureIsolationLevels:
+			// the isolation level cannot be set
+			infoNameBldr.Append(uint32(code))
+			internal.AppendInfoStringList(infoValueBldr, nil)
 		default:
 			infoNameBldr.Append(uint32(code))
 			infoValueBldr.AppendNull()
@@ -410,26 +425,12 @@ func toField(name string, isnullable bool, dataType string, numPrec, numPrecRadi
 	return
 }
 
-func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (result internal.SchemaToTableInfo, err error) {
-	if depth == adbc.ObjectDepthCatalogs || depth == adbc.ObjectDepthDBSchemas {
-		return
-	}
-
-	result = make(internal.SchemaToTableInfo)
-	includeSchema := depth == adbc.ObjectDepthAll || depth == adbc.ObjectDepthColumns
-
-	conditions := make([]string, 0)
-	if catalog != nil && *catalog != "" {
-		conditions = append(conditions, ` TABLE_CATALOG ILIKE \'`+*catalog+`\'`)
-	}
-	if dbSchema != nil && *dbSchema != "" {
-		conditions = append(conditions, ` TABLE_SCHEMA ILIKE \'`+*dbSchema+`\'`)
-	}
-	if tableName != nil && *tableName != "" {
-		conditions = append(conditions, ` TABLE_NAME ILIKE \'`+*tableName+`\'`)
-	}
-
-	const queryPrefix = `DECLARE
+// The script running a query over the INFORMATION_SCHEMA views of all
+// the databases, skipping those of inbound shares: the prefix loops
+// over the databases, appending a select from each to the statement,
+// and the suffix executes the statement.
+const (
+	tablesQueryPrefix = `DECLARE
 		c1 CURSOR FOR SELECT DATABASE_NAME FROM INFORMATION_SCHEMA.DATABASES;
 		res RESULTSET;
 		counter INTEGER DEFAULT 0;
@@ -462,12 +463,7 @@ func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, cat
 			END IF;
 			`
 
-	const noSchema = `statement := statement || ' SELECT table_catalog, table_schema, table_name, table_type FROM ' || rec.database_name || '.INFORMATION_SCHEMA.TABLES';
-			counter := counter + 1;
-		END FOR;
-		`
-
-	const getSchema = `statement := statement ||
+	tablesColumnsQuery = `statement := statement ||
 		' SELECT
 				table_catalog, table_schema, table_name, column_name,
 				ordinal_position, is_nullable::boolean, data_type, numeric_precision,
@@ -479,10 +475,42 @@ func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, cat
 		END FOR;
 	  `
 
-	const querySuffix = `
+	tablesQuerySuffix = `
 		res := (EXECUTE IMMEDIATE :statement);
 		RETURN TABLE (res);
 	END;`
+)
+
+// tableConditions filters the tables of the INFORMATION_SCHEMA views
+// by the search patterns, for the statement of the tables query.
+func tableConditions(catalog, dbSchema, tableName *string) []string {
+	conditions := make([]string, 0)
+	if catalog != nil && *catalog != "" {
+		conditions = append(conditions, ` TABLE_CATALOG ILIKE \'`+*catalog+`\'`)
+	}
+	if dbSchema != nil && *dbSchema != "" {
+		conditions = append(conditions, ` TABLE_SCHEMA ILIKE \'`+*dbSchema+`\'`)
+	}
+	if tableName != nil && *tableName != "" {
+		conditions = append(conditions, ` TABLE_NAME ILIKE \'`+*tableName+`\'`)
+	}
+	return conditions
+}
+
+func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, catalog *string, dbSchema *string, tableName *string, columnName *string, tableType []string) (result internal.SchemaToTableInfo, err error) {
+	if depth == adbc.ObjectDepthCatalogs || depth == adbc.ObjectDepthDBSchemas {
+		return
+	}
+
+	result = make(internal.SchemaToTableInfo)
+	includeSchema := depth == adbc.ObjectDepthAll || depth == adbc.ObjectDepthColumns
+
+	conditions := tableConditions(catalog, dbSchema, tableName)
+
+	const noSchema = `statement := statement || ' SELECT table_catalog, table_schema, table_name, table_type FROM ' || rec.database_name || '.INFORMATION_SCHEMA.TABLES';
+			counter := counter + 1;
+		END FOR;
+		`
 
 	// first populate the tables and table types
 	var rows *sql.Rows
@@ -497,7 +525,7 @@ func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, cat
 	if cond != "" {
 		cond = `statement := 'SELECT * FROM (' || statement || ') WHERE ` + cond + `';`
 	}
-	query := queryPrefix + noSchema + cond + querySuffix
+	query := tablesQueryPrefix + noSchema + cond + tablesQuerySuffix
 	rows, err = c.sqldb.QueryContext(ctx, query)
 	if err != nil {
 		err = errToAdbcErr(adbc.StatusIO, err)
@@ -532,7 +560,7 @@ func (c *cnxn) getObjectsTables(ctx context.Context, depth adbc.ObjectDepth, cat
 		}
 		cond = `statement := 'SELECT * FROM (' || statement || ')` + cond +
 			` ORDER BY table_catalog, table_schema, table_name, ordinal_position';`
-		query = queryPrefix + getSchema + cond + querySuffix
+		query = tablesQueryPrefix + tablesColumnsQuery + cond + tablesQuerySuffix
 		rows, err = c.sqldb.QueryContext(ctx, query)
 		if err != nil {
 			return
@@ -685,34 +713,70 @@ func (c *cnxn) GetTableSchema(ctx context.Context, catalog *string, dbSchema *st
 	tblParts = append(tblParts, strconv.Quote(strings.ToUpper(tableName)))
 	fullyQualifiedTable := strings.Join(tblParts, ".")
 
-	rows, err := c.sqldb.QueryContext(ctx, `DESC TABLE `+fullyQualifiedTable)
+	fields, err := c.describeTable(ctx, fullyQualifiedTable)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		fields[i].Name = strings.ToLower(fields[i].Name)
+	}
+
+	sc := arrow.NewSchema(fields, nil)
+	return sc, nil
+}
+
+// describeTable returns the columns of a table, given by its name as
+// it is used in queries, with their names exactly as snowflake has
+// them. It runs on the connection's own session so that temporary
+// tables are visible too.
+func (c *cnxn) describeTable(ctx context.Context, fullyQualifiedTable string) ([]arrow.Field, error) {
+	rows, err := c.cn.QueryContext(ctx, "DESC TABLE "+fullyQualifiedTable, nil)
 	if err != nil {
 		return nil, errToAdbcErr(adbc.StatusIO, err)
 	}
 	defer rows.Close()
 
+	toStr := func(v driver.Value) string {
+		switch v := v.(type) {
+		case string:
+			return v
+		case []byte:
+			return string(v)
+		}
+		return ""
+	}
+
 	var (
-		name, typ, kind, isnull, primary, unique string
-		def, check, expr, comment, policyName    sql.NullString
-		fields                                   = []arrow.Field{}
+		fields = []arrow.Field{}
+		dest   = make([]driver.Value, len(rows.Columns()))
 	)
+	if len(dest) < 10 {
+		return nil, adbc.Error{
+			Msg:  fmt.Sprintf("unexpected number of columns from DESC TABLE: %d", len(dest)),
+			Code: adbc.StatusInternal,
+		}
+	}
 
-	for rows.Next() {
-		err := rows.Scan(&name, &typ, &kind, &isnull, &def, &primary, &unique,
-			&check, &expr, &comment, &policyName)
-		if err != nil {
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
 			return nil, errToAdbcErr(adbc.StatusIO, err)
 		}
 
-		f, err := descToField(name, typ, isnull, primary, comment)
+		// name, type, kind, null?, default, primary key, unique key,
+		// check, expression, comment, ...
+		comment := sql.NullString{String: toStr(dest[9]), Valid: dest[9] != nil}
+		f, err := descToField(toStr(dest[0]), toStr(dest[1]), toStr(dest[3]), toStr(dest[5]), comment)
 		if err != nil {
 			return nil, err
 		}
+		f.Name = toStr(dest[0])
 		fields = append(fields, f)
 	}
 
-	sc := arrow.NewSchema(fields, nil)
-	return sc, nil
+	return fields, nil
 }
 
 // GetTableTypes returns a list of the table types in the database.
@@ -40,6 +40,10 @@ const (
 	infoDriverName = "ADBC Snowflake Driver - Go"
 	infoVendorName = "Snowflake"
 
+	// uriScheme is the scheme the driver is registered for, which may
+	// prefix the gosnowflake DSN given as adbc.OptionKeyURI
+	uriScheme = "snowflake"
+
 	OptionDatabase  = "adbc.snowflake.sql.db"
 	OptionSchema    = "adbc.snowflake.sql.schema"
 	OptionWarehouse = "adbc.snowflake.sql.warehouse"
@@ -111,12 +115,23 @@ const (
 	OptionValueAuthJwt = "auth_jwt"
 	// use a username and password with mfa
 	OptionValueAuthUserPassMFA = "auth_mfa"
+
+	// ErrorDetailQueryID is the key of the detail of an adbc.Error
+	// holding the ID of the Snowflake query which failed, if any.
+	ErrorDetailQueryID = "adbc.snowflake.query_id"
 )
 
 var (
 	infoDriverVersion      string
 	infoDriverArrowVersion string
 	infoSupportedCodes     []adbc.InfoCode
+	infoBulkIngestModes    = []string{
+		adbc.OptionValueIngestModeCreate,
+		adbc.OptionValueIngestModeAppend,
+		adbc.OptionValueIngestModeReplace,
+		adbc.OptionValueIngestModeCreateAppend,
+		adbc.OptionValueIngestModeMerge,
+	}
 )
 
 func init() {
@@ -144,7 +159,18 @@ func init() {
 		adbc.InfoDriverVersion,
 		adbc.InfoDriverArrowVersion,
 		adbc.InfoVendorName,
+		adbc.InfoVendorSql,
+		adbc.InfoVendorSubstrait,
+		adbc.InfoFeatureBulkIngestModes,
+		adbc.InfoFeaturePartitions,
+		adbc.InfoFeatureTransactions,
+		adbc.InfoFeatureSavepoints,
+		adbc.InfoFeatureCancel,
+		adbc.InfoFeatureParameterSchema,
+		adbc.InfoFeatureIsolationLevels,
 	}
+
+	adbc.RegisterDriver("snowflake", Driver{}, uriScheme)
 }
 
 func errToAdbcErr(code adbc.Status, err error) error {
@@ -164,18 +190,22 @@ func errToAdbcErr(code adbc.Status, err error) error {
 		if len(sferr.SQLState) > 0 {
 			copy(sqlstate[:], sferr.SQLState[:5])
 		}
+		var details []adbc.ErrorDetail
+		if sferr.QueryID != "" {
+			details = append(details, adbc.ErrorDetail{Key: ErrorDetailQueryID, Value: []byte(sferr.QueryID)})
+		}
 		return adbc.Error{
 			Code:       code,
 			Msg:        sferr.Error(),
 			VendorCode: int32(sferr.Number),
 			SqlState:   sqlstate,
-		}
+		}.WithErr(err).WithDetails(details...)
 	}
 
 	return adbc.Error{
 		Msg:  err.Error(),
 		Code: code,
-	}
+	}.WithErr(err)
 }
 
 type Driver struct {
@@ -210,10 +240,53 @@ type database struct {
 	alloc memory.Allocator
 }
 
+// parseDSN parses the DSN given as the uri option. gosnowflake rejects
+// a DSN without a user or password, which may be given by the username
+// and password options instead: the DSN is then parsed again with the
+// values of those options as its user information, escaped, before
+// they are set on the configuration as they are.
+func parseDSN(dsn string, cnOptions map[string]string) (*gosnowflake.Config, error) {
+	cfg, err := gosnowflake.ParseDSN(dsn)
+	if err == nil {
+		return cfg, nil
+	}
+
+	user, hasUser := cnOptions[adbc.OptionKeyUsername]
+	password, hasPassword := cnOptions[adbc.OptionKeyPassword]
+	if !(errors.Is(err, gosnowflake.ErrEmptyUsername) && hasUser) &&
+		!(errors.Is(err, gosnowflake.ErrEmptyPassword) && hasPassword) {
+		return nil, err
+	}
+
+	prefix := uriScheme + "://"
+	u, parseErr := url.Parse(prefix + dsn)
+	if parseErr != nil {
+		return nil, err
+	}
+	if u.User != nil {
+		if !hasUser {
+			user = u.User.Username()
+		}
+		if !hasPassword {
+			password, hasPassword = u.User.Password()
+		}
+	}
+	if hasPassword {
+		u.User = url.UserPassword(user, password)
+	} else {
+		u.User = url.User(user)
+	}
+	return gosnowflake.ParseDSN(strings.TrimPrefix(u.String(), prefix))
+}
+
 func (d *database) SetOptions(cnOptions map[string]string) error {
 	uri, ok := cnOptions[adbc.OptionKeyURI]
 	if ok {
-		cfg, err := gosnowflake.ParseDSN(uri)
+		// the DSN may be given as a snowflake:// URI, as for adbc.Open
+		if prefix := uriScheme + "://"; len(uri) >= len(prefix) && strings.EqualFold(uri[:len(prefix)], prefix) {
+			uri = uri[len(prefix):]
+		}
+		cfg, err := parseDSN(uri, cnOptions)
 		if err != nil {
 			return errToAdbcErr(adbc.StatusInvalidArgument, err)
 		}
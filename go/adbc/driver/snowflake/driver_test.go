@@ -22,6 +22,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"runtime/debug"
 	"strings"
 	"testing"
 
@@ -186,6 +187,7 @@ func (s *SnowflakeQuirks) SupportsTransactions() bool            { return true }
 func (s *SnowflakeQuirks) SupportsGetParameterSchema() bool      { return false }
 func (s *SnowflakeQuirks) SupportsDynamicParameterBinding() bool { return false }
 func (s *SnowflakeQuirks) SupportsBulkIngest() bool              { return true }
+func (s *SnowflakeQuirks) SupportsBulkIngestMode(string) bool    { return true }
 func (s *SnowflakeQuirks) DBSchema() string                      { return s.schemaName }
 func (s *SnowflakeQuirks) GetMetadata(code adbc.InfoCode) interface{} {
 	switch code {
@@ -196,7 +198,7 @@ func (s *SnowflakeQuirks) GetMetadata(code adbc.InfoCode) interface{} {
 	case adbc.InfoDriverVersion:
 		return "(unknown or development build)"
 	case adbc.InfoDriverArrowVersion:
-		return "(unknown or development build)"
+		return arrowVersion()
 	case adbc.InfoVendorName:
 		return "Snowflake"
 	}
@@ -204,6 +206,19 @@ func (s *SnowflakeQuirks) GetMetadata(code adbc.InfoCode) interface{} {
 	return nil
 }
 
+// arrowVersion is the Arrow version the driver reports, which newer
+// toolchains do record in the build info of tests.
+func arrowVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if strings.HasPrefix(dep.Path, "github.com/apache/arrow/go/") {
+				return dep.Version
+			}
+		}
+	}
+	return "(unknown or development build)"
+}
+
 func (s *SnowflakeQuirks) SampleTableSchemaMetadata(tblName string, dt arrow.DataType) arrow.Metadata {
 	switch dt.ID() {
 	case arrow.STRING:
@@ -0,0 +1,93 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlError is an error returned to the client in a failed response,
+// with the error code and SQLSTATE snowflake would use.
+type sqlError struct {
+	code     string
+	sqlState string
+	msg      string
+}
+
+func (e *sqlError) Error() string { return e.msg }
+
+func newError(code, sqlState, msg string) *sqlError {
+	return &sqlError{code: code, sqlState: sqlState, msg: msg}
+}
+
+func errSyntax(detail string) *sqlError {
+	return newError("001003", "42000", "SQL compilation error:\n"+detail)
+}
+
+func errUnsupported(query string) *sqlError {
+	q := strings.Join(strings.Fields(query), " ")
+	if len(q) > 60 {
+		q = q[:60] + "..."
+	}
+	return errSyntax(fmt.Sprintf("syntax error: statement not supported by the stand-in server: %s", q))
+}
+
+func errNotFound(kind, name string) *sqlError {
+	sqlState := "02000"
+	if kind == "Table" {
+		sqlState = "42S02"
+	}
+	return newError("002003", sqlState,
+		fmt.Sprintf("SQL compilation error:\n%s '%s' does not exist or not authorized.", kind, name))
+}
+
+func errExists(name string) *sqlError {
+	return newError("002002", "42710",
+		fmt.Sprintf("SQL compilation error:\nObject '%s' already exists.", name))
+}
+
+func errInvalidIdent(name string) *sqlError {
+	return newError("000904", "42000",
+		fmt.Sprintf("SQL compilation error:\ninvalid identifier '%s'", name))
+}
+
+func errNotRecognized(what, value string) *sqlError {
+	return newError("100038", "22018", fmt.Sprintf("%s value '%s' is not recognized", what, value))
+}
+
+func errTypeMismatch(t colType, in input) *sqlError {
+	got := in.literalType().name
+	if in.parsed {
+		got = "VARIANT"
+	}
+	return newError("002023", "22000",
+		fmt.Sprintf("SQL compilation error:\nExpression type does not match column data type, expecting %s but got %s", t, got))
+}
+
+func errNotNull(col string) *sqlError {
+	return newError("100072", "22000",
+		fmt.Sprintf("NULL result in a non-nullable column '%s'", col))
+}
+
+func errStatementCount(actual, desired int) *sqlError {
+	return newError("000008", "0A000",
+		fmt.Sprintf("Actual statement count %d did not match the desired statement count %d.", actual, desired))
+}
+
+var errCanceled = newError("000604", "57014", "SQL execution canceled")
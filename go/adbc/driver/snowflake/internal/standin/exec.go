@@ -0,0 +1,1198 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statement type ids reported in query responses, clients only care
+// whether the id is in the DML range so that they read the counts of
+// affected rows from the rowset.
+const (
+	stmtTypeSelect int64 = 0x1000
+	stmtTypeInsert int64 = 0x3100
+	stmtTypeMerge  int64 = 0x3400
+	stmtTypeOther  int64 = 0x6000
+)
+
+// result is the outcome of executing a statement, which for DML and
+// DDL is the counts or status message snowflake returns for them.
+type result struct {
+	typeID int64
+	cols   []column
+	rows   [][]interface{}
+}
+
+func (r *result) isDML() bool { return r.typeID >= 0x3000 && r.typeID < 0x4000 }
+
+func statusResult(msg string) *result {
+	return &result{
+		typeID: stmtTypeOther,
+		cols:   []column{{name: "status", typ: typeVarchar, nullable: true}},
+		rows:   [][]interface{}{{msg}},
+	}
+}
+
+var okResult = statusResult("Statement executed successfully.")
+
+// binding is a bound parameter from a query request, either a single
+// value or an array of them for inserting multiple rows at once.
+type binding struct {
+	kind   string
+	scalar *string
+	values []*string
+	array  bool
+}
+
+func (b *binding) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	b.kind = strings.ToUpper(raw.Type)
+	if bytes.HasPrefix(bytes.TrimSpace(raw.Value), []byte("[")) {
+		b.array = true
+		return json.Unmarshal(raw.Value, &b.values)
+	}
+	return json.Unmarshal(raw.Value, &b.scalar)
+}
+
+func (b binding) at(row int) input {
+	if b.array {
+		return input{text: b.values[row], kind: b.kind}
+	}
+	return input{text: b.scalar, kind: b.kind}
+}
+
+// executor runs statements for a session, the caller must hold the
+// server's lock for the duration.
+type executor struct {
+	st    *store
+	sess  *session
+	binds []binding
+}
+
+// newExecutor orders the bindings, which are keyed by their 1-based
+// ordinal as a string.
+func newExecutor(st *store, sess *session, binds map[string]binding) (*executor, error) {
+	e := &executor{st: st, sess: sess, binds: make([]binding, len(binds))}
+	for k, b := range binds {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 1 || i > len(binds) {
+			return nil, newError("002049", "22000", "SQL compilation error:\nBind variable :"+k+" not set.")
+		}
+		e.binds[i-1] = b
+	}
+	return e, nil
+}
+
+// bindRows is the number of rows supplied by array bindings, or 1.
+func (e *executor) bindRows() int {
+	n := 1
+	for _, b := range e.binds {
+		if b.array && len(b.values) > n {
+			n = len(b.values)
+		}
+	}
+	return n
+}
+
+func (e *executor) param(ordinal, row int) (input, error) {
+	if ordinal > len(e.binds) {
+		return input{}, newError("002049", "22000", "SQL compilation error:\nBind variable ? not set.")
+	}
+	b := e.binds[ordinal-1]
+	if b.array && row >= len(b.values) {
+		return input{}, newError("002049", "22000", "SQL compilation error:\nArray bind variables have different lengths.")
+	}
+	return b.at(row), nil
+}
+
+func (e *executor) exec(stmt interface{}) (*result, error) {
+	switch s := stmt.(type) {
+	case *selectStmt:
+		return e.execSelect(s)
+	case *insertStmt:
+		return e.execInsert(s)
+	case *mergeStmt:
+		return e.execMerge(s)
+	case *txnStmt:
+		switch s.kind {
+		case "BEGIN":
+			e.sess.inTxn = true
+		case "COMMIT":
+			e.sess.commit(e.st)
+		case "ROLLBACK":
+			e.sess.rollback()
+		}
+		return okResult, nil
+	case *alterSessionStmt:
+		return e.execAlterSession(s)
+	case *useStmt:
+		return e.execUse(s)
+	case *describeStmt:
+		return e.execDescribe(s)
+	case *showDatabasesStmt:
+		return e.execShowDatabases(), nil
+	}
+
+	// everything else is DDL, which commits any open transaction first
+	e.sess.commit(e.st)
+	switch s := stmt.(type) {
+	case *createTableStmt:
+		return e.execCreateTable(s)
+	case *createStmt:
+		return e.execCreate(s)
+	case *dropStmt:
+		return e.execDrop(s)
+	case *alterTableStmt:
+		return e.execAlterTable(s)
+	}
+	return nil, fmt.Errorf("unhandled statement %T", stmt)
+}
+
+var errNoDatabase = newError("090105", "22000",
+	"Cannot perform operation. This session does not have a current database. Call 'USE DATABASE', or use a qualified name.")
+
+// resolve qualifies a table name with the session's current database
+// and schema.
+func (e *executor) resolve(n objName) (tableKey, error) {
+	key := tableKey{db: e.sess.db, schema: e.sess.schema}
+	switch len(n) {
+	case 1:
+		key.name = n[0]
+	case 2:
+		key.schema, key.name = n[0], n[1]
+	default:
+		key.db, key.schema, key.name = n[0], n[1], n[2]
+	}
+	if key.schema == "" {
+		key.schema = "PUBLIC"
+	}
+	if key.db == "" {
+		return key, errNoDatabase
+	}
+	return key, nil
+}
+
+// resolveSchema qualifies a schema name with the current database.
+func (e *executor) resolveSchema(n objName) (db, schema string, err error) {
+	if len(n) > 2 {
+		return "", "", errSyntax("invalid schema name " + n.String())
+	}
+	if len(n) == 2 {
+		return n[0], n[1], nil
+	}
+	if e.sess.db == "" {
+		return "", "", errNoDatabase
+	}
+	return e.sess.db, n[0], nil
+}
+
+func (e *executor) schemaExists(db, schema string) error {
+	d, ok := e.st.dbs[db]
+	if !ok {
+		return errNotFound("Database", db)
+	}
+	if _, ok := d.schemas[schema]; !ok {
+		return errNotFound("Schema", db+"."+schema)
+	}
+	return nil
+}
+
+func (e *executor) table(n objName) (*table, error) {
+	key, err := e.resolve(n)
+	if err != nil {
+		return nil, err
+	}
+	t := e.sess.lookup(e.st, key)
+	if t == nil {
+		return nil, errNotFound("Table", n.String())
+	}
+	return t, nil
+}
+
+// eval produces the input for a literal or placeholder.
+func (e *executor) eval(x expr, row int) (input, error) {
+	var in input
+	switch {
+	case x.literal != nil:
+		in = *x.literal
+	case x.param > 0:
+		var err error
+		if in, err = e.param(x.param, row); err != nil {
+			return in, err
+		}
+	default:
+		return in, errSyntax("unexpected expression " + x.name())
+	}
+	in.parsed = x.parsed
+	return in, nil
+}
+
+// scalar evaluates an expression in a select list without a table.
+func (e *executor) scalar(x expr) (interface{}, colType, error) {
+	switch x.call {
+	case "":
+	case "SYSTEM$WAIT":
+		// the server does the waiting before taking the lock
+		return fmt.Sprintf("waited %s seconds", *x.args[0].literal.text), typeVarchar, nil
+	case "CURRENT_DATABASE":
+		if e.sess.db == "" {
+			return nil, typeVarchar, nil
+		}
+		return e.sess.db, typeVarchar, nil
+	case "CURRENT_SCHEMA":
+		if e.sess.schema == "" {
+			return nil, typeVarchar, nil
+		}
+		return e.sess.schema, typeVarchar, nil
+	default:
+		return nil, colType{}, errSyntax(x.call + " requires a FROM clause")
+	}
+
+	if x.column != "" {
+		return nil, colType{}, errInvalidIdent(x.column)
+	}
+	in, err := e.eval(x, 0)
+	if err != nil {
+		return nil, colType{}, err
+	}
+	t := in.literalType()
+	if in.parsed {
+		t = colType{name: "VARIANT"}
+	}
+	v, err := coerce(in, t)
+	return v, t, err
+}
+
+func (e *executor) execSelect(s *selectStmt) (*result, error) {
+	if s.sub != nil {
+		res, err := e.execSelect(s.sub)
+		if err != nil {
+			return nil, err
+		}
+		if s.limit >= 0 && s.limit < len(res.rows) {
+			res.rows = res.rows[:s.limit]
+		}
+		return res, nil
+	}
+
+	res := &result{typeID: stmtTypeSelect}
+	if s.from == nil {
+		row := make([]interface{}, len(s.exprs))
+		for i, x := range s.exprs {
+			v, t, err := e.scalar(x)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = v
+			res.cols = append(res.cols, column{name: x.name(), typ: t, nullable: v == nil})
+		}
+		res.rows = [][]interface{}{row}
+		return res, nil
+	}
+
+	t, err := e.table(s.from)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := append([][]interface{}(nil), t.rows...)
+	if len(s.orderBy) > 0 {
+		idx := make([]int, len(s.orderBy))
+		for i, o := range s.orderBy {
+			if idx[i] = t.colIndex(o.column); idx[i] < 0 {
+				return nil, errInvalidIdent(o.column)
+			}
+		}
+		sort.SliceStable(rows, func(a, b int) bool {
+			for i, o := range s.orderBy {
+				c := compareValues(rows[a][idx[i]], rows[b][idx[i]], o.nullsFirst)
+				if o.desc {
+					// nulls were already placed according to nullsFirst
+					if rows[a][idx[i]] != nil && rows[b][idx[i]] != nil {
+						c = -c
+					}
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	if s.limit >= 0 && s.limit < len(rows) {
+		rows = rows[:s.limit]
+	}
+
+	if s.star {
+		res.cols, res.rows = t.cols, rows
+		return res, nil
+	}
+
+	for _, x := range s.exprs {
+		if isAggregate(x) {
+			return e.execAggregate(t, rows, s.exprs)
+		}
+	}
+
+	type projection struct {
+		idx   int
+		value interface{}
+	}
+	projs := make([]projection, len(s.exprs))
+	for i, x := range s.exprs {
+		if x.column == "" {
+			v, typ, err := e.scalar(x)
+			if err != nil {
+				return nil, err
+			}
+			projs[i] = projection{idx: -1, value: v}
+			res.cols = append(res.cols, column{name: x.name(), typ: typ, nullable: v == nil})
+			continue
+		}
+		idx := t.colIndex(x.column)
+		if idx < 0 {
+			return nil, errInvalidIdent(x.column)
+		}
+		projs[i] = projection{idx: idx}
+		col := t.cols[idx]
+		col.name = x.name()
+		res.cols = append(res.cols, col)
+	}
+
+	for _, row := range rows {
+		out := make([]interface{}, len(projs))
+		for i, p := range projs {
+			if p.idx >= 0 {
+				out[i] = row[p.idx]
+			} else {
+				out[i] = p.value
+			}
+		}
+		res.rows = append(res.rows, out)
+	}
+	return res, nil
+}
+
+func isAggregate(x expr) bool {
+	switch x.call {
+	case "COUNT", "APPROX_COUNT_DISTINCT", "MIN", "MAX":
+		return true
+	}
+	return false
+}
+
+// execAggregate computes a select list of aggregates over the rows,
+// there is no GROUP BY.
+func (e *executor) execAggregate(t *table, rows [][]interface{}, exprs []expr) (*result, error) {
+	res := &result{typeID: stmtTypeSelect}
+	out := make([]interface{}, len(exprs))
+	for i, x := range exprs {
+		if !isAggregate(x) {
+			return nil, errSyntax(fmt.Sprintf("'%s' is not a valid group by expression", x.name()))
+		}
+		count := colType{name: "NUMBER", precision: 18}
+		if len(x.args) == 0 {
+			// COUNT(*)
+			out[i] = int64(len(rows))
+			res.cols = append(res.cols, column{name: x.name(), typ: count})
+			continue
+		}
+
+		idx := t.colIndex(x.args[0].column)
+		if idx < 0 {
+			return nil, errInvalidIdent(x.args[0].column)
+		}
+		var n int64
+		var best interface{}
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			v := row[idx]
+			if v == nil {
+				continue
+			}
+			n++
+			seen[fmt.Sprint(v)] = true
+			if best == nil || (x.call == "MIN" && compareValues(v, best, false) < 0) ||
+				(x.call == "MAX" && compareValues(v, best, false) > 0) {
+				best = v
+			}
+		}
+
+		switch {
+		case x.call == "COUNT" && !x.distinct:
+			out[i] = n
+		case x.call == "MIN" || x.call == "MAX":
+			out[i] = best
+			res.cols = append(res.cols, column{name: x.name(), typ: t.cols[idx].typ, nullable: true})
+			continue
+		default:
+			out[i] = int64(len(seen))
+		}
+		res.cols = append(res.cols, column{name: x.name(), typ: count})
+	}
+	res.rows = [][]interface{}{out}
+	return res, nil
+}
+
+// compareValues orders two stored values of the same type, placing
+// NULL first or last.
+func compareValues(a, b interface{}, nullsFirst bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		if nullsFirst {
+			return -1
+		}
+		return 1
+	case b == nil:
+		if nullsFirst {
+			return 1
+		}
+		return -1
+	}
+
+	cmp := func(less, greater bool) int {
+		switch {
+		case less:
+			return -1
+		case greater:
+			return 1
+		}
+		return 0
+	}
+	switch a := a.(type) {
+	case int64:
+		return cmp(a < b.(int64), a > b.(int64))
+	case int32:
+		return cmp(a < b.(int32), a > b.(int32))
+	case float64:
+		return cmp(a < b.(float64), a > b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	case bool:
+		return cmp(!a && b.(bool), a && !b.(bool))
+	case time.Time:
+		return cmp(a.Before(b.(time.Time)), a.After(b.(time.Time)))
+	}
+	return 0
+}
+
+func dmlResult(typeID int64, names []string, counts ...int64) *result {
+	res := &result{typeID: typeID, rows: [][]interface{}{make([]interface{}, len(counts))}}
+	for i, n := range counts {
+		res.cols = append(res.cols, column{name: names[i], typ: colType{name: "NUMBER", precision: 38}})
+		res.rows[0][i] = n
+	}
+	return res
+}
+
+// newRow coerces the inputs for the named columns into a full row of
+// the table, checking the NOT NULL constraints.
+func newRow(t *table, idx []int, inputs []input) ([]interface{}, error) {
+	row := make([]interface{}, len(t.cols))
+	for i, in := range inputs {
+		v, err := coerce(in, t.cols[idx[i]].typ)
+		if err != nil {
+			return nil, err
+		}
+		row[idx[i]] = v
+	}
+	for i, c := range t.cols {
+		if row[i] == nil && !c.nullable {
+			return nil, errNotNull(c.name)
+		}
+	}
+	return row, nil
+}
+
+func (e *executor) columnIndexes(t *table, names []string) ([]int, error) {
+	if len(names) == 0 {
+		idx := make([]int, len(t.cols))
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx, nil
+	}
+
+	idx := make([]int, len(names))
+	for i, n := range names {
+		if idx[i] = t.colIndex(n); idx[i] < 0 {
+			return nil, errInvalidIdent(n)
+		}
+	}
+	return idx, nil
+}
+
+func (e *executor) execInsert(s *insertStmt) (*result, error) {
+	key, err := e.resolve(s.table)
+	if err != nil {
+		return nil, err
+	}
+	t := e.sess.writable(e.st, key)
+	if t == nil {
+		return nil, errNotFound("Table", s.table.String())
+	}
+
+	idx, err := e.columnIndexes(t, s.cols)
+	if err != nil {
+		return nil, err
+	}
+
+	width := len(idx)
+	if s.project != nil {
+		width = len(s.project)
+	}
+	for _, vals := range s.rows {
+		n := len(vals)
+		if s.project != nil {
+			// the select list decides the values inserted
+			n = width
+		}
+		if n != len(idx) {
+			return nil, newError("002020", "21S01", fmt.Sprintf(
+				"SQL compilation error:\nInsert value list does not match column list expecting %d but got %d", len(idx), n))
+		}
+	}
+
+	var added [][]interface{}
+	for _, vals := range s.rows {
+		for r := 0; r < e.bindRows(); r++ {
+			inputs := make([]input, len(vals))
+			for i, x := range vals {
+				if inputs[i], err = e.eval(x, r); err != nil {
+					return nil, err
+				}
+			}
+
+			if s.project != nil {
+				projected := make([]input, len(s.project))
+				for i, x := range s.project {
+					if x.column == "" {
+						if projected[i], err = e.eval(x, r); err != nil {
+							return nil, err
+						}
+						continue
+					}
+					var n int
+					if _, err := fmt.Sscanf(x.column, "COLUMN%d", &n); err != nil || n < 1 || n > len(inputs) {
+						return nil, errInvalidIdent(x.column)
+					}
+					projected[i] = inputs[n-1]
+					projected[i].parsed = x.parsed
+				}
+				inputs = projected
+			}
+
+			row, err := newRow(t, idx, inputs)
+			if err != nil {
+				return nil, err
+			}
+			added = append(added, row)
+		}
+	}
+
+	t.rows = append(t.rows, added...)
+	e.sess.put(e.st, t)
+	return dmlResult(stmtTypeInsert, []string{"number of rows inserted"}, int64(len(added))), nil
+}
+
+func (e *executor) execMerge(s *mergeStmt) (*result, error) {
+	key, err := e.resolve(s.target)
+	if err != nil {
+		return nil, err
+	}
+	target := e.sess.writable(e.st, key)
+	if target == nil {
+		return nil, errNotFound("Table", s.target.String())
+	}
+	source, err := e.table(s.source)
+	if err != nil {
+		return nil, err
+	}
+
+	colPairs := func(pairs [][2]string) (tgt, src []int, err error) {
+		for _, p := range pairs {
+			ti, si := target.colIndex(p[0]), source.colIndex(p[1])
+			if ti < 0 {
+				return nil, nil, errInvalidIdent(p[0])
+			}
+			if si < 0 {
+				return nil, nil, errInvalidIdent(p[1])
+			}
+			tgt, src = append(tgt, ti), append(src, si)
+		}
+		return
+	}
+
+	onTgt, onSrc, err := colPairs(s.on)
+	if err != nil {
+		return nil, err
+	}
+	setTgt, setSrc, err := colPairs(s.set)
+	if err != nil {
+		return nil, err
+	}
+	insPairs := make([][2]string, len(s.insertCols))
+	for i := range insPairs {
+		insPairs[i] = [2]string{s.insertCols[i], s.insertFromSource[i]}
+	}
+	insTgt, insSrc, err := colPairs(insPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	// castTo converts the source row's value in column si to the type
+	// of the target's column ti
+	castTo := func(row []interface{}, si, ti int) (interface{}, error) {
+		return cast(row[si], source.cols[si].typ, target.cols[ti].typ)
+	}
+
+	// rows are matched on the text of their key values, which are
+	// never NULL in a match
+	rowKey := func(row []interface{}, idx []int, typs func(int) colType) (string, bool) {
+		parts := make([]string, len(idx))
+		for i, c := range idx {
+			s := formatValue(row[c], typs(c))
+			if s == nil {
+				return "", false
+			}
+			parts[i] = *s
+		}
+		return strings.Join(parts, "\x00"), true
+	}
+	targetType := func(i int) colType { return target.cols[i].typ }
+
+	existing := make(map[string][]int)
+	for i, row := range target.rows {
+		if k, ok := rowKey(row, onTgt, targetType); ok {
+			existing[k] = append(existing[k], i)
+		}
+	}
+
+	var inserted, updated int64
+	for _, srow := range source.rows {
+		keyRow := make([]interface{}, len(target.cols))
+		for i := range onSrc {
+			if keyRow[onTgt[i]], err = castTo(srow, onSrc[i], onTgt[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		if k, ok := rowKey(keyRow, onTgt, targetType); ok && len(existing[k]) > 0 {
+			if len(setTgt) == 0 {
+				continue
+			}
+			for _, ri := range existing[k] {
+				row := append([]interface{}(nil), target.rows[ri]...)
+				for i := range setTgt {
+					if row[setTgt[i]], err = castTo(srow, setSrc[i], setTgt[i]); err != nil {
+						return nil, err
+					}
+					if row[setTgt[i]] == nil && !target.cols[setTgt[i]].nullable {
+						return nil, errNotNull(target.cols[setTgt[i]].name)
+					}
+				}
+				target.rows[ri] = row
+				updated++
+			}
+			continue
+		}
+
+		if len(insTgt) == 0 {
+			continue
+		}
+		row := make([]interface{}, len(target.cols))
+		for i := range insTgt {
+			if row[insTgt[i]], err = castTo(srow, insSrc[i], insTgt[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i, c := range target.cols {
+			if row[i] == nil && !c.nullable {
+				return nil, errNotNull(c.name)
+			}
+		}
+		target.rows = append(target.rows, row)
+		inserted++
+	}
+
+	e.sess.put(e.st, target)
+	return dmlResult(stmtTypeMerge, []string{"number of rows inserted", "number of rows updated"},
+		inserted, updated), nil
+}
+
+func (e *executor) execAlterSession(s *alterSessionStmt) (*result, error) {
+	for k, v := range s.params {
+		if k != "AUTOCOMMIT" {
+			continue
+		}
+		on, err := coerce(textInput(v), typeBoolean)
+		if err != nil {
+			return nil, err
+		}
+		e.sess.autocommit = on.(bool)
+		if e.sess.autocommit {
+			e.sess.commit(e.st)
+		}
+	}
+	return okResult, nil
+}
+
+func (e *executor) execUse(s *useStmt) (*result, error) {
+	kind := s.kind
+	if kind == "" {
+		kind = "DATABASE"
+		if len(s.name) == 2 {
+			kind = "SCHEMA"
+		}
+	}
+
+	if kind == "DATABASE" {
+		if len(s.name) != 1 {
+			return nil, errSyntax("invalid database name " + s.name.String())
+		}
+		if _, ok := e.st.dbs[s.name[0]]; !ok {
+			return nil, errNotFound("Database", s.name[0])
+		}
+		e.sess.db, e.sess.schema = s.name[0], "PUBLIC"
+		return okResult, nil
+	}
+
+	db, schema, err := e.resolveSchema(s.name)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.schemaExists(db, schema); err != nil {
+		return nil, err
+	}
+	e.sess.db, e.sess.schema = db, schema
+	return okResult, nil
+}
+
+func textColumns(names ...string) []column {
+	cols := make([]column, len(names))
+	for i, n := range names {
+		cols[i] = column{name: n, typ: typeVarchar, nullable: true}
+	}
+	return cols
+}
+
+func (e *executor) execDescribe(s *describeStmt) (*result, error) {
+	t, err := e.table(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{typeID: stmtTypeOther, cols: textColumns("name", "type", "kind", "null?",
+		"default", "primary key", "unique key", "check", "expression", "comment", "policy name")}
+	for _, c := range t.cols {
+		isNull := "N"
+		if c.nullable {
+			isNull = "Y"
+		}
+		res.rows = append(res.rows, []interface{}{c.name, c.typ.String(), "COLUMN", isNull,
+			nil, "N", "N", nil, nil, nil, nil})
+	}
+	return res, nil
+}
+
+func (e *executor) execShowDatabases() *result {
+	res := &result{typeID: stmtTypeOther, cols: append([]column{
+		{name: "created_on", typ: colType{name: "TIMESTAMP_LTZ", scale: 3}, nullable: true}},
+		textColumns("name", "kind", "database_name", "schema_name")...)}
+	for _, name := range e.st.databaseNames() {
+		created := e.st.dbs[name].created.UTC().Truncate(time.Millisecond)
+		res.rows = append(res.rows, []interface{}{created, name, nil, nil, nil})
+	}
+	return res
+}
+
+func (e *executor) execCreateTable(s *createTableStmt) (*result, error) {
+	key, err := e.resolve(s.name)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.schemaExists(key.db, key.schema); err != nil {
+		return nil, err
+	}
+
+	existing := e.st.tables[key]
+	if s.temporary {
+		existing = e.sess.temp[key]
+	}
+	if existing != nil && !s.orReplace {
+		if s.ifNotExists {
+			return statusResult(key.name + " already exists, statement succeeded."), nil
+		}
+		return nil, errExists(key.name)
+	}
+
+	t := &table{key: key, temporary: s.temporary, created: time.Now()}
+	for _, def := range s.cols {
+		if t.colIndex(def.name) >= 0 {
+			return nil, newError("002025", "42S21",
+				fmt.Sprintf("SQL compilation error:\nduplicate column name '%s'", def.name))
+		}
+		t.cols = append(t.cols, column{name: def.name, typ: def.typ, nullable: !def.notNull})
+	}
+
+	if s.temporary {
+		e.sess.temp[key] = t
+	} else {
+		e.st.tables[key] = t
+	}
+	return statusResult(fmt.Sprintf("Table %s successfully created.", key.name)), nil
+}
+
+func (e *executor) execCreate(s *createStmt) (*result, error) {
+	if s.kind == "DATABASE" {
+		if len(s.name) != 1 {
+			return nil, errSyntax("invalid database name " + s.name.String())
+		}
+		name := s.name[0]
+		if _, ok := e.st.dbs[name]; ok && !s.orReplace {
+			if s.ifNotExists {
+				return statusResult(name + " already exists, statement succeeded."), nil
+			}
+			return nil, errExists(name)
+		}
+		e.dropDatabase(name)
+		e.st.createDatabase(name)
+		// like snowflake, creating a database makes it the current one
+		e.sess.db, e.sess.schema = name, "PUBLIC"
+		return statusResult(fmt.Sprintf("Database %s successfully created.", name)), nil
+	}
+
+	db, schema, err := e.resolveSchema(s.name)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := e.st.dbs[db]
+	if !ok {
+		return nil, errNotFound("Database", db)
+	}
+	if _, ok := d.schemas[schema]; ok && !s.orReplace {
+		if s.ifNotExists {
+			return statusResult(schema + " already exists, statement succeeded."), nil
+		}
+		return nil, errExists(schema)
+	}
+	e.dropSchema(db, schema)
+	d.schemas[schema] = time.Now()
+	e.sess.db, e.sess.schema = db, schema
+	return statusResult(fmt.Sprintf("Schema %s successfully created.", schema)), nil
+}
+
+func (e *executor) dropSchema(db, schema string) {
+	for key := range e.st.tables {
+		if key.db == db && key.schema == schema {
+			delete(e.st.tables, key)
+		}
+	}
+	if d, ok := e.st.dbs[db]; ok {
+		delete(d.schemas, schema)
+	}
+}
+
+func (e *executor) dropDatabase(name string) {
+	for key := range e.st.tables {
+		if key.db == name {
+			delete(e.st.tables, key)
+		}
+	}
+	delete(e.st.dbs, name)
+}
+
+func (e *executor) execDrop(s *dropStmt) (*result, error) {
+	var (
+		found bool
+		name  string
+	)
+	switch s.kind {
+	case "TABLE":
+		key, err := e.resolve(s.name)
+		if err != nil {
+			return nil, err
+		}
+		name = key.name
+		if _, found = e.sess.temp[key]; found {
+			delete(e.sess.temp, key)
+		} else if _, found = e.st.tables[key]; found {
+			delete(e.st.tables, key)
+		}
+	case "SCHEMA":
+		db, schema, err := e.resolveSchema(s.name)
+		if err != nil {
+			return nil, err
+		}
+		name = schema
+		if found = e.schemaExists(db, schema) == nil; found {
+			e.dropSchema(db, schema)
+		}
+	case "DATABASE":
+		name = s.name.String()
+		if _, found = e.st.dbs[name]; found {
+			e.dropDatabase(name)
+		}
+	}
+
+	if !found {
+		if s.ifExists {
+			return statusResult(fmt.Sprintf("Drop statement executed successfully (%s already dropped).", name)), nil
+		}
+		kind := strings.ToUpper(s.kind[:1]) + strings.ToLower(s.kind[1:])
+		return nil, errNotFound(kind, s.name.String())
+	}
+	return statusResult(fmt.Sprintf("%s successfully dropped.", name)), nil
+}
+
+func (e *executor) execAlterTable(s *alterTableStmt) (*result, error) {
+	orig, err := e.table(s.name)
+	if err != nil {
+		return nil, err
+	}
+	t := orig.clone()
+
+	idx := t.colIndex(s.col.name)
+	if s.action != "ADD" && idx < 0 {
+		return nil, errInvalidIdent(s.col.name)
+	}
+
+	switch s.action {
+	case "ADD":
+		if idx >= 0 {
+			return nil, newError("002025", "42S21",
+				fmt.Sprintf("SQL compilation error:\ncolumn '%s' already exists", s.col.name))
+		}
+		if s.col.notNull && len(t.rows) > 0 {
+			return nil, errNotNull(s.col.name)
+		}
+		t.cols = append(t.cols, column{name: s.col.name, typ: s.col.typ, nullable: !s.col.notNull})
+		for i, row := range t.rows {
+			t.rows[i] = append(append([]interface{}(nil), row...), nil)
+		}
+	case "SET DATA TYPE":
+		from := t.cols[idx].typ
+		if !from.canWidenTo(s.col.typ) {
+			return nil, newError("002108", "22000", fmt.Sprintf(
+				"SQL compilation error: cannot change column %s from type %s to %s", s.col.name, from, s.col.typ))
+		}
+		t.cols[idx].typ = s.col.typ
+	case "DROP NOT NULL":
+		t.cols[idx].nullable = true
+	case "SET NOT NULL":
+		for _, row := range t.rows {
+			if row[idx] == nil {
+				return nil, errNotNull(s.col.name)
+			}
+		}
+		t.cols[idx].nullable = false
+	}
+
+	if t.temporary {
+		e.sess.temp[t.key] = t
+	} else {
+		e.st.tables[t.key] = t
+	}
+	return okResult, nil
+}
+
+// Metadata queries are snowflake scripting blocks that UNION a view of
+// INFORMATION_SCHEMA across every database and filter the result, only
+// the view and the filter are looked at rather than running the script.
+var (
+	scriptViewRe  = regexp.MustCompile(`\.INFORMATION_SCHEMA\.(SCHEMATA|TABLES|COLUMNS)'`)
+	scriptCondRe  = regexp.MustCompile(`(?i)(\w+) (I?LIKE) \\'(.*?)\\'`)
+	scriptTypesRe = regexp.MustCompile(`(?i)TABLE_TYPE IN \(\\'(.*?)\\'\)`)
+)
+
+// isScript reports whether a query is a scripting block.
+func isScript(query string) bool {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	return strings.HasPrefix(q, "DECLARE") || strings.HasPrefix(q, "BEGIN\n") ||
+		strings.HasPrefix(q, "EXECUTE IMMEDIATE")
+}
+
+// likeMatch implements LIKE and ILIKE patterns, where % matches any
+// run of characters, _ any one and backslash escapes either.
+func likeMatch(pattern, s string, fold bool) bool {
+	var b strings.Builder
+	if fold {
+		b.WriteString("(?is)")
+	} else {
+		b.WriteString("(?s)")
+	}
+	b.WriteByte('^')
+	rs := []rune(pattern)
+	for i := 0; i < len(rs); i++ {
+		switch c := rs[i]; {
+		case c == '\\' && i+1 < len(rs):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(rs[i])))
+		case c == '%':
+			b.WriteString(".*")
+		case c == '_':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteByte('$')
+	return regexp.MustCompile(b.String()).MatchString(s)
+}
+
+type scriptFilter struct {
+	column, op, pattern string
+}
+
+func (f scriptFilter) matches(v string) bool {
+	return likeMatch(f.pattern, v, strings.EqualFold(f.op, "ILIKE"))
+}
+
+func (e *executor) execScript(query string) (*result, error) {
+	m := scriptViewRe.FindStringSubmatch(query)
+	if m == nil {
+		return nil, errUnsupported(query)
+	}
+
+	filters := make(map[string]scriptFilter)
+	for _, c := range scriptCondRe.FindAllStringSubmatch(query, -1) {
+		col := strings.ToUpper(c[1])
+		if col == "SHARES" {
+			// SHOW SHARES LIKE in the loop over the databases
+			continue
+		}
+		filters[col] = scriptFilter{column: col, op: c[2], pattern: c[3]}
+	}
+	match := func(col, v string) bool {
+		f, ok := filters[col]
+		return !ok || f.matches(v)
+	}
+
+	var tableTypes map[string]bool
+	if m := scriptTypesRe.FindStringSubmatch(query); m != nil {
+		tableTypes = make(map[string]bool)
+		for _, t := range strings.Split(m[1], `\',\'`) {
+			tableTypes[t] = true
+		}
+	}
+
+	// the statistics select the sizes of the tables instead of their type
+	sizes := strings.Contains(strings.ToUpper(query), "TABLE_TYPE, ROW_COUNT, BYTES")
+
+	res := &result{typeID: stmtTypeSelect}
+	switch m[1] {
+	case "SCHEMATA":
+		res.cols = textColumns("CATALOG_NAME", "SCHEMA_NAME")
+		for _, db := range e.st.databaseNames() {
+			if !match("CATALOG_NAME", db) {
+				continue
+			}
+			schemas := make([]string, 0, len(e.st.dbs[db].schemas))
+			for s := range e.st.dbs[db].schemas {
+				schemas = append(schemas, s)
+			}
+			sort.Strings(schemas)
+			for _, s := range schemas {
+				if match("SCHEMA_NAME", s) {
+					res.rows = append(res.rows, []interface{}{db, s})
+				}
+			}
+		}
+		return res, nil
+	case "TABLES":
+		if sizes {
+			number := colType{name: "NUMBER", precision: 38}
+			res.cols = append(textColumns("TABLE_CATALOG", "TABLE_SCHEMA", "TABLE_NAME", "TABLE_TYPE"),
+				column{name: "ROW_COUNT", typ: number, nullable: true},
+				column{name: "BYTES", typ: number, nullable: true})
+		} else {
+			res.cols = textColumns("TABLE_CATALOG", "TABLE_SCHEMA", "TABLE_NAME", "TABLE_TYPE")
+		}
+	case "COLUMNS":
+		number := colType{name: "NUMBER", precision: 9}
+		res.cols = append(textColumns("TABLE_CATALOG", "TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME"),
+			column{name: "ORDINAL_POSITION", typ: number},
+			column{name: "IS_NULLABLE::BOOLEAN", typ: typeBoolean},
+			column{name: "DATA_TYPE", typ: typeVarchar},
+			column{name: "NUMERIC_PRECISION", typ: number, nullable: true},
+			column{name: "NUMERIC_PRECISION_RADIX", typ: number, nullable: true},
+			column{name: "NUMERIC_SCALE", typ: number, nullable: true},
+			column{name: "IS_IDENTITY::BOOLEAN", typ: typeBoolean})
+		res.cols = append(res.cols, textColumns("IDENTITY_GENERATION", "IDENTITY_INCREMENT", "COMMENT")...)
+	}
+
+	for _, t := range e.sess.visibleTables(e.st) {
+		if _, ok := e.st.dbs[t.key.db]; !ok {
+			continue
+		}
+		if !match("TABLE_CATALOG", t.key.db) || !match("TABLE_SCHEMA", t.key.schema) ||
+			!match("TABLE_NAME", t.key.name) {
+			continue
+		}
+
+		tableType := "BASE TABLE"
+		if t.temporary {
+			tableType = "TEMPORARY TABLE"
+		}
+		if tableTypes != nil && !tableTypes[tableType] {
+			continue
+		}
+
+		if m[1] == "TABLES" {
+			if sizes {
+				res.rows = append(res.rows, []interface{}{t.key.db, t.key.schema, t.key.name,
+					tableType, int64(len(t.rows)), t.bytes()})
+			} else {
+				res.rows = append(res.rows, []interface{}{t.key.db, t.key.schema, t.key.name, tableType})
+			}
+			continue
+		}
+
+		for i, c := range t.cols {
+			if !match("COLUMN_NAME", c.name) {
+				continue
+			}
+			var precision, radix, scale interface{}
+			if c.typ.name == "NUMBER" {
+				precision, radix, scale = int64(c.typ.precision), int64(10), int64(c.typ.scale)
+			}
+			res.rows = append(res.rows, []interface{}{t.key.db, t.key.schema, t.key.name, c.name,
+				int64(i + 1), c.nullable, c.typ.infoName(), precision, radix, scale, false, nil, nil, nil})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,1018 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuoted
+	tokString
+	tokNumber
+	tokParam
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	// val is the identifier upper cased, the unquoted contents of a
+	// quoted identifier or string, or the text of anything else
+	val string
+}
+
+func lex(query string) ([]token, error) {
+	var (
+		toks []token
+		rs   = []rune(query)
+	)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == '\\' && c == '\'' && j+1 < len(rs) {
+					j++
+					b.WriteRune(rs[j])
+					continue
+				}
+				if rs[j] == c {
+					if j+1 < len(rs) && rs[j+1] == c {
+						b.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, errSyntax("unterminated quoted string or identifier")
+			}
+			kind := tokString
+			if c == '"' {
+				kind = tokQuoted
+			}
+			toks = append(toks, token{kind: kind, val: b.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{kind: tokNumber, val: string(rs[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '$') {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, val: strings.ToUpper(string(rs[i:j]))})
+			i = j
+		case c == '?':
+			toks = append(toks, token{kind: tokParam, val: "?"})
+			i++
+		case c == ':' && i+1 < len(rs) && rs[i+1] == ':':
+			toks = append(toks, token{kind: tokSymbol, val: "::"})
+			i += 2
+		case strings.ContainsRune("(),.=*;-+", c):
+			toks = append(toks, token{kind: tokSymbol, val: string(c)})
+			i++
+		default:
+			return nil, errSyntax(fmt.Sprintf("syntax error unexpected '%c'", c))
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+// objName is a possibly qualified name of a table, schema or database
+// as written, with identifiers already resolved to their stored case.
+type objName []string
+
+func (n objName) String() string { return strings.Join(n, ".") }
+
+// expr is a value in a select list or VALUES clause.
+type expr struct {
+	// exactly one of these is set
+	literal *input
+	param   int    // 1-based ordinal of a ? placeholder
+	column  string // a column reference
+	call    string // an aggregate, SYSTEM$WAIT, CURRENT_DATABASE, CURRENT_SCHEMA
+
+	args []expr
+	// distinct is set for COUNT(DISTINCT ...)
+	distinct bool
+	// parsed is set if the value is wrapped in PARSE_JSON, with
+	// TO_ARRAY or TO_OBJECT around it making no difference here
+	parsed bool
+	alias  string
+}
+
+func (e expr) name() string {
+	switch {
+	case e.alias != "":
+		return e.alias
+	case e.column != "":
+		return e.column
+	case e.call != "":
+		return e.call + "(" + ")"
+	case e.param > 0:
+		return "?"
+	case e.literal != nil && e.literal.text != nil:
+		if e.literal.kind == "TEXT" {
+			return "'" + *e.literal.text + "'"
+		}
+		return strings.ToUpper(*e.literal.text)
+	}
+	return "NULL"
+}
+
+type orderItem struct {
+	column     string
+	desc       bool
+	nullsFirst bool
+}
+
+type selectStmt struct {
+	star    bool
+	exprs   []expr
+	from    objName
+	orderBy []orderItem
+	limit   int
+	// sub is the query selected from instead of a table, only
+	// SELECT * FROM (sub) [LIMIT n] is supported
+	sub *selectStmt
+}
+
+type insertStmt struct {
+	table objName
+	cols  []string
+	// project is the select list of INSERT ... SELECT ... FROM VALUES,
+	// referring to the values by column1, column2 and so on
+	project []expr
+	rows    [][]expr
+}
+
+type columnDef struct {
+	name    string
+	typ     colType
+	notNull bool
+}
+
+type createTableStmt struct {
+	name                              objName
+	orReplace, temporary, ifNotExists bool
+	cols                              []columnDef
+}
+
+type createStmt struct {
+	kind                   string // SCHEMA or DATABASE
+	name                   objName
+	orReplace, ifNotExists bool
+}
+
+type dropStmt struct {
+	kind     string // TABLE, SCHEMA or DATABASE
+	name     objName
+	ifExists bool
+}
+
+type alterTableStmt struct {
+	name objName
+	// one of ADD, SET DATA TYPE, DROP NOT NULL or SET NOT NULL
+	action string
+	col    columnDef
+}
+
+type alterSessionStmt struct {
+	params map[string]string
+}
+
+type describeStmt struct {
+	name objName
+}
+
+type showDatabasesStmt struct{}
+
+type txnStmt struct {
+	kind string // BEGIN, COMMIT or ROLLBACK
+}
+
+type useStmt struct {
+	kind string // DATABASE, SCHEMA or empty
+	name objName
+}
+
+// mergeStmt is the form of MERGE used for ingestion, matching rows
+// on equal key columns, updating the matched ones and inserting the
+// rest.
+type mergeStmt struct {
+	target, source   objName
+	on               [][2]string // target column, source column
+	set              [][2]string
+	insertCols       []string
+	insertFromSource []string
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func parse(query string) (interface{}, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: toks}
+	stmt, err := p.statement()
+	if err != nil {
+		if _, ok := err.(*sqlError); !ok {
+			err = errUnsupported(query)
+		}
+		return nil, err
+	}
+	p.acceptSymbol(";")
+	if p.peek().kind != tokEOF {
+		return nil, errUnsupported(query)
+	}
+	return stmt, nil
+}
+
+// parseStatements parses a request of one or more statements separated
+// by semicolons, as sent with the MULTI_STATEMENT_COUNT parameter.
+func parseStatements(query string) ([]interface{}, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []interface{}
+	p := &parser{toks: toks}
+	for p.peek().kind != tokEOF {
+		if p.acceptSymbol(";") {
+			continue
+		}
+		stmt, err := p.statement()
+		if err != nil {
+			if _, ok := err.(*sqlError); !ok {
+				err = errUnsupported(query)
+			}
+			return nil, err
+		}
+		if !p.acceptSymbol(";") && p.peek().kind != tokEOF {
+			return nil, errUnsupported(query)
+		}
+		stmts = append(stmts, stmt)
+	}
+	if len(stmts) == 0 {
+		return nil, errSyntax("Empty SQL statement.")
+	}
+	return stmts, nil
+}
+
+var errNoMatch = fmt.Errorf("unsupported statement")
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(kws ...string) bool {
+	for i, kw := range kws {
+		if p.pos+i >= len(p.toks) {
+			return false
+		}
+		t := p.toks[p.pos+i]
+		if t.kind != tokIdent || t.val != kw {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) acceptKeyword(kws ...string) bool {
+	if p.isKeyword(kws...) {
+		p.pos += len(kws)
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kws ...string) error {
+	if !p.acceptKeyword(kws...) {
+		return errNoMatch
+	}
+	return nil
+}
+
+func (p *parser) acceptSymbol(s string) bool {
+	if t := p.peek(); t.kind == tokSymbol && t.val == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return errNoMatch
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	switch t := p.peek(); t.kind {
+	case tokIdent, tokQuoted:
+		p.pos++
+		return t.val, nil
+	}
+	return "", errNoMatch
+}
+
+// name parses a name of up to three parts, allowing the middle part to
+// be empty as in db..table.
+func (p *parser) name() (objName, error) {
+	first, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	n := objName{first}
+	for len(n) < 3 && p.acceptSymbol(".") {
+		if len(n) == 1 && p.acceptSymbol(".") {
+			n = append(n, "")
+		}
+		part, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		n = append(n, part)
+	}
+	return n, nil
+}
+
+func (p *parser) statement() (interface{}, error) {
+	switch {
+	case p.acceptKeyword("SELECT"):
+		return p.selectStmt()
+	case p.acceptKeyword("INSERT", "INTO"):
+		return p.insertStmt()
+	case p.acceptKeyword("CREATE"):
+		return p.createStmt()
+	case p.acceptKeyword("DROP"):
+		return p.dropStmt()
+	case p.acceptKeyword("ALTER", "TABLE"):
+		return p.alterTableStmt()
+	case p.acceptKeyword("ALTER", "SESSION", "SET"):
+		return p.alterSessionStmt()
+	case p.acceptKeyword("DESC"), p.acceptKeyword("DESCRIBE"):
+		if err := p.expectKeyword("TABLE"); err != nil {
+			return nil, err
+		}
+		n, err := p.name()
+		return &describeStmt{name: n}, err
+	case p.acceptKeyword("SHOW", "TERSE", "DATABASES"), p.acceptKeyword("SHOW", "DATABASES"):
+		return &showDatabasesStmt{}, nil
+	case p.acceptKeyword("BEGIN"), p.acceptKeyword("START", "TRANSACTION"):
+		_ = p.acceptKeyword("TRANSACTION") || p.acceptKeyword("WORK")
+		return &txnStmt{kind: "BEGIN"}, nil
+	case p.acceptKeyword("COMMIT"):
+		_ = p.acceptKeyword("WORK")
+		return &txnStmt{kind: "COMMIT"}, nil
+	case p.acceptKeyword("ROLLBACK"):
+		_ = p.acceptKeyword("WORK")
+		return &txnStmt{kind: "ROLLBACK"}, nil
+	case p.acceptKeyword("USE"):
+		u := &useStmt{}
+		switch {
+		case p.acceptKeyword("DATABASE"):
+			u.kind = "DATABASE"
+		case p.acceptKeyword("SCHEMA"):
+			u.kind = "SCHEMA"
+		}
+		var err error
+		u.name, err = p.name()
+		return u, err
+	case p.acceptKeyword("MERGE", "INTO"):
+		return p.mergeStmt()
+	}
+	return nil, errNoMatch
+}
+
+// expr parses a literal, placeholder, column reference or one of the
+// few supported function calls.
+func (p *parser) expr() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokParam:
+		p.pos++
+		return expr{param: -1}, nil
+	case tokString:
+		p.pos++
+		return expr{literal: &input{text: &t.val, kind: "TEXT"}}, nil
+	case tokNumber:
+		p.pos++
+		return expr{literal: &input{text: &t.val, kind: "FIXED"}}, nil
+	case tokSymbol:
+		if t.val == "-" || t.val == "+" {
+			p.pos++
+			if n := p.peek(); n.kind == tokNumber {
+				p.pos++
+				v := t.val + n.val
+				return expr{literal: &input{text: &v, kind: "FIXED"}}, nil
+			}
+		}
+		return expr{}, errNoMatch
+	case tokQuoted:
+		p.pos++
+		return expr{column: t.val}, nil
+	case tokIdent:
+	default:
+		return expr{}, errNoMatch
+	}
+
+	p.pos++
+	switch t.val {
+	case "NULL":
+		return expr{literal: &input{kind: "TEXT"}}, nil
+	case "TRUE", "FALSE":
+		v := strings.ToLower(t.val)
+		return expr{literal: &input{text: &v, kind: "BOOLEAN"}}, nil
+	}
+
+	if !p.acceptSymbol("(") {
+		return expr{column: t.val}, nil
+	}
+
+	e := expr{call: t.val}
+	if t.val == "COUNT" {
+		if p.acceptSymbol("*") {
+			return e, p.expectSymbol(")")
+		}
+		e.distinct = p.acceptKeyword("DISTINCT")
+	}
+	for !p.acceptSymbol(")") {
+		if len(e.args) > 0 {
+			if err := p.expectSymbol(","); err != nil {
+				return e, err
+			}
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return e, err
+		}
+		e.args = append(e.args, arg)
+	}
+
+	switch e.call {
+	case "PARSE_JSON":
+		if len(e.args) != 1 {
+			return e, errNoMatch
+		}
+		arg := e.args[0]
+		arg.parsed = true
+		return arg, nil
+	case "TO_ARRAY", "TO_OBJECT", "TO_VARIANT":
+		if len(e.args) != 1 {
+			return e, errNoMatch
+		}
+		return e.args[0], nil
+	case "SYSTEM$WAIT":
+		if len(e.args) == 0 || e.args[0].literal == nil {
+			return e, errNoMatch
+		}
+	case "COUNT", "APPROX_COUNT_DISTINCT", "MIN", "MAX":
+		if len(e.args) > 1 || (len(e.args) == 0 && e.call != "COUNT") ||
+			(len(e.args) == 1 && e.args[0].column == "") {
+			return e, errNoMatch
+		}
+	case "CURRENT_DATABASE", "CURRENT_SCHEMA":
+	default:
+		return e, errSyntax(fmt.Sprintf("Unknown function %s", e.call))
+	}
+	return e, nil
+}
+
+// numberParams assigns ordinals to the ? placeholders in order.
+func numberParams(rows ...[]expr) {
+	n := 0
+	for _, row := range rows {
+		for i := range row {
+			if row[i].param != 0 {
+				n++
+				row[i].param = n
+			}
+		}
+	}
+}
+
+func (p *parser) exprList() ([]expr, error) {
+	var out []expr
+	for {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("AS") {
+			if e.alias, err = p.ident(); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, e)
+		if !p.acceptSymbol(",") {
+			return out, nil
+		}
+	}
+}
+
+func (p *parser) selectStmt() (*selectStmt, error) {
+	s := &selectStmt{limit: -1}
+	if p.acceptSymbol("*") {
+		s.star = true
+	} else {
+		var err error
+		if s.exprs, err = p.exprList(); err != nil {
+			return nil, err
+		}
+		numberParams(s.exprs)
+	}
+
+	if !p.acceptKeyword("FROM") {
+		if s.star {
+			return nil, errNoMatch
+		}
+		return s, nil
+	}
+
+	var err error
+	if p.acceptSymbol("(") {
+		if !s.star || !p.acceptKeyword("SELECT") {
+			return nil, errNoMatch
+		}
+		if s.sub, err = p.selectStmt(); err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	} else if s.from, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if s.sub == nil && p.acceptKeyword("ORDER", "BY") {
+		for {
+			col, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{column: col}
+			if p.acceptKeyword("DESC") {
+				item.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			// snowflake sorts NULLs as if they were the largest value
+			item.nullsFirst = item.desc
+			if p.acceptKeyword("NULLS", "FIRST") {
+				item.nullsFirst = true
+			} else if p.acceptKeyword("NULLS", "LAST") {
+				item.nullsFirst = false
+			}
+			s.orderBy = append(s.orderBy, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		t := p.next()
+		n, err := strconv.Atoi(t.val)
+		if t.kind != tokNumber || err != nil {
+			return nil, errNoMatch
+		}
+		s.limit = n
+	}
+	return s, nil
+}
+
+func (p *parser) valuesRows() ([][]expr, error) {
+	var rows [][]expr
+	for {
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		row, err := p.exprList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	numberParams(rows...)
+	return rows, nil
+}
+
+func (p *parser) insertStmt() (*insertStmt, error) {
+	var (
+		s   = &insertStmt{}
+		err error
+	)
+	if s.table, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if p.acceptSymbol("(") {
+		for {
+			col, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			s.cols = append(s.cols, col)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("SELECT") {
+		if s.project, err = p.exprList(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("FROM"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	s.rows, err = p.valuesRows()
+	return s, err
+}
+
+// columnType parses a type such as NUMBER(10,2), DOUBLE PRECISION or
+// TIMESTAMP_TZ(9).
+func (p *parser) columnType() (colType, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return colType{}, errNoMatch
+	}
+	name := t.val
+	for _, suffix := range [][]string{{"PRECISION"}, {"VARYING"},
+		{"WITHOUT", "TIME", "ZONE"}, {"WITH", "LOCAL", "TIME", "ZONE"}, {"WITH", "TIME", "ZONE"}} {
+		if p.acceptKeyword(suffix...) {
+			name += " " + strings.Join(suffix, " ")
+			break
+		}
+	}
+
+	var params []int
+	if p.acceptSymbol("(") {
+		for {
+			t := p.next()
+			v, err := strconv.Atoi(t.val)
+			if t.kind != tokNumber || err != nil {
+				return colType{}, errNoMatch
+			}
+			params = append(params, v)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return colType{}, err
+		}
+	}
+	return parseType(name, params)
+}
+
+func (p *parser) columnDef() (columnDef, error) {
+	var (
+		def columnDef
+		err error
+	)
+	if def.name, err = p.ident(); err != nil {
+		return def, err
+	}
+	if def.typ, err = p.columnType(); err != nil {
+		return def, err
+	}
+	if p.acceptKeyword("NOT", "NULL") {
+		def.notNull = true
+	} else {
+		p.acceptKeyword("NULL")
+	}
+	return def, nil
+}
+
+func (p *parser) createStmt() (interface{}, error) {
+	orReplace := p.acceptKeyword("OR", "REPLACE")
+	temporary := p.acceptKeyword("TEMPORARY") || p.acceptKeyword("TEMP") ||
+		p.acceptKeyword("LOCAL", "TEMPORARY")
+
+	switch {
+	case p.acceptKeyword("TABLE"):
+	case !temporary && p.acceptKeyword("SCHEMA"):
+		return p.createObject("SCHEMA", orReplace)
+	case !temporary && p.acceptKeyword("DATABASE"):
+		return p.createObject("DATABASE", orReplace)
+	default:
+		return nil, errNoMatch
+	}
+
+	var (
+		s   = &createTableStmt{orReplace: orReplace, temporary: temporary}
+		err error
+	)
+	s.ifNotExists = p.acceptKeyword("IF", "NOT", "EXISTS")
+	if s.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		def, err := p.columnDef()
+		if err != nil {
+			return nil, err
+		}
+		s.cols = append(s.cols, def)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return s, p.expectSymbol(")")
+}
+
+func (p *parser) createObject(kind string, orReplace bool) (*createStmt, error) {
+	var (
+		s   = &createStmt{kind: kind, orReplace: orReplace}
+		err error
+	)
+	s.ifNotExists = p.acceptKeyword("IF", "NOT", "EXISTS")
+	s.name, err = p.name()
+	return s, err
+}
+
+func (p *parser) dropStmt() (*dropStmt, error) {
+	s := &dropStmt{}
+	for _, kind := range []string{"TABLE", "SCHEMA", "DATABASE"} {
+		if p.acceptKeyword(kind) {
+			s.kind = kind
+		}
+	}
+	if s.kind == "" {
+		return nil, errNoMatch
+	}
+
+	var err error
+	s.ifExists = p.acceptKeyword("IF", "EXISTS")
+	if s.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	_ = p.acceptKeyword("CASCADE") || p.acceptKeyword("RESTRICT")
+	return s, nil
+}
+
+func (p *parser) alterTableStmt() (*alterTableStmt, error) {
+	var (
+		s   = &alterTableStmt{}
+		err error
+	)
+	if s.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("ADD") {
+		p.acceptKeyword("COLUMN")
+		s.action = "ADD"
+		s.col, err = p.columnDef()
+		return s, err
+	}
+
+	if !p.acceptKeyword("ALTER") && !p.acceptKeyword("MODIFY") {
+		return nil, errNoMatch
+	}
+	p.acceptKeyword("COLUMN")
+	if s.col.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.acceptKeyword("SET", "DATA", "TYPE"), p.acceptKeyword("TYPE"):
+		s.action = "SET DATA TYPE"
+		s.col.typ, err = p.columnType()
+	case p.acceptKeyword("DROP", "NOT", "NULL"):
+		s.action = "DROP NOT NULL"
+	case p.acceptKeyword("SET", "NOT", "NULL"):
+		s.action = "SET NOT NULL"
+	default:
+		return nil, errNoMatch
+	}
+	return s, err
+}
+
+func (p *parser) alterSessionStmt() (*alterSessionStmt, error) {
+	s := &alterSessionStmt{params: make(map[string]string)}
+	for {
+		key, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		t := p.next()
+		switch t.kind {
+		case tokIdent, tokString, tokNumber:
+		default:
+			return nil, errNoMatch
+		}
+		s.params[strings.ToUpper(key)] = t.val
+		if !p.acceptSymbol(",") {
+			return s, nil
+		}
+	}
+}
+
+// aliasedColumn parses alias.column, checking the alias is the one given.
+func (p *parser) aliasedColumn(alias string) (string, error) {
+	a, err := p.ident()
+	if err != nil || a != alias {
+		return "", errNoMatch
+	}
+	if err := p.expectSymbol("."); err != nil {
+		return "", err
+	}
+	return p.ident()
+}
+
+func (p *parser) mergeStmt() (*mergeStmt, error) {
+	var (
+		s                   = &mergeStmt{}
+		tAlias, sAlias, col string
+		err                 error
+	)
+	if s.target, err = p.name(); err != nil {
+		return nil, err
+	}
+	p.acceptKeyword("AS")
+	if tAlias, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("USING"); err != nil {
+		return nil, err
+	}
+	if s.source, err = p.name(); err != nil {
+		return nil, err
+	}
+	p.acceptKeyword("AS")
+	if sAlias, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	// pair parses a.x = b.y in either order, returning the target
+	// column followed by the source one
+	pair := func() ([2]string, error) {
+		var out [2]string
+		left, err := p.ident()
+		if err != nil {
+			return out, err
+		}
+		if err := p.expectSymbol("."); err != nil {
+			return out, err
+		}
+		lcol, err := p.ident()
+		if err != nil {
+			return out, err
+		}
+		if err := p.expectSymbol("="); err != nil {
+			return out, err
+		}
+		right := sAlias
+		if left == sAlias {
+			right = tAlias
+		} else if left != tAlias {
+			return out, errNoMatch
+		}
+		rcol, err := p.aliasedColumn(right)
+		if err != nil {
+			return out, err
+		}
+		if left == tAlias {
+			return [2]string{lcol, rcol}, nil
+		}
+		return [2]string{rcol, lcol}, nil
+	}
+
+	if err := p.expectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	for {
+		on, err := pair()
+		if err != nil {
+			return nil, err
+		}
+		s.on = append(s.on, on)
+		if !p.acceptKeyword("AND") {
+			break
+		}
+	}
+
+	if p.acceptKeyword("WHEN", "MATCHED", "THEN", "UPDATE", "SET") {
+		for {
+			set, err := pair()
+			if err != nil {
+				return nil, err
+			}
+			s.set = append(s.set, set)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("WHEN", "NOT", "MATCHED", "THEN", "INSERT") {
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		for {
+			if col, err = p.ident(); err != nil {
+				return nil, err
+			}
+			s.insertCols = append(s.insertCols, col)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("VALUES"); err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		for {
+			if col, err = p.aliasedColumn(sAlias); err != nil {
+				return nil, err
+			}
+			s.insertFromSource = append(s.insertFromSource, col)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		if len(s.insertCols) != len(s.insertFromSource) {
+			return nil, errSyntax("Insert value list does not match column list")
+		}
+	}
+	return s, nil
+}
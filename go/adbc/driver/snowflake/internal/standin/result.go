@@ -0,0 +1,229 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+type rowType struct {
+	Name       string `json:"name"`
+	ByteLength int    `json:"byteLength"`
+	Length     int    `json:"length"`
+	Type       string `json:"type"`
+	Precision  int    `json:"precision"`
+	Scale      int    `json:"scale"`
+	Nullable   bool   `json:"nullable"`
+}
+
+func rowTypes(cols []column) []rowType {
+	out := make([]rowType, len(cols))
+	for i, c := range cols {
+		out[i] = rowType{Name: c.name, Type: c.typ.rowType(), Precision: c.typ.precision,
+			Scale: c.typ.scale, Length: c.typ.length, ByteLength: c.typ.length, Nullable: c.nullable}
+	}
+	return out
+}
+
+// jsonRowset formats the rows as the rowset of a JSON result, which is
+// how the counts of a DML statement are read.
+func jsonRowset(cols []column, rows [][]interface{}) [][]*string {
+	out := make([][]*string, len(rows))
+	for i, row := range rows {
+		out[i] = make([]*string, len(cols))
+		for j, c := range cols {
+			out[i][j] = formatValue(row[j], c.typ)
+		}
+	}
+	return out
+}
+
+// arrowType is the type snowflake uses for a column of type t in an
+// arrow result, NUMBER columns use the narrowest integer type that
+// holds every value in the batch.
+func arrowType(t colType, rows [][]interface{}, col int) arrow.DataType {
+	switch t.name {
+	case "NUMBER":
+		var lo, hi int64
+		for _, row := range rows {
+			if v, ok := row[col].(int64); ok {
+				if v < lo {
+					lo = v
+				}
+				if v > hi {
+					hi = v
+				}
+			}
+		}
+		switch {
+		case lo >= math.MinInt8 && hi <= math.MaxInt8:
+			return arrow.PrimitiveTypes.Int8
+		case lo >= math.MinInt16 && hi <= math.MaxInt16:
+			return arrow.PrimitiveTypes.Int16
+		case lo >= math.MinInt32 && hi <= math.MaxInt32:
+			return arrow.PrimitiveTypes.Int32
+		}
+		return arrow.PrimitiveTypes.Int64
+	case "FLOAT":
+		return arrow.PrimitiveTypes.Float64
+	case "BINARY":
+		return arrow.BinaryTypes.Binary
+	case "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean
+	case "DATE":
+		return arrow.FixedWidthTypes.Date32
+	case "TIME":
+		return arrow.PrimitiveTypes.Int64
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ":
+		if t.scale <= 7 {
+			return arrow.PrimitiveTypes.Int64
+		}
+		return arrow.StructOf(
+			arrow.Field{Name: "epoch", Type: arrow.PrimitiveTypes.Int64},
+			arrow.Field{Name: "fraction", Type: arrow.PrimitiveTypes.Int32})
+	case "TIMESTAMP_TZ":
+		if t.scale == 0 {
+			return arrow.StructOf(
+				arrow.Field{Name: "epoch", Type: arrow.PrimitiveTypes.Int64},
+				arrow.Field{Name: "timezone", Type: arrow.PrimitiveTypes.Int32})
+		}
+		return arrow.StructOf(
+			arrow.Field{Name: "epoch", Type: arrow.PrimitiveTypes.Int64},
+			arrow.Field{Name: "fraction", Type: arrow.PrimitiveTypes.Int32},
+			arrow.Field{Name: "timezone", Type: arrow.PrimitiveTypes.Int32})
+	}
+	return arrow.BinaryTypes.String
+}
+
+func fieldMetadata(t colType) arrow.Metadata {
+	return arrow.NewMetadata(
+		[]string{"logicalType", "precision", "scale", "charLength"},
+		[]string{t.rowType(), strconv.Itoa(t.precision), strconv.Itoa(t.scale), strconv.Itoa(t.length)})
+}
+
+// scaled returns the nanoseconds ns in units of 10^-scale seconds.
+func scaled(ns int64, scale int) int64 {
+	return ns / int64(math.Pow10(9-scale))
+}
+
+func appendValue(b array.Builder, t colType, v interface{}) {
+	if v == nil {
+		b.AppendNull()
+		return
+	}
+
+	switch t.name {
+	case "NUMBER":
+		n := v.(int64)
+		switch b := b.(type) {
+		case *array.Int8Builder:
+			b.Append(int8(n))
+		case *array.Int16Builder:
+			b.Append(int16(n))
+		case *array.Int32Builder:
+			b.Append(int32(n))
+		case *array.Int64Builder:
+			b.Append(n)
+		}
+	case "FLOAT":
+		b.(*array.Float64Builder).Append(v.(float64))
+	case "BINARY":
+		b.(*array.BinaryBuilder).Append(v.([]byte))
+	case "BOOLEAN":
+		b.(*array.BooleanBuilder).Append(v.(bool))
+	case "DATE":
+		b.(*array.Date32Builder).Append(arrow.Date32(v.(int32)))
+	case "TIME":
+		b.(*array.Int64Builder).Append(scaled(v.(int64), t.scale))
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		tm := v.(time.Time)
+		epoch, frac := tm.Unix(), int32(tm.Nanosecond())
+		if sb, ok := b.(*array.StructBuilder); ok {
+			sb.Append(true)
+			sb.FieldBuilder(0).(*array.Int64Builder).Append(epoch)
+			// TIMESTAMP_TZ(0) is the only struct without the fraction
+			next := 1
+			if t.name != "TIMESTAMP_TZ" || t.scale != 0 {
+				sb.FieldBuilder(1).(*array.Int32Builder).Append(frac)
+				next = 2
+			}
+			if t.name == "TIMESTAMP_TZ" {
+				_, offset := tm.Zone()
+				sb.FieldBuilder(next).(*array.Int32Builder).Append(int32(offset/60 + 1440))
+			}
+			return
+		}
+		b.(*array.Int64Builder).Append(epoch*int64(math.Pow10(t.scale)) + scaled(int64(frac), t.scale))
+	default:
+		b.(*array.StringBuilder).Append(v.(string))
+	}
+}
+
+// arrowStream encodes the rows as an arrow IPC stream of a single
+// record batch, an empty stream still has the schema.
+func arrowStream(cols []column, rows [][]interface{}) ([]byte, error) {
+	fields := make([]arrow.Field, len(cols))
+	for i, c := range cols {
+		fields[i] = arrow.Field{Name: c.name, Type: arrowType(c.typ, rows, i),
+			Nullable: c.nullable, Metadata: fieldMetadata(c.typ)}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	if len(rows) > 0 {
+		bldr := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+		defer bldr.Release()
+		for _, row := range rows {
+			for i, c := range cols {
+				appendValue(bldr.Field(i), c.typ, row[i])
+			}
+		}
+
+		rec := bldr.NewRecord()
+		defer rec.Release()
+		if err := w.Write(rec); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,724 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package standin implements an in-process stand-in for the snowflake
+// REST API backed by an in-memory table store, so that the snowflake
+// driver can be tested without an account.
+//
+// It speaks enough of the protocol used by gosnowflake for the driver:
+// username and password login, query requests returning arrow results
+// split into chunks, chunk downloads, token renewal, heartbeats,
+// aborting queries and closing sessions. The SQL it accepts is the
+// subset the driver and its tests issue (simple SELECTs, INSERT, MERGE,
+// CREATE/DROP/ALTER TABLE, DESC TABLE, transactions and the metadata
+// queries of GetObjects), anything else fails with a syntax error.
+package standin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// the credentials and account name the server accepts
+const (
+	User     = "standin"
+	Password = "standin"
+	Account  = "standin"
+)
+
+// response codes gosnowflake acts upon
+const (
+	codeSessionExpired  = "390112"
+	codeBadCredentials  = "390100"
+	codeBadDefaultObj   = "390201"
+	codeQueryNotRunning = "000605"
+)
+
+// Stats counts the requests a Server has handled.
+type Stats struct {
+	Logins         int
+	FailedLogins   int
+	OpenSessions   int
+	ClosedSessions int
+	Queries        int
+	Heartbeats     int
+	Aborts         int
+	ChunkDownloads int
+	TokenRenewals  int
+}
+
+type chunk struct {
+	data []byte
+	key  string
+}
+
+// Server is a running stand-in, it must be closed once done with.
+type Server struct {
+	ts *httptest.Server
+
+	mu           sync.Mutex
+	st           *store
+	sessions     map[string]*session // by session token
+	masters      map[string]*session // by master token
+	expired      map[string]bool
+	nextID       int64
+	running      map[string]context.CancelFunc // by request id
+	seen         map[string]bool
+	chunks       map[string]chunk
+	results      map[string]map[string]interface{} // by query id
+	rowsPerChunk int
+	stats        Stats
+	queries      []string
+}
+
+// NewServer starts a server with the given databases, each with an
+// empty PUBLIC schema.
+func NewServer(databases ...string) *Server {
+	s := &Server{
+		st:       newStore(),
+		sessions: make(map[string]*session),
+		masters:  make(map[string]*session),
+		expired:  make(map[string]bool),
+		running:  make(map[string]context.CancelFunc),
+		seen:     make(map[string]bool),
+		chunks:   make(map[string]chunk),
+		results:  make(map[string]map[string]interface{}),
+	}
+	for _, db := range databases {
+		s.st.createDatabase(db)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session/v1/login-request", s.handleLogin)
+	mux.HandleFunc("/session/token-request", s.handleRenew)
+	mux.HandleFunc("/session/heartbeat", s.handleHeartbeat)
+	mux.HandleFunc("/session", s.handleSession)
+	mux.HandleFunc("/queries/v1/query-request", s.handleQuery)
+	mux.HandleFunc("/queries/v1/abort-request", s.handleAbort)
+	mux.HandleFunc("/queries/", s.handleResult)
+	mux.HandleFunc("/telemetry/send", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]interface{}{"success": true})
+	})
+	mux.HandleFunc("/chunks/", s.handleChunk)
+	s.ts = httptest.NewServer(mux)
+	return s
+}
+
+// URL is the base URL of the server.
+func (s *Server) URL() string { return s.ts.URL }
+
+// DSN returns a gosnowflake DSN connecting to the server's first
+// database and its PUBLIC schema.
+func (s *Server) DSN() string {
+	u, _ := url.Parse(s.ts.URL)
+	s.mu.Lock()
+	db := ""
+	if names := s.st.databaseNames(); len(names) > 0 {
+		db = names[0]
+	}
+	s.mu.Unlock()
+	return fmt.Sprintf("%s:%s@%s/%s/PUBLIC?account=%s&protocol=http", User, Password, u.Host, db, Account)
+}
+
+// Close shuts down the server, closing any connections to it.
+func (s *Server) Close() {
+	s.ts.CloseClientConnections()
+	s.ts.Close()
+}
+
+// SetRowsPerChunk splits later results into chunks of at most n rows,
+// with the first returned inline and the rest downloaded separately.
+// Zero, the default, returns every result inline.
+func (s *Server) SetRowsPerChunk(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsPerChunk = n
+}
+
+// Stats returns the counts of requests handled so far.
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.stats
+	st.OpenSessions = len(s.masters)
+	return st
+}
+
+// Queries returns the text of every query received, in order.
+func (s *Server) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+// ExpireTokens expires the session token of every open session, so
+// that clients must renew them with their master tokens.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for tok := range s.sessions {
+		s.expired[tok] = true
+		delete(s.sessions, tok)
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeFailure writes a failed response, which snowflake returns with
+// a 200 status and the details in the body.
+func writeFailure(w http.ResponseWriter, code, msg string, data interface{}) {
+	writeJSON(w, map[string]interface{}{
+		"data": data, "code": code, "message": msg, "success": false,
+	})
+}
+
+// authToken extracts the token from a `Snowflake Token="..."` header.
+func authToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	_, tok, _ := strings.Cut(h, `Token="`)
+	return strings.TrimSuffix(tok, `"`)
+}
+
+// session returns the session for the request's token, or writes the
+// response telling the client its token has expired. s.mu must be held.
+func (s *Server) session(w http.ResponseWriter, r *http.Request) *session {
+	sess, ok := s.sessions[authToken(r)]
+	if !ok || sess.closed {
+		writeFailure(w, codeSessionExpired, "Session token has expired.", nil)
+		return nil
+	}
+	sess.lastActivity = time.Now()
+	return sess
+}
+
+// loginIdent resolves a database or schema name given at login the way
+// snowflake resolves identifiers: uppercased unless double quoted.
+func loginIdent(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return strings.ToUpper(name)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Data struct {
+			LoginName         string                 `json:"LOGIN_NAME"`
+			Password          string                 `json:"PASSWORD"`
+			AccountName       string                 `json:"ACCOUNT_NAME"`
+			SessionParameters map[string]interface{} `json:"SESSION_PARAMETERS"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.EqualFold(req.Data.LoginName, User) || req.Data.Password != Password ||
+		!strings.EqualFold(req.Data.AccountName, Account) {
+		s.stats.FailedLogins++
+		writeFailure(w, codeBadCredentials, "Incorrect username or password was specified.", nil)
+		return
+	}
+
+	validate := true
+	for k, v := range req.Data.SessionParameters {
+		if strings.EqualFold(k, "CLIENT_VALIDATE_DEFAULT_PARAMETERS") {
+			if b, ok := v.(bool); ok {
+				validate = b
+			}
+		}
+	}
+
+	q := r.URL.Query()
+	sess := &session{
+		autocommit: true,
+		txn:        make(map[tableKey]txnTable),
+		temp:       make(map[tableKey]*table),
+	}
+	if db := loginIdent(q.Get("databaseName")); db != "" {
+		if _, ok := s.st.dbs[db]; ok {
+			sess.db, sess.schema = db, "PUBLIC"
+		} else if validate {
+			s.stats.FailedLogins++
+			writeFailure(w, codeBadDefaultObj, "The requested database does not exist or not authorized.", nil)
+			return
+		}
+	}
+	if schema := loginIdent(q.Get("schemaName")); schema != "" && sess.db != "" {
+		if _, ok := s.st.dbs[sess.db].schemas[schema]; ok {
+			sess.schema = schema
+		} else if validate {
+			s.stats.FailedLogins++
+			writeFailure(w, codeBadDefaultObj, "The requested schema does not exist or not authorized.", nil)
+			return
+		} else {
+			sess.schema = ""
+		}
+	}
+
+	s.nextID++
+	sess.id = s.nextID
+	sess.token, sess.masterToken = randomToken(), randomToken()
+	sess.lastActivity = time.Now()
+	s.sessions[sess.token] = sess
+	s.masters[sess.masterToken] = sess
+	s.stats.Logins++
+
+	writeJSON(w, map[string]interface{}{
+		"data": map[string]interface{}{
+			"token":                   sess.token,
+			"validityInSeconds":       3600,
+			"masterToken":             sess.masterToken,
+			"masterValidityInSeconds": 14400,
+			"sessionId":               sess.id,
+			"serverVersion":           "standin",
+			"parameters": []map[string]interface{}{
+				{"name": "TIMEZONE", "value": "UTC"},
+				{"name": "AUTOCOMMIT", "value": true},
+			},
+			"sessionInfo": map[string]interface{}{
+				"databaseName":  sess.db,
+				"schemaName":    sess.schema,
+				"warehouseName": "STANDIN_WH",
+				"roleName":      "STANDIN",
+			},
+		},
+		"success": true,
+	})
+}
+
+func (s *Server) handleRenew(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OldSessionToken string `json:"oldSessionToken"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.masters[authToken(r)]
+	if !ok || sess.closed {
+		writeFailure(w, "390114", "Authentication token has expired. The user must authenticate again.", nil)
+		return
+	}
+
+	delete(s.sessions, sess.token)
+	delete(s.expired, req.OldSessionToken)
+	sess.token = randomToken()
+	s.sessions[sess.token] = sess
+	s.stats.TokenRenewals++
+
+	writeJSON(w, map[string]interface{}{
+		"data": map[string]interface{}{
+			"sessionToken":        sess.token,
+			"validityInSecondsST": 3600,
+			"masterToken":         sess.masterToken,
+			"validityInSecondsMT": 14400,
+			"sessionId":           sess.id,
+		},
+		"success": true,
+	})
+}
+
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session(w, r) == nil {
+		return
+	}
+	s.stats.Heartbeats++
+	writeJSON(w, map[string]interface{}{"data": nil, "success": true})
+}
+
+// handleSession closes a session, discarding its temporary tables and
+// rolling back any open transaction.
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("delete") != "true" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess := s.session(w, r)
+	if sess == nil {
+		return
+	}
+
+	sess.closed = true
+	sess.rollback()
+	sess.temp = nil
+	delete(s.sessions, sess.token)
+	delete(s.masters, sess.masterToken)
+	s.stats.ClosedSessions++
+	writeJSON(w, map[string]interface{}{"data": nil, "success": true})
+}
+
+func (s *Server) handleAbort(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RequestID string `json:"requestId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session(w, r) == nil {
+		return
+	}
+
+	if cancel, ok := s.running[req.RequestID]; ok {
+		cancel()
+	} else if !s.seen[req.RequestID] {
+		writeFailure(w, codeQueryNotRunning, "Identified SQL statement is not currently executing.", nil)
+		return
+	}
+	s.stats.Aborts++
+	writeJSON(w, map[string]interface{}{"data": nil, "success": true})
+}
+
+func (s *Server) handleChunk(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	c, ok := s.chunks[r.URL.Path]
+	if ok {
+		s.stats.ChunkDownloads++
+	}
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		http.NotFound(w, r)
+	case r.Header.Get("x-amz-server-side-encryption-customer-key") != c.key:
+		http.Error(w, "the encryption key does not match", http.StatusForbidden)
+	default:
+		_, _ = w.Write(c.data)
+	}
+}
+
+// handleResult returns the result of a statement of a multi-statement
+// request, which clients fetch by the ids listed in its response.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/result") || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+	queryID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/queries/"), "/result")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session(w, r) == nil {
+		return
+	}
+	data, ok := s.results[queryID]
+	if !ok {
+		writeFailure(w, codeQueryNotRunning, "Identified SQL statement is not currently executing.", nil)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"data": data, "success": true})
+}
+
+type queryRequest struct {
+	SQLText      string                 `json:"sqlText"`
+	DescribeOnly bool                   `json:"describeOnly"`
+	Bindings     map[string]binding     `json:"bindings"`
+	Parameters   map[string]interface{} `json:"parameters"`
+}
+
+// statementCount is the MULTI_STATEMENT_COUNT of the request, one if
+// it is not set and zero for any number of statements.
+func (r *queryRequest) statementCount() int {
+	if n, ok := r.Parameters["MULTI_STATEMENT_COUNT"].(float64); ok {
+		return int(n)
+	}
+	return 1
+}
+
+type chunkMeta struct {
+	URL              string `json:"url"`
+	RowCount         int    `json:"rowCount"`
+	UncompressedSize int    `json:"uncompressedSize"`
+	CompressedSize   int    `json:"compressedSize"`
+}
+
+// waitFor returns how long a SELECT SYSTEM$WAIT(n [, unit]) should
+// wait, which the server does without holding its lock.
+func waitFor(stmt interface{}) time.Duration {
+	sel, ok := stmt.(*selectStmt)
+	if !ok || sel.from != nil || len(sel.exprs) != 1 || sel.exprs[0].call != "SYSTEM$WAIT" {
+		return 0
+	}
+
+	args := sel.exprs[0].args
+	n, err := strconv.ParseFloat(*args[0].literal.text, 64)
+	if err != nil {
+		return 0
+	}
+	unit := time.Second
+	if len(args) > 1 && args[1].literal != nil && args[1].literal.text != nil {
+		switch strings.ToUpper(*args[1].literal.text) {
+		case "MILLISECONDS":
+			unit = time.Millisecond
+		case "MINUTES":
+			unit = time.Minute
+		case "HOURS":
+			unit = time.Hour
+		}
+	}
+	return time.Duration(n * float64(unit))
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requestID := r.URL.Query().Get("requestId")
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	s.mu.Lock()
+	sess := s.session(w, r)
+	if sess == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stats.Queries++
+	s.queries = append(s.queries, req.SQLText)
+	s.running[requestID] = cancel
+	s.seen[requestID] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, requestID)
+		s.mu.Unlock()
+	}()
+
+	queryID := uuid.NewString()
+	resp, err := s.execute(ctx, sess, queryID, &req)
+	if err != nil {
+		sqlErr, ok := err.(*sqlError)
+		if !ok {
+			sqlErr = newError("000603", "XX000", err.Error())
+		}
+		writeFailure(w, sqlErr.code, sqlErr.msg, map[string]interface{}{
+			"sqlState": sqlErr.sqlState, "queryId": queryID,
+		})
+		return
+	}
+	writeJSON(w, map[string]interface{}{"data": resp, "success": true})
+}
+
+func (s *Server) execute(ctx context.Context, sess *session, queryID string, req *queryRequest) (map[string]interface{}, error) {
+	var stmt interface{}
+	if !isScript(req.SQLText) {
+		stmts, err := parseStatements(req.SQLText)
+		if err != nil {
+			return nil, err
+		}
+		if n := req.statementCount(); n != 0 && n != len(stmts) {
+			return nil, errStatementCount(len(stmts), n)
+		}
+		if len(stmts) > 1 {
+			return s.executeMulti(ctx, sess, queryID, req, stmts)
+		}
+		stmt = stmts[0]
+	}
+
+	if d := waitFor(stmt); d > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, errCanceled
+		case <-time.After(d):
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ctx.Err() != nil {
+		return nil, errCanceled
+	}
+
+	e, err := newExecutor(s.st, sess, req.Bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *result
+	if stmt == nil {
+		res, err = e.execScript(req.SQLText)
+	} else {
+		if _, ok := stmt.(*selectStmt); !ok && req.DescribeOnly {
+			// describing anything other than a query must not run it
+			res = &result{typeID: stmtTypeOther, cols: textColumns("status")}
+		} else {
+			res, err = e.exec(stmt)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.DescribeOnly {
+		res.rows = nil
+	}
+	return s.resultData(sess, queryID, res)
+}
+
+// executeMulti runs the statements of a multi-statement request in
+// turn, stopping at the first to fail. Like snowflake, it returns a
+// single row for the request itself, with the ids of the results of
+// each statement for the client to fetch.
+func (s *Server) executeMulti(ctx context.Context, sess *session, queryID string, req *queryRequest, stmts []interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := newExecutor(s.st, sess, req.Bindings)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(stmts))
+	types := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		if ctx.Err() != nil {
+			return nil, errCanceled
+		}
+		res, err := e.exec(stmt)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = uuid.NewString()
+		data, err := s.resultData(sess, ids[i], res)
+		if err != nil {
+			return nil, err
+		}
+		s.results[ids[i]] = data
+		types[i] = strconv.FormatInt(res.typeID, 10)
+	}
+
+	data, err := s.resultData(sess, queryID, &result{
+		typeID: stmtTypeSelect,
+		cols:   textColumns("multiple statement execution"),
+		rows:   [][]interface{}{{"Multiple statements executed successfully."}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	data["resultIds"] = strings.Join(ids, ",")
+	data["resultTypes"] = strings.Join(types, ",")
+	return data, nil
+}
+
+// resultData formats the response to a statement with the result res,
+// storing any chunks beyond the first for download. s.mu must be held.
+func (s *Server) resultData(sess *session, queryID string, res *result) (map[string]interface{}, error) {
+	data := map[string]interface{}{
+		"parameters":         []interface{}{},
+		"rowtype":            rowTypes(res.cols),
+		"total":              len(res.rows),
+		"returned":           len(res.rows),
+		"queryId":            queryID,
+		"finalDatabaseName":  sess.db,
+		"finalSchemaName":    sess.schema,
+		"finalWarehouseName": "STANDIN_WH",
+		"finalRoleName":      "STANDIN",
+		"statementTypeId":    res.typeID,
+		"queryResultFormat":  "arrow",
+	}
+	if res.isDML() {
+		data["rowset"] = jsonRowset(res.cols, res.rows)
+	}
+
+	// like snowflake, an empty result comes without a stream, only
+	// with its row types
+	if len(res.rows) == 0 {
+		data["rowsetbase64"] = ""
+		return data, nil
+	}
+
+	// the first chunk is returned inline, the rest are downloaded
+	rows, per := res.rows, s.rowsPerChunk
+	if per <= 0 || per > len(rows) {
+		per = len(rows)
+	}
+	first, err := arrowStream(res.cols, rows[:per])
+	if err != nil {
+		return nil, err
+	}
+	data["rowsetbase64"] = base64.StdEncoding.EncodeToString(first)
+
+	if rest := rows[per:]; len(rest) > 0 {
+		qrmk := randomToken()
+		var metas []chunkMeta
+		for i := 0; len(rest) > 0; i++ {
+			n := per
+			if n > len(rest) {
+				n = len(rest)
+			}
+			raw, err := arrowStream(res.cols, rest[:n])
+			if err != nil {
+				return nil, err
+			}
+			gz, err := gzipBytes(raw)
+			if err != nil {
+				return nil, err
+			}
+
+			path := fmt.Sprintf("/chunks/%s/%d", queryID, i)
+			s.chunks[path] = chunk{data: gz, key: qrmk}
+			metas = append(metas, chunkMeta{URL: s.ts.URL + path, RowCount: n,
+				UncompressedSize: len(raw), CompressedSize: len(gz)})
+			rest = rest[n:]
+		}
+		data["chunks"] = metas
+		data["qrmk"] = qrmk
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// post sends a request to the stand-in with the given session token
+// and decodes the generic snowflake response envelope.
+func post(t *testing.T, srv *Server, path, token string, body interface{}) (resp struct {
+	Data    map[string]interface{} `json:"data"`
+	Code    string                 `json:"code"`
+	Success bool                   `json:"success"`
+}) {
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	req, err := http.NewRequest(http.MethodPost, srv.URL()+path, &buf)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", `Snowflake Token="`+token+`"`)
+	}
+
+	r, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer r.Body.Close()
+	require.Equal(t, http.StatusOK, r.StatusCode)
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+	return
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	srv := NewServer("DB")
+	defer srv.Close()
+
+	login := post(t, srv, "/session/v1/login-request?databaseName=DB", "",
+		map[string]interface{}{"data": map[string]interface{}{
+			"LOGIN_NAME": User, "PASSWORD": Password, "ACCOUNT_NAME": Account,
+		}})
+	require.True(t, login.Success)
+	token := login.Data["token"].(string)
+
+	assert.True(t, post(t, srv, "/session/heartbeat", token, nil).Success)
+	assert.True(t, post(t, srv, "/session?delete=true", token, nil).Success)
+
+	expired := post(t, srv, "/session/heartbeat", token, nil)
+	assert.False(t, expired.Success)
+	assert.Equal(t, codeSessionExpired, expired.Code)
+
+	assert.Equal(t, Stats{Logins: 1, Heartbeats: 1, ClosedSessions: 1}, srv.Stats())
+}
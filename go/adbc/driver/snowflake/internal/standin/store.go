@@ -0,0 +1,220 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// tableKey identifies a table by its database, schema and name, each
+// exactly as stored (unquoted identifiers are upper cased).
+type tableKey struct {
+	db, schema, name string
+}
+
+type column struct {
+	name     string
+	typ      colType
+	nullable bool
+}
+
+type table struct {
+	key       tableKey
+	cols      []column
+	rows      [][]interface{}
+	temporary bool
+	created   time.Time
+}
+
+// clone returns a copy of the table which can be modified without
+// affecting t. Rows themselves are never modified in place, so they
+// are shared.
+func (t *table) clone() *table {
+	out := *t
+	out.cols = append([]column(nil), t.cols...)
+	out.rows = append([][]interface{}(nil), t.rows...)
+	return &out
+}
+
+func (t *table) colIndex(name string) int {
+	for i, c := range t.cols {
+		if c.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// bytes estimates the storage size of the table, as the length of its
+// values as text.
+func (t *table) bytes() int64 {
+	var n int64
+	for _, row := range t.rows {
+		for _, v := range row {
+			if v != nil {
+				n += int64(len(fmt.Sprint(v)))
+			}
+		}
+	}
+	return n
+}
+
+type database struct {
+	name    string
+	created time.Time
+	schemas map[string]time.Time
+}
+
+// store holds the committed state of the account shared by all
+// sessions, the server's mutex guards it along with every session.
+type store struct {
+	dbs    map[string]*database
+	tables map[tableKey]*table
+}
+
+func newStore() *store {
+	return &store{dbs: make(map[string]*database), tables: make(map[tableKey]*table)}
+}
+
+func (st *store) createDatabase(name string) {
+	st.dbs[name] = &database{name: name, created: time.Now(),
+		schemas: map[string]time.Time{"PUBLIC": time.Now()}}
+}
+
+func (st *store) databaseNames() []string {
+	names := make([]string, 0, len(st.dbs))
+	for n := range st.dbs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// txnTable is a table modified in an open transaction, orig is what
+// it was copied from so that commit can tell if it was since dropped.
+type txnTable struct {
+	orig, cur *table
+}
+
+// session is the server side of a client connection.
+type session struct {
+	id           int64
+	token        string
+	masterToken  string
+	db, schema   string
+	autocommit   bool
+	inTxn        bool
+	txn          map[tableKey]txnTable
+	temp         map[tableKey]*table
+	closed       bool
+	lastActivity time.Time
+}
+
+// lookup finds the table visible to the session, temporary tables
+// shadow permanent ones and uncommitted changes are only visible to
+// the session that made them.
+func (s *session) lookup(st *store, key tableKey) *table {
+	if t, ok := s.temp[key]; ok {
+		return t
+	}
+	if t, ok := s.txn[key]; ok {
+		return t.cur
+	}
+	return st.tables[key]
+}
+
+// writable returns a copy of the table visible to the session for a
+// DML statement to modify, put then makes the changes visible. Tables
+// are replaced rather than modified so that other sessions and open
+// transactions keep seeing the version they started with.
+func (s *session) writable(st *store, key tableKey) *table {
+	t := s.lookup(st, key)
+	if t == nil {
+		return nil
+	}
+	return t.clone()
+}
+
+// put stores a table modified by a DML statement, starting a
+// transaction first if autocommit is off.
+func (s *session) put(st *store, t *table) {
+	if t.temporary {
+		s.temp[t.key] = t
+		return
+	}
+	if !s.autocommit {
+		s.inTxn = true
+	}
+	if !s.inTxn {
+		st.tables[t.key] = t
+		return
+	}
+
+	entry, ok := s.txn[t.key]
+	if !ok {
+		entry.orig = st.tables[t.key]
+	}
+	entry.cur = t
+	s.txn[t.key] = entry
+}
+
+func (s *session) commit(st *store) {
+	for key, t := range s.txn {
+		if st.tables[key] == t.orig {
+			st.tables[key] = t.cur
+		}
+	}
+	s.rollback()
+}
+
+func (s *session) rollback() {
+	s.txn = make(map[tableKey]txnTable)
+	s.inTxn = false
+}
+
+// visibleTables returns every table the session can see, sorted by key.
+func (s *session) visibleTables(st *store) []*table {
+	seen := make(map[tableKey]bool)
+	var out []*table
+	add := func(t *table) {
+		if !seen[t.key] {
+			seen[t.key] = true
+			out = append(out, s.lookup(st, t.key))
+		}
+	}
+	for _, t := range s.temp {
+		add(t)
+	}
+	for _, t := range st.tables {
+		add(t)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].key, out[j].key
+		if a.db != b.db {
+			return a.db < b.db
+		}
+		if a.schema != b.schema {
+			return a.schema < b.schema
+		}
+		return a.name < b.name
+	})
+	return out
+}
@@ -0,0 +1,572 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package standin
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the largest precision of a NUMBER and the default lengths of VARCHAR
+// and BINARY columns, which are also their maximums
+const (
+	maxPrecision     = 38
+	maxVarcharLength = 16777216
+	maxBinaryLength  = 8388608
+)
+
+// colType is the type of a column as snowflake reports it, name is one
+// of the canonical type names such as NUMBER, VARCHAR or TIMESTAMP_NTZ.
+type colType struct {
+	name      string
+	precision int // NUMBER only
+	scale     int // NUMBER, TIME and TIMESTAMP_*
+	length    int // VARCHAR and BINARY
+}
+
+var (
+	typeInteger = colType{name: "NUMBER", precision: maxPrecision}
+	typeFloat   = colType{name: "FLOAT"}
+	typeVarchar = colType{name: "VARCHAR", length: maxVarcharLength}
+	typeBoolean = colType{name: "BOOLEAN"}
+)
+
+// parseType resolves a type name and its parameters from a column
+// definition, accepting the synonyms snowflake does.
+func parseType(name string, params []int) (colType, error) {
+	param := func(i, def int) int {
+		if i < len(params) {
+			return params[i]
+		}
+		return def
+	}
+
+	var t colType
+	switch strings.ToUpper(name) {
+	case "NUMBER", "NUMERIC", "DECIMAL":
+		t = colType{name: "NUMBER", precision: param(0, maxPrecision), scale: param(1, 0)}
+		if t.precision < 1 || t.precision > maxPrecision || t.scale < 0 || t.scale > t.precision {
+			return t, errInvalidType(name, params)
+		}
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "BYTEINT":
+		t = typeInteger
+	case "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "DOUBLE PRECISION", "REAL":
+		t = typeFloat
+	case "VARCHAR", "STRING", "TEXT", "NVARCHAR", "NVARCHAR2", "CHAR VARYING", "NCHAR VARYING":
+		t = colType{name: "VARCHAR", length: param(0, maxVarcharLength)}
+	case "CHAR", "CHARACTER", "NCHAR":
+		t = colType{name: "VARCHAR", length: param(0, 1)}
+	case "BINARY", "VARBINARY":
+		t = colType{name: "BINARY", length: param(0, maxBinaryLength)}
+	case "BOOLEAN":
+		t = typeBoolean
+	case "DATE":
+		t = colType{name: "DATE"}
+	case "TIME":
+		t = colType{name: "TIME", scale: param(0, 9)}
+	case "DATETIME", "TIMESTAMP", "TIMESTAMP_NTZ", "TIMESTAMPNTZ", "TIMESTAMP WITHOUT TIME ZONE":
+		t = colType{name: "TIMESTAMP_NTZ", scale: param(0, 9)}
+	case "TIMESTAMP_LTZ", "TIMESTAMPLTZ", "TIMESTAMP WITH LOCAL TIME ZONE":
+		t = colType{name: "TIMESTAMP_LTZ", scale: param(0, 9)}
+	case "TIMESTAMP_TZ", "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE":
+		t = colType{name: "TIMESTAMP_TZ", scale: param(0, 9)}
+	case "VARIANT", "ARRAY", "OBJECT":
+		t = colType{name: strings.ToUpper(name)}
+	default:
+		return t, errInvalidType(name, params)
+	}
+
+	switch t.name {
+	case "TIME", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		if t.scale < 0 || t.scale > 9 {
+			return t, errInvalidType(name, params)
+		}
+	}
+	return t, nil
+}
+
+func errInvalidType(name string, params []int) error {
+	strs := make([]string, len(params))
+	for i, p := range params {
+		strs[i] = strconv.Itoa(p)
+	}
+	if len(strs) > 0 {
+		name += "(" + strings.Join(strs, ",") + ")"
+	}
+	return errSyntax("unsupported data type '" + name + "'")
+}
+
+// String formats the type the way DESC TABLE does.
+func (t colType) String() string {
+	switch t.name {
+	case "NUMBER":
+		return fmt.Sprintf("NUMBER(%d,%d)", t.precision, t.scale)
+	case "VARCHAR", "BINARY":
+		return fmt.Sprintf("%s(%d)", t.name, t.length)
+	case "TIME", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		return fmt.Sprintf("%s(%d)", t.name, t.scale)
+	}
+	return t.name
+}
+
+// infoName is the DATA_TYPE reported for the type by INFORMATION_SCHEMA.
+func (t colType) infoName() string {
+	if t.name == "VARCHAR" {
+		return "TEXT"
+	}
+	return t.name
+}
+
+// rowType is the type name used for the type in the rowtype of a
+// query response, which is also how clients decide how to decode it.
+func (t colType) rowType() string {
+	switch t.name {
+	case "NUMBER":
+		return "fixed"
+	case "FLOAT":
+		return "real"
+	case "VARCHAR":
+		return "text"
+	}
+	return strings.ToLower(t.name)
+}
+
+// canWidenTo reports whether a column of type t can be changed to type
+// to with ALTER COLUMN ... SET DATA TYPE, which snowflake only allows
+// for increasing the precision of a NUMBER or the length of a VARCHAR.
+func (t colType) canWidenTo(to colType) bool {
+	if t.name != to.name {
+		return false
+	}
+	switch t.name {
+	case "NUMBER":
+		return t.scale == to.scale && to.precision >= t.precision
+	case "VARCHAR":
+		return to.length >= t.length
+	}
+	return t == to
+}
+
+// Values are stored in a form that depends on the type of their column:
+//
+//	NUMBER           int64, unscaled (so 1.25 in a NUMBER(10,2) is 125)
+//	FLOAT            float64
+//	VARCHAR          string
+//	VARIANT, ARRAY,  string holding the JSON
+//	OBJECT
+//	BINARY           []byte
+//	BOOLEAN          bool
+//	DATE             int32 days since the epoch
+//	TIME             int64 nanoseconds since midnight
+//	TIMESTAMP_*      time.Time, in UTC except for TIMESTAMP_TZ
+//
+// with nil for NULL.
+
+// input is a value supplied to a statement, either a literal in the
+// SQL text or a bound parameter, before it is coerced to a column type.
+type input struct {
+	text *string
+	// kind is the binding type such as FIXED, TEXT or TIMESTAMP_TZ,
+	// literals use the kind matching their syntax.
+	kind string
+	// parsed is set if the value went through PARSE_JSON
+	parsed bool
+}
+
+func textInput(s string) input { return input{text: &s, kind: "TEXT"} }
+
+// literalType is the type snowflake infers for a value selected without
+// a table, such as SELECT 42 or SELECT ?.
+func (in input) literalType() colType {
+	switch in.kind {
+	case "FIXED":
+		if in.text == nil {
+			return typeInteger
+		}
+		digits := strings.TrimLeft(*in.text, "-+")
+		scale := 0
+		if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+			scale = len(digits) - dot - 1
+			digits = digits[:dot] + digits[dot+1:]
+		}
+		precision := len(strings.TrimLeft(digits, "0"))
+		if precision < scale {
+			precision = scale
+		}
+		if precision == 0 {
+			precision = 1
+		}
+		return colType{name: "NUMBER", precision: precision, scale: scale}
+	case "REAL":
+		return typeFloat
+	case "BOOLEAN":
+		return typeBoolean
+	case "BINARY":
+		return colType{name: "BINARY", length: maxBinaryLength}
+	case "DATE":
+		return colType{name: "DATE"}
+	case "TIME":
+		return colType{name: "TIME", scale: 9}
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		return colType{name: in.kind, scale: 9}
+	}
+	if in.text != nil {
+		return colType{name: "VARCHAR", length: len(*in.text)}
+	}
+	return typeVarchar
+}
+
+// coerce converts an input to the stored form of a value of type t, the
+// way snowflake would when inserting it into a column of that type.
+func coerce(in input, t colType) (interface{}, error) {
+	if in.text == nil {
+		return nil, nil
+	}
+	s := *in.text
+
+	switch t.name {
+	case "VARIANT", "ARRAY", "OBJECT":
+		if !in.parsed {
+			return nil, errTypeMismatch(t, in)
+		}
+		return normalizeJSON(s, t)
+	}
+	if in.parsed {
+		return nil, errTypeMismatch(t, in)
+	}
+
+	switch t.name {
+	case "NUMBER":
+		switch in.kind {
+		case "FIXED", "REAL", "TEXT":
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		return parseNumber(s, t)
+	case "FLOAT":
+		switch in.kind {
+		case "FIXED", "REAL", "TEXT":
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, errNotRecognized("Numeric", s)
+		}
+		return v, nil
+	case "VARCHAR":
+		if in.kind == "BINARY" {
+			// binary values are sent hex encoded already
+			s = strings.ToUpper(s)
+		}
+		if len(s) > t.length {
+			return nil, newError("100074", "22001", fmt.Sprintf("String '%s' is too long and would be truncated", s))
+		}
+		return s, nil
+	case "BINARY":
+		switch in.kind {
+		case "BINARY", "TEXT":
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, errNotRecognized("Hex", s)
+		}
+		return b, nil
+	case "BOOLEAN":
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "f", "no", "n", "off", "0":
+			return false, nil
+		}
+		return nil, errNotRecognized("Boolean", s)
+	case "DATE":
+		var tm time.Time
+		switch in.kind {
+		case "DATE":
+			ms, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, errNotRecognized("Date", s)
+			}
+			tm = time.UnixMilli(ms).UTC()
+		case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+			ts, err := bindTimestamp(s)
+			if err != nil {
+				return nil, err
+			}
+			tm = ts
+		case "TEXT":
+			ts, err := parseTimestamp(s, time.UTC)
+			if err != nil {
+				return nil, errNotRecognized("Date", s)
+			}
+			tm = ts
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		y, m, d := tm.Date()
+		return int32(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400), nil
+	case "TIME":
+		var ns int64
+		switch in.kind {
+		case "TIME":
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, errNotRecognized("Time", s)
+			}
+			ns = v
+		case "TEXT":
+			tm, err := time.Parse("15:04:05.999999999", strings.TrimSpace(s))
+			if err != nil {
+				return nil, errNotRecognized("Time", s)
+			}
+			h, m, sec := tm.Clock()
+			ns = int64(h)*int64(time.Hour) + int64(m)*int64(time.Minute) +
+				int64(sec)*int64(time.Second) + int64(tm.Nanosecond())
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		return truncateNanos(ns, t.scale), nil
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		var tm time.Time
+		switch in.kind {
+		case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+			ts, err := bindTimestamp(s)
+			if err != nil {
+				return nil, err
+			}
+			tm = ts
+		case "DATE":
+			ms, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, errNotRecognized("Timestamp", s)
+			}
+			tm = time.UnixMilli(ms).UTC()
+		case "TEXT":
+			ts, err := parseTimestamp(s, time.UTC)
+			if err != nil {
+				return nil, errNotRecognized("Timestamp", s)
+			}
+			tm = ts
+		default:
+			return nil, errTypeMismatch(t, in)
+		}
+		if t.name == "TIMESTAMP_NTZ" {
+			// keep the wallclock, dropping the offset
+			tm = time.Date(tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(),
+				tm.Second(), tm.Nanosecond(), time.UTC)
+		} else if t.name == "TIMESTAMP_LTZ" {
+			tm = tm.UTC()
+		}
+		ns := truncateNanos(int64(tm.Nanosecond()), t.scale)
+		return time.Unix(tm.Unix(), ns).In(tm.Location()), nil
+	}
+	return nil, errTypeMismatch(t, in)
+}
+
+// bindTimestamp parses a timestamp binding, which is the nanoseconds
+// since the epoch followed, for TIMESTAMP_TZ, by the offset in minutes
+// plus 1440.
+func bindTimestamp(s string) (time.Time, error) {
+	nanos, offset, hasOffset := strings.Cut(s, " ")
+	ns, ok := new(big.Int).SetString(nanos, 10)
+	if !ok {
+		return time.Time{}, errNotRecognized("Timestamp", s)
+	}
+	sec, frac := new(big.Int).DivMod(ns, big.NewInt(int64(time.Second)), new(big.Int))
+	tm := time.Unix(sec.Int64(), frac.Int64()).UTC()
+	if hasOffset {
+		mins, err := strconv.Atoi(offset)
+		if err != nil {
+			return time.Time{}, errNotRecognized("Timestamp", s)
+		}
+		tm = tm.In(tzLocation(mins - 1440))
+	}
+	return tm, nil
+}
+
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseTimestamp parses a timestamp from text, values without an
+// offset are taken to be in loc.
+func parseTimestamp(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if tm, err := time.ParseInLocation(layout, s, loc); err == nil {
+			if _, offset := tm.Zone(); strings.Contains(layout, "07") {
+				tm = tm.In(tzLocation(offset / 60))
+			}
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// tzLocation returns a fixed zone for an offset in minutes.
+func tzLocation(mins int) *time.Location {
+	if mins == 0 {
+		return time.UTC
+	}
+	sign, abs := '+', mins
+	if mins < 0 {
+		sign, abs = '-', -mins
+	}
+	return time.FixedZone(fmt.Sprintf("%c%02d%02d", sign, abs/60, abs%60), mins*60)
+}
+
+// truncateNanos drops the digits of a nanosecond count beyond scale.
+func truncateNanos(ns int64, scale int) int64 {
+	unit := int64(math.Pow10(9 - scale))
+	return ns - ns%unit
+}
+
+// parseNumber parses a decimal string into the unscaled value for a
+// NUMBER of type t, rounding half away from zero like snowflake.
+func parseNumber(s string, t colType) (interface{}, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return nil, errNotRecognized("Numeric", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.scale)), nil)))
+
+	num, den := r.Num(), r.Denom()
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(t.precision)), nil)
+	if new(big.Int).Abs(q).Cmp(limit) >= 0 || !q.IsInt64() {
+		return nil, newError("100039", "22003",
+			fmt.Sprintf("Numeric value '%s' is out of range", s))
+	}
+	return q.Int64(), nil
+}
+
+// normalizeJSON parses a semi-structured value and formats it the way
+// snowflake returns it, with two space indentation.
+func normalizeJSON(s string, t colType) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, newError("100069", "22P02", fmt.Sprintf("Error parsing JSON: %s", s))
+	}
+
+	switch t.name {
+	case "ARRAY":
+		if _, ok := v.([]interface{}); !ok {
+			v = []interface{}{v}
+		}
+	case "OBJECT":
+		if _, ok := v.(map[string]interface{}); !ok {
+			return nil, newError("002031", "22000", "Invalid object: "+s)
+		}
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// formatValue formats a stored value of type t as text which coerce
+// can parse back, or nil for NULL. It is how values are cast between
+// types and how they are returned in JSON rowsets.
+func formatValue(v interface{}, t colType) *string {
+	if v == nil {
+		return nil
+	}
+
+	var s string
+	switch t.name {
+	case "NUMBER":
+		s = formatDecimal(v.(int64), t.scale)
+	case "FLOAT":
+		s = strconv.FormatFloat(v.(float64), 'g', -1, 64)
+	case "BINARY":
+		s = strings.ToUpper(hex.EncodeToString(v.([]byte)))
+	case "BOOLEAN":
+		s = strconv.FormatBool(v.(bool))
+	case "DATE":
+		s = time.Unix(int64(v.(int32))*86400, 0).UTC().Format("2006-01-02")
+	case "TIME":
+		s = time.Unix(0, v.(int64)).UTC().Format("15:04:05.999999999")
+	case "TIMESTAMP_NTZ":
+		s = v.(time.Time).Format("2006-01-02 15:04:05.999999999")
+	case "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+		s = v.(time.Time).Format("2006-01-02 15:04:05.999999999 -07:00")
+	default:
+		s = v.(string)
+	}
+	return &s
+}
+
+func formatDecimal(v int64, scale int) string {
+	if scale == 0 {
+		return strconv.FormatInt(v, 10)
+	}
+	sign := ""
+	u := new(big.Int).SetInt64(v)
+	if u.Sign() < 0 {
+		sign = "-"
+		u.Neg(u)
+	}
+	digits := u.String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// cast converts a stored value from one column type to another.
+func cast(v interface{}, from, to colType) (interface{}, error) {
+	if from == to || v == nil {
+		return v, nil
+	}
+
+	in := input{text: formatValue(v, from), kind: "TEXT"}
+	switch from.name {
+	case "VARIANT", "ARRAY", "OBJECT":
+		in.parsed = true
+	case "NUMBER":
+		in.kind = "FIXED"
+	case "FLOAT":
+		in.kind = "REAL"
+	case "BINARY":
+		in.kind = "BINARY"
+	}
+	return coerce(in, to)
+}
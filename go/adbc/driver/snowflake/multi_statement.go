@@ -0,0 +1,364 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/snowflakedb/gosnowflake"
+)
+
+// multiRows are the rows gosnowflake returns for a query executed with
+// arrow batches, which hold the results of every statement in turn.
+type multiRows interface {
+	driver.Rows
+	driver.RowsNextResultSet
+	driver.RowsColumnTypeDatabaseTypeName
+	driver.RowsColumnTypeNullable
+	driver.RowsColumnTypePrecisionScale
+	GetArrowBatches() ([]*gosnowflake.ArrowBatch, error)
+}
+
+// singleStatement reports whether the query is certainly made of one
+// statement, having no semicolon other than a trailing one.
+func singleStatement(query string) bool {
+	return !strings.Contains(strings.TrimRight(query, "; \t\r\n"), ";")
+}
+
+// singleResult is the result of a query made of one statement, which
+// is read the same way as by ExecuteQuery.
+type singleResult struct {
+	rdr  array.RecordReader
+	n    int64
+	done bool
+}
+
+func (r *singleResult) NextResult(context.Context) (array.RecordReader, int64, error) {
+	if r.done {
+		return nil, -1, io.EOF
+	}
+	r.done = true
+	return r.rdr, r.n, nil
+}
+
+func (r *singleResult) Close() error {
+	if !r.done {
+		r.rdr.Release()
+	}
+	r.done = true
+	return nil
+}
+
+// multiResult reads the results of a multi-statement query from the
+// rows of gosnowflake, converting them to the same types as
+// ExecuteQuery does.
+//
+// gosnowflake cannot move past a result which spans several chunks
+// (it stays at that result), so NextResult reports an error instead of
+// the results which follow such a result, or of io.EOF if it is the
+// last one.
+type multiResult struct {
+	alloc   memory.Allocator
+	rows    multiRows
+	started bool
+	// first is the first batch of the current result, which tells
+	// whether the rows moved on to the next one
+	first *gosnowflake.ArrowBatch
+}
+
+func (m *multiResult) NextResult(ctx context.Context) (array.RecordReader, int64, error) {
+	if m.rows == nil {
+		return nil, -1, io.EOF
+	}
+
+	if m.started {
+		// with arrow batches, NextResultSet returns io.EOF when moving
+		// to a result of a single chunk, so only HasNextResultSet tells
+		// whether there is another result
+		if !m.rows.HasNextResultSet() {
+			return nil, -1, io.EOF
+		}
+		if err := m.rows.NextResultSet(); err != nil && err != io.EOF {
+			return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+		}
+	}
+	m.started = true
+
+	batches, err := m.rows.GetArrowBatches()
+	if err != nil {
+		return nil, -1, errToAdbcErr(adbc.StatusIO, err)
+	}
+	if len(batches) > 0 && batches[0] == m.first {
+		return nil, -1, adbc.Error{
+			Msg:  "[Snowflake] cannot read the results after one spanning several chunks",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+	m.first = nil
+	if len(batches) > 0 {
+		m.first = batches[0]
+	}
+
+	cols := m.rows.Columns()
+	fields := make([]arrow.Field, len(cols))
+	for i, name := range cols {
+		typ := m.rows.ColumnTypeDatabaseTypeName(i)
+		_, scale, _ := m.rows.ColumnTypePrecisionScale(i)
+		nullable, _ := m.rows.ColumnTypeNullable(i)
+		fields[i] = rowTypeField(name, typ, scale, nullable, loc)
+	}
+
+	rdr, err := newBatchReader(ctx, m.alloc, arrow.NewSchema(fields, nil), batches)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if !isDMLResult(cols) {
+		return rdr, -1, nil
+	}
+
+	// the counts of a DML statement are its result, as the one row
+	// of columns such as "number of rows inserted"
+	defer rdr.Release()
+	var (
+		recs []arrow.Record
+		n    int64
+	)
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+		if len(recs) > 1 || rec.NumRows() == 0 {
+			continue
+		}
+		for _, col := range rec.Columns() {
+			if c, ok := col.(*array.Int64); ok && c.IsValid(0) {
+				n += c.Value(0)
+			}
+		}
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, -1, err
+	}
+
+	out, err := array.NewRecordReader(rdr.Schema(), recs)
+	if err != nil {
+		return nil, -1, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+	}
+	return out, n, nil
+}
+
+func (m *multiResult) Close() error {
+	if m.rows == nil {
+		return adbc.Error{
+			Msg:  "[Snowflake] multi-statement result already closed",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	err := m.rows.Close()
+	m.rows = nil
+	return errToAdbcErr(adbc.StatusIO, err)
+}
+
+// isDMLResult reports whether a result with the given columns holds
+// the counts of rows affected by a DML statement.
+func isDMLResult(cols []string) bool {
+	for _, c := range cols {
+		if !strings.HasPrefix(c, "number of ") || !strings.HasSuffix(c, " rows inserted") &&
+			!strings.HasSuffix(c, " rows updated") && !strings.HasSuffix(c, " rows deleted") {
+			return false
+		}
+	}
+	return len(cols) > 0
+}
+
+// batchReader reads the records of gosnowflake arrow batches, casting
+// their columns to the types of the schema.
+type batchReader struct {
+	refCount int64
+	ctx      context.Context
+	alloc    memory.Allocator
+	schema   *arrow.Schema
+	batches  []*gosnowflake.ArrowBatch
+	pending  []arrow.Record
+	rec      arrow.Record
+	err      error
+}
+
+// newBatchReader reads the batches with the given schema. If there are
+// any records, the metadata of the fields is taken from them as by
+// ExecuteQuery, and so is the time zone of TIMESTAMP_LTZ columns, as
+// gosnowflake uses the time zone of the session for them.
+func newBatchReader(ctx context.Context, alloc memory.Allocator, schema *arrow.Schema, batches []*gosnowflake.ArrowBatch) (*batchReader, error) {
+	r := &batchReader{refCount: 1, ctx: compute.WithAllocator(ctx, alloc),
+		alloc: alloc, schema: schema, batches: batches}
+	if !r.fetch() {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return r, nil
+	}
+
+	fields := append([]arrow.Field(nil), schema.Fields()...)
+	for i, f := range r.pending[0].Schema().Fields() {
+		if f.HasMetadata() {
+			fields[i].Metadata = f.Metadata
+		}
+		if logicalType(fields[i]) == "TIMESTAMP_LTZ" && f.Type.ID() == arrow.TIMESTAMP {
+			fields[i].Type = f.Type
+		}
+	}
+	r.schema = arrow.NewSchema(fields, nil)
+	return r, nil
+}
+
+func logicalType(f arrow.Field) string {
+	typ, _ := f.Metadata.GetValue("logicalType")
+	return strings.ToUpper(typ)
+}
+
+// scaleTime converts times which gosnowflake casts to nanoseconds
+// without scaling them.
+func scaleTime(alloc memory.Allocator, col *array.Time64, f arrow.Field) arrow.Array {
+	str, _ := f.Metadata.GetValue("scale")
+	scale, err := strconv.Atoi(str)
+	if err != nil || scale >= 9 {
+		col.Retain()
+		return col
+	}
+
+	mult := arrow.Time64(math.Pow10(9 - scale))
+	bldr := array.NewTime64Builder(alloc, col.DataType().(*arrow.Time64Type))
+	defer bldr.Release()
+	bldr.Reserve(col.Len())
+	for i, v := range col.Time64Values() {
+		if col.IsNull(i) {
+			bldr.AppendNull()
+		} else {
+			bldr.UnsafeAppend(v * mult)
+		}
+	}
+	return bldr.NewArray()
+}
+
+// fetch fetches batches until there are records pending, returning
+// false once there are no more or on error.
+func (r *batchReader) fetch() bool {
+	for len(r.pending) == 0 {
+		if len(r.batches) == 0 {
+			return false
+		}
+		recs, err := r.batches[0].Fetch()
+		if err != nil {
+			r.err = errToAdbcErr(adbc.StatusIO, err)
+			return false
+		}
+		r.batches = r.batches[1:]
+		if recs != nil {
+			r.pending = *recs
+		}
+	}
+	return true
+}
+
+func (r *batchReader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+func (r *batchReader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		for _, rec := range r.pending {
+			rec.Release()
+		}
+		r.pending, r.batches = nil, nil
+	}
+}
+
+func (r *batchReader) Schema() *arrow.Schema { return r.schema }
+
+func (r *batchReader) Record() arrow.Record { return r.rec }
+
+func (r *batchReader) Err() error { return r.err }
+
+func (r *batchReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+	if r.err != nil || !r.fetch() {
+		return false
+	}
+
+	src := r.pending[0]
+	r.pending = r.pending[1:]
+	defer src.Release()
+
+	cols := make([]arrow.Array, src.NumCols())
+	defer func() {
+		for _, c := range cols {
+			if c != nil {
+				c.Release()
+			}
+		}
+	}()
+	for i, col := range src.Columns() {
+		f := r.schema.Field(i)
+		want := f.Type
+		if logicalType(f) == "TIME" {
+			if col, ok := col.(*array.Time64); ok {
+				cols[i] = scaleTime(r.alloc, col, f)
+				continue
+			}
+		}
+		if arrow.TypeEqual(col.DataType(), want) {
+			col.Retain()
+			cols[i] = col
+			continue
+		}
+
+		out, err := compute.CastArray(r.ctx, col, compute.SafeCastOptions(want))
+		if err != nil {
+			r.err = adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+			return false
+		}
+		cols[i] = out
+	}
+
+	r.rec = array.NewRecord(r.schema, cols, src.NumRows())
+	return true
+}
@@ -108,9 +108,32 @@ func getTransformer(sc *arrow.Schema, ld gosnowflake.ArrowStreamLoader) (*arrow.
 				}
 			}
 		case "TIME":
-			f.Type = arrow.FixedWidthTypes.Time64ns
+			dt := arrow.FixedWidthTypes.Time64ns.(*arrow.Time64Type)
+			f.Type = dt
 			transformers[i] = func(ctx context.Context, a arrow.Array) (arrow.Array, error) {
-				return compute.CastArray(ctx, a, compute.SafeCastOptions(f.Type))
+				if srcMeta.Scale == 9 {
+					return compute.CastArray(ctx, a, compute.SafeCastOptions(dt))
+				}
+
+				// the values are in units of 10^-scale seconds
+				ints, err := compute.CastArray(ctx, a, compute.SafeCastOptions(arrow.PrimitiveTypes.Int64))
+				if err != nil {
+					return nil, err
+				}
+				defer ints.Release()
+
+				tb := array.NewTime64Builder(compute.GetAllocator(ctx), dt)
+				defer tb.Release()
+
+				mult := int64(math.Pow10(9 - int(srcMeta.Scale)))
+				for i, t := range ints.(*array.Int64).Int64Values() {
+					if ints.IsNull(i) {
+						tb.AppendNull()
+						continue
+					}
+					tb.Append(arrow.Time64(t * mult))
+				}
+				return tb.NewArray(), nil
 			}
 		case "TIMESTAMP_NTZ":
 			dt := &arrow.TimestampType{Unit: arrow.Nanosecond}
@@ -133,13 +156,13 @@ func getTransformer(sc *arrow.Schema, ld gosnowflake.ArrowStreamLoader) (*arrow.
 						tb.Append(arrow.Timestamp(time.Unix(epoch[i], int64(fraction[i])).UnixNano()))
 					}
 				} else {
-					for i, t := range a.(*array.Timestamp).TimestampValues() {
+					for i, t := range a.(*array.Int64).Int64Values() {
 						if a.IsNull(i) {
 							tb.AppendNull()
 							continue
 						}
 
-						val := time.Unix(0, int64(t)*int64(math.Pow10(9-int(srcMeta.Scale)))).UTC()
+						val := time.Unix(0, t*int64(math.Pow10(9-int(srcMeta.Scale)))).UTC()
 						tb.Append(arrow.Timestamp(val.UnixNano()))
 					}
 				}
@@ -166,15 +189,15 @@ func getTransformer(sc *arrow.Schema, ld gosnowflake.ArrowStreamLoader) (*arrow.
 						tb.Append(arrow.Timestamp(time.Unix(epoch[i], int64(fraction[i])).UnixNano()))
 					}
 				} else {
-					for i, t := range a.(*array.Timestamp).TimestampValues() {
+					scale := int64(math.Pow10(int(srcMeta.Scale)))
+					for i, t := range a.(*array.Int64).Int64Values() {
 						if a.IsNull(i) {
 							tb.AppendNull()
 							continue
 						}
 
-						q := int64(t) / int64(math.Pow10(int(srcMeta.Scale)))
-						r := int64(t) % int64(math.Pow10(int(srcMeta.Scale)))
-						tb.Append(arrow.Timestamp(time.Unix(q, r).UnixNano()))
+						q, r := t/scale, t%scale
+						tb.Append(arrow.Timestamp(time.Unix(q, r*int64(math.Pow10(9-int(srcMeta.Scale)))).UnixNano()))
 					}
 				}
 				return tb.NewArray(), nil
@@ -228,6 +251,84 @@ func getTransformer(sc *arrow.Schema, ld gosnowflake.ArrowStreamLoader) (*arrow.
 	return out, getRecTransformer(out, transformers)
 }
 
+// rowTypeField returns the field for a column of a Snowflake result,
+// with the type getTransformer converts the column to. It is used when
+// there is no record batch stream to take the schema from, so the field
+// has none of the metadata Snowflake attaches to the streams.
+func rowTypeField(name, typ string, scale int64, nullable bool, loc *time.Location) arrow.Field {
+	f := arrow.Field{Name: name, Nullable: nullable}
+
+	switch strings.ToUpper(typ) {
+	case "FIXED":
+		if scale == 0 {
+			f.Type = arrow.PrimitiveTypes.Int64
+		} else {
+			f.Type = arrow.PrimitiveTypes.Float64
+		}
+	case "REAL":
+		f.Type = arrow.PrimitiveTypes.Float64
+	case "BOOLEAN":
+		f.Type = arrow.FixedWidthTypes.Boolean
+	case "BINARY":
+		f.Type = arrow.BinaryTypes.Binary
+	case "DATE":
+		f.Type = arrow.FixedWidthTypes.Date32
+	case "TIME":
+		f.Type = arrow.FixedWidthTypes.Time64ns
+	case "TIMESTAMP_NTZ", "TIMESTAMP_TZ":
+		f.Type = &arrow.TimestampType{Unit: arrow.Nanosecond}
+	case "TIMESTAMP_LTZ":
+		f.Type = &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: loc.String()}
+	default:
+		// text, and the semi-structured and geospatial types which
+		// snowflake returns as strings
+		f.Type = arrow.BinaryTypes.String
+	}
+	return f
+}
+
+// rowTypesSchema returns the schema of the results of ld from the column
+// descriptions, for an empty result which comes without a stream.
+func rowTypesSchema(ld gosnowflake.ArrowStreamLoader) *arrow.Schema {
+	loc, types := ld.Location(), ld.RowTypes()
+	fields := make([]arrow.Field, len(types))
+	for i, t := range types {
+		fields[i] = rowTypeField(t.Name, t.Type, t.Scale, t.Nullable, loc)
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// getSchema returns the schema the results of ld are read with, without
+// reading any records.
+func getSchema(ctx context.Context, alloc memory.Allocator, ld gosnowflake.ArrowStreamLoader) (*arrow.Schema, error) {
+	batches, err := ld.GetBatches()
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+
+	if len(batches) == 0 {
+		return rowTypesSchema(ld), nil
+	}
+
+	r, err := batches[0].GetStream(ctx)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	defer r.Close()
+
+	rr, err := ipc.NewReader(r, ipc.WithAllocator(alloc))
+	if err != nil {
+		return nil, adbc.Error{
+			Msg:  err.Error(),
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	defer rr.Release()
+
+	schema, _ := getTransformer(rr.Schema(), ld)
+	return schema, nil
+}
+
 type reader struct {
 	refCount   int64
 	schema     *arrow.Schema
@@ -245,6 +346,14 @@ func newRecordReader(ctx context.Context, alloc memory.Allocator, ld gosnowflake
 		return nil, errToAdbcErr(adbc.StatusInternal, err)
 	}
 
+	if len(batches) == 0 {
+		rdr, err := array.NewRecordReader(rowTypesSchema(ld), nil)
+		if err != nil {
+			return nil, adbc.Error{Msg: err.Error(), Code: adbc.StatusInternal}
+		}
+		return rdr, nil
+	}
+
 	ch := make(chan arrow.Record, bufferSize)
 	r, err := batches[0].GetStream(ctx)
 	if err != nil {
@@ -271,9 +380,15 @@ func newRecordReader(ctx context.Context, alloc memory.Allocator, ld gosnowflake
 		}
 	}()
 
+	lastChannelIndex := len(batches) - 1
 	group.Go(func() error {
 		defer rr.Release()
 		defer r.Close()
+		// as with the other batches, only the last channel is left open
+		// for the closing goroutine below
+		if lastChannelIndex != 0 {
+			defer close(ch)
+		}
 
 		for rr.Next() && ctx.Err() == nil {
 			rec := rr.Record()
@@ -296,7 +411,6 @@ func newRecordReader(ctx context.Context, alloc memory.Allocator, ld gosnowflake
 		schema:   schema,
 	}
 
-	lastChannelIndex := len(chs) - 1
 	for i, b := range batches[1:] {
 		batch, batchIdx := b, i+1
 		chs[batchIdx] = make(chan arrow.Record, bufferSize)
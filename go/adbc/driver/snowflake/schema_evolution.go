@@ -0,0 +1,243 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snowflake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+)
+
+// the largest precision snowflake supports for NUMBER
+const maxNumberPrecision = 38
+
+// parseColumnType splits a column type as reported by DESC TABLE, such
+// as NUMBER(38,0) or VARCHAR(16777216), into its name and parameters.
+func parseColumnType(typ string) (name string, params []int) {
+	paren := strings.Index(typ, "(")
+	if paren == -1 {
+		return strings.ToUpper(typ), nil
+	}
+
+	name = strings.ToUpper(typ[:paren])
+	for _, p := range strings.Split(strings.TrimSuffix(typ[paren+1:], ")"), ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return name, nil
+		}
+		params = append(params, v)
+	}
+	return
+}
+
+// intDigits is the number of decimal digits needed to hold any value
+// of an integer type.
+func intDigits(dt arrow.DataType) int {
+	switch dt.ID() {
+	case arrow.INT8, arrow.UINT8:
+		return 3
+	case arrow.INT16, arrow.UINT16:
+		return 5
+	case arrow.INT32, arrow.UINT32:
+		return 10
+	case arrow.INT64:
+		return 19
+	}
+	return 20
+}
+
+func valueType(dt arrow.DataType) arrow.DataType {
+	switch dt := dt.(type) {
+	case arrow.ExtensionType:
+		return valueType(dt.StorageType())
+	case *arrow.DictionaryType:
+		return valueType(dt.ValueType)
+	case *arrow.RunEndEncodedType:
+		return valueType(dt.Encoded())
+	}
+	return dt
+}
+
+// columnEvolution checks whether values of the arrow type dt can be
+// inserted into an existing column of the given snowflake type. If the
+// column has to be widened first, the new type is returned.
+func columnEvolution(dt arrow.DataType, colType string) (widenTo string, ok bool) {
+	name, params := parseColumnType(colType)
+
+	// widenNumber handles values needing `digits` integral digits and
+	// `scale` fractional digits going into a NUMBER column.
+	widenNumber := func(digits, scale int) (string, bool) {
+		prec, colScale := maxNumberPrecision, 0
+		if len(params) > 0 {
+			prec = params[0]
+		}
+		if len(params) > 1 {
+			colScale = params[1]
+		}
+
+		// the scale of a column can't be changed and decreasing
+		// it would lose the fractional digits
+		if scale > colScale {
+			return "", false
+		}
+		if prec-colScale >= digits {
+			return "", true
+		}
+		if digits+colScale > maxNumberPrecision {
+			return "", false
+		}
+		return fmt.Sprintf("NUMBER(%d,%d)", digits+colScale, colScale), true
+	}
+
+	switch dt := valueType(dt); dt.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		switch name {
+		case "NUMBER", "DECIMAL", "NUMERIC":
+			return widenNumber(intDigits(dt), 0)
+		case "FLOAT", "DOUBLE", "REAL":
+			// a double can only hold every integer of up to 32 bits
+			return "", intDigits(dt) <= 10
+		}
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		switch name {
+		case "FLOAT", "DOUBLE", "REAL":
+			return "", true
+		}
+	case arrow.DECIMAL128, arrow.DECIMAL256:
+		switch name {
+		case "NUMBER", "DECIMAL", "NUMERIC":
+			dec := dt.(arrow.DecimalType)
+			return widenNumber(int(dec.GetPrecision()-dec.GetScale()), int(dec.GetScale()))
+		}
+	case arrow.STRING, arrow.LARGE_STRING:
+		switch name {
+		case "VARCHAR", "TEXT", "STRING", "CHAR", "CHARACTER":
+			// the length of a column isn't changed, values which are
+			// too long are rejected when they are inserted
+			return "", true
+		}
+	case arrow.BINARY, arrow.LARGE_BINARY:
+		return "", name == "BINARY" || name == "VARBINARY"
+	case arrow.FIXED_SIZE_BINARY:
+		if name != "BINARY" && name != "VARBINARY" {
+			return "", false
+		}
+		return "", len(params) == 0 || params[0] >= dt.(*arrow.FixedSizeBinaryType).ByteWidth
+	case arrow.BOOL:
+		return "", name == "BOOLEAN"
+	case arrow.DATE32, arrow.DATE64:
+		return "", name == "DATE"
+	case arrow.TIME32, arrow.TIME64:
+		return "", name == "TIME"
+	case arrow.TIMESTAMP:
+		switch name {
+		case "TIMESTAMP", "DATETIME", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ":
+			return "", true
+		}
+	case arrow.LIST, arrow.LARGE_LIST, arrow.FIXED_SIZE_LIST:
+		return "", name == "ARRAY" || name == "VARIANT"
+	case arrow.STRUCT, arrow.MAP:
+		return "", name == "OBJECT" || name == "VARIANT"
+	case arrow.SPARSE_UNION, arrow.DENSE_UNION:
+		return "", name == "VARIANT"
+	}
+	return "", false
+}
+
+// schemaEvolution compares the schema of the bound data with the
+// columns of the existing target table. It returns the statements
+// needed for the table to accept the data, or a description of each
+// field which can't be reconciled safely.
+func schemaEvolution(target string, existing []arrow.Field, bound *arrow.Schema) (ddl, conflicts []string) {
+	columns := make(map[string]arrow.Field, len(existing))
+	for _, f := range existing {
+		columns[f.Name] = f
+	}
+
+	for _, f := range bound.Fields() {
+		col, ok := columns[f.Name]
+		if !ok {
+			if !f.Nullable {
+				conflicts = append(conflicts, fmt.Sprintf("new field '%s' must be nullable to be added to the table", f.Name))
+				continue
+			}
+
+			ty := toSnowflakeType(f.Type)
+			if ty == "" {
+				conflicts = append(conflicts, fmt.Sprintf("new field '%s' has unsupported type %s", f.Name, f.Type))
+				continue
+			}
+			ddl = append(ddl, "ALTER TABLE "+target+" ADD COLUMN "+quoteIdentifier(f.Name)+" "+ty)
+			continue
+		}
+		delete(columns, f.Name)
+
+		colType, _ := col.Metadata.GetValue("DATA_TYPE")
+		widenTo, ok := columnEvolution(f.Type, colType)
+		if !ok {
+			conflicts = append(conflicts, fmt.Sprintf("field '%s' of type %s is incompatible with column type %s", f.Name, f.Type, colType))
+			continue
+		}
+
+		// constraints are never relaxed: a nullable field can go into
+		// a NOT NULL column as long as it has no nulls, which
+		// snowflake checks when the data is inserted
+		if widenTo != "" {
+			ddl = append(ddl, "ALTER TABLE "+target+" ALTER COLUMN "+quoteIdentifier(f.Name)+" SET DATA TYPE "+widenTo)
+		}
+	}
+
+	for _, f := range existing {
+		if _, ok := columns[f.Name]; ok && !f.Nullable {
+			conflicts = append(conflicts, fmt.Sprintf("column '%s' is NOT NULL but missing from the bound data", f.Name))
+		}
+	}
+
+	return
+}
+
+// evolveSchema alters the ingestion target so that the bound data can be
+// inserted. Every field is checked before any change is made, so the
+// table is left as it is if the data can't be ingested safely. The
+// changes themselves aren't atomic, as snowflake commits each DDL
+// statement on its own: if one of them fails, those before it remain.
+func (st *statement) evolveSchema(ctx context.Context, target string) error {
+	existing, err := st.cnxn.describeTable(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	ddl, conflicts := schemaEvolution(target, existing, st.boundSchema())
+	if len(conflicts) > 0 {
+		return adbc.Error{
+			Msg:  fmt.Sprintf("cannot evolve schema of %s to ingest bound data: %s", target, strings.Join(conflicts, "; ")),
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	for _, q := range ddl {
+		if _, err := st.cnxn.cn.ExecContext(ctx, q, nil); err != nil {
+			return errToAdbcErr(adbc.StatusInternal, err)
+		}
+	}
+	return nil
+}
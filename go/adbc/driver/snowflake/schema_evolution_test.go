@@ -0,0 +1,179 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnEvolution(t *testing.T) {
+	tests := []struct {
+		dt      arrow.DataType
+		colType string
+		widenTo string
+		ok      bool
+	}{
+		{arrow.PrimitiveTypes.Int64, "NUMBER(38,0)", "", true},
+		{arrow.PrimitiveTypes.Int64, "NUMBER(10,0)", "NUMBER(19,0)", true},
+		{arrow.PrimitiveTypes.Int16, "NUMBER(10,2)", "", true},
+		{arrow.PrimitiveTypes.Int64, "NUMBER(10,2)", "NUMBER(21,2)", true},
+		{arrow.PrimitiveTypes.Uint64, "NUMBER(38,20)", "", false},
+		{arrow.PrimitiveTypes.Int32, "FLOAT", "", true},
+		{arrow.PrimitiveTypes.Int64, "FLOAT", "", false},
+		{arrow.PrimitiveTypes.Float32, "FLOAT", "", true},
+		{arrow.PrimitiveTypes.Float64, "NUMBER(38,0)", "", false},
+		{&arrow.Decimal128Type{Precision: 12, Scale: 2}, "NUMBER(10,2)", "NUMBER(12,2)", true},
+		{&arrow.Decimal128Type{Precision: 5, Scale: 1}, "NUMBER(10,2)", "", true},
+		{&arrow.Decimal128Type{Precision: 10, Scale: 4}, "NUMBER(10,2)", "", false},
+		{arrow.BinaryTypes.String, "VARCHAR(16777216)", "", true},
+		{arrow.BinaryTypes.LargeString, "VARCHAR(10)", "", true},
+		{arrow.BinaryTypes.String, "NUMBER(38,0)", "", false},
+		{arrow.BinaryTypes.Binary, "BINARY(8388608)", "", true},
+		{&arrow.FixedSizeBinaryType{ByteWidth: 16}, "BINARY(8)", "", false},
+		{arrow.FixedWidthTypes.Boolean, "BOOLEAN", "", true},
+		{arrow.FixedWidthTypes.Date32, "DATE", "", true},
+		{arrow.FixedWidthTypes.Time64us, "TIME(9)", "", true},
+		{arrow.FixedWidthTypes.Timestamp_ms, "TIMESTAMP_NTZ(9)", "", true},
+		{arrow.FixedWidthTypes.Timestamp_ms, "DATE", "", false},
+		{arrow.ListOf(arrow.PrimitiveTypes.Int32), "ARRAY", "", true},
+		{arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32}), "VARIANT", "", true},
+		{arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32}), "ARRAY", "", false},
+		{&arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}, "VARCHAR(16777216)", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dt.String()+" into "+tt.colType, func(t *testing.T) {
+			widenTo, ok := columnEvolution(tt.dt, tt.colType)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.widenTo, widenTo)
+		})
+	}
+}
+
+func existingColumn(name, typ string, nullable bool) arrow.Field {
+	return arrow.Field{Name: name, Nullable: nullable,
+		Metadata: arrow.MetadataFrom(map[string]string{"DATA_TYPE": typ})}
+}
+
+func TestSchemaEvolution(t *testing.T) {
+	existing := []arrow.Field{
+		existingColumn("id", "NUMBER(10,0)", false),
+		existingColumn("name", "VARCHAR(16777216)", false),
+		existingColumn("note", "VARCHAR(16777216)", true),
+	}
+
+	t.Run("compatible", func(t *testing.T) {
+		bound := arrow.NewSchema([]arrow.Field{
+			{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+			{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+			{Name: "added", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		}, nil)
+
+		ddl, conflicts := schemaEvolution(`tbl`, existing, bound)
+		assert.Empty(t, conflicts)
+		assert.Equal(t, []string{
+			`ALTER TABLE tbl ALTER COLUMN "id" SET DATA TYPE NUMBER(19,0)`,
+			`ALTER TABLE tbl ADD COLUMN "added" boolean`,
+		}, ddl)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		bound := arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: arrow.BinaryTypes.String},
+			{Name: "required", Type: arrow.PrimitiveTypes.Int32},
+			{Name: "note", Type: arrow.BinaryTypes.String, Nullable: true},
+		}, nil)
+
+		_, conflicts := schemaEvolution(`tbl`, existing, bound)
+		require.Len(t, conflicts, 3)
+		assert.Contains(t, conflicts[0], "'id'")
+		assert.Contains(t, conflicts[1], "'required'")
+		assert.Contains(t, conflicts[2], "'name'")
+	})
+}
+
+func TestIngestSchemaEvolution(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "extra", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "int64s", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil)
+	rec, _, err := array.RecordFromJSON(mem, sc, strings.NewReader(`[{"extra": "a", "int64s": 1}]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	newStmt := func(cn *standInConn) *statement {
+		st := &statement{alloc: mem, cnxn: &cnxn{cn: cn}}
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetTable, "tbl"))
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeAppend))
+		require.NoError(t, st.SetOption(OptionStatementIngestSchemaEvolution, adbc.OptionValueEnabled))
+		require.NoError(t, st.Bind(context.Background(), rec))
+		return st
+	}
+
+	t.Run("evolve", func(t *testing.T) {
+		cn := &standInConn{desc: [][]driver.Value{descRow("int64s", "NUMBER(10,0)", true)}}
+		st := newStmt(cn)
+		defer st.Close()
+
+		_, err := st.ExecuteUpdate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`DESC TABLE tbl`,
+			`ALTER TABLE tbl ADD COLUMN "extra" text`,
+			`ALTER TABLE tbl ALTER COLUMN "int64s" SET DATA TYPE NUMBER(19,0)`,
+			`INSERT INTO tbl ("extra", "int64s") VALUES (?, ?)`,
+		}, cn.queries)
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		cn := &standInConn{desc: [][]driver.Value{
+			descRow("int64s", "BOOLEAN", true),
+			descRow("other", "VARCHAR(16777216)", false),
+		}}
+		st := newStmt(cn)
+		defer st.Close()
+
+		var adbcErr adbc.Error
+		_, err := st.ExecuteUpdate(context.Background())
+		require.ErrorAs(t, err, &adbcErr)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Contains(t, adbcErr.Msg, "'int64s'")
+		assert.Contains(t, adbcErr.Msg, "'other'")
+		// nothing was altered
+		assert.Equal(t, []string{`DESC TABLE tbl`}, cn.queries)
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		st := &statement{alloc: mem, cnxn: &cnxn{cn: &standInConn{}}}
+		var adbcErr adbc.Error
+		require.ErrorAs(t, st.SetOption(OptionStatementIngestSchemaEvolution, "sometimes"), &adbcErr)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+	})
+}
@@ -0,0 +1,994 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package snowflake_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	driver "github.com/apache/arrow-adbc/go/adbc/driver/snowflake"
+	"github.com/apache/arrow-adbc/go/adbc/driver/snowflake/internal/standin"
+	"github.com/apache/arrow-adbc/go/adbc/sqldriver"
+	"github.com/apache/arrow-adbc/go/adbc/validation"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/decimal128"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/snowflakedb/gosnowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestADBCSnowflakeStandIn(t *testing.T) {
+	srv := standin.NewServer("ADBC_TESTING")
+	defer srv.Close()
+
+	uri := srv.DSN()
+	q := &SnowflakeQuirks{dsn: uri, schemaName: createTempSchema(uri)}
+	defer dropTempSchema(uri, q.schemaName)
+	suite.Run(t, &validation.DatabaseTests{Quirks: q})
+	suite.Run(t, &validation.ConnectionTests{Quirks: q})
+	suite.Run(t, &validation.StatementTests{Quirks: q})
+}
+
+// openStandIn connects to a fresh stand-in server, checking that no
+// memory is leaked once the test is done.
+func openStandIn(t *testing.T) (*standin.Server, adbc.Database) {
+	srv := standin.NewServer("ADBC_TESTING")
+	t.Cleanup(srv.Close)
+
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	t.Cleanup(func() { mem.AssertSize(t, 0) })
+
+	db, err := driver.Driver{Alloc: mem}.NewDatabase(map[string]string{
+		adbc.OptionKeyURI: srv.DSN(),
+	})
+	require.NoError(t, err)
+	return srv, db
+}
+
+func openConn(t *testing.T, db adbc.Database) adbc.Connection {
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { cnxn.Close() })
+	return cnxn
+}
+
+func execUpdate(t *testing.T, cnxn adbc.Connection, query string) int64 {
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery(query))
+	n, err := stmt.ExecuteUpdate(context.Background())
+	require.NoError(t, err, query)
+	return n
+}
+
+// queryAll runs a query, returning its result as a single record.
+func queryAll(t *testing.T, cnxn adbc.Connection, query string) arrow.Record {
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery(query))
+	rdr, _, err := stmt.ExecuteQuery(context.Background())
+	require.NoError(t, err, query)
+	defer rdr.Release()
+
+	var recs []arrow.Record
+	for rdr.Next() {
+		rec := rdr.Record()
+		rec.Retain()
+		defer rec.Release()
+		recs = append(recs, rec)
+	}
+	require.NoError(t, rdr.Err())
+
+	if len(recs) == 0 {
+		return array.NewRecord(rdr.Schema(), nil, 0)
+	}
+
+	cols := make([]arrow.Array, len(rdr.Schema().Fields()))
+	for i := range cols {
+		chunks := make([]arrow.Array, len(recs))
+		for j, rec := range recs {
+			chunks[j] = rec.Column(i)
+		}
+		col, err := array.Concatenate(chunks, memory.DefaultAllocator)
+		require.NoError(t, err)
+		defer col.Release()
+		cols[i] = col
+	}
+	return array.NewRecord(rdr.Schema(), cols, int64(cols[0].Len()))
+}
+
+func TestStandInResultTypes(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+
+	execUpdate(t, cnxn, `CREATE TABLE types (
+		num NUMBER(10,2), small NUMBER(38,0), tm TIME(3), ntz3 TIMESTAMP_NTZ(3),
+		ntz9 TIMESTAMP_NTZ(9), ltz3 TIMESTAMP_LTZ(3), ltz9 TIMESTAMP_LTZ(9),
+		tz0 TIMESTAMP_TZ(0), tz9 TIMESTAMP_TZ(9), d DATE, b BOOLEAN, bin BINARY,
+		v VARIANT)`)
+	n := execUpdate(t, cnxn, `INSERT INTO types VALUES (
+		1.25, 7, '12:34:56.789123', '2023-01-02 03:04:05.123456789',
+		'2023-01-02 03:04:05.123456789', '2023-01-02 03:04:05.123456 +02:00',
+		'2023-01-02 03:04:05.123456789 +02:00', '2023-01-02 03:04:05.9 -05:00',
+		'2023-01-02 03:04:05.123456789 +02:00', '2023-01-02', TRUE, 'CAFE',
+		PARSE_JSON('{"a": [1, 2]}'))`)
+	assert.EqualValues(t, 1, n)
+	execUpdate(t, cnxn, `INSERT INTO types (num) VALUES (NULL)`)
+
+	rec := queryAll(t, cnxn, `SELECT * FROM types ORDER BY num`)
+	defer rec.Release()
+	require.EqualValues(t, 2, rec.NumRows())
+
+	plus2 := time.FixedZone("", 2*60*60)
+	expected := []struct {
+		dt  arrow.DataType
+		val interface{}
+	}{
+		{arrow.PrimitiveTypes.Float64, 1.25},
+		{arrow.PrimitiveTypes.Int64, int64(7)},
+		{arrow.FixedWidthTypes.Time64ns, arrow.Time64((12*time.Hour + 34*time.Minute + 56789*time.Millisecond).Nanoseconds())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 123000000, time.UTC).UnixNano())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 123456789, time.UTC).UnixNano())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 123000000, plus2).UnixNano())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 123456789, plus2).UnixNano())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 0, time.FixedZone("", -5*60*60)).UnixNano())},
+		{&arrow.TimestampType{Unit: arrow.Nanosecond},
+			arrow.Timestamp(time.Date(2023, 1, 2, 3, 4, 5, 123456789, plus2).UnixNano())},
+		{arrow.FixedWidthTypes.Date32, arrow.Date32FromTime(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))},
+		{arrow.FixedWidthTypes.Boolean, true},
+		{arrow.BinaryTypes.Binary, []byte{0xca, 0xfe}},
+		{arrow.BinaryTypes.String, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+	}
+
+	for i, exp := range expected {
+		col := rec.Column(i)
+		name := rec.ColumnName(i)
+		assert.Truef(t, arrow.TypeEqual(exp.dt, col.DataType()), "%s: expected %s, got %s", name, exp.dt, col.DataType())
+		assert.Truef(t, col.IsNull(1), "%s should be null in the second row", name)
+		if col.IsNull(0) {
+			t.Errorf("%s should not be null in the first row", name)
+			continue
+		}
+
+		var got interface{}
+		switch col := col.(type) {
+		case *array.Float64:
+			got = col.Value(0)
+		case *array.Int64:
+			got = col.Value(0)
+		case *array.Time64:
+			got = col.Value(0)
+		case *array.Timestamp:
+			got = col.Value(0)
+		case *array.Date32:
+			got = col.Value(0)
+		case *array.Boolean:
+			got = col.Value(0)
+		case *array.Binary:
+			got = col.Value(0)
+		case *array.String:
+			got = col.Value(0)
+		}
+		assert.Equal(t, exp.val, got, name)
+	}
+}
+
+func TestStandInExecuteSchema(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+
+	execUpdate(t, cnxn, `CREATE TABLE types (
+		num NUMBER(10,2), small NUMBER(38,0), tm TIME(3), ntz3 TIMESTAMP_NTZ(3),
+		ntz9 TIMESTAMP_NTZ(9), ltz3 TIMESTAMP_LTZ(3), ltz9 TIMESTAMP_LTZ(9),
+		tz0 TIMESTAMP_TZ(0), tz9 TIMESTAMP_TZ(9), d DATE, b BOOLEAN, bin BINARY,
+		v VARIANT, s VARCHAR)`)
+	execUpdate(t, cnxn, `INSERT INTO types (num, small, s) VALUES (1.25, 7, 'a')`)
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	var adbcErr adbc.Error
+	_, err = stmt.(adbc.StatementExecuteSchema).ExecuteSchema(context.Background())
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidState, adbcErr.Code)
+
+	require.NoError(t, stmt.SetSqlQuery("SELECT * FROM types -- all of them"))
+	schema, err := stmt.(adbc.StatementExecuteSchema).ExecuteSchema(context.Background())
+	require.NoError(t, err)
+
+	// the schema is the one the results are converted to, without the
+	// metadata of the record batch streams
+	rec := queryAll(t, cnxn, `SELECT * FROM types`)
+	defer rec.Release()
+	require.Len(t, schema.Fields(), len(rec.Schema().Fields()))
+	for i, expected := range rec.Schema().Fields() {
+		f := schema.Field(i)
+		assert.Equal(t, expected.Name, f.Name)
+		assert.Truef(t, arrow.TypeEqual(expected.Type, f.Type), "%s: expected %s, got %s", f.Name, expected.Type, f.Type)
+		assert.Equal(t, expected.Nullable, f.Nullable, f.Name)
+		assert.Empty(t, f.Metadata.Keys(), f.Name)
+	}
+
+	// errors compiling the query are reported
+	require.NoError(t, stmt.SetSqlQuery(`SELECT * FROM missing`))
+	_, err = stmt.(adbc.StatementExecuteSchema).ExecuteSchema(context.Background())
+	assert.Error(t, err)
+}
+
+// readIDs reads the first column of a result, of type Int64.
+func readIDs(t *testing.T, rdr array.RecordReader) []int64 {
+	defer rdr.Release()
+	var ids []int64
+	for rdr.Next() {
+		ids = append(ids, rdr.Record().Column(0).(*array.Int64).Int64Values()...)
+	}
+	require.NoError(t, rdr.Err())
+	return ids
+}
+
+func TestStandInExecuteMulti(t *testing.T) {
+	srv, db := openStandIn(t)
+	cnxn := openConn(t, db)
+	ctx := context.Background()
+
+	execUpdate(t, cnxn, `CREATE TABLE types (num NUMBER(10,2), small NUMBER(38,0), tm TIME(3),
+		ntz TIMESTAMP_NTZ(9), ltz TIMESTAMP_LTZ(9), d DATE, b BOOLEAN, bin BINARY, s VARCHAR)`)
+	execUpdate(t, cnxn, `INSERT INTO types VALUES (1.25, 7, '12:34:56.789', '2023-01-02 03:04:05',
+		'2023-01-02 03:04:05 +02:00', '2023-01-02', TRUE, 'CAFE', 'a')`)
+	execUpdate(t, cnxn, `CREATE TABLE ids (id INTEGER)`)
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery(`INSERT INTO ids VALUES (2), (1);
+		SELECT * FROM ids ORDER BY id;
+		SELECT * FROM types;`))
+	res, err := stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	require.NoError(t, err)
+
+	rdr, n, err := res.NextResult(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, []int64{2}, readIDs(t, rdr))
+
+	rdr, n, err = res.NextResult(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, -1, n)
+	assert.Equal(t, []int64{1, 2}, readIDs(t, rdr))
+
+	// the results are converted to the same types as by ExecuteQuery
+	rdr, _, err = res.NextResult(ctx)
+	require.NoError(t, err)
+	defer rdr.Release()
+	expected := queryAll(t, cnxn, `SELECT * FROM types`)
+	defer expected.Release()
+	assert.Truef(t, expected.Schema().Equal(rdr.Schema()), "expected %s, got %s", expected.Schema(), rdr.Schema())
+	require.True(t, rdr.Next())
+	assert.True(t, array.RecordEqual(expected, rdr.Record()), "expected %s, got %s", expected, rdr.Record())
+	assert.False(t, rdr.Next())
+
+	_, _, err = res.NextResult(ctx)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, res.Close())
+
+	// a single statement is executed as by ExecuteQuery
+	require.NoError(t, stmt.SetSqlQuery(`SELECT * FROM ids ORDER BY id;`))
+	queries := len(srv.Queries())
+	res, err = stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	require.NoError(t, err)
+	rdr, n, err = res.NextResult(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, []int64{1, 2}, readIDs(t, rdr))
+	_, _, err = res.NextResult(ctx)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, res.Close())
+	assert.Len(t, srv.Queries(), queries+1)
+
+	// the statements after a failing one are not run
+	require.NoError(t, stmt.SetSqlQuery(`SELECT * FROM missing; INSERT INTO ids VALUES (3)`))
+	_, err = stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	assert.Error(t, err)
+
+	// semicolons which don't end the statements are left to snowflake
+	require.NoError(t, stmt.SetSqlQuery(`SELECT id, ';' AS sep FROM ids ORDER BY id DESC; -- last;`))
+	res, err = stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	require.NoError(t, err)
+	rdr, _, err = res.NextResult(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, readIDs(t, rdr))
+	_, _, err = res.NextResult(ctx)
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, res.Close())
+
+	// gosnowflake cannot move past a result of several chunks
+	srv.SetRowsPerChunk(1)
+	require.NoError(t, stmt.SetSqlQuery(`SELECT * FROM ids ORDER BY id; SELECT * FROM ids`))
+	res, err = stmt.(adbc.StatementExecuteMulti).ExecuteMulti(ctx)
+	require.NoError(t, err)
+	defer res.Close()
+	rdr, _, err = res.NextResult(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, readIDs(t, rdr))
+
+	var adbcErr adbc.Error
+	_, _, err = res.NextResult(ctx)
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+func TestStandInIngestRoundTrip(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	tsTZ := &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "f", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "b", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "bin", Type: arrow.BinaryTypes.Binary, Nullable: true},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "tm", Type: arrow.FixedWidthTypes.Time64us, Nullable: true},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+		{Name: "ltz", Type: tsTZ, Nullable: true},
+		{Name: "dec", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+	}, nil)
+
+	ts := time.Date(2023, 5, 6, 7, 8, 9, 123456000, time.UTC)
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2, 3}, nil)
+	bldr.Field(1).(*array.Float64Builder).AppendValues([]float64{1.5, -2.25, 0}, []bool{true, true, false})
+	bldr.Field(2).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, []bool{true, false, true})
+	bldr.Field(3).(*array.BooleanBuilder).AppendValues([]bool{true, false, true}, nil)
+	bldr.Field(4).(*array.BinaryBuilder).AppendValues([][]byte{{1, 2}, nil, {0xff}}, []bool{true, false, true})
+	bldr.Field(5).(*array.Date32Builder).AppendValues([]arrow.Date32{
+		arrow.Date32FromTime(ts), 0, arrow.Date32FromTime(ts.AddDate(0, 0, 1))}, nil)
+	bldr.Field(6).(*array.Time64Builder).AppendValues([]arrow.Time64{
+		arrow.Time64((7*time.Hour + 123456*time.Microsecond).Microseconds()), 0, 1}, []bool{true, false, true})
+	bldr.Field(7).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{
+		arrow.Timestamp(ts.UnixMicro()), 0, arrow.Timestamp(ts.Add(time.Hour).UnixMicro())}, []bool{true, false, true})
+	bldr.Field(8).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{
+		arrow.Timestamp(ts.UnixMicro()), arrow.Timestamp(ts.UnixMicro() + 1), 0}, []bool{true, true, false})
+	bldr.Field(9).(*array.Decimal128Builder).AppendValues([]decimal128.Num{
+		decimal128.FromI64(12345), decimal128.FromI64(-1), decimal128.FromI64(0)}, nil)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestTargetTable, "roundtrip"))
+	require.NoError(t, stmt.Bind(context.Background(), rec))
+	n, err := stmt.ExecuteUpdate(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+
+	out := queryAll(t, cnxn, `SELECT * FROM roundtrip ORDER BY "id"`)
+	defer out.Release()
+	require.EqualValues(t, 3, out.NumRows())
+
+	assert.Equal(t, []int64{1, 2, 3}, out.Column(0).(*array.Int64).Int64Values())
+	f := out.Column(1).(*array.Float64)
+	assert.Equal(t, []float64{1.5, -2.25}, f.Float64Values()[:2])
+	assert.True(t, f.IsNull(2))
+	s := out.Column(2).(*array.String)
+	assert.Equal(t, "a", s.Value(0))
+	assert.True(t, s.IsNull(1))
+	assert.Equal(t, []bool{true, false, true},
+		[]bool{out.Column(3).(*array.Boolean).Value(0), out.Column(3).(*array.Boolean).Value(1), out.Column(3).(*array.Boolean).Value(2)})
+	bin := out.Column(4).(*array.Binary)
+	assert.Equal(t, []byte{1, 2}, bin.Value(0))
+	assert.True(t, bin.IsNull(1))
+	assert.Equal(t, []arrow.Date32{arrow.Date32FromTime(ts), 0, arrow.Date32FromTime(ts.AddDate(0, 0, 1))},
+		out.Column(5).(*array.Date32).Date32Values())
+
+	tm := out.Column(6).(*array.Time64)
+	assert.Equal(t, arrow.Time64((7*time.Hour + 123456*time.Microsecond).Nanoseconds()), tm.Value(0))
+	assert.True(t, tm.IsNull(1))
+	assert.Equal(t, arrow.Time64(time.Microsecond.Nanoseconds()), tm.Value(2))
+
+	// timezone-naive timestamps are ingested as UTC into TIMESTAMP_TZ
+	naive := out.Column(7).(*array.Timestamp)
+	assert.Equal(t, arrow.Timestamp(ts.UnixNano()), naive.Value(0))
+	assert.True(t, naive.IsNull(1))
+	ltz := out.Column(8).(*array.Timestamp)
+	assert.Equal(t, "UTC", ltz.DataType().(*arrow.TimestampType).TimeZone)
+	assert.Equal(t, arrow.Timestamp(ts.UnixNano()+1000), ltz.Value(1))
+	assert.True(t, ltz.IsNull(2))
+
+	assert.Equal(t, []float64{123.45, -0.01, 0}, out.Column(9).(*array.Float64).Float64Values())
+}
+
+type testExtType struct {
+	arrow.ExtensionBase
+}
+
+func (testExtType) ArrayType() reflect.Type { return reflect.TypeOf(testExtArray{}) }
+func (testExtType) ExtensionName() string   { return "adbc.test" }
+func (testExtType) Serialize() string       { return "" }
+func (e testExtType) Deserialize(storage arrow.DataType, _ string) (arrow.ExtensionType, error) {
+	return &testExtType{ExtensionBase: arrow.ExtensionBase{Storage: storage}}, nil
+}
+func (e testExtType) ExtensionEquals(other arrow.ExtensionType) bool {
+	return e.ExtensionName() == other.ExtensionName() &&
+		arrow.TypeEqual(e.StorageType(), other.StorageType())
+}
+
+type testExtArray struct {
+	array.ExtensionArrayBase
+}
+
+// TestStandInBindRoundTrip ingests a column of each type, which binds
+// it as an array, and again one row at a time, which binds scalars,
+// then reads back what the stand-in stored.
+func TestStandInBindRoundTrip(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+	ctx := context.Background()
+
+	fromJSON := func(t *testing.T, dt arrow.DataType, js string) arrow.Array {
+		arr, _, err := array.FromJSON(mem, dt, strings.NewReader(js))
+		require.NoError(t, err)
+		return arr
+	}
+
+	ingest := func(t *testing.T, table, mode string, ids, vals arrow.Array) {
+		schema := arrow.NewSchema([]arrow.Field{
+			{Name: "id", Type: ids.DataType()},
+			{Name: "v", Type: vals.DataType(), Nullable: true},
+		}, nil)
+		rec := array.NewRecord(schema, []arrow.Array{ids, vals}, int64(ids.Len()))
+		defer rec.Release()
+
+		stmt, err := cnxn.NewStatement()
+		require.NoError(t, err)
+		defer stmt.Close()
+		require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestTargetTable, table))
+		require.NoError(t, stmt.SetOption(adbc.OptionKeyIngestMode, mode))
+		require.NoError(t, stmt.Bind(ctx, rec))
+		_, err = stmt.ExecuteUpdate(ctx)
+		require.NoError(t, err)
+	}
+
+	check := func(t *testing.T, table string, input, expected arrow.Array) {
+		idBldr := array.NewInt64Builder(mem)
+		defer idBldr.Release()
+		for i := 0; i < input.Len(); i++ {
+			idBldr.Append(int64(i))
+		}
+		ids := idBldr.NewArray()
+		defer ids.Release()
+
+		ingest(t, table, adbc.OptionValueIngestModeCreate, ids, input)
+		for i := 0; i < input.Len(); i++ {
+			id, val := array.NewSlice(ids, int64(i), int64(i+1)), array.NewSlice(input, int64(i), int64(i+1))
+			ingest(t, table+"_rows", adbc.OptionValueIngestModeCreateAppend, id, val)
+			id.Release()
+			val.Release()
+		}
+
+		for _, tbl := range []string{table, table + "_rows"} {
+			out := queryAll(t, cnxn, `SELECT "v" FROM `+tbl+` ORDER BY "id"`)
+			assert.Truef(t, array.Equal(expected, out.Column(0)),
+				"%s expected: %s %s\ngot: %s %s", tbl, expected.DataType(), expected, out.Column(0).DataType(), out.Column(0))
+			out.Release()
+		}
+	}
+
+	tsUTC := &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+	tests := []struct {
+		name string
+		dt   arrow.DataType
+		js   string
+		// the type and values read back, if they differ
+		want   arrow.DataType
+		wantJS string
+	}{
+		{"bool", arrow.FixedWidthTypes.Boolean, `[true, null, false]`, nil, ""},
+		{"int8", arrow.PrimitiveTypes.Int8, `[-128, null, 127]`, arrow.PrimitiveTypes.Int64, ""},
+		{"uint8", arrow.PrimitiveTypes.Uint8, `[0, null, 255]`, arrow.PrimitiveTypes.Int64, ""},
+		{"int16", arrow.PrimitiveTypes.Int16, `[-32768, null, 32767]`, arrow.PrimitiveTypes.Int64, ""},
+		{"uint16", arrow.PrimitiveTypes.Uint16, `[0, null, 65535]`, arrow.PrimitiveTypes.Int64, ""},
+		{"int32", arrow.PrimitiveTypes.Int32, `[-2147483648, null, 2147483647]`, arrow.PrimitiveTypes.Int64, ""},
+		{"uint32", arrow.PrimitiveTypes.Uint32, `[0, null, 4294967295]`, arrow.PrimitiveTypes.Int64, ""},
+		{"int64", arrow.PrimitiveTypes.Int64, `[-1099511627776, null, 1099511627776]`, nil, ""},
+		{"uint64", arrow.PrimitiveTypes.Uint64, `[0, null, 1099511627776]`, arrow.PrimitiveTypes.Int64, ""},
+		{"float16", arrow.FixedWidthTypes.Float16, `[1.5, null, -0.25]`, arrow.PrimitiveTypes.Float64, ""},
+		{"float32", arrow.PrimitiveTypes.Float32, `[1.25, null, -3.5e10]`, arrow.PrimitiveTypes.Float64, `[1.25, null, -35000000512]`},
+		{"float64", arrow.PrimitiveTypes.Float64, `[0.1, null, 1.7976931348623157e308]`, nil, ""},
+		// decimals with a scale are read back as doubles
+		{"decimal128", &arrow.Decimal128Type{Precision: 10, Scale: 3}, `["1234567.891", null, "-0.001"]`,
+			arrow.PrimitiveTypes.Float64, `[1234567.891, null, -0.001]`},
+		{"decimal256", &arrow.Decimal256Type{Precision: 38, Scale: 5}, `["1234567.12345", null, "-1.00000"]`,
+			arrow.PrimitiveTypes.Float64, `[1234567.12345, null, -1]`},
+		{"string", arrow.BinaryTypes.String, `["foo", null, ""]`, nil, ""},
+		{"large_string", arrow.BinaryTypes.LargeString, `["foo", null, "bar"]`, arrow.BinaryTypes.String, ""},
+		{"binary", arrow.BinaryTypes.Binary, `["AAEC", null, ""]`, nil, ""},
+		{"large_binary", arrow.BinaryTypes.LargeBinary, `["AAEC", null, "/w=="]`, arrow.BinaryTypes.Binary, ""},
+		{"fixed_size_binary", &arrow.FixedSizeBinaryType{ByteWidth: 3}, `["AAEC", null, "AwQF"]`, arrow.BinaryTypes.Binary, ""},
+		{"date32", arrow.FixedWidthTypes.Date32, `["2023-05-01", null, "1969-12-31"]`, nil, ""},
+		{"date64", arrow.FixedWidthTypes.Date64, `["2023-05-01", null, "1900-01-01"]`, arrow.FixedWidthTypes.Date32, ""},
+		{"time32s", arrow.FixedWidthTypes.Time32s, `["12:34:56", null, "00:00:00"]`, arrow.FixedWidthTypes.Time64ns, ""},
+		{"time32ms", arrow.FixedWidthTypes.Time32ms, `["12:34:56.789", null, "23:59:59.999"]`, arrow.FixedWidthTypes.Time64ns, ""},
+		{"time64us", arrow.FixedWidthTypes.Time64us, `["12:34:56.789012", null, "00:00:00.000001"]`, arrow.FixedWidthTypes.Time64ns, ""},
+		{"time64ns", arrow.FixedWidthTypes.Time64ns, `["12:34:56.789012345", null, "23:59:59.999999999"]`, nil, ""},
+		// timestamps with a timezone are read back in the session
+		// timezone, timezone-naive ones are stored as UTC
+		{"timestamp_s", arrow.FixedWidthTypes.Timestamp_s, `["2023-05-01T12:34:56Z", null, "1960-01-01T00:00:00Z"]`, tsUTC, ""},
+		{"timestamp_us", &arrow.TimestampType{Unit: arrow.Microsecond}, `["2023-05-01T12:34:56.123456Z", null, "1970-01-01T00:00:00Z"]`,
+			&arrow.TimestampType{Unit: arrow.Nanosecond}, ""},
+		{"timestamp_ns_tz", &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "America/New_York"},
+			`["2023-05-01T12:34:56.123456789Z", null, "1999-12-31T23:59:59.999999999Z"]`, tsUTC, ""},
+		// semi-structured values are read back as their JSON text
+		{"list", arrow.ListOf(arrow.PrimitiveTypes.Int32), `[[1, 2, null], null, []]`,
+			arrow.BinaryTypes.String, `["[\n  1,\n  2,\n  null\n]", null, "[]"]`},
+		{"large_list", arrow.LargeListOf(arrow.BinaryTypes.String), `[["a", "b"], null, [null]]`,
+			arrow.BinaryTypes.String, `["[\n  \"a\",\n  \"b\"\n]", null, "[\n  null\n]"]`},
+		{"fixed_size_list", arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Float64), `[[1.5, 2], null, [null, -3]]`,
+			arrow.BinaryTypes.String, `["[\n  1.5,\n  2\n]", null, "[\n  null,\n  -3\n]"]`},
+		{"struct", arrow.StructOf(
+			arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+			arrow.Field{Name: "b", Type: arrow.BinaryTypes.String, Nullable: true}),
+			`[{"a": 1, "b": "foo"}, null, {"a": null, "b": "bar"}]`,
+			arrow.BinaryTypes.String, `["{\n  \"a\": 1,\n  \"b\": \"foo\"\n}", null, "{\n  \"a\": null,\n  \"b\": \"bar\"\n}"]`},
+		{"map", arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32),
+			`[[{"key": "a", "value": 1}, {"key": "b", "value": null}], null, []]`,
+			arrow.BinaryTypes.String, `["{\n  \"a\": 1,\n  \"b\": null\n}", null, "{}"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := fromJSON(t, tt.dt, tt.js)
+			defer input.Release()
+
+			want, wantJS := tt.want, tt.wantJS
+			if want == nil {
+				want = tt.dt
+			}
+			if wantJS == "" {
+				wantJS = tt.js
+			}
+			expected := fromJSON(t, want, wantJS)
+			defer expected.Release()
+
+			check(t, tt.name, input, expected)
+		})
+	}
+
+	t.Run("dictionary", func(t *testing.T) {
+		dict := fromJSON(t, arrow.BinaryTypes.String, `["foo", "bar"]`)
+		defer dict.Release()
+		indices := fromJSON(t, arrow.PrimitiveTypes.Int8, `[1, null, 0, 1]`)
+		defer indices.Release()
+		expected := fromJSON(t, arrow.BinaryTypes.String, `["bar", null, "foo", "bar"]`)
+		defer expected.Release()
+
+		arr := array.NewDictionaryArray(&arrow.DictionaryType{
+			IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}, indices, dict)
+		defer arr.Release()
+
+		check(t, "dictionary", arr, expected)
+	})
+
+	t.Run("run_end_encoded", func(t *testing.T) {
+		runEnds := fromJSON(t, arrow.PrimitiveTypes.Int32, `[2, 3, 5]`)
+		defer runEnds.Release()
+		values := fromJSON(t, arrow.PrimitiveTypes.Int64, `[7, null, 9]`)
+		defer values.Release()
+		expected := fromJSON(t, arrow.PrimitiveTypes.Int64, `[7, 7, null, 9, 9]`)
+		defer expected.Release()
+
+		arr := array.NewRunEndEncodedArray(runEnds, values, 5, 0)
+		defer arr.Release()
+
+		check(t, "run_end_encoded", arr, expected)
+	})
+
+	t.Run("extension", func(t *testing.T) {
+		storage := fromJSON(t, arrow.BinaryTypes.String, `["foo", null]`)
+		defer storage.Release()
+
+		arr := array.NewExtensionArrayWithStorage(
+			&testExtType{ExtensionBase: arrow.ExtensionBase{Storage: arrow.BinaryTypes.String}}, storage)
+		defer arr.Release()
+
+		check(t, "extension", arr, storage)
+	})
+}
+
+func TestStandInTransactions(t *testing.T) {
+	_, db := openStandIn(t)
+	writer, reader := openConn(t, db), openConn(t, db)
+	ctx := context.Background()
+
+	count := func(cnxn adbc.Connection) int64 {
+		rec := queryAll(t, cnxn, `SELECT COUNT(*) FROM txn`)
+		defer rec.Release()
+		return rec.Column(0).(*array.Int64).Value(0)
+	}
+
+	execUpdate(t, writer, `CREATE TABLE txn (id INTEGER)`)
+	require.NoError(t, writer.(adbc.PostInitOptions).SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+
+	execUpdate(t, writer, `INSERT INTO txn VALUES (1), (2)`)
+	assert.EqualValues(t, 2, count(writer))
+	assert.EqualValues(t, 0, count(reader), "uncommitted rows must not be visible to other sessions")
+
+	require.NoError(t, writer.Rollback(ctx))
+	assert.EqualValues(t, 0, count(writer))
+
+	execUpdate(t, writer, `INSERT INTO txn VALUES (3)`)
+	require.NoError(t, writer.Commit(ctx))
+	assert.EqualValues(t, 1, count(reader))
+
+	// turning autocommit back on commits the open transaction
+	execUpdate(t, writer, `INSERT INTO txn VALUES (4)`)
+	assert.EqualValues(t, 1, count(reader))
+	require.NoError(t, writer.(adbc.PostInitOptions).SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueEnabled))
+	assert.EqualValues(t, 2, count(reader))
+}
+
+func TestStandInGetObjects(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+	ctx := context.Background()
+
+	execUpdate(t, cnxn, `CREATE SCHEMA ADBC_TESTING.OTHER`)
+	execUpdate(t, cnxn, `CREATE TABLE ADBC_TESTING.PUBLIC.ALPHA (a INTEGER NOT NULL, b NUMBER(10,2))`)
+	execUpdate(t, cnxn, `CREATE TABLE ADBC_TESTING.OTHER.ALPHA_TOO (c TEXT)`)
+	execUpdate(t, cnxn, `CREATE TABLE ADBC_TESTING.OTHER.BETA (d BOOLEAN)`)
+
+	tables := func(schema, table *string, tableTypes []string) []string {
+		rdr, err := cnxn.GetObjects(ctx, adbc.ObjectDepthTables, nil, schema, table, nil, tableTypes)
+		require.NoError(t, err)
+		defer rdr.Release()
+
+		var out []string
+		for rdr.Next() {
+			rec := rdr.Record()
+			catalogs := rec.Column(0).(*array.String)
+			schemaLists := rec.Column(1).(*array.List)
+			schemas := schemaLists.ListValues().(*array.Struct)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				start, end := schemaLists.ValueOffsets(i)
+				for j := start; j < end; j++ {
+					schemaName := schemas.Field(0).(*array.String).Value(int(j))
+					tableLists := schemas.Field(1).(*array.List)
+					tbls := tableLists.ListValues().(*array.Struct)
+					tstart, tend := tableLists.ValueOffsets(int(j))
+					for k := tstart; k < tend; k++ {
+						out = append(out, fmt.Sprintf("%s.%s.%s", catalogs.Value(i), schemaName,
+							tbls.Field(0).(*array.String).Value(int(k))))
+					}
+				}
+			}
+		}
+		require.NoError(t, rdr.Err())
+		return out
+	}
+
+	all := []string{"ADBC_TESTING.OTHER.ALPHA_TOO", "ADBC_TESTING.OTHER.BETA", "ADBC_TESTING.PUBLIC.ALPHA"}
+	assert.ElementsMatch(t, all, tables(nil, nil, nil))
+
+	pattern := "alpha%"
+	assert.ElementsMatch(t, []string{"ADBC_TESTING.OTHER.ALPHA_TOO", "ADBC_TESTING.PUBLIC.ALPHA"},
+		tables(nil, &pattern, nil), "table names are matched case insensitively")
+
+	schema := "OTHER"
+	assert.ElementsMatch(t, all[:2], tables(&schema, nil, nil))
+	assert.Empty(t, tables(nil, nil, []string{"VIEW"}))
+
+	// the tables of GetObjects use a separate session, so temporary
+	// tables are not visible to it
+	execUpdate(t, cnxn, `CREATE TEMPORARY TABLE TEMPORARY_ONE (a INTEGER)`)
+	assert.ElementsMatch(t, all, tables(nil, nil, nil))
+
+	// CREATE SCHEMA made OTHER the current schema of the session
+	public := "public"
+	sc, err := cnxn.GetTableSchema(ctx, nil, &public, "alpha")
+	require.NoError(t, err)
+	require.Len(t, sc.Fields(), 2)
+	assert.Equal(t, "a", sc.Field(0).Name)
+	assert.False(t, sc.Field(0).Nullable)
+	assert.True(t, sc.Field(1).Nullable)
+
+	// unlike GetObjects, GetTableSchema runs on the connection's session
+	sc, err = cnxn.GetTableSchema(ctx, nil, nil, "temporary_one")
+	require.NoError(t, err)
+	require.Len(t, sc.Fields(), 1)
+	assert.Equal(t, "a", sc.Field(0).Name)
+}
+
+func TestStandInStatistics(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+	ctx := context.Background()
+
+	execUpdate(t, cnxn, `CREATE TABLE stats (id INTEGER, name TEXT, score FLOAT)`)
+	execUpdate(t, cnxn, `INSERT INTO stats VALUES (1, 'a', 1.5), (2, 'a', NULL), (3, NULL, -2.5), (4, 'b', 1.5)`)
+	execUpdate(t, cnxn, `CREATE TABLE other (x INTEGER)`)
+
+	pattern := "ST%"
+	stats := func(tableName *string, approximate bool) map[string]string {
+		rdr, err := cnxn.(adbc.ConnectionGetStatistics).GetStatistics(ctx, nil, nil, tableName, approximate)
+		require.NoError(t, err)
+		defer rdr.Release()
+		assert.True(t, adbc.GetStatisticsSchema.Equal(rdr.Schema()))
+
+		out := make(map[string]string)
+		for rdr.Next() {
+			rec := rdr.Record()
+			catalogs := rec.Column(0).(*array.String)
+			schemaLists := rec.Column(1).(*array.List)
+			schemas := schemaLists.ListValues().(*array.Struct)
+			statLists := schemas.Field(1).(*array.List)
+			items := statLists.ListValues().(*array.Struct)
+			tables := items.Field(0).(*array.String)
+			columns := items.Field(1).(*array.String)
+			keys := items.Field(2).(*array.Int16)
+			values := items.Field(3).(*array.DenseUnion)
+			approx := items.Field(4).(*array.Boolean)
+
+			for i := 0; i < int(rec.NumRows()); i++ {
+				start, end := schemaLists.ValueOffsets(i)
+				for j := start; j < end; j++ {
+					schema := schemas.Field(0).(*array.String).Value(int(j))
+					sStart, sEnd := statLists.ValueOffsets(int(j))
+					for k := int(sStart); k < int(sEnd); k++ {
+						name := catalogs.Value(i) + "." + schema + "." + tables.Value(k)
+						if columns.IsValid(k) {
+							name += "." + columns.Value(k)
+						}
+						name += fmt.Sprintf(" %d", keys.Value(k))
+
+						var val string
+						off := int(values.ValueOffset(k))
+						switch child := values.Field(values.ChildID(k)).(type) {
+						case *array.Int64:
+							val = fmt.Sprint(child.Value(off))
+						case *array.Float64:
+							val = fmt.Sprintf("%.1f", child.Value(off))
+						}
+						if approx.Value(k) {
+							val += " approximate"
+						}
+						out[name] = val
+					}
+				}
+			}
+		}
+		require.NoError(t, rdr.Err())
+		return out
+	}
+
+	const tbl = "ADBC_TESTING.PUBLIC.STATS"
+	exact := stats(&pattern, false)
+	assert.Contains(t, exact, tbl+" 1024")
+	delete(exact, tbl+" 1024")
+	// the distinct counts are approximate regardless
+	assert.Equal(t, map[string]string{
+		tbl + " 6":       "4",
+		tbl + ".ID 5":    "0",
+		tbl + ".ID 1":    "4.0 approximate",
+		tbl + ".ID 4":    "1",
+		tbl + ".ID 3":    "4",
+		tbl + ".NAME 5":  "1",
+		tbl + ".NAME 1":  "2.0 approximate",
+		tbl + ".SCORE 5": "1",
+		tbl + ".SCORE 1": "2.0 approximate",
+		tbl + ".SCORE 4": "-2.5",
+		tbl + ".SCORE 3": "1.5",
+	}, exact)
+
+	// only the table metadata, for all the tables
+	approx := stats(nil, true)
+	assert.Equal(t, "4", approx[tbl+" 6"])
+	assert.Contains(t, approx, tbl+" 1024")
+	assert.Equal(t, "0", approx["ADBC_TESTING.PUBLIC.OTHER 6"])
+	assert.NotContains(t, approx, tbl+".NAME 1")
+
+	// exact statistics of all the tables would scan them all
+	var adbcErr adbc.Error
+	_, err := cnxn.(adbc.ConnectionGetStatistics).GetStatistics(ctx, nil, nil, nil, false)
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+
+	rdr, err := cnxn.(adbc.ConnectionGetStatistics).GetStatisticNames(ctx)
+	require.NoError(t, err)
+	defer rdr.Release()
+	require.True(t, rdr.Next())
+	assert.Equal(t, driver.StatisticTableBytesName, rdr.Record().Column(0).(*array.String).Value(0))
+	assert.EqualValues(t, driver.StatisticTableBytesKey, rdr.Record().Column(1).(*array.Int16).Value(0))
+}
+
+func TestStandInChunkedResults(t *testing.T) {
+	srv, db := openStandIn(t)
+	cnxn := openConn(t, db)
+
+	execUpdate(t, cnxn, `CREATE TABLE chunked (id INTEGER, name TEXT)`)
+	values := make([]string, 35)
+	for i := range values {
+		values[i] = fmt.Sprintf("(%d, 'row %d')", i*1000, i)
+	}
+	assert.EqualValues(t, 35, execUpdate(t, cnxn, `INSERT INTO chunked VALUES `+strings.Join(values, ", ")))
+
+	srv.SetRowsPerChunk(10)
+	rec := queryAll(t, cnxn, `SELECT * FROM chunked ORDER BY id DESC`)
+	defer rec.Release()
+
+	require.EqualValues(t, 35, rec.NumRows())
+	ids := rec.Column(0).(*array.Int64)
+	for i := 0; i < ids.Len(); i++ {
+		assert.EqualValues(t, (34-i)*1000, ids.Value(i))
+	}
+	assert.Equal(t, "row 0", rec.Column(1).(*array.String).Value(34))
+	assert.Equal(t, 3, srv.Stats().ChunkDownloads)
+}
+
+func TestStandInCancel(t *testing.T) {
+	srv, db := openStandIn(t)
+	cnxn := openConn(t, db)
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	require.NoError(t, stmt.SetSqlQuery(`SELECT SYSTEM$WAIT(10)`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err = stmt.ExecuteQuery(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Eventually(t, func() bool { return srv.Stats().Aborts == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStandInSessions(t *testing.T) {
+	srv, db := openStandIn(t)
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+
+	execUpdate(t, cnxn, `SELECT 1`)
+	srv.ExpireTokens()
+	execUpdate(t, cnxn, `SELECT 1`)
+	assert.Equal(t, 1, srv.Stats().TokenRenewals)
+
+	require.NoError(t, cnxn.Close())
+	assert.Eventually(t, func() bool { return srv.Stats().OpenSessions == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStandInOpenURI(t *testing.T) {
+	srv := standin.NewServer("ADBC_TESTING")
+	t.Cleanup(srv.Close)
+
+	db, err := adbc.Open(context.Background(), "snowflake://"+srv.DSN(), nil)
+	require.NoError(t, err)
+	cnxn := openConn(t, db)
+	assert.EqualValues(t, -1, execUpdate(t, cnxn, `SELECT 1`))
+}
+
+func TestStandInCredentialOptions(t *testing.T) {
+	srv := standin.NewServer("ADBC_TESTING")
+	t.Cleanup(srv.Close)
+	dsn := strings.TrimPrefix(srv.DSN(), standin.User+":"+standin.Password+"@")
+
+	db, err := driver.Driver{}.NewDatabase(map[string]string{
+		adbc.OptionKeyURI:      dsn,
+		adbc.OptionKeyUsername: standin.User,
+		adbc.OptionKeyPassword: standin.Password,
+	})
+	require.NoError(t, err)
+	cnxn := openConn(t, db)
+	assert.EqualValues(t, -1, execUpdate(t, cnxn, `SELECT 1`))
+
+	// the password is not parsed as part of the DSN
+	db, err = driver.Driver{}.NewDatabase(map[string]string{
+		adbc.OptionKeyURI:      standin.User + "@" + dsn,
+		adbc.OptionKeyPassword: "p@ss:w/ord?",
+	})
+	require.NoError(t, err)
+	_, err = db.Open(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, srv.Stats().FailedLogins)
+
+	_, err = driver.Driver{}.NewDatabase(map[string]string{adbc.OptionKeyURI: dsn})
+	var adbcErr adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func TestStandInDatabaseSQL(t *testing.T) {
+	srv := standin.NewServer("ADBC_TESTING")
+	defer srv.Close()
+
+	db, err := sql.Open(sqldriver.DriverNamePrefix+"snowflake", "snowflake://"+srv.DSN())
+	require.NoError(t, err)
+	defer db.Close()
+
+	var v int64
+	require.NoError(t, db.QueryRow(`SELECT 42`).Scan(&v))
+	assert.EqualValues(t, 42, v)
+}
+
+func TestStandInErrorDetails(t *testing.T) {
+	_, db := openStandIn(t)
+	cnxn := openConn(t, db)
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	require.NoError(t, stmt.SetSqlQuery(`SELECT * FROM missing`))
+	_, _, err = stmt.ExecuteQuery(context.Background())
+
+	var adbcErr adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.EqualValues(t, 2003, adbcErr.VendorCode)
+	assert.Equal(t, "42S02", string(adbcErr.SqlState[:]))
+	require.Len(t, adbcErr.Details(), 1)
+	assert.Equal(t, driver.ErrorDetailQueryID, adbcErr.Details()[0].Key)
+
+	// the error of gosnowflake is reachable through the chain of errors
+	var sferr *gosnowflake.SnowflakeError
+	require.ErrorAs(t, err, &sferr)
+	assert.Equal(t, sferr.QueryID, string(adbcErr.Details()[0].Value))
+	assert.NotEmpty(t, sferr.QueryID)
+}
+
+func TestStandInLoginErrors(t *testing.T) {
+	srv := standin.NewServer("ADBC_TESTING")
+	defer srv.Close()
+
+	for _, dsn := range []string{
+		strings.Replace(srv.DSN(), ":"+standin.Password+"@", ":wrong@", 1),
+		strings.Replace(srv.DSN(), "/ADBC_TESTING/", "/MISSING/", 1),
+	} {
+		db, err := sql.Open("snowflake", dsn)
+		require.NoError(t, err)
+		assert.Error(t, db.Ping(), dsn)
+		db.Close()
+	}
+	assert.Equal(t, 2, srv.Stats().FailedLogins)
+}
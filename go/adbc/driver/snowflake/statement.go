@@ -20,13 +20,18 @@ package snowflake
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/apache/arrow-adbc/go/adbc"
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/compute"
+	"github.com/apache/arrow/go/v12/arrow/encoded"
 	"github.com/apache/arrow/go/v12/arrow/memory"
 	"github.com/snowflakedb/gosnowflake"
 	"golang.org/x/exp/constraints"
@@ -34,6 +39,15 @@ import (
 
 const (
 	OptionStatementQueueSize = "adbc.rpc.result_queue_size"
+	// When enabled, appending to an existing table (with the append,
+	// create_append or merge ingest modes) first alters the table to
+	// add new nullable columns and safely widen column types to match
+	// the bound data. Incompatible changes fail the ingestion.
+	OptionStatementIngestSchemaEvolution = "adbc.snowflake.statement.ingest_schema_evolution"
+	// When enabled, the names of the ingestion target table, schema
+	// and catalog are quoted, so that they are case-sensitive, rather
+	// than uppercased by snowflake as unquoted identifiers are.
+	OptionStatementIngestCaseSensitive = "adbc.snowflake.statement.ingest_case_sensitive"
 )
 
 type statement struct {
@@ -41,9 +55,15 @@ type statement struct {
 	alloc     memory.Allocator
 	queueSize int
 
-	query       string
-	targetTable string
-	append      bool
+	query           string
+	targetTable     string
+	targetCatalog   string
+	targetSchema    string
+	ingestMode      string
+	keyColumns      []string
+	temporary       bool
+	schemaEvolution bool
+	caseSensitive   bool
 
 	bound      arrow.Record
 	streamBind array.RecordReader
@@ -60,13 +80,7 @@ func (st *statement) Close() error {
 			Code: adbc.StatusInvalidState}
 	}
 
-	if st.bound != nil {
-		st.bound.Release()
-		st.bound = nil
-	} else if st.streamBind != nil {
-		st.streamBind.Release()
-		st.streamBind = nil
-	}
+	st.releaseBound()
 	st.cnxn = nil
 	return nil
 }
@@ -77,12 +91,61 @@ func (st *statement) SetOption(key string, val string) error {
 	case adbc.OptionKeyIngestTargetTable:
 		st.query = ""
 		st.targetTable = val
+	case adbc.OptionKeyIngestTargetCatalog:
+		st.targetCatalog = val
+	case adbc.OptionKeyIngestTargetDBSchema:
+		st.targetSchema = val
+	case adbc.OptionKeyIngestKeyColumns:
+		st.keyColumns = nil
+		for _, k := range strings.Split(val, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				st.keyColumns = append(st.keyColumns, k)
+			}
+		}
+	case adbc.OptionKeyIngestTemporary:
+		switch val {
+		case adbc.OptionValueEnabled:
+			st.temporary = true
+		case adbc.OptionValueDisabled:
+			st.temporary = false
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
 	case adbc.OptionKeyIngestMode:
 		switch val {
-		case adbc.OptionValueIngestModeAppend:
-			st.append = true
-		case adbc.OptionValueIngestModeCreate:
-			st.append = false
+		case adbc.OptionValueIngestModeAppend,
+			adbc.OptionValueIngestModeCreate,
+			adbc.OptionValueIngestModeReplace,
+			adbc.OptionValueIngestModeCreateAppend,
+			adbc.OptionValueIngestModeMerge:
+			st.ingestMode = val
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case OptionStatementIngestSchemaEvolution:
+		switch val {
+		case adbc.OptionValueEnabled:
+			st.schemaEvolution = true
+		case adbc.OptionValueDisabled:
+			st.schemaEvolution = false
+		default:
+			return adbc.Error{
+				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+	case OptionStatementIngestCaseSensitive:
+		switch val {
+		case adbc.OptionValueEnabled:
+			st.caseSensitive = true
+		case adbc.OptionValueDisabled:
+			st.caseSensitive = false
 		default:
 			return adbc.Error{
 				Msg:  fmt.Sprintf("invalid statement option %s=%s", key, val),
@@ -167,176 +230,451 @@ func toSnowflakeType(dt arrow.DataType) string {
 	return ""
 }
 
-func (st *statement) initIngest(ctx context.Context) (string, error) {
-	var (
-		createBldr, insertBldr strings.Builder
-	)
+// quoteIdentifier quotes an identifier so that it is taken literally,
+// preserving its case and allowing any characters in it.
+func quoteIdentifier(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
 
-	createBldr.WriteString("CREATE TABLE ")
-	createBldr.WriteString(st.targetTable)
-	createBldr.WriteString(" (")
+// targetIdentifier returns a part of the name of the ingestion target
+// as it is used in queries: as given, so that snowflake resolves it as
+// any unquoted identifier, or quoted if the names are case-sensitive.
+func (st *statement) targetIdentifier(ident string) string {
+	if st.caseSensitive {
+		return quoteIdentifier(ident)
+	}
+	return ident
+}
 
-	insertBldr.WriteString("INSERT INTO ")
-	insertBldr.WriteString(st.targetTable)
-	insertBldr.WriteString(" VALUES (")
+// qualifiedTargetTable returns the name of the ingestion target
+// qualified by the target catalog and schema if they were set.
+func (st *statement) qualifiedTargetTable() string {
+	return st.qualifyTarget(st.targetIdentifier(st.targetTable))
+}
 
-	var schema *arrow.Schema
+// qualifyTarget qualifies the name of a table by the target catalog
+// and schema if they were set, so that it is next to the target.
+func (st *statement) qualifyTarget(table string) string {
+	parts := make([]string, 0, 3)
+	if st.targetCatalog != "" {
+		parts = append(parts, st.targetIdentifier(st.targetCatalog))
+		if st.targetSchema == "" {
+			// snowflake requires a schema when the database is given,
+			// an empty one means the default PUBLIC schema
+			parts = append(parts, "")
+		}
+	}
+	if st.targetSchema != "" {
+		parts = append(parts, st.targetIdentifier(st.targetSchema))
+	}
+	return strings.Join(append(parts, table), ".")
+}
+
+func (st *statement) boundSchema() *arrow.Schema {
 	if st.bound != nil {
-		schema = st.bound.Schema()
-	} else {
-		schema = st.streamBind.Schema()
+		return st.bound.Schema()
+	}
+	return st.streamBind.Schema()
+}
+
+// ingestQueries returns the column definitions for a table matching
+// the schema along with the query to insert bound values into target.
+// If namedColumns is true, the insert lists the columns by name rather
+// than relying on their position in the table.
+func ingestQueries(target string, schema *arrow.Schema, namedColumns bool) (colDefs, insertQuery string, err error) {
+	var (
+		colBldr, insertBldr strings.Builder
+		selectExprs         []string
+		semiStructured      bool
+	)
+
+	insertBldr.WriteString("INSERT INTO ")
+	insertBldr.WriteString(target)
+	if namedColumns {
+		names := make([]string, len(schema.Fields()))
+		for i, f := range schema.Fields() {
+			names[i] = quoteIdentifier(f.Name)
+		}
+		insertBldr.WriteString(" (")
+		insertBldr.WriteString(strings.Join(names, ", "))
+		insertBldr.WriteString(")")
 	}
 
 	for i, f := range schema.Fields() {
 		if i != 0 {
-			insertBldr.WriteString(", ")
-			createBldr.WriteString(", ")
+			colBldr.WriteString(", ")
 		}
 
-		createBldr.WriteString(strconv.Quote(f.Name))
-		createBldr.WriteString(" ")
+		colBldr.WriteString(quoteIdentifier(f.Name))
+		colBldr.WriteString(" ")
 		ty := toSnowflakeType(f.Type)
 		if ty == "" {
-			return "", adbc.Error{
+			return "", "", adbc.Error{
 				Msg:  fmt.Sprintf("unimplemented type conversion for field %s, arrow type: %s", f.Name, f.Type),
 				Code: adbc.StatusNotImplemented,
 			}
 		}
 
-		createBldr.WriteString(ty)
+		colBldr.WriteString(ty)
 		if !f.Nullable {
-			createBldr.WriteString(" NOT NULL")
+			colBldr.WriteString(" NOT NULL")
 		}
 
-		insertBldr.WriteString("?")
+		// semi-structured values are bound as JSON strings, which
+		// snowflake will only convert via PARSE_JSON in a SELECT
+		col := "column" + strconv.Itoa(i+1)
+		switch ty {
+		case "array":
+			semiStructured = true
+			col = "TO_ARRAY(PARSE_JSON(" + col + "))"
+		case "object":
+			semiStructured = true
+			col = "TO_OBJECT(PARSE_JSON(" + col + "))"
+		case "variant":
+			semiStructured = true
+			col = "PARSE_JSON(" + col + ")"
+		}
+		selectExprs = append(selectExprs, col)
 	}
 
-	createBldr.WriteString(")")
-	insertBldr.WriteString(")")
-
-	if !st.append {
-		// create the table!
-		createQuery := createBldr.String()
-		_, err := st.cnxn.cn.ExecContext(ctx, createQuery, nil)
-		if err != nil {
-			return "", errToAdbcErr(adbc.StatusInternal, err)
-		}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(selectExprs)), ", ")
+	if semiStructured {
+		insertBldr.WriteString(" SELECT ")
+		insertBldr.WriteString(strings.Join(selectExprs, ", "))
+		insertBldr.WriteString(" FROM VALUES (")
+	} else {
+		insertBldr.WriteString(" VALUES (")
 	}
+	insertBldr.WriteString(placeholders)
+	insertBldr.WriteString(")")
 
-	return insertBldr.String(), nil
+	return colBldr.String(), insertBldr.String(), nil
 }
 
-type nativeArrowArr[T string | []byte] interface {
-	arrow.Array
-	Value(int) T
-}
+func (st *statement) initIngest(ctx context.Context) (string, error) {
+	target := st.qualifiedTargetTable()
+	colDefs, insertQuery, err := ingestQueries(target, st.boundSchema(), st.schemaEvolution)
+	if err != nil {
+		return "", err
+	}
 
-func convToArr[T string | []byte](arr nativeArrowArr[T]) interface{} {
-	if arr.Len() == 1 {
-		if arr.IsNull(0) {
-			return nil
+	if st.ingestMode == adbc.OptionValueIngestModeAppend {
+		if st.schemaEvolution {
+			if err := st.evolveSchema(ctx, target); err != nil {
+				return "", err
+			}
 		}
+		return insertQuery, nil
+	}
 
-		return arr.Value(0)
+	var createBldr strings.Builder
+	createBldr.WriteString("CREATE ")
+	if st.ingestMode == adbc.OptionValueIngestModeReplace {
+		createBldr.WriteString("OR REPLACE ")
+	}
+	if st.temporary {
+		createBldr.WriteString("TEMPORARY ")
+	}
+	createBldr.WriteString("TABLE ")
+	switch st.ingestMode {
+	case adbc.OptionValueIngestModeCreateAppend, adbc.OptionValueIngestModeMerge:
+		createBldr.WriteString("IF NOT EXISTS ")
 	}
+	createBldr.WriteString(target)
+	createBldr.WriteString(" (")
+	createBldr.WriteString(colDefs)
+	createBldr.WriteString(")")
 
-	v := make([]interface{}, arr.Len())
-	for i := 0; i < arr.Len(); i++ {
-		if arr.IsNull(i) {
-			continue
-		}
-		v[i] = arr.Value(i)
+	// create the table!
+	if _, err := st.cnxn.cn.ExecContext(ctx, createBldr.String(), nil); err != nil {
+		return "", errToAdbcErr(adbc.StatusInternal, err)
 	}
-	return gosnowflake.Array(&v)
-}
 
-func convMarshal(arr arrow.Array) interface{} {
-	if arr.Len() == 0 {
-		if arr.IsNull(0) {
-			return nil
+	switch st.ingestMode {
+	case adbc.OptionValueIngestModeCreateAppend, adbc.OptionValueIngestModeMerge:
+		// the table may have already existed
+		if st.schemaEvolution {
+			if err := st.evolveSchema(ctx, target); err != nil {
+				return "", err
+			}
 		}
-		return arr.ValueStr(0)
 	}
 
-	v := make([]interface{}, arr.Len())
-	for i := 0; i < arr.Len(); i++ {
+	return insertQuery, nil
+}
+
+// bindKind tells the snowflake driver how to interpret the time.Time
+// values in an array binding, it is ignored for all other types.
+type bindKind int8
+
+const (
+	bindDefault bindKind = iota
+	bindDate
+	bindTime
+	bindTimestampTZ
+	bindTimestampLTZ
+)
+
+type nativeArrowArr[T string | []byte | bool] interface {
+	arrow.Array
+	Value(int) T
+}
+
+func convNative[T string | []byte | bool](arr nativeArrowArr[T]) []interface{} {
+	out := make([]interface{}, arr.Len())
+	for i := range out {
 		if arr.IsNull(i) {
 			continue
 		}
-		v[i] = arr.ValueStr(i)
+		out[i] = arr.Value(i)
 	}
-	return gosnowflake.Array(&v)
+	return out
 }
 
 // snowflake driver bindings only support specific types
 // int/int32/int64/float64/float32/bool/string/byte/time
 // so we have to cast anything else appropriately
-func convToSlice[T, O constraints.Integer | constraints.Float](arr arrow.Array, vals []T) interface{} {
-	if arr.Len() == 1 {
-		if arr.IsNull(0) {
-			return nil
+func convToSlice[T, O constraints.Integer | constraints.Float](arr arrow.Array, vals []T) []interface{} {
+	out := make([]interface{}, arr.Len())
+	for i, v := range vals {
+		if arr.IsNull(i) {
+			continue
 		}
+		out[i] = O(v)
+	}
+	return out
+}
 
-		return vals[0]
+func convWith(arr arrow.Array, fn func(int) interface{}) []interface{} {
+	out := make([]interface{}, arr.Len())
+	for i := range out {
+		if arr.IsNull(i) {
+			continue
+		}
+		out[i] = fn(i)
 	}
+	return out
+}
 
+// convJSON marshals each element to a JSON string so that semi-structured
+// columns (ARRAY, OBJECT and VARIANT) can be loaded with PARSE_JSON.
+func convJSON(arr arrow.Array) ([]interface{}, error) {
+	m, isMap := arr.(*array.Map)
 	out := make([]interface{}, arr.Len())
-	for i, v := range vals {
+	for i := range out {
 		if arr.IsNull(i) {
 			continue
 		}
-		out[i] = O(v)
+		var v interface{}
+		if isMap {
+			v = mapObject(m, i)
+		} else {
+			v = arr.GetOneForMarshal(i)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, adbc.Error{
+				Msg:  fmt.Sprintf("could not convert %s value to JSON: %s", arr.DataType(), err),
+				Code: adbc.StatusInternal,
+			}
+		}
+		out[i] = string(b)
+	}
+	return out, nil
+}
+
+// mapObject returns a value of a map as an object keyed by the string
+// form of its keys, rather than as the list of key-value pairs arrow
+// marshals it to, so that it can be stored in an OBJECT column.
+func mapObject(arr *array.Map, i int) map[string]interface{} {
+	keys, items := arr.Keys(), arr.Items()
+	start, end := arr.ValueOffsets(i)
+	obj := make(map[string]interface{}, end-start)
+	for j := int(start); j < int(end); j++ {
+		obj[keys.ValueStr(j)] = items.GetOneForMarshal(j)
+	}
+	return obj
+}
+
+// decodeREE expands a run-end encoded array into its logical values.
+func decodeREE(ctx context.Context, arr *array.RunEndEncoded) (arrow.Array, error) {
+	bldr := array.NewInt64Builder(compute.GetAllocator(ctx))
+	defer bldr.Release()
+	bldr.Reserve(arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		bldr.UnsafeAppend(int64(encoded.FindPhysicalIndex(arr.Data(), i+arr.Data().Offset())))
 	}
-	return gosnowflake.Array(&out)
+	indices := bldr.NewArray()
+	defer indices.Release()
+	return compute.TakeArray(ctx, arr.Values(), indices)
 }
 
-func getQueryArg(arr arrow.Array) interface{} {
+// queryArgValues converts a column into a slice of values the snowflake
+// driver knows how to bind, leaving nil in the slots which are null.
+func queryArgValues(ctx context.Context, arr arrow.Array) ([]interface{}, bindKind, error) {
 	switch arr := arr.(type) {
+	case *array.Null:
+		return make([]interface{}, arr.Len()), bindDefault, nil
+	case *array.Boolean:
+		return convNative[bool](arr), bindDefault, nil
 	case *array.Int8:
-		v := arr.Int8Values()
-		return convToSlice[int8, int32](arr, v)
+		return convToSlice[int8, int32](arr, arr.Int8Values()), bindDefault, nil
 	case *array.Uint8:
-		v := arr.Uint8Values()
-		return convToSlice[uint8, int32](arr, v)
+		return convToSlice[uint8, int32](arr, arr.Uint8Values()), bindDefault, nil
 	case *array.Int16:
-		v := arr.Int16Values()
-		return convToSlice[int16, int32](arr, v)
+		return convToSlice[int16, int32](arr, arr.Int16Values()), bindDefault, nil
 	case *array.Uint16:
-		v := arr.Uint16Values()
-		return convToSlice[uint16, int32](arr, v)
+		return convToSlice[uint16, int32](arr, arr.Uint16Values()), bindDefault, nil
 	case *array.Int32:
-		v := arr.Int32Values()
-		return convToSlice[int32, int32](arr, v)
+		return convToSlice[int32, int32](arr, arr.Int32Values()), bindDefault, nil
 	case *array.Uint32:
-		v := arr.Uint32Values()
-		return convToSlice[uint32, int64](arr, v)
+		return convToSlice[uint32, int64](arr, arr.Uint32Values()), bindDefault, nil
 	case *array.Int64:
-		v := arr.Int64Values()
-		return convToSlice[int64, int64](arr, v)
+		return convToSlice[int64, int64](arr, arr.Int64Values()), bindDefault, nil
 	case *array.Uint64:
-		v := arr.Uint64Values()
-		return convToSlice[uint64, int64](arr, v)
+		// values above math.MaxInt64 don't fit in any type the driver
+		// binds natively, so let snowflake parse the number instead
+		return convWith(arr, func(i int) interface{} {
+			return strconv.FormatUint(arr.Value(i), 10)
+		}), bindDefault, nil
+	case *array.Float16:
+		return convWith(arr, func(i int) interface{} {
+			return float64(arr.Value(i).Float32())
+		}), bindDefault, nil
 	case *array.Float32:
-		v := arr.Float32Values()
-		return convToSlice[float32, float64](arr, v)
+		return convToSlice[float32, float64](arr, arr.Float32Values()), bindDefault, nil
 	case *array.Float64:
-		v := arr.Float64Values()
-		return convToSlice[float64, float64](arr, v)
+		return convToSlice[float64, float64](arr, arr.Float64Values()), bindDefault, nil
+	case *array.Decimal128:
+		scale := arr.DataType().(*arrow.Decimal128Type).Scale
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToString(scale)
+		}), bindDefault, nil
+	case *array.Decimal256:
+		scale := arr.DataType().(*arrow.Decimal256Type).Scale
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToString(scale)
+		}), bindDefault, nil
 	case *array.LargeBinary:
-		return convToArr[[]byte](arr)
+		return convNative[[]byte](arr), bindDefault, nil
 	case *array.Binary:
-		return convToArr[[]byte](arr)
+		return convNative[[]byte](arr), bindDefault, nil
+	case *array.FixedSizeBinary:
+		return convNative[[]byte](arr), bindDefault, nil
 	case *array.LargeString:
-		return convToArr[string](arr)
+		return convNative[string](arr), bindDefault, nil
 	case *array.String:
-		return convToArr[string](arr)
-	default:
-		// default convert to array of strings and pass to snowflake driver
-		// not the most efficient, but snowflake doesn't really give a better
-		// route currently short of writing everything out to a Parquet file
-		// and then uploading that (which might be preferable)
-		return convMarshal(arr)
+		return convNative[string](arr), bindDefault, nil
+	case *array.Date32:
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToTime()
+		}), bindDate, nil
+	case *array.Date64:
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToTime()
+		}), bindDate, nil
+	case *array.Time32:
+		unit := arr.DataType().(*arrow.Time32Type).Unit
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToTime(unit)
+		}), bindTime, nil
+	case *array.Time64:
+		unit := arr.DataType().(*arrow.Time64Type).Unit
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToTime(unit)
+		}), bindTime, nil
+	case *array.Timestamp:
+		// matches toSnowflakeType: timezone-naive values go into a
+		// TIMESTAMP_TZ column as UTC, while values with a timezone
+		// are instants and go into TIMESTAMP_LTZ.
+		dt := arr.DataType().(*arrow.TimestampType)
+		kind := bindTimestampTZ
+		if dt.TimeZone != "" {
+			kind = bindTimestampLTZ
+		}
+		return convWith(arr, func(i int) interface{} {
+			return arr.Value(i).ToTime(dt.Unit)
+		}), kind, nil
+	case *array.Dictionary:
+		values, err := compute.TakeArray(ctx, arr.Dictionary(), arr.Indices())
+		if err != nil {
+			return nil, bindDefault, errToAdbcErr(adbc.StatusInternal, err)
+		}
+		defer values.Release()
+		return queryArgValues(ctx, values)
+	case *array.RunEndEncoded:
+		values, err := decodeREE(ctx, arr)
+		if err != nil {
+			return nil, bindDefault, errToAdbcErr(adbc.StatusInternal, err)
+		}
+		defer values.Release()
+		return queryArgValues(ctx, values)
+	case array.ExtensionArray:
+		return queryArgValues(ctx, arr.Storage())
+	case *array.List, *array.LargeList, *array.FixedSizeList,
+		*array.Struct, *array.Map, array.Union:
+		out, err := convJSON(arr)
+		return out, bindDefault, err
 	}
+
+	return nil, bindDefault, adbc.Error{
+		Msg:  fmt.Sprintf("unimplemented bind parameter conversion for arrow type: %s", arr.DataType()),
+		Code: adbc.StatusNotImplemented,
+	}
+}
+
+// toScalarArg converts a single value produced by queryArgValues into
+// one the snowflake driver accepts outside of an array binding, which
+// only allows int64, float64, bool, string, []byte and time.Time.
+func toScalarArg(v interface{}, kind bindKind) interface{} {
+	switch v := v.(type) {
+	case int32:
+		return int64(v)
+	case []byte:
+		// the driver only hex encodes a single []byte if it follows
+		// the DataTypeBinary marker, so send the hex encoded text,
+		// which snowflake converts to binary.
+		return hex.EncodeToString(v)
+	case float64:
+		// the driver formats a bound float64 with 32-bit precision,
+		// so format it ourselves to avoid losing precision
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		// binding a single time.Time would be sent as TIMESTAMP_NTZ,
+		// so pass a string that snowflake can cast to the column type.
+		switch kind {
+		case bindDate:
+			return v.Format("2006-01-02")
+		case bindTime:
+			return v.Format("15:04:05.999999999")
+		default:
+			return v.Format("2006-01-02 15:04:05.999999999 -07:00")
+		}
+	}
+	return v
+}
+
+func getQueryArg(ctx context.Context, arr arrow.Array) (interface{}, error) {
+	vals, kind, err := queryArgValues(ctx, arr)
+	if err != nil {
+		return nil, err
+	}
+
+	if arr.Len() == 1 {
+		return toScalarArg(vals[0], kind), nil
+	}
+
+	switch kind {
+	case bindDate:
+		return gosnowflake.Array(&vals, gosnowflake.DateType), nil
+	case bindTime:
+		return gosnowflake.Array(&vals, gosnowflake.TimeType), nil
+	case bindTimestampTZ:
+		return gosnowflake.Array(&vals, gosnowflake.TimestampTZType), nil
+	case bindTimestampLTZ:
+		return gosnowflake.Array(&vals, gosnowflake.TimestampLTZType), nil
+	}
+	return gosnowflake.Array(&vals), nil
 }
 
 func (st *statement) executeIngest(ctx context.Context) (int64, error) {
@@ -347,11 +685,38 @@ func (st *statement) executeIngest(ctx context.Context) (int64, error) {
 		}
 	}
 
+	if st.ingestMode == adbc.OptionValueIngestModeMerge {
+		res, err := st.executeMerge(ctx)
+		if err != nil {
+			return -1, err
+		}
+		return res.inserted + res.updated, nil
+	}
+
 	insertQuery, err := st.initIngest(ctx)
 	if err != nil {
 		return -1, err
 	}
 
+	return st.insertBound(ctx, insertQuery, nil)
+}
+
+// releaseBound releases the bound parameters, if any.
+func (st *statement) releaseBound() {
+	if st.bound != nil {
+		st.bound.Release()
+		st.bound = nil
+	} else if st.streamBind != nil {
+		st.streamBind.Release()
+		st.streamBind = nil
+	}
+}
+
+// insertBound executes insertQuery with each batch of the bound
+// parameters, releasing them once done. If check is not nil, each
+// batch is passed to it before being inserted and an error stops the
+// insertion.
+func (st *statement) insertBound(ctx context.Context, insertQuery string, check func(arrow.Record) error) (int64, error) {
 	// if the ingestion is large enough it might make more sense to
 	// write this out to a temporary file / stage / etc. and use
 	// the snowflake bulk loader that way.
@@ -362,9 +727,23 @@ func (st *statement) executeIngest(ctx context.Context) (int64, error) {
 
 	var n int64
 	exec := func(rec arrow.Record, args []driver.NamedValue) error {
+		if rec.NumRows() == 0 {
+			return nil
+		}
+		if check != nil {
+			if err := check(rec); err != nil {
+				return err
+			}
+		}
+
+		argCtx := compute.WithAllocator(ctx, st.alloc)
 		for i, c := range rec.Columns() {
+			v, err := getQueryArg(argCtx, c)
+			if err != nil {
+				return err
+			}
 			args[i].Ordinal = i
-			args[i].Value = getQueryArg(c)
+			args[i].Value = v
 		}
 
 		r, err := st.cnxn.cn.ExecContext(ctx, insertQuery, args)
@@ -403,6 +782,213 @@ func (st *statement) executeIngest(ctx context.Context) (int64, error) {
 	return n, nil
 }
 
+type mergeResult struct {
+	inserted, updated int64
+}
+
+var mergeResultSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "rows_inserted", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "rows_updated", Type: arrow.PrimitiveTypes.Int64},
+}, nil)
+
+func (m mergeResult) toReader(alloc memory.Allocator) (array.RecordReader, error) {
+	bldr := array.NewRecordBuilder(alloc, mergeResultSchema)
+	defer bldr.Release()
+
+	bldr.Field(0).(*array.Int64Builder).Append(m.inserted)
+	bldr.Field(1).(*array.Int64Builder).Append(m.updated)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	return array.NewRecordReader(mergeResultSchema, []arrow.Record{rec})
+}
+
+// mergeQuery returns the MERGE statement of the staging table into the
+// target, and whether it updates the rows matched, which it does when
+// there are columns other than the keys.
+func mergeQuery(target, stage string, schema *arrow.Schema, keys []string) (string, bool) {
+	var (
+		on, set, cols, vals []string
+		isKey               = make(map[string]bool)
+	)
+
+	for _, k := range keys {
+		isKey[k] = true
+		on = append(on, "t."+quoteIdentifier(k)+" = s."+quoteIdentifier(k))
+	}
+
+	for _, f := range schema.Fields() {
+		col := quoteIdentifier(f.Name)
+		cols = append(cols, col)
+		vals = append(vals, "s."+col)
+		if !isKey[f.Name] {
+			set = append(set, "t."+col+" = s."+col)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("MERGE INTO ")
+	b.WriteString(target)
+	b.WriteString(" AS t USING ")
+	b.WriteString(stage)
+	b.WriteString(" AS s ON ")
+	b.WriteString(strings.Join(on, " AND "))
+	if len(set) > 0 {
+		b.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		b.WriteString(strings.Join(set, ", "))
+	}
+	b.WriteString(" WHEN NOT MATCHED THEN INSERT (")
+	b.WriteString(strings.Join(cols, ", "))
+	b.WriteString(") VALUES (")
+	b.WriteString(strings.Join(vals, ", "))
+	b.WriteString(")")
+	return b.String(), len(set) > 0
+}
+
+func toInt64(v driver.Value) (int64, error) {
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case []byte:
+		return strconv.ParseInt(string(v), 10, 64)
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected value %v (%T) for row count", v, v)
+}
+
+// executeMerge loads the bound data into a temporary staging table and
+// then merges it into the target using the key columns. The bound data
+// is released even if the merge fails. The staging table is dropped
+// afterwards, unless the connection is lost or closed in the middle of
+// the merge: being temporary, it is then only dropped by Snowflake at
+// the end of the session.
+func (st *statement) executeMerge(ctx context.Context) (res mergeResult, err error) {
+	defer st.releaseBound()
+
+	schema := st.boundSchema()
+	if len(st.keyColumns) == 0 {
+		return res, adbc.Error{
+			Msg:  fmt.Sprintf("ingest mode %s requires option %s", adbc.OptionValueIngestModeMerge, adbc.OptionKeyIngestKeyColumns),
+			Code: adbc.StatusInvalidState,
+		}
+	}
+	keyIdx := make([]int, len(st.keyColumns))
+	for i, k := range st.keyColumns {
+		idx := schema.FieldIndices(k)
+		if len(idx) == 0 {
+			return res, adbc.Error{
+				Msg:  fmt.Sprintf("key column '%s' not found in bound schema: %s", k, schema),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		keyIdx[i] = idx[0]
+	}
+
+	// rows sharing a key would be inserted twice or update the same
+	// row of the target, so they are rejected. rows with a null key
+	// never match and are always inserted.
+	seen := make(map[string]struct{})
+	checkKeys := func(rec arrow.Record) error {
+		key := make([]string, len(keyIdx))
+	rows:
+		for r := 0; r < int(rec.NumRows()); r++ {
+			for i, c := range keyIdx {
+				if rec.Column(c).IsNull(r) {
+					continue rows
+				}
+				key[i] = rec.Column(c).ValueStr(r)
+			}
+			k := strings.Join(key, "\x00")
+			if _, dup := seen[k]; dup {
+				return adbc.Error{
+					Msg: fmt.Sprintf("bound data has more than one row with key (%s) = (%s)",
+						strings.Join(st.keyColumns, ", "), strings.Join(key, ", ")),
+					Code: adbc.StatusInvalidArgument,
+				}
+			}
+			seen[k] = struct{}{}
+		}
+		return nil
+	}
+
+	// creates the target if it doesn't already exist
+	if _, err = st.initIngest(ctx); err != nil {
+		return
+	}
+
+	stage := st.qualifyTarget(quoteIdentifier("adbc_merge_stage_" + strconv.FormatInt(time.Now().UnixNano(), 36)))
+	colDefs, stageInsert, err := ingestQueries(stage, schema, false)
+	if err != nil {
+		return
+	}
+
+	if _, err = st.cnxn.cn.ExecContext(ctx, "CREATE TEMPORARY TABLE "+stage+" ("+colDefs+")", nil); err != nil {
+		return res, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	defer func() {
+		_, dropErr := st.cnxn.cn.ExecContext(ctx, "DROP TABLE IF EXISTS "+stage, nil)
+		if err == nil && dropErr != nil {
+			err = errToAdbcErr(adbc.StatusInternal, dropErr)
+		}
+	}()
+
+	if _, err = st.insertBound(ctx, stageInsert, checkKeys); err != nil {
+		return
+	}
+
+	merge, updates := mergeQuery(st.qualifiedTargetTable(), stage, schema, st.keyColumns)
+	rows, err := st.cnxn.cn.QueryContext(ctx, merge, nil)
+	if err != nil {
+		return res, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	defer rows.Close()
+
+	// MERGE returns a single row with a column for each action of the
+	// statement: the rows inserted, then the rows updated if there are
+	// columns to update. the names of the columns are localized, so
+	// they are read by position.
+	dest := make([]driver.Value, len(rows.Columns()))
+	if err = rows.Next(dest); err != nil {
+		return res, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	if len(dest) > 0 {
+		if res.inserted, err = toInt64(dest[0]); err != nil {
+			return res, errToAdbcErr(adbc.StatusInternal, err)
+		}
+	}
+	if len(dest) > 1 && updates {
+		if res.updated, err = toInt64(dest[1]); err != nil {
+			return res, errToAdbcErr(adbc.StatusInternal, err)
+		}
+	}
+	return res, nil
+}
+
+// executeMergeQuery performs a merge ingestion, returning a reader
+// with a single row holding the number of rows inserted and updated.
+func (st *statement) executeMergeQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	if st.streamBind == nil && st.bound == nil {
+		return nil, -1, adbc.Error{
+			Msg:  "must call Bind before bulk ingestion",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	res, err := st.executeMerge(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	rdr, err := res.toReader(st.alloc)
+	if err != nil {
+		return nil, -1, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	return rdr, res.inserted + res.updated, nil
+}
+
 // ExecuteQuery executes the current query or prepared statement
 // and returnes a RecordReader for the results along with the number
 // of rows affected if known, otherwise it will be -1.
@@ -410,6 +996,9 @@ func (st *statement) executeIngest(ctx context.Context) (int64, error) {
 // This invalidates any prior result sets on this statement.
 func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
 	if st.targetTable != "" {
+		if st.ingestMode == adbc.OptionValueIngestModeMerge {
+			return st.executeMergeQuery(ctx)
+		}
 		n, err := st.executeIngest(ctx)
 		return nil, n, err
 	}
@@ -441,6 +1030,98 @@ func (st *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int6
 	return rdr, nrec, err
 }
 
+// ExecuteSchema gets the schema of the result set of the current query
+// without reading its results, by running it with LIMIT 0. The column
+// types are converted the same way as for ExecuteQuery, but as the
+// empty result comes without a record batch stream, the fields have
+// none of the metadata Snowflake attaches to the streams.
+//
+// Only queries can be wrapped in a LIMIT 0 query, other statements
+// (such as SHOW or DML) fail with the error Snowflake reports for them.
+func (st *statement) ExecuteSchema(ctx context.Context) (*arrow.Schema, error) {
+	if st.targetTable != "" {
+		return nil, adbc.Error{
+			Msg:  "cannot get the result schema of a bulk ingestion",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	if st.query == "" {
+		return nil, adbc.Error{
+			Msg:  "cannot execute without a query",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	query := strings.TrimRight(strings.TrimSpace(st.query), "; \t\r\n")
+	// the query may end with a -- comment
+	loader, err := st.cnxn.cn.QueryArrowStream(ctx, "SELECT * FROM (\n"+query+"\n) LIMIT 0")
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+
+	return getSchema(ctx, st.alloc, loader)
+}
+
+// ExecuteMulti executes the current query, which may be made of several
+// statements separated by semicolons, and returns the result of each.
+// A query of a single statement is executed the same way as by
+// ExecuteQuery, other queries with the MULTI_STATEMENT_COUNT parameter
+// set to 0 so that Snowflake runs the statements together, stopping at
+// the first to fail. Their results are fetched one at a time as
+// NextResult moves to them.
+func (st *statement) ExecuteMulti(ctx context.Context) (adbc.MultiResult, error) {
+	if st.targetTable != "" {
+		return nil, adbc.Error{
+			Msg:  "cannot execute a bulk ingestion as multiple statements",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	if st.query == "" {
+		return nil, adbc.Error{
+			Msg:  "cannot execute without a query",
+			Code: adbc.StatusInvalidState,
+		}
+	}
+
+	if st.streamBind != nil || st.bound != nil {
+		return nil, adbc.Error{
+			Msg:  "executing non-bulk ingest with bound params not yet implemented",
+			Code: adbc.StatusNotImplemented,
+		}
+	}
+
+	if singleStatement(st.query) {
+		rdr, n, err := st.ExecuteQuery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &singleResult{rdr: rdr, n: n}, nil
+	}
+
+	multiCtx, err := gosnowflake.WithMultiStatement(ctx, 0)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+	multiCtx = gosnowflake.WithArrowAllocator(gosnowflake.WithArrowBatches(multiCtx), st.alloc)
+
+	rows, err := st.cnxn.cn.QueryContext(multiCtx, st.query, nil)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusInternal, err)
+	}
+
+	mr, ok := rows.(multiRows)
+	if !ok {
+		rows.Close()
+		return nil, adbc.Error{
+			Msg:  "[Snowflake] gosnowflake returned rows without arrow batches",
+			Code: adbc.StatusInternal,
+		}
+	}
+	return &multiResult{alloc: st.alloc, rows: mr}, nil
+}
+
 // ExecuteUpdate executes a statement that does not generate a result
 // set. It returns the number of rows affected if known, otherwise -1.
 func (st *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
@@ -589,3 +1270,8 @@ func (st *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc
 		Code: adbc.StatusNotImplemented,
 	}
 }
+
+var (
+	_ adbc.StatementExecuteSchema = (*statement)(nil)
+	_ adbc.StatementExecuteMulti  = (*statement)(nil)
+)
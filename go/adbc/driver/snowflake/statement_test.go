@@ -0,0 +1,329 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type standInConn struct {
+	snowflakeConn
+
+	queries []string
+	args    [][]driver.NamedValue
+	// the result set returned by QueryContext
+	cols []string
+	row  []driver.Value
+	// the rows returned for DESC TABLE
+	desc [][]driver.Value
+}
+
+type standInRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *standInRows) Columns() []string { return r.cols }
+func (r *standInRows) Close() error      { return nil }
+func (r *standInRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var descTableCols = []string{"name", "type", "kind", "null?", "default", "primary key",
+	"unique key", "check", "expression", "comment", "policy name"}
+
+func descRow(name, typ string, nullable bool) []driver.Value {
+	isnull := "N"
+	if nullable {
+		isnull = "Y"
+	}
+	return []driver.Value{name, typ, "COLUMN", isnull, nil, "N", "N", nil, nil, nil, nil}
+}
+
+func (c *standInConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, append([]driver.NamedValue(nil), args...))
+	if strings.HasPrefix(query, "DESC TABLE ") {
+		return &standInRows{cols: descTableCols, rows: c.desc}, nil
+	}
+	return &standInRows{cols: c.cols, rows: [][]driver.Value{c.row}}, nil
+}
+
+func (c *standInConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.queries = append(c.queries, query)
+	c.args = append(c.args, append([]driver.NamedValue(nil), args...))
+	return driver.RowsAffected(0), nil
+}
+
+func TestIngestSemiStructured(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+		{Name: "attrs", Type: arrow.StructOf(arrow.Field{Name: "k", Type: arrow.BinaryTypes.String, Nullable: true}), Nullable: true},
+	}, nil)
+
+	rec, _, err := array.RecordFromJSON(mem, sc, strings.NewReader(`[
+		{"id": 1, "tags": ["a", "b"], "attrs": {"k": "v"}},
+		{"id": 2, "tags": null, "attrs": null}
+	]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	cn := &standInConn{}
+	st := &statement{alloc: mem, cnxn: &cnxn{cn: cn}}
+	require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetTable, "semi"))
+	require.NoError(t, st.Bind(context.Background(), rec))
+	_, err = st.ExecuteUpdate(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, cn.queries, 2)
+	assert.Equal(t, `CREATE TABLE semi ("id" integer NOT NULL, "tags" array, "attrs" object)`, cn.queries[0])
+	assert.Equal(t, `INSERT INTO semi SELECT column1, TO_ARRAY(PARSE_JSON(column2)), TO_OBJECT(PARSE_JSON(column3)) FROM VALUES (?, ?, ?)`, cn.queries[1])
+	assert.Len(t, cn.args[1], 3)
+}
+
+func TestIngestModes(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	sc := arrow.NewSchema([]arrow.Field{{Name: `a"b`, Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+	rec, _, err := array.RecordFromJSON(mem, sc, strings.NewReader(`[{"a\"b": 1}]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	tests := []struct {
+		name    string
+		opts    map[string]string
+		queries []string
+	}{
+		{"create", map[string]string{}, []string{
+			`CREATE TABLE tbl ("a""b" integer)`,
+			`INSERT INTO tbl VALUES (?)`}},
+		{"append", map[string]string{adbc.OptionKeyIngestMode: adbc.OptionValueIngestModeAppend}, []string{
+			`INSERT INTO tbl VALUES (?)`}},
+		{"replace", map[string]string{adbc.OptionKeyIngestMode: adbc.OptionValueIngestModeReplace}, []string{
+			`CREATE OR REPLACE TABLE tbl ("a""b" integer)`,
+			`INSERT INTO tbl VALUES (?)`}},
+		{"create_append", map[string]string{adbc.OptionKeyIngestMode: adbc.OptionValueIngestModeCreateAppend}, []string{
+			`CREATE TABLE IF NOT EXISTS tbl ("a""b" integer)`,
+			`INSERT INTO tbl VALUES (?)`}},
+		{"temporary", map[string]string{adbc.OptionKeyIngestTemporary: adbc.OptionValueEnabled}, []string{
+			`CREATE TEMPORARY TABLE tbl ("a""b" integer)`,
+			`INSERT INTO tbl VALUES (?)`}},
+		{"qualified", map[string]string{
+			adbc.OptionKeyIngestTargetCatalog:  "db",
+			adbc.OptionKeyIngestTargetDBSchema: "my_schema",
+		}, []string{
+			`CREATE TABLE db.my_schema.tbl ("a""b" integer)`,
+			`INSERT INTO db.my_schema.tbl VALUES (?)`}},
+		{"case sensitive", map[string]string{
+			adbc.OptionKeyIngestTargetCatalog:  "My DB",
+			adbc.OptionKeyIngestTargetDBSchema: "my_schema",
+			OptionStatementIngestCaseSensitive: adbc.OptionValueEnabled,
+		}, []string{
+			`CREATE TABLE "My DB"."my_schema"."tbl" ("a""b" integer)`,
+			`INSERT INTO "My DB"."my_schema"."tbl" VALUES (?)`}},
+		{"catalog only", map[string]string{adbc.OptionKeyIngestTargetCatalog: "db"}, []string{
+			`CREATE TABLE db..tbl ("a""b" integer)`,
+			`INSERT INTO db..tbl VALUES (?)`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cn := &standInConn{}
+			st := &statement{alloc: mem, cnxn: &cnxn{cn: cn}}
+			require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetTable, "tbl"))
+			for k, v := range tt.opts {
+				require.NoError(t, st.SetOption(k, v))
+			}
+			require.NoError(t, st.Bind(context.Background(), rec))
+			_, err := st.ExecuteUpdate(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.queries, cn.queries)
+		})
+	}
+
+	st := &statement{alloc: mem, cnxn: &cnxn{cn: &standInConn{}}}
+	var adbcErr adbc.Error
+	require.ErrorAs(t, st.SetOption(adbc.OptionKeyIngestMode, "adbc.ingest.mode.unknown"), &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+	require.ErrorAs(t, st.SetOption(adbc.OptionKeyIngestTemporary, "maybe"), &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+	require.ErrorAs(t, st.SetOption(OptionStatementIngestCaseSensitive, "maybe"), &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func TestIngestMerge(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	sc := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "region", Type: arrow.BinaryTypes.String},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	rec, _, err := array.RecordFromJSON(mem, sc, strings.NewReader(`[
+		{"id": 1, "region": "a", "value": 1.5},
+		{"id": 2, "region": "b", "value": null}
+	]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	newStmt := func(cn *standInConn) *statement {
+		st := &statement{alloc: mem, cnxn: &cnxn{cn: cn}}
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetTable, "tbl"))
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeMerge))
+		return st
+	}
+
+	t.Run("update", func(t *testing.T) {
+		// the counts are read by position, whatever the language of
+		// the names of the columns
+		cn := &standInConn{
+			cols: []string{"nombre de lignes insérées", "nombre de lignes mises à jour"},
+			row:  []driver.Value{"1", "2"},
+		}
+		st := newStmt(cn)
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestKeyColumns, "id, region"))
+		require.NoError(t, st.Bind(context.Background(), rec))
+
+		rdr, n, err := st.ExecuteQuery(context.Background())
+		require.NoError(t, err)
+		defer rdr.Release()
+		assert.EqualValues(t, 3, n)
+
+		require.True(t, rdr.Next())
+		assert.Truef(t, mergeResultSchema.Equal(rdr.Schema()), "got: %s", rdr.Schema())
+		assert.EqualValues(t, 1, rdr.Record().Column(0).(*array.Int64).Value(0))
+		assert.EqualValues(t, 2, rdr.Record().Column(1).(*array.Int64).Value(0))
+		assert.False(t, rdr.Next())
+
+		require.Len(t, cn.queries, 5)
+		assert.Equal(t, `CREATE TABLE IF NOT EXISTS tbl ("id" integer NOT NULL, "region" text NOT NULL, "value" double)`, cn.queries[0])
+		stage := regexp.MustCompile(`^CREATE TEMPORARY TABLE ("adbc_merge_stage_[0-9a-z]+") \(`).FindStringSubmatch(cn.queries[1])
+		require.Len(t, stage, 2, cn.queries[1])
+		assert.Equal(t, `INSERT INTO `+stage[1]+` VALUES (?, ?, ?)`, cn.queries[2])
+		assert.Equal(t, `MERGE INTO tbl AS t USING `+stage[1]+` AS s ON t."id" = s."id" AND t."region" = s."region"`+
+			` WHEN MATCHED THEN UPDATE SET t."value" = s."value"`+
+			` WHEN NOT MATCHED THEN INSERT ("id", "region", "value") VALUES (s."id", s."region", s."value")`, cn.queries[3])
+		assert.Equal(t, `DROP TABLE IF EXISTS `+stage[1], cn.queries[4])
+	})
+
+	t.Run("only keys", func(t *testing.T) {
+		cn := &standInConn{
+			cols: []string{"number of rows inserted"},
+			row:  []driver.Value{int64(2)},
+		}
+		st := newStmt(cn)
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestKeyColumns, "id,region,value"))
+		require.NoError(t, st.Bind(context.Background(), rec))
+
+		n, err := st.ExecuteUpdate(context.Background())
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, n)
+		require.Len(t, cn.queries, 5)
+		assert.NotContains(t, cn.queries[3], "WHEN MATCHED")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var adbcErr adbc.Error
+
+		st := newStmt(&standInConn{})
+		require.NoError(t, st.Bind(context.Background(), rec))
+		_, err := st.ExecuteUpdate(context.Background())
+		require.ErrorAs(t, err, &adbcErr)
+		assert.Equal(t, adbc.StatusInvalidState, adbcErr.Code)
+		// the bound data is released by a failed merge too
+		assert.Nil(t, st.bound)
+
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestKeyColumns, "id,missing"))
+		require.NoError(t, st.Bind(context.Background(), rec))
+		_, _, err = st.ExecuteQuery(context.Background())
+		require.ErrorAs(t, err, &adbcErr)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Contains(t, adbcErr.Msg, "missing")
+		assert.Nil(t, st.bound)
+		require.NoError(t, st.Close())
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		dups, _, err := array.RecordFromJSON(mem, sc, strings.NewReader(`[
+			{"id": 1, "region": "a", "value": 1.5},
+			{"id": 1, "region": "b", "value": 2},
+			{"id": 1, "region": "a", "value": null}
+		]`))
+		require.NoError(t, err)
+		defer dups.Release()
+
+		cn := &standInConn{}
+		st := newStmt(cn)
+		defer st.Close()
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestKeyColumns, "id,region"))
+		require.NoError(t, st.Bind(context.Background(), dups))
+
+		var adbcErr adbc.Error
+		_, err = st.ExecuteUpdate(context.Background())
+		require.ErrorAs(t, err, &adbcErr)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Equal(t, "bound data has more than one row with key (id, region) = (1, a)", adbcErr.Msg)
+
+		// nothing was merged and the staging table was dropped
+		require.Len(t, cn.queries, 3)
+		assert.True(t, strings.HasPrefix(cn.queries[2], "DROP TABLE IF EXISTS "), cn.queries[2])
+	})
+
+	t.Run("qualified", func(t *testing.T) {
+		cn := &standInConn{
+			cols: []string{"number of rows inserted", "number of rows updated"},
+			row:  []driver.Value{"2", "0"},
+		}
+		st := newStmt(cn)
+		defer st.Close()
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetCatalog, "db"))
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestTargetDBSchema, "sch"))
+		require.NoError(t, st.SetOption(adbc.OptionKeyIngestKeyColumns, "id"))
+		require.NoError(t, st.Bind(context.Background(), rec))
+
+		_, err := st.ExecuteUpdate(context.Background())
+		require.NoError(t, err)
+		require.Len(t, cn.queries, 5)
+		// the staging table is created next to the target
+		stage := regexp.MustCompile(`^CREATE TEMPORARY TABLE (db\.sch\."adbc_merge_stage_[0-9a-z]+") \(`).FindStringSubmatch(cn.queries[1])
+		require.Len(t, stage, 2, cn.queries[1])
+		assert.True(t, strings.HasPrefix(cn.queries[3], `MERGE INTO db.sch.tbl AS t USING `+stage[1]+` AS s`), cn.queries[3])
+	})
+}
@@ -0,0 +1,327 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// The driver-specific statistics
+const (
+	// StatisticTableBytesKey is the number of bytes of the table, as
+	// reported by the BYTES column of INFORMATION_SCHEMA.TABLES.
+	StatisticTableBytesKey  = 1024
+	StatisticTableBytesName = "snowflake.table.bytes"
+)
+
+// union type codes of adbc.StatisticsValueTypeUnion
+const (
+	statInt64Code   arrow.UnionTypeCode = 0
+	statFloat64Code arrow.UnionTypeCode = 2
+)
+
+type statTable struct {
+	catalog, schema, name string
+	rowCount, bytes       sql.NullInt64
+	columns               []statColumn
+	values                []statValue
+}
+
+type statColumn struct {
+	name     string
+	dataType string
+	scale    sql.NullInt16
+}
+
+// hasMinMax reports whether the min and max values of the column are
+// reported, which is the case of the columns fitting an int64 or a
+// float64.
+func (c statColumn) hasMinMax() bool {
+	return c.dataType == "FLOAT" || (c.dataType == "NUMBER" && c.scale.Valid && c.scale.Int16 == 0)
+}
+
+// statValue is a statistic of a table, or of one of its columns
+type statValue struct {
+	column      string
+	key         int16
+	i           int64
+	f           float64
+	isFloat     bool
+	approximate bool
+}
+
+// GetStatistics reports the row count and size in bytes of the tables
+// from INFORMATION_SCHEMA.TABLES. That is all if approximate is set.
+// Otherwise, the null count, distinct count and for numeric columns,
+// the min and max values of their columns are computed by a query over
+// each table, so a table name pattern is required. The distinct count
+// is always approximate, computed with APPROX_COUNT_DISTINCT.
+func (c *cnxn) GetStatistics(ctx context.Context, catalog, dbSchema, tableName *string, approximate bool) (array.RecordReader, error) {
+	if !approximate && tableName == nil {
+		return nil, adbc.Error{
+			Msg:  "[Snowflake] exact statistics scan the tables, a table name pattern is required",
+			Code: adbc.StatusInvalidArgument,
+		}
+	}
+
+	tables, err := c.getStatTables(ctx, catalog, dbSchema, tableName, !approximate)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		t := &tables[i]
+		if t.rowCount.Valid {
+			t.values = append(t.values, statValue{key: adbc.StatisticRowCountKey, i: t.rowCount.Int64})
+		}
+		if t.bytes.Valid {
+			t.values = append(t.values, statValue{key: StatisticTableBytesKey, i: t.bytes.Int64})
+		}
+
+		colValues, err := c.getColumnStatistics(ctx, *t)
+		if err != nil {
+			return nil, err
+		}
+		t.values = append(t.values, colValues...)
+	}
+
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.GetStatisticsSchema)
+	defer bldr.Release()
+
+	catalogNameBldr := bldr.Field(0).(*array.StringBuilder)
+	dbSchemasBldr := bldr.Field(1).(*array.ListBuilder)
+	dbSchemaItems := dbSchemasBldr.ValueBuilder().(*array.StructBuilder)
+	dbSchemaNameBldr := dbSchemaItems.FieldBuilder(0).(*array.StringBuilder)
+	statsBldr := dbSchemaItems.FieldBuilder(1).(*array.ListBuilder)
+	statsItems := statsBldr.ValueBuilder().(*array.StructBuilder)
+	tableNameBldr := statsItems.FieldBuilder(0).(*array.StringBuilder)
+	columnNameBldr := statsItems.FieldBuilder(1).(*array.StringBuilder)
+	keyBldr := statsItems.FieldBuilder(2).(*array.Int16Builder)
+	valueBldr := statsItems.FieldBuilder(3).(*array.DenseUnionBuilder)
+	int64Bldr := valueBldr.Child(int(statInt64Code)).(*array.Int64Builder)
+	float64Bldr := valueBldr.Child(int(statFloat64Code)).(*array.Float64Builder)
+	approxBldr := statsItems.FieldBuilder(4).(*array.BooleanBuilder)
+
+	// the tables are sorted, so that those of a schema are together
+	for i, t := range tables {
+		if i == 0 || t.catalog != tables[i-1].catalog {
+			catalogNameBldr.Append(t.catalog)
+			dbSchemasBldr.Append(true)
+		}
+		if i == 0 || t.catalog != tables[i-1].catalog || t.schema != tables[i-1].schema {
+			dbSchemaItems.Append(true)
+			dbSchemaNameBldr.Append(t.schema)
+			statsBldr.Append(true)
+		}
+
+		for _, val := range t.values {
+			statsItems.Append(true)
+			tableNameBldr.Append(t.name)
+			if val.column == "" {
+				columnNameBldr.AppendNull()
+			} else {
+				columnNameBldr.Append(val.column)
+			}
+			keyBldr.Append(val.key)
+			if val.isFloat {
+				valueBldr.Append(statFloat64Code)
+				float64Bldr.Append(val.f)
+			} else {
+				valueBldr.Append(statInt64Code)
+				int64Bldr.Append(val.i)
+			}
+			approxBldr.Append(val.approximate)
+		}
+	}
+
+	rec := bldr.NewRecord()
+	defer rec.Release()
+	return array.NewRecordReader(adbc.GetStatisticsSchema, []arrow.Record{rec})
+}
+
+// GetStatisticNames lists the driver-specific statistics.
+func (c *cnxn) GetStatisticNames(context.Context) (array.RecordReader, error) {
+	bldr := array.NewRecordBuilder(c.db.alloc, adbc.GetStatisticNamesSchema)
+	defer bldr.Release()
+
+	bldr.Field(0).(*array.StringBuilder).Append(StatisticTableBytesName)
+	bldr.Field(1).(*array.Int16Builder).Append(StatisticTableBytesKey)
+
+	rec := bldr.NewRecord()
+	defer rec.Release()
+	return array.NewRecordReader(adbc.GetStatisticNamesSchema, []arrow.Record{rec})
+}
+
+// getStatTables lists the tables matching the patterns with their
+// sizes, and their columns if withColumns is set, sorted by catalog,
+// schema and name.
+func (c *cnxn) getStatTables(ctx context.Context, catalog, dbSchema, tableName *string, withColumns bool) ([]statTable, error) {
+	const sizes = `statement := statement || ' SELECT table_catalog, table_schema, table_name, table_type, row_count, bytes FROM ' || rec.database_name || '.INFORMATION_SCHEMA.TABLES';
+			counter := counter + 1;
+		END FOR;
+		`
+
+	conditions := tableConditions(catalog, dbSchema, tableName)
+	cond := strings.Join(append(conditions, ` TABLE_TYPE IN (\'BASE TABLE\',\'TEMPORARY TABLE\')`), " AND ")
+	query := tablesQueryPrefix + sizes + `statement := 'SELECT * FROM (' || statement || ') WHERE ` + cond + `';` + tablesQuerySuffix
+	rows, err := c.sqldb.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	defer rows.Close()
+
+	var tables []statTable
+	lookup := make(map[[3]string]int)
+	for rows.Next() {
+		var t statTable
+		var tableType string
+		if err := rows.Scan(&t.catalog, &t.schema, &t.name, &tableType, &t.rowCount, &t.bytes); err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		a, b := tables[i], tables[j]
+		if a.catalog != b.catalog {
+			return a.catalog < b.catalog
+		}
+		if a.schema != b.schema {
+			return a.schema < b.schema
+		}
+		return a.name < b.name
+	})
+	for i, t := range tables {
+		lookup[[3]string{t.catalog, t.schema, t.name}] = i
+	}
+	if len(tables) == 0 || !withColumns {
+		return tables, nil
+	}
+
+	cond = strings.Join(conditions, " AND ")
+	if cond != "" {
+		cond = " WHERE " + cond
+	}
+	cond = `statement := 'SELECT * FROM (' || statement || ')` + cond +
+		` ORDER BY table_catalog, table_schema, table_name, ordinal_position';`
+	colRows, err := c.sqldb.QueryContext(ctx, tablesQueryPrefix+tablesColumnsQuery+cond+tablesQuerySuffix)
+	if err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	defer colRows.Close()
+
+	var (
+		tblCat, tblSchema, tblName, colName, dataType string
+		identGen, identIncrement, comment             sql.NullString
+		ordinalPos                                    int
+		numericPrec, numericPrecRadix, numericScale   sql.NullInt16
+		isNullable, isIdent                           bool
+	)
+	for colRows.Next() {
+		// order here matches the order of the columns in tablesColumnsQuery
+		err := colRows.Scan(&tblCat, &tblSchema, &tblName, &colName,
+			&ordinalPos, &isNullable, &dataType, &numericPrec,
+			&numericPrecRadix, &numericScale, &isIdent, &identGen,
+			&identIncrement, &comment)
+		if err != nil {
+			return nil, errToAdbcErr(adbc.StatusIO, err)
+		}
+		if i, ok := lookup[[3]string{tblCat, tblSchema, tblName}]; ok {
+			tables[i].columns = append(tables[i].columns,
+				statColumn{name: colName, dataType: dataType, scale: numericScale})
+		}
+	}
+	if err := colRows.Err(); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+	return tables, nil
+}
+
+// getColumnStatistics computes the statistics of the columns of a
+// table with a single query.
+func (c *cnxn) getColumnStatistics(ctx context.Context, t statTable) ([]statValue, error) {
+	if len(t.columns) == 0 {
+		return nil, nil
+	}
+
+	exprs := []string{"COUNT(*)"}
+	for _, col := range t.columns {
+		q := quoteIdentifier(col.name)
+		exprs = append(exprs, "COUNT("+q+")", "APPROX_COUNT_DISTINCT("+q+")")
+		if col.hasMinMax() {
+			exprs = append(exprs, "MIN("+q+")", "MAX("+q+")")
+		}
+	}
+	query := "SELECT " + strings.Join(exprs, ", ") + " FROM " +
+		quoteIdentifier(t.catalog) + "." + quoteIdentifier(t.schema) + "." + quoteIdentifier(t.name)
+
+	var total int64
+	dest := []interface{}{&total}
+	counts := make([][2]int64, len(t.columns))
+	bounds := make([][2]sql.NullString, len(t.columns))
+	for i, col := range t.columns {
+		dest = append(dest, &counts[i][0], &counts[i][1])
+		if col.hasMinMax() {
+			dest = append(dest, &bounds[i][0], &bounds[i][1])
+		}
+	}
+	if err := c.sqldb.QueryRowContext(ctx, query).Scan(dest...); err != nil {
+		return nil, errToAdbcErr(adbc.StatusIO, err)
+	}
+
+	var values []statValue
+	for i, col := range t.columns {
+		values = append(values,
+			statValue{column: col.name, key: adbc.StatisticNullCountKey, i: total - counts[i][0]},
+			// approximate counts are given as float64
+			statValue{column: col.name, key: adbc.StatisticDistinctCountKey,
+				f: float64(counts[i][1]), isFloat: true, approximate: true})
+
+		for j, key := range []int16{adbc.StatisticMinValueKey, adbc.StatisticMaxValueKey} {
+			b := bounds[i][j]
+			if !b.Valid {
+				continue
+			}
+			val := statValue{column: col.name, key: key}
+			var err error
+			if col.dataType == "FLOAT" {
+				val.isFloat = true
+				val.f, err = strconv.ParseFloat(b.String, 64)
+			} else {
+				val.i, err = strconv.ParseInt(b.String, 10, 64)
+			}
+			// values out of the range of an int64 are not reported
+			if err == nil {
+				values = append(values, val)
+			}
+		}
+	}
+	return values, nil
+}
+
+var _ adbc.ConnectionGetStatistics = (*cnxn)(nil)
@@ -19,4 +19,18 @@
 //
 // This provides an implementation of the ADBC interfaces which
 // uses CGO to wrap a shared object implementation of adbc.h
+//
+// The "driver" option names the driver to load, either as the path or
+// name of its shared library, or through a driver manifest: a TOML
+// file giving the library, entrypoint and default options of the
+// driver (see Manifest). A driver name such as "adbc_driver_sqlite"
+// is looked up as adbc_driver_sqlite.toml in the directories of
+// Driver.SearchPaths, then of the ADBC_DRIVER_PATH environment
+// variable and the default ones (see SearchPaths), falling back to
+// loading the library of that name if no manifest is found.
+//
+// The package registers Driver with adbc.RegisterDriver as
+// "drivermgr", and the ADBC SQLite driver as "sqlite" for the sqlite
+// scheme, so that adbc.Open opens a URI such as sqlite:///tmp/data.db
+// as the SQLite database /tmp/data.db.
 package drivermgr
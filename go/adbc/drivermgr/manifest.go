@@ -0,0 +1,297 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package drivermgr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/apache/arrow-adbc/go/adbc"
+)
+
+// DriverPathEnv is the environment variable holding a list of
+// directories, separated by os.PathListSeparator, which are searched
+// for driver manifests after Driver.SearchPaths.
+const DriverPathEnv = "ADBC_DRIVER_PATH"
+
+// Manifest describes a driver, as read from a manifest file named
+// after the driver with the extension .toml, such as
+// adbc_driver_sqlite.toml:
+//
+//	name = "ADBC SQLite Driver"
+//	version = "0.5.0"
+//
+//	[driver]
+//	library = "/usr/lib/libadbc_driver_sqlite.so"
+//	entrypoint = "AdbcDriverSqliteInit"
+//
+//	[options]
+//	adbc.sqlite.query.batch_rows = 1024
+//
+// The library is either a single path, or a table of paths per
+// platform, whose keys are GOOS_GOARCH:
+//
+//	[driver.library]
+//	linux_amd64 = "linux/libadbc_driver_sqlite.so"
+//	darwin_arm64 = "macos/libadbc_driver_sqlite.dylib"
+//
+// Relative library paths are relative to the directory of the
+// manifest. The options are the default options of databases using
+// the driver, which the options given to Driver.NewDatabase override.
+type Manifest struct {
+	// Path is the path of the manifest file
+	Path string
+	// Name is the display name of the driver
+	Name string
+	// Version is the version of the driver
+	Version string
+	// Library is the path of the shared library of the driver for
+	// the current platform
+	Library string
+	// Entrypoint is the name of the initialization function of the
+	// driver, if it is not the default one
+	Entrypoint string
+	// Options are the default database options
+	Options map[string]string
+}
+
+func manifestError(path, format string, args ...interface{}) error {
+	return &adbc.Error{
+		Msg:  fmt.Sprintf("[drivermgr] invalid manifest %s: ", path) + fmt.Sprintf(format, args...),
+		Code: adbc.StatusInvalidArgument,
+	}
+}
+
+// LoadManifest reads the driver manifest at the given path.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		code := adbc.StatusIO
+		if errors.Is(err, fs.ErrNotExist) {
+			code = adbc.StatusNotFound
+		}
+		adbcErr := adbc.Error{
+			Msg:  fmt.Sprintf("[drivermgr] cannot read manifest: %s", err),
+			Code: code,
+		}.WithErr(err)
+		return nil, &adbcErr
+	}
+
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, manifestError(path, "%s", err)
+	}
+
+	m := &Manifest{Path: path, Options: make(map[string]string)}
+	getString := func(tbl map[string]interface{}, key, name string, out *string) error {
+		switch v := tbl[key].(type) {
+		case nil:
+		case string:
+			*out = v
+		default:
+			return manifestError(path, "%s must be a string", name)
+		}
+		return nil
+	}
+	if err := getString(doc, "name", "name", &m.Name); err != nil {
+		return nil, err
+	}
+	if err := getString(doc, "version", "version", &m.Version); err != nil {
+		return nil, err
+	}
+
+	drv, ok := doc["driver"].(map[string]interface{})
+	if !ok {
+		return nil, manifestError(path, "missing [driver] table")
+	}
+	if err := getString(drv, "entrypoint", "driver.entrypoint", &m.Entrypoint); err != nil {
+		return nil, err
+	}
+
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	switch lib := drv["library"].(type) {
+	case string:
+		m.Library = lib
+	case map[string]interface{}:
+		if err := getString(lib, platform, "driver.library."+platform, &m.Library); err != nil {
+			return nil, err
+		}
+		if m.Library == "" {
+			return nil, manifestError(path, "no library for platform %s", platform)
+		}
+	case nil:
+		return nil, manifestError(path, "missing driver.library")
+	default:
+		return nil, manifestError(path, "driver.library must be a string or a table")
+	}
+	if !filepath.IsAbs(m.Library) {
+		m.Library = filepath.Join(filepath.Dir(path), filepath.FromSlash(m.Library))
+	}
+
+	switch opts := doc["options"].(type) {
+	case nil:
+	case map[string]interface{}:
+		if err := flattenOptions("", opts, m.Options); err != nil {
+			return nil, manifestError(path, "%s", err)
+		}
+	default:
+		return nil, manifestError(path, "options must be a table")
+	}
+	return m, nil
+}
+
+// flattenOptions adds the options of the table to out, taking the
+// keys of nested tables, which unquoted dotted keys give, as the
+// dotted keys they were written as. Options must be strings, numbers
+// or booleans.
+func flattenOptions(prefix string, tbl map[string]interface{}, out map[string]string) error {
+	for k, v := range tbl {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if err := flattenOptions(key+".", v, out); err != nil {
+				return err
+			}
+		case string:
+			out[key] = v
+		case int64:
+			out[key] = strconv.FormatInt(v, 10)
+		case float64:
+			out[key] = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			out[key] = strconv.FormatBool(v)
+		default:
+			return fmt.Errorf("option %s must be a string, a number or a boolean", key)
+		}
+	}
+	return nil
+}
+
+// SearchPaths returns the directories searched for driver manifests,
+// in order: the given directories, those of the DriverPathEnv
+// environment variable, then adbc/drivers in the user configuration
+// directory (see os.UserConfigDir) and, except on Windows,
+// /etc/adbc/drivers.
+func SearchPaths(extra ...string) []string {
+	paths := append([]string(nil), extra...)
+	for _, p := range filepath.SplitList(os.Getenv(DriverPathEnv)) {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "adbc", "drivers"))
+	}
+	if runtime.GOOS != "windows" {
+		paths = append(paths, "/etc/adbc/drivers")
+	}
+	return paths
+}
+
+// FindManifest looks for the manifest of the named driver in the
+// directories returned by SearchPaths for the given ones, returning
+// the first one found. If there is none, the error lists the
+// locations tried.
+func FindManifest(name string, searchPaths ...string) (*Manifest, error) {
+	var tried []string
+	for _, dir := range SearchPaths(searchPaths...) {
+		path := filepath.Join(dir, name+".toml")
+		m, err := LoadManifest(path)
+		if err == nil {
+			return m, nil
+		}
+		var adbcErr *adbc.Error
+		if !errors.As(err, &adbcErr) || adbcErr.Code != adbc.StatusNotFound {
+			return nil, err
+		}
+		tried = append(tried, path)
+	}
+
+	return nil, &adbc.Error{
+		Msg: fmt.Sprintf("[drivermgr] no manifest found for driver '%s', tried: %s",
+			name, strings.Join(tried, ", ")),
+		Code: adbc.StatusNotFound,
+	}
+}
+
+// isDriverName reports whether the driver option is the name of a
+// driver to look up manifests for, rather than the path or file name
+// of a library or of a manifest.
+func isDriverName(driver string) bool {
+	if strings.ContainsAny(driver, `/\`) {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(driver)) {
+	case ".so", ".dylib", ".dll", ".toml":
+		return false
+	}
+	return true
+}
+
+// resolveDriver replaces the driver given by the options with the
+// library of its manifest, along with its entrypoint and default
+// options, if the driver is a manifest file or the name of a driver
+// which has one. If there is no manifest for the name, the options
+// are returned as they are, so that the driver manager loads the
+// library of that name, along with the locations of manifests tried
+// to report should that fail.
+func resolveDriver(opts map[string]string, searchPaths []string) (map[string]string, string, error) {
+	driver := opts["driver"]
+
+	var (
+		m   *Manifest
+		err error
+	)
+	switch {
+	case driver == "":
+		return opts, "", nil
+	case strings.EqualFold(filepath.Ext(driver), ".toml"):
+		m, err = LoadManifest(driver)
+	case isDriverName(driver):
+		m, err = FindManifest(driver, searchPaths...)
+		var adbcErr *adbc.Error
+		if errors.As(err, &adbcErr) && adbcErr.Code == adbc.StatusNotFound {
+			return opts, adbcErr.Msg, nil
+		}
+	default:
+		return opts, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	resolved := make(map[string]string, len(m.Options)+len(opts))
+	for k, v := range m.Options {
+		resolved[k] = v
+	}
+	for k, v := range opts {
+		resolved[k] = v
+	}
+	resolved["driver"] = m.Library
+	if _, ok := opts["entrypoint"]; !ok && m.Entrypoint != "" {
+		resolved["entrypoint"] = m.Entrypoint
+	}
+	return resolved, "", nil
+}
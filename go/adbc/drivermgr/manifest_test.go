@@ -0,0 +1,174 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package drivermgr
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.toml")
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+
+	writeFile(t, path, `
+name = "Test Driver"
+version = "1.2.3"
+
+[driver]
+entrypoint = "TestDriverInit"
+
+[driver.library]
+`+platform+` = "lib/libtest.so"
+other_platform = "/other/libtest.so"
+
+[options]
+adbc.test.batch_rows = 1024
+"adbc.test.name" = "value"
+adbc.test.enabled = true
+`)
+
+	m, err := LoadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, &Manifest{
+		Path:       path,
+		Name:       "Test Driver",
+		Version:    "1.2.3",
+		Library:    filepath.Join(dir, "lib", "libtest.so"),
+		Entrypoint: "TestDriverInit",
+		Options: map[string]string{
+			"adbc.test.batch_rows": "1024",
+			"adbc.test.name":       "value",
+			"adbc.test.enabled":    "true",
+		},
+	}, m)
+
+	var adbcErr *adbc.Error
+	for _, tc := range []struct{ doc, err string }{
+		{`name = "x"`, "missing [driver] table"},
+		{"[driver]", "missing driver.library"},
+		{"[driver]\nlibrary = 1", "driver.library must be a string or a table"},
+		{"[driver.library]\nother_platform = 'x'", "no library for platform " + platform},
+		{"version = 1\n[driver]\nlibrary = 'x'", "version must be a string"},
+		{"options = 1\n[driver]\nlibrary = 'x'", "options must be a table"},
+		{"[driver]\nlibrary = 'x'\n[options]\na = [1]", "option a must be a string, a number or a boolean"},
+		{"[driver\nlibrary = 'x'", "toml: line 2: expected '.' or ']' to end table name, but got '\\n' instead"},
+	} {
+		writeFile(t, path, tc.doc)
+		_, err := LoadManifest(path)
+		require.ErrorAs(t, err, &adbcErr, tc.doc)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Equal(t, "[drivermgr] invalid manifest "+path+": "+tc.err, adbcErr.Msg)
+	}
+
+	_, err = LoadManifest(filepath.Join(dir, "missing.toml"))
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+}
+
+func TestFindManifest(t *testing.T) {
+	first, second, env := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv(DriverPathEnv, env)
+
+	writeFile(t, filepath.Join(second, "test.toml"), "[driver]\nlibrary = '/second/libtest.so'")
+	writeFile(t, filepath.Join(env, "test.toml"), "[driver]\nlibrary = '/env/libtest.so'")
+	writeFile(t, filepath.Join(env, "envonly.toml"), "[driver]\nlibrary = '/env/libenvonly.so'")
+
+	m, err := FindManifest("test", first, second)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(second, "test.toml"), m.Path)
+
+	m, err = FindManifest("envonly", first, second)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(env, "envonly.toml"), m.Path)
+
+	// the error lists the locations tried
+	_, err = FindManifest("missing", first, second)
+	var adbcErr *adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+	assert.Contains(t, adbcErr.Msg, "no manifest found for driver 'missing'")
+	for _, dir := range []string{first, second, env} {
+		assert.Contains(t, adbcErr.Msg, filepath.Join(dir, "missing.toml"))
+	}
+
+	// an invalid manifest is reported rather than skipped
+	writeFile(t, filepath.Join(first, "test.toml"), "[driver")
+	_, err = FindManifest("test", first, second)
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+}
+
+func TestResolveDriver(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "test.toml"), `
+[driver]
+library = "/lib/libtest.so"
+entrypoint = "TestDriverInit"
+
+[options]
+a = "manifest"
+b = "manifest"
+`)
+
+	opts, notFound, err := resolveDriver(map[string]string{"driver": "test", "b": "user"}, []string{dir})
+	require.NoError(t, err)
+	assert.Empty(t, notFound)
+	assert.Equal(t, map[string]string{
+		"driver":     filepath.FromSlash("/lib/libtest.so"),
+		"entrypoint": "TestDriverInit",
+		"a":          "manifest",
+		"b":          "user",
+	}, opts)
+
+	// the entrypoint given overrides that of the manifest
+	opts, _, err = resolveDriver(map[string]string{
+		"driver":     filepath.Join(dir, "test.toml"),
+		"entrypoint": "OtherInit",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "OtherInit", opts["entrypoint"])
+
+	// libraries are loaded as they are
+	for _, driver := range []string{"/lib/libtest.so", "libtest.so", "test.dll", ""} {
+		in := map[string]string{"driver": driver}
+		opts, notFound, err = resolveDriver(in, []string{dir})
+		require.NoError(t, err)
+		assert.Empty(t, notFound)
+		assert.Equal(t, in, opts)
+	}
+
+	// names without manifests are kept to load the library by name
+	in := map[string]string{"driver": "missing"}
+	opts, notFound, err = resolveDriver(in, []string{dir})
+	require.NoError(t, err)
+	assert.Equal(t, in, opts)
+	assert.Contains(t, notFound, filepath.Join(dir, "missing.toml"))
+}
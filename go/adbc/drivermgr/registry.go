@@ -0,0 +1,62 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build cgo
+
+package drivermgr
+
+import (
+	"strings"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+)
+
+const sqliteScheme = "sqlite://"
+
+func init() {
+	adbc.RegisterDriver("drivermgr", Driver{})
+	adbc.RegisterDriver("sqlite", sqliteDriver{}, "sqlite")
+}
+
+// sqliteDriver loads the ADBC SQLite driver, unless the "driver"
+// option names another one. A sqlite:// URI is given to it as the
+// SQLite URI filename of the rest of the URI, so that
+// sqlite:///tmp/data.db opens /tmp/data.db, and sqlite:// alone
+// opens the default in-memory database of the driver.
+type sqliteDriver struct {
+	Driver
+}
+
+func (d sqliteDriver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	dbOpts := make(map[string]string, len(opts)+1)
+	for k, v := range opts {
+		dbOpts[k] = v
+	}
+	if _, ok := dbOpts["driver"]; !ok {
+		dbOpts["driver"] = "adbc_driver_sqlite"
+	}
+
+	uri, ok := dbOpts[adbc.OptionKeyURI]
+	if ok && len(uri) >= len(sqliteScheme) && strings.EqualFold(uri[:len(sqliteScheme)], sqliteScheme) {
+		if path := uri[len(sqliteScheme):]; path == "" {
+			delete(dbOpts, adbc.OptionKeyURI)
+		} else {
+			dbOpts[adbc.OptionKeyURI] = "file:" + path
+		}
+	}
+	return d.Driver.NewDatabase(dbOpts)
+}
@@ -25,7 +25,12 @@ package drivermgr
 // #include "adbc.h"
 // #include <stdlib.h>
 //
-// void releaseErr(struct AdbcError* err) { err->release(err); }
+// void releaseErr(struct AdbcError* err) {
+//     if (err->release) err->release(err);
+// }
+// void releasePartitions(struct AdbcPartitions* partitions) {
+//     if (partitions->release) partitions->release(partitions);
+// }
 // struct ArrowArray* allocArr() {
 //     return (struct ArrowArray*)malloc(sizeof(struct ArrowArray));
 // }
@@ -40,6 +45,7 @@ import (
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
 	"github.com/apache/arrow/go/v12/arrow/cdata"
+	"github.com/apache/arrow/go/v12/arrow/memory"
 )
 
 type option struct {
@@ -62,9 +68,22 @@ func convOptions(incoming map[string]string, existing map[string]option) {
 	}
 }
 
-type Driver struct{}
+// Driver loads the driver given by the "driver" option, which is the
+// path or the name of a shared library, the path of a driver manifest
+// file (see Manifest), or the name of a driver whose manifest is found
+// in the search paths (see SearchPaths).
+type Driver struct {
+	// SearchPaths are the directories searched for driver manifests
+	// before the default ones
+	SearchPaths []string
+}
 
 func (d Driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	opts, notFound, resolveErr := resolveDriver(opts, d.SearchPaths)
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
 	dbOptions := make(map[string]option)
 	convOptions(opts, dbOptions)
 
@@ -89,6 +108,10 @@ func (d Driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
 
 	if code := adbc.Status(C.AdbcDatabaseInit(db.db, &err)); code != adbc.StatusOK {
 		errOut := toAdbcError(code, &err)
+		if notFound != "" {
+			// the library was looked up by name, having no manifest
+			errOut.(*adbc.Error).Msg += "\n" + notFound
+		}
 		C.AdbcDatabaseRelease(db.db, &err)
 		db.db = nil
 		return nil, errOut
@@ -198,7 +221,64 @@ func (c *cnxn) GetInfo(_ context.Context, infoCodes []adbc.InfoCode) (array.Reco
 		return nil, toAdbcError(code, &err)
 	}
 
-	return getRdr(&out), nil
+	return overrideCancel(getRdr(&out), infoCodes)
+}
+
+// overrideCancel reports cancellation as unsupported, whatever the
+// driver reports: the driver manager implements ADBC 1.0, which has no
+// AdbcConnectionCancel or AdbcStatementCancel, and the calls it makes
+// do not end with their context.
+//
+// The row of the driver for InfoFeatureCancel, if any, is replaced by
+// one reporting false, but only if the code is among the info codes
+// requested, or no codes were requested. Otherwise the reader is
+// returned as is.
+func overrideCancel(rdr array.RecordReader, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	const boolValTypeID arrow.UnionTypeCode = 1
+
+	requested := len(infoCodes) == 0
+	for _, code := range infoCodes {
+		requested = requested || code == adbc.InfoFeatureCancel
+	}
+	if !requested {
+		return rdr, nil
+	}
+	defer rdr.Release()
+
+	var recs []arrow.Record
+	defer func() {
+		for _, rec := range recs {
+			rec.Release()
+		}
+	}()
+
+	for rdr.Next() {
+		rec := rdr.Record()
+		codes := rec.Column(0).(*array.Uint32)
+		start := 0
+		for i := 0; i <= codes.Len(); i++ {
+			if i < codes.Len() && codes.Value(i) != uint32(adbc.InfoFeatureCancel) {
+				continue
+			}
+			if i > start {
+				recs = append(recs, rec.NewSlice(int64(start), int64(i)))
+			}
+			start = i + 1
+		}
+	}
+	if err := rdr.Err(); err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewRecordBuilder(memory.DefaultAllocator, rdr.Schema())
+	defer bldr.Release()
+	bldr.Field(0).(*array.Uint32Builder).Append(uint32(adbc.InfoFeatureCancel))
+	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
+	infoValueBldr.Append(boolValTypeID)
+	infoValueBldr.Child(int(boolValTypeID)).(*array.BooleanBuilder).Append(false)
+	recs = append(recs, bldr.NewRecord())
+
+	return array.NewRecordReader(rdr.Schema(), recs)
 }
 
 func (c *cnxn) GetObjects(_ context.Context, depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (array.RecordReader, error) {
@@ -214,11 +294,19 @@ func (c *cnxn) GetTableTypes(context.Context) (array.RecordReader, error) {
 }
 
 func (c *cnxn) Commit(context.Context) error {
-	return &adbc.Error{Code: adbc.StatusNotImplemented}
+	var err C.struct_AdbcError
+	if code := adbc.Status(C.AdbcConnectionCommit(c.conn, &err)); code != adbc.StatusOK {
+		return toAdbcError(code, &err)
+	}
+	return nil
 }
 
 func (c *cnxn) Rollback(context.Context) error {
-	return &adbc.Error{Code: adbc.StatusNotImplemented}
+	var err C.struct_AdbcError
+	if code := adbc.Status(C.AdbcConnectionRollback(c.conn, &err)); code != adbc.StatusOK {
+		return toAdbcError(code, &err)
+	}
+	return nil
 }
 
 func (c *cnxn) NewStatement() (adbc.Statement, error) {
@@ -240,7 +328,19 @@ func (c *cnxn) Close() error {
 }
 
 func (c *cnxn) ReadPartition(_ context.Context, serializedPartition []byte) (array.RecordReader, error) {
-	return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	var (
+		out C.struct_ArrowArrayStream
+		err C.struct_AdbcError
+	)
+	partition := C.CBytes(serializedPartition)
+	defer C.free(partition)
+
+	code := adbc.Status(C.AdbcConnectionReadPartition(c.conn, (*C.uint8_t)(partition),
+		C.size_t(len(serializedPartition)), &out, &err))
+	if code != adbc.StatusOK {
+		return nil, toAdbcError(code, &err)
+	}
+	return getRdr(&out), nil
 }
 
 func (c *cnxn) SetOption(key, value string) error {
@@ -356,9 +456,48 @@ func (s *stmt) BindStream(_ context.Context, stream array.RecordReader) error {
 }
 
 func (s *stmt) GetParameterSchema() (*arrow.Schema, error) {
-	return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	var (
+		schema C.struct_ArrowSchema
+		err    C.struct_AdbcError
+	)
+	if code := adbc.Status(C.AdbcStatementGetParameterSchema(s.st, &schema, &err)); code != adbc.StatusOK {
+		return nil, toAdbcError(code, &err)
+	}
+	return cdata.ImportCArrowSchema((*cdata.CArrowSchema)(unsafe.Pointer(&schema)))
 }
 
 func (s *stmt) ExecutePartitions(context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
-	return nil, adbc.Partitions{}, 0, &adbc.Error{Code: adbc.StatusNotImplemented}
+	var (
+		schema     C.struct_ArrowSchema
+		partitions C.struct_AdbcPartitions
+		affected   C.int64_t
+		err        C.struct_AdbcError
+	)
+	code := adbc.Status(C.AdbcStatementExecutePartitions(s.st, &schema, &partitions, &affected, &err))
+	if code != adbc.StatusOK {
+		return nil, adbc.Partitions{}, 0, toAdbcError(code, &err)
+	}
+	defer C.releasePartitions(&partitions)
+
+	// the partitions are copied, as they are released along with the
+	// C struct
+	n := int(partitions.num_partitions)
+	parts := adbc.Partitions{NumPartitions: uint64(n), PartitionIDs: make([][]byte, n)}
+	if n > 0 {
+		ptrs := unsafe.Slice(partitions.partitions, n)
+		lengths := unsafe.Slice(partitions.partition_lengths, n)
+		for i := range parts.PartitionIDs {
+			parts.PartitionIDs[i] = C.GoBytes(unsafe.Pointer(ptrs[i]), C.int(lengths[i]))
+		}
+	}
+
+	// the schema is optional
+	if schema.release == nil {
+		return nil, parts, int64(affected), nil
+	}
+	sc, e := cdata.ImportCArrowSchema((*cdata.CArrowSchema)(unsafe.Pointer(&schema)))
+	if e != nil {
+		return nil, adbc.Partitions{}, 0, e
+	}
+	return sc, parts, int64(affected), nil
 }
@@ -21,6 +21,8 @@ package drivermgr_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -98,6 +100,107 @@ func (dm *DriverMgrSuite) TestMetadataGetInfo() {
 	// TODO(apache/arrow-nanoarrow#76): values are not checked because go fails to import the union values
 }
 
+func (dm *DriverMgrSuite) TestMetadataGetInfoFeatures() {
+	// the features are those the driver reports, which the SQLite
+	// driver does not, but cancellation, which the driver manager
+	// cannot do
+	for _, codes := range [][]adbc.InfoCode{
+		{adbc.InfoDriverName, adbc.InfoFeatureTransactions, adbc.InfoFeatureCancel},
+		nil,
+	} {
+		rdr, err := dm.conn.GetInfo(dm.ctx, codes)
+		dm.Require().NoError(err)
+
+		features := make(map[adbc.InfoCode]bool)
+		names := 0
+		for rdr.Next() {
+			rec := rdr.Record()
+			infoCodes := rec.Column(0).(*array.Uint32)
+			values := rec.Column(1).(*array.DenseUnion)
+			for i := 0; i < infoCodes.Len(); i++ {
+				code := adbc.InfoCode(infoCodes.Value(i))
+				switch {
+				case code == adbc.InfoDriverName:
+					names++
+				case code >= adbc.InfoFeatureBulkIngestModes:
+					dm.Require().EqualValues(1, values.TypeCode(i), code.String())
+					features[code] = values.Field(1).(*array.Boolean).Value(int(values.ValueOffset(i)))
+				}
+			}
+		}
+		dm.NoError(rdr.Err())
+		rdr.Release()
+		dm.Equal(1, names)
+		dm.Equal(map[adbc.InfoCode]bool{adbc.InfoFeatureCancel: false}, features)
+	}
+
+	rdr, err := dm.conn.GetInfo(dm.ctx, []adbc.InfoCode{adbc.InfoDriverName})
+	dm.Require().NoError(err)
+	defer rdr.Release()
+	dm.Require().True(rdr.Next())
+	dm.EqualValues(1, rdr.Record().NumRows())
+}
+
+func (dm *DriverMgrSuite) TestTransactions() {
+	cnxn := dm.conn.(adbc.PostInitOptions)
+	dm.Require().NoError(cnxn.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+
+	exec := func(query string) {
+		st, err := dm.conn.NewStatement()
+		dm.Require().NoError(err)
+		defer st.Close()
+		dm.Require().NoError(st.SetSqlQuery(query))
+		_, err = st.ExecuteUpdate(dm.ctx)
+		dm.Require().NoError(err)
+	}
+	count := func() int64 {
+		st, err := dm.conn.NewStatement()
+		dm.Require().NoError(err)
+		defer st.Close()
+		dm.Require().NoError(st.SetSqlQuery("SELECT COUNT(*) FROM txn"))
+		rdr, _, err := st.ExecuteQuery(dm.ctx)
+		dm.Require().NoError(err)
+		defer rdr.Release()
+		dm.Require().True(rdr.Next())
+		return rdr.Record().Column(0).(*array.Int64).Value(0)
+	}
+
+	exec("CREATE TABLE txn (v INT)")
+	dm.Require().NoError(dm.conn.Commit(dm.ctx))
+	exec("INSERT INTO txn VALUES (1)")
+	dm.Require().NoError(dm.conn.Rollback(dm.ctx))
+	dm.EqualValues(0, count())
+	exec("INSERT INTO txn VALUES (1)")
+	dm.Require().NoError(dm.conn.Commit(dm.ctx))
+	dm.EqualValues(1, count())
+	dm.Require().NoError(cnxn.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueEnabled))
+}
+
+func (dm *DriverMgrSuite) TestSqlPrepareGetParameterSchema() {
+	st, err := dm.conn.NewStatement()
+	dm.Require().NoError(err)
+	defer st.Close()
+	dm.Require().NoError(st.SetSqlQuery("SELECT ?1, ?2"))
+	dm.Require().NoError(st.Prepare(dm.ctx))
+
+	sc, err := st.GetParameterSchema()
+	dm.Require().NoError(err)
+	dm.Equal([]string{"?1", "?2"}, []string{sc.Field(0).Name, sc.Field(1).Name})
+}
+
+func (dm *DriverMgrSuite) TestSqlPartitions() {
+	st, err := dm.conn.NewStatement()
+	dm.Require().NoError(err)
+	defer st.Close()
+	dm.Require().NoError(st.SetSqlQuery("SELECT 1"))
+
+	// the call reaches the driver, which has no partitioned results
+	_, _, _, err = st.ExecutePartitions(dm.ctx)
+	var adbcErr *adbc.Error
+	dm.Require().ErrorAs(err, &adbcErr)
+	dm.Equal(adbc.StatusNotImplemented, adbcErr.Code)
+}
+
 func (dm *DriverMgrSuite) TestSqlExecute() {
 	query := "SELECT 1"
 	st, err := dm.conn.NewStatement()
@@ -232,3 +335,75 @@ func TestDriverMgrCustomInitFunc(t *testing.T) {
 		assert.Contains(t, exp.Msg, "undefined symbol: ThisSymbolDoesNotExist")
 	}
 }
+
+func TestOpenSQLiteURI(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data.db")
+
+	exec := func(db adbc.Database, query string) {
+		cnxn, err := db.Open(ctx)
+		require.NoError(t, err)
+		defer cnxn.Close()
+		stmt, err := cnxn.NewStatement()
+		require.NoError(t, err)
+		defer stmt.Close()
+		require.NoError(t, stmt.SetSqlQuery(query))
+		_, err = stmt.ExecuteUpdate(ctx)
+		require.NoError(t, err)
+	}
+
+	db, err := adbc.Open(ctx, "sqlite://"+filepath.ToSlash(path), nil)
+	require.NoError(t, err)
+	exec(db, "CREATE TABLE foo (id INTEGER)")
+	assert.FileExists(t, path)
+
+	// a read-only database is opened through the query of the URI
+	db, err = adbc.Open(ctx, "sqlite://"+filepath.ToSlash(path)+"?mode=ro", nil)
+	require.NoError(t, err)
+	cnxn, err := db.Open(ctx)
+	require.NoError(t, err)
+	defer cnxn.Close()
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	require.NoError(t, stmt.SetSqlQuery("INSERT INTO foo VALUES (1)"))
+	_, err = stmt.ExecuteUpdate(ctx)
+	assert.Error(t, err)
+
+	// without a path, the in-memory database of the driver is used
+	db, err = adbc.Open(ctx, "sqlite://", nil)
+	require.NoError(t, err)
+	exec(db, "SELECT 1")
+}
+
+func TestDriverMgrManifest(t *testing.T) {
+	var library string
+	for _, dir := range filepath.SplitList(os.Getenv("LD_LIBRARY_PATH")) {
+		path := filepath.Join(dir, "libadbc_driver_sqlite.so")
+		if _, err := os.Stat(path); dir != "" && err == nil {
+			library = path
+			break
+		}
+	}
+	if library == "" {
+		t.Skip("libadbc_driver_sqlite.so not found in LD_LIBRARY_PATH")
+	}
+
+	dir := t.TempDir()
+	manifest := "name = \"SQLite\"\n[driver]\nlibrary = '" + library + "'\nentrypoint = 'AdbcDriverInit'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sqlite_manifest.toml"), []byte(manifest), 0644))
+
+	drv := drivermgr.Driver{SearchPaths: []string{dir}}
+	db, err := drv.NewDatabase(map[string]string{"driver": "sqlite_manifest"})
+	require.NoError(t, err)
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, cnxn.Close())
+
+	// without a manifest, the error lists the locations tried
+	_, err = drv.NewDatabase(map[string]string{"driver": "no_such_driver"})
+	var exp *adbc.Error
+	require.ErrorAs(t, err, &exp)
+	assert.Contains(t, exp.Msg, "no manifest found for driver 'no_such_driver'")
+	assert.Contains(t, exp.Msg, filepath.Join(dir, "no_such_driver.toml"))
+}
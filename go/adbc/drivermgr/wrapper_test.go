@@ -0,0 +1,108 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build cgo
+
+package drivermgr
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// infoReader returns a reader of GetInfo rows with boolean values.
+func infoReader(t *testing.T, alloc memory.Allocator, rows map[adbc.InfoCode]bool, order ...adbc.InfoCode) array.RecordReader {
+	const boolValTypeID arrow.UnionTypeCode = 1
+
+	bldr := array.NewRecordBuilder(alloc, adbc.GetInfoSchema)
+	defer bldr.Release()
+	infoValueBldr := bldr.Field(1).(*array.DenseUnionBuilder)
+	for _, code := range order {
+		bldr.Field(0).(*array.Uint32Builder).Append(uint32(code))
+		infoValueBldr.Append(boolValTypeID)
+		infoValueBldr.Child(int(boolValTypeID)).(*array.BooleanBuilder).Append(rows[code])
+	}
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	rdr, err := array.NewRecordReader(adbc.GetInfoSchema, []arrow.Record{rec})
+	require.NoError(t, err)
+	return rdr
+}
+
+// readInfo returns the boolean values of the rows, failing on
+// duplicate codes.
+func readInfo(t *testing.T, rdr array.RecordReader) map[adbc.InfoCode]bool {
+	defer rdr.Release()
+	out := make(map[adbc.InfoCode]bool)
+	for rdr.Next() {
+		rec := rdr.Record()
+		codes := rec.Column(0).(*array.Uint32)
+		values := rec.Column(1).(*array.DenseUnion)
+		for i := 0; i < codes.Len(); i++ {
+			code := adbc.InfoCode(codes.Value(i))
+			require.NotContains(t, out, code)
+			out[code] = values.Field(int(values.ChildID(i))).(*array.Boolean).Value(int(values.ValueOffset(i)))
+		}
+	}
+	require.NoError(t, rdr.Err())
+	return out
+}
+
+func TestOverrideCancel(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer alloc.AssertSize(t, 0)
+
+	driverRows := map[adbc.InfoCode]bool{
+		adbc.InfoFeaturePartitions:   true,
+		adbc.InfoFeatureCancel:       true,
+		adbc.InfoFeatureTransactions: true,
+	}
+	order := []adbc.InfoCode{adbc.InfoFeaturePartitions, adbc.InfoFeatureCancel, adbc.InfoFeatureTransactions}
+
+	tests := []struct {
+		name     string
+		codes    []adbc.InfoCode
+		reported []adbc.InfoCode
+		expected map[adbc.InfoCode]bool
+	}{
+		{"all", nil, order, map[adbc.InfoCode]bool{
+			adbc.InfoFeaturePartitions:   true,
+			adbc.InfoFeatureCancel:       false,
+			adbc.InfoFeatureTransactions: true,
+		}},
+		{"requested", []adbc.InfoCode{adbc.InfoFeatureCancel}, []adbc.InfoCode{adbc.InfoFeatureCancel},
+			map[adbc.InfoCode]bool{adbc.InfoFeatureCancel: false}},
+		{"not reported", []adbc.InfoCode{adbc.InfoFeaturePartitions, adbc.InfoFeatureCancel}, []adbc.InfoCode{adbc.InfoFeaturePartitions},
+			map[adbc.InfoCode]bool{adbc.InfoFeaturePartitions: true, adbc.InfoFeatureCancel: false}},
+		{"not requested", []adbc.InfoCode{adbc.InfoFeaturePartitions}, []adbc.InfoCode{adbc.InfoFeaturePartitions},
+			map[adbc.InfoCode]bool{adbc.InfoFeaturePartitions: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdr, err := overrideCancel(infoReader(t, alloc, driverRows, tt.reported...), tt.codes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, readInfo(t, rdr))
+		})
+	}
+}
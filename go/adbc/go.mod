@@ -20,6 +20,7 @@ module github.com/apache/arrow-adbc/go/adbc
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/apache/arrow/go/v12 v12.0.0
 	github.com/bluele/gcache v0.0.2
 	github.com/google/uuid v1.3.0
@@ -30,6 +31,7 @@ require (
 	golang.org/x/tools v0.9.1
 	google.golang.org/grpc v1.54.0
 	google.golang.org/protobuf v1.30.0
+	modernc.org/sqlite v1.21.2
 )
 
 require (
@@ -99,7 +101,6 @@ require (
 	modernc.org/mathutil v1.5.0 // indirect
 	modernc.org/memory v1.5.0 // indirect
 	modernc.org/opt v0.1.3 // indirect
-	modernc.org/sqlite v1.21.2 // indirect
 	modernc.org/strutil v1.1.3 // indirect
 	modernc.org/token v1.1.0 // indirect
 )
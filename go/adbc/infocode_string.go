@@ -11,28 +11,44 @@ func _() {
 	_ = x[InfoVendorName-0]
 	_ = x[InfoVendorVersion-1]
 	_ = x[InfoVendorArrowVersion-2]
+	_ = x[InfoVendorSql-3]
+	_ = x[InfoVendorSubstrait-4]
+	_ = x[InfoVendorSubstraitMinVersion-5]
+	_ = x[InfoVendorSubstraitMaxVersion-6]
 	_ = x[InfoDriverName-100]
 	_ = x[InfoDriverVersion-101]
 	_ = x[InfoDriverArrowVersion-102]
+	_ = x[InfoFeatureBulkIngestModes-20000]
+	_ = x[InfoFeaturePartitions-20001]
+	_ = x[InfoFeatureTransactions-20002]
+	_ = x[InfoFeatureSavepoints-20003]
+	_ = x[InfoFeatureCancel-20004]
+	_ = x[InfoFeatureParameterSchema-20005]
+	_ = x[InfoFeatureIsolationLevels-20006]
 }
 
 const (
-	_InfoCode_name_0 = "VendorNameVendorVersionVendorArrowVersion"
+	_InfoCode_name_0 = "VendorNameVendorVersionVendorArrowVersionVendorSqlVendorSubstraitVendorSubstraitMinVersionVendorSubstraitMaxVersion"
 	_InfoCode_name_1 = "DriverNameDriverVersionDriverArrowVersion"
+	_InfoCode_name_2 = "FeatureBulkIngestModesFeaturePartitionsFeatureTransactionsFeatureSavepointsFeatureCancelFeatureParameterSchemaFeatureIsolationLevels"
 )
 
 var (
-	_InfoCode_index_0 = [...]uint8{0, 10, 23, 41}
+	_InfoCode_index_0 = [...]uint8{0, 10, 23, 41, 50, 65, 90, 115}
 	_InfoCode_index_1 = [...]uint8{0, 10, 23, 41}
+	_InfoCode_index_2 = [...]uint8{0, 22, 39, 58, 75, 88, 110, 132}
 )
 
 func (i InfoCode) String() string {
 	switch {
-	case i <= 2:
+	case i <= 6:
 		return _InfoCode_name_0[_InfoCode_index_0[i]:_InfoCode_index_0[i+1]]
 	case 100 <= i && i <= 102:
 		i -= 100
 		return _InfoCode_name_1[_InfoCode_index_1[i]:_InfoCode_index_1[i+1]]
+	case 20000 <= i && i <= 20006:
+		i -= 20000
+		return _InfoCode_name_2[_InfoCode_index_2[i]:_InfoCode_index_2[i+1]]
 	default:
 		return "InfoCode(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
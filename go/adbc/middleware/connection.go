@@ -0,0 +1,162 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type cnxn struct {
+	wrapped      adbc.Connection
+	interceptors chain
+}
+
+// WrapConnection returns a connection passing the calls made on it, and
+// on the statements it creates, through the interceptors. It always
+// satisfies adbc.PostInitOptions and adbc.ConnectionGetStatistics; the
+// calls fail with adbc.StatusNotImplemented if the wrapped connection
+// doesn't support them.
+func WrapConnection(c adbc.Connection, interceptors ...Interceptor) adbc.Connection {
+	return &cnxn{wrapped: c, interceptors: interceptors}
+}
+
+func (c *cnxn) GetInfo(ctx context.Context, infoCodes []adbc.InfoCode) (array.RecordReader, error) {
+	call := &Call{Method: MethodGetInfo}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = c.wrapped.GetInfo(ctx, infoCodes)
+		return
+	})
+	return call.Reader, err
+}
+
+func (c *cnxn) GetObjects(ctx context.Context, depth adbc.ObjectDepth, catalog, dbSchema, tableName, columnName *string, tableType []string) (array.RecordReader, error) {
+	call := &Call{Method: MethodGetObjects}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = c.wrapped.GetObjects(ctx, depth, catalog, dbSchema, tableName, columnName, tableType)
+		return
+	})
+	return call.Reader, err
+}
+
+func (c *cnxn) GetTableSchema(ctx context.Context, catalog, dbSchema *string, tableName string) (*arrow.Schema, error) {
+	call := &Call{Method: MethodGetTableSchema}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Schema, err = c.wrapped.GetTableSchema(ctx, catalog, dbSchema, tableName)
+		return
+	})
+	return call.Schema, err
+}
+
+func (c *cnxn) GetTableTypes(ctx context.Context) (array.RecordReader, error) {
+	call := &Call{Method: MethodGetTableTypes}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = c.wrapped.GetTableTypes(ctx)
+		return
+	})
+	return call.Reader, err
+}
+
+func (c *cnxn) Commit(ctx context.Context) error {
+	return c.interceptors.invoke(ctx, &Call{Method: MethodCommit}, func(ctx context.Context, _ *Call) error {
+		return c.wrapped.Commit(ctx)
+	})
+}
+
+func (c *cnxn) Rollback(ctx context.Context) error {
+	return c.interceptors.invoke(ctx, &Call{Method: MethodRollback}, func(ctx context.Context, _ *Call) error {
+		return c.wrapped.Rollback(ctx)
+	})
+}
+
+func (c *cnxn) NewStatement() (adbc.Statement, error) {
+	call := &Call{Method: MethodNewStatement}
+	err := c.interceptors.invoke(context.Background(), call, func(_ context.Context, call *Call) (err error) {
+		call.stmt, err = c.wrapped.NewStatement()
+		return
+	})
+	if err != nil {
+		// an interceptor failed the call after the statement was created
+		if call.stmt != nil {
+			call.stmt.Close()
+		}
+		return nil, err
+	}
+	return wrapStatement(call.stmt, c.interceptors), nil
+}
+
+func (c *cnxn) Close() error {
+	return c.interceptors.invoke(context.Background(), &Call{Method: MethodCnxnClose}, func(context.Context, *Call) error {
+		return c.wrapped.Close()
+	})
+}
+
+func (c *cnxn) ReadPartition(ctx context.Context, serializedPartition []byte) (array.RecordReader, error) {
+	call := &Call{Method: MethodReadPartition}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = c.wrapped.ReadPartition(ctx, serializedPartition)
+		return
+	})
+	return call.Reader, err
+}
+
+func (c *cnxn) SetOption(key, value string) error {
+	opts, ok := c.wrapped.(adbc.PostInitOptions)
+	if !ok {
+		return &adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	call := &Call{Method: MethodCnxnSetOption, Key: key, Value: value}
+	return c.interceptors.invoke(context.Background(), call, func(_ context.Context, call *Call) error {
+		return opts.SetOption(call.Key, call.Value)
+	})
+}
+
+func (c *cnxn) GetStatistics(ctx context.Context, catalog, dbSchema, tableName *string, approximate bool) (array.RecordReader, error) {
+	stats, ok := c.wrapped.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	call := &Call{Method: MethodGetStatistics}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = stats.GetStatistics(ctx, catalog, dbSchema, tableName, approximate)
+		return
+	})
+	return call.Reader, err
+}
+
+func (c *cnxn) GetStatisticNames(ctx context.Context) (array.RecordReader, error) {
+	stats, ok := c.wrapped.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	call := &Call{Method: MethodGetStatisticNames}
+	err := c.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, err = stats.GetStatisticNames(ctx)
+		return
+	})
+	return call.Reader, err
+}
+
+var (
+	_ adbc.Connection              = (*cnxn)(nil)
+	_ adbc.PostInitOptions         = (*cnxn)(nil)
+	_ adbc.ConnectionGetStatistics = (*cnxn)(nil)
+)
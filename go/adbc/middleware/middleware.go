@@ -0,0 +1,195 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package middleware wraps ADBC drivers, databases and connections so
+// that calls made through them pass through a chain of interceptors,
+// in the style of gRPC interceptors. An interceptor sees every call
+// before and after it reaches the wrapped object and can log it,
+// reject it, or change its arguments and results:
+//
+//	audit := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+//		err := next(ctx, call)
+//		log.Printf("%s %q: %v", call.Method, call.Query, err)
+//		return err
+//	}
+//	drv := middleware.WrapDriver(flightsql.NewDriver(mem), audit, rowLimit)
+//
+// Interceptors run in the order given, the first being the outermost.
+// The objects returned by a wrapped object are wrapped in turn. They
+// always satisfy the optional interfaces, such as adbc.PostInitOptions,
+// and fail the calls with adbc.StatusNotImplemented when the objects
+// they wrap don't support them.
+package middleware
+
+import (
+	"context"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// The methods which are intercepted, as given by Call.Method. Other
+// methods are forwarded to the wrapped object directly.
+const (
+	MethodOpen = "Database.Open"
+
+	MethodGetInfo        = "Connection.GetInfo"
+	MethodGetObjects     = "Connection.GetObjects"
+	MethodGetTableSchema = "Connection.GetTableSchema"
+	MethodGetTableTypes  = "Connection.GetTableTypes"
+	MethodCommit         = "Connection.Commit"
+	MethodRollback       = "Connection.Rollback"
+	MethodNewStatement   = "Connection.NewStatement"
+	MethodReadPartition  = "Connection.ReadPartition"
+	MethodCnxnSetOption  = "Connection.SetOption"
+	MethodCnxnClose      = "Connection.Close"
+
+	MethodGetStatistics     = "Connection.GetStatistics"
+	MethodGetStatisticNames = "Connection.GetStatisticNames"
+
+	MethodSetSqlQuery       = "Statement.SetSqlQuery"
+	MethodStmtSetOption     = "Statement.SetOption"
+	MethodPrepare           = "Statement.Prepare"
+	MethodBind              = "Statement.Bind"
+	MethodBindStream        = "Statement.BindStream"
+	MethodExecuteQuery      = "Statement.ExecuteQuery"
+	MethodExecuteUpdate     = "Statement.ExecuteUpdate"
+	MethodExecutePartitions = "Statement.ExecutePartitions"
+	MethodExecuteSchema     = "Statement.ExecuteSchema"
+	MethodExecuteMulti      = "Statement.ExecuteMulti"
+	MethodStmtClose         = "Statement.Close"
+
+	MethodNextResult = "MultiResult.NextResult"
+)
+
+// Call is an intercepted call. Interceptors may change the arguments
+// before calling the next handler, and the results after it returns.
+type Call struct {
+	// Method is the name of the method called, one of the Method
+	// constants.
+	Method string
+
+	// Query is the query of Statement.SetSqlQuery, which is passed on
+	// to the statement as the interceptors leave it. For the other
+	// statement methods and MultiResult.NextResult it is the query
+	// last set on the statement, changing it then has no effect.
+	Query string
+	// Key and Value are the option set by SetOption.
+	Key, Value string
+	// Values are the parameters of Statement.Bind.
+	Values arrow.Record
+	// Stream are the parameters of Statement.BindStream.
+	Stream array.RecordReader
+
+	// Reader is the result of the methods returning a record reader:
+	// Statement.ExecuteQuery, MultiResult.NextResult,
+	// Connection.GetInfo, GetObjects, GetTableTypes, GetStatistics,
+	// GetStatisticNames and ReadPartition. An interceptor replacing it
+	// is responsible for releasing the original reader.
+	Reader array.RecordReader
+	// Schema is the result of Connection.GetTableSchema,
+	// Statement.ExecutePartitions and ExecuteSchema.
+	Schema *arrow.Schema
+	// RowsAffected is the result of Statement.ExecuteQuery,
+	// ExecuteUpdate, ExecutePartitions and MultiResult.NextResult, -1
+	// if unknown.
+	RowsAffected int64
+
+	cnxn       adbc.Connection
+	stmt       adbc.Statement
+	partitions adbc.Partitions
+	results    adbc.MultiResult
+}
+
+// Handler performs a call, filling in its results.
+type Handler func(ctx context.Context, call *Call) error
+
+// Interceptor is called in place of the handler of a call, which it
+// calls itself through next, unless it fails the call.
+type Interceptor func(ctx context.Context, call *Call, next Handler) error
+
+// chain calls the interceptors around the final handler
+type chain []Interceptor
+
+func (c chain) invoke(ctx context.Context, call *Call, final Handler) error {
+	h := final
+	for i := len(c) - 1; i >= 0; i-- {
+		interceptor, next := c[i], h
+		h = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return h(ctx, call)
+}
+
+type driver struct {
+	wrapped      adbc.Driver
+	interceptors chain
+}
+
+// WrapDriver returns a driver whose databases pass the calls made on
+// them and on the connections and statements they create through the
+// interceptors.
+func WrapDriver(drv adbc.Driver, interceptors ...Interceptor) adbc.Driver {
+	return &driver{wrapped: drv, interceptors: interceptors}
+}
+
+func (d *driver) NewDatabase(opts map[string]string) (adbc.Database, error) {
+	db, err := d.wrapped.NewDatabase(opts)
+	if err != nil {
+		return nil, err
+	}
+	return WrapDatabase(db, d.interceptors...), nil
+}
+
+type database struct {
+	wrapped      adbc.Database
+	interceptors chain
+}
+
+// WrapDatabase returns a database passing calls to Open, and the calls
+// made on the connections and statements it creates, through the
+// interceptors.
+func WrapDatabase(db adbc.Database, interceptors ...Interceptor) adbc.Database {
+	return &database{wrapped: db, interceptors: interceptors}
+}
+
+func (d *database) SetOptions(opts map[string]string) error {
+	return d.wrapped.SetOptions(opts)
+}
+
+func (d *database) Open(ctx context.Context) (adbc.Connection, error) {
+	call := &Call{Method: MethodOpen}
+	err := d.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.cnxn, err = d.wrapped.Open(ctx)
+		return
+	})
+	if err != nil {
+		// an interceptor failed the call after the connection was opened
+		if call.cnxn != nil {
+			call.cnxn.Close()
+		}
+		return nil, err
+	}
+	return WrapConnection(call.cnxn, d.interceptors...), nil
+}
+
+var (
+	_ adbc.Driver   = (*driver)(nil)
+	_ adbc.Database = (*database)(nil)
+)
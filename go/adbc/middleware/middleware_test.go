@@ -0,0 +1,353 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/adbcmock"
+	"github.com/apache/arrow-adbc/go/adbc/middleware"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T, mock *adbcmock.Mock, interceptors ...middleware.Interceptor) adbc.Connection {
+	db, err := middleware.WrapDriver(mock, interceptors...).NewDatabase(nil)
+	require.NoError(t, err)
+	cnxn, err := db.Open(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, cnxn.Close()) })
+	return cnxn
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) middleware.Interceptor {
+		return func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+			calls = append(calls, name+" "+call.Method)
+			defer func() { calls = append(calls, name+" done") }()
+			return next(ctx, call)
+		}
+	}
+
+	mock := adbcmock.New()
+	mock.ExpectExec("UPDATE t SET x = 1").WillReturnResult(3)
+	cnxn := open(t, mock, record("outer"), record("inner"))
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	calls = nil
+	require.NoError(t, stmt.SetSqlQuery("UPDATE t SET x = 1"))
+	n, err := stmt.ExecuteUpdate(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, n)
+	require.NoError(t, stmt.Close())
+
+	assert.Equal(t, []string{
+		"outer Statement.SetSqlQuery", "inner Statement.SetSqlQuery", "inner done", "outer done",
+		"outer Statement.ExecuteUpdate", "inner Statement.ExecuteUpdate", "inner done", "outer done",
+		"outer Statement.Close", "inner Statement.Close", "inner done", "outer done",
+	}, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRewriteAndReject(t *testing.T) {
+	rewrite := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		if call.Method == middleware.MethodSetSqlQuery {
+			call.Query = strings.ReplaceAll(call.Query, "users", "tenant_a.users")
+		}
+		return next(ctx, call)
+	}
+	errDenied := adbc.Error{Msg: "denied", Code: adbc.StatusUnauthorized}
+	policy := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		if call.Method == middleware.MethodExecuteUpdate && strings.HasPrefix(call.Query, "DROP") {
+			return errDenied
+		}
+		return next(ctx, call)
+	}
+
+	mock := adbcmock.New()
+	mock.ExpectQuery(`SELECT \* FROM tenant_a\.users`).WillReturnRowsAffected(5)
+	cnxn := open(t, mock, rewrite, policy)
+
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery("SELECT * FROM users"))
+	rdr, n, err := stmt.ExecuteQuery(context.Background())
+	require.NoError(t, err)
+	rdr.Release()
+	assert.EqualValues(t, 5, n)
+
+	require.NoError(t, stmt.SetSqlQuery("DROP TABLE users"))
+	_, err = stmt.ExecuteUpdate(context.Background())
+	assert.ErrorIs(t, err, errDenied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceResult(t *testing.T) {
+	sc := arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	empty, err := array.NewRecordReader(sc, nil)
+	require.NoError(t, err)
+
+	replace := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		if err := next(ctx, call); err != nil {
+			return err
+		}
+		if call.Method == middleware.MethodGetTableTypes {
+			call.Reader.Release()
+			call.Reader = empty
+		}
+		return nil
+	}
+
+	mock := adbcmock.New()
+	mock.ExpectGetTableTypes()
+	cnxn := open(t, mock, replace)
+
+	rdr, err := cnxn.GetTableTypes(context.Background())
+	require.NoError(t, err)
+	defer rdr.Release()
+	assert.Same(t, empty, rdr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenFailure(t *testing.T) {
+	errBoom := errors.New("boom")
+	mock := adbcmock.New()
+	mock.ExpectOpen().WillReturnError(errBoom)
+
+	seen := false
+	db, err := middleware.WrapDriver(mock, func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		seen = call.Method == middleware.MethodOpen
+		return next(ctx, call)
+	}).NewDatabase(nil)
+	require.NoError(t, err)
+
+	_, err = db.Open(context.Background())
+	assert.ErrorIs(t, err, errBoom)
+	assert.True(t, seen)
+}
+
+// hideOptions hides the SetOption method of a connection
+type hideOptions struct {
+	adbc.Connection
+}
+
+func TestPostInitOptions(t *testing.T) {
+	mock := adbcmock.New()
+	mock.ExpectSetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled)
+	var keys []string
+	cnxn := open(t, mock, func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		if call.Method == middleware.MethodCnxnSetOption {
+			keys = append(keys, call.Key)
+		}
+		return next(ctx, call)
+	})
+
+	opts, ok := cnxn.(adbc.PostInitOptions)
+	require.True(t, ok)
+	require.NoError(t, opts.SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+	assert.Equal(t, []string{adbc.OptionKeyAutoCommit}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	wrapped := middleware.WrapConnection(hideOptions{cnxn})
+	assertNotImplemented(t, wrapped.(adbc.PostInitOptions).SetOption(adbc.OptionKeyAutoCommit, adbc.OptionValueDisabled))
+}
+
+func assertNotImplemented(t *testing.T, err error) {
+	t.Helper()
+	var adbcErr *adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotImplemented, adbcErr.Code)
+}
+
+type withStatistics struct {
+	adbc.Connection
+}
+
+func (withStatistics) GetStatistics(context.Context, *string, *string, *string, bool) (array.RecordReader, error) {
+	return nil, adbc.Error{Code: adbc.StatusNotFound}
+}
+
+func (withStatistics) GetStatisticNames(context.Context) (array.RecordReader, error) {
+	return nil, adbc.Error{Code: adbc.StatusNotFound}
+}
+
+func TestGetStatistics(t *testing.T) {
+	var methods []string
+	record := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		methods = append(methods, call.Method)
+		return next(ctx, call)
+	}
+
+	wrapped := middleware.WrapConnection(withStatistics{hideOptions{}}, record)
+	assertNotImplemented(t, wrapped.(adbc.PostInitOptions).SetOption("key", "value"))
+	stats, ok := wrapped.(adbc.ConnectionGetStatistics)
+	require.True(t, ok)
+
+	var adbcErr adbc.Error
+	_, err := stats.GetStatistics(context.Background(), nil, nil, nil, true)
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+	_, err = stats.GetStatisticNames(context.Background())
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusNotFound, adbcErr.Code)
+	assert.Equal(t, []string{middleware.MethodGetStatistics, middleware.MethodGetStatisticNames}, methods)
+
+	// not supported by the wrapped connection, so not intercepted
+	methods = nil
+	stats = middleware.WrapConnection(hideOptions{}, record).(adbc.ConnectionGetStatistics)
+	_, err = stats.GetStatistics(context.Background(), nil, nil, nil, true)
+	assertNotImplemented(t, err)
+	_, err = stats.GetStatisticNames(context.Background())
+	assertNotImplemented(t, err)
+	assert.Empty(t, methods)
+}
+
+type withExecuteSchema struct {
+	adbc.Connection
+}
+
+func (c withExecuteSchema) NewStatement() (adbc.Statement, error) {
+	stmt, err := c.Connection.NewStatement()
+	return schemaStatement{stmt}, err
+}
+
+type schemaStatement struct {
+	adbc.Statement
+}
+
+func (schemaStatement) ExecuteSchema(context.Context) (*arrow.Schema, error) {
+	return arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.PrimitiveTypes.Int64}}, nil), nil
+}
+
+func TestExecuteSchema(t *testing.T) {
+	var calls []string
+	record := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		calls = append(calls, call.Method+" "+call.Query)
+		return next(ctx, call)
+	}
+
+	cnxn := middleware.WrapConnection(withExecuteSchema{open(t, adbcmock.New())}, record)
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	require.NoError(t, stmt.SetSqlQuery("SELECT a FROM t"))
+	es, ok := stmt.(adbc.StatementExecuteSchema)
+	require.True(t, ok)
+	sc, err := es.ExecuteSchema(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "a", sc.Field(0).Name)
+	assert.Equal(t, []string{
+		"Connection.NewStatement ", "Statement.SetSqlQuery SELECT a FROM t",
+		"Statement.ExecuteSchema SELECT a FROM t",
+	}, calls)
+
+	// the mock statements don't implement it
+	stmt, err = open(t, adbcmock.New()).NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.(adbc.StatementExecuteSchema).ExecuteSchema(context.Background())
+	assertNotImplemented(t, err)
+}
+
+type withExecuteMulti struct {
+	adbc.Connection
+}
+
+func (c withExecuteMulti) NewStatement() (adbc.Statement, error) {
+	stmt, err := c.Connection.NewStatement()
+	return multiStatement{stmt}, err
+}
+
+type multiStatement struct {
+	adbc.Statement
+}
+
+func (multiStatement) ExecuteMulti(context.Context) (adbc.MultiResult, error) {
+	return &twoResults{}, nil
+}
+
+// twoResults returns two empty results affecting 1 and 2 rows
+type twoResults struct {
+	n int64
+}
+
+func (r *twoResults) NextResult(context.Context) (array.RecordReader, int64, error) {
+	if r.n == 2 {
+		return nil, -1, io.EOF
+	}
+	r.n++
+	rdr, err := array.NewRecordReader(arrow.NewSchema(nil, nil), nil)
+	return rdr, r.n, err
+}
+
+func (r *twoResults) Close() error { return nil }
+
+func TestExecuteMulti(t *testing.T) {
+	var calls []string
+	record := func(ctx context.Context, call *middleware.Call, next middleware.Handler) error {
+		err := next(ctx, call)
+		calls = append(calls, call.Method+" "+call.Query)
+		if call.Method == middleware.MethodNextResult && err == nil {
+			call.RowsAffected *= 10
+		}
+		return err
+	}
+
+	cnxn := middleware.WrapConnection(withExecuteMulti{open(t, adbcmock.New())}, record)
+	stmt, err := cnxn.NewStatement()
+	require.NoError(t, err)
+	defer stmt.Close()
+	_, err = stmt.(adbc.StatementExecuteSchema).ExecuteSchema(context.Background())
+	assertNotImplemented(t, err)
+
+	require.NoError(t, stmt.SetSqlQuery("INSERT 1; INSERT 2"))
+	em, ok := stmt.(adbc.StatementExecuteMulti)
+	require.True(t, ok)
+	calls = nil
+	res, err := em.ExecuteMulti(context.Background())
+	require.NoError(t, err)
+
+	for _, expected := range []int64{10, 20} {
+		rdr, n, err := res.NextResult(context.Background())
+		require.NoError(t, err)
+		rdr.Release()
+		assert.Equal(t, expected, n)
+	}
+	_, _, err = res.NextResult(context.Background())
+	assert.ErrorIs(t, err, io.EOF)
+	require.NoError(t, res.Close())
+
+	assert.Equal(t, []string{
+		"Statement.ExecuteMulti INSERT 1; INSERT 2",
+		"MultiResult.NextResult INSERT 1; INSERT 2",
+		"MultiResult.NextResult INSERT 1; INSERT 2",
+		"MultiResult.NextResult INSERT 1; INSERT 2",
+	}, calls)
+}
@@ -0,0 +1,180 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+type statement struct {
+	wrapped      adbc.Statement
+	interceptors chain
+	// query is the query last set, for the calls executing it
+	query string
+}
+
+func wrapStatement(st adbc.Statement, interceptors chain) adbc.Statement {
+	return &statement{wrapped: st, interceptors: interceptors}
+}
+
+func (s *statement) Close() error {
+	return s.interceptors.invoke(context.Background(), &Call{Method: MethodStmtClose, Query: s.query}, func(context.Context, *Call) error {
+		return s.wrapped.Close()
+	})
+}
+
+func (s *statement) SetOption(key, val string) error {
+	call := &Call{Method: MethodStmtSetOption, Query: s.query, Key: key, Value: val}
+	return s.interceptors.invoke(context.Background(), call, func(_ context.Context, call *Call) error {
+		return s.wrapped.SetOption(call.Key, call.Value)
+	})
+}
+
+func (s *statement) SetSqlQuery(query string) error {
+	call := &Call{Method: MethodSetSqlQuery, Query: query}
+	return s.interceptors.invoke(context.Background(), call, func(_ context.Context, call *Call) error {
+		if err := s.wrapped.SetSqlQuery(call.Query); err != nil {
+			return err
+		}
+		s.query = call.Query
+		return nil
+	})
+}
+
+func (s *statement) ExecuteQuery(ctx context.Context) (array.RecordReader, int64, error) {
+	call := &Call{Method: MethodExecuteQuery, Query: s.query, RowsAffected: -1}
+	err := s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, call.RowsAffected, err = s.wrapped.ExecuteQuery(ctx)
+		return
+	})
+	return call.Reader, call.RowsAffected, err
+}
+
+func (s *statement) ExecuteUpdate(ctx context.Context) (int64, error) {
+	call := &Call{Method: MethodExecuteUpdate, Query: s.query, RowsAffected: -1}
+	err := s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.RowsAffected, err = s.wrapped.ExecuteUpdate(ctx)
+		return
+	})
+	return call.RowsAffected, err
+}
+
+func (s *statement) Prepare(ctx context.Context) error {
+	return s.interceptors.invoke(ctx, &Call{Method: MethodPrepare, Query: s.query}, func(ctx context.Context, _ *Call) error {
+		return s.wrapped.Prepare(ctx)
+	})
+}
+
+// SetSubstraitPlan is not intercepted, the plan replaces the query.
+func (s *statement) SetSubstraitPlan(plan []byte) error {
+	if err := s.wrapped.SetSubstraitPlan(plan); err != nil {
+		return err
+	}
+	s.query = ""
+	return nil
+}
+
+func (s *statement) Bind(ctx context.Context, values arrow.Record) error {
+	call := &Call{Method: MethodBind, Query: s.query, Values: values}
+	return s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) error {
+		return s.wrapped.Bind(ctx, call.Values)
+	})
+}
+
+func (s *statement) BindStream(ctx context.Context, stream array.RecordReader) error {
+	call := &Call{Method: MethodBindStream, Query: s.query, Stream: stream}
+	return s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) error {
+		return s.wrapped.BindStream(ctx, call.Stream)
+	})
+}
+
+func (s *statement) GetParameterSchema() (*arrow.Schema, error) {
+	return s.wrapped.GetParameterSchema()
+}
+
+func (s *statement) ExecutePartitions(ctx context.Context) (*arrow.Schema, adbc.Partitions, int64, error) {
+	call := &Call{Method: MethodExecutePartitions, Query: s.query, RowsAffected: -1}
+	err := s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Schema, call.partitions, call.RowsAffected, err = s.wrapped.ExecutePartitions(ctx)
+		return
+	})
+	return call.Schema, call.partitions, call.RowsAffected, err
+}
+
+func (s *statement) ExecuteSchema(ctx context.Context) (*arrow.Schema, error) {
+	es, ok := s.wrapped.(adbc.StatementExecuteSchema)
+	if !ok {
+		return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	call := &Call{Method: MethodExecuteSchema, Query: s.query}
+	err := s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Schema, err = es.ExecuteSchema(ctx)
+		return
+	})
+	return call.Schema, err
+}
+
+func (s *statement) ExecuteMulti(ctx context.Context) (adbc.MultiResult, error) {
+	em, ok := s.wrapped.(adbc.StatementExecuteMulti)
+	if !ok {
+		return nil, &adbc.Error{Code: adbc.StatusNotImplemented}
+	}
+	call := &Call{Method: MethodExecuteMulti, Query: s.query}
+	err := s.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.results, err = em.ExecuteMulti(ctx)
+		return
+	})
+	if err != nil {
+		// an interceptor failed the call after the statement was executed
+		if call.results != nil {
+			call.results.Close()
+		}
+		return nil, err
+	}
+	return &multiResult{wrapped: call.results, interceptors: s.interceptors, query: s.query}, nil
+}
+
+// multiResult passes the calls to NextResult through the interceptors
+type multiResult struct {
+	wrapped      adbc.MultiResult
+	interceptors chain
+	query        string
+}
+
+func (m *multiResult) NextResult(ctx context.Context) (array.RecordReader, int64, error) {
+	call := &Call{Method: MethodNextResult, Query: m.query, RowsAffected: -1}
+	err := m.interceptors.invoke(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Reader, call.RowsAffected, err = m.wrapped.NextResult(ctx)
+		return
+	})
+	return call.Reader, call.RowsAffected, err
+}
+
+func (m *multiResult) Close() error {
+	return m.wrapped.Close()
+}
+
+var (
+	_ adbc.Statement              = (*statement)(nil)
+	_ adbc.StatementExecuteSchema = (*statement)(nil)
+	_ adbc.StatementExecuteMulti  = (*statement)(nil)
+)
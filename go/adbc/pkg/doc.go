@@ -24,6 +24,15 @@
 // be utilized to generate a new driver by providing a function prefix
 // and the path to the driver package.
 //
+// The drivers export the ADBC 1.1 API, except that
+// AdbcErrorFromArrayStream is not supported: the result streams are
+// exported by cdata.ExportRecordReader, so their errors are only
+// available through get_last_error and it returns NULL with
+// ADBC_STATUS_NOT_IMPLEMENTED. They are built against the 1.1 header
+// in this directory rather than the adbc.h at the root of the
+// repository, which stays at 1.0 until the C driver manager supports
+// 1.1.
+//
 // These generations are added here using go generate to make it easy to
 // generate all drivers via a single `go generate` command.
 package pkg
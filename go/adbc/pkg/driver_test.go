@@ -0,0 +1,153 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pkg_test
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	driver "github.com/apache/arrow-adbc/go/adbc/driver/flightsql"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/flight"
+	"github.com/apache/arrow/go/v12/arrow/flight/flightsql"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// failingServer fails every query with an error carrying a detail and
+// a trailer.
+type failingServer struct {
+	flightsql.BaseServer
+}
+
+func (*failingServer) GetFlightInfoStatement(ctx context.Context, _ flightsql.StatementQuery, _ *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if err := grpc.SetTrailer(ctx, metadata.Pairs("x-test", "value")); err != nil {
+		return nil, err
+	}
+	st, err := status.New(codes.InvalidArgument, "bad query").WithDetails(wrapperspb.String("detail"))
+	if err != nil {
+		return nil, err
+	}
+	return nil, st.Err()
+}
+
+// statisticsServer adds the statistics actions to a Flight SQL server,
+// with a single statistic for whatever table is requested.
+type statisticsServer struct {
+	flight.FlightServer
+}
+
+func (*statisticsServer) ListActions(_ *flight.Empty, stream flight.FlightService_ListActionsServer) error {
+	for _, action := range []string{driver.GetStatisticsActionType, driver.GetStatisticNamesActionType} {
+		if err := stream.Send(&flight.ActionType{Type: action}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ss *statisticsServer) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	var (
+		sc   *arrow.Schema
+		data string
+	)
+	switch action.Type {
+	case driver.GetStatisticsActionType:
+		sc = adbc.GetStatisticsSchema
+		data = `[{"catalog_name": "main", "catalog_db_schemas": [{
+			"db_schema_name": "public",
+			"db_schema_statistics": [{
+				"table_name": "foo", "column_name": null,
+				"statistic_key": 6, "statistic_value": [0, 42],
+				"statistic_is_approximate": true}]}]}]`
+	case driver.GetStatisticNamesActionType:
+		sc = adbc.GetStatisticNamesSchema
+		data = `[{"statistic_name": "test.statistic", "statistic_key": 1024}]`
+	default:
+		return ss.FlightServer.DoAction(action, stream)
+	}
+
+	rec, _, err := array.RecordFromJSON(memory.DefaultAllocator, sc, strings.NewReader(data))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(sc))
+	if err := w.Write(rec); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if err := w.Close(); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return stream.Send(&flight.Result{Body: buf.Bytes()})
+}
+
+// TestCDriver builds the FlightSQL shared library and runs the C
+// program in testdata against it, to check the functions added by
+// ADBC 1.1 from C: the typed options, error details and statistics.
+func TestCDriver(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the shared library")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler found")
+	}
+
+	dir := t.TempDir()
+	lib := filepath.Join(dir, "libadbc_driver_flightsql.so")
+	build := exec.Command(goTool, "build", "-tags", "driverlib", "-buildmode=c-shared", "-o", lib, "./flightsql")
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	prog := filepath.Join(dir, "driver_test")
+	compile := exec.Command(cc, "-o", prog, filepath.Join("testdata", "driver_test.c"),
+		"-L"+dir, "-ladbc_driver_flightsql", "-Wl,-rpath,"+dir)
+	out, err = compile.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	server := flight.NewServerWithMiddleware(nil)
+	require.NoError(t, server.Init("localhost:0"))
+	server.RegisterFlightService(&statisticsServer{flightsql.NewFlightServer(&failingServer{})})
+	go func() {
+		// Explicitly ignore error
+		_ = server.Serve()
+	}()
+	defer server.Shutdown()
+
+	out, err = exec.Command(prog, "grpc+tcp://"+server.Addr().String()).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
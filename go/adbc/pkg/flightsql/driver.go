@@ -22,7 +22,7 @@
 package main
 
 // #cgo CXXFLAGS: -std=c++11
-// #include "../../drivermgr/adbc.h"
+// #include "../adbc.h"
 // #include "utils.h"
 // #include <stdint.h>
 // #include <string.h>
@@ -39,6 +39,8 @@ import (
 	"fmt"
 	"runtime"
 	"runtime/cgo"
+	"strconv"
+	"sync"
 	"unsafe"
 
 	"github.com/apache/arrow-adbc/go/adbc"
@@ -64,6 +66,17 @@ func setErr(err *C.struct_AdbcError, format string, vals ...interface{}) {
 	msg := errPrefix + fmt.Sprintf(format, vals...)
 	err.message = C.CString(msg)
 	err.release = (*[0]byte)(C.FlightSQL_release_error)
+	// the fields added by ADBC 1.1 may only be used if the caller set
+	// the vendor code asking for them, which must then be kept as is
+	if extendedErr(err) {
+		err.private_data = nil
+	}
+}
+
+// extendedErr reports whether the caller allocated the ADBC 1.1
+// AdbcError, whose private_data and private_driver may be used.
+func extendedErr(err *C.struct_AdbcError) bool {
+	return err.vendor_code == C.ADBC_ERROR_VENDOR_CODE_PRIVATE_DATA
 }
 
 func errToAdbcErr(adbcerr *C.struct_AdbcError, err error) adbc.Status {
@@ -74,6 +87,15 @@ func errToAdbcErr(adbcerr *C.struct_AdbcError, err error) adbc.Status {
 	var adbcError adbc.Error
 	if errors.As(err, &adbcError) {
 		setErr(adbcerr, adbcError.Msg)
+		if !extendedErr(adbcerr) {
+			adbcerr.vendor_code = C.int32_t(adbcError.VendorCode)
+		}
+		for i, c := range adbcError.SqlState {
+			adbcerr.sqlstate[i] = C.char(c)
+		}
+		if extendedErr(adbcerr) {
+			setErrDetails(adbcerr, adbcError.Details())
+		}
 		return adbcError.Code
 	}
 
@@ -81,6 +103,217 @@ func errToAdbcErr(adbcerr *C.struct_AdbcError, err error) adbc.Status {
 	return adbc.StatusUnknown
 }
 
+// setErrDetails copies the details of an error to its private_data, in
+// a single allocation freed along with the error: an array of
+// AdbcErrorDetail ended by one with a NULL key, followed by the keys
+// and values it points to.
+func setErrDetails(err *C.struct_AdbcError, details []adbc.ErrorDetail) {
+	if len(details) == 0 {
+		return
+	}
+
+	arrSize := int(C.sizeof_struct_AdbcErrorDetail) * (len(details) + 1)
+	size := arrSize
+	for _, d := range details {
+		size += len(d.Key) + 1 + len(d.Value)
+	}
+
+	buf := C.malloc(C.size_t(size))
+	arr := fromCArr[C.struct_AdbcErrorDetail]((*C.struct_AdbcErrorDetail)(buf), len(details)+1)
+	data := fromCArr[byte]((*byte)(buf), size)
+	off := arrSize
+	for i, d := range details {
+		arr[i].key = (*C.char)(unsafe.Add(buf, off))
+		off += copy(data[off:], d.Key)
+		data[off] = 0
+		off++
+
+		arr[i].value = (*C.uint8_t)(unsafe.Add(buf, off))
+		arr[i].value_length = C.size_t(len(d.Value))
+		off += copy(data[off:], d.Value)
+	}
+	arr[len(details)] = C.struct_AdbcErrorDetail{}
+	err.private_data = buf
+}
+
+// errDetails returns the details stored by setErrDetails, if any.
+func errDetails(err *C.struct_AdbcError) []C.struct_AdbcErrorDetail {
+	if err == nil || !extendedErr(err) || err.private_data == nil {
+		return nil
+	}
+
+	n := 0
+	for p := (*C.struct_AdbcErrorDetail)(err.private_data); p.key != nil; n++ {
+		p = (*C.struct_AdbcErrorDetail)(unsafe.Add(unsafe.Pointer(p), C.sizeof_struct_AdbcErrorDetail))
+	}
+	return fromCArr[C.struct_AdbcErrorDetail]((*C.struct_AdbcErrorDetail)(err.private_data), n)
+}
+
+// cancellableContext holds the context used by the calls made on a
+// connection or statement, so that they can be cancelled from another
+// thread by AdbcConnectionCancel or AdbcStatementCancel.
+type cancellableContext struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	// active counts the calls in progress and the result streams not
+	// yet released, which are what a cancel applies to
+	active int
+}
+
+// newContext returns the context for a call, which is in progress
+// until the returned function is called.
+func (c *cancellableContext) newContext() (context.Context, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ctx == nil {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	}
+	c.active++
+
+	var once sync.Once
+	return c.ctx, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.active--
+		})
+	}
+}
+
+// cancelContext cancels the calls in progress, and the result streams
+// still being read, the following calls getting a new context. It
+// returns false if there was nothing to cancel.
+func (c *cancellableContext) cancelContext() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	return c.active > 0
+}
+
+// exportReader exports the result stream of a call, which can be
+// cancelled until it is released.
+func (c *cancellableContext) exportReader(rdr array.RecordReader, out *C.struct_ArrowArrayStream) {
+	_, done := c.newContext()
+	cdata.ExportRecordReader(&cancellableReader{RecordReader: rdr, done: done}, toCdataStream(out))
+}
+
+// cancellableReader is a result stream which counts as in progress
+// until it is released.
+type cancellableReader struct {
+	array.RecordReader
+	done func()
+}
+
+func (r *cancellableReader) Release() {
+	r.RecordReader.Release()
+	r.done()
+}
+
+func notImplemented(err *C.struct_AdbcError, fname string) C.AdbcStatusCode {
+	setErr(err, "%s: not supported by the driver", fname)
+	return C.ADBC_STATUS_NOT_IMPLEMENTED
+}
+
+// getOption gets a string option for one of the GetOption functions,
+// falling back on the given function for the objects which don't
+// implement adbc.GetSetOptions.
+func getOption(obj interface{}, key string, fallback func(string) (string, bool)) (string, error) {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.GetOption(key)
+	}
+	if fallback != nil {
+		if val, ok := fallback(key); ok {
+			return val, nil
+		}
+	}
+	return "", adbc.Error{Msg: fmt.Sprintf("option '%s' not found", key), Code: adbc.StatusNotFound}
+}
+
+func getOptionBytes(obj interface{}, key string, fallback func(string) (string, bool)) ([]byte, error) {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.GetOptionBytes(key)
+	}
+	val, err := getOption(nil, key, fallback)
+	return []byte(val), err
+}
+
+func getOptionInt(obj interface{}, key string, fallback func(string) (string, bool)) (int64, error) {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.GetOptionInt(key)
+	}
+	val, err := getOption(nil, key, fallback)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, adbc.Error{Msg: fmt.Sprintf("option '%s' is not an integer: %s", key, val), Code: adbc.StatusInvalidArgument}
+	}
+	return n, nil
+}
+
+func getOptionDouble(obj interface{}, key string, fallback func(string) (string, bool)) (float64, error) {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.GetOptionDouble(key)
+	}
+	val, err := getOption(nil, key, fallback)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, adbc.Error{Msg: fmt.Sprintf("option '%s' is not a double: %s", key, val), Code: adbc.StatusInvalidArgument}
+	}
+	return f, nil
+}
+
+// setOptionInt sets an integer option, as a string for the objects
+// which don't implement adbc.GetSetOptions.
+func setOptionInt(obj interface{}, key string, value int64, set func(k, v string) error) error {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.SetOptionInt(key, value)
+	}
+	return set(key, strconv.FormatInt(value, 10))
+}
+
+func setOptionDouble(obj interface{}, key string, value float64, set func(k, v string) error) error {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.SetOptionDouble(key, value)
+	}
+	return set(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func setOptionBytes(obj interface{}, key string, value []byte) error {
+	if opts, ok := obj.(adbc.GetSetOptions); ok {
+		return opts.SetOptionBytes(key, value)
+	}
+	return adbc.Error{Msg: fmt.Sprintf("option '%s' cannot be set as bytes", key), Code: adbc.StatusNotImplemented}
+}
+
+// exportOption copies a string option to the buffer of a GetOption
+// function if large enough, setting length to the size required.
+func exportOption(val string, out *C.char, length *C.size_t) {
+	n := C.size_t(len(val) + 1)
+	if n <= *length {
+		buf := fromCArr[byte](out, int(n))
+		copy(buf, val)
+		buf[len(val)] = 0
+	}
+	*length = n
+}
+
+func exportOptionBytes(val []byte, out *C.uint8_t, length *C.size_t) {
+	n := C.size_t(len(val))
+	if n <= *length {
+		copy(fromCArr[byte](out, int(n)), val)
+	}
+	*length = n
+}
+
 // Allocate a new cgo.Handle and store its address in a heap-allocated
 // uintptr_t.  Experimentally, this was found to be necessary, else
 // something (the Go runtime?) would corrupt (garbage-collect?) the
@@ -198,7 +431,122 @@ func FlightSQLDatabaseRelease(db *C.struct_AdbcDatabase, err *C.struct_AdbcError
 	return C.ADBC_STATUS_OK
 }
 
+// lookup gets the options the database was created with, for the
+// drivers which don't implement adbc.GetSetOptions
+func (cdb *cDatabase) lookup(key string) (string, bool) {
+	val, ok := cdb.opts[key]
+	return val, ok
+}
+
+func (cdb *cDatabase) setOption(key, value string) error {
+	cdb.opts[key] = value
+	return nil
+}
+
+// options returns the database to get or set options on, which is nil
+// until it is initialized
+func (cdb *cDatabase) options() interface{} {
+	if cdb.db == nil {
+		return nil
+	}
+	return cdb.db
+}
+
+//export FlightSQLDatabaseGetOption
+func FlightSQLDatabaseGetOption(db *C.struct_AdbcDatabase, key *C.cchar_t, value *C.char, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseGetOption") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	val, e := getOption(cdb.options(), C.GoString(key), cdb.lookup)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOption(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLDatabaseGetOptionBytes
+func FlightSQLDatabaseGetOptionBytes(db *C.struct_AdbcDatabase, key *C.cchar_t, value *C.uint8_t, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseGetOptionBytes") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	val, e := getOptionBytes(cdb.options(), C.GoString(key), cdb.lookup)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOptionBytes(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLDatabaseGetOptionInt
+func FlightSQLDatabaseGetOptionInt(db *C.struct_AdbcDatabase, key *C.cchar_t, value *C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseGetOptionInt") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	val, e := getOptionInt(cdb.options(), C.GoString(key), cdb.lookup)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.int64_t(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLDatabaseGetOptionDouble
+func FlightSQLDatabaseGetOptionDouble(db *C.struct_AdbcDatabase, key *C.cchar_t, value *C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseGetOptionDouble") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	val, e := getOptionDouble(cdb.options(), C.GoString(key), cdb.lookup)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.double(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLDatabaseSetOptionBytes
+func FlightSQLDatabaseSetOptionBytes(db *C.struct_AdbcDatabase, key *C.cchar_t, value *C.cuint8_t, length C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseSetOptionBytes") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	code := errToAdbcErr(err, setOptionBytes(cdb.options(), C.GoString(key), fromCArr[byte](value, int(length))))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLDatabaseSetOptionInt
+func FlightSQLDatabaseSetOptionInt(db *C.struct_AdbcDatabase, key *C.cchar_t, value C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseSetOptionInt") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	code := errToAdbcErr(err, setOptionInt(cdb.options(), C.GoString(key), int64(value), cdb.setOption))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLDatabaseSetOptionDouble
+func FlightSQLDatabaseSetOptionDouble(db *C.struct_AdbcDatabase, key *C.cchar_t, value C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	if !checkDBAlloc(db, err, "AdbcDatabaseSetOptionDouble") {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	cdb := getFromHandle[cDatabase](db.private_data)
+
+	code := errToAdbcErr(err, setOptionDouble(cdb.options(), C.GoString(key), float64(value), cdb.setOption))
+	return C.AdbcStatusCode(code)
+}
+
 type cConn struct {
+	cancellableContext
 	cnxn adbc.Connection
 }
 
@@ -265,7 +613,9 @@ func FlightSQLConnectionInit(cnxn *C.struct_AdbcConnection, db *C.struct_AdbcDat
 	if cdb == nil {
 		return C.ADBC_STATUS_INVALID_STATE
 	}
-	c, e := cdb.db.Open(context.Background())
+	ctx, done := conn.newContext()
+	defer done()
+	c, e := cdb.db.Open(ctx)
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
@@ -283,6 +633,7 @@ func FlightSQLConnectionRelease(cnxn *C.struct_AdbcConnection, err *C.struct_Adb
 
 	conn := h.Value().(*cConn)
 	defer func() {
+		conn.cancelContext()
 		conn.cnxn = nil
 		C.free(unsafe.Pointer(cnxn.private_data))
 		cnxn.private_data = nil
@@ -329,12 +680,14 @@ func FlightSQLConnectionGetInfo(cnxn *C.struct_AdbcConnection, codes *C.uint32_t
 	}
 
 	infoCodes := fromCArr[adbc.InfoCode](codes, int(len))
-	rdr, e := conn.cnxn.GetInfo(context.Background(), infoCodes)
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := conn.cnxn.GetInfo(ctx, infoCodes)
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
 
-	cdata.ExportRecordReader(rdr, toCdataStream(out))
+	conn.exportReader(rdr, out)
 	return C.ADBC_STATUS_OK
 }
 
@@ -371,11 +724,13 @@ func FlightSQLConnectionGetObjects(cnxn *C.struct_AdbcConnection, depth C.int, c
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	rdr, e := conn.cnxn.GetObjects(context.Background(), adbc.ObjectDepth(depth), toStrPtr(catalog), toStrPtr(dbSchema), toStrPtr(tableName), toStrPtr(columnName), toStrSlice(tableType))
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := conn.cnxn.GetObjects(ctx, adbc.ObjectDepth(depth), toStrPtr(catalog), toStrPtr(dbSchema), toStrPtr(tableName), toStrPtr(columnName), toStrSlice(tableType))
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
-	cdata.ExportRecordReader(rdr, toCdataStream(out))
+	conn.exportReader(rdr, out)
 	return C.ADBC_STATUS_OK
 }
 
@@ -386,7 +741,9 @@ func FlightSQLConnectionGetTableSchema(cnxn *C.struct_AdbcConnection, catalog, d
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	sc, e := conn.cnxn.GetTableSchema(context.Background(), toStrPtr(catalog), toStrPtr(dbSchema), C.GoString(tableName))
+	ctx, done := conn.newContext()
+	defer done()
+	sc, e := conn.cnxn.GetTableSchema(ctx, toStrPtr(catalog), toStrPtr(dbSchema), C.GoString(tableName))
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
@@ -401,11 +758,13 @@ func FlightSQLConnectionGetTableTypes(cnxn *C.struct_AdbcConnection, out *C.stru
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	rdr, e := conn.cnxn.GetTableTypes(context.Background())
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := conn.cnxn.GetTableTypes(ctx)
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
-	cdata.ExportRecordReader(rdr, toCdataStream(out))
+	conn.exportReader(rdr, out)
 	return C.ADBC_STATUS_OK
 }
 
@@ -416,11 +775,13 @@ func FlightSQLConnectionReadPartition(cnxn *C.struct_AdbcConnection, serialized
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	rdr, e := conn.cnxn.ReadPartition(context.Background(), fromCArr[byte](serialized, int(serializedLen)))
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := conn.cnxn.ReadPartition(ctx, fromCArr[byte](serialized, int(serializedLen)))
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
-	cdata.ExportRecordReader(rdr, toCdataStream(out))
+	conn.exportReader(rdr, out)
 	return C.ADBC_STATUS_OK
 }
 
@@ -431,7 +792,9 @@ func FlightSQLConnectionCommit(cnxn *C.struct_AdbcConnection, err *C.struct_Adbc
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, conn.cnxn.Commit(context.Background())))
+	ctx, done := conn.newContext()
+	defer done()
+	return C.AdbcStatusCode(errToAdbcErr(err, conn.cnxn.Commit(ctx)))
 }
 
 //export FlightSQLConnectionRollback
@@ -441,10 +804,176 @@ func FlightSQLConnectionRollback(cnxn *C.struct_AdbcConnection, err *C.struct_Ad
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, conn.cnxn.Rollback(context.Background())))
+	ctx, done := conn.newContext()
+	defer done()
+	return C.AdbcStatusCode(errToAdbcErr(err, conn.cnxn.Rollback(ctx)))
+}
+
+//export FlightSQLConnectionCancel
+func FlightSQLConnectionCancel(cnxn *C.struct_AdbcConnection, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionCancel")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	if !conn.cancelContext() {
+		setErr(err, "AdbcConnectionCancel: no call or result stream in progress")
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	return C.ADBC_STATUS_OK
 }
 
-func checkStmtInit(stmt *C.struct_AdbcStatement, err *C.struct_AdbcError, fname string) adbc.Statement {
+//export FlightSQLConnectionGetStatistics
+func FlightSQLConnectionGetStatistics(cnxn *C.struct_AdbcConnection, catalog, dbSchema, tableName *C.cchar_t, approximate C.char, out *C.struct_ArrowArrayStream, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetStatistics")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	stats, ok := conn.cnxn.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return notImplemented(err, "AdbcConnectionGetStatistics")
+	}
+
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := stats.GetStatistics(ctx, toStrPtr(catalog), toStrPtr(dbSchema), toStrPtr(tableName), approximate != 0)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	conn.exportReader(rdr, out)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionGetStatisticNames
+func FlightSQLConnectionGetStatisticNames(cnxn *C.struct_AdbcConnection, out *C.struct_ArrowArrayStream, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetStatisticNames")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	stats, ok := conn.cnxn.(adbc.ConnectionGetStatistics)
+	if !ok {
+		return notImplemented(err, "AdbcConnectionGetStatisticNames")
+	}
+
+	ctx, done := conn.newContext()
+	defer done()
+	rdr, e := stats.GetStatisticNames(ctx)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	conn.exportReader(rdr, out)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionGetOption
+func FlightSQLConnectionGetOption(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value *C.char, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetOption")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOption(conn.cnxn, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOption(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionGetOptionBytes
+func FlightSQLConnectionGetOptionBytes(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value *C.uint8_t, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetOptionBytes")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionBytes(conn.cnxn, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOptionBytes(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionGetOptionInt
+func FlightSQLConnectionGetOptionInt(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value *C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetOptionInt")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionInt(conn.cnxn, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.int64_t(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionGetOptionDouble
+func FlightSQLConnectionGetOptionDouble(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value *C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionGetOptionDouble")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionDouble(conn.cnxn, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.double(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLConnectionSetOptionBytes
+func FlightSQLConnectionSetOptionBytes(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value *C.cuint8_t, length C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionSetOptionBytes")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	code := errToAdbcErr(err, setOptionBytes(conn.cnxn, C.GoString(key), fromCArr[byte](value, int(length))))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLConnectionSetOptionInt
+func FlightSQLConnectionSetOptionInt(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionSetOptionInt")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	opts, ok := conn.cnxn.(adbc.PostInitOptions)
+	if !ok {
+		return notImplemented(err, "AdbcConnectionSetOptionInt")
+	}
+
+	code := errToAdbcErr(err, setOptionInt(opts, C.GoString(key), int64(value), opts.SetOption))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLConnectionSetOptionDouble
+func FlightSQLConnectionSetOptionDouble(cnxn *C.struct_AdbcConnection, key *C.cchar_t, value C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	conn := checkConnInit(cnxn, err, "AdbcConnectionSetOptionDouble")
+	if conn == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	opts, ok := conn.cnxn.(adbc.PostInitOptions)
+	if !ok {
+		return notImplemented(err, "AdbcConnectionSetOptionDouble")
+	}
+
+	code := errToAdbcErr(err, setOptionDouble(opts, C.GoString(key), float64(value), opts.SetOption))
+	return C.AdbcStatusCode(code)
+}
+
+type cStmt struct {
+	cancellableContext
+	stmt adbc.Statement
+}
+
+func checkStmtInit(stmt *C.struct_AdbcStatement, err *C.struct_AdbcError, fname string) *cStmt {
 	if stmt == nil {
 		setErr(err, "%s: statement not allocated", fname)
 		return nil
@@ -455,7 +984,7 @@ func checkStmtInit(stmt *C.struct_AdbcStatement, err *C.struct_AdbcError, fname
 		return nil
 	}
 
-	return (*(*cgo.Handle)(stmt.private_data)).Value().(adbc.Statement)
+	return getFromHandle[cStmt](stmt.private_data)
 }
 
 //export FlightSQLStatementNew
@@ -470,7 +999,7 @@ func FlightSQLStatementNew(cnxn *C.struct_AdbcConnection, stmt *C.struct_AdbcSta
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
 
-	h := cgo.NewHandle(st)
+	h := cgo.NewHandle(&cStmt{stmt: st})
 	stmt.private_data = createHandle(h)
 	return C.ADBC_STATUS_OK
 }
@@ -488,11 +1017,12 @@ func FlightSQLStatementRelease(stmt *C.struct_AdbcStatement, err *C.struct_AdbcE
 	}
 
 	h := (*(*cgo.Handle)(stmt.private_data))
-	st := h.Value().(adbc.Statement)
+	st := h.Value().(*cStmt)
 	C.free(stmt.private_data)
 	stmt.private_data = nil
 
-	e := st.Close()
+	st.cancelContext()
+	e := st.stmt.Close()
 	h.Delete()
 	// manually trigger GC for two reasons:
 	//  1. ASAN expects the release callback to be called before
@@ -511,7 +1041,9 @@ func FlightSQLStatementPrepare(stmt *C.struct_AdbcStatement, err *C.struct_AdbcE
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, st.Prepare(context.Background())))
+	ctx, done := st.newContext()
+	defer done()
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.Prepare(ctx)))
 }
 
 //export FlightSQLStatementExecuteQuery
@@ -522,7 +1054,9 @@ func FlightSQLStatementExecuteQuery(stmt *C.struct_AdbcStatement, out *C.struct_
 	}
 
 	if out == nil {
-		n, e := st.ExecuteUpdate(context.Background())
+		ctx, done := st.newContext()
+		defer done()
+		n, e := st.stmt.ExecuteUpdate(ctx)
 		if e != nil {
 			return C.AdbcStatusCode(errToAdbcErr(err, e))
 		}
@@ -531,7 +1065,9 @@ func FlightSQLStatementExecuteQuery(stmt *C.struct_AdbcStatement, out *C.struct_
 			*affected = C.int64_t(n)
 		}
 	} else {
-		rdr, n, e := st.ExecuteQuery(context.Background())
+		ctx, done := st.newContext()
+		defer done()
+		rdr, n, e := st.stmt.ExecuteQuery(ctx)
 		if e != nil {
 			return C.AdbcStatusCode(errToAdbcErr(err, e))
 		}
@@ -540,7 +1076,7 @@ func FlightSQLStatementExecuteQuery(stmt *C.struct_AdbcStatement, out *C.struct_
 			*affected = C.int64_t(n)
 		}
 
-		cdata.ExportRecordReader(rdr, toCdataStream(out))
+		st.exportReader(rdr, out)
 	}
 	return C.ADBC_STATUS_OK
 }
@@ -552,7 +1088,7 @@ func FlightSQLStatementSetSqlQuery(stmt *C.struct_AdbcStatement, query *C.cchar_
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, st.SetSqlQuery(C.GoString(query))))
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.SetSqlQuery(C.GoString(query))))
 }
 
 //export FlightSQLStatementSetSubstraitPlan
@@ -562,7 +1098,7 @@ func FlightSQLStatementSetSubstraitPlan(stmt *C.struct_AdbcStatement, plan *C.cu
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, st.SetSubstraitPlan(fromCArr[byte](plan, int(length)))))
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.SetSubstraitPlan(fromCArr[byte](plan, int(length)))))
 }
 
 //export FlightSQLStatementBind
@@ -580,7 +1116,9 @@ func FlightSQLStatementBind(stmt *C.struct_AdbcStatement, values *C.struct_Arrow
 	}
 	defer rec.Release()
 
-	return C.AdbcStatusCode(errToAdbcErr(err, st.Bind(context.Background(), rec)))
+	ctx, done := st.newContext()
+	defer done()
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.Bind(ctx, rec)))
 }
 
 //export FlightSQLStatementBindStream
@@ -591,7 +1129,9 @@ func FlightSQLStatementBindStream(stmt *C.struct_AdbcStatement, stream *C.struct
 	}
 
 	rdr := cdata.ImportCArrayStream(toCdataStream(stream), nil)
-	return C.AdbcStatusCode(errToAdbcErr(err, st.BindStream(context.Background(), rdr.(array.RecordReader))))
+	ctx, done := st.newContext()
+	defer done()
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.BindStream(ctx, rdr.(array.RecordReader))))
 }
 
 //export FlightSQLStatementGetParameterSchema
@@ -601,7 +1141,7 @@ func FlightSQLStatementGetParameterSchema(stmt *C.struct_AdbcStatement, schema *
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	sc, e := st.GetParameterSchema()
+	sc, e := st.stmt.GetParameterSchema()
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
@@ -617,7 +1157,7 @@ func FlightSQLStatementSetOption(stmt *C.struct_AdbcStatement, key, value *C.cch
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	return C.AdbcStatusCode(errToAdbcErr(err, st.SetOption(C.GoString(key), C.GoString(value))))
+	return C.AdbcStatusCode(errToAdbcErr(err, st.stmt.SetOption(C.GoString(key), C.GoString(value))))
 }
 
 //export releasePartitions
@@ -641,7 +1181,9 @@ func FlightSQLStatementExecutePartitions(stmt *C.struct_AdbcStatement, schema *C
 		return C.ADBC_STATUS_INVALID_STATE
 	}
 
-	sc, part, n, e := st.ExecutePartitions(context.Background())
+	ctx, done := st.newContext()
+	defer done()
+	sc, part, n, e := st.stmt.ExecutePartitions(ctx)
 	if e != nil {
 		return C.AdbcStatusCode(errToAdbcErr(err, e))
 	}
@@ -684,15 +1226,177 @@ func FlightSQLStatementExecutePartitions(stmt *C.struct_AdbcStatement, schema *C
 	return C.ADBC_STATUS_OK
 }
 
+//export FlightSQLStatementCancel
+func FlightSQLStatementCancel(stmt *C.struct_AdbcStatement, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementCancel")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	if !st.cancelContext() {
+		setErr(err, "AdbcStatementCancel: no call or result stream in progress")
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementExecuteSchema
+func FlightSQLStatementExecuteSchema(stmt *C.struct_AdbcStatement, schema *C.struct_ArrowSchema, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementExecuteSchema")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	es, ok := st.stmt.(adbc.StatementExecuteSchema)
+	if !ok {
+		return notImplemented(err, "AdbcStatementExecuteSchema")
+	}
+
+	ctx, done := st.newContext()
+	defer done()
+	sc, e := es.ExecuteSchema(ctx)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+
+	cdata.ExportArrowSchema(sc, toCdataSchema(schema))
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementGetOption
+func FlightSQLStatementGetOption(stmt *C.struct_AdbcStatement, key *C.cchar_t, value *C.char, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementGetOption")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOption(st.stmt, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOption(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementGetOptionBytes
+func FlightSQLStatementGetOptionBytes(stmt *C.struct_AdbcStatement, key *C.cchar_t, value *C.uint8_t, length *C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementGetOptionBytes")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionBytes(st.stmt, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	exportOptionBytes(val, value, length)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementGetOptionInt
+func FlightSQLStatementGetOptionInt(stmt *C.struct_AdbcStatement, key *C.cchar_t, value *C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementGetOptionInt")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionInt(st.stmt, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.int64_t(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementGetOptionDouble
+func FlightSQLStatementGetOptionDouble(stmt *C.struct_AdbcStatement, key *C.cchar_t, value *C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementGetOptionDouble")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	val, e := getOptionDouble(st.stmt, C.GoString(key), nil)
+	if e != nil {
+		return C.AdbcStatusCode(errToAdbcErr(err, e))
+	}
+	*value = C.double(val)
+	return C.ADBC_STATUS_OK
+}
+
+//export FlightSQLStatementSetOptionBytes
+func FlightSQLStatementSetOptionBytes(stmt *C.struct_AdbcStatement, key *C.cchar_t, value *C.cuint8_t, length C.size_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementSetOptionBytes")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	code := errToAdbcErr(err, setOptionBytes(st.stmt, C.GoString(key), fromCArr[byte](value, int(length))))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLStatementSetOptionInt
+func FlightSQLStatementSetOptionInt(stmt *C.struct_AdbcStatement, key *C.cchar_t, value C.int64_t, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementSetOptionInt")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	code := errToAdbcErr(err, setOptionInt(st.stmt, C.GoString(key), int64(value), st.stmt.SetOption))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLStatementSetOptionDouble
+func FlightSQLStatementSetOptionDouble(stmt *C.struct_AdbcStatement, key *C.cchar_t, value C.double, err *C.struct_AdbcError) C.AdbcStatusCode {
+	st := checkStmtInit(stmt, err, "AdbcStatementSetOptionDouble")
+	if st == nil {
+		return C.ADBC_STATUS_INVALID_STATE
+	}
+
+	code := errToAdbcErr(err, setOptionDouble(st.stmt, C.GoString(key), float64(value), st.stmt.SetOption))
+	return C.AdbcStatusCode(code)
+}
+
+//export FlightSQLErrorGetDetailCount
+func FlightSQLErrorGetDetailCount(err *C.struct_AdbcError) C.int {
+	return C.int(len(errDetails(err)))
+}
+
+//export FlightSQLErrorGetDetail
+func FlightSQLErrorGetDetail(err *C.struct_AdbcError, index C.int) C.struct_AdbcErrorDetail {
+	details := errDetails(err)
+	if index < 0 || int(index) >= len(details) {
+		return C.struct_AdbcErrorDetail{}
+	}
+	return details[index]
+}
+
+//export FlightSQLErrorFromArrayStream
+func FlightSQLErrorFromArrayStream(stream *C.struct_ArrowArrayStream, status *C.AdbcStatusCode) *C.struct_AdbcError {
+	// not supported: the streams are exported by
+	// cdata.ExportRecordReader, which has no room for an AdbcError, so
+	// their errors are only available through get_last_error
+	if status != nil {
+		*status = C.ADBC_STATUS_NOT_IMPLEMENTED
+	}
+	return nil
+}
+
 //export FlightSQLDriverInit
 func FlightSQLDriverInit(version C.int, rawDriver *C.void, err *C.struct_AdbcError) C.AdbcStatusCode {
-	if version != C.ADBC_VERSION_1_0_0 {
-		setErr(err, "Only version %d supported, got %d", int(C.ADBC_VERSION_1_0_0), int(version))
+	var size C.size_t
+	switch version {
+	case C.ADBC_VERSION_1_0_0:
+		size = C.ADBC_DRIVER_1_0_0_SIZE
+	case C.ADBC_VERSION_1_1_0:
+		size = C.ADBC_DRIVER_1_1_0_SIZE
+	default:
+		setErr(err, "Only versions %d and %d supported, got %d", int(C.ADBC_VERSION_1_0_0), int(C.ADBC_VERSION_1_1_0), int(version))
 		return C.ADBC_STATUS_NOT_IMPLEMENTED
 	}
 
+	// only the part of the table matching the version was allocated
 	driver := (*C.struct_AdbcDriver)(unsafe.Pointer(rawDriver))
-	C.memset(unsafe.Pointer(driver), 0, C.sizeof_struct_AdbcDriver)
+	C.memset(unsafe.Pointer(driver), 0, size)
 	driver.DatabaseInit = (*[0]byte)(C.FlightSQLDatabaseInit)
 	driver.DatabaseNew = (*[0]byte)(C.FlightSQLDatabaseNew)
 	driver.DatabaseRelease = (*[0]byte)(C.FlightSQLDatabaseRelease)
@@ -722,6 +1426,43 @@ func FlightSQLDriverInit(version C.int, rawDriver *C.void, err *C.struct_AdbcErr
 	driver.StatementGetParameterSchema = (*[0]byte)(C.FlightSQLStatementGetParameterSchema)
 	driver.StatementPrepare = (*[0]byte)(C.FlightSQLStatementPrepare)
 
+	if version == C.ADBC_VERSION_1_0_0 {
+		return C.ADBC_STATUS_OK
+	}
+
+	driver.ErrorGetDetailCount = (*[0]byte)(C.FlightSQLErrorGetDetailCount)
+	driver.ErrorGetDetail = (*[0]byte)(C.FlightSQLErrorGetDetail)
+	driver.ErrorFromArrayStream = (*[0]byte)(C.FlightSQLErrorFromArrayStream)
+
+	driver.DatabaseGetOption = (*[0]byte)(C.FlightSQLDatabaseGetOption)
+	driver.DatabaseGetOptionBytes = (*[0]byte)(C.FlightSQLDatabaseGetOptionBytes)
+	driver.DatabaseGetOptionDouble = (*[0]byte)(C.FlightSQLDatabaseGetOptionDouble)
+	driver.DatabaseGetOptionInt = (*[0]byte)(C.FlightSQLDatabaseGetOptionInt)
+	driver.DatabaseSetOptionBytes = (*[0]byte)(C.FlightSQLDatabaseSetOptionBytes)
+	driver.DatabaseSetOptionDouble = (*[0]byte)(C.FlightSQLDatabaseSetOptionDouble)
+	driver.DatabaseSetOptionInt = (*[0]byte)(C.FlightSQLDatabaseSetOptionInt)
+
+	driver.ConnectionCancel = (*[0]byte)(C.FlightSQLConnectionCancel)
+	driver.ConnectionGetOption = (*[0]byte)(C.FlightSQLConnectionGetOption)
+	driver.ConnectionGetOptionBytes = (*[0]byte)(C.FlightSQLConnectionGetOptionBytes)
+	driver.ConnectionGetOptionDouble = (*[0]byte)(C.FlightSQLConnectionGetOptionDouble)
+	driver.ConnectionGetOptionInt = (*[0]byte)(C.FlightSQLConnectionGetOptionInt)
+	driver.ConnectionGetStatistics = (*[0]byte)(C.FlightSQLConnectionGetStatistics)
+	driver.ConnectionGetStatisticNames = (*[0]byte)(C.FlightSQLConnectionGetStatisticNames)
+	driver.ConnectionSetOptionBytes = (*[0]byte)(C.FlightSQLConnectionSetOptionBytes)
+	driver.ConnectionSetOptionDouble = (*[0]byte)(C.FlightSQLConnectionSetOptionDouble)
+	driver.ConnectionSetOptionInt = (*[0]byte)(C.FlightSQLConnectionSetOptionInt)
+
+	driver.StatementCancel = (*[0]byte)(C.FlightSQLStatementCancel)
+	driver.StatementExecuteSchema = (*[0]byte)(C.FlightSQLStatementExecuteSchema)
+	driver.StatementGetOption = (*[0]byte)(C.FlightSQLStatementGetOption)
+	driver.StatementGetOptionBytes = (*[0]byte)(C.FlightSQLStatementGetOptionBytes)
+	driver.StatementGetOptionDouble = (*[0]byte)(C.FlightSQLStatementGetOptionDouble)
+	driver.StatementGetOptionInt = (*[0]byte)(C.FlightSQLStatementGetOptionInt)
+	driver.StatementSetOptionBytes = (*[0]byte)(C.FlightSQLStatementSetOptionBytes)
+	driver.StatementSetOptionDouble = (*[0]byte)(C.FlightSQLStatementSetOptionDouble)
+	driver.StatementSetOptionInt = (*[0]byte)(C.FlightSQLStatementSetOptionInt)
+
 	return C.ADBC_STATUS_OK
 }
 
@@ -24,7 +24,7 @@
 #pragma once
 
 #include <stdlib.h>
-#include "../../drivermgr/adbc.h"
+#include "../adbc.h"
 
 AdbcStatusCode FlightSQLDatabaseNew(struct AdbcDatabase* db, struct AdbcError* err);
 AdbcStatusCode FlightSQLDatabaseSetOption(struct AdbcDatabase* db, const char* key,
@@ -90,6 +90,82 @@ AdbcStatusCode FlightSQLStatementExecutePartitions(struct AdbcStatement* stmt,
                                                    struct AdbcPartitions* partitions,
                                                    int64_t* affected,
                                                    struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseGetOption(struct AdbcDatabase* db, const char* key,
+                                          char* value, size_t* length,
+                                          struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseGetOptionBytes(struct AdbcDatabase* db, const char* key,
+                                               uint8_t* value, size_t* length,
+                                               struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseGetOptionDouble(struct AdbcDatabase* db, const char* key,
+                                                double* value, struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseGetOptionInt(struct AdbcDatabase* db, const char* key,
+                                             int64_t* value, struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseSetOptionBytes(struct AdbcDatabase* db, const char* key,
+                                               const uint8_t* value, size_t length,
+                                               struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseSetOptionDouble(struct AdbcDatabase* db, const char* key,
+                                                double value, struct AdbcError* err);
+AdbcStatusCode FlightSQLDatabaseSetOptionInt(struct AdbcDatabase* db, const char* key,
+                                             int64_t value, struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionCancel(struct AdbcConnection* cnxn,
+                                         struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetOption(struct AdbcConnection* cnxn, const char* key,
+                                            char* value, size_t* length,
+                                            struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetOptionBytes(struct AdbcConnection* cnxn,
+                                                 const char* key, uint8_t* value,
+                                                 size_t* length, struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetOptionDouble(struct AdbcConnection* cnxn,
+                                                  const char* key, double* value,
+                                                  struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetOptionInt(struct AdbcConnection* cnxn,
+                                               const char* key, int64_t* value,
+                                               struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetStatistics(struct AdbcConnection* cnxn,
+                                                const char* catalog, const char* dbSchema,
+                                                const char* tableName, char approximate,
+                                                struct ArrowArrayStream* out,
+                                                struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionGetStatisticNames(struct AdbcConnection* cnxn,
+                                                    struct ArrowArrayStream* out,
+                                                    struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionSetOptionBytes(struct AdbcConnection* cnxn,
+                                                 const char* key, const uint8_t* value,
+                                                 size_t length, struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionSetOptionDouble(struct AdbcConnection* cnxn,
+                                                  const char* key, double value,
+                                                  struct AdbcError* err);
+AdbcStatusCode FlightSQLConnectionSetOptionInt(struct AdbcConnection* cnxn,
+                                               const char* key, int64_t value,
+                                               struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementCancel(struct AdbcStatement* stmt,
+                                        struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementExecuteSchema(struct AdbcStatement* stmt,
+                                               struct ArrowSchema* schema,
+                                               struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementGetOption(struct AdbcStatement* stmt, const char* key,
+                                           char* value, size_t* length,
+                                           struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementGetOptionBytes(struct AdbcStatement* stmt,
+                                                const char* key, uint8_t* value,
+                                                size_t* length, struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementGetOptionDouble(struct AdbcStatement* stmt,
+                                                 const char* key, double* value,
+                                                 struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementGetOptionInt(struct AdbcStatement* stmt, const char* key,
+                                              int64_t* value, struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementSetOptionBytes(struct AdbcStatement* stmt,
+                                                const char* key, const uint8_t* value,
+                                                size_t length, struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementSetOptionDouble(struct AdbcStatement* stmt,
+                                                 const char* key, double value,
+                                                 struct AdbcError* err);
+AdbcStatusCode FlightSQLStatementSetOptionInt(struct AdbcStatement* stmt, const char* key,
+                                              int64_t value, struct AdbcError* err);
+int FlightSQLErrorGetDetailCount(struct AdbcError* err);
+struct AdbcErrorDetail FlightSQLErrorGetDetail(struct AdbcError* err, int index);
+struct AdbcError* FlightSQLErrorFromArrayStream(struct ArrowArrayStream* stream,
+                                                AdbcStatusCode* status);
 AdbcStatusCode FlightSQLDriverInit(int version, void* rawDriver, struct AdbcError* err);
 
 static inline void FlightSQLerrRelease(struct AdbcError* error) { error->release(error); }
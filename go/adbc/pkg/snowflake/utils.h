@@ -24,7 +24,7 @@
 #pragma once
 
 #include <stdlib.h>
-#include "../../drivermgr/adbc.h"
+#include "../adbc.h"
 
 AdbcStatusCode SnowflakeDatabaseNew(struct AdbcDatabase* db, struct AdbcError* err);
 AdbcStatusCode SnowflakeDatabaseSetOption(struct AdbcDatabase* db, const char* key,
@@ -90,6 +90,82 @@ AdbcStatusCode SnowflakeStatementExecutePartitions(struct AdbcStatement* stmt,
                                                    struct AdbcPartitions* partitions,
                                                    int64_t* affected,
                                                    struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseGetOption(struct AdbcDatabase* db, const char* key,
+                                          char* value, size_t* length,
+                                          struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseGetOptionBytes(struct AdbcDatabase* db, const char* key,
+                                               uint8_t* value, size_t* length,
+                                               struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseGetOptionDouble(struct AdbcDatabase* db, const char* key,
+                                                double* value, struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseGetOptionInt(struct AdbcDatabase* db, const char* key,
+                                             int64_t* value, struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseSetOptionBytes(struct AdbcDatabase* db, const char* key,
+                                               const uint8_t* value, size_t length,
+                                               struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseSetOptionDouble(struct AdbcDatabase* db, const char* key,
+                                                double value, struct AdbcError* err);
+AdbcStatusCode SnowflakeDatabaseSetOptionInt(struct AdbcDatabase* db, const char* key,
+                                             int64_t value, struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionCancel(struct AdbcConnection* cnxn,
+                                         struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetOption(struct AdbcConnection* cnxn, const char* key,
+                                            char* value, size_t* length,
+                                            struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetOptionBytes(struct AdbcConnection* cnxn,
+                                                 const char* key, uint8_t* value,
+                                                 size_t* length, struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetOptionDouble(struct AdbcConnection* cnxn,
+                                                  const char* key, double* value,
+                                                  struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetOptionInt(struct AdbcConnection* cnxn,
+                                               const char* key, int64_t* value,
+                                               struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetStatistics(struct AdbcConnection* cnxn,
+                                                const char* catalog, const char* dbSchema,
+                                                const char* tableName, char approximate,
+                                                struct ArrowArrayStream* out,
+                                                struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionGetStatisticNames(struct AdbcConnection* cnxn,
+                                                    struct ArrowArrayStream* out,
+                                                    struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionSetOptionBytes(struct AdbcConnection* cnxn,
+                                                 const char* key, const uint8_t* value,
+                                                 size_t length, struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionSetOptionDouble(struct AdbcConnection* cnxn,
+                                                  const char* key, double value,
+                                                  struct AdbcError* err);
+AdbcStatusCode SnowflakeConnectionSetOptionInt(struct AdbcConnection* cnxn,
+                                               const char* key, int64_t value,
+                                               struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementCancel(struct AdbcStatement* stmt,
+                                        struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementExecuteSchema(struct AdbcStatement* stmt,
+                                               struct ArrowSchema* schema,
+                                               struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementGetOption(struct AdbcStatement* stmt, const char* key,
+                                           char* value, size_t* length,
+                                           struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementGetOptionBytes(struct AdbcStatement* stmt,
+                                                const char* key, uint8_t* value,
+                                                size_t* length, struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementGetOptionDouble(struct AdbcStatement* stmt,
+                                                 const char* key, double* value,
+                                                 struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementGetOptionInt(struct AdbcStatement* stmt, const char* key,
+                                              int64_t* value, struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementSetOptionBytes(struct AdbcStatement* stmt,
+                                                const char* key, const uint8_t* value,
+                                                size_t length, struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementSetOptionDouble(struct AdbcStatement* stmt,
+                                                 const char* key, double value,
+                                                 struct AdbcError* err);
+AdbcStatusCode SnowflakeStatementSetOptionInt(struct AdbcStatement* stmt, const char* key,
+                                              int64_t value, struct AdbcError* err);
+int SnowflakeErrorGetDetailCount(struct AdbcError* err);
+struct AdbcErrorDetail SnowflakeErrorGetDetail(struct AdbcError* err, int index);
+struct AdbcError* SnowflakeErrorFromArrayStream(struct ArrowArrayStream* stream,
+                                                AdbcStatusCode* status);
 AdbcStatusCode SnowflakeDriverInit(int version, void* rawDriver, struct AdbcError* err);
 
 static inline void SnowflakeerrRelease(struct AdbcError* error) { error->release(error); }
@@ -0,0 +1,298 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Exercises the ADBC 1.1 functions of the FlightSQL shared library
+// through its driver table, against the server whose URI is the only
+// argument. Run by TestCDriver.
+
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "../adbc.h"
+
+AdbcStatusCode AdbcDriverInit(int version, void* driver, struct AdbcError* error);
+
+static struct AdbcDriver driver;
+
+#define FAIL(...)                                   \
+  do {                                              \
+    fprintf(stderr, "%s:%d: ", __FILE__, __LINE__); \
+    fprintf(stderr, __VA_ARGS__);                   \
+    fprintf(stderr, "\n");                          \
+    exit(1);                                        \
+  } while (0)
+
+#define EXPECT_STATUS(expected, call)                                              \
+  do {                                                                             \
+    AdbcStatusCode status = (call);                                                \
+    if (status != (expected)) {                                                    \
+      FAIL("%s: expected status %d, got %d: %s", #call, (expected), status,        \
+           error.message ? error.message : "(no message)");                        \
+    }                                                                              \
+    if (error.release) error.release(&error);                                      \
+  } while (0)
+
+#define EXPECT_OK(call) EXPECT_STATUS(ADBC_STATUS_OK, call)
+
+// Reads a stream to the end, returning its number of rows.
+static int64_t CountRows(struct ArrowArrayStream* stream) {
+  int64_t rows = 0;
+  for (;;) {
+    struct ArrowArray array;
+    if (stream->get_next(stream, &array) != 0) {
+      FAIL("get_next: %s", stream->get_last_error(stream));
+    }
+    if (array.release == NULL) break;
+    rows += array.length;
+    array.release(&array);
+  }
+  return rows;
+}
+
+static void TestDatabaseOptions(const char* uri) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+  struct AdbcDatabase database;
+  memset(&database, 0, sizeof(database));
+
+  EXPECT_OK(driver.DatabaseNew(&database, &error));
+  EXPECT_OK(driver.DatabaseSetOption(&database, "uri", uri, &error));
+
+  // a buffer too small is left alone, with the length required
+  char value[256];
+  size_t length = 1;
+  value[0] = 'x';
+  EXPECT_OK(driver.DatabaseGetOption(&database, "uri", value, &length, &error));
+  if (length != strlen(uri) + 1 || value[0] != 'x') {
+    FAIL("expected length %zu and the buffer untouched, got %zu", strlen(uri) + 1,
+         length);
+  }
+  length = sizeof(value);
+  EXPECT_OK(driver.DatabaseGetOption(&database, "uri", value, &length, &error));
+  if (strcmp(value, uri) != 0) FAIL("expected uri '%s', got '%s'", uri, value);
+
+  uint8_t bytes[256];
+  length = sizeof(bytes);
+  EXPECT_OK(driver.DatabaseGetOptionBytes(&database, "uri", bytes, &length, &error));
+  if (length != strlen(uri) || memcmp(bytes, uri, length) != 0) {
+    FAIL("expected the uri as bytes");
+  }
+
+  length = sizeof(value);
+  EXPECT_STATUS(ADBC_STATUS_NOT_FOUND,
+                driver.DatabaseGetOption(&database, "missing", value, &length, &error));
+  int64_t i = 0;
+  EXPECT_STATUS(ADBC_STATUS_INVALID_ARGUMENT,
+                driver.DatabaseGetOptionInt(&database, "uri", &i, &error));
+
+  EXPECT_OK(driver.DatabaseSetOptionInt(
+      &database, "adbc.flight.sql.client_option.with_max_msg_size", 1024, &error));
+  EXPECT_OK(driver.DatabaseGetOptionInt(
+      &database, "adbc.flight.sql.client_option.with_max_msg_size", &i, &error));
+  if (i != 1024) FAIL("expected 1024, got %lld", (long long)i);
+
+  double d = 0;
+  EXPECT_OK(driver.DatabaseSetOptionDouble(
+      &database, "adbc.flight.sql.rpc.timeout_seconds.fetch", 1.5, &error));
+  EXPECT_OK(driver.DatabaseGetOptionDouble(
+      &database, "adbc.flight.sql.rpc.timeout_seconds.fetch", &d, &error));
+  if (d != 1.5) FAIL("expected 1.5, got %f", d);
+  length = sizeof(value);
+  EXPECT_OK(driver.DatabaseGetOption(
+      &database, "adbc.flight.sql.rpc.timeout_seconds.fetch", value, &length, &error));
+  if (strcmp(value, "1.5") != 0) FAIL("expected '1.5', got '%s'", value);
+
+  EXPECT_OK(driver.DatabaseRelease(&database, &error));
+}
+
+static void TestConnectionOptions(struct AdbcConnection* connection) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+
+  EXPECT_OK(driver.ConnectionSetOptionDouble(
+      connection, "adbc.flight.sql.rpc.timeout_seconds.fetch", 2.5, &error));
+  EXPECT_OK(driver.ConnectionSetOptionInt(
+      connection, "adbc.flight.sql.rpc.timeout_seconds.update", 3, &error));
+  EXPECT_STATUS(ADBC_STATUS_INVALID_ARGUMENT,
+                driver.ConnectionSetOptionDouble(
+                    connection, "adbc.flight.sql.rpc.timeout_seconds.update", -1, &error));
+
+  const uint8_t bytes[] = {1, 2, 3};
+  EXPECT_STATUS(ADBC_STATUS_NOT_IMPLEMENTED,
+                driver.ConnectionSetOptionBytes(connection, "adbc.test.bytes", bytes,
+                                                sizeof(bytes), &error));
+
+  char value[256];
+  size_t length = sizeof(value);
+  EXPECT_STATUS(ADBC_STATUS_NOT_FOUND,
+                driver.ConnectionGetOption(connection, ADBC_CONNECTION_OPTION_AUTOCOMMIT,
+                                           value, &length, &error));
+}
+
+static void TestStatementOptions(struct AdbcConnection* connection) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+  struct AdbcStatement statement;
+  memset(&statement, 0, sizeof(statement));
+
+  EXPECT_OK(driver.StatementNew(connection, &statement, &error));
+  EXPECT_OK(driver.StatementSetOptionInt(&statement, "adbc.rpc.result_queue_size", 10,
+                                         &error));
+  EXPECT_STATUS(ADBC_STATUS_INVALID_ARGUMENT,
+                driver.StatementSetOptionInt(&statement, "adbc.rpc.result_queue_size",
+                                             -1, &error));
+  int64_t i = 0;
+  EXPECT_STATUS(ADBC_STATUS_NOT_FOUND,
+                driver.StatementGetOptionInt(&statement, "adbc.rpc.result_queue_size",
+                                             &i, &error));
+  EXPECT_OK(driver.StatementRelease(&statement, &error));
+}
+
+static void TestStatistics(struct AdbcConnection* connection) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+  struct ArrowArrayStream stream;
+
+  EXPECT_OK(driver.ConnectionGetStatisticNames(connection, &stream, &error));
+  int64_t rows = CountRows(&stream);
+  if (rows != 1) FAIL("expected 1 statistic name, got %lld", (long long)rows);
+
+  // errors cannot be had from the streams
+  AdbcStatusCode status = ADBC_STATUS_OK;
+  if (driver.ErrorFromArrayStream(&stream, &status) != NULL ||
+      status != ADBC_STATUS_NOT_IMPLEMENTED) {
+    FAIL("expected ErrorFromArrayStream to be unsupported, got status %d", status);
+  }
+  stream.release(&stream);
+
+  EXPECT_OK(driver.ConnectionGetStatistics(connection, NULL, NULL, "foo", 1, &stream,
+                                           &error));
+  rows = CountRows(&stream);
+  if (rows != 1) FAIL("expected 1 catalog, got %lld", (long long)rows);
+  stream.release(&stream);
+}
+
+static void TestCancel(struct AdbcConnection* connection) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+  struct ArrowArrayStream stream;
+
+  // there is nothing to cancel until a call is made
+  EXPECT_STATUS(ADBC_STATUS_INVALID_STATE, driver.ConnectionCancel(connection, &error));
+
+  // the stream can be cancelled until it is released
+  EXPECT_OK(driver.ConnectionGetStatisticNames(connection, &stream, &error));
+  EXPECT_OK(driver.ConnectionCancel(connection, &error));
+  stream.release(&stream);
+  EXPECT_STATUS(ADBC_STATUS_INVALID_STATE, driver.ConnectionCancel(connection, &error));
+
+  // the calls which follow get a new context
+  EXPECT_OK(driver.ConnectionGetStatisticNames(connection, &stream, &error));
+  int64_t rows = CountRows(&stream);
+  if (rows != 1) FAIL("expected 1 statistic name, got %lld", (long long)rows);
+  stream.release(&stream);
+}
+
+static void TestErrorDetails(struct AdbcConnection* connection) {
+  struct AdbcError error = ADBC_ERROR_INIT;
+  struct AdbcStatement statement;
+  struct ArrowArrayStream stream;
+  memset(&statement, 0, sizeof(statement));
+
+  EXPECT_OK(driver.StatementNew(connection, &statement, &error));
+  EXPECT_OK(driver.StatementSetSqlQuery(&statement, "SELECT 1", &error));
+
+  if (driver.StatementExecuteQuery(&statement, &stream, NULL, &error) !=
+      ADBC_STATUS_INVALID_ARGUMENT) {
+    FAIL("expected the query to fail");
+  }
+  if (error.vendor_code != ADBC_ERROR_VENDOR_CODE_PRIVATE_DATA) {
+    FAIL("expected the vendor code to be kept, got %d", error.vendor_code);
+  }
+  int count = driver.ErrorGetDetailCount(&error);
+  if (count != 2) FAIL("expected 2 details, got %d", count);
+
+  // the gRPC status details come first, then the trailers
+  struct AdbcErrorDetail detail = driver.ErrorGetDetail(&error, 0);
+  if (strcmp(detail.key, "type.googleapis.com/google.protobuf.StringValue") != 0) {
+    FAIL("unexpected detail key '%s'", detail.key);
+  }
+  detail = driver.ErrorGetDetail(&error, 1);
+  if (strcmp(detail.key, "x-test") != 0 || detail.value_length != 5 ||
+      memcmp(detail.value, "value", 5) != 0) {
+    FAIL("unexpected detail '%s'", detail.key);
+  }
+  detail = driver.ErrorGetDetail(&error, 2);
+  if (detail.key != NULL || detail.value != NULL || detail.value_length != 0) {
+    FAIL("expected an empty detail past the end");
+  }
+  error.release(&error);
+
+  // an ADBC 1.0 error has no details
+  struct AdbcError old_error;
+  memset(&old_error, 0, sizeof(old_error));
+  if (driver.StatementExecuteQuery(&statement, &stream, NULL, &old_error) !=
+      ADBC_STATUS_INVALID_ARGUMENT) {
+    FAIL("expected the query to fail");
+  }
+  if (old_error.vendor_code != 0 || old_error.private_data != NULL) {
+    FAIL("expected no details in an ADBC 1.0 error");
+  }
+  old_error.release(&old_error);
+
+  EXPECT_OK(driver.StatementRelease(&statement, &error));
+}
+
+int main(int argc, char** argv) {
+  if (argc != 2) {
+    fprintf(stderr, "usage: %s URI\n", argv[0]);
+    return 2;
+  }
+  const char* uri = argv[1];
+
+  struct AdbcError error = ADBC_ERROR_INIT;
+  memset(&driver, 0, sizeof(driver));
+  EXPECT_STATUS(ADBC_STATUS_NOT_IMPLEMENTED, AdbcDriverInit(42, &driver, &error));
+  EXPECT_OK(AdbcDriverInit(ADBC_VERSION_1_1_0, &driver, &error));
+
+  TestDatabaseOptions(uri);
+
+  struct AdbcDatabase database;
+  struct AdbcConnection connection;
+  memset(&database, 0, sizeof(database));
+  memset(&connection, 0, sizeof(connection));
+  EXPECT_OK(driver.DatabaseNew(&database, &error));
+  EXPECT_OK(driver.DatabaseSetOption(&database, "uri", uri, &error));
+  EXPECT_OK(driver.DatabaseSetOptionInt(
+      &database, "adbc.flight.sql.client_option.with_max_msg_size", 1 << 24, &error));
+  EXPECT_OK(driver.DatabaseInit(&database, &error));
+
+  // the options set before init can still be read
+  int64_t i = 0;
+  EXPECT_OK(driver.DatabaseGetOptionInt(
+      &database, "adbc.flight.sql.client_option.with_max_msg_size", &i, &error));
+  if (i != 1 << 24) FAIL("expected %d, got %lld", 1 << 24, (long long)i);
+
+  EXPECT_OK(driver.ConnectionNew(&connection, &error));
+  EXPECT_OK(driver.ConnectionInit(&connection, &database, &error));
+
+  TestConnectionOptions(&connection);
+  TestStatementOptions(&connection);
+  TestStatistics(&connection);
+  TestCancel(&connection);
+  TestErrorDetails(&connection);
+
+  EXPECT_OK(driver.ConnectionRelease(&connection, &error));
+  EXPECT_OK(driver.DatabaseRelease(&database, &error));
+  return 0;
+}
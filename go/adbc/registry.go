@@ -0,0 +1,170 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package adbc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var registry = struct {
+	sync.Mutex
+	drivers map[string]Driver
+	// schemes maps the URI schemes to the names of their drivers
+	schemes  map[string]string
+	watchers []func(name string, drv Driver)
+}{
+	drivers: make(map[string]Driver),
+	schemes: make(map[string]string),
+}
+
+// RegisterDriver makes a driver available under the given name, and
+// for the given URI schemes to Open. Drivers usually register
+// themselves in the init function of their package, so that importing
+// the package is enough to use them:
+//
+//	import _ "github.com/apache/arrow-adbc/go/adbc/driver/flightsql"
+//
+//	db, err := adbc.Open(ctx, "grpc+tls://localhost:31337", nil)
+//
+// Schemes are case insensitive. RegisterDriver panics if the driver is
+// nil, or if the name or one of the schemes is already registered.
+func RegisterDriver(name string, drv Driver, schemes ...string) {
+	if drv == nil {
+		panic("adbc: RegisterDriver driver is nil")
+	}
+
+	registry.Lock()
+	if _, dup := registry.drivers[name]; dup {
+		registry.Unlock()
+		panic("adbc: RegisterDriver called twice for driver " + name)
+	}
+	for _, s := range schemes {
+		if other, dup := registry.schemes[strings.ToLower(s)]; dup {
+			registry.Unlock()
+			panic("adbc: RegisterDriver called twice for scheme " + s +
+				" (drivers " + other + " and " + name + ")")
+		}
+	}
+
+	registry.drivers[name] = drv
+	for _, s := range schemes {
+		registry.schemes[strings.ToLower(s)] = name
+	}
+	watchers := make([]func(string, Driver), len(registry.watchers))
+	copy(watchers, registry.watchers)
+	registry.Unlock()
+
+	for _, fn := range watchers {
+		fn(name, drv)
+	}
+}
+
+// WatchDrivers calls fn for each registered driver, then for each
+// driver registered afterwards, as they are registered. This lets a
+// package act on drivers whatever the order in which packages are
+// initialized, which is how sqldriver registers each of them with
+// database/sql.
+//
+// fn is called without holding the lock of the registry, so it may
+// itself register or look up drivers, but it may be called from
+// several goroutines at once if drivers are registered concurrently.
+func WatchDrivers(fn func(name string, drv Driver)) {
+	registry.Lock()
+	names := sortedDrivers()
+	drivers := make([]Driver, len(names))
+	for i, name := range names {
+		drivers[i] = registry.drivers[name]
+	}
+	registry.watchers = append(registry.watchers, fn)
+	registry.Unlock()
+
+	for i, name := range names {
+		fn(name, drivers[i])
+	}
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	registry.Lock()
+	defer registry.Unlock()
+	return sortedDrivers()
+}
+
+func sortedDrivers() []string {
+	names := make([]string, 0, len(registry.drivers))
+	for name := range registry.drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupDriver returns the driver registered under the given name.
+func LookupDriver(name string) (Driver, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	drv, ok := registry.drivers[name]
+	return drv, ok
+}
+
+// LookupScheme returns the name of the driver registered for the
+// scheme of the given URI, and the driver itself.
+func LookupScheme(uri string) (string, Driver, bool) {
+	idx := strings.Index(uri, "://")
+	if idx <= 0 {
+		return "", nil, false
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	name, ok := registry.schemes[strings.ToLower(uri[:idx])]
+	if !ok {
+		return "", nil, false
+	}
+	return name, registry.drivers[name], true
+}
+
+// Open creates a database with the driver registered for the scheme
+// of the URI, such as grpc+tls:// for Flight SQL, snowflake:// or
+// sqlite://. The URI is given to the driver as OptionKeyURI, along
+// with the other options, which may be nil.
+func Open(ctx context.Context, uri string, opts map[string]string) (Database, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Error{Msg: "[adbc] " + err.Error(), Code: StatusCancelled}.WithErr(err)
+	}
+
+	_, drv, ok := LookupScheme(uri)
+	if !ok {
+		// the URI itself is left out as it may hold credentials
+		msg := "[adbc] URI has no scheme"
+		if idx := strings.Index(uri, "://"); idx > 0 {
+			msg = "[adbc] no driver registered for URI scheme '" + uri[:idx] + "'"
+		}
+		return nil, Error{Msg: msg, Code: StatusNotFound}
+	}
+
+	dbOpts := make(map[string]string, len(opts)+1)
+	for k, v := range opts {
+		dbOpts[k] = v
+	}
+	dbOpts[OptionKeyURI] = uri
+	return drv.NewDatabase(dbOpts)
+}
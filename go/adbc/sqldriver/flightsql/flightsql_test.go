@@ -120,6 +120,17 @@ func (suite *SQLDriverFlightSQLSuite) TestQuery() {
 	}
 }
 
+func (suite *SQLDriverFlightSQLSuite) TestQueryURL() {
+	db, err := sql.Open("flightsql", "grpc+tcp://"+suite.s.Addr().String()+
+		"?adbc.flight.sql.rpc.call_header.x-test=a%3Bb")
+	suite.Require().NoError(err)
+	defer db.Close()
+
+	var actual int
+	suite.Require().NoError(db.QueryRow("SELECT 42").Scan(&actual))
+	suite.Equal(42, actual)
+}
+
 func TestSQLDriverFlightSQL(t *testing.T) {
 	suite.Run(t, new(SQLDriverFlightSQLSuite))
 }
@@ -0,0 +1,381 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// OptionKeyPlaceholderStyle is the option enabling the rewriting of
+// the placeholders of queries into the style native to the driver,
+// given as its value. Queries may then use any of ? for positional
+// parameters, $1, $2... for numbered parameters, or :name and @name
+// for named parameters, which are bound to the arguments given with
+// sql.Named. Only the names of arguments are taken as placeholders, so
+// that other uses of : and @, such as the stages (@stage) and paths
+// (v:field) of Snowflake, are left as they are. A query cannot mix
+// these kinds of placeholders.
+//
+// The option is used by this package rather than passed to the
+// driver, and queries are used as they are without it.
+const OptionKeyPlaceholderStyle = "adbc.sqldriver.placeholder_style"
+
+// Placeholder styles, the values of OptionKeyPlaceholderStyle.
+const (
+	// PlaceholderQMark is ? for each parameter, in order.
+	PlaceholderQMark = "qmark"
+	// PlaceholderDollar is $1, $2... for numbered parameters.
+	PlaceholderDollar = "dollar"
+	// PlaceholderColon is :name for named parameters. Positional
+	// parameters are named p1, p2...
+	PlaceholderColon = "colon"
+	// PlaceholderAt is @name for named parameters. Positional
+	// parameters are named p1, p2...
+	PlaceholderAt = "at"
+)
+
+func isPlaceholderStyle(style string) bool {
+	switch style {
+	case PlaceholderQMark, PlaceholderDollar, PlaceholderColon, PlaceholderAt:
+		return true
+	}
+	return false
+}
+
+// placeholderKind is the kind of placeholders a query uses
+type placeholderKind int
+
+const (
+	placeholderNone placeholderKind = iota
+	placeholderPositional
+	placeholderNumbered
+	placeholderNamed
+)
+
+func (k placeholderKind) String() string {
+	switch k {
+	case placeholderPositional:
+		return "positional (?)"
+	case placeholderNumbered:
+		return "numbered ($1)"
+	case placeholderNamed:
+		return "named (:name or @name)"
+	}
+	return "none"
+}
+
+// param is a parameter of a query, either named or given by the
+// 1-based ordinal of its argument.
+type param struct {
+	name    string
+	ordinal int
+}
+
+func (p param) String() string {
+	if p.name != "" {
+		return "'" + p.name + "'"
+	}
+	return strconv.Itoa(p.ordinal)
+}
+
+// rewrittenQuery is a query whose placeholders were rewritten into the
+// native style of the driver.
+type rewrittenQuery struct {
+	sql  string
+	kind placeholderKind
+	// the parameters in the order of the placeholders of the
+	// rewritten query, which are the columns of the record bound
+	params []param
+	// the number of arguments expected
+	inputs int
+	// whether the query may have named placeholders, which were left
+	// as they are since the names of the arguments were not known
+	named bool
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '_'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// dollarTag returns the tag starting the dollar-quoted string at the
+// start of s, such as $$ or $tag$, or "" if there is none.
+func dollarTag(s string) string {
+	i := 1
+	if i < len(s) && isIdentStart(s[i]) {
+		for i < len(s) && isIdentChar(s[i]) {
+			i++
+		}
+	}
+	if i < len(s) && s[i] == '$' {
+		return s[:i+1]
+	}
+	return ""
+}
+
+// quoteEnd returns the index following the quote closing the quoted
+// part of the query starting at i, or -1 if it is unterminated. Quotes
+// escaped by a backslash are skipped within string literals, but not
+// within quoted identifiers, where a backslash is an ordinary
+// character.
+func quoteEnd(query string, i int) int {
+	c := query[i]
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if c == '\'' {
+				j++
+			}
+		case c:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// rewriteQuery rewrites the placeholders of a query into the given
+// style. Named placeholders are only those whose name is in names, the
+// names of the arguments; if names is nil, they are left as they are
+// and the query is reported as possibly having some. Placeholders
+// within string literals, dollar-quoted strings, quoted identifiers
+// and comments are left alone, as are :: casts and @@ variables, and so
+// are :name placeholders following an identifier, such as the path
+// v:name of a Snowflake variant.
+func rewriteQuery(query, style string, names map[string]bool) (*rewrittenQuery, error) {
+	var (
+		b    strings.Builder
+		q    = &rewrittenQuery{}
+		seen = make(map[param]int)
+	)
+
+	// add rewrites a placeholder of the query
+	add := func(kind placeholderKind, p param) error {
+		if q.kind != placeholderNone && q.kind != kind {
+			return paramError("query mixes %s and %s placeholders", q.kind, kind)
+		}
+		q.kind = kind
+
+		idx, ok := seen[p]
+		if !ok {
+			idx = len(seen) + 1
+			seen[p] = idx
+		}
+		switch kind {
+		case placeholderPositional:
+			q.inputs++
+		case placeholderNumbered:
+			if p.ordinal > q.inputs {
+				q.inputs = p.ordinal
+			}
+		case placeholderNamed:
+			q.inputs = len(seen)
+		}
+
+		switch style {
+		case PlaceholderQMark:
+			b.WriteByte('?')
+			q.params = append(q.params, p)
+			return nil
+		case PlaceholderDollar:
+			b.WriteString("$" + strconv.Itoa(idx))
+		default:
+			if style == PlaceholderColon {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte('@')
+			}
+			if p.name != "" {
+				b.WriteString(p.name)
+			} else {
+				b.WriteString("p" + strconv.Itoa(p.ordinal))
+			}
+		}
+		if !ok {
+			q.params = append(q.params, p)
+		}
+		return nil
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := quoteEnd(query, i)
+			if end < 0 {
+				return nil, paramError("unterminated quote %c in query", c)
+			}
+			// doubled quotes within are skipped as two quoted parts
+			b.WriteString(query[i:end])
+			i = end
+		case c == '$' && dollarTag(query[i:]) != "":
+			tag := dollarTag(query[i:])
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				return nil, paramError("unterminated dollar-quoted string in query")
+			}
+			end += i + 2*len(tag)
+			b.WriteString(query[i:end])
+			i = end
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, paramError("unterminated comment in query")
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 4
+		case strings.HasPrefix(query[i:], "::") || strings.HasPrefix(query[i:], "@@"):
+			b.WriteString(query[i : i+2])
+			i += 2
+			for i < len(query) && isIdentChar(query[i]) {
+				b.WriteByte(query[i])
+				i++
+			}
+		case c == '?':
+			if err := add(placeholderPositional, param{ordinal: q.inputs + 1}); err != nil {
+				return nil, err
+			}
+			i++
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			end := i + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			n, err := strconv.Atoi(query[i+1 : end])
+			if err != nil || n == 0 {
+				return nil, paramError("invalid placeholder %s", query[i:end])
+			}
+			if err := add(placeholderNumbered, param{ordinal: n}); err != nil {
+				return nil, err
+			}
+			i = end
+		case c == ':' && i > 0 && isIdentChar(query[i-1]):
+			b.WriteByte(c)
+			i++
+		case (c == ':' || c == '@') && i+1 < len(query) && isIdentStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && isIdentChar(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			if names == nil || !names[name] {
+				q.named = q.named || names == nil
+				b.WriteString(query[i:end])
+			} else if err := add(placeholderNamed, param{name: name}); err != nil {
+				return nil, err
+			}
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	q.sql = b.String()
+	return q, nil
+}
+
+// argNames returns the names of the arguments, which are the named
+// placeholders of a query: those given with sql.Named, or the columns
+// of a record or reader given as the only argument.
+func argNames(args []driver.NamedValue) map[string]bool {
+	names := make(map[string]bool)
+	for _, arg := range args {
+		var schema *arrow.Schema
+		switch v := arg.Value.(type) {
+		case arrow.Record:
+			schema = v.Schema()
+		case array.RecordReader:
+			schema = v.Schema()
+		}
+		if schema != nil {
+			for _, f := range schema.Fields() {
+				names[f.Name] = true
+			}
+		}
+		if arg.Name != "" {
+			names[arg.Name] = true
+		}
+	}
+	return names
+}
+
+// bind returns the record of the arguments to bind, with a column
+// for each parameter of the rewritten query.
+func (q *rewrittenQuery) bind(args []driver.NamedValue) (arrow.Record, error) {
+	values := make(map[param]driver.Value, len(args))
+	for _, arg := range args {
+		var p param
+		switch {
+		case q.kind == placeholderNamed && arg.Name == "":
+			return nil, paramError("query has named parameters, argument %d must be given with sql.Named", arg.Ordinal)
+		case q.kind == placeholderNamed:
+			p = param{name: arg.Name}
+		case arg.Name != "":
+			return nil, paramError("query has %s placeholders, cannot bind the argument named '%s'", q.kind, arg.Name)
+		default:
+			p = param{ordinal: arg.Ordinal}
+		}
+		if _, dup := values[p]; dup {
+			return nil, paramError("parameter %s is given more than once", p)
+		}
+		values[p] = arg.Value
+	}
+
+	used := make(map[param]bool, len(q.params))
+	fields := make([]arrow.Field, len(q.params))
+	cols := make([]arrow.Array, len(q.params))
+	for i, p := range q.params {
+		val, ok := values[p]
+		if !ok {
+			return nil, paramError("missing value for parameter %s", p)
+		}
+		used[p] = true
+
+		arr := arrFromVal(val)
+		defer arr.Release()
+		fields[i] = arrow.Field{Type: arr.DataType(), Nullable: true}
+		if p.name != "" {
+			fields[i].Name = p.name
+		} else {
+			fields[i].Name = strconv.Itoa(p.ordinal)
+		}
+		cols[i] = arr
+	}
+	for p := range values {
+		if !used[p] {
+			return nil, paramError("no parameter %s in the query", p)
+		}
+	}
+
+	return array.NewRecord(arrow.NewSchema(fields, nil), cols, 1), nil
+}
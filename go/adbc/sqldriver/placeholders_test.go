@@ -0,0 +1,191 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/adbcmock"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteQuery(t *testing.T) {
+	const literals = ` 'it''s :a ?' 'it\'s ?' "col?" ` + "`x@y`" + ` -- :c ?
+/* $1 */ x::int @@version $$ :x ? $$ $tag$ $1 $ $tag$`
+	names := map[string]bool{"x": true, "y": true}
+
+	tests := []struct {
+		query, style, sql string
+		names             map[string]bool
+		params            []param
+		inputs            int
+	}{
+		{"SELECT 1", PlaceholderQMark, "SELECT 1", nil, nil, 0},
+		{"a = ? AND b = ?" + literals, PlaceholderQMark, "a = ? AND b = ?" + literals,
+			nil, []param{{ordinal: 1}, {ordinal: 2}}, 2},
+		{"a = ? AND b = ?", PlaceholderDollar, "a = $1 AND b = $2",
+			nil, []param{{ordinal: 1}, {ordinal: 2}}, 2},
+		{"a = ? AND b = ?", PlaceholderColon, "a = :p1 AND b = :p2",
+			nil, []param{{ordinal: 1}, {ordinal: 2}}, 2},
+		{"a = $2 AND b = $1 AND c = $2", PlaceholderQMark, "a = ? AND b = ? AND c = ?",
+			nil, []param{{ordinal: 2}, {ordinal: 1}, {ordinal: 2}}, 2},
+		{"a = $2 AND b = $1 AND c = $2", PlaceholderDollar, "a = $1 AND b = $2 AND c = $1",
+			nil, []param{{ordinal: 2}, {ordinal: 1}}, 2},
+		{"a = :x AND b = @y AND c = :x" + literals, PlaceholderQMark, "a = ? AND b = ? AND c = ?" + literals,
+			names, []param{{name: "x"}, {name: "y"}, {name: "x"}}, 2},
+		{"a = :x AND b = @y AND c = :x", PlaceholderDollar, "a = $1 AND b = $2 AND c = $1",
+			names, []param{{name: "x"}, {name: "y"}}, 2},
+		{"a = :x AND b = @y AND c = :x", PlaceholderAt, "a = @x AND b = @y AND c = @x",
+			names, []param{{name: "x"}, {name: "y"}}, 2},
+		// only the names of arguments are placeholders
+		{"SELECT v:a FROM @stage WHERE x = :x", PlaceholderQMark, "SELECT v:a FROM @stage WHERE x = ?", names,
+			[]param{{name: "x"}}, 1},
+		{"SELECT v:a FROM @stage WHERE x = ?", PlaceholderDollar, "SELECT v:a FROM @stage WHERE x = $1", names,
+			[]param{{ordinal: 1}}, 1},
+		// nor are names following an identifier
+		{"SELECT v:x, v1:y FROM t WHERE x = (:x)", PlaceholderQMark, "SELECT v:x, v1:y FROM t WHERE x = (?)", names,
+			[]param{{name: "x"}}, 1},
+		// backslashes only escape quotes within string literals
+		{`SELECT "a\", ` + "`b\\`" + ` FROM t WHERE x = :x`, PlaceholderQMark, `SELECT "a\", ` + "`b\\`" + ` FROM t WHERE x = ?`, names,
+			[]param{{name: "x"}}, 1},
+	}
+	for _, tc := range tests {
+		q, err := rewriteQuery(tc.query, tc.style, tc.names)
+		require.NoError(t, err, tc.query)
+		assert.Equal(t, tc.sql, q.sql, tc.query)
+		assert.Equal(t, tc.params, q.params, tc.query)
+		assert.Equal(t, tc.inputs, q.inputs, tc.query)
+		assert.False(t, q.named, tc.query)
+	}
+
+	// without the names of the arguments, named placeholders are left
+	q, err := rewriteQuery("a = :x AND b = ? AND c = '@y'", PlaceholderDollar, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a = :x AND b = $1 AND c = '@y'", q.sql)
+	assert.True(t, q.named)
+
+	var adbcErr *adbc.Error
+	for _, tc := range []struct{ query, err string }{
+		{"a = ? AND b = :x", "query mixes positional (?) and named (:name or @name) placeholders"},
+		{"a = $1 AND b = ?", "query mixes numbered ($1) and positional (?) placeholders"},
+		{"a = $0", "invalid placeholder $0"},
+		{"a = 'b", "unterminated quote ' in query"},
+		{"a /* b", "unterminated comment in query"},
+		{`a = 'b\'`, "unterminated quote ' in query"},
+		{"a = $$b", "unterminated dollar-quoted string in query"},
+	} {
+		_, err := rewriteQuery(tc.query, PlaceholderQMark, names)
+		require.ErrorAs(t, err, &adbcErr, tc.query)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Equal(t, tc.err, adbcErr.Msg)
+	}
+}
+
+func TestRewrittenQueryBind(t *testing.T) {
+	q, err := rewriteQuery("a = :x AND b = :y AND c = :x", PlaceholderQMark, map[string]bool{"x": true, "y": true})
+	require.NoError(t, err)
+
+	rec, err := q.bind([]driver.NamedValue{
+		{Name: "y", Ordinal: 1, Value: "y"},
+		{Name: "x", Ordinal: 2, Value: int64(1)},
+	})
+	require.NoError(t, err)
+	defer rec.Release()
+	assert.Equal(t, []string{"x", "y", "x"}, []string{
+		rec.Schema().Field(0).Name, rec.Schema().Field(1).Name, rec.Schema().Field(2).Name})
+	assert.Equal(t, int64(1), rec.Column(0).(*array.Int64).Value(0))
+	assert.Equal(t, "y", rec.Column(1).(*array.String).Value(0))
+	assert.Equal(t, int64(1), rec.Column(2).(*array.Int64).Value(0))
+
+	var adbcErr *adbc.Error
+	for _, tc := range []struct {
+		query string
+		args  []driver.NamedValue
+		err   string
+	}{
+		{"a = :x", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}},
+			"no parameter 1 in the query"},
+		{"a = ?", []driver.NamedValue{{Name: "x", Ordinal: 1, Value: int64(1)}},
+			"query has positional (?) placeholders, cannot bind the argument named 'x'"},
+		{"a = :x", []driver.NamedValue{{Name: "x", Ordinal: 1, Value: int64(1)}, {Name: "x", Ordinal: 2, Value: int64(2)}},
+			"parameter 'x' is given more than once"},
+		{"a = :x AND b = :y", []driver.NamedValue{{Name: "x", Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: int64(2)}},
+			"query has named parameters, argument 2 must be given with sql.Named"},
+		{"a = $2", []driver.NamedValue{{Ordinal: 1, Value: int64(1)}},
+			"missing value for parameter 2"},
+	} {
+		q, err := rewriteQuery(tc.query, PlaceholderQMark, argNames(tc.args))
+		require.NoError(t, err)
+		_, err = q.bind(tc.args)
+		require.ErrorAs(t, err, &adbcErr, tc.query)
+		assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+		assert.Equal(t, tc.err, adbcErr.Msg)
+	}
+}
+
+func TestPlaceholderStyle(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil)
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.StringBuilder).Append("a")
+	bldr.Field(1).(*array.Int64Builder).Append(1)
+	args := bldr.NewRecord()
+	defer args.Release()
+
+	mock := adbcmock.New()
+	mock.ExpectNewDatabase().WithOptions(map[string]string{adbc.OptionKeyURI: "test://"})
+	connector, err := NewConnector(mock, map[string]string{
+		adbc.OptionKeyURI:         "test://",
+		OptionKeyPlaceholderStyle: PlaceholderDollar,
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectPrepare(`UPDATE t SET name = \$1 WHERE id = \$2`)
+	mock.ExpectExec(`UPDATE t SET name = \$1 WHERE id = \$2`).WithArgs(args).WillReturnResult(1)
+	_, err = db.ExecContext(ctx, "UPDATE t SET name = :name WHERE id = :id",
+		sql.Named("name", "a"), sql.Named("id", 1))
+	require.NoError(t, err)
+
+	mock.ExpectPrepare(`SELECT`)
+	_, err = db.ExecContext(ctx, "SELECT :a", 1)
+	var adbcErr *adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = NewConnector(mock, map[string]string{OptionKeyPlaceholderStyle: "percent"})
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+}
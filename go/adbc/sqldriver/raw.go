@@ -0,0 +1,206 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow/go/v12/arrow/array"
+)
+
+// Conn is implemented by the connections of this package, to access
+// the ADBC connection of a database/sql connection with sql.Conn.Raw:
+//
+//	err = sqlConn.Raw(func(driverConn any) error {
+//		cnxn := driverConn.(sqldriver.Conn).AdbcConnection()
+//		rdr, err := cnxn.GetTableTypes(ctx)
+//		...
+//	})
+//
+// The ADBC connection must not be closed, and must only be used until
+// the sql.Conn is closed, when it is returned to the pool of the
+// database.
+type Conn interface {
+	driver.Conn
+	// AdbcConnection returns the ADBC connection of the connection.
+	AdbcConnection() adbc.Connection
+}
+
+var _ Conn = (*conn)(nil)
+
+func (c *conn) AdbcConnection() adbc.Connection { return c.Conn }
+
+// QueryArrow runs a query on a connection of this package and returns
+// its result as an array.RecordReader, rather than converting it into
+// rows, so that the same pooled connection can be used for row-wise
+// and columnar access:
+//
+//	sqlConn, err := db.Conn(ctx)
+//	...
+//	defer sqlConn.Close()
+//	rdr, err := sqldriver.QueryArrow(ctx, sqlConn, "SELECT * FROM t WHERE id > ?", []any{10})
+//	...
+//	defer rdr.Release()
+//	for rdr.Next() {
+//		rec := rdr.Record()
+//		...
+//	}
+//
+// The arguments are bound as by sql.Conn.QueryContext, including
+// those given with sql.Named, or an arrow.Record or
+// array.RecordReader given as the only argument. As the reader uses
+// the connection, the connection is held as by sql.Conn.Raw until the
+// reader is released: the reader must be released, and the sql.Conn
+// cannot be used or closed until then.
+func QueryArrow(ctx context.Context, sqlConn *sql.Conn, query string, args []any) (array.RecordReader, error) {
+	type result struct {
+		rdr array.RecordReader
+		err error
+	}
+	var (
+		started = make(chan result, 1)
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+
+	// Raw is run until the reader is released, keeping the connection
+	go func() {
+		defer close(done)
+		err := sqlConn.Raw(func(driverConn any) error {
+			c, ok := driverConn.(*conn)
+			if !ok {
+				return &adbc.Error{
+					Msg:  "the connection does not use an ADBC driver",
+					Code: adbc.StatusInvalidArgument,
+				}
+			}
+
+			namedArgs, err := namedValues(args)
+			if err != nil {
+				return err
+			}
+			return c.queryArrow(ctx, query, namedArgs, func(rdr array.RecordReader) error {
+				started <- result{rdr: rdr}
+				<-release
+				return nil
+			})
+		})
+		if err != nil {
+			started <- result{err: err}
+		}
+	}()
+
+	res := <-started
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &rawReader{RecordReader: res.rdr, refCount: 1, release: release, done: done}, nil
+}
+
+// rawReader is the reader returned by QueryArrow, which ends the call
+// of sql.Conn.Raw holding the connection once released.
+type rawReader struct {
+	array.RecordReader
+	refCount int64
+	release  chan struct{}
+	done     chan struct{}
+}
+
+func (r *rawReader) Retain() {
+	atomic.AddInt64(&r.refCount, 1)
+}
+
+// Release releases the reader of the result and the connection,
+// returning once the connection can be used again.
+func (r *rawReader) Release() {
+	if atomic.AddInt64(&r.refCount, -1) == 0 {
+		close(r.release)
+		<-r.done
+	}
+}
+
+// namedValues converts arguments as the sql package does before
+// passing them to drivers, keeping records and record readers as they
+// are.
+func namedValues(args []any) ([]driver.NamedValue, error) {
+	out := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nv := &out[i]
+		nv.Ordinal = i + 1
+		if named, ok := arg.(sql.NamedArg); ok {
+			nv.Name, arg = named.Name, named.Value
+		}
+		if isRecordArg(arg) {
+			nv.Value = arg
+			continue
+		}
+
+		v, err := driver.DefaultParameterConverter.ConvertValue(arg)
+		if err != nil {
+			return nil, &adbc.Error{
+				Msg:  "cannot convert argument " + describeArg(*nv) + ": " + err.Error(),
+				Code: adbc.StatusInvalidArgument,
+			}
+		}
+		nv.Value = v
+	}
+	return out, nil
+}
+
+func describeArg(nv driver.NamedValue) string {
+	if nv.Name != "" {
+		return "'" + nv.Name + "'"
+	}
+	return param{ordinal: nv.Ordinal}.String()
+}
+
+// queryArrow executes a query as QueryContext does, passing the reader
+// of its result to fn, after which the reader is released.
+func (c *conn) queryArrow(ctx context.Context, query string, args []driver.NamedValue, fn func(array.RecordReader) error) error {
+	rewritten, err := c.rewriteQuery(query, argNames(args))
+	if err != nil {
+		return err
+	}
+	if rewritten != nil {
+		query = rewritten.sql
+	}
+
+	s, err := c.Conn.NewStatement()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.SetSqlQuery(query); err != nil {
+		return err
+	}
+
+	st := &stmt{stmt: s, query: rewritten}
+	if err := st.bind(ctx, args); err != nil {
+		return err
+	}
+	rdr, _, err := s.ExecuteQuery(ctx)
+	if err != nil {
+		return err
+	}
+	defer rdr.Release()
+	return fn(rdr)
+}
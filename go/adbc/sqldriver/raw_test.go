@@ -0,0 +1,114 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sqldriver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-adbc/go/adbc"
+	"github.com/apache/arrow-adbc/go/adbc/adbcmock"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawConn(t *testing.T) {
+	mock := adbcmock.New()
+	db := openMockDB(t, mock)
+	ctx := context.Background()
+
+	sqlConn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer sqlConn.Close()
+
+	mock.ExpectGetTableTypes()
+	require.NoError(t, sqlConn.Raw(func(driverConn any) error {
+		rdr, err := driverConn.(Conn).AdbcConnection().GetTableTypes(ctx)
+		if err != nil {
+			return err
+		}
+		rdr.Release()
+		return nil
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryArrow(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer mem.AssertSize(t, 0)
+
+	argSchema := arrow.NewSchema([]arrow.Field{{Name: "1", Type: arrow.PrimitiveTypes.Int64}}, nil)
+	argBldr := array.NewRecordBuilder(mem, argSchema)
+	defer argBldr.Release()
+	argBldr.Field(0).(*array.Int64Builder).Append(10)
+	args := argBldr.NewRecord()
+	defer args.Release()
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: arrow.BinaryTypes.String}}, nil)
+	bldr := array.NewRecordBuilder(mem, schema)
+	defer bldr.Release()
+	bldr.Field(0).(*array.StringBuilder).AppendValues([]string{"a", "b"}, nil)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+	result, err := array.NewRecordReader(schema, []arrow.Record{rec})
+	require.NoError(t, err)
+
+	mock := adbcmock.New()
+	db := openMockDB(t, mock)
+	ctx := context.Background()
+
+	sqlConn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer sqlConn.Close()
+
+	mock.ExpectQuery(`SELECT v FROM t WHERE id > \?`).WithArgs(args).WillReturnRows(result)
+	rdr, err := QueryArrow(ctx, sqlConn, "SELECT v FROM t WHERE id > ?", []any{10})
+	require.NoError(t, err)
+	assert.True(t, schema.Equal(rdr.Schema()))
+	require.True(t, rdr.Next())
+	assert.True(t, array.RecordEqual(rec, rdr.Record()))
+	assert.False(t, rdr.Next())
+	assert.NoError(t, rdr.Err())
+	rdr.Retain()
+	rdr.Release()
+	rdr.Release()
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// the error of the query is returned
+	errQuery := adbc.Error{Msg: "no table t", Code: adbc.StatusNotFound}
+	mock.ExpectQuery(`SELECT v FROM t`).WillReturnError(errQuery)
+	_, err = QueryArrow(ctx, sqlConn, "SELECT v FROM t", nil)
+	assert.ErrorIs(t, err, errQuery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// the connection is usable row-wise once the reader is released
+	mock.ExpectQuery(`SELECT 1`)
+	rows, err := sqlConn.QueryContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = QueryArrow(ctx, sqlConn, "SELECT ?", []any{struct{}{}})
+	var adbcErr *adbc.Error
+	require.ErrorAs(t, err, &adbcErr)
+	assert.Equal(t, adbc.StatusInvalidArgument, adbcErr.Code)
+	assert.Contains(t, adbcErr.Msg, "cannot convert argument 1")
+}
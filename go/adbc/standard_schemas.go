@@ -92,6 +92,39 @@ var (
 		{Name: "catalog_db_schemas", Type: arrow.ListOf(DBSchemaSchema), Nullable: true},
 	}, nil)
 
+	StatisticsValueTypeUnion = arrow.DenseUnionOf(
+		[]arrow.Field{
+			{Name: "int64", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+			{Name: "uint64", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+			{Name: "float64", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+			{Name: "binary", Type: arrow.BinaryTypes.Binary, Nullable: true},
+		},
+		[]arrow.UnionTypeCode{0, 1, 2, 3},
+	)
+
+	StatisticsSchema = arrow.StructOf(
+		arrow.Field{Name: "table_name", Type: arrow.BinaryTypes.String},
+		arrow.Field{Name: "column_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		arrow.Field{Name: "statistic_key", Type: arrow.PrimitiveTypes.Int16},
+		arrow.Field{Name: "statistic_value", Type: StatisticsValueTypeUnion},
+		arrow.Field{Name: "statistic_is_approximate", Type: arrow.FixedWidthTypes.Boolean},
+	)
+
+	StatisticsDBSchemaSchema = arrow.StructOf(
+		arrow.Field{Name: "db_schema_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		arrow.Field{Name: "db_schema_statistics", Type: arrow.ListOf(StatisticsSchema)},
+	)
+
+	GetStatisticsSchema = arrow.NewSchema([]arrow.Field{
+		{Name: "catalog_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "catalog_db_schemas", Type: arrow.ListOf(StatisticsDBSchemaSchema)},
+	}, nil)
+
+	GetStatisticNamesSchema = arrow.NewSchema([]arrow.Field{
+		{Name: "statistic_name", Type: arrow.BinaryTypes.String},
+		{Name: "statistic_key", Type: arrow.PrimitiveTypes.Int16},
+	}, nil)
+
 	GetTableSchemaSchema = arrow.NewSchema([]arrow.Field{
 		{Name: "catalog_name", Type: arrow.BinaryTypes.String, Nullable: true},
 		{Name: "db_schema_name", Type: arrow.BinaryTypes.String, Nullable: true},
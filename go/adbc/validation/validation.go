@@ -23,6 +23,7 @@ package validation
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/apache/arrow/go/v12/arrow"
 	"github.com/apache/arrow/go/v12/arrow/array"
 	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -70,6 +72,84 @@ type DriverQuirks interface {
 	Alloc() memory.Allocator
 }
 
+// IngestModeQuirks can be implemented by the DriverQuirks of drivers
+// which support bulk ingest, to tell which of the ingest modes they
+// support. Without it, only the create and append modes are tested.
+type IngestModeQuirks interface {
+	// Whether the driver supports bulk ingest using the given ingest
+	// mode (one of the adbc.OptionValueIngestMode* values)
+	SupportsBulkIngestMode(mode string) bool
+}
+
+// getInfoValue returns the value the connection reports for an info
+// code through GetInfo, as a bool or a []string, or false if it does
+// not report it.
+func getInfoValue(ctx context.Context, cnxn adbc.Connection, code adbc.InfoCode) (interface{}, bool) {
+	rdr, err := cnxn.GetInfo(ctx, []adbc.InfoCode{code})
+	if err != nil {
+		return nil, false
+	}
+	defer rdr.Release()
+
+	for rdr.Next() {
+		rec := rdr.Record()
+		codeCol := rec.Column(0).(*array.Uint32)
+		valUnion := rec.Column(1).(*array.DenseUnion)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			if adbc.InfoCode(codeCol.Value(i)) != code {
+				continue
+			}
+
+			offset := int(valUnion.ValueOffset(i))
+			child := valUnion.Field(valUnion.ChildID(i))
+			if child.IsNull(offset) {
+				return nil, false
+			}
+			switch child := child.(type) {
+			case *array.Boolean:
+				return child.Value(offset), true
+			case *array.List:
+				values, ok := child.ListValues().(*array.String)
+				if !ok {
+					return nil, false
+				}
+				start, end := child.ValueOffsets(offset)
+				strs := []string{}
+				for j := int(start); j < int(end); j++ {
+					strs = append(strs, values.Value(j))
+				}
+				return strs, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// supportsFeature returns the quirk telling whether the driver
+// supports a feature, asserting that GetInfo does not report it as
+// unsupported when the quirk says it is. A quirk saying it is not may
+// just leave the feature untested.
+func supportsFeature(t *testing.T, ctx context.Context, cnxn adbc.Connection, code adbc.InfoCode, quirk bool) bool {
+	if quirk {
+		if v, ok := getInfoValue(ctx, cnxn, code); ok {
+			assert.Equal(t, true, v, "the quirk and GetInfo disagree on %s", code)
+		}
+	}
+	return quirk
+}
+
+// supportsIngestMode returns the quirk telling whether the driver
+// supports bulk ingestion with the given mode, asserting that GetInfo
+// reports the mode when the quirk says it is supported.
+func supportsIngestMode(t *testing.T, ctx context.Context, cnxn adbc.Connection, mode string, quirk bool) bool {
+	if quirk {
+		if v, ok := getInfoValue(ctx, cnxn, adbc.InfoFeatureBulkIngestModes); ok {
+			assert.Contains(t, v, mode, "the quirk and GetInfo disagree on %s", adbc.InfoFeatureBulkIngestModes)
+		}
+	}
+	return quirk
+}
+
 type DatabaseTests struct {
 	suite.Suite
 
@@ -173,7 +253,7 @@ func (c *ConnectionTests) TestAutocommitToggle() {
 	cnxn, _ := c.DB.Open(ctx)
 	defer cnxn.Close()
 
-	if !c.Quirks.SupportsTransactions() {
+	if !supportsFeature(c.T(), ctx, cnxn, adbc.InfoFeatureTransactions, c.Quirks.SupportsTransactions()) {
 		return
 	}
 
@@ -232,6 +312,42 @@ func (c *ConnectionTests) TestMetadataGetInfo() {
 	}
 }
 
+func (c *ConnectionTests) TestMetadataGetInfoFeatures() {
+	ctx := context.Background()
+	cnxn, _ := c.DB.Open(ctx)
+	defer cnxn.Close()
+
+	// drivers need not report the features, but those they report
+	// must have the right type
+	for _, code := range []adbc.InfoCode{
+		adbc.InfoVendorSql,
+		adbc.InfoVendorSubstrait,
+		adbc.InfoFeaturePartitions,
+		adbc.InfoFeatureTransactions,
+		adbc.InfoFeatureSavepoints,
+		adbc.InfoFeatureCancel,
+		adbc.InfoFeatureParameterSchema,
+	} {
+		if v, ok := getInfoValue(ctx, cnxn, code); ok {
+			c.IsType(true, v, code.String())
+		}
+	}
+
+	if v, ok := getInfoValue(ctx, cnxn, adbc.InfoFeatureBulkIngestModes); ok {
+		c.IsType([]string{}, v)
+		modes, _ := v.([]string)
+		for _, mode := range modes {
+			c.Contains([]string{adbc.OptionValueIngestModeCreate,
+				adbc.OptionValueIngestModeAppend, adbc.OptionValueIngestModeReplace,
+				adbc.OptionValueIngestModeCreateAppend, adbc.OptionValueIngestModeMerge}, mode)
+		}
+	}
+
+	if v, ok := getInfoValue(ctx, cnxn, adbc.InfoFeatureIsolationLevels); ok {
+		c.IsType([]string{}, v)
+	}
+}
+
 func (c *ConnectionTests) TestMetadataGetTableSchema() {
 	rec, _, err := array.RecordFromJSON(c.Quirks.Alloc(), arrow.NewSchema(
 		[]arrow.Field{
@@ -390,6 +506,20 @@ func (s *StatementTests) TearDownTest() {
 	s.Driver = nil
 }
 
+// supportsBulkIngest reports whether the driver supports bulk ingest
+// using the given mode.
+func (s *StatementTests) supportsBulkIngest(mode string) bool {
+	supported := s.Quirks.SupportsBulkIngest()
+	if supported {
+		if q, ok := s.Quirks.(IngestModeQuirks); ok {
+			supported = q.SupportsBulkIngestMode(mode)
+		} else {
+			supported = mode == adbc.OptionValueIngestModeCreate || mode == adbc.OptionValueIngestModeAppend
+		}
+	}
+	return supportsIngestMode(s.T(), s.ctx, s.Cnxn, mode, supported)
+}
+
 func (s *StatementTests) TestNewStatement() {
 	stmt, err := s.Cnxn.NewStatement()
 	s.NoError(err)
@@ -415,7 +545,7 @@ func (s *StatementTests) TestSqlPartitionedInts() {
 	s.NoError(stmt.SetSqlQuery("SELECT 42"))
 
 	var adbcError adbc.Error
-	if !s.Quirks.SupportsPartitionedData() {
+	if !supportsFeature(s.T(), s.ctx, s.Cnxn, adbc.InfoFeaturePartitions, s.Quirks.SupportsPartitionedData()) {
 		_, _, _, err := stmt.ExecutePartitions(s.ctx)
 		s.ErrorAs(err, &adbcError)
 		s.Equal(adbc.StatusNotImplemented, adbcError.Code)
@@ -470,7 +600,7 @@ func (s *StatementTests) TestSQLPrepareGetParameterSchema() {
 	s.NoError(stmt.Prepare(s.ctx))
 
 	sc, err := stmt.GetParameterSchema()
-	if !s.Quirks.SupportsGetParameterSchema() {
+	if !supportsFeature(s.T(), s.ctx, s.Cnxn, adbc.InfoFeatureParameterSchema, s.Quirks.SupportsGetParameterSchema()) {
 		var adbcError adbc.Error
 		s.ErrorAs(err, &adbcError)
 		s.Equal(adbc.StatusNotImplemented, adbcError.Code)
@@ -569,6 +699,38 @@ func (s *StatementTests) TestSQLPrepareSelectNoParams() {
 	s.False(rdr.Next())
 }
 
+func (s *StatementTests) TestSqlExecuteSchema() {
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	es, ok := stmt.(adbc.StatementExecuteSchema)
+	if !ok {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(stmt.SetSqlQuery("SELECT 1"))
+	sc, err := es.ExecuteSchema(s.ctx)
+	var adbcError adbc.Error
+	if errors.As(err, &adbcError) && adbcError.Code == adbc.StatusNotImplemented {
+		s.T().SkipNow()
+	}
+	s.Require().NoError(err)
+
+	// the field metadata may only come with the results, such as with
+	// the record batch streams of snowflake
+	rdr, _, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+	s.Require().Len(sc.Fields(), len(rdr.Schema().Fields()))
+	for i, expected := range rdr.Schema().Fields() {
+		f := sc.Field(i)
+		s.Equal(expected.Name, f.Name)
+		s.Truef(arrow.TypeEqual(expected.Type, f.Type), "expected %s, got %s", expected.Type, f.Type)
+		s.Equal(expected.Nullable, f.Nullable)
+	}
+}
+
 func (s *StatementTests) TestSqlPrepareErrorParamCountMismatch() {
 	if !s.Quirks.SupportsDynamicParameterBinding() {
 		s.T().SkipNow()
@@ -596,7 +758,7 @@ func (s *StatementTests) TestSqlPrepareErrorParamCountMismatch() {
 }
 
 func (s *StatementTests) TestSqlIngestInts() {
-	if !s.Quirks.SupportsBulkIngest() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) {
 		s.T().SkipNow()
 	}
 
@@ -647,7 +809,8 @@ func (s *StatementTests) TestSqlIngestInts() {
 }
 
 func (s *StatementTests) TestSqlIngestAppend() {
-	if !s.Quirks.SupportsBulkIngest() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) ||
+		!s.supportsBulkIngest(adbc.OptionValueIngestModeAppend) {
 		s.T().SkipNow()
 	}
 
@@ -716,8 +879,226 @@ func (s *StatementTests) TestSqlIngestAppend() {
 	s.Require().NoError(rdr.Err())
 }
 
+// ingestInt64s bulk ingests a single int64s column into bulk_ingest
+// with the given mode, returning the batch which was ingested.
+func (s *StatementTests) ingestInt64s(stmt adbc.Statement, mode string, vals []int64, valid []bool) arrow.Record {
+	schema := arrow.NewSchema([]arrow.Field{{
+		Name: "int64s", Type: arrow.PrimitiveTypes.Int64, Nullable: true}}, nil)
+
+	batchbldr := array.NewRecordBuilder(s.Quirks.Alloc(), schema)
+	defer batchbldr.Release()
+	batchbldr.Field(0).(*array.Int64Builder).AppendValues(vals, valid)
+	batch := batchbldr.NewRecord()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, "bulk_ingest"))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestMode, mode))
+	s.Require().NoError(stmt.Bind(s.ctx, batch))
+
+	affected, err := stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	if affected != -1 && affected != int64(len(vals)) {
+		s.FailNowf("invalid number of affected rows", "should be -1 or %d, got: %d", len(vals), affected)
+	}
+	return batch
+}
+
+// checkInt64s runs the query and checks that it returns a single int64s
+// column with the expected values.
+func (s *StatementTests) checkInt64s(stmt adbc.Statement, query string, expected arrow.Array) {
+	s.Require().NoError(stmt.SetSqlQuery(query))
+	rdr, rows, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+	if rows != -1 && rows != int64(expected.Len()) {
+		s.FailNowf("invalid number of returned rows", "should be -1 or %d, got: %d", expected.Len(), rows)
+	}
+
+	s.Require().True(rdr.Next())
+	rec := rdr.Record()
+	s.EqualValues(expected.Len(), rec.NumRows())
+	s.EqualValues(1, rec.NumCols())
+	s.Truef(array.Equal(rec.Column(0), expected), "expected: %s\ngot: %s", expected, rec.Column(0))
+
+	s.Require().False(rdr.Next())
+	s.Require().NoError(rdr.Err())
+}
+
+func (s *StatementTests) TestSqlIngestReplace() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) ||
+		!s.supportsBulkIngest(adbc.OptionValueIngestModeReplace) {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	batch := s.ingestInt64s(stmt, adbc.OptionValueIngestModeCreate, []int64{42}, nil)
+	defer batch.Release()
+
+	// replacing drops the existing data
+	batch2 := s.ingestInt64s(stmt, adbc.OptionValueIngestModeReplace, []int64{-42, 0}, []bool{true, false})
+	defer batch2.Release()
+
+	s.checkInt64s(stmt, `SELECT * FROM bulk_ingest ORDER BY "int64s" DESC NULLS LAST`, batch2.Column(0))
+
+	// replacing a table that doesn't exist creates it
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+	batch3 := s.ingestInt64s(stmt, adbc.OptionValueIngestModeReplace, []int64{7}, nil)
+	defer batch3.Release()
+
+	s.checkInt64s(stmt, `SELECT * FROM bulk_ingest`, batch3.Column(0))
+}
+
+func (s *StatementTests) TestSqlIngestCreateAppend() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreateAppend) {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	// creates the table
+	batch := s.ingestInt64s(stmt, adbc.OptionValueIngestModeCreateAppend, []int64{42}, nil)
+	defer batch.Release()
+
+	// appends to the now existing table
+	batch2 := s.ingestInt64s(stmt, adbc.OptionValueIngestModeCreateAppend, []int64{-42, 0}, []bool{true, false})
+	defer batch2.Release()
+
+	exp, err := array.Concatenate([]arrow.Array{batch.Column(0), batch2.Column(0)}, s.Quirks.Alloc())
+	s.Require().NoError(err)
+	defer exp.Release()
+
+	s.checkInt64s(stmt, `SELECT * FROM bulk_ingest ORDER BY "int64s" DESC NULLS LAST`, exp)
+}
+
+func (s *StatementTests) TestSqlIngestMerge() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) ||
+		!s.supportsBulkIngest(adbc.OptionValueIngestModeMerge) {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "val", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	batch, _, err := array.RecordFromJSON(s.Quirks.Alloc(), schema,
+		strings.NewReader(`[{"id": 1, "val": "one"}, {"id": 2, "val": "two"}]`))
+	s.Require().NoError(err)
+	defer batch.Release()
+
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, "bulk_ingest"))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeCreate))
+	s.Require().NoError(stmt.Bind(s.ctx, batch))
+	_, err = stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+
+	// id 2 gets updated, id 3 is inserted
+	batch2, _, err := array.RecordFromJSON(s.Quirks.Alloc(), schema,
+		strings.NewReader(`[{"id": 2, "val": "deux"}, {"id": 3, "val": "three"}]`))
+	s.Require().NoError(err)
+	defer batch2.Release()
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTargetTable, "bulk_ingest"))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestMode, adbc.OptionValueIngestModeMerge))
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestKeyColumns, "id"))
+	s.Require().NoError(stmt.Bind(s.ctx, batch2))
+
+	affected, err := stmt.ExecuteUpdate(s.ctx)
+	s.Require().NoError(err)
+	if affected != -1 && affected != 2 {
+		s.FailNowf("invalid number of affected rows", "should be -1 or 2, got: %d", affected)
+	}
+
+	expected, _, err := array.RecordFromJSON(s.Quirks.Alloc(), schema,
+		strings.NewReader(`[{"id": 1, "val": "one"}, {"id": 2, "val": "deux"}, {"id": 3, "val": "three"}]`))
+	s.Require().NoError(err)
+	defer expected.Release()
+
+	s.Require().NoError(stmt.SetSqlQuery(`SELECT * FROM bulk_ingest ORDER BY "id"`))
+	rdr, _, err := stmt.ExecuteQuery(s.ctx)
+	s.Require().NoError(err)
+	defer rdr.Release()
+
+	s.Require().True(rdr.Next())
+	rec := rdr.Record()
+	s.EqualValues(3, rec.NumRows())
+	s.Require().EqualValues(2, rec.NumCols())
+	for i, col := range expected.Columns() {
+		s.Truef(array.Equal(col, rec.Column(i)), "expected: %s\ngot: %s", col, rec.Column(i))
+	}
+	s.Require().False(rdr.Next())
+	s.Require().NoError(rdr.Err())
+}
+
+func (s *StatementTests) TestSqlIngestTemporary() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	var e adbc.Error
+	if err := stmt.SetOption(adbc.OptionKeyIngestTemporary, adbc.OptionValueEnabled); errors.As(err, &e) && e.Code == adbc.StatusNotImplemented {
+		s.T().Skip("temporary table ingestion not supported")
+	} else {
+		s.Require().NoError(err)
+	}
+
+	batch := s.ingestInt64s(stmt, adbc.OptionValueIngestModeCreate, []int64{42, -42, 0}, []bool{true, true, false})
+	defer batch.Release()
+
+	s.checkInt64s(stmt, `SELECT * FROM bulk_ingest ORDER BY "int64s" DESC NULLS LAST`, batch.Column(0))
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	s.Require().NoError(stmt.SetOption(adbc.OptionKeyIngestTemporary, adbc.OptionValueDisabled))
+	s.ErrorAs(stmt.SetOption(adbc.OptionKeyIngestTemporary, "invalid"), &e)
+	s.Equal(adbc.StatusInvalidArgument, e.Code)
+}
+
+func (s *StatementTests) TestSqlIngestTargetDBSchema() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) || s.Quirks.DBSchema() == "" {
+		s.T().SkipNow()
+	}
+
+	s.Require().NoError(s.Quirks.DropTable(s.Cnxn, "bulk_ingest"))
+
+	stmt, err := s.Cnxn.NewStatement()
+	s.Require().NoError(err)
+	defer stmt.Close()
+
+	var e adbc.Error
+	if err := stmt.SetOption(adbc.OptionKeyIngestTargetDBSchema, s.Quirks.DBSchema()); errors.As(err, &e) && e.Code == adbc.StatusNotImplemented {
+		s.T().Skip("ingestion into a specific db schema not supported")
+	} else {
+		s.Require().NoError(err)
+	}
+
+	batch := s.ingestInt64s(stmt, adbc.OptionValueIngestModeCreate, []int64{42, -42, 0}, []bool{true, true, false})
+	defer batch.Release()
+
+	s.checkInt64s(stmt, `SELECT * FROM `+s.Quirks.DBSchema()+`.bulk_ingest ORDER BY "int64s" DESC NULLS LAST`, batch.Column(0))
+}
+
 func (s *StatementTests) TestSqlIngestErrors() {
-	if !s.Quirks.SupportsBulkIngest() {
+	if !s.supportsBulkIngest(adbc.OptionValueIngestModeCreate) {
 		s.T().SkipNow()
 	}
 